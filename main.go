@@ -9,34 +9,63 @@
 package main
 
 import (
+	"context"
+	"embed"
+	"io/fs"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"ashokshau/tgmusic/src"
 	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/diag"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/core/shutdown"
+	"ashokshau/tgmusic/src/core/web"
 	"ashokshau/tgmusic/src/lang"
-	"ashokshau/tgmusic/src/vc"
 
 	tg "github.com/amarnathcjd/gogram/telegram"
 )
 
 //go:generate go run setup_ntgcalls.go static
 
+// localeFiles are the translations built into the binary.
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
 // main serves as the entry point for the application.
 // It initializes the configuration, database, and Telegram client, then starts the bot and waits for a shutdown signal.
 func main() {
 	if err := config.LoadConfig(); err != nil {
-		panic(err)
+		log.Fatal(err)
+	}
+	log.SetOutput(config.NewRedactWriter(logging.Tee(os.Stderr)))
+	setupLogging(config.Get())
+	config.OnReload(setupLogging)
+	if config.Get().Tracing {
+		stop, err := logging.StartTracing("tgmusic")
+		if err != nil {
+			log.Printf("Tracing is disabled: %v", err)
+		} else {
+			defer stop()
+		}
 	}
 
-	err := lang.LoadTranslations()
+	locales, _ := fs.Sub(localeFiles, "locales")
+	err := lang.LoadTranslations(locales)
 	if err != nil {
 		panic(err)
 	}
+	if code := config.Get().DefaultLang; !lang.Has(code) {
+		log.Printf("DEFAULT_LANG %q has no locale file; %s is used instead.", code, lang.Fallback)
+	}
 
 	clientConfig := tg.ClientConfig{
-		AppID:        config.Conf.ApiId,
-		AppHash:      config.Conf.ApiHash,
+		AppID:        config.Get().ApiId,
+		AppHash:      config.Get().ApiHash,
 		FloodHandler: handleFlood,
 		SessionName:  "bot",
 	}
@@ -45,13 +74,14 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to create client: %v", err)
 	}
+	client.Log.SetOutput(config.NewRedactWriter(logging.Tee(os.Stdout)))
 
 	_, err = client.Conn()
 	if err != nil {
 		log.Fatalf("failed to connect: %v", err)
 	}
 
-	err = client.LoginBot(config.Conf.Token)
+	err = client.LoginBot(config.Get().Token)
 	if err != nil {
 		log.Fatalf("failed to login: %v", err)
 	}
@@ -61,12 +91,51 @@ func main() {
 		log.Fatalf("failed to init: %v", err)
 	}
 
+	go reloadOnHangup()
+
+	if addr := config.Get().DebugAddr; addr != "" {
+		srv := diag.Serve(addr, func(err error) { log.Printf("The debug server has stopped: %v", err) })
+		shutdown.Register("stop the debug server", shutdown.PriorityClient, srv.Shutdown)
+		log.Printf("The debug server is listening on %s.", addr)
+	}
+	if addr := config.Get().PublicAddr; addr != "" {
+		srv := web.Serve(addr, func(err error) { log.Printf("The public web server has stopped: %v", err) })
+		shutdown.Register("stop the public web server", shutdown.PriorityClient, srv.Shutdown)
+		log.Printf("The public web server is listening on %s.", addr)
+	}
+
+	shutdown.Register("stop the bot client", shutdown.PriorityClient, func(context.Context) error {
+		return client.Stop()
+	})
+	shutdown.Register("close the log file", shutdown.PriorityLogs, func(context.Context) error {
+		logging.Close()
+		return nil
+	})
+
 	client.Log.Info("The bot is running as @%s.", client.Me().Username)
-	_, _ = client.SendMessage(config.Conf.LoggerId, "The bot has started!")
-	client.Idle()
+	_, _ = client.SendMessage(config.Get().LoggerId, "The bot has started!")
+	waitForStop()
 	log.Println("The bot is shutting down...")
-	vc.Calls.StopAllClients()
-	_ = client.Stop()
+	shutdown.Run(time.Duration(config.Get().ShutdownGrace) * time.Second)
+	if code := shutdown.ExitCode(); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// waitForStop blocks until the process receives SIGINT or SIGTERM or a restart is requested.
+// A signal received after that exits at once.
+func waitForStop() {
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	select {
+	case <-sig:
+	case <-shutdown.Requested():
+	}
+	go func() {
+		<-sig
+		log.Println("Received another signal; exiting without finishing the shutdown.")
+		os.Exit(1)
+	}()
 }
 
 // handleFlood manages flood wait errors by pausing execution for the specified duration.
@@ -79,3 +148,42 @@ func handleFlood(err error) bool {
 	}
 	return false
 }
+
+// setupLogging applies the configured log format, levels and log file. The values were validated when the configuration was loaded.
+func setupLogging(c *config.BotConfig) {
+	level, _ := logging.ParseLevel(c.LogLevel)
+	overrides, _ := logging.ParseOverrides(c.LogLevels)
+	logging.Setup(c.LogFormat, config.NewRedactWriter(logging.Tee(os.Stdout)), level, overrides)
+	logging.SetSlowThreshold(time.Duration(c.SlowOpThreshold) * time.Millisecond)
+	logging.SetSampling(c.LogSampleBurst)
+
+	err := logging.SetFile(c.LogFile, logging.FileOptions{
+		MaxSize:     c.LogFileMaxSize << 20,
+		MaxBackups:  int(c.LogFileMaxBackups),
+		MaxAge:      time.Duration(c.LogFileMaxAge) * 24 * time.Hour,
+		Compress:    c.LogFileCompress,
+		SyncOnError: c.LogSyncOnError,
+	})
+	if err != nil {
+		log.Printf("File logging is disabled: %v", err)
+	}
+}
+
+// reloadOnHangup reloads the configuration every time the process receives SIGHUP.
+func reloadOnHangup() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		res, err := config.Reload()
+		if err != nil {
+			log.Printf("Failed to reload the configuration: %v", err)
+		}
+		if res == nil {
+			continue
+		}
+		log.Printf("The configuration has been reloaded. Updated: %v", res.Changed)
+		if len(res.Ignored) > 0 {
+			log.Printf("These settings need a restart to take effect: %v", res.Ignored)
+		}
+	}
+}
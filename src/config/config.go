@@ -10,15 +10,25 @@ package config
 
 import (
 	"fmt"
+	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
-
-	"github.com/joho/godotenv"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// DefaultCleanDelay is the clean mode delay, in seconds, used when AUTO_DELETE_DELAY is unset.
+const DefaultCleanDelay = 20
+
+// MaxBroadcastWorkers caps BROADCAST_WORKERS and /broadcast -workers.
+const MaxBroadcastWorkers = 50
+
 // BotConfig holds the configuration for the bot.
 type BotConfig struct {
+	Profile           string   // Profile is the PROFILE whose defaults were applied (small, medium, large, or empty for none).
 	ApiId             int32    // ApiId is the Telegram API ID.
 	ApiHash           string   // ApiHash is the Telegram API hash.
 	Token             string   // Token is the bot token.
@@ -28,29 +38,220 @@ type BotConfig struct {
 	DbName            string   // DbName is the name of the database.
 	ApiUrl            string   // ApiUrl is the URL of the API.
 	ApiKey            string   // ApiKey is the API key.
-	OwnerId           int64    // OwnerId is the user ID of the bot owner.
+	OwnerIds          []int64  // OwnerIds are the user IDs of the bot owners.
 	LoggerId          int64    // LoggerId is the group ID of the bot logger.
+	SupportChatId     int64    // SupportChatId is the group errors and user reports are forwarded to (0 uses LoggerId).
 	Proxy             string   // Proxy is the proxy URL for the bot.
 	DefaultService    string   // DefaultService is the default search platform.
+	DefaultLang       string   // DefaultLang is the language of users and chats that have not chosen one.
+	Aliases           string   // Aliases adds command aliases, such as "np=queue,bajao=play".
 	MaxFileSize       int64    // MaxFileSize is the maximum file size for downloads.
 	SongDurationLimit int64    // SongDurationLimit is the maximum duration of a song in seconds.
+	VideoHeight       int64    // VideoHeight is the highest video resolution streamed, in lines.
+	MaxPlaylists      int64    // MaxPlaylists caps how many playlists a user may own.
 	DownloadsDir      string   // DownloadsDir is the directory where downloads are stored.
+	DownloadsLayout   string   // DownloadsLayout arranges DownloadsDir: flat, daily (one folder per day) or prefix (by the first two characters of the ID).
 	SupportGroup      string   // SupportGroup is the Telegram group link.
 	SupportChannel    string   // SupportChannel is the Telegram channel link.
+	ForceSub          string   // ForceSub is the channel users must join before using playback commands, as @username or chat ID (empty disables).
+	AutoDeleteDelay   int64    // AutoDeleteDelay is the default clean mode delay in seconds for chats that have not set one (0 disables).
+	MaxQueueLength    int64    // MaxQueueLength is the maximum number of pending tracks per chat (0 disables the limit).
+	IdleLeaveTimeout  int64    // IdleLeaveTimeout is how many seconds the assistant stays in a voice chat after the queue ends (0 leaves immediately).
+	AloneLeaveTimeout int64    // AloneLeaveTimeout is how many seconds the assistant may stay alone in a voice chat before leaving (0 disables).
+	AutoPauseAfter    int64    // AutoPauseAfter is how many seconds a stream may play to an empty voice chat before it is paused (0 disables).
+	ReapIdleAfter     int64    // ReapIdleAfter is how many hours per-chat state may stay idle before it is torn down (0 disables).
+	MaxRadioChats     int64    // MaxRadioChats caps how many chats may enable 24/7 radio mode (0 disables the cap).
+	MaxFollows        int64    // MaxFollows caps the channels all chats together may follow with /follow (0 disables the cap).
+	MaxChatFollows    int64    // MaxChatFollows caps the channels a single chat may follow with /follow.
+	FollowPoll        int64    // FollowPoll is how often, in seconds, followed channels are checked for new uploads.
+	MaxActiveCalls    int64    // MaxActiveCalls caps how many chats may play at once; further chats wait for a free slot (0 disables the cap).
+	DuplicateWindow   int64    // DuplicateWindow is how many minutes a finished track counts as a duplicate in chats with no_duplicates on.
+	ApprovalTimeout   int64    // ApprovalTimeout is how many seconds a request waits for an admin in chats with approval mode on before it expires.
+	ApprovalPending   int64    // ApprovalPending caps how many requests one user may have waiting for approval in a chat.
+	MaxDownloads      int64    // MaxDownloads bounds how many tracks are downloaded at once, including prefetches.
+	DownloadRate      int64    // DownloadRate caps the combined bandwidth of all downloads in bytes per second (0 leaves it unlimited).
+	LowBandwidthHours string   // LowBandwidthHours is a daily HH:MM-HH:MM window, UTC, in which LowBandwidthRate applies instead (empty disables).
+	LowBandwidthRate  int64    // LowBandwidthRate caps the download bandwidth during LowBandwidthHours, in bytes per second.
+	TempBudget        int64    // TempBudget bounds the space the temporary files of edits and transcodes may take, in bytes (0 leaves it unlimited).
+	GaplessPreload    bool     // GaplessPreload pre-spawns the next track's ffmpeg process to shorten the gap between tracks.
+	AutoResume        bool     // AutoResume resumes interrupted playback on startup instead of offering a button.
+	ResumeStaleAfter  int64    // ResumeStaleAfter is how many seconds a queue snapshot stays eligible for resuming.
+	LogFormat         string   // LogFormat is the log output format: text (key=value) or json.
+	LogLevel          string   // LogLevel is the default log level: debug, info, warn, error or off.
+	LogLevels         string   // LogLevels overrides the level per module, such as "dl=debug,broadcast=warn".
+	LogFile           string   // LogFile is the file logs are also written to (empty logs to stdout only).
+	LogFileMaxSize    int64    // LogFileMaxSize is the size in MB at which the log file is rotated.
+	LogFileMaxBackups int64    // LogFileMaxBackups is how many rotated log files are kept (0 keeps all).
+	LogFileMaxAge     int64    // LogFileMaxAge is how many days rotated log files are kept (0 keeps them regardless of age).
+	LogFileCompress   bool     // LogFileCompress gzips rotated log files.
+	LogSyncOnError    bool     // LogSyncOnError flushes the log file to disk after every error.
+	SlowOpThreshold   int64    // SlowOpThreshold is how many milliseconds a command, download or query may take before it is logged as slow (0 disables).
+	LogSampleBurst    int64    // LogSampleBurst is how many times a minute the same warning may be logged before it is summarised (0 disables sampling).
+	Tracing           bool     // Tracing exports timed operations as OpenTelemetry spans; needs a build with the otel tag.
+	ShutdownGrace     int64    // ShutdownGrace is how many seconds a shutdown may take before the process is forced to exit.
+	WatchdogInterval  int64    // WatchdogInterval is how many seconds apart the watchdog checks that the bot still responds (0 disables it).
+	WatchdogFailures  int64    // WatchdogFailures is how many checks in a row must fail before the watchdog raises an alert.
+	WatchdogStuck     int64    // WatchdogStuck is how many seconds a handler may run before the watchdog counts it as stuck.
+	WatchdogIdleAfter int64    // WatchdogIdleAfter is how many seconds without any update count as a failed check (0 disables this check).
+	WatchdogRestart   bool     // WatchdogRestart restarts the bot through the graceful shutdown path when the watchdog raises an alert.
+	DebugAddr         string   // DebugAddr is the address of the debug server serving pprof, such as 127.0.0.1:6060 (empty disables it).
+	DebugToken        string   // DebugToken must be sent with every debug server request.
+	PublicAddr        string   // PublicAddr is the address of the public web server serving the now-playing pages, such as :8080 (empty disables it).
+	SelfTestStrict    bool     // SelfTestStrict refuses to start the bot when the startup self-test finds a hard failure.
+	DispatchWorkers   int64    // DispatchWorkers is how many handlers may run at the same time.
+	DispatchQueue     int64    // DispatchQueue is how many updates may wait for a handler before service updates are dropped.
+	BroadcastWorkers  int64    // BroadcastWorkers is how many targets a broadcast sends to at once, unless /broadcast -workers says otherwise.
+	DigestTime        string   // DigestTime is when the daily digest is posted to the logger group, as HH:MM local time (empty disables).
+	Cards             bool     // Cards replaces the now-playing text with a rendered card once playback has started.
+	CardFallback      bool     // CardFallback shows the track's plain cover when the card cannot be rendered.
+	CardFont          string   // CardFont is a TTF or OTF font for the card's text, for titles the bundled font cannot draw (empty uses the bundled one).
+	Features          Features // Features switches whole features off for this deployment.
+	Premium           Premium  // Premium configures the paid tier and the limits it lifts.
+	Speech            Speech   // Speech configures the speech-to-text backend behind voice note searches.
+	TTS               TTS      // TTS configures the text-to-speech backend behind spoken announcements between tracks.
 	DEVS              []int64  // DEVS is a list of developer user IDs.
 	CookiesPath       []string // CookiesPath is a list of paths to cookies files.
 	cookiesUrl        []string // cookiesUrl is a list of URLs to cookies files.
 }
 
-// Conf is the global configuration for the bot.
-var Conf *BotConfig
+// Features lists the features a deployment can switch off. Everything is enabled by default.
+// Commands of a disabled feature are not registered at startup; runtime checks also honour reloads.
+type Features struct {
+	Video       bool // Video allows video playback (FEATURE_VIDEO).
+	Broadcasts  bool // Broadcasts allows the developer broadcast commands (FEATURE_BROADCASTS).
+	Playlists   bool // Playlists allows user playlists (FEATURE_PLAYLISTS).
+	Radio       bool // Radio allows 24/7 radio mode (FEATURE_RADIO).
+	QueueIO     bool // QueueIO allows exporting and importing queues (FEATURE_QUEUE_IO).
+	VoiceSearch bool // VoiceSearch lets /play search for what is said in a replied voice note (FEATURE_VOICE_SEARCH).
+}
 
-// LoadConfig loads the configuration from environment variables and sets the global Conf.
-// It also validates the configuration and saves cookies if provided.
-func LoadConfig() error {
-	_ = godotenv.Load()
+// Speech configures speech-to-text for voice note searches. A local whisper.cpp binary is used when
+// WhisperBin and WhisperModel are set, otherwise the HTTP API at APIURL; with neither, voice searches are off.
+type Speech struct {
+	WhisperBin   string // WhisperBin is the path of the whisper.cpp command-line binary (WHISPER_BIN).
+	WhisperModel string // WhisperModel is the path of the ggml model whisper.cpp loads (WHISPER_MODEL).
+	APIURL       string // APIURL is an OpenAI-compatible transcription endpoint (STT_API_URL).
+	APIKey       string // APIKey is sent to APIURL as a bearer token (STT_API_KEY).
+	Model        string // Model is the model name sent to APIURL (STT_MODEL).
+	Language     string // Language is the spoken language as an ISO 639-1 code; empty detects it (STT_LANGUAGE).
+}
+
+// Local reports whether a local whisper.cpp binary transcribes voice notes.
+func (s Speech) Local() bool {
+	return s.WhisperBin != "" && s.WhisperModel != ""
+}
+
+// Configured reports whether a speech-to-text backend is set up.
+func (s Speech) Configured() bool {
+	return s.Local() || s.APIURL != ""
+}
+
+// TTS configures text-to-speech for the announcements chats can switch on with /announce. A local espeak or
+// piper binary is used when Bin is set, otherwise the HTTP API at APIURL; with neither, announcements are off.
+type TTS struct {
+	Bin    string // Bin is the path of an espeak, espeak-ng or piper binary (TTS_BIN).
+	Voice  string // Voice is the espeak voice, the piper model file or the API voice (TTS_VOICE).
+	APIURL string // APIURL is an OpenAI-compatible speech endpoint (TTS_API_URL).
+	APIKey string // APIKey is sent to APIURL as a bearer token (TTS_API_KEY).
+	Model  string // Model is the model name sent to APIURL (TTS_MODEL).
+}
+
+// Piper reports whether Bin is piper, which reads its text from stdin and needs a model as Voice.
+func (t TTS) Piper() bool {
+	return strings.Contains(strings.ToLower(filepath.Base(t.Bin)), "piper")
+}
+
+// Configured reports whether a text-to-speech backend is set up.
+func (t TTS) Configured() bool {
+	return t.Bin != "" || t.APIURL != ""
+}
+
+// Premium configures the paid tier. The tier is sold with /premium when Price is above 0; owners can grant it
+// regardless. Its limits replace SongDurationLimit, VideoHeight and MaxPlaylists for premium users.
+type Premium struct {
+	ProviderToken string // ProviderToken is the payment provider token; empty charges Telegram Stars (PAYMENT_PROVIDER_TOKEN).
+	Currency      string // Currency is the invoice currency, XTR for Stars (PREMIUM_CURRENCY).
+	Price         int64  // Price is the price of one period in the currency's smallest unit; 0 stops selling (PREMIUM_PRICE).
+	Days          int64  // Days is how long one period lasts (PREMIUM_DAYS).
+	SongDuration  int64  // SongDuration is the maximum duration of a song in seconds (PREMIUM_SONG_DURATION).
+	VideoHeight   int64  // VideoHeight is the highest video resolution streamed, in lines (PREMIUM_VIDEO_HEIGHT).
+	Playlists     int64  // Playlists caps how many playlists a user may own (PREMIUM_PLAYLISTS).
+}
+
+// Selling reports whether the tier can be bought with /premium.
+func (p Premium) Selling() bool {
+	return p.Price > 0 && (p.Currency == "XTR" || p.ProviderToken != "")
+}
+
+// parseClockWindow parses a daily "HH:MM-HH:MM" window into minutes after midnight.
+func parseClockWindow(s string) (start, end int, err error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("%q is not a window; use HH:MM-HH:MM", s)
+	}
+	a, err := time.Parse("15:04", strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%q is not a time of day", from)
+	}
+	b, err := time.Parse("15:04", strings.TrimSpace(to))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%q is not a time of day", to)
+	}
+	return a.Hour()*60 + a.Minute(), b.Hour()*60 + b.Minute(), nil
+}
+
+// InLowBandwidthHours reports whether t falls within LowBandwidthHours. A window whose end is before its
+// start wraps past midnight.
+func (c *BotConfig) InLowBandwidthHours(t time.Time) bool {
+	if c.LowBandwidthHours == "" {
+		return false
+	}
+	start, end, err := parseClockWindow(c.LowBandwidthHours)
+	if err != nil || start == end {
+		return false
+	}
+	t = t.UTC()
+	minute := t.Hour()*60 + t.Minute()
+	if end < start {
+		return minute >= start || minute < end
+	}
+	return minute >= start && minute < end
+}
+
+// DownloadRateAt returns the download bandwidth cap in force at t, in bytes per second, or 0 if there is none.
+func (c *BotConfig) DownloadRateAt(t time.Time) int64 {
+	if c.LowBandwidthRate > 0 && c.InLowBandwidthHours(t) {
+		return c.LowBandwidthRate
+	}
+	return c.DownloadRate
+}
+
+// current holds the active configuration snapshot; reloads swap it atomically.
+var current atomic.Pointer[BotConfig]
+
+// Use makes c the active configuration without reading the environment, and returns the func that restores
+// the previous one. It is meant for the tests of packages that read the configuration.
+func Use(c *BotConfig) (restore func()) {
+	prev := current.Swap(c)
+	return func() { current.Store(prev) }
+}
 
-	Conf = &BotConfig{
+// reloadMu serializes reloads and cookie updates.
+var reloadMu sync.Mutex
+
+// Get returns the active configuration.
+// Read it where the value is used instead of keeping it, so that /reloadconfig takes effect.
+func Get() *BotConfig {
+	return current.Load()
+}
+
+// readEnv builds a configuration from the environment.
+func readEnv() *BotConfig {
+	envProblems = nil
+	profile := strings.ToLower(os.Getenv("PROFILE"))
+	activeProfile = profiles[profile]
+	c := &BotConfig{
+		Profile:           profile,
 		ApiId:             getEnvInt32("API_ID", 0),
 		ApiHash:           os.Getenv("API_HASH"),
 		Token:             os.Getenv("TOKEN"),
@@ -60,40 +261,220 @@ func LoadConfig() error {
 		DbName:            getEnvStr("DB_NAME", "MusicBot"),
 		ApiUrl:            getEnvStr("API_URL", "https://tgmusic.fallenapi.fun"),
 		ApiKey:            os.Getenv("API_KEY"),
-		OwnerId:           getEnvInt64("OWNER_ID", 5938660179),
+		OwnerIds:          getEnvIDs("OWNER_ID", []int64{5938660179}),
 		LoggerId:          getEnvInt64("LOGGER_ID", -1002166934878),
+		SupportChatId:     getEnvInt64("SUPPORT_CHAT_ID", 0),
 		Proxy:             os.Getenv("PROXY"),
 		DefaultService:    strings.ToLower(getEnvStr("DEFAULT_SERVICE", "youtube")),
+		DefaultLang:       strings.ToLower(getEnvStr("DEFAULT_LANG", "en")),
+		Aliases:           getEnvStr("COMMAND_ALIASES", ""),
 		MaxFileSize:       getEnvInt64("MAX_FILE_SIZE", 500*1024*1024),
 		SongDurationLimit: getEnvInt64("SONG_DURATION_LIMIT", 3600),
+		VideoHeight:       getEnvInt64("VIDEO_MAX_HEIGHT", 720),
+		MaxPlaylists:      getEnvInt64("MAX_PLAYLISTS", 10),
 		DownloadsDir:      getEnvStr("DOWNLOADS_DIR", "downloads"),
+		DownloadsLayout:   strings.ToLower(getEnvStr("DOWNLOADS_LAYOUT", "flat")),
 		SupportGroup:      getEnvStr("SUPPORT_GROUP", "https://t.me/GuardxSupport"),
 		SupportChannel:    getEnvStr("SUPPORT_CHANNEL", "https://t.me/FallenProjects"),
+		ForceSub:          getEnvStr("FORCE_SUB_CHANNEL", ""),
+		AutoDeleteDelay:   getEnvInt64("AUTO_DELETE_DELAY", DefaultCleanDelay),
+		MaxQueueLength:    getEnvInt64("MAX_QUEUE_LENGTH", 10),
+		IdleLeaveTimeout:  getEnvInt64("IDLE_LEAVE_TIMEOUT", 180),
+		AloneLeaveTimeout: getEnvInt64("ALONE_LEAVE_TIMEOUT", 0),
+		AutoPauseAfter:    getEnvInt64("AUTO_PAUSE_AFTER", 60),
+		ReapIdleAfter:     getEnvInt64("REAP_IDLE_AFTER", 24),
+		MaxRadioChats:     getEnvInt64("MAX_RADIO_CHATS", 10),
+		MaxFollows:        getEnvInt64("MAX_FOLLOWS", 500),
+		MaxChatFollows:    getEnvInt64("MAX_CHAT_FOLLOWS", 10),
+		FollowPoll:        getEnvInt64("FOLLOW_POLL_INTERVAL", 1800),
+		MaxActiveCalls:    getEnvInt64("MAX_ACTIVE_CALLS", 0),
+		DuplicateWindow:   getEnvInt64("DUPLICATE_WINDOW", 30),
+		ApprovalTimeout:   getEnvInt64("APPROVAL_TIMEOUT", 600),
+		ApprovalPending:   getEnvInt64("APPROVAL_MAX_PENDING", 3),
+		MaxDownloads:      getEnvInt64("MAX_CONCURRENT_DOWNLOADS", 3),
+		DownloadRate:      getEnvInt64("DOWNLOAD_RATE_LIMIT", 0),
+		LowBandwidthHours: getEnvStr("LOW_BANDWIDTH_HOURS", ""),
+		LowBandwidthRate:  getEnvInt64("LOW_BANDWIDTH_RATE", 0),
+		TempBudget:        getEnvInt64("TEMP_SPACE_BUDGET", 1024*1024*1024),
+		GaplessPreload:    getEnvBool("GAPLESS_PRELOAD", false),
+		AutoResume:        getEnvBool("AUTO_RESUME", false),
+		ResumeStaleAfter:  getEnvInt64("RESUME_STALE_AFTER", 1800),
 		cookiesUrl:        processCookieURLs(os.Getenv("COOKIES_URL")),
+		LogFormat:         strings.ToLower(getEnvStr("LOG_FORMAT", "text")),
+		LogLevel:          getEnvStr("LOG_LEVEL", "info"),
+		LogLevels:         getEnvStr("LOG_LEVELS", ""),
+		LogFile:           getEnvStr("LOG_FILE", ""),
+		LogFileMaxSize:    getEnvInt64("LOG_FILE_MAX_SIZE", 20),
+		LogFileMaxBackups: getEnvInt64("LOG_FILE_MAX_BACKUPS", 5),
+		LogFileMaxAge:     getEnvInt64("LOG_FILE_MAX_AGE", 14),
+		LogFileCompress:   getEnvBool("LOG_FILE_COMPRESS", true),
+		LogSyncOnError:    getEnvBool("LOG_SYNC_ON_ERROR", true),
+		SlowOpThreshold:   getEnvInt64("SLOW_OP_THRESHOLD", 2000),
+		LogSampleBurst:    getEnvInt64("LOG_SAMPLE_BURST", 10),
+		Tracing:           getEnvBool("TRACING", false),
+		ShutdownGrace:     getEnvInt64("SHUTDOWN_GRACE", 30),
+		WatchdogInterval:  getEnvInt64("WATCHDOG_INTERVAL", 60),
+		WatchdogFailures:  getEnvInt64("WATCHDOG_FAILURES", 3),
+		WatchdogStuck:     getEnvInt64("WATCHDOG_STUCK_AFTER", 300),
+		WatchdogIdleAfter: getEnvInt64("WATCHDOG_IDLE_AFTER", 0),
+		WatchdogRestart:   getEnvBool("WATCHDOG_RESTART", false),
+		DebugAddr:         getEnvStr("DEBUG_ADDR", ""),
+		DebugToken:        getEnvStr("DEBUG_TOKEN", ""),
+		PublicAddr:        getEnvStr("PUBLIC_ADDR", ""),
+		SelfTestStrict:    getEnvBool("SELFTEST_STRICT", false),
+		DispatchWorkers:   getEnvInt64("DISPATCH_WORKERS", 50),
+		DispatchQueue:     getEnvInt64("DISPATCH_QUEUE", 1000),
+		BroadcastWorkers:  getEnvInt64("BROADCAST_WORKERS", 20),
+		DigestTime:        getEnvStr("DIGEST_TIME", "00:00"),
+		Cards:             getEnvBool("THUMB_CARDS", true),
+		CardFallback:      getEnvBool("THUMB_FALLBACK", true),
+		CardFont:          getEnvStr("THUMB_FONT", ""),
+		Features: Features{
+			Video:       getEnvBool("FEATURE_VIDEO", true),
+			Broadcasts:  getEnvBool("FEATURE_BROADCASTS", true),
+			Playlists:   getEnvBool("FEATURE_PLAYLISTS", true),
+			Radio:       getEnvBool("FEATURE_RADIO", true),
+			QueueIO:     getEnvBool("FEATURE_QUEUE_IO", true),
+			VoiceSearch: getEnvBool("FEATURE_VOICE_SEARCH", true),
+		},
+		Speech: Speech{
+			WhisperBin:   getEnvStr("WHISPER_BIN", ""),
+			WhisperModel: getEnvStr("WHISPER_MODEL", ""),
+			APIURL:       getEnvStr("STT_API_URL", ""),
+			APIKey:       getEnvStr("STT_API_KEY", ""),
+			Model:        getEnvStr("STT_MODEL", "whisper-1"),
+			Language:     strings.ToLower(getEnvStr("STT_LANGUAGE", "")),
+		},
+		TTS: TTS{
+			Bin:    getEnvStr("TTS_BIN", ""),
+			Voice:  getEnvStr("TTS_VOICE", ""),
+			APIURL: getEnvStr("TTS_API_URL", ""),
+			APIKey: getEnvStr("TTS_API_KEY", ""),
+			Model:  getEnvStr("TTS_MODEL", "tts-1"),
+		},
+		Premium: Premium{
+			ProviderToken: getEnvStr("PAYMENT_PROVIDER_TOKEN", ""),
+			Currency:      strings.ToUpper(getEnvStr("PREMIUM_CURRENCY", "XTR")),
+			Price:         getEnvInt64("PREMIUM_PRICE", 0),
+			Days:          getEnvInt64("PREMIUM_DAYS", 30),
+			SongDuration:  getEnvInt64("PREMIUM_SONG_DURATION", 3*3600),
+			VideoHeight:   getEnvInt64("PREMIUM_VIDEO_HEIGHT", 1080),
+			Playlists:     getEnvInt64("PREMIUM_PLAYLISTS", 50),
+		},
 	}
 
-	// Parse DEVS list
-	devsEnv := os.Getenv("DEVS")
-	if devsEnv != "" {
-		for _, idStr := range strings.Fields(devsEnv) {
-			if id, err := strconv.ParseInt(idStr, 10, 64); err == nil {
-				Conf.DEVS = append(Conf.DEVS, id)
-			}
+	// Owners are always developers.
+	c.DEVS = getEnvIDs("DEVS", nil)
+	for _, owner := range c.OwnerIds {
+		if !containsInt(c.DEVS, owner) {
+			c.DEVS = append(c.DEVS, owner)
 		}
 	}
-	if Conf.OwnerId != 0 && !containsInt(Conf.DEVS, Conf.OwnerId) {
-		Conf.DEVS = append(Conf.DEVS, Conf.OwnerId)
+
+	return c
+}
+
+// checked validates c, logging soft problems and returning a *ValidationError listing every fatal one.
+func checked(c *BotConfig) error {
+	var fatal []Problem
+	for _, p := range c.Validate() {
+		if p.Fatal {
+			fatal = append(fatal, p)
+		} else {
+			log.Printf("[config] Warning: %s", p)
+		}
+	}
+	if len(fatal) > 0 {
+		return &ValidationError{Problems: fatal}
+	}
+	return nil
+}
+
+// LoadConfig loads the configuration from environment variables, the .env file and the optional CONFIG_FILE,
+// in that order of precedence, and makes it the active one.
+// It also validates the configuration, logging soft problems and returning a *ValidationError listing every fatal one,
+// and saves cookies if provided.
+func LoadConfig() error {
+	if err := loadLayers(); err != nil {
+		return err
 	}
 
-	if err := Conf.validate(); err != nil {
+	c := readEnv()
+	if err := checked(c); err != nil {
 		return err
 	}
+	current.Store(c)
+	log.Printf("[config] Effective configuration:\n%s", c.Summary())
 
-	if len(Conf.cookiesUrl) > 0 {
+	if len(c.cookiesUrl) > 0 {
 		if err := os.MkdirAll(tmpDir, 0750); err != nil {
 			return fmt.Errorf("failed to create temp dir: %w", err)
 		}
-		go saveAllCookies(Conf.cookiesUrl)
+		go saveAllCookies(c.cookiesUrl)
 	}
 	return nil
 }
+
+// IsOwner reports whether id is one of the bot owners.
+func IsOwner(id int64) bool {
+	return containsInt(Get().OwnerIds, id)
+}
+
+// ParseAliases parses COMMAND_ALIASES, a comma-separated list of alias=command pairs, into a map from
+// lower-cased alias to command. A leading slash on either side is ignored.
+func ParseAliases(s string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		alias, command, ok := strings.Cut(pair, "=")
+		alias = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(alias), "/"))
+		command = strings.TrimPrefix(strings.TrimSpace(command), "/")
+		if !ok || !commandName(alias) || !commandName(command) {
+			return nil, fmt.Errorf("%q is not an alias=command pair", pair)
+		}
+		if prev, dup := aliases[alias]; dup && !strings.EqualFold(prev, command) {
+			return nil, fmt.Errorf("alias %q is given for both %q and %q", alias, prev, command)
+		}
+		aliases[alias] = command
+	}
+	return aliases, nil
+}
+
+// ChannelRef parses a channel given as @username, t.me link or -100 chat ID into the username or the ID.
+func ChannelRef(s string) (any, bool) {
+	s = strings.TrimSpace(s)
+	if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return id, id < 0
+	}
+	for _, prefix := range []string{"https://", "http://", "t.me/", "@"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	if len(s) < 5 || len(s) > 32 || !commandName(s) {
+		return nil, false
+	}
+	return s, true
+}
+
+// commandName reports whether s can be a Telegram command: letters, digits and underscores.
+func commandName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r != '_' && (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// ReportChatID returns the chat errors and user reports are forwarded to: the support chat if set, else the logger group.
+func ReportChatID() int64 {
+	if c := Get(); c.SupportChatId != 0 {
+		return c.SupportChatId
+	}
+	return Get().LoggerId
+}
@@ -0,0 +1,420 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the layout of the optional YAML file named by CONFIG_FILE.
+// Every value maps to the environment variable documented next to it; see config.sample.yaml.
+type fileConfig struct {
+	Profile  string            `yaml:"profile"`  // PROFILE
+	Language string            `yaml:"language"` // DEFAULT_LANG
+	Aliases  map[string]string `yaml:"aliases"`  // COMMAND_ALIASES
+	Telegram struct {
+		ApiId         *int64  `yaml:"api_id"`          // API_ID
+		ApiHash       string  `yaml:"api_hash"`        // API_HASH
+		Token         string  `yaml:"token"`           // TOKEN
+		Owners        []int64 `yaml:"owners"`          // OWNER_ID
+		LoggerId      *int64  `yaml:"logger_id"`       // LOGGER_ID
+		SupportChatId *int64  `yaml:"support_chat_id"` // SUPPORT_CHAT_ID
+	} `yaml:"telegram"`
+	Devs       []int64 `yaml:"devs"` // DEVS
+	Assistants struct {
+		SessionType string   `yaml:"session_type"` // SESSION_TYPE
+		Sessions    []string `yaml:"sessions"`     // STRING1-10
+	} `yaml:"assistants"`
+	Database struct {
+		MongoUri string `yaml:"mongo_uri"` // MONGO_URI
+		Name     string `yaml:"name"`      // DB_NAME
+	} `yaml:"database"`
+	Api struct {
+		Url string `yaml:"url"` // API_URL
+		Key string `yaml:"key"` // API_KEY
+	} `yaml:"api"`
+	Platforms struct {
+		Default string `yaml:"default"` // DEFAULT_SERVICE
+	} `yaml:"platforms"`
+	Downloads struct {
+		Dir           string   `yaml:"dir"`            // DOWNLOADS_DIR
+		Layout        string   `yaml:"layout"`         // DOWNLOADS_LAYOUT
+		MaxConcurrent *int64   `yaml:"max_concurrent"` // MAX_CONCURRENT_DOWNLOADS
+		RateLimit     *int64   `yaml:"rate_limit"`     // DOWNLOAD_RATE_LIMIT
+		TempBudget    *int64   `yaml:"temp_budget"`    // TEMP_SPACE_BUDGET
+		Proxy         string   `yaml:"proxy"`          // PROXY
+		CookiesUrls   []string `yaml:"cookies_urls"`   // COOKIES_URL
+		LowBandwidth  struct {
+			Hours string `yaml:"hours"` // LOW_BANDWIDTH_HOURS
+			Rate  *int64 `yaml:"rate"`  // LOW_BANDWIDTH_RATE
+		} `yaml:"low_bandwidth"`
+	} `yaml:"downloads"`
+	Limits struct {
+		MaxFileSize       *int64 `yaml:"max_file_size"`       // MAX_FILE_SIZE
+		SongDuration      *int64 `yaml:"song_duration"`       // SONG_DURATION_LIMIT
+		VideoHeight       *int64 `yaml:"video_height"`        // VIDEO_MAX_HEIGHT
+		MaxPlaylists      *int64 `yaml:"max_playlists"`       // MAX_PLAYLISTS
+		MaxQueueLength    *int64 `yaml:"max_queue_length"`    // MAX_QUEUE_LENGTH
+		MaxRadioChats     *int64 `yaml:"max_radio_chats"`     // MAX_RADIO_CHATS
+		MaxActiveCalls    *int64 `yaml:"max_active_calls"`    // MAX_ACTIVE_CALLS
+		DuplicateWindow   *int64 `yaml:"duplicate_window"`    // DUPLICATE_WINDOW
+		ApprovalTimeout   *int64 `yaml:"approval_timeout"`    // APPROVAL_TIMEOUT
+		ApprovalPending   *int64 `yaml:"approval_pending"`    // APPROVAL_MAX_PENDING
+		AutoDeleteDelay   *int64 `yaml:"auto_delete_delay"`   // AUTO_DELETE_DELAY
+		IdleLeaveTimeout  *int64 `yaml:"idle_leave_timeout"`  // IDLE_LEAVE_TIMEOUT
+		AloneLeaveTimeout *int64 `yaml:"alone_leave_timeout"` // ALONE_LEAVE_TIMEOUT
+		AutoPauseAfter    *int64 `yaml:"auto_pause_after"`    // AUTO_PAUSE_AFTER
+		ReapIdleAfter     *int64 `yaml:"reap_idle_after"`     // REAP_IDLE_AFTER
+		MaxFollows        *int64 `yaml:"max_follows"`         // MAX_FOLLOWS
+		MaxChatFollows    *int64 `yaml:"max_chat_follows"`    // MAX_CHAT_FOLLOWS
+		FollowPoll        *int64 `yaml:"follow_poll"`         // FOLLOW_POLL_INTERVAL
+	} `yaml:"limits"`
+	Playback struct {
+		GaplessPreload   *bool  `yaml:"gapless_preload"`    // GAPLESS_PRELOAD
+		AutoResume       *bool  `yaml:"auto_resume"`        // AUTO_RESUME
+		ResumeStaleAfter *int64 `yaml:"resume_stale_after"` // RESUME_STALE_AFTER
+		ShutdownGrace    *int64 `yaml:"shutdown_grace"`     // SHUTDOWN_GRACE
+	} `yaml:"playback"`
+	Support struct {
+		Group    string `yaml:"group"`     // SUPPORT_GROUP
+		Channel  string `yaml:"channel"`   // SUPPORT_CHANNEL
+		ForceSub string `yaml:"force_sub"` // FORCE_SUB_CHANNEL
+	} `yaml:"support"`
+	Logging struct {
+		Format string            `yaml:"format"` // LOG_FORMAT
+		Level  string            `yaml:"level"`  // LOG_LEVEL
+		Levels map[string]string `yaml:"levels"` // LOG_LEVELS
+		File   struct {
+			Path        string `yaml:"path"`          // LOG_FILE
+			MaxSize     *int64 `yaml:"max_size"`      // LOG_FILE_MAX_SIZE
+			MaxBackups  *int64 `yaml:"max_backups"`   // LOG_FILE_MAX_BACKUPS
+			MaxAge      *int64 `yaml:"max_age"`       // LOG_FILE_MAX_AGE
+			Compress    *bool  `yaml:"compress"`      // LOG_FILE_COMPRESS
+			SyncOnError *bool  `yaml:"sync_on_error"` // LOG_SYNC_ON_ERROR
+		} `yaml:"file"`
+		SlowThreshold *int64 `yaml:"slow_threshold"` // SLOW_OP_THRESHOLD
+		SampleBurst   *int64 `yaml:"sample_burst"`   // LOG_SAMPLE_BURST
+		Tracing       *bool  `yaml:"tracing"`        // TRACING
+		DigestTime    string `yaml:"digest_time"`    // DIGEST_TIME
+	} `yaml:"logging"`
+	Debug struct {
+		Addr  string `yaml:"addr"`  // DEBUG_ADDR
+		Token string `yaml:"token"` // DEBUG_TOKEN
+	} `yaml:"debug"`
+	Web struct {
+		Addr string `yaml:"addr"` // PUBLIC_ADDR
+	} `yaml:"web"`
+	Watchdog struct {
+		Interval   *int64 `yaml:"interval"`    // WATCHDOG_INTERVAL
+		Failures   *int64 `yaml:"failures"`    // WATCHDOG_FAILURES
+		StuckAfter *int64 `yaml:"stuck_after"` // WATCHDOG_STUCK_AFTER
+		IdleAfter  *int64 `yaml:"idle_after"`  // WATCHDOG_IDLE_AFTER
+		Restart    *bool  `yaml:"restart"`     // WATCHDOG_RESTART
+	} `yaml:"watchdog"`
+	Dispatch struct {
+		Workers *int64 `yaml:"workers"` // DISPATCH_WORKERS
+		Queue   *int64 `yaml:"queue"`   // DISPATCH_QUEUE
+	} `yaml:"dispatch"`
+	Broadcast struct {
+		Workers *int64 `yaml:"workers"` // BROADCAST_WORKERS
+	} `yaml:"broadcast"`
+	SelfTest struct {
+		Strict *bool `yaml:"strict"` // SELFTEST_STRICT
+	} `yaml:"selftest"`
+	Cards struct {
+		Enabled  *bool  `yaml:"enabled"`  // THUMB_CARDS
+		Fallback *bool  `yaml:"fallback"` // THUMB_FALLBACK
+		Font     string `yaml:"font"`     // THUMB_FONT
+	} `yaml:"cards"`
+	Features struct {
+		Video       *bool `yaml:"video"`        // FEATURE_VIDEO
+		Broadcasts  *bool `yaml:"broadcasts"`   // FEATURE_BROADCASTS
+		Playlists   *bool `yaml:"playlists"`    // FEATURE_PLAYLISTS
+		Radio       *bool `yaml:"radio"`        // FEATURE_RADIO
+		QueueIO     *bool `yaml:"queue_io"`     // FEATURE_QUEUE_IO
+		VoiceSearch *bool `yaml:"voice_search"` // FEATURE_VOICE_SEARCH
+	} `yaml:"features"`
+	Premium struct {
+		ProviderToken string `yaml:"provider_token"` // PAYMENT_PROVIDER_TOKEN
+		Currency      string `yaml:"currency"`       // PREMIUM_CURRENCY
+		Price         *int64 `yaml:"price"`          // PREMIUM_PRICE
+		Days          *int64 `yaml:"days"`           // PREMIUM_DAYS
+		SongDuration  *int64 `yaml:"song_duration"`  // PREMIUM_SONG_DURATION
+		VideoHeight   *int64 `yaml:"video_height"`   // PREMIUM_VIDEO_HEIGHT
+		Playlists     *int64 `yaml:"playlists"`      // PREMIUM_PLAYLISTS
+	} `yaml:"premium"`
+	Speech struct {
+		WhisperBin   string `yaml:"whisper_bin"`   // WHISPER_BIN
+		WhisperModel string `yaml:"whisper_model"` // WHISPER_MODEL
+		APIURL       string `yaml:"api_url"`       // STT_API_URL
+		APIKey       string `yaml:"api_key"`       // STT_API_KEY
+		Model        string `yaml:"model"`         // STT_MODEL
+		Language     string `yaml:"language"`      // STT_LANGUAGE
+	} `yaml:"speech"`
+	TTS struct {
+		Bin    string `yaml:"bin"`     // TTS_BIN
+		Voice  string `yaml:"voice"`   // TTS_VOICE
+		APIURL string `yaml:"api_url"` // TTS_API_URL
+		APIKey string `yaml:"api_key"` // TTS_API_KEY
+		Model  string `yaml:"model"`   // TTS_MODEL
+	} `yaml:"tts"`
+}
+
+// env flattens the file into the environment variables its values stand for. Unset values are left out.
+func (f *fileConfig) env() map[string]string {
+	env := make(map[string]string)
+	str := func(key, val string) {
+		if val != "" {
+			env[key] = val
+		}
+	}
+	num := func(key string, val *int64) {
+		if val != nil {
+			env[key] = strconv.FormatInt(*val, 10)
+		}
+	}
+	flag := func(key string, val *bool) {
+		if val != nil {
+			env[key] = strconv.FormatBool(*val)
+		}
+	}
+
+	str("PROFILE", f.Profile)
+	num("API_ID", f.Telegram.ApiId)
+	str("API_HASH", f.Telegram.ApiHash)
+	str("TOKEN", f.Telegram.Token)
+	ids := func(key string, val []int64) {
+		if len(val) > 0 {
+			parts := make([]string, len(val))
+			for i, id := range val {
+				parts[i] = strconv.FormatInt(id, 10)
+			}
+			env[key] = strings.Join(parts, " ")
+		}
+	}
+
+	ids("OWNER_ID", f.Telegram.Owners)
+	num("LOGGER_ID", f.Telegram.LoggerId)
+	num("SUPPORT_CHAT_ID", f.Telegram.SupportChatId)
+	ids("DEVS", f.Devs)
+
+	str("SESSION_TYPE", f.Assistants.SessionType)
+	for i, session := range f.Assistants.Sessions {
+		str(fmt.Sprintf("STRING%d", i+1), session)
+	}
+
+	str("MONGO_URI", f.Database.MongoUri)
+	str("DB_NAME", f.Database.Name)
+	str("API_URL", f.Api.Url)
+	str("API_KEY", f.Api.Key)
+	str("DEFAULT_SERVICE", f.Platforms.Default)
+	str("DEFAULT_LANG", f.Language)
+	if len(f.Aliases) > 0 {
+		var parts []string
+		for alias, command := range f.Aliases {
+			parts = append(parts, alias+"="+command)
+		}
+		sort.Strings(parts)
+		env["COMMAND_ALIASES"] = strings.Join(parts, ",")
+	}
+
+	str("DOWNLOADS_DIR", f.Downloads.Dir)
+	str("DOWNLOADS_LAYOUT", f.Downloads.Layout)
+	num("MAX_CONCURRENT_DOWNLOADS", f.Downloads.MaxConcurrent)
+	num("DOWNLOAD_RATE_LIMIT", f.Downloads.RateLimit)
+	num("TEMP_SPACE_BUDGET", f.Downloads.TempBudget)
+	str("LOW_BANDWIDTH_HOURS", f.Downloads.LowBandwidth.Hours)
+	num("LOW_BANDWIDTH_RATE", f.Downloads.LowBandwidth.Rate)
+	str("PROXY", f.Downloads.Proxy)
+	str("COOKIES_URL", strings.Join(f.Downloads.CookiesUrls, " "))
+
+	num("MAX_FILE_SIZE", f.Limits.MaxFileSize)
+	num("SONG_DURATION_LIMIT", f.Limits.SongDuration)
+	num("VIDEO_MAX_HEIGHT", f.Limits.VideoHeight)
+	num("MAX_PLAYLISTS", f.Limits.MaxPlaylists)
+	num("MAX_QUEUE_LENGTH", f.Limits.MaxQueueLength)
+	num("MAX_RADIO_CHATS", f.Limits.MaxRadioChats)
+	num("MAX_FOLLOWS", f.Limits.MaxFollows)
+	num("MAX_CHAT_FOLLOWS", f.Limits.MaxChatFollows)
+	num("FOLLOW_POLL_INTERVAL", f.Limits.FollowPoll)
+	num("MAX_ACTIVE_CALLS", f.Limits.MaxActiveCalls)
+	num("DUPLICATE_WINDOW", f.Limits.DuplicateWindow)
+	num("APPROVAL_TIMEOUT", f.Limits.ApprovalTimeout)
+	num("APPROVAL_MAX_PENDING", f.Limits.ApprovalPending)
+	num("AUTO_DELETE_DELAY", f.Limits.AutoDeleteDelay)
+	num("IDLE_LEAVE_TIMEOUT", f.Limits.IdleLeaveTimeout)
+	num("ALONE_LEAVE_TIMEOUT", f.Limits.AloneLeaveTimeout)
+	num("AUTO_PAUSE_AFTER", f.Limits.AutoPauseAfter)
+	num("REAP_IDLE_AFTER", f.Limits.ReapIdleAfter)
+
+	flag("GAPLESS_PRELOAD", f.Playback.GaplessPreload)
+	flag("AUTO_RESUME", f.Playback.AutoResume)
+	num("RESUME_STALE_AFTER", f.Playback.ResumeStaleAfter)
+	num("SHUTDOWN_GRACE", f.Playback.ShutdownGrace)
+
+	str("SUPPORT_GROUP", f.Support.Group)
+	str("SUPPORT_CHANNEL", f.Support.Channel)
+	str("FORCE_SUB_CHANNEL", f.Support.ForceSub)
+
+	str("LOG_FORMAT", f.Logging.Format)
+	str("LOG_LEVEL", f.Logging.Level)
+	if len(f.Logging.Levels) > 0 {
+		var parts []string
+		for module, level := range f.Logging.Levels {
+			parts = append(parts, module+"="+level)
+		}
+		sort.Strings(parts)
+		env["LOG_LEVELS"] = strings.Join(parts, ",")
+	}
+	str("LOG_FILE", f.Logging.File.Path)
+	num("LOG_FILE_MAX_SIZE", f.Logging.File.MaxSize)
+	num("LOG_FILE_MAX_BACKUPS", f.Logging.File.MaxBackups)
+	num("LOG_FILE_MAX_AGE", f.Logging.File.MaxAge)
+	flag("LOG_FILE_COMPRESS", f.Logging.File.Compress)
+	flag("LOG_SYNC_ON_ERROR", f.Logging.File.SyncOnError)
+	num("SLOW_OP_THRESHOLD", f.Logging.SlowThreshold)
+	num("LOG_SAMPLE_BURST", f.Logging.SampleBurst)
+	flag("TRACING", f.Logging.Tracing)
+	str("DIGEST_TIME", f.Logging.DigestTime)
+
+	str("DEBUG_ADDR", f.Debug.Addr)
+	str("DEBUG_TOKEN", f.Debug.Token)
+	str("PUBLIC_ADDR", f.Web.Addr)
+
+	num("WATCHDOG_INTERVAL", f.Watchdog.Interval)
+	num("WATCHDOG_FAILURES", f.Watchdog.Failures)
+	num("WATCHDOG_STUCK_AFTER", f.Watchdog.StuckAfter)
+	num("WATCHDOG_IDLE_AFTER", f.Watchdog.IdleAfter)
+	flag("WATCHDOG_RESTART", f.Watchdog.Restart)
+
+	num("DISPATCH_WORKERS", f.Dispatch.Workers)
+	num("DISPATCH_QUEUE", f.Dispatch.Queue)
+	num("BROADCAST_WORKERS", f.Broadcast.Workers)
+
+	flag("SELFTEST_STRICT", f.SelfTest.Strict)
+
+	flag("THUMB_CARDS", f.Cards.Enabled)
+	flag("THUMB_FALLBACK", f.Cards.Fallback)
+	str("THUMB_FONT", f.Cards.Font)
+
+	flag("FEATURE_VIDEO", f.Features.Video)
+	flag("FEATURE_BROADCASTS", f.Features.Broadcasts)
+	flag("FEATURE_PLAYLISTS", f.Features.Playlists)
+	flag("FEATURE_RADIO", f.Features.Radio)
+	flag("FEATURE_QUEUE_IO", f.Features.QueueIO)
+	flag("FEATURE_VOICE_SEARCH", f.Features.VoiceSearch)
+
+	str("PAYMENT_PROVIDER_TOKEN", f.Premium.ProviderToken)
+	str("PREMIUM_CURRENCY", f.Premium.Currency)
+	num("PREMIUM_PRICE", f.Premium.Price)
+	num("PREMIUM_DAYS", f.Premium.Days)
+	num("PREMIUM_SONG_DURATION", f.Premium.SongDuration)
+	num("PREMIUM_VIDEO_HEIGHT", f.Premium.VideoHeight)
+	num("PREMIUM_PLAYLISTS", f.Premium.Playlists)
+
+	str("WHISPER_BIN", f.Speech.WhisperBin)
+	str("WHISPER_MODEL", f.Speech.WhisperModel)
+	str("STT_API_URL", f.Speech.APIURL)
+	str("STT_API_KEY", f.Speech.APIKey)
+	str("STT_MODEL", f.Speech.Model)
+	str("STT_LANGUAGE", f.Speech.Language)
+
+	str("TTS_BIN", f.TTS.Bin)
+	str("TTS_VOICE", f.TTS.Voice)
+	str("TTS_API_URL", f.TTS.APIURL)
+	str("TTS_API_KEY", f.TTS.APIKey)
+	str("TTS_MODEL", f.TTS.Model)
+	return env
+}
+
+// readConfigFile parses the YAML file at path into environment variable values.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("CONFIG_FILE: cannot read %q: %w", path, err)
+	}
+
+	var f fileConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("CONFIG_FILE: cannot parse %q: %w", path, err)
+	}
+	return f.env(), nil
+}
+
+var (
+	// processEnv is the set of variables the process was started with; they override every file.
+	processEnv map[string]bool
+	// layered holds the variables that were set from .env or the config file, so that reloads can replace them.
+	layered = make(map[string]bool)
+)
+
+// loadLayers sets the environment from the .env file and the CONFIG_FILE file.
+// Variables from the process environment win over .env, which wins over the config file.
+// Values set by an earlier call are replaced, and removed when no layer sets them anymore.
+func loadLayers() error {
+	if processEnv == nil {
+		processEnv = make(map[string]bool)
+		for _, kv := range os.Environ() {
+			key, _, _ := strings.Cut(kv, "=")
+			processEnv[key] = true
+		}
+	}
+
+	dotenv, err := godotenv.Read()
+	if err != nil {
+		dotenv = map[string]string{}
+	}
+
+	values := make(map[string]string)
+	path := os.Getenv("CONFIG_FILE")
+	if !processEnv["CONFIG_FILE"] {
+		if p, ok := dotenv["CONFIG_FILE"]; ok {
+			path = p
+		}
+	}
+	if path != "" {
+		fileValues, err := readConfigFile(path)
+		if err != nil {
+			return err
+		}
+		for key, val := range fileValues {
+			values[key] = val
+		}
+	}
+	for key, val := range dotenv {
+		values[key] = val
+	}
+
+	for key := range layered {
+		if _, ok := values[key]; !ok {
+			_ = os.Unsetenv(key)
+			delete(layered, key)
+		}
+	}
+	for key, val := range values {
+		if processEnv[key] {
+			continue
+		}
+		_ = os.Setenv(key, val)
+		layered[key] = true
+	}
+	return nil
+}
@@ -15,11 +15,19 @@ import (
 	"strings"
 )
 
+// getEnv returns the environment variable key, falling back to the active profile's default.
+func getEnv(key string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return activeProfile[key]
+}
+
 // getEnvStr retrieves a string from an environment variable or returns a default value.
 // It takes the environment variable key and a default string as input.
 // It returns the value of the environment variable if it exists, otherwise it returns the default value.
 func getEnvStr(key, def string) string {
-	val := os.Getenv(key)
+	val := getEnv(key)
 	if val == "" {
 		return def
 	}
@@ -30,12 +38,13 @@ func getEnvStr(key, def string) string {
 // It takes the environment variable key and a default int64 as input.
 // It returns the value of the environment variable if it exists and is a valid int64, otherwise it returns the default value.
 func getEnvInt64(key string, def int64) int64 {
-	val := os.Getenv(key)
+	val := getEnv(key)
 	if val == "" {
 		return def
 	}
 	i, err := strconv.ParseInt(val, 10, 64)
 	if err != nil {
+		addEnvProblem(key, val, "integer")
 		return def
 	}
 	return i
@@ -45,17 +54,52 @@ func getEnvInt64(key string, def int64) int64 {
 // It takes the environment variable key and a default int32 as input.
 // It returns the value of the environment variable if it exists and is a valid int32, otherwise it returns the default value.
 func getEnvInt32(key string, def int32) int32 {
-	val := os.Getenv(key)
+	val := getEnv(key)
 	if val == "" {
 		return def
 	}
 	i, err := strconv.ParseInt(val, 10, 32)
 	if err != nil {
+		addEnvProblem(key, val, "integer")
 		return def
 	}
 	return int32(i)
 }
 
+// getEnvBool retrieves a boolean from an environment variable or returns a default value.
+// It accepts the values understood by strconv.ParseBool.
+func getEnvBool(key string, def bool) bool {
+	val := getEnv(key)
+	if val == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		addEnvProblem(key, val, "boolean")
+		return def
+	}
+	return b
+}
+
+// getEnvIDs retrieves a list of user or chat IDs separated by spaces or commas from an environment variable.
+// It returns def if the variable is unset; entries that are not valid IDs are skipped and reported.
+func getEnvIDs(key string, def []int64) []int64 {
+	val := getEnv(key)
+	if val == "" {
+		return def
+	}
+	var ids []int64
+	for _, idStr := range strings.Fields(strings.ReplaceAll(val, ",", " ")) {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			addEnvProblem(key, idStr, "ID")
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 // getSessionStrings retrieves a list of session strings from environment variables.
 // It takes a prefix and a count as input.
 // It returns a slice of strings containing the session strings.
@@ -97,50 +141,3 @@ func containsInt(list []int64, x int64) bool {
 	}
 	return false
 }
-
-// validate checks if the bot configuration is valid.
-// It returns an error if the configuration is invalid, otherwise it returns nil.
-func (c *BotConfig) validate() error {
-	var missing []string
-	if c.ApiId == 0 {
-		missing = append(missing, "API_ID")
-	}
-	if c.ApiHash == "" {
-		missing = append(missing, "API_HASH")
-	}
-	if c.Token == "" {
-		missing = append(missing, "TOKEN")
-	}
-	if c.MongoUri == "" {
-		missing = append(missing, "MONGO_URI")
-	}
-	if c.LoggerId == 0 {
-		missing = append(missing, "LOGGER_ID")
-	}
-	if c.DbName == "" {
-		missing = append(missing, "DB_NAME")
-	}
-
-	if len(missing) > 0 {
-		return fmt.Errorf("missing required config: %s", strings.Join(missing, ", "))
-	}
-
-	if len(c.SessionStrings) == 0 {
-		return fmt.Errorf("at least one session string (STRING1–10) is required")
-	}
-
-	// Validate session type
-	c.SessionType = strings.ToLower(strings.TrimSpace(c.SessionType))
-	switch c.SessionType {
-	case "pyrogram", "telethon", "gogram":
-		// Valid session type
-	default:
-		return fmt.Errorf("invalid SESSION_TYPE: %s, must be one of: pyrogram, telethon, gogram", c.SessionType)
-	}
-
-	if err := os.MkdirAll(c.DownloadsDir, 0750); err != nil {
-		return fmt.Errorf("failed to create downloads dir: %v", err)
-	}
-
-	return nil
-}
@@ -0,0 +1,79 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// profiles holds the defaults each PROFILE sets, keyed by environment variable.
+// Variables that are set explicitly still override the profile. Add new tunables to every profile;
+// profile_test.go checks that each profile is consistent.
+var profiles = map[string]map[string]string{
+	"small": {
+		"MAX_CONCURRENT_DOWNLOADS": "1",
+		"MAX_ACTIVE_CALLS":         "5",
+		"MAX_QUEUE_LENGTH":         "10",
+		"MAX_RADIO_CHATS":          "2",
+		"MAX_FILE_SIZE":            strconv.Itoa(200 * 1024 * 1024),
+		"IDLE_LEAVE_TIMEOUT":       "60",
+		"GAPLESS_PRELOAD":          "false",
+	},
+	"medium": {
+		"MAX_CONCURRENT_DOWNLOADS": "3",
+		"MAX_ACTIVE_CALLS":         "25",
+		"MAX_QUEUE_LENGTH":         "25",
+		"MAX_RADIO_CHATS":          "10",
+		"MAX_FILE_SIZE":            strconv.Itoa(500 * 1024 * 1024),
+		"IDLE_LEAVE_TIMEOUT":       "180",
+		"GAPLESS_PRELOAD":          "false",
+	},
+	"large": {
+		"MAX_CONCURRENT_DOWNLOADS": "8",
+		"MAX_ACTIVE_CALLS":         "100",
+		"MAX_QUEUE_LENGTH":         "50",
+		"MAX_RADIO_CHATS":          "50",
+		"MAX_FILE_SIZE":            strconv.Itoa(1024 * 1024 * 1024),
+		"IDLE_LEAVE_TIMEOUT":       "300",
+		"GAPLESS_PRELOAD":          "true",
+	},
+}
+
+// activeProfile holds the defaults of the profile being loaded; the getEnv helpers fall back to it.
+var activeProfile map[string]string
+
+// checkProfile makes sure a profile's values fit together.
+func checkProfile(values map[string]string) error {
+	keys := []string{"MAX_CONCURRENT_DOWNLOADS", "MAX_ACTIVE_CALLS", "MAX_QUEUE_LENGTH", "MAX_RADIO_CHATS", "MAX_FILE_SIZE", "IDLE_LEAVE_TIMEOUT"}
+	nums := make(map[string]int64, len(keys))
+	for _, key := range keys {
+		n, err := strconv.ParseInt(values[key], 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a number", key, values[key])
+		}
+		nums[key] = n
+	}
+	if _, err := strconv.ParseBool(values["GAPLESS_PRELOAD"]); err != nil {
+		return fmt.Errorf("GAPLESS_PRELOAD: %q is not a boolean", values["GAPLESS_PRELOAD"])
+	}
+
+	if nums["MAX_CONCURRENT_DOWNLOADS"] < 1 {
+		return fmt.Errorf("MAX_CONCURRENT_DOWNLOADS must be at least 1")
+	}
+	if calls := nums["MAX_ACTIVE_CALLS"]; calls > 0 {
+		if nums["MAX_CONCURRENT_DOWNLOADS"] > calls {
+			return fmt.Errorf("MAX_CONCURRENT_DOWNLOADS exceeds MAX_ACTIVE_CALLS")
+		}
+		if nums["MAX_RADIO_CHATS"] > calls {
+			return fmt.Errorf("MAX_RADIO_CHATS exceeds MAX_ACTIVE_CALLS")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package config
+
+import "testing"
+
+func TestProfilesAreConsistent(t *testing.T) {
+	for name, values := range profiles {
+		if err := checkProfile(values); err != nil {
+			t.Errorf("profile %q is inconsistent: %v", name, err)
+		}
+	}
+}
+
+func TestCheckProfile(t *testing.T) {
+	base := func() map[string]string {
+		values := make(map[string]string)
+		for k, v := range profiles["small"] {
+			values[k] = v
+		}
+		return values
+	}
+	tests := []struct {
+		name       string
+		key, value string
+	}{
+		{"not a number", "MAX_ACTIVE_CALLS", "many"},
+		{"not a boolean", "GAPLESS_PRELOAD", "maybe"},
+		{"no downloads", "MAX_CONCURRENT_DOWNLOADS", "0"},
+		{"more downloads than calls", "MAX_CONCURRENT_DOWNLOADS", "6"},
+		{"more radio chats than calls", "MAX_RADIO_CHATS", "6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := base()
+			values[tt.key] = tt.value
+			if checkProfile(values) == nil {
+				t.Errorf("checkProfile accepted %s=%q", tt.key, tt.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package config
+
+import (
+	"bytes"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// redacted replaces secrets removed by Redact.
+const redacted = "[REDACTED]"
+
+// redactor caches the replacer built for one configuration snapshot.
+type redactor struct {
+	conf     *BotConfig
+	replacer *strings.Replacer
+}
+
+var currentRedactor atomic.Pointer[redactor]
+
+// secrets lists the values Redact hides for c: credentials, URLs carrying credentials and cookie files.
+func (c *BotConfig) secrets() []string {
+	values := []string{c.Token, c.ApiHash, c.ApiKey, c.MongoUri, c.Proxy, c.DebugToken, c.Premium.ProviderToken, c.Speech.APIKey, c.TTS.APIKey}
+	if _, secret, ok := strings.Cut(c.Token, ":"); ok {
+		values = append(values, secret)
+	}
+	values = append(values, c.SessionStrings...)
+	values = append(values, c.cookiesUrl...)
+	for _, raw := range []string{c.MongoUri, c.Proxy} {
+		if u, err := url.Parse(raw); err == nil && u.User != nil {
+			if password, ok := u.User.Password(); ok {
+				values = append(values, password)
+			}
+		}
+	}
+	for _, path := range c.CookiesPath {
+		values = append(values, path)
+		if abs, err := filepath.Abs(path); err == nil {
+			values = append(values, abs)
+		}
+	}
+
+	// Short values would mask unrelated text, and longer values must be replaced before their substrings.
+	var kept []string
+	for _, v := range values {
+		if len(v) >= 6 {
+			kept = append(kept, v)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return len(kept[i]) > len(kept[j]) })
+	return kept
+}
+
+// Redact masks every configured secret in s: the bot token, API hash and key, session strings,
+// database and proxy URLs, and the cookie files and their URLs.
+func Redact(s string) string {
+	c := Get()
+	if c == nil || s == "" {
+		return s
+	}
+
+	r := currentRedactor.Load()
+	if r == nil || r.conf != c {
+		var pairs []string
+		for _, secret := range c.secrets() {
+			pairs = append(pairs, secret, redacted)
+		}
+		r = &redactor{conf: c, replacer: strings.NewReplacer(pairs...)}
+		currentRedactor.Store(r)
+	}
+	return r.replacer.Replace(s)
+}
+
+// redactedError keeps the wrapped error for errors.Is and errors.As while hiding secrets in its message.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// RedactError returns err with every configured secret masked in its message.
+func RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if clean := Redact(msg); clean != msg {
+		return &redactedError{msg: clean, err: err}
+	}
+	return err
+}
+
+// redactWriter masks secrets in everything written through it. It passes on whole lines only,
+// so that a secret split across two writes is still caught.
+type redactWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf []byte
+}
+
+// NewRedactWriter returns a writer that masks configured secrets before writing to w.
+func NewRedactWriter(w io.Writer) io.Writer {
+	return &redactWriter{w: w}
+}
+
+func (r *redactWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf = append(r.buf, p...)
+	end := bytes.LastIndexByte(r.buf, '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+	lines := string(r.buf[:end+1])
+	r.buf = append(r.buf[:0], r.buf[end+1:]...)
+	if _, err := io.WriteString(r.w, Redact(lines)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,120 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+)
+
+// immutableEnv maps the fields that only take effect on restart to their environment variables.
+var immutableEnv = map[string]string{
+	"ApiId":          "API_ID",
+	"ApiHash":        "API_HASH",
+	"Token":          "TOKEN",
+	"SessionStrings": "STRING1-10",
+	"SessionType":    "SESSION_TYPE",
+	"MongoUri":       "MONGO_URI",
+	"DbName":         "DB_NAME",
+	// The layout is only migrated at startup.
+	"DownloadsLayout": "DOWNLOADS_LAYOUT",
+	// The tracer and the debug and public servers are only started at startup.
+	"Tracing":    "TRACING",
+	"DebugAddr":  "DEBUG_ADDR",
+	"PublicAddr": "PUBLIC_ADDR",
+	// The dispatcher's pool is sized once when the handlers are loaded.
+	"DispatchWorkers": "DISPATCH_WORKERS",
+	"DispatchQueue":   "DISPATCH_QUEUE",
+	// Aliases are registered as commands when the handlers are loaded.
+	"Aliases": "COMMAND_ALIASES",
+	// The card fonts are parsed once, on the first card.
+	"CardFont": "THUMB_FONT",
+}
+
+// reloadHooks run after every successful reload with the new configuration.
+var reloadHooks []func(c *BotConfig)
+
+// OnReload registers fn to apply reloaded values that were copied out of the configuration, such as pool sizes.
+func OnReload(fn func(c *BotConfig)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHooks = append(reloadHooks, fn)
+}
+
+// ReloadResult describes what a reload changed.
+type ReloadResult struct {
+	Changed []string // Changed lists the reloadable fields that now have a new value.
+	Ignored []string // Ignored lists the environment variables that changed but need a restart.
+}
+
+// Reload re-reads the .env file, the config file and the environment and swaps in the new configuration.
+// Fields that need a restart keep their current value and are reported in ReloadResult.Ignored.
+// Nothing changes if the new configuration has fatal problems.
+func Reload() (*ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	old := Get()
+	if err := loadLayers(); err != nil {
+		return nil, err
+	}
+	next := readEnv()
+	if err := checked(next); err != nil {
+		return nil, err
+	}
+
+	res := &ReloadResult{}
+	oldVal := reflect.ValueOf(old).Elem()
+	nextVal := reflect.ValueOf(next).Elem()
+	for i := 0; i < oldVal.NumField(); i++ {
+		field := oldVal.Type().Field(i)
+		if !field.IsExported() || field.Name == "CookiesPath" {
+			continue
+		}
+		if reflect.DeepEqual(oldVal.Field(i).Interface(), nextVal.Field(i).Interface()) {
+			continue
+		}
+		if env, ok := immutableEnv[field.Name]; ok {
+			nextVal.Field(i).Set(oldVal.Field(i))
+			res.Ignored = append(res.Ignored, env)
+			continue
+		}
+		res.Changed = append(res.Changed, field.Name)
+	}
+
+	cookiesChanged := !slices.Equal(old.cookiesUrl, next.cookiesUrl)
+	if !cookiesChanged {
+		next.CookiesPath = old.CookiesPath
+	} else {
+		res.Changed = append(res.Changed, "CookiesPath")
+	}
+	current.Store(next)
+	for _, hook := range reloadHooks {
+		hook(next)
+	}
+
+	if cookiesChanged && len(next.cookiesUrl) > 0 {
+		if err := os.MkdirAll(tmpDir, 0750); err != nil {
+			return res, fmt.Errorf("failed to create temp dir: %w", err)
+		}
+		go saveAllCookies(next.cookiesUrl)
+	}
+	return res, nil
+}
+
+// update applies fn to a copy of the active configuration and makes the copy active.
+func update(fn func(c *BotConfig)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	next := *Get()
+	fn(&next)
+	current.Store(&next)
+}
@@ -83,9 +83,10 @@ func saveContent(url, content string) (string, error) {
 	return filePath, nil
 }
 
-// saveAllCookies downloads all URLs and stores paths in Conf.CookiesPath.
+// saveAllCookies downloads all URLs and stores their paths in the active configuration's CookiesPath.
 // It takes a slice of URLs as input.
 func saveAllCookies(urls []string) {
+	var paths []string
 	for _, url := range urls {
 		content, err := fetchContent(url)
 		if err != nil {
@@ -99,6 +100,10 @@ func saveAllCookies(urls []string) {
 			continue
 		}
 
-		Conf.CookiesPath = append(Conf.CookiesPath, path)
+		paths = append(paths, path)
 	}
+
+	update(func(c *BotConfig) {
+		c.CookiesPath = paths
+	})
 }
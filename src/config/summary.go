@@ -0,0 +1,86 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// secretFields are masked when the configuration is printed.
+var secretFields = map[string]bool{
+	"ApiHash":        true,
+	"Token":          true,
+	"SessionStrings": true,
+	"ApiKey":         true,
+}
+
+// mask hides all but the last four characters of a secret.
+func mask(s string) string {
+	if s == "" {
+		return ""
+	}
+	if len(s) <= 8 {
+		return "****"
+	}
+	return "****" + s[len(s)-4:]
+}
+
+// maskURL hides the password of a URL with credentials, such as a MongoDB URI or an authenticated proxy.
+// A value that does not parse as a URL is masked whole, as it may still hold a password.
+func maskURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return mask(raw)
+	}
+	if u.User == nil {
+		return raw
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
+
+// Summary lists every exported setting, one per line, with secrets masked.
+func (c *BotConfig) Summary() string {
+	var b strings.Builder
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		var value string
+		switch val := v.Field(i).Interface().(type) {
+		case string:
+			switch {
+			case secretFields[field.Name]:
+				value = mask(val)
+			case field.Name == "MongoUri" || field.Name == "Proxy":
+				value = maskURL(val)
+			default:
+				value = val
+			}
+		case []string:
+			if secretFields[field.Name] {
+				value = fmt.Sprintf("[%d set]", len(val))
+			} else {
+				value = fmt.Sprint(val)
+			}
+		default:
+			value = fmt.Sprintf("%+v", val)
+		}
+		fmt.Fprintf(&b, "  %s = %s\n", field.Name, value)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,318 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core/logging"
+)
+
+// Problem is a configuration issue tied to the environment variable that fixes it.
+type Problem struct {
+	Env     string // Env is the environment variable to change.
+	Message string // Message explains what is wrong and how to fix it.
+	Fatal   bool   // Fatal problems stop the bot from starting; the others are only logged.
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Env, p.Message)
+}
+
+// ValidationError lists every fatal configuration problem found at startup.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration, fix the following and restart:")
+	for _, p := range e.Problems {
+		b.WriteString("\n  - " + p.String())
+	}
+	return b.String()
+}
+
+// envProblems collects values that were set but could not be parsed while the config was loaded.
+var envProblems []Problem
+
+// addEnvProblem records an environment variable whose value was ignored in favour of its default.
+func addEnvProblem(key, val, kind string) {
+	envProblems = append(envProblems, Problem{
+		Env:     key,
+		Message: fmt.Sprintf("%q is not a valid %s; the default is used instead", val, kind),
+	})
+}
+
+var tokenRegex = regexp.MustCompile(`^\d+:[A-Za-z0-9_-]{30,}$`)
+
+// Validate checks the configuration and returns every problem found, fatal or not.
+// It normalizes SESSION_TYPE and creates DOWNLOADS_DIR if it does not exist.
+func (c *BotConfig) Validate() []Problem {
+	problems := append([]Problem(nil), envProblems...)
+	fatal := func(env, format string, args ...any) {
+		problems = append(problems, Problem{Env: env, Message: fmt.Sprintf(format, args...), Fatal: true})
+	}
+	warn := func(env, format string, args ...any) {
+		problems = append(problems, Problem{Env: env, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if _, ok := profiles[c.Profile]; c.Profile != "" && !ok {
+		fatal("PROFILE", "%q is not a known profile; use small, medium or large, or leave it empty", c.Profile)
+	}
+	if c.MaxActiveCalls > 0 && c.MaxDownloads > c.MaxActiveCalls {
+		warn("MAX_CONCURRENT_DOWNLOADS", "is higher than MAX_ACTIVE_CALLS (%d); downloads beyond the playback cap only wait for a slot", c.MaxActiveCalls)
+	}
+
+	if c.DownloadRate < 0 {
+		fatal("DOWNLOAD_RATE_LIMIT", "must be 0 (unlimited) or a number of bytes per second")
+	}
+	if c.MaxFollows < 0 {
+		fatal("MAX_FOLLOWS", "must be 0 (no cap) or a positive number")
+	}
+	if c.MaxChatFollows < 1 {
+		fatal("MAX_CHAT_FOLLOWS", "must be at least 1")
+	}
+	if c.FollowPoll < 300 {
+		fatal("FOLLOW_POLL_INTERVAL", "must be at least 300 seconds, or YouTube may start throttling")
+	}
+	if c.TempBudget < 0 {
+		fatal("TEMP_SPACE_BUDGET", "must be 0 (unlimited) or a number of bytes")
+	}
+	if c.LowBandwidthRate < 0 {
+		fatal("LOW_BANDWIDTH_RATE", "must be 0 (unlimited) or a number of bytes per second")
+	}
+	if c.LowBandwidthHours != "" {
+		if _, _, err := parseClockWindow(c.LowBandwidthHours); err != nil {
+			fatal("LOW_BANDWIDTH_HOURS", "%v", err)
+		} else if c.LowBandwidthRate == 0 {
+			warn("LOW_BANDWIDTH_HOURS", "is set but LOW_BANDWIDTH_RATE is 0, so the window changes nothing")
+		}
+	}
+
+	switch c.LogFormat {
+	case "text", "json":
+	default:
+		fatal("LOG_FORMAT", "%q is not supported; use text or json", c.LogFormat)
+	}
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		fatal("LOG_LEVEL", "%v", err)
+	}
+	if _, err := logging.ParseOverrides(c.LogLevels); err != nil {
+		fatal("LOG_LEVELS", "%v", err)
+	}
+	if _, err := ParseAliases(c.Aliases); err != nil {
+		fatal("COMMAND_ALIASES", "%v", err)
+	}
+	if _, ok := ChannelRef(c.ForceSub); c.ForceSub != "" && !ok {
+		fatal("FORCE_SUB_CHANNEL", "%q is not a channel; use @username or the channel's -100 ID", c.ForceSub)
+	}
+	if c.ApprovalTimeout < 1 {
+		fatal("APPROVAL_TIMEOUT", "must be at least 1 (second), got %d", c.ApprovalTimeout)
+	}
+	if c.ApprovalPending < 1 {
+		fatal("APPROVAL_MAX_PENDING", "must be at least 1, got %d", c.ApprovalPending)
+	}
+	if c.VideoHeight < 144 || c.VideoHeight > 2160 {
+		fatal("VIDEO_MAX_HEIGHT", "must be between 144 and 2160, got %d", c.VideoHeight)
+	}
+	if c.MaxPlaylists < 1 {
+		fatal("MAX_PLAYLISTS", "must be at least 1, got %d", c.MaxPlaylists)
+	}
+	if c.Premium.Price < 0 {
+		fatal("PREMIUM_PRICE", "must not be negative, got %d", c.Premium.Price)
+	}
+	if c.Premium.Price > 0 && c.Premium.Currency != "XTR" && c.Premium.ProviderToken == "" {
+		fatal("PAYMENT_PROVIDER_TOKEN", "is required to charge in %s; leave PREMIUM_CURRENCY at XTR to charge Telegram Stars", c.Premium.Currency)
+	}
+	if c.Premium.Days < 1 {
+		fatal("PREMIUM_DAYS", "must be at least 1, got %d", c.Premium.Days)
+	}
+	if c.Premium.SongDuration < 1 {
+		fatal("PREMIUM_SONG_DURATION", "must be at least 1 (second), got %d", c.Premium.SongDuration)
+	}
+	if c.Premium.VideoHeight < 144 || c.Premium.VideoHeight > 2160 {
+		fatal("PREMIUM_VIDEO_HEIGHT", "must be between 144 and 2160, got %d", c.Premium.VideoHeight)
+	}
+	if c.Premium.Playlists < 1 {
+		fatal("PREMIUM_PLAYLISTS", "must be at least 1, got %d", c.Premium.Playlists)
+	}
+	if (c.Speech.WhisperBin == "") != (c.Speech.WhisperModel == "") {
+		fatal("WHISPER_MODEL", "WHISPER_BIN and WHISPER_MODEL must be set together")
+	}
+	if c.Speech.APIURL != "" && !strings.HasPrefix(c.Speech.APIURL, "http://") && !strings.HasPrefix(c.Speech.APIURL, "https://") {
+		fatal("STT_API_URL", "must be an http(s) URL, got %q", c.Speech.APIURL)
+	}
+	if c.TTS.Piper() && c.TTS.Voice == "" {
+		fatal("TTS_VOICE", "must name the piper model when TTS_BIN is piper")
+	}
+	if c.TTS.APIURL != "" && !strings.HasPrefix(c.TTS.APIURL, "http://") && !strings.HasPrefix(c.TTS.APIURL, "https://") {
+		fatal("TTS_API_URL", "must be an http(s) URL, got %q", c.TTS.APIURL)
+	}
+	if c.SlowOpThreshold < 0 {
+		fatal("SLOW_OP_THRESHOLD", "must not be negative, got %d", c.SlowOpThreshold)
+	}
+	if c.WatchdogInterval < 0 {
+		fatal("WATCHDOG_INTERVAL", "must not be negative, got %d", c.WatchdogInterval)
+	}
+	if c.WatchdogFailures < 1 {
+		fatal("WATCHDOG_FAILURES", "must be at least 1, got %d", c.WatchdogFailures)
+	}
+	if c.WatchdogStuck < 1 {
+		fatal("WATCHDOG_STUCK_AFTER", "must be at least 1 (second), got %d", c.WatchdogStuck)
+	}
+	if c.WatchdogIdleAfter < 0 {
+		fatal("WATCHDOG_IDLE_AFTER", "must not be negative, got %d", c.WatchdogIdleAfter)
+	}
+	if c.DebugAddr != "" && len(c.DebugToken) < 16 {
+		fatal("DEBUG_TOKEN", "must be at least 16 characters long when DEBUG_ADDR is set")
+	}
+	if c.PublicAddr != "" && c.PublicAddr == c.DebugAddr {
+		fatal("PUBLIC_ADDR", "must differ from DEBUG_ADDR; the public pages are served on their own server")
+	}
+	if c.ReapIdleAfter < 0 {
+		fatal("REAP_IDLE_AFTER", "must not be negative, got %d", c.ReapIdleAfter)
+	}
+	if c.DispatchWorkers < 1 {
+		fatal("DISPATCH_WORKERS", "must be at least 1, got %d", c.DispatchWorkers)
+	}
+	if c.DispatchQueue < 1 {
+		fatal("DISPATCH_QUEUE", "must be at least 1, got %d", c.DispatchQueue)
+	}
+	if c.BroadcastWorkers < 1 || c.BroadcastWorkers > MaxBroadcastWorkers {
+		fatal("BROADCAST_WORKERS", "must be between 1 and %d, got %d", MaxBroadcastWorkers, c.BroadcastWorkers)
+	}
+	if c.ShutdownGrace < 1 {
+		fatal("SHUTDOWN_GRACE", "must be at least 1 (second), got %d", c.ShutdownGrace)
+	}
+	if c.LogSampleBurst < 0 {
+		fatal("LOG_SAMPLE_BURST", "must not be negative, got %d", c.LogSampleBurst)
+	}
+	if _, err := time.Parse("15:04", c.DigestTime); c.DigestTime != "" && err != nil {
+		fatal("DIGEST_TIME", "%q is not a time of day; use HH:MM, e.g. 09:30, or leave it empty to disable the digest", c.DigestTime)
+	}
+	if c.CardFont != "" {
+		if info, err := os.Stat(c.CardFont); err != nil || info.IsDir() {
+			fatal("THUMB_FONT", "%q is not a font file", c.CardFont)
+		}
+	}
+	if c.LogFile != "" {
+		if err := checkWritableDir(filepath.Dir(c.LogFile)); err != nil {
+			fatal("LOG_FILE", "%v", err)
+		}
+		if c.LogFileMaxSize < 1 {
+			fatal("LOG_FILE_MAX_SIZE", "must be at least 1 (MB), got %d", c.LogFileMaxSize)
+		}
+		if c.LogFileMaxBackups < 0 {
+			fatal("LOG_FILE_MAX_BACKUPS", "must not be negative, got %d", c.LogFileMaxBackups)
+		}
+		if c.LogFileMaxAge < 0 {
+			fatal("LOG_FILE_MAX_AGE", "must not be negative, got %d", c.LogFileMaxAge)
+		}
+	}
+
+	if c.ApiId == 0 {
+		fatal("API_ID", "is required; get it from https://my.telegram.org")
+	}
+	if c.ApiHash == "" {
+		fatal("API_HASH", "is required; get it from https://my.telegram.org")
+	}
+	if c.Token == "" {
+		fatal("TOKEN", "is required; get it from @BotFather")
+	} else if !tokenRegex.MatchString(c.Token) {
+		fatal("TOKEN", "does not look like a bot token (expected <bot id>:<secret> as given by @BotFather)")
+	}
+	if c.MongoUri == "" {
+		fatal("MONGO_URI", "is required")
+	} else if !strings.HasPrefix(c.MongoUri, "mongodb://") && !strings.HasPrefix(c.MongoUri, "mongodb+srv://") {
+		fatal("MONGO_URI", "must start with mongodb:// or mongodb+srv://")
+	}
+	if c.LoggerId == 0 {
+		fatal("LOGGER_ID", "is required; set it to the ID of the group the bot logs to")
+	}
+	if c.DbName == "" {
+		fatal("DB_NAME", "is required")
+	}
+	if len(c.SessionStrings) == 0 {
+		fatal("STRING1", "at least one assistant session string (STRING1 to STRING10) is required")
+	}
+
+	c.SessionType = strings.ToLower(strings.TrimSpace(c.SessionType))
+	switch c.SessionType {
+	case "pyrogram", "telethon", "gogram":
+	default:
+		fatal("SESSION_TYPE", "%q is not supported; use pyrogram, telethon or gogram", c.SessionType)
+	}
+
+	if err := checkURL(c.ApiUrl, "http", "https"); err != nil {
+		fatal("API_URL", "%v", err)
+	}
+	if c.ApiKey == "" {
+		warn("API_KEY", "is not set; tracks are downloaded with yt-dlp only")
+	}
+	if c.Proxy != "" {
+		if err := checkURL(c.Proxy, "http", "https", "socks4", "socks5", "socks5h"); err != nil {
+			fatal("PROXY", "%v", err)
+		}
+	}
+
+	switch c.DownloadsLayout {
+	case "flat", "daily", "prefix":
+	default:
+		fatal("DOWNLOADS_LAYOUT", "%q is not supported; use flat, daily or prefix", c.DownloadsLayout)
+	}
+
+	if c.DownloadsDir == "" {
+		fatal("DOWNLOADS_DIR", "must not be empty")
+	} else if err := checkWritableDir(c.DownloadsDir); err != nil {
+		fatal("DOWNLOADS_DIR", "%v", err)
+	}
+
+	return problems
+}
+
+// checkURL reports whether raw is an absolute URL with one of the given schemes.
+func checkURL(raw string, schemes ...string) error {
+	if raw == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid URL: %v", raw, err)
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			if u.Host == "" {
+				return fmt.Errorf("%q has no host", raw)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%q must start with one of %s://", raw, strings.Join(schemes, "://, "))
+}
+
+// checkWritableDir creates dir if it is missing and makes sure files can be written to it.
+func checkWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("cannot create %q: %v", dir, err)
+	}
+	f, err := os.CreateTemp(dir, ".write-test-*")
+	if err != nil {
+		return fmt.Errorf("%q is not writable: %v", dir, err)
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	return nil
+}
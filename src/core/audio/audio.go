@@ -0,0 +1,141 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package audio wraps ffmpeg for the small audio edits users ask for, such as cutting a clip out of a file or
+// changing its format. Errors are sorted into the sentinel errors below so callers never show ffmpeg's output.
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/logging"
+)
+
+var logger = logging.For("audio")
+
+var (
+	// ErrUnsupported is returned when ffmpeg cannot read the input or it has no audio.
+	ErrUnsupported = errors.New("the file has no audio ffmpeg can read")
+	// ErrBadRange is returned for a range that is malformed, empty or ends before it starts.
+	ErrBadRange = errors.New("invalid time range")
+	// ErrFailed is returned when ffmpeg fails for any other reason; the details are logged.
+	ErrFailed = errors.New("ffmpeg failed")
+)
+
+// Format is an output format of Convert.
+type Format struct {
+	Ext  string   // Ext is the file extension, without the dot.
+	Mime string   // Mime is the MIME type of the output.
+	args []string // args are the ffmpeg encoder arguments.
+}
+
+// Formats are the formats Convert and Trim can write, by name.
+var Formats = map[string]Format{
+	"mp3": {Ext: "mp3", Mime: "audio/mpeg", args: []string{"-c:a", "libmp3lame", "-q:a", "2"}},
+	"ogg": {Ext: "ogg", Mime: "audio/ogg", args: []string{"-c:a", "libopus", "-b:a", "128k"}},
+	"wav": {Ext: "wav", Mime: "audio/wav", args: []string{"-c:a", "pcm_s16le"}},
+}
+
+// FormatNames returns the names of Formats, sorted.
+func FormatNames() []string {
+	names := make([]string, 0, len(Formats))
+	for name := range Formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Trim writes the part of in between start and end, in seconds, to out in format f.
+func Trim(ctx context.Context, in, out string, start, end int, f Format) error {
+	if start < 0 || end <= start {
+		return ErrBadRange
+	}
+	args := []string{"-ss", strconv.Itoa(start), "-to", strconv.Itoa(end), "-i", in, "-vn", "-map_metadata", "0"}
+	return run(ctx, in, append(append(args, f.args...), out))
+}
+
+// Convert transcodes in to out in format f.
+func Convert(ctx context.Context, in, out string, f Format) error {
+	args := []string{"-i", in, "-vn", "-map_metadata", "0"}
+	return run(ctx, in, append(append(args, f.args...), out))
+}
+
+// ToSpeechWAV converts in to the 16 kHz mono WAV that speech recognisers expect.
+func ToSpeechWAV(ctx context.Context, in, out string) error {
+	return run(ctx, in, []string{"-i", in, "-vn", "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", out})
+}
+
+// run runs ffmpeg with args and sorts its failure into one of the package errors.
+func run(ctx context.Context, in string, args []string) error {
+	defer logger.Time(ctx, "exec", "ffmpeg", in)()
+	args = append([]string{"-hide_banner", "-loglevel", "error", "-nostdin", "-y"}, args...)
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		out := stderr.String()
+		for _, marker := range []string{"Invalid data found", "does not contain any stream", "Output file does not contain", "matches no streams"} {
+			if strings.Contains(out, marker) {
+				return ErrUnsupported
+			}
+		}
+		logger.Warn("ffmpeg failed on %s: %v: %s", in, err, config.Redact(strings.TrimSpace(out)))
+		return ErrFailed
+	}
+	return nil
+}
+
+// ParseRange parses a range such as "0:30-1:45" into its start and end in seconds. Each side may be
+// seconds, m:ss or h:mm:ss.
+func ParseRange(s string) (int, int, error) {
+	from, to, ok := strings.Cut(strings.ReplaceAll(s, " ", ""), "-")
+	if !ok {
+		return 0, 0, ErrBadRange
+	}
+	start, err := ParseClock(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := ParseClock(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end <= start {
+		return 0, 0, ErrBadRange
+	}
+	return start, end, nil
+}
+
+// ParseClock parses seconds, m:ss or h:mm:ss into seconds.
+func ParseClock(s string) (int, error) {
+	parts := strings.Split(s, ":")
+	if s == "" || len(parts) > 3 {
+		return 0, fmt.Errorf("%w: %q is not a time", ErrBadRange, s)
+	}
+	total := 0
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (i > 0 && (n > 59 || len(part) != 2)) {
+			return 0, fmt.Errorf("%w: %q is not a time", ErrBadRange, s)
+		}
+		total = total*60 + n
+	}
+	return total, nil
+}
@@ -0,0 +1,48 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package blockwords matches track titles against blocked keywords.
+package blockwords
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Tokens splits s into lowercase words, treating anything but letters and digits as a separator.
+func Tokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Normalize returns the form a keyword is stored and compared in: its words in lowercase, separated by single
+// spaces. It returns "" if the keyword has no words.
+func Normalize(word string) string {
+	return strings.Join(Tokens(word), " ")
+}
+
+// Match reports whether title contains any of words as whole words, ignoring case and punctuation. A keyword
+// of several words matches only when they appear together and in order, and a keyword never matches inside a
+// longer word, so blocking "ass" leaves "Bass Boosted" alone.
+func Match(title string, words []string) bool {
+	tokens := Tokens(title)
+	for _, word := range words {
+		kw := Tokens(word)
+		if len(kw) == 0 || len(kw) > len(tokens) {
+			continue
+		}
+		for i := 0; i+len(kw) <= len(tokens); i++ {
+			if slices.Equal(tokens[i:i+len(kw)], kw) {
+				return true
+			}
+		}
+	}
+	return false
+}
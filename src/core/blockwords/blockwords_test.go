@@ -0,0 +1,52 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package blockwords
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		title string
+		words []string
+		want  bool
+	}{
+		{"Bass Boosted Mix", []string{"ass"}, false},
+		{"Kick Ass Anthem", []string{"ass"}, true},
+		{"KICK-ASS (Remix)", []string{"ass"}, true},
+		{"Lofi Beats to Study", []string{"study beats"}, false},
+		{"Lofi Beats to Study", []string{"beats to"}, true},
+		{"Lofi  Beats, to   Study", []string{"Beats   TO"}, true},
+		{"Nightcore Mix", []string{"night"}, false},
+		{"Café del Mar", []string{"CAFÉ"}, true},
+		{"Track 2024 Edition", []string{"2024"}, true},
+		{"Anything", nil, false},
+		{"Anything", []string{"", "!!"}, false},
+		{"Short", []string{"short title here"}, false},
+		{"Second keyword matches", []string{"first", "keyword"}, true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.title, tt.words); got != tt.want {
+			t.Errorf("Match(%q, %q) = %t, want %t", tt.title, tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"  Kick   ASS ": "kick ass",
+		"rock'n'roll":   "rock n roll",
+		"!!!":           "",
+		"Café":          "café",
+	}
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
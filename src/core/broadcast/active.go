@@ -0,0 +1,31 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package broadcast
+
+// KeepActive returns the targets of list that are in active, in their order, how many it left out, and how
+// many of those are in untracked, the targets with no recorded activity.
+func KeepActive(list, active, untracked []int64) (kept []int64, inactive, unknown int) {
+	keep := make(map[int64]bool, len(active))
+	for _, id := range active {
+		keep[id] = true
+	}
+	noRecord := make(map[int64]bool, len(untracked))
+	for _, id := range untracked {
+		noRecord[id] = true
+	}
+	for _, id := range list {
+		switch {
+		case keep[id]:
+			kept = append(kept, id)
+		case noRecord[id]:
+			unknown++
+		}
+	}
+	return kept, len(list) - len(kept), unknown
+}
@@ -0,0 +1,40 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package broadcast
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKeepActive(t *testing.T) {
+	list := []int64{-100, 7, 8, -200, 9, 10}
+	active := []int64{8, -100, 42}
+	untracked := []int64{9, -200, 43}
+
+	kept, inactive, unknown := KeepActive(list, active, untracked)
+	if want := []int64{-100, 8}; !slices.Equal(kept, want) {
+		t.Errorf("kept %v, want %v", kept, want)
+	}
+	// 7 and 10 were seen but not lately; 9 and -200 were never seen, as after an update before they used
+	// the bot again.
+	if inactive != 4 || unknown != 2 {
+		t.Errorf("left out %d, %d of them untracked; want 4, 2", inactive, unknown)
+	}
+}
+
+func TestKeepActiveRightAfterUpdate(t *testing.T) {
+	// Right after the update nothing has recorded activity yet, so everything is left out as untracked,
+	// which the start message warns about.
+	list := []int64{1, 2, 3}
+	kept, inactive, unknown := KeepActive(list, nil, list)
+	if len(kept) != 0 || inactive != 3 || unknown != 3 {
+		t.Errorf("kept %v, left out %d, %d untracked; want none, 3, 3", kept, inactive, unknown)
+	}
+}
@@ -0,0 +1,230 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package broadcast holds the parts of /broadcast that need no bot: parsing its flags, pacing and running the
+// sends, and making sure only one broadcast runs at a time.
+package broadcast
+
+import (
+	"ashokshau/tgmusic/src/config"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// maxButtonRows and maxButtonsPerRow bound the keyboard of -buttons.
+const (
+	maxButtonRows    = 10
+	maxButtonsPerRow = 8
+)
+
+// Options are the flags of a /broadcast command.
+type Options struct {
+	Copy     bool           // Copy sends the message without the forward header.
+	NoChats  bool           // NoChats skips the groups.
+	NoUsers  bool           // NoUsers skips the private chats.
+	Limit    int            // Limit caps the number of targets; 0 sends to all of them.
+	Delay    time.Duration  // Delay is the pause each worker takes after every message.
+	Schedule string         // Schedule is when to send instead of now: a clock time or a delay.
+	Pin      bool           // Pin pins the message in the groups it was sent to.
+	PinLoud  bool           // PinLoud notifies the members of those groups about the pin.
+	List     bool           // List sends to the IDs in the replied file instead of every chat and user.
+	NoPrune  bool           // NoPrune keeps the chats and users that can no longer be sent to.
+	DryRun   bool           // DryRun reports what would be sent without sending anything.
+	Workers  int            // Workers is how many targets are sent to at once.
+	Active   time.Duration  // Active only sends to the chats and users that used the bot this recently.
+	Buttons  tg.ReplyMarkup // Buttons is the inline keyboard of a text broadcast.
+	Text     string         // Text is sent instead of a replied message when the command replies to none.
+	Rest     int            // Rest is the index of the first argument after the flags, where text starts.
+}
+
+// ParseArgs parses the arguments of a /broadcast command: the flags, then the text to send when the command
+// replies to no message. workers is the default of -workers. It returns the lang key of the error to show
+// for an invalid flag.
+func ParseArgs(raw string, workers int) (Options, string) {
+	args, offsets := splitArgs(raw)
+	opts, errKey := parseFlags(args, workers)
+	if errKey == "" && opts.Rest < len(args) {
+		opts.Text = strings.TrimSpace(raw[offsets[opts.Rest]:])
+	}
+	return opts, errKey
+}
+
+// splitArgs splits the arguments of a /broadcast command into words, keeping a quoted value such
+// as -buttons "Join|https://t.me/foo" in one word without its quotes. It also returns where each word
+// starts in raw.
+func splitArgs(raw string) ([]string, []int) {
+	var words []string
+	var offsets []int
+	var word strings.Builder
+	start, inWord, quoted := 0, false, false
+	for i, r := range raw {
+		switch {
+		case !quoted && unicode.IsSpace(r):
+			if inWord {
+				words, offsets = append(words, word.String()), append(offsets, start)
+				word.Reset()
+				inWord = false
+			}
+			continue
+		case !quoted && (r == '"' || r == '“'):
+			quoted = true
+		case quoted && (r == '"' || r == '”'):
+			quoted = false
+		default:
+			word.WriteRune(r)
+		}
+		if !inWord {
+			start, inWord = i, true
+		}
+	}
+	if inWord {
+		words, offsets = append(words, word.String()), append(offsets, start)
+	}
+	return words, offsets
+}
+
+// parseActiveWindow parses the window of -active: a number of days such as "30d", or a duration such as "12h".
+func parseActiveWindow(s string) (time.Duration, bool) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			return 0, false
+		}
+		return time.Duration(n) * 24 * time.Hour, true
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// parseButtons builds the inline keyboard of -buttons from spec: buttons written as "Text|URL",
+// separated by ";" within a row and by ";;" between rows. It returns nil if a button is malformed.
+func parseButtons(spec string) tg.ReplyMarkup {
+	kb := tg.NewKeyboard()
+	rows := 0
+	for _, row := range strings.Split(spec, ";;") {
+		var buttons []tg.KeyboardButton
+		for _, b := range strings.Split(row, ";") {
+			text, url, ok := strings.Cut(b, "|")
+			text, url = strings.TrimSpace(text), strings.TrimSpace(url)
+			if !ok || text == "" || !isButtonURL(url) {
+				return nil
+			}
+			buttons = append(buttons, tg.Button.URL(text, url))
+		}
+		if len(buttons) > maxButtonsPerRow {
+			return nil
+		}
+		kb.AddRow(buttons...)
+		rows++
+	}
+	if rows > maxButtonRows {
+		return nil
+	}
+	return kb.Build()
+}
+
+// isButtonURL reports whether url is a link Telegram accepts on a URL button.
+func isButtonURL(url string) bool {
+	for _, scheme := range []string{"https://", "http://", "tg://"} {
+		if strings.HasPrefix(url, scheme) && len(url) > len(scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFlags parses the flags of a /broadcast command, which come before any text. Flags that take
+// a value accept it either as the next argument ("-limit 100") or attached ("-limit100"). It returns the lang
+// key of the error to show for an invalid flag, or "" if they are all valid. Unknown flags are ignored.
+func parseFlags(args []string, workers int) (Options, string) {
+	opts := Options{Workers: workers}
+	opts.Rest = len(args)
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "-") {
+			opts.Rest = i
+			break
+		}
+		// value returns the value of the flag name, consuming the next argument if it is not attached.
+		value := func(name string) string {
+			if v := strings.TrimPrefix(a, name); v != "" {
+				return v
+			}
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+
+		switch {
+		case a == "-copy":
+			opts.Copy = true
+		case a == "-nochat" || a == "-nochats":
+			opts.NoChats = true
+		case a == "-nouser" || a == "-nousers":
+			opts.NoUsers = true
+		case a == "-pin":
+			opts.Pin = true
+		case a == "-pinloud":
+			opts.Pin, opts.PinLoud = true, true
+		case a == "-list":
+			opts.List = true
+		case a == "-noprune":
+			opts.NoPrune = true
+		case a == "-dryrun":
+			opts.DryRun = true
+
+		case strings.HasPrefix(a, "-limit"):
+			n, err := strconv.Atoi(value("-limit"))
+			if err != nil || n <= 0 {
+				return opts, "broadcast_invalid_limit"
+			}
+			opts.Limit = n
+
+		case strings.HasPrefix(a, "-delay"):
+			d, err := time.ParseDuration(value("-delay"))
+			if err != nil || d < 0 {
+				return opts, "broadcast_invalid_delay"
+			}
+			opts.Delay = d
+
+		case strings.HasPrefix(a, "-active"):
+			d, ok := parseActiveWindow(value("-active"))
+			if !ok {
+				return opts, "broadcast_invalid_active"
+			}
+			opts.Active = d
+
+		case strings.HasPrefix(a, "-workers"):
+			n, err := strconv.Atoi(value("-workers"))
+			if err != nil || n < 1 || n > config.MaxBroadcastWorkers {
+				return opts, "broadcast_invalid_workers"
+			}
+			opts.Workers = n
+
+		case strings.HasPrefix(a, "-schedule"):
+			if opts.Schedule = value("-schedule"); opts.Schedule == "" {
+				return opts, "broadcast_invalid_schedule"
+			}
+
+		case strings.HasPrefix(a, "-buttons"):
+			if opts.Buttons = parseButtons(value("-buttons")); opts.Buttons == nil {
+				return opts, "broadcast_invalid_buttons"
+			}
+		}
+	}
+	return opts, ""
+}
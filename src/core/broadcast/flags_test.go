@@ -0,0 +1,111 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package broadcast
+
+import (
+	"testing"
+	"time"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Options
+	}{
+		{"-copy -nochat", Options{Copy: true, NoChats: true, Workers: 4, Rest: 2}},
+		{"-nousers -noprune -dryrun", Options{NoUsers: true, NoPrune: true, DryRun: true, Workers: 4, Rest: 3}},
+		{"-pinloud -list", Options{Pin: true, PinLoud: true, List: true, Workers: 4, Rest: 2}},
+		{"-limit 100 -delay 2s", Options{Limit: 100, Delay: 2 * time.Second, Workers: 4, Rest: 4}},
+		{"-limit100 -delay2s", Options{Limit: 100, Delay: 2 * time.Second, Workers: 4, Rest: 2}},
+		{"-workers 8 -active 30d", Options{Workers: 8, Active: 30 * 24 * time.Hour, Rest: 4}},
+		{"-active12h", Options{Active: 12 * time.Hour, Workers: 4, Rest: 1}},
+		{"-schedule 21:30", Options{Schedule: "21:30", Workers: 4, Rest: 2}},
+		{"-unknown -copy", Options{Copy: true, Workers: 4, Rest: 2}},
+		{"-copy Hello  there, -everyone", Options{Copy: true, Workers: 4, Rest: 1, Text: "Hello  there, -everyone"}},
+		{"Hello", Options{Workers: 4, Text: "Hello"}},
+	}
+	for _, tt := range tests {
+		got, errKey := ParseArgs(tt.raw, 4)
+		if errKey != "" {
+			t.Errorf("ParseArgs(%q) failed with %s", tt.raw, errKey)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseArgs(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParseArgsRejectsBadValues(t *testing.T) {
+	tests := []struct {
+		raw, errKey string
+	}{
+		{"-limit", "broadcast_invalid_limit"},
+		{"-limit 0", "broadcast_invalid_limit"},
+		{"-limit ten", "broadcast_invalid_limit"},
+		{"-delay soon", "broadcast_invalid_delay"},
+		{"-delay -1s", "broadcast_invalid_delay"},
+		{"-active 0d", "broadcast_invalid_active"},
+		{"-active forever", "broadcast_invalid_active"},
+		{"-workers 0", "broadcast_invalid_workers"},
+		{"-workers 1000", "broadcast_invalid_workers"},
+		{"-schedule", "broadcast_invalid_schedule"},
+		{"-buttons Join", "broadcast_invalid_buttons"},
+		{`-buttons "Join|ftp://example.com"`, "broadcast_invalid_buttons"},
+	}
+	for _, tt := range tests {
+		if _, errKey := ParseArgs(tt.raw, 4); errKey != tt.errKey {
+			t.Errorf("ParseArgs(%q) failed with %q, want %q", tt.raw, errKey, tt.errKey)
+		}
+	}
+}
+
+func TestParseButtons(t *testing.T) {
+	opts, errKey := ParseArgs(`-buttons “Join|https://t.me/foo; Site|https://example.com;;Help|tg://resolve?domain=foo” Hello`, 4)
+	if errKey != "" {
+		t.Fatalf("ParseArgs failed with %s", errKey)
+	}
+	if opts.Text != "Hello" {
+		t.Errorf("Text = %q, want %q", opts.Text, "Hello")
+	}
+
+	kb, ok := opts.Buttons.(*tg.ReplyInlineMarkup)
+	if !ok {
+		t.Fatalf("Buttons = %T, want an inline keyboard", opts.Buttons)
+	}
+	if len(kb.Rows) != 2 || len(kb.Rows[0].Buttons) != 2 || len(kb.Rows[1].Buttons) != 1 {
+		t.Fatalf("keyboard has the wrong shape: %+v", kb.Rows)
+	}
+	if b, ok := kb.Rows[0].Buttons[1].(*tg.KeyboardButtonURL); !ok || b.Text != "Site" || b.URL != "https://example.com" {
+		t.Errorf("second button = %+v, want Site linking to https://example.com", kb.Rows[0].Buttons[1])
+	}
+}
+
+func TestParseButtonsBounds(t *testing.T) {
+	row := "a|https://a.io"
+	for i := 1; i < maxButtonsPerRow; i++ {
+		row += ";a|https://a.io"
+	}
+	if parseButtons(row) == nil {
+		t.Fatalf("a row of %d buttons was rejected", maxButtonsPerRow)
+	}
+	if parseButtons(row+";a|https://a.io") != nil {
+		t.Fatalf("a row of %d buttons was accepted", maxButtonsPerRow+1)
+	}
+
+	rows := "a|https://a.io"
+	for i := 1; i <= maxButtonRows; i++ {
+		rows += ";;a|https://a.io"
+	}
+	if parseButtons(rows) != nil {
+		t.Fatalf("%d rows were accepted", maxButtonRows+1)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package broadcast
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxRate is the rate a broadcast starts at, in messages per second, below what Telegram allows a bot.
+	MaxRate = 25.0
+	// minRate is the lowest rate flood waits back a broadcast off to.
+	minRate = 1.0
+	// rampEvery is how long a broadcast must go without a flood wait before its rate climbs by
+	// rampStep, until it is back at MaxRate.
+	rampEvery = 10 * time.Second
+	rampStep  = 1.0
+)
+
+// Limiter is a token bucket shared by the workers of a broadcast, holding up to one second of its rate.
+// A flood wait on any worker pauses them all until it is over and halves the rate, which then climbs back
+// slowly while no more come.
+type Limiter struct {
+	mu       sync.Mutex
+	rate     float64
+	tokens   float64
+	last     time.Time
+	changed  time.Time     // changed is when the rate last went up or down.
+	paused   time.Time     // paused is when the current flood wait is over.
+	floodFor time.Duration // floodFor is the total time the workers were paused for flood waits.
+}
+
+// NewLimiter returns a limiter that starts at MaxRate.
+func NewLimiter() *Limiter {
+	now := time.Now()
+	return &Limiter{rate: MaxRate, tokens: 1, last: now, changed: now}
+}
+
+// Wait blocks until a message may be sent, or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		now := time.Now()
+		l.mu.Lock()
+		var sleep time.Duration
+		if now.Before(l.paused) {
+			sleep = l.paused.Sub(now)
+		} else {
+			if l.rate < MaxRate && now.Sub(l.changed) >= rampEvery {
+				l.rate = min(l.rate+rampStep, MaxRate)
+				l.changed = now
+			}
+			if now.After(l.last) {
+				l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, l.rate)
+				l.last = now
+			}
+			if l.tokens >= 1 {
+				l.tokens--
+				l.mu.Unlock()
+				return nil
+			}
+			sleep = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Flood pauses every worker for d, the flood wait a send got. The first flood wait of a pause halves the
+// rate; the others, from sends already under way, only extend the pause.
+func (l *Limiter) Flood(d time.Duration) {
+	now := time.Now()
+	until := now.Add(d)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !now.Before(l.paused) {
+		l.rate = max(l.rate/2, minRate)
+	}
+	if until.After(l.paused) {
+		// Only the part not already covered by an earlier flood wait counts.
+		from := now
+		if l.paused.After(now) {
+			from = l.paused
+		}
+		l.floodFor += until.Sub(from)
+		l.paused = until
+	}
+	l.tokens = 0
+	l.last = l.paused
+	l.changed = l.paused
+}
+
+// Rate returns the rate the limiter lets messages through at now, in messages per second.
+func (l *Limiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// FloodWaited returns the total time the workers were paused for flood waits.
+func (l *Limiter) FloodWaited() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.floodFor
+}
@@ -0,0 +1,46 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package broadcast
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFloodSlowsTheLimiterDown(t *testing.T) {
+	l := NewLimiter()
+	l.Flood(50 * time.Millisecond)
+	// A flood wait reported by another worker during the same pause only extends it.
+	l.Flood(80 * time.Millisecond)
+	if got := l.Rate(); got != MaxRate/2 {
+		t.Fatalf("rate after one pause = %v, want %v", got, MaxRate/2)
+	}
+
+	begun := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(begun); waited < 70*time.Millisecond {
+		t.Fatalf("Wait returned after %s, during the flood wait", waited)
+	}
+	if got := l.FloodWaited(); got < 75*time.Millisecond || got > 85*time.Millisecond {
+		t.Fatalf("FloodWaited = %s, want the 80ms the pause lasted", got)
+	}
+}
+
+func TestWaitStopsOnCancel(t *testing.T) {
+	l := NewLimiter()
+	l.Flood(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Fatal("Wait let a send through during a flood wait after the broadcast was cancelled")
+	}
+}
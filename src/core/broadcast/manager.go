@@ -0,0 +1,97 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package broadcast
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Manager tracks the running broadcast, of which there is at most one. A broadcast holds the manager from
+// Begin until all of its workers have returned, so a new one cannot start while the workers of the last are
+// still sending. The zero value is ready to use.
+type Manager struct {
+	mu     sync.Mutex
+	runID  int64
+	cancel context.CancelFunc // cancel stops the running broadcast; nil when none is running.
+	done   chan struct{}      // done is closed when the running broadcast ends.
+}
+
+// Begin claims the manager for a new broadcast and returns the context its workers stop on, which is also
+// cancelled with parent. It reports false if a broadcast is already running.
+func (b *Manager) Begin(parent context.Context) (context.Context, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cancel != nil {
+		return nil, false
+	}
+	ctx, cancel := context.WithCancel(parent)
+	b.runID = time.Now().UnixNano()
+	b.cancel = cancel
+	b.done = make(chan struct{})
+	return ctx, true
+}
+
+// End releases the manager. It must be called once the workers of the broadcast have all returned.
+func (b *Manager) End() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cancel == nil {
+		return
+	}
+	b.cancel()
+	b.cancel = nil
+	close(b.done)
+}
+
+// Stop cancels the running broadcast without waiting for it to end, and reports whether one was running.
+func (b *Manager) Stop() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cancel == nil {
+		return false
+	}
+	b.cancel()
+	return true
+}
+
+// Running reports whether a broadcast is running.
+func (b *Manager) Running() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.cancel != nil
+}
+
+// Token identifies the running broadcast, so cancel buttons of finished broadcasts are stale.
+func (b *Manager) Token() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cancel == nil {
+		return "0"
+	}
+	return strconv.FormatInt(b.runID, 36)
+}
+
+// Wait blocks until the running broadcast ends or ctx is done.
+func (b *Manager) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	done, running := b.done, b.cancel != nil
+	b.mu.Unlock()
+	if !running {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
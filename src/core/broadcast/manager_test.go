@@ -0,0 +1,97 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package broadcast
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOnlyOneBroadcastRuns(t *testing.T) {
+	var m Manager
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := m.Begin(context.Background()); ok {
+				wins.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := wins.Load(); n != 1 {
+		t.Fatalf("%d broadcasts started at once, want 1", n)
+	}
+
+	// A stopped broadcast keeps the manager until its workers are done, so the next cannot overlap them.
+	if !m.Stop() {
+		t.Fatal("Stop found nothing running")
+	}
+	if _, ok := m.Begin(context.Background()); ok {
+		t.Fatal("a broadcast started while the stopped one was still winding down")
+	}
+	m.End()
+	if m.Running() {
+		t.Fatal("the manager is still held after End")
+	}
+	if _, ok := m.Begin(context.Background()); !ok {
+		t.Fatal("no broadcast could start after the last one ended")
+	}
+	m.End()
+}
+
+func TestStopCancelsTheRunningBroadcast(t *testing.T) {
+	var m Manager
+	ctx, ok := m.Begin(context.Background())
+	if !ok {
+		t.Fatal("Begin failed")
+	}
+	token := m.Token()
+	if token == "0" {
+		t.Fatal("a running broadcast has no token")
+	}
+
+	m.Stop()
+	select {
+	case <-ctx.Done():
+	default:
+		t.Fatal("Stop did not cancel the broadcast's context")
+	}
+
+	ended := make(chan error, 1)
+	go func() { ended <- m.Wait(context.Background()) }()
+	select {
+	case <-ended:
+		t.Fatal("Wait returned before the broadcast ended")
+	case <-time.After(20 * time.Millisecond):
+	}
+	m.End()
+	if err := <-ended; err != nil {
+		t.Fatalf("Wait = %v", err)
+	}
+	if m.Token() != "0" {
+		t.Fatal("the token of the finished broadcast is still current")
+	}
+}
+
+func TestParentCancelsTheBroadcast(t *testing.T) {
+	var m Manager
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, _ := m.Begin(parent)
+	defer m.End()
+	cancel()
+	if ctx.Err() == nil {
+		t.Fatal("cancelling the parent, as a shutdown does, did not stop the broadcast")
+	}
+}
@@ -0,0 +1,147 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package broadcast
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// deadTargetErrors are the errors of a send that will fail the same way every time: the user blocked or
+// deleted the bot, or it was removed from the chat. Errors that can pass, such as a muted bot or a peer
+// missing from the session's cache, are left out.
+var deadTargetErrors = []string{
+	"USER_IS_BLOCKED",
+	"USER_DEACTIVATED",
+	"INPUT_USER_DEACTIVATED",
+	"CHANNEL_PRIVATE",
+	"CHANNEL_INVALID",
+	"CHAT_ID_INVALID",
+	"USER_BANNED_IN_CHANNEL",
+}
+
+// IsDeadTarget reports whether a send to a target failed with a permanent error.
+func IsDeadTarget(err error) bool {
+	for _, e := range deadTargetErrors {
+		if tg.MatchError(err, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan is how Run sends a broadcast.
+type Plan struct {
+	Workers int           // Workers is how many targets are sent to at once.
+	Delay   time.Duration // Delay is the pause each worker takes after every target.
+	Limiter *Limiter      // Limiter paces the sends of all the workers.
+
+	// Send sends the broadcast to id. A flood wait error is retried once it is over.
+	Send func(ctx context.Context, id int64) error
+	// Start, if set, is called before the first attempt to send to id.
+	Start func(id int64)
+	// Flood, if set, is called when a send to id got a flood wait of d.
+	Flood func(id int64, d time.Duration)
+	// Failed, if set, is called when sending to id failed with err.
+	Failed func(id int64, err error)
+}
+
+// Progress counts the targets of a running broadcast. It is safe to read while Run is sending.
+type Progress struct {
+	sent, failed, skipped atomic.Int32
+}
+
+// Sent returns how many targets the broadcast was sent to.
+func (p *Progress) Sent() int32 { return p.sent.Load() }
+
+// Failed returns how many targets the broadcast could not be sent to.
+func (p *Progress) Failed() int32 { return p.failed.Load() }
+
+// Skipped returns how many targets were not tried because the broadcast was cancelled first.
+func (p *Progress) Skipped() int32 { return p.skipped.Load() }
+
+// Run sends to every target as plan says, counting them in p, and returns once all of its workers have.
+// Once ctx is cancelled no new send starts; the targets left are counted as skipped.
+func Run(ctx context.Context, targets []int64, plan Plan, p *Progress) {
+	jobs := make(chan int64, plan.Workers)
+	var wg sync.WaitGroup
+
+	worker := func() {
+		defer wg.Done()
+		for id := range jobs {
+			// The limiter may let a send through without looking at ctx, so ctx is checked after it.
+			if plan.Limiter.Wait(ctx) != nil || ctx.Err() != nil {
+				p.skipped.Add(1)
+				continue
+			}
+			if plan.Start != nil {
+				plan.Start(id)
+			}
+			send(ctx, id, plan, p)
+			if plan.Delay > 0 {
+				sleep(ctx, plan.Delay)
+			}
+		}
+	}
+
+	wg.Add(plan.Workers)
+	for i := 0; i < plan.Workers; i++ {
+		go worker()
+	}
+	for _, id := range targets {
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// send sends to id, retrying after flood waits. The caller has already waited for the limiter once.
+func send(ctx context.Context, id int64, plan Plan, p *Progress) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && (plan.Limiter.Wait(ctx) != nil || ctx.Err() != nil) {
+			p.failed.Add(1)
+			return
+		}
+
+		err := plan.Send(ctx, id)
+		if err == nil {
+			p.sent.Add(1)
+			return
+		}
+		if wait := tg.GetFloodWait(err); wait > 0 {
+			// Every worker pauses, not just this one, or the others would keep the flood going.
+			d := time.Duration(wait) * time.Second
+			plan.Limiter.Flood(d)
+			if plan.Flood != nil {
+				plan.Flood(id, d)
+			}
+			continue
+		}
+
+		p.failed.Add(1)
+		if plan.Failed != nil {
+			plan.Failed(id, err)
+		}
+		return
+	}
+}
+
+// sleep waits for d, returning early if ctx is done.
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,139 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package broadcast
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSender records the targets sent to and fails those listed in errs.
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []int64
+	errs map[int64]error
+	// after, if set, is called after every send with how many have been made.
+	after func(n int)
+}
+
+func (f *fakeSender) Send(_ context.Context, id int64) error {
+	f.mu.Lock()
+	if err, ok := f.errs[id]; ok {
+		delete(f.errs, id)
+		f.mu.Unlock()
+		return err
+	}
+	f.sent = append(f.sent, id)
+	n := len(f.sent)
+	f.mu.Unlock()
+	if f.after != nil {
+		f.after(n)
+	}
+	return nil
+}
+
+func targets(n int) []int64 {
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	return ids
+}
+
+func TestRunSendsToEveryTarget(t *testing.T) {
+	dead := errors.New("rpc error code 403: USER_IS_BLOCKED")
+	f := &fakeSender{errs: map[int64]error{3: dead, 5: errors.New("rpc error code 400: MESSAGE_EMPTY")}}
+	var failed []int64
+	var mu sync.Mutex
+	plan := Plan{Workers: 3, Limiter: NewLimiter(), Send: f.Send, Failed: func(id int64, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if IsDeadTarget(err) {
+			failed = append(failed, id)
+		}
+	}}
+
+	var p Progress
+	Run(context.Background(), targets(8), plan, &p)
+	if p.Sent() != 6 || p.Failed() != 2 || p.Skipped() != 0 {
+		t.Fatalf("sent %d, failed %d, skipped %d; want 6, 2, 0", p.Sent(), p.Failed(), p.Skipped())
+	}
+	slices.Sort(f.sent)
+	if want := []int64{1, 2, 4, 6, 7, 8}; !slices.Equal(f.sent, want) {
+		t.Fatalf("sent to %v, want %v", f.sent, want)
+	}
+	if !slices.Equal(failed, []int64{3}) {
+		t.Fatalf("dead targets %v, want [3]", failed)
+	}
+}
+
+func TestRunRetriesAfterFloodWait(t *testing.T) {
+	f := &fakeSender{errs: map[int64]error{2: errors.New("rpc error code 420: FLOOD_WAIT_1")}}
+	floods := 0
+	plan := Plan{Workers: 1, Limiter: NewLimiter(), Send: f.Send, Flood: func(int64, time.Duration) { floods++ }}
+
+	var p Progress
+	Run(context.Background(), targets(3), plan, &p)
+	if p.Sent() != 3 || p.Failed() != 0 {
+		t.Fatalf("sent %d, failed %d; want 3, 0", p.Sent(), p.Failed())
+	}
+	if floods != 1 {
+		t.Fatalf("%d flood waits reported, want 1", floods)
+	}
+}
+
+func TestNoSendsAfterCancel(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		ctx, cancel := context.WithCancel(context.Background())
+		f := &fakeSender{}
+		var cancelledAt int
+		var once sync.Once
+		f.after = func(n int) {
+			if n == 3 {
+				once.Do(func() {
+					cancelledAt = n
+					cancel()
+				})
+			}
+		}
+		started := 0
+		var mu sync.Mutex
+		plan := Plan{Workers: workers, Limiter: NewLimiter(), Send: f.Send, Start: func(int64) {
+			mu.Lock()
+			started++
+			mu.Unlock()
+			if ctx.Err() != nil {
+				t.Errorf("workers=%d: a send started after the broadcast was cancelled", workers)
+			}
+		}}
+
+		var p Progress
+		ids := targets(20)
+		Run(ctx, ids, plan, &p)
+		cancel()
+
+		// Sends already under way when the broadcast was cancelled may finish; no new one starts.
+		if got := len(f.sent); got < cancelledAt || got > cancelledAt+workers-1 {
+			t.Errorf("workers=%d: %d sends, want %d to %d", workers, got, cancelledAt, cancelledAt+workers-1)
+		}
+		if workers == 1 && len(f.sent) != 3 {
+			t.Errorf("workers=1: %d sends, want exactly 3", len(f.sent))
+		}
+		if total := int(p.Sent() + p.Failed() + p.Skipped()); total != len(ids) {
+			t.Errorf("workers=%d: %d targets accounted for, want %d", workers, total, len(ids))
+		}
+		if int(p.Skipped()) != len(ids)-started {
+			t.Errorf("workers=%d: %d skipped but %d never started", workers, p.Skipped(), len(ids)-started)
+		}
+	}
+}
@@ -11,6 +11,7 @@ package core
 import (
 	"fmt"
 
+	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/lang"
 
@@ -59,8 +60,15 @@ func SupportKeyboard() *telegram.ReplyInlineMarkup {
 	return keyboard.Build()
 }
 
+// CleanModeOptions are the clean mode delays, in seconds, offered in the settings menu. 0 turns clean mode off.
+var CleanModeOptions = []int{0, config.DefaultCleanDelay, 30, 60, 120}
+
+// SearchDefault is the settings value of a chat that has not picked a search platform and uses the configured
+// default service.
+const SearchDefault = "default"
+
 // SettingsKeyboard creates an inline keyboard for bot settings
-func SettingsKeyboard(playMode, adminMode string) *telegram.ReplyInlineMarkup {
+func SettingsKeyboard(playMode, adminMode string, cleanMode int, searchPlatform string) *telegram.ReplyInlineMarkup {
 	// Helper function to create a button with a checkmark if active
 	createButton := func(label, settingType, settingValue, currentValue string) *telegram.KeyboardButtonCallback {
 		text := label
@@ -88,6 +96,30 @@ func SettingsKeyboard(playMode, adminMode string) *telegram.ReplyInlineMarkup {
 		createButton("Everyone", "admin", cache.Everyone, adminMode),
 	)
 
+	// Clean Mode Section
+	keyboard.AddRow(telegram.Button.Data("🧹 Clean Mode", "settings_xxx_clean"))
+	var cleanRow []telegram.KeyboardButton
+	for _, seconds := range CleanModeOptions {
+		label := fmt.Sprintf("%ds", seconds)
+		if seconds == 0 {
+			label = "Off"
+		}
+		cleanRow = append(cleanRow, createButton(label, "clean", fmt.Sprint(seconds), fmt.Sprint(cleanMode)))
+	}
+	keyboard.AddRow(cleanRow...)
+
+	// Search Platform Section
+	if searchPlatform == "" {
+		searchPlatform = SearchDefault
+	}
+	keyboard.AddRow(telegram.Button.Data("🔎 Search Platform", "settings_xxx_search"))
+	keyboard.AddRow(
+		createButton("Default", "search", SearchDefault, searchPlatform),
+		createButton("YouTube", "search", cache.YouTube, searchPlatform),
+		createButton("JioSaavn", "search", cache.JioSaavn, searchPlatform),
+		createButton("SoundCloud", "search", cache.SoundCloud, searchPlatform),
+	)
+
 	// Close button
 	keyboard.AddRow(CloseBtn)
 
@@ -145,6 +177,39 @@ func ControlButtons(mode string) *telegram.ReplyInlineMarkup {
 	return keyboard.Build()
 }
 
+// ActiveVcKeyboard creates an inline keyboard for the /activevc listing with a stop button for each chat on the page
+// and navigation buttons when the listing spans more than one page.
+func ActiveVcKeyboard(chatIDs []int64, offset, page, pages int) *telegram.ReplyInlineMarkup {
+	keyboard := telegram.NewKeyboard()
+
+	var row []telegram.KeyboardButton
+	for i, chatID := range chatIDs {
+		row = append(row, telegram.Button.Data(fmt.Sprintf("⏹ %d", offset+i+1), fmt.Sprintf("activevc_stop_%d_%d", page, chatID)))
+		if len(row) == 5 {
+			keyboard.AddRow(row...)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboard.AddRow(row...)
+	}
+
+	if pages > 1 {
+		var nav []telegram.KeyboardButton
+		if page > 0 {
+			nav = append(nav, telegram.Button.Data("« Prev", fmt.Sprintf("activevc_page_%d", page-1)))
+		}
+		nav = append(nav, telegram.Button.Data(fmt.Sprintf("%d/%d", page+1, pages), "activevc_noop"))
+		if page < pages-1 {
+			nav = append(nav, telegram.Button.Data("Next »", fmt.Sprintf("activevc_page_%d", page+1)))
+		}
+		keyboard.AddRow(nav...)
+	}
+
+	keyboard.AddRow(telegram.Button.Data("↻ Refresh", fmt.Sprintf("activevc_page_%d", page)), CloseBtn)
+	return keyboard.Build()
+}
+
 func LanguageKeyboard() *telegram.ReplyInlineMarkup {
 	keyboard := telegram.NewKeyboard()
 	langs := lang.GetAvailableLangs()
@@ -165,12 +230,22 @@ func LanguageKeyboard() *telegram.ReplyInlineMarkup {
 // AddMeMarkup creates and returns an inline keyboard with a button that allows users to add the bot to their group.
 // It requires the bot's username to generate the correct link.
 func AddMeMarkup(username string) *telegram.ReplyInlineMarkup {
+	return addMeKeyboard(username).Build()
+}
+
+// AnnouncementMarkup creates the /start keyboard with an extra button to dismiss the given announcement.
+func AnnouncementMarkup(username string, announcementID int64) *telegram.ReplyInlineMarkup {
+	return addMeKeyboard(username).
+		AddRow(telegram.Button.Data("✅ Got it", fmt.Sprintf("announce_dismiss_%d", announcementID))).
+		Build()
+}
+
+// addMeKeyboard builds the rows shared by the /start keyboards.
+func addMeKeyboard(username string) *telegram.KeyboardBuilder {
 	addMeBtn := telegram.Button.URL(fmt.Sprintf("Aᴅᴅ ᴍᴇ ᴛᴏ ʏᴏᴜʀ ɢʀᴏᴜᴘ"), fmt.Sprintf("https://t.me/%s?startgroup=true", username))
 
-	keyboard := telegram.NewKeyboard().
+	return telegram.NewKeyboard().
 		AddRow(addMeBtn).
 		AddRow(HelpBtn, SourceCodeBtn).
 		AddRow(ChannelBtn, GroupBtn)
-
-	return keyboard.Build()
 }
@@ -10,9 +10,18 @@ package cache
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// hits and misses count lookups across every Cache since the last TakeLookups.
+var hits, misses atomic.Int64
+
+// TakeLookups returns how many cache lookups hit and missed since the last call and resets both counts.
+func TakeLookups() (hit, miss int64) {
+	return hits.Swap(0), misses.Swap(0)
+}
+
 // Item represents an item stored in the cache, containing a value and its expiration time.
 type Item[T any] struct {
 	Value      T
@@ -29,10 +38,12 @@ type Cache[T any] struct {
 // NewCache initializes and returns a new Cache with a specified default TTL.
 // The ttl parameter sets the default time-to-live duration for cache items.
 func NewCache[T any](ttl time.Duration) *Cache[T] {
-	return &Cache[T]{
+	c := &Cache[T]{
 		data: make(map[string]Item[T]),
 		ttl:  ttl,
 	}
+	registerCache(c)
+	return c
 }
 
 // Get retrieves a value from the cache by its key.
@@ -43,9 +54,11 @@ func (c *Cache[T]) Get(key string) (T, bool) {
 	c.mu.RUnlock()
 
 	if !ok || time.Now().After(item.Expiration) {
+		misses.Add(1)
 		var zero T
 		return zero, false
 	}
+	hits.Add(1)
 	return item.Value, true
 }
 
@@ -80,3 +93,26 @@ func (c *Cache[T]) Clear() {
 	defer c.mu.Unlock()
 	c.data = make(map[string]Item[T])
 }
+
+// Len returns how many items the cache holds, including expired ones that have not been purged yet.
+func (c *Cache[T]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.data)
+}
+
+// Purge removes the items that have expired by now and returns how many were removed.
+// Expired items are otherwise only replaced when their key is set again.
+func (c *Cache[T]) Purge(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for key, item := range c.data {
+		if now.After(item.Expiration) {
+			delete(c.data, key)
+			n++
+		}
+	}
+	return n
+}
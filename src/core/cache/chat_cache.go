@@ -9,52 +9,269 @@
 package cache
 
 import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 )
 
+// ErrQueueFull is returned when adding a track would exceed the configured queue length.
+var ErrQueueFull = errors.New("queue is full")
+
+// DefaultMaxQueueLength is the queue bound used until SetMaxLength is called.
+const DefaultMaxQueueLength = 10
+
+// QueueEvent describes what kind of mutation a QueueChange reports.
+type QueueEvent int
+
+const (
+	QueueAdded QueueEvent = iota
+	QueueRemoved
+	QueueMoved
+	QueueShuffled
+	QueueAdvanced
+	QueueCleared
+)
+
+// QueueChange is delivered to listeners registered with OnChange after a queue mutation.
+type QueueChange struct {
+	ChatID int64
+	Event  QueueEvent
+	Length int
+}
+
 // ChatData holds the state of a chat's music queue, including whether it is active and the list of tracks.
+// The first track of the queue is the one currently playing.
 type ChatData struct {
+	mu       sync.Mutex
+	removed  bool // removed is set once the entry has left the map; it must not be mutated any more.
 	IsActive bool
 	Queue    []*CachedTrack
 }
 
 // ChatCacher is a thread-safe cache that manages music queues for multiple chats.
+// The map is guarded by mu while each chat's queue is guarded by its own mutex,
+// so a busy chat never blocks the others. When both are needed, mu is taken first.
 type ChatCacher struct {
 	mu        sync.RWMutex
 	chatCache map[int64]*ChatData
+	maxLength atomic.Int64
+
+	listenersMu sync.RWMutex
+	listeners   []func(QueueChange)
 }
 
 // NewChatCacher initializes and returns a new ChatCacher.
 func NewChatCacher() *ChatCacher {
-	return &ChatCacher{
-		chatCache: make(map[int64]*ChatData),
+	c := &ChatCacher{chatCache: make(map[int64]*ChatData)}
+	c.maxLength.Store(DefaultMaxQueueLength)
+	return c
+}
+
+// SetMaxLength sets the maximum number of pending tracks a chat may hold (0 disables the limit).
+func (c *ChatCacher) SetMaxLength(n int) {
+	c.maxLength.Store(int64(n))
+}
+
+// MaxLength returns the maximum number of pending tracks a chat may hold.
+// It does not take mu, so it may be called with a chat's mutex held.
+func (c *ChatCacher) MaxLength() int {
+	return int(c.maxLength.Load())
+}
+
+// OnChange registers fn to be called after every queue mutation.
+// Listeners are called synchronously outside of any lock and must not block.
+func (c *ChatCacher) OnChange(fn func(QueueChange)) {
+	c.listenersMu.Lock()
+	defer c.listenersMu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// notify delivers a change to every registered listener.
+func (c *ChatCacher) notify(chatID int64, event QueueEvent, length int) {
+	c.listenersMu.RLock()
+	listeners := c.listeners
+	c.listenersMu.RUnlock()
+
+	change := QueueChange{ChatID: chatID, Event: event, Length: length}
+	for _, fn := range listeners {
+		fn(change)
 	}
 }
 
-// AddSong adds a new song to a chat's queue. If the chat does not exist, it creates a new one.
-// It takes a chat ID and a CachedTrack to add, and returns the added track.
-func (c *ChatCacher) AddSong(chatID int64, song *CachedTrack) *CachedTrack {
+// get returns the chat's data, or nil if the chat has no queue.
+func (c *ChatCacher) get(chatID int64) *ChatData {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.chatCache[chatID]
+}
+
+// lock returns the chat's data with its mutex held, or nil if the chat has no queue.
+func (c *ChatCacher) lock(chatID int64) *ChatData {
+	data := c.get(chatID)
+	if data == nil {
+		return nil
+	}
+	data.mu.Lock()
+	if data.removed {
+		data.mu.Unlock()
+		return nil
+	}
+	return data
+}
+
+// lockOrCreate returns the chat's data with its mutex held, creating an empty queue with the given active
+// state if needed. An entry removed by ClearChat or Forget before it could be locked is replaced, so that a
+// mutation never lands on a queue that is no longer in the map.
+func (c *ChatCacher) lockOrCreate(chatID int64, active bool) *ChatData {
+	for {
+		data := c.getOrCreate(chatID, active)
+		data.mu.Lock()
+		if !data.removed {
+			return data
+		}
+		data.mu.Unlock()
+	}
+}
+
+// getOrCreate returns the chat's data, creating an empty queue with the given active state if needed.
+func (c *ChatCacher) getOrCreate(chatID int64, active bool) *ChatData {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	data, ok := c.chatCache[chatID]
 	if !ok {
-		data = &ChatData{IsActive: true, Queue: []*CachedTrack{}}
+		data = &ChatData{IsActive: active, Queue: []*CachedTrack{}}
 		c.chatCache[chatID] = data
 	}
+	return data
+}
 
+// full reports whether a queue of the given length has no room for another pending track.
+// The currently playing track does not count towards the limit.
+func (c *ChatCacher) full(length int) bool {
+	limit := c.MaxLength()
+	return limit > 0 && length > limit
+}
+
+// Enqueue appends a track to the end of a chat's queue.
+// It returns the track's position in the queue, or ErrQueueFull if the queue is at its limit.
+func (c *ChatCacher) Enqueue(chatID int64, song *CachedTrack) (int, error) {
+	data := c.lockOrCreate(chatID, true)
+	if c.full(len(data.Queue)) {
+		data.mu.Unlock()
+		return 0, ErrQueueFull
+	}
 	data.Queue = append(data.Queue, song)
+	pos, length := len(data.Queue)-1, len(data.Queue)
+	data.mu.Unlock()
+
+	c.notify(chatID, QueueAdded, length)
+	return pos, nil
+}
+
+// EnqueueMany appends as many of songs as fit to the end of a chat's queue, in order, in one step.
+// It returns the position of the first track added and how many were added; the rest did not fit.
+func (c *ChatCacher) EnqueueMany(chatID int64, songs []*CachedTrack) (int, int) {
+	data := c.lockOrCreate(chatID, true)
+	room := len(songs)
+	if limit := c.MaxLength(); limit > 0 {
+		// The playing track does not count towards the limit, so a queue holds up to limit+1 tracks.
+		room = min(room, max(limit+1-len(data.Queue), 0))
+	}
+	first := len(data.Queue)
+	data.Queue = append(data.Queue, songs[:room]...)
+	length := len(data.Queue)
+	data.mu.Unlock()
+
+	if room > 0 {
+		c.notify(chatID, QueueAdded, length)
+	}
+	return first, room
+}
+
+// InsertNext places a track right after the currently playing one.
+// It returns ErrQueueFull if the queue is at its limit.
+func (c *ChatCacher) InsertNext(chatID int64, song *CachedTrack) error {
+	data := c.lockOrCreate(chatID, true)
+	if c.full(len(data.Queue)) {
+		data.mu.Unlock()
+		return ErrQueueFull
+	}
+	if len(data.Queue) == 0 {
+		data.Queue = append(data.Queue, song)
+	} else {
+		data.Queue = append(data.Queue[:1], append([]*CachedTrack{song}, data.Queue[1:]...)...)
+	}
+	length := len(data.Queue)
+	data.mu.Unlock()
+
+	c.notify(chatID, QueueAdded, length)
+	return nil
+}
+
+// IsFull reports whether a chat's queue has reached its limit.
+func (c *ChatCacher) IsFull(chatID int64) bool {
+	data := c.lock(chatID)
+	if data == nil {
+		return false
+	}
+	defer data.mu.Unlock()
+	return c.full(len(data.Queue))
+}
+
+// AddSong adds a new song to a chat's queue. If the chat does not exist, it creates a new one.
+// It takes a chat ID and a CachedTrack to add, and returns the added track, or nil if the queue is full.
+func (c *ChatCacher) AddSong(chatID int64, song *CachedTrack) *CachedTrack {
+	if _, err := c.Enqueue(chatID, song); err != nil {
+		return nil
+	}
 	return song
 }
 
+// Next advances a chat's queue and returns the track that should play now.
+// If the current track still has loops left, the loop count is decremented and the same track is returned.
+// It returns nil once the queue is exhausted.
+func (c *ChatCacher) Next(chatID int64) *CachedTrack {
+	data := c.lock(chatID)
+	if data == nil {
+		return nil
+	}
+	if len(data.Queue) == 0 {
+		data.mu.Unlock()
+		return nil
+	}
+
+	current := data.Queue[0]
+	if current.Loop > 0 {
+		current.Loop--
+		data.mu.Unlock()
+		return current
+	}
+
+	data.Queue = data.Queue[1:]
+	var next *CachedTrack
+	if len(data.Queue) > 0 {
+		next = data.Queue[0]
+	}
+	length := len(data.Queue)
+	data.mu.Unlock()
+
+	c.notify(chatID, QueueAdvanced, length)
+	return next
+}
+
 // GetUpcomingTrack retrieves the next song in the queue for a given chat.
 // It returns the upcoming track or nil if the queue is empty or has only one song.
 func (c *ChatCacher) GetUpcomingTrack(chatID int64) *CachedTrack {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	data, ok := c.chatCache[chatID]
-	if !ok || len(data.Queue) < 2 {
+	data := c.lock(chatID)
+	if data == nil {
+		return nil
+	}
+	defer data.mu.Unlock()
+	if len(data.Queue) < 2 {
 		return nil
 	}
 	return data.Queue[1]
@@ -63,11 +280,12 @@ func (c *ChatCacher) GetUpcomingTrack(chatID int64) *CachedTrack {
 // GetPlayingTrack retrieves the currently playing song for a given chat.
 // It returns the current track or nil if the queue is empty.
 func (c *ChatCacher) GetPlayingTrack(chatID int64) *CachedTrack {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	data, ok := c.chatCache[chatID]
-	if !ok || len(data.Queue) == 0 {
+	data := c.lock(chatID)
+	if data == nil {
+		return nil
+	}
+	defer data.mu.Unlock()
+	if len(data.Queue) == 0 {
 		return nil
 	}
 	return data.Queue[0]
@@ -76,75 +294,91 @@ func (c *ChatCacher) GetPlayingTrack(chatID int64) *CachedTrack {
 // RemoveCurrentSong removes the currently playing song from the queue.
 // It returns the removed track or nil if the queue was empty.
 func (c *ChatCacher) RemoveCurrentSong(chatID int64) *CachedTrack {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	data, ok := c.chatCache[chatID]
-	if !ok || len(data.Queue) == 0 {
+	data := c.lock(chatID)
+	if data == nil {
+		return nil
+	}
+	if len(data.Queue) == 0 {
+		data.mu.Unlock()
 		return nil
 	}
-
 	removed := data.Queue[0]
 	data.Queue = data.Queue[1:]
+	length := len(data.Queue)
+	data.mu.Unlock()
 
+	c.notify(chatID, QueueAdvanced, length)
 	return removed
 }
 
 // IsActive checks if the music player is currently active in a specific chat.
 // It returns true if active, otherwise false.
 func (c *ChatCacher) IsActive(chatID int64) bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	data, ok := c.chatCache[chatID]
-	return ok && data.IsActive
+	data := c.lock(chatID)
+	if data == nil {
+		return false
+	}
+	defer data.mu.Unlock()
+	return data.IsActive
 }
 
 // SetActive updates the active state of the music player for a chat.
 func (c *ChatCacher) SetActive(chatID int64, active bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	data, ok := c.chatCache[chatID]
-	if !ok {
-		data = &ChatData{Queue: []*CachedTrack{}}
-		c.chatCache[chatID] = data
-	}
+	data := c.lockOrCreate(chatID, active)
+	defer data.mu.Unlock()
 	data.IsActive = active
 }
 
 // ClearChat removes all tracks from a chat's queue.
 func (c *ChatCacher) ClearChat(chatID int64) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	_, ok := c.chatCache[chatID]
+	data, ok := c.chatCache[chatID]
 	if !ok {
+		c.mu.Unlock()
 		return
 	}
-
+	data.mu.Lock()
+	data.removed = true
+	data.mu.Unlock()
 	delete(c.chatCache, chatID)
+	c.mu.Unlock()
+
+	c.notify(chatID, QueueCleared, 0)
+}
+
+// Clear drops every pending track but keeps the currently playing one.
+func (c *ChatCacher) Clear(chatID int64) {
+	data := c.lock(chatID)
+	if data == nil {
+		return
+	}
+	if len(data.Queue) > 1 {
+		data.Queue = data.Queue[:1]
+	}
+	length := len(data.Queue)
+	data.mu.Unlock()
+
+	c.notify(chatID, QueueCleared, length)
 }
 
 // GetQueueLength returns the total number of songs in a chat's queue.
 func (c *ChatCacher) GetQueueLength(chatID int64) int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	data, ok := c.chatCache[chatID]
-	if !ok {
+	data := c.lock(chatID)
+	if data == nil {
 		return 0
 	}
+	defer data.mu.Unlock()
 	return len(data.Queue)
 }
 
 // GetLoopCount retrieves the loop count for the currently playing song in a chat.
 func (c *ChatCacher) GetLoopCount(chatID int64) int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	data, ok := c.chatCache[chatID]
-	if !ok || len(data.Queue) == 0 {
+	data := c.lock(chatID)
+	if data == nil {
+		return 0
+	}
+	defer data.mu.Unlock()
+	if len(data.Queue) == 0 {
 		return 0
 	}
 	return data.Queue[0].Loop
@@ -153,69 +387,308 @@ func (c *ChatCacher) GetLoopCount(chatID int64) int {
 // SetLoopCount sets the loop count for the currently playing song.
 // It returns true if the loop count was successfully set, otherwise false.
 func (c *ChatCacher) SetLoopCount(chatID int64, loop int) bool {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	data, ok := c.chatCache[chatID]
-	if !ok || len(data.Queue) == 0 {
+	data := c.lock(chatID)
+	if data == nil {
+		return false
+	}
+	defer data.mu.Unlock()
+	if len(data.Queue) == 0 {
 		return false
 	}
 	data.Queue[0].Loop = loop
 	return true
 }
 
+// RemoveAt removes a track from the queue by its index and returns it.
+// The currently playing track (index 0) cannot be removed this way.
+func (c *ChatCacher) RemoveAt(chatID int64, index int) (*CachedTrack, bool) {
+	data := c.lock(chatID)
+	if data == nil {
+		return nil, false
+	}
+	if index < 1 || index >= len(data.Queue) {
+		data.mu.Unlock()
+		return nil, false
+	}
+	removed := data.Queue[index]
+	data.Queue = append(data.Queue[:index], data.Queue[index+1:]...)
+	length := len(data.Queue)
+	data.mu.Unlock()
+
+	c.notify(chatID, QueueRemoved, length)
+	return removed, true
+}
+
 // RemoveTrack removes a specific song from the queue by its index.
 // It returns true if the track was successfully removed, otherwise false.
 func (c *ChatCacher) RemoveTrack(chatID int64, index int) bool {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	_, ok := c.RemoveAt(chatID, index)
+	return ok
+}
 
-	data, ok := c.chatCache[chatID]
-	if !ok || index < 0 || index >= len(data.Queue) {
+// Move moves a pending track from one queue index to another.
+// Both indexes must point at pending tracks; the currently playing track cannot be moved.
+func (c *ChatCacher) Move(chatID int64, from, to int) bool {
+	data := c.lock(chatID)
+	if data == nil {
 		return false
 	}
+	n := len(data.Queue)
+	if from < 1 || from >= n || to < 1 || to >= n {
+		data.mu.Unlock()
+		return false
+	}
+	if from != to {
+		track := data.Queue[from]
+		data.Queue = append(data.Queue[:from], data.Queue[from+1:]...)
+		data.Queue = append(data.Queue[:to], append([]*CachedTrack{track}, data.Queue[to:]...)...)
+	}
+	data.mu.Unlock()
 
-	data.Queue = append(data.Queue[:index], data.Queue[index+1:]...)
+	c.notify(chatID, QueueMoved, n)
 	return true
 }
 
+// Shuffle randomizes the order of the pending tracks, leaving the current one in place.
+// It returns false if there are fewer than two pending tracks.
+func (c *ChatCacher) Shuffle(chatID int64) bool {
+	data := c.lock(chatID)
+	if data == nil {
+		return false
+	}
+	n := len(data.Queue)
+	if n < 3 {
+		data.mu.Unlock()
+		return false
+	}
+	pending := data.Queue[1:]
+	rand.Shuffle(len(pending), func(i, j int) { pending[i], pending[j] = pending[j], pending[i] })
+	data.mu.Unlock()
+
+	c.notify(chatID, QueueShuffled, n)
+	return true
+}
+
+// SetTrackFile stores a downloaded file path on a queued track.
+// It returns false if the track is no longer queued or already has a file.
+func (c *ChatCacher) SetTrackFile(chatID int64, track *CachedTrack, path string) bool {
+	data := c.lock(chatID)
+	if data == nil {
+		return false
+	}
+	defer data.mu.Unlock()
+	for _, t := range data.Queue {
+		if t == track {
+			if t.FilePath != "" {
+				return false
+			}
+			t.FilePath = path
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateTrack runs update on a queued track under the chat's lock, so that fields other goroutines read,
+// such as FilePath, can be changed safely. It reports false if the track is no longer queued.
+func (c *ChatCacher) UpdateTrack(chatID int64, track *CachedTrack, update func(t *CachedTrack)) bool {
+	data := c.lock(chatID)
+	if data == nil {
+		return false
+	}
+	defer data.mu.Unlock()
+	if !slices.Contains(data.Queue, track) {
+		return false
+	}
+	update(track)
+	return true
+}
+
+// TrackFile returns the downloaded file path of a track. The path of a queued track is read under the chat's
+// lock, as SetTrackFile may set it from another goroutine.
+func (c *ChatCacher) TrackFile(chatID int64, track *CachedTrack) string {
+	data := c.lock(chatID)
+	if data == nil {
+		return track.FilePath
+	}
+	defer data.mu.Unlock()
+	return track.FilePath
+}
+
 // GetQueue returns a copy of the current song queue for a chat.
 func (c *ChatCacher) GetQueue(chatID int64) []*CachedTrack {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	data, ok := c.chatCache[chatID]
-	if !ok {
+	data := c.lock(chatID)
+	if data == nil {
 		return []*CachedTrack{}
 	}
+	defer data.mu.Unlock()
 	return append([]*CachedTrack(nil), data.Queue...)
 }
 
-// GetActiveChats returns a list of all chat IDs where the music player is currently active.
-func (c *ChatCacher) GetActiveChats() []int64 {
+// ETAs returns how many seconds from now each queued track starts playing, given that the current track is
+// elapsed seconds in. The current track's remaining loops are included. Tracks queued after one of unknown
+// duration get -1.
+func (c *ChatCacher) ETAs(chatID int64, elapsed int) []int {
+	queue := c.GetQueue(chatID)
+	etas := make([]int, len(queue))
+	if len(queue) == 0 {
+		return etas
+	}
+
+	current := queue[0]
+	wait := -1
+	if current.Duration > 0 {
+		wait = max(current.Duration-elapsed, 0) + current.Loop*current.Duration
+	}
+	for i := 1; i < len(queue); i++ {
+		etas[i] = wait
+		if wait < 0 || queue[i].Duration <= 0 {
+			wait = -1
+			continue
+		}
+		wait += queue[i].Duration
+	}
+	return etas
+}
+
+// snapshot returns every chat's data while holding the map lock only briefly.
+func (c *ChatCacher) snapshot() map[int64]*ChatData {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	var active []int64
+	chats := make(map[int64]*ChatData, len(c.chatCache))
 	for chatID, data := range c.chatCache {
+		chats[chatID] = data
+	}
+	return chats
+}
+
+// GetActiveChats returns a list of all chat IDs where the music player is currently active.
+func (c *ChatCacher) GetActiveChats() []int64 {
+	var active []int64
+	for chatID, data := range c.snapshot() {
+		data.mu.Lock()
 		if data.IsActive {
 			active = append(active, chatID)
 		}
+		data.mu.Unlock()
 	}
 	return active
 }
 
-// GetTrackIfExists searches for a track in the queue by its ID and returns it if found.
-// It returns the track or nil if it does not exist in the queue.
-func (c *ChatCacher) GetTrackIfExists(chatID int64, trackID string) *CachedTrack {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// IdleChats returns the chats that have an entry but neither play nor hold any tracks.
+func (c *ChatCacher) IdleChats() []int64 {
+	var idle []int64
+	for chatID, data := range c.snapshot() {
+		data.mu.Lock()
+		if !data.IsActive && len(data.Queue) == 0 {
+			idle = append(idle, chatID)
+		}
+		data.mu.Unlock()
+	}
+	return idle
+}
+
+// Forget removes a chat's entry if it still neither plays nor holds any tracks, and reports whether it did.
+// Unlike ClearChat it does not notify listeners, as there is no queue to clear.
+func (c *ChatCacher) Forget(chatID int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	data, ok := c.chatCache[chatID]
 	if !ok {
-		return nil
+		return false
 	}
+	data.mu.Lock()
+	defer data.mu.Unlock()
+	if data.IsActive || len(data.Queue) > 0 {
+		return false
+	}
+	data.removed = true
+	delete(c.chatCache, chatID)
+	return true
+}
+
+// ForgetUser clears the requester of every queued track userID requested, in every chat, and returns how
+// many tracks it changed.
+func (c *ChatCacher) ForgetUser(userID int64) int {
+	var n int
+	for _, data := range c.snapshot() {
+		data.mu.Lock()
+		for _, t := range data.Queue {
+			if t.UserID == userID {
+				t.UserID, t.User = 0, ""
+				n++
+			}
+		}
+		data.mu.Unlock()
+	}
+	return n
+}
 
+// Size returns how many chats have an entry, playing or not.
+func (c *ChatCacher) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.chatCache)
+}
+
+// ChatSession is a point-in-time snapshot of a chat's playback session.
+type ChatSession struct {
+	ChatID      int64
+	Current     *CachedTrack
+	QueueLength int
+}
+
+// Sessions returns a snapshot of every active playback session, ordered by chat ID.
+// The returned values are copies and can be used without holding the cache lock.
+func (c *ChatCacher) Sessions() []ChatSession {
+	chats := c.snapshot()
+	sessions := make([]ChatSession, 0, len(chats))
+	for chatID, data := range chats {
+		data.mu.Lock()
+		if !data.IsActive {
+			data.mu.Unlock()
+			continue
+		}
+
+		session := ChatSession{ChatID: chatID, QueueLength: len(data.Queue)}
+		if len(data.Queue) > 0 {
+			current := *data.Queue[0]
+			session.Current = &current
+		}
+		data.mu.Unlock()
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ChatID < sessions[j].ChatID })
+	return sessions
+}
+
+// TrackPosition returns the queue position of the track with the given platform and ID,
+// where 0 is the track that is playing. It returns -1 if the track is not queued.
+func (c *ChatCacher) TrackPosition(chatID int64, platform, trackID string) int {
+	data := c.lock(chatID)
+	if data == nil {
+		return -1
+	}
+	defer data.mu.Unlock()
+	for i, t := range data.Queue {
+		if t.Platform == platform && t.TrackID == trackID {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetTrackIfExists searches for a track in the queue by its ID and returns it if found.
+// It returns the track or nil if it does not exist in the queue.
+func (c *ChatCacher) GetTrackIfExists(chatID int64, trackID string) *CachedTrack {
+	data := c.lock(chatID)
+	if data == nil {
+		return nil
+	}
+	defer data.mu.Unlock()
 	for _, t := range data.Queue {
 		if t.TrackID == trackID {
 			return t
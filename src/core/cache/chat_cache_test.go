@@ -0,0 +1,203 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func track(id int) *CachedTrack {
+	return &CachedTrack{TrackID: strconv.Itoa(id), Duration: 60}
+}
+
+func TestEnqueueRespectsLimit(t *testing.T) {
+	c := NewChatCacher()
+	c.SetMaxLength(3)
+
+	// The playing track does not count, so four tracks fit.
+	for i := range 4 {
+		if pos, err := c.Enqueue(1, track(i)); err != nil || pos != i {
+			t.Fatalf("Enqueue %d = %d, %v", i, pos, err)
+		}
+	}
+	if _, err := c.Enqueue(1, track(4)); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Enqueue past the limit: err = %v, want ErrQueueFull", err)
+	}
+	if err := c.InsertNext(1, track(5)); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("InsertNext past the limit: err = %v, want ErrQueueFull", err)
+	}
+
+	c.Clear(1)
+	first, added := c.EnqueueMany(1, []*CachedTrack{track(6), track(7), track(8), track(9)})
+	if first != 1 || added != 3 {
+		t.Fatalf("EnqueueMany = %d, %d, want 1, 3", first, added)
+	}
+}
+
+func TestConcurrentEnqueueNeverExceedsLimit(t *testing.T) {
+	c := NewChatCacher()
+	c.SetMaxLength(50)
+
+	var ok atomic.Int64
+	var wg sync.WaitGroup
+	for i := range 200 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Enqueue(1, track(i)); err == nil {
+				ok.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := c.GetQueueLength(1); n != 51 || ok.Load() != 51 {
+		t.Fatalf("queue length = %d, successful enqueues = %d, want 51 each", n, ok.Load())
+	}
+}
+
+func TestConcurrentMutation(t *testing.T) {
+	c := NewChatCacher()
+	c.SetMaxLength(20)
+
+	var changes atomic.Int64
+	c.OnChange(func(QueueChange) { changes.Add(1) })
+
+	var wg sync.WaitGroup
+	ops := []func(i int){
+		func(i int) { _, _ = c.Enqueue(1, track(i)) },
+		func(i int) { _ = c.InsertNext(1, track(i)) },
+		func(i int) { c.EnqueueMany(1, []*CachedTrack{track(i), track(i + 1)}) },
+		func(int) { c.RemoveAt(1, 1) },
+		func(int) { c.Move(1, 1, 2) },
+		func(int) { c.Shuffle(1) },
+		func(int) { c.Next(1) },
+		func(int) { c.Clear(1) },
+		func(int) { c.ClearChat(1) },
+		func(int) { c.GetQueue(1) },
+		func(int) { c.ETAs(1, 10) },
+		func(int) { c.Sessions() },
+	}
+	for i := range 600 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ops[i%len(ops)](i)
+		}()
+	}
+	wg.Wait()
+
+	if n := c.GetQueueLength(1); n > 21 {
+		t.Fatalf("queue length = %d, want at most 21", n)
+	}
+	if changes.Load() == 0 {
+		t.Fatal("no change notifications were delivered")
+	}
+}
+
+func TestMutationRetriesOnRemovedEntry(t *testing.T) {
+	c := NewChatCacher()
+	c.SetActive(1, true)
+	data := c.get(1)
+
+	// Hold the chat's lock so that Enqueue finds the entry and then waits for it.
+	data.mu.Lock()
+	done := make(chan struct{})
+	go func() {
+		_, _ = c.Enqueue(1, track(1))
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// Remove the entry the way ClearChat does, while Enqueue still waits on it.
+	c.mu.Lock()
+	data.removed = true
+	delete(c.chatCache, 1)
+	c.mu.Unlock()
+	data.mu.Unlock()
+	<-done
+
+	if len(data.Queue) != 0 {
+		t.Fatal("Enqueue mutated the removed entry")
+	}
+	if c.GetTrackIfExists(1, "1") == nil {
+		t.Fatal("Enqueue did not add the track to the new entry")
+	}
+}
+
+func TestForgetKeepsBusyChats(t *testing.T) {
+	c := NewChatCacher()
+	c.SetActive(1, false)
+	_, _ = c.Enqueue(2, track(1))
+
+	if !c.Forget(1) {
+		t.Fatal("Forget kept an idle chat")
+	}
+	if c.Forget(2) {
+		t.Fatal("Forget dropped a chat with tracks")
+	}
+	if c.Size() != 1 {
+		t.Fatalf("Size = %d, want 1", c.Size())
+	}
+}
+
+func TestUpdateTrack(t *testing.T) {
+	c := NewChatCacher()
+	queued, other := track(1), track(2)
+	_, _ = c.Enqueue(1, queued)
+
+	if !c.UpdateTrack(1, queued, func(t *CachedTrack) { t.FilePath = "a.mp3" }) {
+		t.Fatal("UpdateTrack skipped a queued track")
+	}
+	if c.TrackFile(1, queued) != "a.mp3" {
+		t.Fatalf("TrackFile = %q", c.TrackFile(1, queued))
+	}
+	if c.UpdateTrack(1, other, func(t *CachedTrack) { t.FilePath = "b.mp3" }) || other.FilePath != "" {
+		t.Fatal("UpdateTrack changed a track that is not queued")
+	}
+}
+
+func TestForgetUser(t *testing.T) {
+	c := NewChatCacher()
+	h := NewTrackHistory(DefaultHistorySize)
+	mine := func(id int) *CachedTrack {
+		tr := track(id)
+		tr.UserID, tr.User = 7, "Asha"
+		return tr
+	}
+	_, _ = c.Enqueue(1, mine(1))
+	_, _ = c.Enqueue(1, track(2))
+	_, _ = c.Enqueue(2, mine(3))
+	h.Push(1, mine(4))
+	h.Push(1, track(5))
+
+	if n := c.ForgetUser(7); n != 2 {
+		t.Fatalf("ChatCacher.ForgetUser = %d, want 2", n)
+	}
+	if n := h.ForgetUser(7); n != 1 {
+		t.Fatalf("TrackHistory.ForgetUser = %d, want 1", n)
+	}
+	for _, chatID := range []int64{1, 2} {
+		for _, tr := range c.GetQueue(chatID) {
+			if tr.UserID == 7 || tr.User != "" {
+				t.Fatalf("chat %d still has %s requested by %d %q", chatID, tr.TrackID, tr.UserID, tr.User)
+			}
+		}
+	}
+	for tr := h.Pop(1); tr != nil; tr = h.Pop(1) {
+		if tr.UserID == 7 || tr.User != "" {
+			t.Fatalf("history still has %s requested by %d %q", tr.TrackID, tr.UserID, tr.User)
+		}
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os/exec"
 	"time"
 
@@ -49,7 +48,7 @@ func GetFileDur(m *tg.NewMessage) int {
 func getDocumentDuration(media *tg.MessageMediaDocument) int {
 	doc, ok := media.Document.(*tg.DocumentObj)
 	if !ok {
-		log.Printf("Unsupported document type: %T", media.Document)
+		logger.Debug("Unsupported document type: %T", media.Document)
 		return 0
 	}
 
@@ -63,9 +62,9 @@ func getDocumentDuration(media *tg.MessageMediaDocument) int {
 	}
 
 	if len(doc.Attributes) > 0 {
-		log.Printf("No supported duration attributes found in (%T): %#v", media, doc.Attributes)
+		logger.Debug("No supported duration attributes found in (%T): %#v", media, doc.Attributes)
 	} else {
-		log.Print("No attributes found in the document.")
+		logger.Debug("No attributes found in the document.")
 	}
 
 	return 0
@@ -76,6 +75,7 @@ func getDocumentDuration(media *tg.MessageMediaDocument) int {
 func GetFileDuration(filePath string) int {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	defer logger.Time(ctx, "exec", "ffprobe", filePath)()
 
 	cmd := exec.CommandContext(ctx, "ffprobe",
 		"-v", "quiet",
@@ -87,20 +87,20 @@ func GetFileDuration(filePath string) int {
 
 	output, err := cmd.Output()
 	if err != nil {
-		log.Printf("Failed to get audio duration with ffprobe: %v", err)
+		logger.Warn("Failed to get audio duration with ffprobe: %v", err)
 		return 0
 	}
 
 	var info FFProbeFormat
 	if err := json.Unmarshal(output, &info); err != nil {
-		log.Printf("Failed to parse ffprobe's JSON output: %v", err)
+		logger.Warn("Failed to parse ffprobe's JSON output: %v", err)
 		return 0
 	}
 
 	var duration float64
 	if info.Format.Duration != "" {
 		if _, err := fmt.Sscanf(info.Format.Duration, "%f", &duration); err != nil {
-			log.Printf("Could not parse duration format: %v", err)
+			logger.Warn("Could not parse duration format: %v", err)
 			return 0
 		}
 	}
@@ -0,0 +1,55 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package cache
+
+import "sync"
+
+// FileRefCounter counts how many queue entries hold a downloaded file,
+// so cleanup never removes a file that is still queued or playing.
+type FileRefCounter struct {
+	mu   sync.Mutex
+	refs map[string]int
+}
+
+// NewFileRefCounter initializes and returns a new FileRefCounter.
+func NewFileRefCounter() *FileRefCounter {
+	return &FileRefCounter{refs: make(map[string]int)}
+}
+
+// Acquire records one more holder of the file at path.
+func (f *FileRefCounter) Acquire(path string) {
+	if path == "" {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refs[path]++
+}
+
+// Release drops one holder of the file at path.
+// It returns the number of holders left.
+func (f *FileRefCounter) Release(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, ok := f.refs[path]
+	if !ok {
+		return 0
+	}
+	if n <= 1 {
+		delete(f.refs, path)
+		return 0
+	}
+	f.refs[path] = n - 1
+	return n - 1
+}
+
+// FileRefs is the global file reference counter.
+var FileRefs = NewFileRefCounter()
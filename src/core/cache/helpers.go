@@ -10,14 +10,17 @@ package cache
 
 import (
 	"fmt"
-	"log"
+
+	"ashokshau/tgmusic/src/core/logging"
 )
 
+var logger = logging.For("cache")
+
 // SecToMin converts a duration in seconds to a formatted string (MM:SS or HH:MM:SS).
 // It returns "0:00" for negative inputs and logs a warning.
 func SecToMin(seconds int) string {
 	if seconds < 0 {
-		log.Println("Warning: SecToMin received a negative duration.")
+		logger.Warn("SecToMin received a negative duration.")
 		return "0:00"
 	}
 
@@ -30,3 +33,25 @@ func SecToMin(seconds int) string {
 	}
 	return fmt.Sprintf("%d:%02d", minutes, secs)
 }
+
+// PlatformName returns a human-readable name for a platform identifier.
+func PlatformName(platform string) string {
+	switch platform {
+	case Telegram:
+		return "Telegram"
+	case YouTube:
+		return "YouTube"
+	case Spotify:
+		return "Spotify"
+	case JioSaavn:
+		return "JioSaavn"
+	case Apple:
+		return "Apple Music"
+	case SoundCloud:
+		return "SoundCloud"
+	case "":
+		return "Unknown"
+	default:
+		return platform
+	}
+}
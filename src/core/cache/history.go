@@ -0,0 +1,136 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is how many finished tracks are remembered per chat.
+const DefaultHistorySize = 10
+
+// TrackHistory keeps a short stack of recently finished tracks for every chat.
+// Entries are copies, so they keep the URL, track ID and platform needed to download a track again.
+type TrackHistory struct {
+	mu     sync.Mutex
+	size   int
+	tracks map[int64][]historyEntry
+}
+
+// historyEntry is a finished track and when it finished.
+type historyEntry struct {
+	track CachedTrack
+	at    time.Time
+}
+
+// NewTrackHistory initializes and returns a new TrackHistory holding up to size tracks per chat.
+func NewTrackHistory(size int) *TrackHistory {
+	return &TrackHistory{size: size, tracks: make(map[int64][]historyEntry)}
+}
+
+// Push records a finished track, dropping the oldest entry once the stack is full.
+func (h *TrackHistory) Push(chatID int64, track *CachedTrack) {
+	if track == nil {
+		return
+	}
+
+	entry := *track
+	entry.Loop = 0
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	stack := append(h.tracks[chatID], historyEntry{track: entry, at: time.Now()})
+	if len(stack) > h.size {
+		stack = stack[len(stack)-h.size:]
+	}
+	h.tracks[chatID] = stack
+}
+
+// Pop removes and returns the most recently finished track, or nil if there is none.
+func (h *TrackHistory) Pop(chatID int64) *CachedTrack {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	stack := h.tracks[chatID]
+	if len(stack) == 0 {
+		return nil
+	}
+
+	track := stack[len(stack)-1].track
+	if len(stack) == 1 {
+		delete(h.tracks, chatID)
+	} else {
+		h.tracks[chatID] = stack[:len(stack)-1]
+	}
+	return &track
+}
+
+// Len returns how many finished tracks are remembered for a chat.
+func (h *TrackHistory) Len(chatID int64) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.tracks[chatID])
+}
+
+// ForgetUser clears the requester of every finished track userID requested and returns how many it changed.
+func (h *TrackHistory) ForgetUser(userID int64) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var n int
+	for _, stack := range h.tracks {
+		for i := range stack {
+			if stack[i].track.UserID == userID {
+				stack[i].track.UserID, stack[i].track.User = 0, ""
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// PlayedWithin reports whether the track with the given platform and ID finished in the chat within the last window.
+func (h *TrackHistory) PlayedWithin(chatID int64, platform, trackID string, window time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cutoff := time.Now().Add(-window)
+	for _, e := range h.tracks[chatID] {
+		if e.at.After(cutoff) && e.track.Platform == platform && e.track.TrackID == trackID {
+			return true
+		}
+	}
+	return false
+}
+
+// Clear forgets a chat's history.
+func (h *TrackHistory) Clear(chatID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.tracks, chatID)
+}
+
+// Chats returns the chats that have a history.
+func (h *TrackHistory) Chats() []int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	chats := make([]int64, 0, len(h.tracks))
+	for chatID := range h.tracks {
+		chats = append(chats, chatID)
+	}
+	return chats
+}
+
+// Size returns how many chats have a history.
+func (h *TrackHistory) Size() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.tracks)
+}
+
+// History is the global history of finished tracks.
+var History = NewTrackHistory(DefaultHistorySize)
@@ -0,0 +1,87 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/reaper"
+)
+
+// purgeable is a Cache of any value type.
+type purgeable interface {
+	Len() int
+	Purge(now time.Time) int
+}
+
+var (
+	cachesMu sync.Mutex
+	caches   []purgeable
+)
+
+// registerCache lets the reaper purge the expired items of c.
+func registerCache(c purgeable) {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+	caches = append(caches, c)
+}
+
+// allCaches returns every Cache created so far.
+func allCaches() []purgeable {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+	return append([]purgeable(nil), caches...)
+}
+
+func init() {
+	reaper.Register(reaper.Pool{
+		Name: "cache items",
+		Len: func() int {
+			n := 0
+			for _, c := range allCaches() {
+				n += c.Len()
+			}
+			return n
+		},
+		Purge: func(now time.Time) int {
+			n := 0
+			for _, c := range allCaches() {
+				n += c.Purge(now)
+			}
+			return n
+		},
+	})
+	reaper.Register(reaper.Pool{
+		Name: "chat queues",
+		Len:  ChatCache.Size,
+		Idle: ChatCache.IdleChats,
+		Drop: ChatCache.Forget,
+	})
+	reaper.Register(reaper.Pool{
+		Name: "track histories",
+		Len:  History.Size,
+		Idle: func() []int64 {
+			var idle []int64
+			for _, chatID := range History.Chats() {
+				if !ChatCache.IsActive(chatID) {
+					idle = append(idle, chatID)
+				}
+			}
+			return idle
+		},
+		Drop: func(chatID int64) bool {
+			if ChatCache.IsActive(chatID) {
+				return false
+			}
+			History.Clear(chatID)
+			return true
+		},
+	})
+}
@@ -15,6 +15,7 @@ type CachedTrack struct {
 	Name      string `json:"name"`
 	Loop      int    `json:"loop"`
 	User      string `json:"user"`
+	UserID    int64  `json:"user_id,omitempty"`
 	FilePath  string `json:"file_path"`
 	Thumbnail string `json:"thumbnail"`
 	TrackID   string `json:"track_id"`
@@ -22,6 +23,7 @@ type CachedTrack struct {
 	Lyrics    string `json:"lyrics"`
 	IsVideo   bool   `json:"is_video"`
 	Platform  string `json:"platform"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // TrackInfo holds detailed information about a specific track, including its CDN URL, cover art, and lyrics.
@@ -54,11 +56,12 @@ type PlatformTracks struct {
 }
 
 const (
-	Telegram = "telegram"
-	YouTube  = "youtube"
-	Spotify  = "spotify"
-	JioSaavn = "jiosaavn"
-	Apple    = "apple_music"
+	Telegram   = "telegram"
+	YouTube    = "youtube"
+	Spotify    = "spotify"
+	JioSaavn   = "jiosaavn"
+	Apple      = "apple_music"
+	SoundCloud = "soundcloud"
 )
 
 const (
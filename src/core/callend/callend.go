@@ -0,0 +1,44 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package callend decides how the player reacts when a chat's voice chat is ended under it.
+package callend
+
+// Session is what the player knows about a chat whose voice chat was ended.
+type Session struct {
+	// Mirroring is set if the chat relays another chat's stream.
+	Mirroring bool
+	// Active is set while a track is playing or paused.
+	Active bool
+	// Idle is set while the assistant stays in the voice chat with nothing to play, either waiting for new
+	// tracks until the inactivity timeout or held there by 24/7 radio mode.
+	Idle bool
+}
+
+// Action is how the player reacts to the end of a voice chat.
+type Action int
+
+const (
+	// Ignore leaves the chat alone; the bot had no session there.
+	Ignore Action = iota
+	// DegradeMirror marks the chat's mirror link degraded.
+	DegradeMirror
+	// Teardown ends the chat's session.
+	Teardown
+)
+
+// Decide returns how the player reacts to the end of the chat's voice chat.
+func Decide(s Session) Action {
+	switch {
+	case s.Mirroring:
+		return DegradeMirror
+	case s.Active, s.Idle:
+		return Teardown
+	}
+	return Ignore
+}
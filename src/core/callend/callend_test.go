@@ -0,0 +1,32 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package callend
+
+import "testing"
+
+func TestDecide(t *testing.T) {
+	tests := []struct {
+		name    string
+		session Session
+		want    Action
+	}{
+		{"no session", Session{}, Ignore},
+		{"playing", Session{Active: true}, Teardown},
+		{"paused", Session{Active: true, Idle: false}, Teardown},
+		{"idle or held by 24/7 radio", Session{Idle: true}, Teardown},
+		{"mirror", Session{Mirroring: true, Active: true}, DegradeMirror},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Decide(tt.session); got != tt.want {
+				t.Errorf("Decide(%+v) = %d, want %d", tt.session, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package cbroute encodes routed callback data and decides whether a button press may be handled.
+//
+// Routed callback data has the form "cb:<route>:<boot>:<token>:<arg>...". The boot ID ties a button to the
+// process that rendered it, and the token ties it to the state it was rendered for.
+package cbroute
+
+import "strings"
+
+const (
+	// Prefix starts all routed callback data.
+	Prefix = "cb:"
+	sep    = ":"
+)
+
+// Rejection lang keys returned by Router.Resolve and Check.
+const (
+	Invalid = "invalid_request"
+	Stale   = "callback_stale"
+)
+
+// Data is decoded callback data.
+type Data struct {
+	Route string
+	Boot  string
+	Token string
+	Args  []string
+}
+
+// Arg returns the i-th argument, or an empty string if it is missing.
+func (d Data) Arg(i int) string {
+	if i < 0 || i >= len(d.Args) {
+		return ""
+	}
+	return d.Args[i]
+}
+
+// Encode builds routed callback data.
+func Encode(route, boot, token string, args ...string) string {
+	parts := append([]string{route, boot, token}, args...)
+	return Prefix + strings.Join(parts, sep)
+}
+
+// Parse decodes routed callback data. It reports false if data is not routed callback data.
+func Parse(data string) (Data, bool) {
+	rest, ok := strings.CutPrefix(data, Prefix)
+	if !ok {
+		return Data{}, false
+	}
+	parts := strings.Split(rest, sep)
+	if len(parts) < 3 || parts[0] == "" {
+		return Data{}, false
+	}
+	return Data{Route: parts[0], Boot: parts[1], Token: parts[2], Args: parts[3:]}, true
+}
+
+// Router maps route names to routes of type R for the process identified by boot.
+type Router[R any] struct {
+	boot    string
+	routes  map[string]R
+	durable map[string]bool
+}
+
+// New returns an empty router for the process identified by boot.
+func New[R any](boot string) *Router[R] {
+	return &Router[R]{boot: boot, routes: map[string]R{}, durable: map[string]bool{}}
+}
+
+// Register adds a route. Routes are registered from init functions, before any callback is routed.
+func (r *Router[R]) Register(name string, route R) {
+	r.routes[name] = route
+}
+
+// RegisterDurable adds a route whose buttons stay valid across restarts, for buttons that act on stored
+// state rather than on state held by the process that rendered them.
+func (r *Router[R]) RegisterDurable(name string, route R) {
+	r.Register(name, route)
+	r.durable[name] = true
+}
+
+// Data builds callback data for a button of the named route.
+func (r *Router[R]) Data(route, token string, args ...string) string {
+	return Encode(route, r.boot, token, args...)
+}
+
+// Resolve decodes data and finds its route. It returns the Invalid lang key if data is malformed or names an
+// unknown route.
+func (r *Router[R]) Resolve(data string) (R, Data, string) {
+	var zero R
+	d, ok := Parse(data)
+	if !ok {
+		return zero, Data{}, Invalid
+	}
+	route, ok := r.routes[d.Route]
+	if !ok {
+		return zero, d, Invalid
+	}
+	return route, d, ""
+}
+
+// Check decides whether a resolved press may be handled. The presser's permission is checked first, so that
+// users who may not use a button learn that rather than that it is stale; then the boot ID, unless the route
+// is durable, and, if token is not nil, the state token. It returns an empty string or the lang key to reject
+// the press with.
+func (r *Router[R]) Check(d Data, allow func() string, token func() string) string {
+	if allow != nil {
+		if reason := allow(); reason != "" {
+			return reason
+		}
+	}
+	if (d.Boot != r.boot && !r.durable[d.Route]) || (token != nil && token() != d.Token) {
+		return Stale
+	}
+	return ""
+}
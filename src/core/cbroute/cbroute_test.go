@@ -0,0 +1,109 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package cbroute
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEncodeParse(t *testing.T) {
+	data := Encode("q", "b00t", "tok", "2", "x")
+	if data != "cb:q:b00t:tok:2:x" {
+		t.Fatalf("Encode = %q", data)
+	}
+	d, ok := Parse(data)
+	if !ok {
+		t.Fatal("Parse rejected encoded data")
+	}
+	if d.Route != "q" || d.Boot != "b00t" || d.Token != "tok" || !slices.Equal(d.Args, []string{"2", "x"}) {
+		t.Fatalf("Parse = %+v", d)
+	}
+	if d.Arg(1) != "x" || d.Arg(2) != "" || d.Arg(-1) != "" {
+		t.Fatalf("Arg out of range handling is wrong: %+v", d)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	for _, data := range []string{"", "q:b:t", "cb:", "cb:q", "cb:q:b", "cb::b:t"} {
+		if _, ok := Parse(data); ok {
+			t.Errorf("Parse(%q) accepted malformed data", data)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	r := New[string]("boot")
+	r.Register("q", "queue")
+
+	route, d, reason := r.Resolve(r.Data("q", "tok", "1"))
+	if reason != "" || route != "queue" || d.Arg(0) != "1" {
+		t.Fatalf("Resolve = %q, %+v, %q", route, d, reason)
+	}
+	if _, _, reason := r.Resolve(r.Data("nope", "tok")); reason != Invalid {
+		t.Errorf("unknown route: reason = %q, want %q", reason, Invalid)
+	}
+	if _, _, reason := r.Resolve("settings_play_admins"); reason != Invalid {
+		t.Errorf("unrouted data: reason = %q, want %q", reason, Invalid)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	r := New[string]("boot")
+	token := func(s string) func() string { return func() string { return s } }
+	deny := func() string { return "denied" }
+
+	tests := []struct {
+		name  string
+		data  string
+		allow func() string
+		token func() string
+		want  string
+	}{
+		{"fresh", r.Data("q", "t1"), nil, token("t1"), ""},
+		{"no token check", r.Data("q", "t1"), nil, nil, ""},
+		{"state changed", r.Data("q", "t1"), nil, token("t2"), Stale},
+		{"other process", Encode("q", "old", "t1"), nil, token("t1"), Stale},
+		{"other process without token", Encode("q", "old", "t1"), nil, nil, Stale},
+		{"denied", r.Data("q", "t1"), deny, token("t1"), "denied"},
+		{"denied before stale", Encode("q", "old", "t1"), deny, token("t2"), "denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := Parse(tt.data)
+			if !ok {
+				t.Fatalf("Parse(%q) failed", tt.data)
+			}
+			if got := r.Check(d, tt.allow, tt.token); got != tt.want {
+				t.Errorf("Check = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurableRouteSurvivesRestart(t *testing.T) {
+	r := New[string]("boot")
+	r.RegisterDurable("rt", "report")
+	r.Register("q", "queue")
+
+	old, _ := Parse(Encode("rt", "old", "t1"))
+	if got := r.Check(old, nil, nil); got != "" {
+		t.Fatalf("durable route from an earlier process: Check = %q, want accepted", got)
+	}
+	if got := r.Check(old, nil, func() string { return "t2" }); got != Stale {
+		t.Fatalf("durable route with a changed token: Check = %q, want %q", got, Stale)
+	}
+	if got := r.Check(old, func() string { return "denied" }, nil); got != "denied" {
+		t.Fatalf("durable route: Check = %q, want the denial", got)
+	}
+	other, _ := Parse(Encode("q", "old", "t1"))
+	if got := r.Check(other, nil, nil); got != Stale {
+		t.Fatalf("other route from an earlier process: Check = %q, want %q", got, Stale)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package cleaner
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/db"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// deletion is a pending deletion of one or more messages in a chat.
+type deletion struct {
+	at     time.Time
+	client *tg.Client
+	chatID int64
+	msgIDs []int32
+}
+
+// deletionHeap is a min-heap of pending deletions ordered by their due time.
+type deletionHeap []*deletion
+
+func (h deletionHeap) Len() int           { return len(h) }
+func (h deletionHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h deletionHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *deletionHeap) Push(x any)        { *h = append(*h, x.(*deletion)) }
+func (h *deletionHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// Scheduler deletes messages once their delay has passed.
+// A single goroutine drains a timer heap, so scheduling is cheap regardless of how many messages are pending.
+// Pending deletions live only in memory and are lost on restart.
+type Scheduler struct {
+	mu      sync.Mutex
+	pending deletionHeap
+	wake    chan struct{}
+	once    sync.Once
+}
+
+// NewScheduler creates a new Scheduler. Its worker goroutine is started on first use.
+func NewScheduler() *Scheduler {
+	return &Scheduler{wake: make(chan struct{}, 1)}
+}
+
+// Schedule queues the given messages for deletion after delay.
+// Non-positive delays and empty ID lists are ignored.
+func (s *Scheduler) Schedule(client *tg.Client, chatID int64, delay time.Duration, msgIDs ...int32) {
+	if client == nil || delay <= 0 || len(msgIDs) == 0 {
+		return
+	}
+	s.once.Do(func() { go s.run() })
+
+	s.mu.Lock()
+	heap.Push(&s.pending, &deletion{at: time.Now().Add(delay), client: client, chatID: chatID, msgIDs: msgIDs})
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run is the worker loop. It sleeps until the earliest deletion is due or a new one is scheduled.
+func (s *Scheduler) run() {
+	timer := time.NewTimer(time.Hour)
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		var due []*deletion
+		for s.pending.Len() > 0 && !s.pending[0].at.After(now) {
+			due = append(due, heap.Pop(&s.pending).(*deletion))
+		}
+		wait := time.Hour
+		if s.pending.Len() > 0 {
+			wait = s.pending[0].at.Sub(now)
+		}
+		s.mu.Unlock()
+
+		for _, d := range due {
+			// The messages may already be gone; there is nothing useful to do on failure.
+			_, _ = d.client.DeleteMessages(d.chatID, d.msgIDs)
+		}
+
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Default is the global deletion scheduler.
+var Default = NewScheduler()
+
+// ChatDelay returns how long the bot's service messages should live in a chat according to its clean mode.
+// A zero duration means clean mode is off.
+func ChatDelay(chatID int64) time.Duration {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return time.Duration(db.Instance.GetCleanMode(ctx, chatID)) * time.Second
+}
+
+// ScheduleChat queues messages for deletion using the chat's clean mode delay.
+func ScheduleChat(client *tg.Client, chatID int64, msgIDs ...int32) {
+	Default.Schedule(client, chatID, ChatDelay(chatID), msgIDs...)
+}
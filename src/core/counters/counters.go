@@ -0,0 +1,93 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package counters keeps in-memory event counts for the daily digest.
+package counters
+
+import (
+	"sort"
+	"sync"
+)
+
+// Groups of counters. Each group holds counts by key, such as plays by platform.
+const (
+	NewUsers         = "new_users"         // NewUsers counts users seen for the first time; the key is empty.
+	NewChats         = "new_chats"         // NewChats counts chats seen for the first time; the key is empty.
+	Plays            = "plays"             // Plays counts finished or skipped tracks by platform.
+	DownloadFailures = "download_failures" // DownloadFailures counts failed downloads by error class.
+	Broadcasts       = "broadcasts"        // Broadcasts counts broadcast runs, sent and failed messages.
+	Errors           = "errors"            // Errors counts handler errors by signature.
+)
+
+// maxKeys caps how many keys a group keeps, so that unbounded keys such as error texts cannot grow without limit.
+const maxKeys = 500
+
+var (
+	mu     sync.Mutex
+	groups = make(map[string]map[string]int64)
+)
+
+// Add adds n to the count of key in group.
+func Add(group, key string, n int64) {
+	mu.Lock()
+	defer mu.Unlock()
+	g, ok := groups[group]
+	if !ok {
+		g = make(map[string]int64)
+		groups[group] = g
+	}
+	if _, ok := g[key]; !ok && len(g) >= maxKeys {
+		key = "other"
+	}
+	g[key] += n
+}
+
+// Snapshot is the counts collected since the last Take.
+type Snapshot map[string]map[string]int64
+
+// Take returns the counts collected so far and starts counting anew.
+func Take() Snapshot {
+	mu.Lock()
+	defer mu.Unlock()
+	s := Snapshot(groups)
+	groups = make(map[string]map[string]int64)
+	return s
+}
+
+// Total returns the sum of all counts in group.
+func (s Snapshot) Total(group string) int64 {
+	var total int64
+	for _, n := range s[group] {
+		total += n
+	}
+	return total
+}
+
+// Count is one key of a group with its count.
+type Count struct {
+	Key string
+	N   int64
+}
+
+// Top returns up to n keys of group with the highest counts, highest first. Zero n returns all of them.
+func (s Snapshot) Top(group string, n int) []Count {
+	out := make([]Count, 0, len(s[group]))
+	for key, count := range s[group] {
+		out = append(out, Count{Key: key, N: count})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].N != out[j].N {
+			return out[i].N > out[j].N
+		}
+		return out[i].Key < out[j].Key
+	})
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
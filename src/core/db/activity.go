@@ -0,0 +1,116 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ensureActivityIndex indexes chats and users by when they last used the bot, which broadcasts filter on.
+func (db *Database) ensureActivityIndex(ctx context.Context) {
+	for _, coll := range []*mongo.Collection{db.chatDB, db.userDB} {
+		_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "last_active", Value: 1}}})
+		if err != nil {
+			logger.Warn("Failed to create the activity index of %s: %v", coll.Name(), err)
+		}
+	}
+}
+
+// TouchChat records that the chat chatID used the bot at t, which also makes it reachable by broadcasts again.
+// Chats that are not in the database are left out.
+func (db *Database) TouchChat(ctx context.Context, chatID int64, t time.Time) error {
+	_, err := db.chatDB.UpdateOne(ctx, bson.M{"_id": chatID}, touch(t))
+	return err
+}
+
+// TouchUser records that the user userID used the bot at t, which also makes them reachable by broadcasts
+// again. Users that are not in the database are left out.
+func (db *Database) TouchUser(ctx context.Context, userID int64, t time.Time) error {
+	_, err := db.userDB.UpdateOne(ctx, bson.M{"_id": userID}, touch(t))
+	return err
+}
+
+// touch is the update that records activity at t.
+func touch(t time.Time) bson.M {
+	return bson.M{"$set": bson.M{"last_active": t}, "$unset": bson.M{"unreachable": ""}}
+}
+
+// MarkUnreachable records that broadcasts can no longer be sent to the chat or user id, so that later ones
+// skip it until it uses the bot again. The document and its settings are kept. It reports whether id was
+// in the database.
+func (db *Database) MarkUnreachable(ctx context.Context, id int64, t time.Time) (bool, error) {
+	coll := db.userDB
+	if id < 0 {
+		coll = db.chatDB
+	}
+	res, err := coll.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"unreachable": t}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// GetReachableChats returns the IDs of the chats broadcasts can be sent to.
+func (db *Database) GetReachableChats(ctx context.Context) ([]int64, error) {
+	return reachableIDs(ctx, db.chatDB, bson.M{})
+}
+
+// GetReachableUsers returns the IDs of the users broadcasts can be sent to.
+func (db *Database) GetReachableUsers(ctx context.Context) ([]int64, error) {
+	return reachableIDs(ctx, db.userDB, bson.M{})
+}
+
+// GetActiveChats returns the IDs of the reachable chats that used the bot since since.
+func (db *Database) GetActiveChats(ctx context.Context, since time.Time) ([]int64, error) {
+	return reachableIDs(ctx, db.chatDB, bson.M{"last_active": bson.M{"$gte": since}})
+}
+
+// GetActiveUsers returns the IDs of the reachable users that used the bot since since.
+func (db *Database) GetActiveUsers(ctx context.Context, since time.Time) ([]int64, error) {
+	return reachableIDs(ctx, db.userDB, bson.M{"last_active": bson.M{"$gte": since}})
+}
+
+// GetUntrackedChats returns the IDs of the reachable chats with no recorded activity, such as those that have
+// not used the bot since activity started being recorded.
+func (db *Database) GetUntrackedChats(ctx context.Context) ([]int64, error) {
+	return reachableIDs(ctx, db.chatDB, bson.M{"last_active": bson.M{"$exists": false}})
+}
+
+// GetUntrackedUsers returns the IDs of the reachable users with no recorded activity.
+func (db *Database) GetUntrackedUsers(ctx context.Context) ([]int64, error) {
+	return reachableIDs(ctx, db.userDB, bson.M{"last_active": bson.M{"$exists": false}})
+}
+
+// reachableIDs returns the IDs of the documents of coll that match filter and are not marked unreachable.
+func reachableIDs(ctx context.Context, coll *mongo.Collection, filter bson.M) ([]int64, error) {
+	filter["unreachable"] = bson.M{"$exists": false}
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	var ids []int64
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID int64 `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID)
+	}
+	return ids, cursor.Err()
+}
@@ -0,0 +1,114 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Announcement is an owner-managed notice shown below the /start welcome in private chats.
+type Announcement struct {
+	ID   int64  `bson:"id"`
+	Text string `bson:"text"`
+}
+
+// announcementKey returns the bot cache key used for a bot's announcement.
+func announcementKey(botID int64) string {
+	return fmt.Sprintf("announcement:%d", botID)
+}
+
+// SetAnnouncement stores a new announcement for a bot, replacing any previous one.
+// Every announcement gets a fresh ID so that earlier dismissals do not hide it.
+func (db *Database) SetAnnouncement(ctx context.Context, botID int64, text string) (*Announcement, error) {
+	announcement := &Announcement{ID: time.Now().UnixNano(), Text: text}
+	_, err := db.botDB.UpdateOne(ctx,
+		bson.M{"_id": botID},
+		bson.M{"$set": bson.M{"announcement": announcement}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	db.botCache.Set(announcementKey(botID), map[string]interface{}{"id": announcement.ID, "text": announcement.Text})
+	return announcement, nil
+}
+
+// GetAnnouncement retrieves the current announcement for a bot.
+// It returns nil if no announcement is set.
+func (db *Database) GetAnnouncement(ctx context.Context, botID int64) *Announcement {
+	key := announcementKey(botID)
+	if cached, ok := db.botCache.Get(key); ok {
+		id, _ := cached["id"].(int64)
+		text, _ := cached["text"].(string)
+		if id == 0 || text == "" {
+			return nil
+		}
+		return &Announcement{ID: id, Text: text}
+	}
+
+	var doc struct {
+		Announcement *Announcement `bson:"announcement"`
+	}
+	err := db.botDB.FindOne(ctx, bson.M{"_id": botID}).Decode(&doc)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+
+	if doc.Announcement == nil {
+		db.botCache.Set(key, map[string]interface{}{})
+		return nil
+	}
+
+	db.botCache.Set(key, map[string]interface{}{"id": doc.Announcement.ID, "text": doc.Announcement.Text})
+	return doc.Announcement
+}
+
+// DeleteAnnouncement removes the current announcement for a bot.
+func (db *Database) DeleteAnnouncement(ctx context.Context, botID int64) error {
+	_, err := db.botDB.UpdateOne(ctx,
+		bson.M{"_id": botID},
+		bson.M{"$unset": bson.M{"announcement": ""}},
+	)
+	if err != nil {
+		return err
+	}
+
+	db.botCache.Set(announcementKey(botID), map[string]interface{}{})
+	return nil
+}
+
+// DismissAnnouncement records that a user has dismissed the announcement with the given ID.
+func (db *Database) DismissAnnouncement(ctx context.Context, userID, announcementID int64) error {
+	return db.updateUserField(ctx, userID, "dismissed_announcement", announcementID)
+}
+
+// IsAnnouncementDismissed checks whether a user has dismissed the announcement with the given ID.
+func (db *Database) IsAnnouncementDismissed(ctx context.Context, userID, announcementID int64) bool {
+	if cached, ok := db.userCache.Get(toKey(userID)); ok {
+		if val, ok := cached["dismissed_announcement"].(int64); ok {
+			return val == announcementID
+		}
+	}
+
+	var user map[string]interface{}
+	if err := db.userDB.FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		return false
+	}
+
+	val, _ := user["dismissed_announcement"].(int64)
+	return val == announcementID
+}
@@ -0,0 +1,71 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AuditEntry records one administrative action.
+type AuditEntry struct {
+	ActorID int64  `bson:"actor_id"`
+	Actor   string `bson:"actor"`
+	Action  string `bson:"action"`
+	ChatID  int64  `bson:"chat_id"`
+	Target  string `bson:"target,omitempty"`
+	Params  string `bson:"params,omitempty"`
+	Time    int64  `bson:"time"`
+}
+
+// AuditFilter narrows the audit entries returned by GetAudit. Zero fields match everything.
+type AuditFilter struct {
+	ActorID int64
+	Action  string
+}
+
+func (f AuditFilter) query() bson.M {
+	q := bson.M{}
+	if f.ActorID != 0 {
+		q["actor_id"] = f.ActorID
+	}
+	if f.Action != "" {
+		q["action"] = f.Action
+	}
+	return q
+}
+
+// AddAudit stores an audit entry.
+func (db *Database) AddAudit(ctx context.Context, entry AuditEntry) error {
+	_, err := db.auditDB.InsertOne(ctx, entry)
+	return err
+}
+
+// GetAudit retrieves audit entries matching filter, newest first, skipping the first skip entries.
+func (db *Database) GetAudit(ctx context.Context, filter AuditFilter, skip, limit int64) ([]AuditEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "time", Value: -1}, {Key: "_id", Value: -1}}).SetSkip(skip).SetLimit(limit)
+	cursor, err := db.auditDB.Find(ctx, filter.query(), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []AuditEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// CountAudit counts the audit entries matching filter.
+func (db *Database) CountAudit(ctx context.Context, filter AuditFilter) (int64, error) {
+	return db.auditDB.CountDocuments(ctx, filter.query())
+}
@@ -0,0 +1,91 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// getStringSlice converts a stored list of strings, as decoded from MongoDB or cached, into a []string.
+func getStringSlice(v interface{}) []string {
+	switch val := v.(type) {
+	case []string:
+		return val
+	case bson.A:
+		return stringsOf(val)
+	case []interface{}:
+		return stringsOf(val)
+	}
+	return nil
+}
+
+// stringsOf returns the strings in arr, skipping anything else.
+func stringsOf(arr []interface{}) []string {
+	out := make([]string, 0, len(arr))
+	for _, v := range arr {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// GetBlockedWords returns the keywords a chat blocks in track titles.
+func (db *Database) GetBlockedWords(ctx context.Context, chatID int64) []string {
+	chat, _ := db.getChat(ctx, chatID)
+	return getStringSlice(chat["blocked_words"])
+}
+
+// SetBlockedWords replaces the keywords a chat blocks in track titles.
+func (db *Database) SetBlockedWords(ctx context.Context, chatID int64, words []string) error {
+	return db.updateChatField(ctx, chatID, "blocked_words", words)
+}
+
+// globalBlockedKey returns the bot cache key used for a bot's global blocked keywords.
+func globalBlockedKey(botID int64) string {
+	return fmt.Sprintf("blocked_words:%d", botID)
+}
+
+// GetGlobalBlockedWords returns the keywords blocked in every chat the bot plays in.
+func (db *Database) GetGlobalBlockedWords(ctx context.Context, botID int64) []string {
+	key := globalBlockedKey(botID)
+	if cached, ok := db.botCache.Get(key); ok {
+		return getStringSlice(cached["words"])
+	}
+
+	var doc struct {
+		Words []string `bson:"blocked_words"`
+	}
+	err := db.botDB.FindOne(ctx, bson.M{"_id": botID}).Decode(&doc)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	db.botCache.Set(key, map[string]interface{}{"words": doc.Words})
+	return doc.Words
+}
+
+// SetGlobalBlockedWords replaces the keywords blocked in every chat the bot plays in.
+func (db *Database) SetGlobalBlockedWords(ctx context.Context, botID int64, words []string) error {
+	_, err := db.botDB.UpdateOne(ctx,
+		bson.M{"_id": botID},
+		bson.M{"$set": bson.M{"blocked_words": words}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		return err
+	}
+	db.botCache.Set(globalBlockedKey(botID), map[string]interface{}{"words": words})
+	return nil
+}
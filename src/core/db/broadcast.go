@@ -0,0 +1,203 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ScheduledBroadcast is a /broadcast -schedule command waiting for its time. The command message MsgID, and
+// the message it replies to, are read again when the broadcast starts; Args are its flags.
+type ScheduledBroadcast struct {
+	ID      int64     `bson:"_id"`
+	ChatID  int64     `bson:"chat_id"`
+	MsgID   int32     `bson:"msg_id"`
+	UserID  int64     `bson:"user_id"`
+	Args    string    `bson:"args"`
+	At      time.Time `bson:"at"`
+	Created time.Time `bson:"created"`
+}
+
+// ensureBroadcastScheduleIndex indexes scheduled broadcasts by time, which the scheduler polls.
+func (db *Database) ensureBroadcastScheduleIndex(ctx context.Context) {
+	_, err := db.bcScheduleDB.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "at", Value: 1}}})
+	if err != nil {
+		logger.Warn("Failed to create the scheduled broadcast index: %v", err)
+	}
+}
+
+// AddScheduledBroadcast stores a new scheduled broadcast and sets its ID and creation time.
+func (db *Database) AddScheduledBroadcast(ctx context.Context, b *ScheduledBroadcast) error {
+	now := time.Now()
+	b.ID = now.UnixNano()
+	b.Created = now
+	_, err := db.bcScheduleDB.InsertOne(ctx, b)
+	return err
+}
+
+// GetScheduledBroadcasts returns the scheduled broadcasts, soonest first.
+func (db *Database) GetScheduledBroadcasts(ctx context.Context) ([]ScheduledBroadcast, error) {
+	cursor, err := db.bcScheduleDB.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var broadcasts []ScheduledBroadcast
+	if err := cursor.All(ctx, &broadcasts); err != nil {
+		return nil, err
+	}
+	return broadcasts, nil
+}
+
+// TakeScheduledBroadcast removes the scheduled broadcast with the given ID and returns it, or nil if it was
+// already cancelled or started. Whoever takes a broadcast is the only one to act on it.
+func (db *Database) TakeScheduledBroadcast(ctx context.Context, id int64) (*ScheduledBroadcast, error) {
+	var b ScheduledBroadcast
+	err := db.bcScheduleDB.FindOneAndDelete(ctx, bson.M{"_id": id}).Decode(&b)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// DueScheduledBroadcasts returns the scheduled broadcasts whose time is up by now, oldest first.
+func (db *Database) DueScheduledBroadcasts(ctx context.Context, now time.Time) ([]ScheduledBroadcast, error) {
+	cursor, err := db.bcScheduleDB.Find(ctx, bson.M{"at": bson.M{"$lte": now}}, options.Find().SetSort(bson.D{{Key: "at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var broadcasts []ScheduledBroadcast
+	if err := cursor.All(ctx, &broadcasts); err != nil {
+		return nil, err
+	}
+	return broadcasts, nil
+}
+
+// BroadcastCounts are the running totals of a broadcast.
+type BroadcastCounts struct {
+	Success    int32 `bson:"success"`
+	Failed     int32 `bson:"failed"`
+	Pinned     int32 `bson:"pinned"`
+	PinSkipped int32 `bson:"pin_skipped"`
+	Pruned     int32 `bson:"pruned"`
+}
+
+// BroadcastJob is the state of a running broadcast, kept so that it can be resumed if the bot stops before it
+// finishes. Done lists the targets the message was sent to, or was about to be sent to, so none of them
+// gets it twice; the counts are saved now and then and may trail behind.
+type BroadcastJob struct {
+	ID         int64     `bson:"_id"`
+	ChatID     int64     `bson:"chat_id"`
+	UserID     int64     `bson:"user_id"`
+	SourceChat int64     `bson:"source_chat"`
+	SourceMsg  int32     `bson:"source_msg"`
+	Args       string    `bson:"args"`
+	LangCode   string    `bson:"lang"`
+	Targets    []int64   `bson:"targets"`
+	Done       []int64   `bson:"done"`
+	Started    time.Time `bson:"started"`
+
+	BroadcastCounts `bson:",inline"`
+}
+
+// SaveBroadcastJob stores a new broadcast job, replacing any earlier one, and sets its ID and start time.
+func (db *Database) SaveBroadcastJob(ctx context.Context, job *BroadcastJob) error {
+	now := time.Now()
+	job.ID = now.UnixNano()
+	job.Started = now
+	if job.Done == nil {
+		job.Done = []int64{}
+	}
+	if _, err := db.bcJobDB.DeleteMany(ctx, bson.M{}); err != nil {
+		return err
+	}
+	_, err := db.bcJobDB.InsertOne(ctx, job)
+	return err
+}
+
+// GetBroadcastJob returns the unfinished broadcast job, or nil if there is none.
+func (db *Database) GetBroadcastJob(ctx context.Context) (*BroadcastJob, error) {
+	var job BroadcastJob
+	err := db.bcJobDB.FindOne(ctx, bson.M{}).Decode(&job)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// MarkBroadcastDone records that the broadcast job id is sending to target.
+func (db *Database) MarkBroadcastDone(ctx context.Context, id, target int64) error {
+	_, err := db.bcJobDB.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$push": bson.M{"done": target}})
+	return err
+}
+
+// SaveBroadcastCounts stores the totals of the broadcast job id.
+func (db *Database) SaveBroadcastCounts(ctx context.Context, id int64, counts BroadcastCounts) error {
+	_, err := db.bcJobDB.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"success": counts.Success, "failed": counts.Failed, "pinned": counts.Pinned,
+		"pin_skipped": counts.PinSkipped, "pruned": counts.Pruned,
+	}})
+	return err
+}
+
+// DeleteBroadcastJob removes the broadcast job id once it has finished.
+func (db *Database) DeleteBroadcastJob(ctx context.Context, id int64) error {
+	_, err := db.bcJobDB.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// BroadcastExclusion is a chat or user that broadcasts never send to.
+type BroadcastExclusion struct {
+	ID      int64     `bson:"_id"`
+	UserID  int64     `bson:"user_id"`
+	Created time.Time `bson:"created"`
+}
+
+// ExcludeFromBroadcasts adds id to the broadcast exclusion list, noting userID as who added it. It reports
+// false if id was already excluded.
+func (db *Database) ExcludeFromBroadcasts(ctx context.Context, id, userID int64) (bool, error) {
+	_, err := db.bcExcludeDB.InsertOne(ctx, &BroadcastExclusion{ID: id, UserID: userID, Created: time.Now()})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// IncludeInBroadcasts removes id from the broadcast exclusion list and reports whether it was on it.
+func (db *Database) IncludeInBroadcasts(ctx context.Context, id int64) (bool, error) {
+	res, err := db.bcExcludeDB.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// GetBroadcastExclusions returns the broadcast exclusion list, oldest first.
+func (db *Database) GetBroadcastExclusions(ctx context.Context) ([]BroadcastExclusion, error) {
+	cursor, err := db.bcExcludeDB.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var exclusions []BroadcastExclusion
+	if err := cursor.All(ctx, &exclusions); err != nil {
+		return nil, err
+	}
+	return exclusions, nil
+}
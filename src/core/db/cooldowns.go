@@ -0,0 +1,65 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AssistantCooldown is a restriction Telegram placed on an assistant account, such as a long flood wait.
+type AssistantCooldown struct {
+	Reason string    `bson:"reason"`
+	Since  time.Time `bson:"since"`
+	Until  time.Time `bson:"until"`
+}
+
+// GetAssistantCooldowns returns the stored cooldowns of the bot's assistants, keyed by their user IDs.
+func (db *Database) GetAssistantCooldowns(ctx context.Context, botID int64) (map[int64]AssistantCooldown, error) {
+	var doc struct {
+		Cooldowns map[string]AssistantCooldown `bson:"assistant_cooldowns"`
+	}
+	err := db.botDB.FindOne(ctx, bson.M{"_id": botID}).Decode(&doc)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	cooldowns := make(map[int64]AssistantCooldown, len(doc.Cooldowns))
+	for key, cd := range doc.Cooldowns {
+		if id, err := strconv.ParseInt(key, 10, 64); err == nil {
+			cooldowns[id] = cd
+		}
+	}
+	return cooldowns, nil
+}
+
+// SetAssistantCooldown stores the cooldown of the assistant with the given user ID, so that it outlives a restart.
+func (db *Database) SetAssistantCooldown(ctx context.Context, botID, assistantID int64, cd AssistantCooldown) error {
+	_, err := db.botDB.UpdateOne(ctx,
+		bson.M{"_id": botID},
+		bson.M{"$set": bson.M{"assistant_cooldowns." + strconv.FormatInt(assistantID, 10): cd}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// ClearAssistantCooldown removes the stored cooldown of the assistant with the given user ID.
+func (db *Database) ClearAssistantCooldown(ctx context.Context, botID, assistantID int64) error {
+	_, err := db.botDB.UpdateOne(ctx,
+		bson.M{"_id": botID},
+		bson.M{"$unset": bson.M{"assistant_cooldowns." + strconv.FormatInt(assistantID, 10): ""}},
+	)
+	return err
+}
@@ -0,0 +1,111 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// dailyStatsRetention is how long the daily counters are kept before MongoDB removes them.
+const dailyStatsRetention = 30 * 24 * time.Hour
+
+// Kinds of daily counters.
+const (
+	dailyChat = "chat"
+	dailyUser = "user"
+)
+
+// Fields of the daily counters that TopChats can rank by.
+const (
+	// TopByPlays ranks chats by the number of tracks they played.
+	TopByPlays = "plays"
+	// TopBySeconds ranks chats by the time they spent streaming.
+	TopBySeconds = "seconds"
+)
+
+// TopEntry is a chat or user and its total over the period asked for.
+type TopEntry struct {
+	ID    int64 `bson:"_id"`
+	Total int64 `bson:"total"`
+}
+
+// Day returns the UTC day t falls on, which is what the daily counters are keyed by.
+func Day(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// ensureDailyStatsIndex makes MongoDB drop daily counters older than dailyStatsRetention.
+// Failing to create it is only logged; the counters then pile up but stay correct.
+func (db *Database) ensureDailyStatsIndex(ctx context.Context) {
+	_, err := db.dailyStatsDB.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "day", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(dailyStatsRetention / time.Second)),
+	})
+	if err != nil {
+		logger.Warn("Failed to create the daily stats index: %v", err)
+	}
+}
+
+// addDaily adds inc to the counters of kind for id on day.
+func (db *Database) addDaily(ctx context.Context, kind string, id int64, day time.Time, inc bson.M) error {
+	_, err := db.dailyStatsDB.UpdateOne(ctx,
+		bson.M{"kind": kind, "id": id, "day": Day(day)},
+		bson.M{"$inc": inc},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// AddDailyChatStats adds to the number of tracks a chat played and the seconds it streamed on day.
+func (db *Database) AddDailyChatStats(ctx context.Context, chatID int64, day time.Time, plays, seconds int64) error {
+	return db.addDaily(ctx, dailyChat, chatID, day, bson.M{TopByPlays: plays, TopBySeconds: seconds})
+}
+
+// AddDailyRequests adds to the number of tracks a user requested on day.
+func (db *Database) AddDailyRequests(ctx context.Context, userID int64, day time.Time, requests int64) error {
+	return db.addDaily(ctx, dailyUser, userID, day, bson.M{"requests": requests})
+}
+
+// topDaily sums field of the counters of kind since the given day and returns the highest totals.
+// Ties are broken by ID so that the order is stable between pages.
+func (db *Database) topDaily(ctx context.Context, kind, field string, since time.Time, limit int64) ([]TopEntry, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"kind": kind, "day": bson.M{"$gte": Day(since)}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$id", "total": bson.M{"$sum": "$" + field}}}},
+		{{Key: "$match", Value: bson.M{"total": bson.M{"$gt": 0}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "total", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+	cursor, err := db.dailyStatsDB.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []TopEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// TopChats returns the chats with the highest total of field, TopByPlays or TopBySeconds, since the given day.
+func (db *Database) TopChats(ctx context.Context, field string, since time.Time, limit int64) ([]TopEntry, error) {
+	return db.topDaily(ctx, dailyChat, field, since, limit)
+}
+
+// TopUsers returns the users who requested the most tracks since the given day.
+func (db *Database) TopUsers(ctx context.Context, since time.Time, limit int64) ([]TopEntry, error) {
+	return db.topDaily(ctx, dailyUser, "requests", since, limit)
+}
@@ -0,0 +1,92 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// The failed downloads collection is capped, so the oldest entries make room for new ones.
+const (
+	failedDownloadsCollection = "failed_downloads"
+	failedDownloadsMax        = 2000
+	failedDownloadsBytes      = 4 << 20
+	// namespaceExists is the server's error code for creating a collection that already exists.
+	namespaceExists = 48
+)
+
+// FailedDownload is a download that failed, kept so that owners can spot patterns and retry it.
+type FailedDownload struct {
+	ID       int64  `bson:"_id"`
+	TrackID  string `bson:"track_id"`
+	Platform string `bson:"platform"`
+	Name     string `bson:"name"`
+	URL      string `bson:"url"`
+	IsVideo  bool   `bson:"is_video"`
+	Class    string `bson:"class"`
+	Error    string `bson:"error"`
+	// Terminal marks failures that a retry cannot fix, such as removed or private tracks.
+	Terminal bool  `bson:"terminal"`
+	ChatID   int64 `bson:"chat_id"`
+	Time     int64 `bson:"time"`
+}
+
+// ensureFailedDownloads creates the capped collection of failed downloads if it does not exist yet.
+func (db *Database) ensureFailedDownloads(ctx context.Context) {
+	opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(failedDownloadsBytes).SetMaxDocuments(failedDownloadsMax)
+	err := db.DB.CreateCollection(ctx, failedDownloadsCollection, opts)
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && cmdErr.HasErrorCode(namespaceExists)) {
+		logger.Warn("Failed to create the failed downloads collection: %v", err)
+	}
+}
+
+// AddFailedDownload records a failed download and sets its ID and time.
+func (db *Database) AddFailedDownload(ctx context.Context, f *FailedDownload) error {
+	now := time.Now()
+	f.ID = now.UnixNano()
+	f.Time = now.Unix()
+	_, err := db.failedDB.InsertOne(ctx, f)
+	return err
+}
+
+// RecentFailedDownloads returns up to limit failed downloads, newest first.
+func (db *Database) RecentFailedDownloads(ctx context.Context, limit int64) ([]FailedDownload, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "$natural", Value: -1}}).SetLimit(limit)
+	cursor, err := db.failedDB.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var failures []FailedDownload
+	if err := cursor.All(ctx, &failures); err != nil {
+		return nil, err
+	}
+	return failures, nil
+}
+
+// GetFailedDownload returns the failed download with the given ID, or nil if it has been rotated out.
+func (db *Database) GetFailedDownload(ctx context.Context, id int64) (*FailedDownload, error) {
+	var f FailedDownload
+	err := db.failedDB.FindOne(ctx, bson.M{"_id": id}).Decode(&f)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
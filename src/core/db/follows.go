@@ -0,0 +1,112 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// followSeenLimit is how many of a channel's latest upload IDs a subscription remembers.
+const followSeenLimit = 50
+
+// Follow is a chat's subscription to the uploads of a YouTube channel. Seen holds the IDs of the latest
+// uploads the chat was already told about, or that existed when it followed the channel.
+type Follow struct {
+	ChatID    int64     `bson:"chat_id"`
+	ChannelID string    `bson:"channel_id"`
+	Name      string    `bson:"name"`
+	UserID    int64     `bson:"user_id"`
+	Seen      []string  `bson:"seen"`
+	Created   time.Time `bson:"created"`
+}
+
+// ensureFollowIndex makes a chat follow a channel at most once.
+func (db *Database) ensureFollowIndex(ctx context.Context) {
+	_, err := db.followDB.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "chat_id", Value: 1}, {Key: "channel_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.Warn("Failed to create the follow index: %v", err)
+	}
+}
+
+// AddFollow stores a new subscription. It reports false if the chat already follows the channel.
+func (db *Database) AddFollow(ctx context.Context, f *Follow) (bool, error) {
+	f.Created = time.Now()
+	if len(f.Seen) > followSeenLimit {
+		f.Seen = f.Seen[:followSeenLimit]
+	}
+	_, err := db.followDB.InsertOne(ctx, f)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// RemoveFollow deletes a chat's subscription to a channel and reports whether there was one.
+func (db *Database) RemoveFollow(ctx context.Context, chatID int64, channelID string) (bool, error) {
+	res, err := db.followDB.DeleteOne(ctx, bson.M{"chat_id": chatID, "channel_id": channelID})
+	if err != nil {
+		return false, err
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// GetFollows returns a chat's subscriptions, oldest first.
+func (db *Database) GetFollows(ctx context.Context, chatID int64) ([]Follow, error) {
+	cursor, err := db.followDB.Find(ctx, bson.M{"chat_id": chatID}, options.Find().SetSort(bson.D{{Key: "created", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var follows []Follow
+	err = cursor.All(ctx, &follows)
+	return follows, err
+}
+
+// CountFollows returns how many subscriptions a chat has, or all chats together if chatID is 0.
+func (db *Database) CountFollows(ctx context.Context, chatID int64) (int64, error) {
+	filter := bson.M{}
+	if chatID != 0 {
+		filter["chat_id"] = chatID
+	}
+	return db.followDB.CountDocuments(ctx, filter)
+}
+
+// FollowedChannels returns the IDs of the channels at least one chat follows.
+func (db *Database) FollowedChannels(ctx context.Context) ([]string, error) {
+	var ids []string
+	err := db.followDB.Distinct(ctx, "channel_id", bson.M{}).Decode(&ids)
+	return ids, err
+}
+
+// ChannelFollows returns the subscriptions to a channel.
+func (db *Database) ChannelFollows(ctx context.Context, channelID string) ([]Follow, error) {
+	cursor, err := db.followDB.Find(ctx, bson.M{"channel_id": channelID})
+	if err != nil {
+		return nil, err
+	}
+	var follows []Follow
+	err = cursor.All(ctx, &follows)
+	return follows, err
+}
+
+// MarkFollowSeen records uploads a chat was told about, keeping only the latest followSeenLimit.
+func (db *Database) MarkFollowSeen(ctx context.Context, chatID int64, channelID string, videoIDs []string) error {
+	_, err := db.followDB.UpdateOne(ctx,
+		bson.M{"chat_id": chatID, "channel_id": channelID},
+		bson.M{"$push": bson.M{"seen": bson.M{"$each": videoIDs, "$position": 0, "$slice": followSeenLimit}}},
+	)
+	return err
+}
@@ -0,0 +1,64 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MirrorLink makes a target chat play whatever its source chat plays.
+type MirrorLink struct {
+	Target int64 `bson:"_id"`
+	Source int64 `bson:"source"`
+	// Degraded is why the target last failed to follow the source, or empty while it is following.
+	Degraded  string    `bson:"degraded,omitempty"`
+	CreatedBy int64     `bson:"created_by"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// SaveMirrorLink stores a link, replacing any link of the same target.
+func (db *Database) SaveMirrorLink(ctx context.Context, link *MirrorLink) error {
+	_, err := db.mirrorDB.ReplaceOne(ctx, bson.M{"_id": link.Target}, link, options.Replace().SetUpsert(true))
+	return err
+}
+
+// SetMirrorDegraded records why a target failed to follow its source; an empty reason clears it.
+func (db *Database) SetMirrorDegraded(ctx context.Context, target int64, reason string) error {
+	update := bson.M{"$set": bson.M{"degraded": reason}}
+	if reason == "" {
+		update = bson.M{"$unset": bson.M{"degraded": ""}}
+	}
+	_, err := db.mirrorDB.UpdateOne(ctx, bson.M{"_id": target}, update)
+	return err
+}
+
+// DeleteMirrorLink removes the link of a target chat.
+func (db *Database) DeleteMirrorLink(ctx context.Context, target int64) error {
+	_, err := db.mirrorDB.DeleteOne(ctx, bson.M{"_id": target})
+	return err
+}
+
+// GetMirrorLinks returns every link.
+func (db *Database) GetMirrorLinks(ctx context.Context) ([]MirrorLink, error) {
+	cursor, err := db.mirrorDB.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var links []MirrorLink
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
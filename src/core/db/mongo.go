@@ -11,12 +11,13 @@ package db
 import (
 	"context"
 	"errors"
-	"log"
 	"sync"
 	"time"
 
 	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/counters"
+	"ashokshau/tgmusic/src/core/logging"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 
@@ -24,6 +25,8 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+var logger = logging.For("db")
+
 // Database encapsulates the MongoDB connection, database, collections, and caches.
 type Database struct {
 	client       *mongo.Client
@@ -32,12 +35,32 @@ type Database struct {
 	userDB       *mongo.Collection
 	botDB        *mongo.Collection
 	playlistDB   *mongo.Collection
+	snapshotDB   *mongo.Collection
+	playStatsDB  *mongo.Collection
+	trackStatsDB *mongo.Collection
+	dailyStatsDB *mongo.Collection
+	resumeDB     *mongo.Collection
+	mirrorDB     *mongo.Collection
+	premiumDB    *mongo.Collection
+	scheduleDB   *mongo.Collection
+	quotaDB      *mongo.Collection
+	failedDB     *mongo.Collection
+	auditDB      *mongo.Collection
+	reportDB     *mongo.Collection
+	followDB     *mongo.Collection
+	bcScheduleDB *mongo.Collection
+	bcJobDB      *mongo.Collection
+	bcExcludeDB  *mongo.Collection
 	chatCache    *cache.Cache[map[string]interface{}]
 	botCache     *cache.Cache[map[string]interface{}]
 	userCache    *cache.Cache[map[string]interface{}]
+	premiumCache *cache.Cache[time.Time]
+	quotaCache   *cache.Cache[int]
 	chatCacheMux sync.RWMutex
 	botCacheMux  sync.RWMutex
 	userCacheMux sync.RWMutex
+	// radioMu serializes enabling 24/7 radio mode, so that the chat limit is checked and taken atomically.
+	radioMu sync.Mutex
 }
 
 // Instance is the global singleton for the database.
@@ -46,29 +69,56 @@ var Instance *Database
 // InitDatabase initializes the database connection and sets up the global instance.
 // It returns an error if the connection fails or pinging the database is unsuccessful.
 func InitDatabase(ctx context.Context) error {
-	client, err := mongo.Connect(options.Client().ApplyURI(config.Conf.MongoUri))
+	client, err := mongo.Connect(options.Client().ApplyURI(config.Get().MongoUri).SetMonitor(commandMonitor()))
 	if err != nil {
 		return err
 	}
 
-	db := client.Database(config.Conf.DbName)
+	db := client.Database(config.Get().DbName)
 	Instance = &Database{
-		client:     client,
-		DB:         db,
-		chatDB:     db.Collection("chats"),
-		userDB:     db.Collection("users"),
-		botDB:      db.Collection("bot"),
-		playlistDB: db.Collection("playlists"),
-		chatCache:  cache.NewCache[map[string]interface{}](20 * time.Minute),
-		botCache:   cache.NewCache[map[string]interface{}](20 * time.Minute),
-		userCache:  cache.NewCache[map[string]interface{}](20 * time.Minute),
+		client:       client,
+		DB:           db,
+		chatDB:       db.Collection("chats"),
+		userDB:       db.Collection("users"),
+		botDB:        db.Collection("bot"),
+		playlistDB:   db.Collection("playlists"),
+		snapshotDB:   db.Collection("queue_snapshots"),
+		playStatsDB:  db.Collection("play_stats"),
+		trackStatsDB: db.Collection("track_stats"),
+		dailyStatsDB: db.Collection("daily_stats"),
+		resumeDB:     db.Collection("resume_points"),
+		mirrorDB:     db.Collection("mirror_links"),
+		premiumDB:    db.Collection("premium_users"),
+		scheduleDB:   db.Collection("scheduled_plays"),
+		quotaDB:      db.Collection("request_quotas"),
+		failedDB:     db.Collection(failedDownloadsCollection),
+		auditDB:      db.Collection("audit_log"),
+		reportDB:     db.Collection("reports"),
+		followDB:     db.Collection("follows"),
+		bcScheduleDB: db.Collection("scheduled_broadcasts"),
+		bcJobDB:      db.Collection("broadcast_jobs"),
+		bcExcludeDB:  db.Collection("broadcast_exclusions"),
+		chatCache:    cache.NewCache[map[string]interface{}](20 * time.Minute),
+		botCache:     cache.NewCache[map[string]interface{}](20 * time.Minute),
+		userCache:    cache.NewCache[map[string]interface{}](20 * time.Minute),
+		premiumCache: cache.NewCache[time.Time](5 * time.Minute),
+		quotaCache:   cache.NewCache[int](30 * time.Minute),
 	}
 
 	if err := Instance.Ping(ctx); err != nil {
 		return errors.New("failed to ping database: " + err.Error())
 	}
 
-	log.Println("[DB] The database connection has been successfully established.")
+	Instance.ensureDailyStatsIndex(ctx)
+	Instance.ensureResumeIndex(ctx)
+	Instance.ensureScheduleIndex(ctx)
+	Instance.ensureQuotaIndex(ctx)
+	Instance.ensureFailedDownloads(ctx)
+	Instance.ensureFollowIndex(ctx)
+	Instance.ensureNPTokenIndex(ctx)
+	Instance.ensureBroadcastScheduleIndex(ctx)
+	Instance.ensureActivityIndex(ctx)
+	logger.Info("The database connection has been successfully established.")
 	return nil
 }
 
@@ -93,7 +143,7 @@ func (db *Database) getChat(ctx context.Context, chatID int64) (map[string]inter
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		return nil, nil
 	} else if err != nil {
-		log.Printf("[DB] An error occurred while getting the chat: %v", err)
+		logger.Error("An error occurred while getting the chat: %v", err)
 		return nil, err
 	}
 
@@ -101,23 +151,33 @@ func (db *Database) getChat(ctx context.Context, chatID int64) (map[string]inter
 	return chat, nil
 }
 
-// AddChat adds a new chat to the database if it does not already exist.
-func (db *Database) AddChat(ctx context.Context, chatID int64) error {
+// AddChat adds a new chat to the database if it does not already exist, and reports whether it was new.
+func (db *Database) AddChat(ctx context.Context, chatID int64) (bool, error) {
 	chat, _ := db.getChat(ctx, chatID)
 	if chat != nil {
-		return nil // Chat already exists.
+		return false, nil // Chat already exists.
 	}
 
-	_, err := db.chatDB.UpdateOne(ctx, bson.M{"_id": chatID}, bson.M{"$setOnInsert": bson.M{}}, options.UpdateOne().SetUpsert(true))
-	if err == nil {
-		log.Printf("[DB] A new chat has been added: %d", chatID)
+	res, err := db.chatDB.UpdateOne(ctx, bson.M{"_id": chatID}, bson.M{"$setOnInsert": bson.M{}}, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		return false, err
 	}
-	return err
+	if res.UpsertedCount == 0 {
+		return false, nil
+	}
+	logger.Info("A new chat has been added: %d", chatID)
+	counters.Add(counters.NewChats, "", 1)
+	return true, nil
 }
 
 // updateChatField updates a specific field in a chat's document.
 func (db *Database) updateChatField(ctx context.Context, chatID int64, key string, value interface{}) error {
-	_, err := db.chatDB.UpdateOne(ctx, bson.M{"_id": chatID}, bson.M{"$set": bson.M{key: value}}, options.UpdateOne().SetUpsert(true))
+	return db.updateChatFields(ctx, chatID, bson.M{key: value})
+}
+
+// updateChatFields updates several fields of a chat's document at once.
+func (db *Database) updateChatFields(ctx context.Context, chatID int64, fields bson.M) error {
+	_, err := db.chatDB.UpdateOne(ctx, bson.M{"_id": chatID}, bson.M{"$set": fields}, options.UpdateOne().SetUpsert(true))
 	if err != nil {
 		return err
 	}
@@ -134,7 +194,9 @@ func (db *Database) updateChatField(ctx context.Context, chatID int64, key strin
 		}
 	}
 
-	newCached[key] = value
+	for key, value := range fields {
+		newCached[key] = value
+	}
 	db.chatCache.Set(cacheKey, newCached)
 
 	return nil
@@ -219,6 +281,312 @@ func (db *Database) SetAdminMode(ctx context.Context, chatID int64, adminMode st
 	return db.updateChatField(ctx, chatID, "admin_mode", adminMode)
 }
 
+// GetCleanMode retrieves how many seconds the bot's service messages live in a chat before being deleted.
+// It returns the configured AUTO_DELETE_DELAY if the chat has not set a value, and 0 if clean mode is off.
+func (db *Database) GetCleanMode(ctx context.Context, chatID int64) int {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return int(config.Get().AutoDeleteDelay)
+	}
+	if val, ok := chat["clean_mode"].(int32); ok {
+		return int(val)
+	}
+	return int(config.Get().AutoDeleteDelay)
+}
+
+// SetCleanMode sets the clean mode delay in seconds for a given chat. A value of 0 turns clean mode off.
+func (db *Database) SetCleanMode(ctx context.Context, chatID int64, seconds int) error {
+	return db.updateChatField(ctx, chatID, "clean_mode", int32(seconds))
+}
+
+// GetSearchPlatform retrieves the platform used for plain-text searches in a chat.
+// It returns an empty string if the chat uses the default service.
+func (db *Database) GetSearchPlatform(ctx context.Context, chatID int64) string {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return ""
+	}
+	if val, ok := chat["search_platform"].(string); ok {
+		return val
+	}
+	return ""
+}
+
+// SetSearchPlatform sets the platform used for plain-text searches in a chat.
+func (db *Database) SetSearchPlatform(ctx context.Context, chatID int64, platform string) error {
+	return db.updateChatField(ctx, chatID, "search_platform", platform)
+}
+
+// GetKeepSpeed reports whether a chat keeps its playback speed when the next track starts.
+func (db *Database) GetKeepSpeed(ctx context.Context, chatID int64) bool {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return false
+	}
+	if val, ok := chat["keep_speed"].(bool); ok {
+		return val
+	}
+	return false
+}
+
+// SetKeepSpeed sets whether a chat keeps its playback speed when the next track starts.
+func (db *Database) SetKeepSpeed(ctx context.Context, chatID int64, keep bool) error {
+	return db.updateChatField(ctx, chatID, "keep_speed", keep)
+}
+
+// GetAutoPause reports whether playback in a chat is paused while nobody is listening. It is on by default.
+func (db *Database) GetAutoPause(ctx context.Context, chatID int64) bool {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return true
+	}
+	if val, ok := chat["auto_pause"].(bool); ok {
+		return val
+	}
+	return true
+}
+
+// SetAutoPause sets whether playback in a chat is paused while nobody is listening.
+func (db *Database) SetAutoPause(ctx context.Context, chatID int64, enabled bool) error {
+	return db.updateChatField(ctx, chatID, "auto_pause", enabled)
+}
+
+// GetRadio247 reports whether a chat runs in 24/7 radio mode, and which playlist it loops when the queue empties.
+// An empty playlist ID means the assistant holds the voice chat silently until new tracks arrive.
+func (db *Database) GetRadio247(ctx context.Context, chatID int64) (bool, string) {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return false, ""
+	}
+	enabled, _ := chat["radio247"].(bool)
+	playlistID, _ := chat["radio_playlist"].(string)
+	return enabled, playlistID
+}
+
+// SetRadio247 turns 24/7 radio mode on or off for a chat and sets the playlist it loops.
+func (db *Database) SetRadio247(ctx context.Context, chatID int64, enabled bool, playlistID string) error {
+	if err := db.updateChatField(ctx, chatID, "radio_playlist", playlistID); err != nil {
+		return err
+	}
+	return db.updateChatField(ctx, chatID, "radio247", enabled)
+}
+
+// ErrRadioLimit is returned by EnableRadio247 when the limit of 24/7 radio chats is reached.
+var ErrRadioLimit = errors.New("24/7 radio chat limit reached")
+
+// EnableRadio247 turns 24/7 radio mode on for a chat and sets the playlist it loops. If limit is positive and
+// the chat is not yet in radio mode, it returns ErrRadioLimit when limit chats already are. The check and the
+// update run under one lock, so concurrent calls cannot exceed the limit.
+func (db *Database) EnableRadio247(ctx context.Context, chatID int64, playlistID string, limit int64) error {
+	db.radioMu.Lock()
+	defer db.radioMu.Unlock()
+
+	if enabled, _ := db.GetRadio247(ctx, chatID); !enabled && limit > 0 {
+		count, err := db.CountRadio247(ctx)
+		if err != nil {
+			return err
+		}
+		if count >= limit {
+			return ErrRadioLimit
+		}
+	}
+	return db.SetRadio247(ctx, chatID, true, playlistID)
+}
+
+// CountRadio247 returns how many chats have 24/7 radio mode enabled.
+func (db *Database) CountRadio247(ctx context.Context) (int64, error) {
+	return db.chatDB.CountDocuments(ctx, bson.M{"radio247": true})
+}
+
+// GetVCTitle reports whether the voice chat title of a chat follows the current track.
+func (db *Database) GetVCTitle(ctx context.Context, chatID int64) bool {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return false
+	}
+	if val, ok := chat["vc_title"].(bool); ok {
+		return val
+	}
+	return false
+}
+
+// SetVCTitle sets whether the voice chat title of a chat follows the current track.
+func (db *Database) SetVCTitle(ctx context.Context, chatID int64, enabled bool) error {
+	return db.updateChatField(ctx, chatID, "vc_title", enabled)
+}
+
+// GetNoDuplicates reports whether a chat rejects tracks that are already queued or were played recently.
+func (db *Database) GetNoDuplicates(ctx context.Context, chatID int64) bool {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return false
+	}
+	if val, ok := chat["no_duplicates"].(bool); ok {
+		return val
+	}
+	return false
+}
+
+// SetNoDuplicates sets whether a chat rejects tracks that are already queued or were played recently.
+func (db *Database) SetNoDuplicates(ctx context.Context, chatID int64, enabled bool) error {
+	return db.updateChatField(ctx, chatID, "no_duplicates", enabled)
+}
+
+// GetForceSub returns the channel a chat's members must join before using playback commands, or "" for none.
+func (db *Database) GetForceSub(ctx context.Context, chatID int64) string {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return ""
+	}
+	val, _ := chat["force_sub"].(string)
+	return val
+}
+
+// SetForceSub sets the channel a chat's members must join before using playback commands; "" clears it.
+func (db *Database) SetForceSub(ctx context.Context, chatID int64, channel string) error {
+	return db.updateChatField(ctx, chatID, "force_sub", channel)
+}
+
+// GetApprovalMode reports whether requests by a chat's members wait for an admin's approval before they are queued.
+func (db *Database) GetApprovalMode(ctx context.Context, chatID int64) bool {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return false
+	}
+	val, _ := chat["approval_mode"].(bool)
+	return val
+}
+
+// SetApprovalMode sets whether requests by a chat's members wait for an admin's approval.
+func (db *Database) SetApprovalMode(ctx context.Context, chatID int64, enabled bool) error {
+	return db.updateChatField(ctx, chatID, "approval_mode", enabled)
+}
+
+// GetNowPlayingTemplate returns the chat's custom now-playing template, or "" if it uses the default text.
+func (db *Database) GetNowPlayingTemplate(ctx context.Context, chatID int64) string {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return ""
+	}
+	val, _ := chat["np_template"].(string)
+	return val
+}
+
+// SetNowPlayingTemplate sets the chat's now-playing template. An empty template restores the default text.
+func (db *Database) SetNowPlayingTemplate(ctx context.Context, chatID int64, tmpl string) error {
+	return db.updateChatField(ctx, chatID, "np_template", tmpl)
+}
+
+// QuietHours is the daily window in which a chat refuses to start playback. Start and End are "HH:MM" in
+// the time zone TZ; the window wraps past midnight when End is before Start.
+type QuietHours struct {
+	Start string
+	End   string
+	TZ    string
+}
+
+// GetQuietHours returns the quiet hours of a chat, or nil if it has none.
+func (db *Database) GetQuietHours(ctx context.Context, chatID int64) *QuietHours {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return nil
+	}
+	start, _ := chat["quiet_start"].(string)
+	end, _ := chat["quiet_end"].(string)
+	if start == "" || end == "" {
+		return nil
+	}
+	tz, _ := chat["quiet_tz"].(string)
+	return &QuietHours{Start: start, End: end, TZ: tz}
+}
+
+// SetQuietHours sets the quiet hours of a chat; nil clears them.
+func (db *Database) SetQuietHours(ctx context.Context, chatID int64, q *QuietHours) error {
+	if q == nil {
+		q = &QuietHours{}
+	}
+	return db.updateChatFields(ctx, chatID, bson.M{"quiet_start": q.Start, "quiet_end": q.End, "quiet_tz": q.TZ})
+}
+
+// GetRequestLimit returns how many tracks each member may request in the chat per day, or 0 for no limit.
+func (db *Database) GetRequestLimit(ctx context.Context, chatID int64) int {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return 0
+	}
+	val, _ := chat["request_limit"].(int32)
+	return int(val)
+}
+
+// SetRequestLimit sets how many tracks each member may request in the chat per day. 0 removes the limit.
+func (db *Database) SetRequestLimit(ctx context.Context, chatID int64, limit int) error {
+	return db.updateChatField(ctx, chatID, "request_limit", int32(limit))
+}
+
+// GetTimezone returns the chat's time zone name, or "" if it has not set one.
+func (db *Database) GetTimezone(ctx context.Context, chatID int64) string {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return ""
+	}
+	val, _ := chat["timezone"].(string)
+	return val
+}
+
+// SetTimezone sets the chat's time zone, used to read the clock times given to /schedule.
+func (db *Database) SetTimezone(ctx context.Context, chatID int64, tz string) error {
+	return db.updateChatField(ctx, chatID, "timezone", tz)
+}
+
+// GetQueueNotice returns how a chat is told about tracks added to its queue: "off", "minimal" or "detailed".
+func (db *Database) GetQueueNotice(ctx context.Context, chatID int64) string {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return "detailed"
+	}
+	if val, ok := chat["queue_notice"].(string); ok && val != "" {
+		return val
+	}
+	return "detailed"
+}
+
+// SetQueueNotice sets how a chat is told about tracks added to its queue.
+func (db *Database) SetQueueNotice(ctx context.Context, chatID int64, mode string) error {
+	return db.updateChatField(ctx, chatID, "queue_notice", mode)
+}
+
+// GetKeepQueue reports whether a chat keeps its queue when its voice chat is ended during playback.
+func (db *Database) GetKeepQueue(ctx context.Context, chatID int64) bool {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return true
+	}
+	if val, ok := chat["keep_queue"].(bool); ok {
+		return val
+	}
+	return true
+}
+
+// SetKeepQueue sets whether a chat keeps its queue when its voice chat is ended during playback.
+func (db *Database) SetKeepQueue(ctx context.Context, chatID int64, enabled bool) error {
+	return db.updateChatField(ctx, chatID, "keep_queue", enabled)
+}
+
+// GetAnnounce reports whether a chat hears a spoken announcement of every track before it starts.
+func (db *Database) GetAnnounce(ctx context.Context, chatID int64) bool {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return false
+	}
+	val, _ := chat["announce"].(bool)
+	return val
+}
+
+// SetAnnounce sets whether a chat hears a spoken announcement of every track before it starts.
+func (db *Database) SetAnnounce(ctx context.Context, chatID int64, enabled bool) error {
+	return db.updateChatField(ctx, chatID, "announce", enabled)
+}
+
 // GetAssistant retrieves the username of the assistant for a chat.
 func (db *Database) GetAssistant(ctx context.Context, chatID int64) (string, error) {
 	chat, _ := db.getChat(ctx, chatID)
@@ -249,7 +617,7 @@ func (db *Database) ClearAllAssistants(ctx context.Context) (int64, error) {
 		bson.M{"assistant": bson.M{"$exists": true}},
 	)
 	if err != nil {
-		log.Printf("[DB] Error finding chats with assistants: %v", err)
+		logger.Error("Error finding chats with assistants: %v", err)
 		return 0, err
 	}
 	defer func(cursor *mongo.Cursor, ctx context.Context) {
@@ -271,7 +639,7 @@ func (db *Database) ClearAllAssistants(ctx context.Context) (int64, error) {
 		bson.M{"$unset": bson.M{"assistant": ""}},
 	)
 	if err != nil {
-		log.Printf("[DB] Error clearing assistants: %v", err)
+		logger.Error("Error clearing assistants: %v", err)
 		return 0, err
 	}
 	for _, chatID := range chatIDs {
@@ -285,7 +653,7 @@ func (db *Database) SetUserLang(ctx context.Context, userID int64, lang string)
 	return db.updateUserField(ctx, userID, "language", lang)
 }
 
-// getUserLang retrieves the language for a user.
+// getUserLang retrieves the language a user has chosen, or an empty string if they have not chosen one.
 func (db *Database) getUserLang(ctx context.Context, userID int64) string {
 	key := toKey(userID)
 	if cached, ok := db.userCache.Get(key); ok {
@@ -297,13 +665,13 @@ func (db *Database) getUserLang(ctx context.Context, userID int64) string {
 	var user map[string]interface{}
 	err := db.userDB.FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
 	if err != nil {
-		return "en"
+		return ""
 	}
 
 	if val, ok := user["language"].(string); ok {
 		return val
 	}
-	return "en"
+	return ""
 }
 
 // SetChatLang sets the language for a given chat.
@@ -311,24 +679,46 @@ func (db *Database) SetChatLang(ctx context.Context, chatID int64, lang string)
 	return db.updateChatField(ctx, chatID, "language", lang)
 }
 
-// getChatLang retrieves the language for a chat.
+// getChatLang retrieves the language a chat has chosen, or an empty string if it has not chosen one.
 func (db *Database) getChatLang(ctx context.Context, chatID int64) string {
 	chat, _ := db.getChat(ctx, chatID)
 	if chat == nil {
-		return "en"
+		return ""
 	}
 	if val, ok := chat["language"].(string); ok {
 		return val
 	}
-	return "en"
+	return ""
 }
 
-// GetLang retrieves the language for a chat or user.
+// GetLang retrieves the language for a chat or user, or DEFAULT_LANG if they have not chosen one.
 func (db *Database) GetLang(ctx context.Context, chatID int64) string {
+	var code string
 	if chatID > 0 {
-		return db.getUserLang(ctx, chatID)
+		code = db.getUserLang(ctx, chatID)
+	} else {
+		code = db.getChatLang(ctx, chatID)
+	}
+	if code == "" {
+		return config.Get().DefaultLang
+	}
+	return code
+}
+
+// LangFor returns the language to answer a user in a chat with: the user's own choice, then the chat's,
+// then DEFAULT_LANG.
+func (db *Database) LangFor(ctx context.Context, chatID, userID int64) string {
+	if userID > 0 {
+		if code := db.getUserLang(ctx, userID); code != "" {
+			return code
+		}
+	}
+	if chatID < 0 {
+		if code := db.getChatLang(ctx, chatID); code != "" {
+			return code
+		}
 	}
-	return db.getChatLang(ctx, chatID)
+	return config.Get().DefaultLang
 }
 
 // ----------------- AUTH USERS -----------------
@@ -452,28 +842,31 @@ func (db *Database) SetLoggerStatus(ctx context.Context, botID int64, status boo
 
 // ----------------- USERS -----------------
 
-// AddUser adds a new user to the database if they do not already exist.
-func (db *Database) AddUser(ctx context.Context, userID int64) error {
+// AddUser adds a new user to the database if they do not already exist, and reports whether they were new.
+func (db *Database) AddUser(ctx context.Context, userID int64) (bool, error) {
 	key := toKey(userID)
 
 	// Check cache first to avoid unnecessary database operations.
 	if _, ok := db.userCache.Get(key); ok {
-		return nil
+		return false, nil
 	}
 
 	// Upsert in the database to ensure the user is added.
-	_, err := db.userDB.UpdateOne(ctx,
+	res, err := db.userDB.UpdateOne(ctx,
 		bson.M{"_id": userID},
 		bson.M{"$setOnInsert": bson.M{}},
 		options.UpdateOne().SetUpsert(true),
 	)
 	if err != nil {
-		return err
+		return false, err
+	}
+	if res.UpsertedCount > 0 {
+		counters.Add(counters.NewUsers, "", 1)
 	}
 
 	// Update the cache to reflect the new user.
 	db.userCache.Set(key, map[string]interface{}{})
-	return nil
+	return res.UpsertedCount > 0, nil
 }
 
 // RemoveUser removes a user from the database and cache.
@@ -515,6 +908,16 @@ func (db *Database) IsUserExist(ctx context.Context, userID int64) (bool, error)
 	return true, nil
 }
 
+// CountChats returns roughly how many chats are stored, from the collection's metadata.
+func (db *Database) CountChats(ctx context.Context) (int64, error) {
+	return db.chatDB.EstimatedDocumentCount(ctx)
+}
+
+// CountUsers returns roughly how many users are stored, from the collection's metadata.
+func (db *Database) CountUsers(ctx context.Context) (int64, error) {
+	return db.userDB.EstimatedDocumentCount(ctx)
+}
+
 // GetAllChats retrieves a list of all chat IDs from the database.
 func (db *Database) GetAllChats(ctx context.Context) ([]int64, error) {
 	cursor, err := db.chatDB.Find(ctx, bson.M{})
@@ -575,6 +978,6 @@ func (db *Database) GetAllUsers(ctx context.Context) ([]int64, error) {
 
 // Close gracefully closes the database connection.
 func (db *Database) Close(ctx context.Context) error {
-	log.Println("[DB] Closing the database connection...")
+	logger.Info("Closing the database connection...")
 	return db.client.Disconnect(ctx)
 }
@@ -0,0 +1,50 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// commandDetails holds the collection and filter of every running command, keyed by its request ID.
+var commandDetails sync.Map
+
+// commandMonitor times every command sent to MongoDB, so that slow queries behind the Database methods are logged
+// and show up in /slowlog.
+func commandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			detail := evt.DatabaseName
+			if coll, ok := evt.Command.Lookup(evt.CommandName).StringValueOK(); ok {
+				detail += "." + coll
+			}
+			if filter, err := evt.Command.LookupErr("filter"); err == nil {
+				detail += " " + filter.String()
+			}
+			commandDetails.Store(evt.RequestID, detail)
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			observeCommand(ctx, evt.CommandFinishedEvent)
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			observeCommand(ctx, evt.CommandFinishedEvent)
+		},
+	}
+}
+
+// observeCommand records a finished command.
+func observeCommand(ctx context.Context, evt event.CommandFinishedEvent) {
+	detail, _ := commandDetails.LoadAndDelete(evt.RequestID)
+	text, _ := detail.(string)
+	logger.Observe(ctx, "db", evt.CommandName, text, time.Now().Add(-evt.Duration), evt.Duration)
+}
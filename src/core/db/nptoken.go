@@ -0,0 +1,78 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ensureNPTokenIndex indexes the public now-playing tokens, which every page request looks up.
+func (db *Database) ensureNPTokenIndex(ctx context.Context) {
+	_, err := db.chatDB.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "np_token", Value: 1}},
+		Options: options.Index().SetUnique(true).SetSparse(true),
+	})
+	if err != nil {
+		logger.Warn("Failed to create the now-playing token index: %v", err)
+	}
+}
+
+// GetNPToken returns the public now-playing token of a chat, or "" if it has none.
+func (db *Database) GetNPToken(ctx context.Context, chatID int64) string {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return ""
+	}
+	token, _ := chat["np_token"].(string)
+	return token
+}
+
+// SetNPToken sets the public now-playing token of a chat, replacing any previous one.
+func (db *Database) SetNPToken(ctx context.Context, chatID int64, token string) error {
+	return db.updateChatField(ctx, chatID, "np_token", token)
+}
+
+// RevokeNPToken removes the public now-playing token of a chat.
+func (db *Database) RevokeNPToken(ctx context.Context, chatID int64) error {
+	_, err := db.chatDB.UpdateOne(ctx, bson.M{"_id": chatID}, bson.M{"$unset": bson.M{"np_token": ""}})
+	db.chatCache.Delete(toKey(chatID))
+	return err
+}
+
+// ChatByNPToken returns the chat a public now-playing token belongs to, or 0 if no chat has it.
+func (db *Database) ChatByNPToken(ctx context.Context, token string) (int64, error) {
+	var chat struct {
+		ID int64 `bson:"_id"`
+	}
+	err := db.chatDB.FindOne(ctx, bson.M{"np_token": token}, options.FindOne().SetProjection(bson.M{"_id": 1})).Decode(&chat)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	return chat.ID, err
+}
+
+// GetNPRequesters reports whether the public now-playing page of a chat names who requested each track.
+func (db *Database) GetNPRequesters(ctx context.Context, chatID int64) bool {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return false
+	}
+	show, _ := chat["np_requesters"].(bool)
+	return show
+}
+
+// SetNPRequesters sets whether the public now-playing page of a chat names who requested each track.
+func (db *Database) SetNPRequesters(ctx context.Context, chatID int64, show bool) error {
+	return db.updateChatField(ctx, chatID, "np_requesters", show)
+}
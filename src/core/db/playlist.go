@@ -100,6 +100,17 @@ func (db *Database) AddSongToPlaylist(ctx context.Context, id string, song Song)
 	return err
 }
 
+// AddSongsToPlaylist appends songs to a playlist in one update. Unlike AddSongToPlaylist it does not skip
+// songs the playlist already has, so callers must leave those out.
+func (db *Database) AddSongsToPlaylist(ctx context.Context, id string, songs []Song) error {
+	_, err := db.playlistDB.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$push": bson.M{"songs": bson.M{"$each": songs}}},
+	)
+	return err
+}
+
 // RemoveSongFromPlaylist removes a song from a playlist by its track ID.
 func (db *Database) RemoveSongFromPlaylist(ctx context.Context, id string, trackID string) error {
 	if !db.songExists(ctx, id, trackID) {
@@ -0,0 +1,84 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// GlobalStatsID is the chat ID under which the bot-wide playback counters are stored.
+const GlobalStatsID int64 = 0
+
+// PlayStats holds the playback counters of a chat, or of the whole bot under GlobalStatsID.
+type PlayStats struct {
+	ChatID    int64 `bson:"_id"`
+	Seconds   int64 `bson:"seconds"`
+	Completed int64 `bson:"completed"`
+	Skipped   int64 `bson:"skipped"`
+}
+
+// TrackStat is how many times a track was played to the end in a chat, or bot-wide under GlobalStatsID.
+type TrackStat struct {
+	ChatID  int64  `bson:"chat_id"`
+	TrackID string `bson:"track_id"`
+	Name    string `bson:"name"`
+	Plays   int64  `bson:"plays"`
+}
+
+// AddPlayStats adds to the playback counters of a chat.
+func (db *Database) AddPlayStats(ctx context.Context, chatID, seconds, completed, skipped int64) error {
+	_, err := db.playStatsDB.UpdateOne(ctx,
+		bson.M{"_id": chatID},
+		bson.M{"$inc": bson.M{"seconds": seconds, "completed": completed, "skipped": skipped}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// AddTrackPlays adds to the play count of a track in a chat.
+func (db *Database) AddTrackPlays(ctx context.Context, chatID int64, trackID, name string, plays int64) error {
+	_, err := db.trackStatsDB.UpdateOne(ctx,
+		bson.M{"chat_id": chatID, "track_id": trackID},
+		bson.M{"$inc": bson.M{"plays": plays}, "$set": bson.M{"name": name}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// GetPlayStats retrieves the playback counters of a chat.
+// A chat that has not played anything yet gets zeroed counters.
+func (db *Database) GetPlayStats(ctx context.Context, chatID int64) (*PlayStats, error) {
+	stats := &PlayStats{ChatID: chatID}
+	err := db.playStatsDB.FindOne(ctx, bson.M{"_id": chatID}).Decode(stats)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// TopTracks retrieves the most-played tracks of a chat, most played first.
+func (db *Database) TopTracks(ctx context.Context, chatID int64, limit int64) ([]TrackStat, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "plays", Value: -1}}).SetLimit(limit)
+	cursor, err := db.trackStatsDB.Find(ctx, bson.M{"chat_id": chatID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tracks []TrackStat
+	if err := cursor.All(ctx, &tracks); err != nil {
+		return nil, err
+	}
+	return tracks, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Premium sources record how a subscription was last extended.
+const (
+	PremiumPaid    = "payment"
+	PremiumGranted = "grant"
+)
+
+// PremiumUser is a user's premium subscription. It lapses on its own once Until has passed.
+type PremiumUser struct {
+	UserID    int64            `bson:"_id" json:"user_id"`
+	Until     time.Time        `bson:"until" json:"until"`
+	Source    string           `bson:"source" json:"source"`
+	Payments  []PremiumPayment `bson:"payments,omitempty" json:"payments,omitempty"`
+	UpdatedAt time.Time        `bson:"updated_at" json:"updated_at"`
+}
+
+// PremiumPayment is one successful payment, kept so that it can be refunded.
+type PremiumPayment struct {
+	ChargeID string    `bson:"charge_id" json:"charge_id"`
+	Amount   int64     `bson:"amount" json:"amount"`
+	Currency string    `bson:"currency" json:"currency"`
+	At       time.Time `bson:"at" json:"at"`
+}
+
+// GetPremium returns userID's subscription, or nil if they never had one.
+func (db *Database) GetPremium(ctx context.Context, userID int64) (*PremiumUser, error) {
+	var p PremiumUser
+	err := db.premiumDB.FindOne(ctx, bson.M{"_id": userID}).Decode(&p)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// PremiumUntil returns when userID's subscription ends, or the zero time if they have none. Lookups are
+// cached briefly since every play request asks; failures count as no subscription.
+func (db *Database) PremiumUntil(ctx context.Context, userID int64) time.Time {
+	key := toKey(userID)
+	if until, ok := db.premiumCache.Get(key); ok {
+		return until
+	}
+	p, err := db.GetPremium(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to look up the premium subscription of %d: %v", userID, err)
+		return time.Time{}
+	}
+	var until time.Time
+	if p != nil {
+		until = p.Until
+	}
+	db.premiumCache.Set(key, until)
+	return until
+}
+
+// ExtendPremium adds d to userID's subscription, counting from now if it has lapsed, and returns the new
+// end. payment is recorded when the extension was paid for.
+func (db *Database) ExtendPremium(ctx context.Context, userID int64, d time.Duration, source string, payment *PremiumPayment) (time.Time, error) {
+	now := time.Now()
+	start := now
+	if p, err := db.GetPremium(ctx, userID); err != nil {
+		return time.Time{}, err
+	} else if p != nil && p.Until.After(now) {
+		start = p.Until
+	}
+	until := start.Add(d)
+
+	update := bson.M{"$set": bson.M{"until": until, "source": source, "updated_at": now}}
+	if payment != nil {
+		update["$push"] = bson.M{"payments": payment}
+	}
+	if _, err := db.premiumDB.UpdateOne(ctx, bson.M{"_id": userID}, update, options.UpdateOne().SetUpsert(true)); err != nil {
+		return time.Time{}, err
+	}
+	db.premiumCache.Set(toKey(userID), until)
+	return until, nil
+}
+
+// RevokePremium ends userID's subscription now. The payment history is kept.
+func (db *Database) RevokePremium(ctx context.Context, userID int64) (bool, error) {
+	now := time.Now()
+	res, err := db.premiumDB.UpdateOne(ctx,
+		bson.M{"_id": userID, "until": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"until": now, "source": PremiumGranted, "updated_at": now}},
+	)
+	if err != nil {
+		return false, err
+	}
+	db.premiumCache.Delete(toKey(userID))
+	return res.ModifiedCount > 0, nil
+}
@@ -0,0 +1,194 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// UserData is everything stored about a user, as exported by /mydata.
+type UserData struct {
+	UserID int64 `json:"user_id"`
+	// Profile is the user's own record: language, dismissed announcements and the like. It is nil if the
+	// user has none.
+	Profile map[string]any `json:"profile"`
+	// Playlists are the playlists the user owns.
+	Playlists []Playlist `json:"playlists"`
+	// AuthChats are the chats where the user is an authorized user.
+	AuthChats []int64 `json:"auth_chats"`
+	// QueuedIn are the chats whose saved queue holds tracks the user requested.
+	QueuedIn []int64 `json:"queued_in"`
+	// Requests are the user's playback requests per day, as ranked by /top.
+	Requests []DailyRequests `json:"requests"`
+	// ChatRequests are the user's playback requests per chat and day, as shown in weekly recaps.
+	ChatRequests []ChatDailyRequests `json:"chat_requests"`
+	// Reports are the reports the user sent with /report.
+	Reports []Report `json:"reports"`
+	// Premium is the user's premium subscription and its payments, or nil if they never had one.
+	Premium *PremiumUser `json:"premium"`
+}
+
+// DailyRequests is how many tracks a user requested on one day.
+type DailyRequests struct {
+	Day      time.Time `json:"day" bson:"day"`
+	Requests int64     `json:"requests" bson:"requests"`
+}
+
+// ChatDailyRequests is how many tracks a user requested in one chat on one day.
+type ChatDailyRequests struct {
+	ChatID   int64     `json:"chat_id" bson:"chat"`
+	Day      time.Time `json:"day" bson:"day"`
+	Requests int64     `json:"requests" bson:"requests"`
+}
+
+// DeletedUserData counts what DeleteUserData removed.
+type DeletedUserData struct {
+	Profile   bool
+	Playlists int64
+	AuthChats int64
+	Queues    int64
+	Stats     int64
+	Reports   int64
+}
+
+// chatIDs returns the IDs of the chats matching filter in coll.
+func chatIDs(ctx context.Context, coll *mongo.Collection, filter bson.M) ([]int64, error) {
+	cursor, err := coll.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID int64 `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(docs))
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	return ids, nil
+}
+
+// ExportUserData gathers everything stored about userID.
+func (db *Database) ExportUserData(ctx context.Context, userID int64) (*UserData, error) {
+	data := &UserData{UserID: userID}
+
+	var profile bson.M
+	err := db.userDB.FindOne(ctx, bson.M{"_id": userID}).Decode(&profile)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if profile != nil {
+		delete(profile, "_id")
+		data.Profile = profile
+	}
+
+	if data.Playlists, err = db.GetUserPlaylists(ctx, userID); err != nil {
+		return nil, err
+	}
+	if data.AuthChats, err = chatIDs(ctx, db.chatDB, bson.M{"auth_users": userID}); err != nil {
+		return nil, err
+	}
+	if data.QueuedIn, err = chatIDs(ctx, db.snapshotDB, bson.M{"tracks.userid": userID}); err != nil {
+		return nil, err
+	}
+
+	cursor, err := db.dailyStatsDB.Find(ctx, bson.M{"kind": dailyUser, "id": userID}, options.Find().SetSort(bson.D{{Key: "day", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &data.Requests); err != nil {
+		return nil, err
+	}
+
+	cursor, err = db.dailyStatsDB.Find(ctx, bson.M{"kind": dailyChatUser, "id": userID}, options.Find().SetSort(bson.D{{Key: "day", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &data.ChatRequests); err != nil {
+		return nil, err
+	}
+
+	cursor, err = db.reportDB.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &data.Reports); err != nil {
+		return nil, err
+	}
+	if data.Premium, err = db.GetPremium(ctx, userID); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// DeleteUserData removes userID from every collection: their record, playlists, per-chat authorization,
+// the requester fields of saved queues, the daily counters and their reports. Without a record the user is
+// also left out of broadcasts until they start the bot again.
+// The audit log is kept, since it records what admins did rather than data about the user, and so is the
+// premium subscription, since it records payments that may have to be refunded. Request quota counters
+// expire within two days and are left to do so, since deleting them would reset the user's quota.
+// Data held in memory, such as queued tracks and unflushed counters, is removed by the player's ForgetUser,
+// which callers run first.
+func (db *Database) DeleteUserData(ctx context.Context, userID int64) (*DeletedUserData, error) {
+	var deleted DeletedUserData
+
+	res, err := db.userDB.DeleteOne(ctx, bson.M{"_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	deleted.Profile = res.DeletedCount > 0
+	db.userCache.Delete(toKey(userID))
+
+	if res, err = db.playlistDB.DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return nil, err
+	}
+	deleted.Playlists = res.DeletedCount
+
+	chats, err := chatIDs(ctx, db.chatDB, bson.M{"auth_users": userID})
+	if err != nil {
+		return nil, err
+	}
+	for _, chatID := range chats {
+		if err := db.RemoveAuthUser(ctx, chatID, userID); err != nil {
+			return nil, err
+		}
+	}
+	deleted.AuthChats = int64(len(chats))
+
+	update, err := db.snapshotDB.UpdateMany(ctx,
+		bson.M{"tracks.userid": userID},
+		bson.M{"$set": bson.M{"tracks.$[t].userid": 0, "tracks.$[t].user": ""}},
+		options.UpdateMany().SetArrayFilters([]any{bson.M{"t.userid": userID}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	deleted.Queues = update.ModifiedCount
+
+	if res, err = db.dailyStatsDB.DeleteMany(ctx, bson.M{"kind": bson.M{"$in": []string{dailyUser, dailyChatUser}}, "id": userID}); err != nil {
+		return nil, err
+	}
+	deleted.Stats = res.DeletedCount
+
+	if res, err = db.reportDB.DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+		return nil, err
+	}
+	deleted.Reports = res.DeletedCount
+	return &deleted, nil
+}
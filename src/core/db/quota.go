@@ -0,0 +1,102 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// quotaRetention is how long a day's request counter is kept. It outlives the day in every time zone.
+const quotaRetention = 48 * time.Hour
+
+// requestCount is a user's number of requests in a chat on one chat-local day.
+type requestCount struct {
+	ID      string    `bson:"_id"`
+	ChatID  int64     `bson:"chat_id"`
+	UserID  int64     `bson:"user_id"`
+	Count   int       `bson:"count"`
+	Expires time.Time `bson:"expires"`
+}
+
+// quotaKey returns the ID of the request counter of userID in chatID on day, a chat-local date.
+func quotaKey(chatID, userID int64, day string) string {
+	return fmt.Sprintf("%d:%d:%s", chatID, userID, day)
+}
+
+// ensureQuotaIndex makes MongoDB drop request counters once they expire.
+func (db *Database) ensureQuotaIndex(ctx context.Context) {
+	_, err := db.quotaDB.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logger.Warn("Failed to create the request quota index: %v", err)
+	}
+}
+
+// RequestsOn returns how many requests userID made in chatID on day, a chat-local date such as "2025-01-31".
+func (db *Database) RequestsOn(ctx context.Context, chatID, userID int64, day string) (int, error) {
+	key := quotaKey(chatID, userID, day)
+	if count, ok := db.quotaCache.Get(key); ok {
+		return count, nil
+	}
+
+	var doc requestCount
+	err := db.quotaDB.FindOne(ctx, bson.M{"_id": key}).Decode(&doc)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, err
+	}
+	db.quotaCache.Set(key, doc.Count)
+	return doc.Count, nil
+}
+
+// AddRequest counts a request by userID in chatID on day and returns the day's new total. Counting comes
+// before checking the total against the limit, so that concurrent requests cannot all pass the check.
+func (db *Database) AddRequest(ctx context.Context, chatID, userID int64, day string) (int, error) {
+	key := quotaKey(chatID, userID, day)
+	var doc requestCount
+	err := db.quotaDB.FindOneAndUpdate(ctx,
+		bson.M{"_id": key},
+		bson.M{"$inc": bson.M{"count": 1}, "$setOnInsert": bson.M{
+			"chat_id": chatID, "user_id": userID, "expires": time.Now().Add(quotaRetention),
+		}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&doc)
+	if err != nil {
+		return 0, err
+	}
+	db.quotaCache.Set(key, doc.Count)
+	return doc.Count, nil
+}
+
+// RefundRequest takes back a request counted by AddRequest that was refused or did not queue anything.
+func (db *Database) RefundRequest(ctx context.Context, chatID, userID int64, day string) error {
+	key := quotaKey(chatID, userID, day)
+	var doc requestCount
+	err := db.quotaDB.FindOneAndUpdate(ctx,
+		bson.M{"_id": key, "count": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"count": -1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	db.quotaCache.Set(key, doc.Count)
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Report statuses.
+const (
+	ReportOpen     = "open"
+	ReportResolved = "resolved"
+	ReportIgnored  = "ignored"
+)
+
+// Report is an issue a user sent to the support chat with /report.
+type Report struct {
+	ID        int64  `bson:"_id"`
+	ChatID    int64  `bson:"chat_id"`
+	ChatTitle string `bson:"chat_title"`
+	UserID    int64  `bson:"user_id"`
+	UserName  string `bson:"user_name"`
+	Text      string `bson:"text"`
+	Track     string `bson:"track,omitempty"`
+	ErrorID   string `bson:"error_id,omitempty"`
+	Playback  string `bson:"playback,omitempty"`
+	Status    string `bson:"status"`
+	ClosedBy  int64  `bson:"closed_by,omitempty"`
+	Created   int64  `bson:"created"`
+	Closed    int64  `bson:"closed,omitempty"`
+}
+
+// AddReport stores a new open report and sets its ID and creation time.
+func (db *Database) AddReport(ctx context.Context, report *Report) error {
+	now := time.Now()
+	report.ID = now.UnixNano()
+	report.Created = now.Unix()
+	report.Status = ReportOpen
+	_, err := db.reportDB.InsertOne(ctx, report)
+	return err
+}
+
+// CloseReport marks an open report as resolved or ignored by closedBy and returns it.
+// It returns nil if the report does not exist or was already closed.
+func (db *Database) CloseReport(ctx context.Context, id int64, status string, closedBy int64) (*Report, error) {
+	var report Report
+	err := db.reportDB.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": ReportOpen},
+		bson.M{"$set": bson.M{"status": status, "closed_by": closedBy, "closed": time.Now().Unix()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&report)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// OpenReports returns up to limit open reports, oldest first.
+func (db *Database) OpenReports(ctx context.Context, limit int64) ([]Report, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created", Value: 1}}).SetLimit(limit)
+	cursor, err := db.reportDB.Find(ctx, bson.M{"status": ReportOpen}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reports []Report
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// CountOpenReports counts the reports that are still open.
+func (db *Database) CountOpenReports(ctx context.Context) (int64, error) {
+	return db.reportDB.CountDocuments(ctx, bson.M{"status": ReportOpen})
+}
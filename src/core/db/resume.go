@@ -0,0 +1,73 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ResumePointTTL is how long a chat's resume point stays usable.
+const ResumePointTTL = 24 * time.Hour
+
+// ResumePoint is where a chat's playback of a track was stopped, so the track can pick up from there when
+// it is played again.
+type ResumePoint struct {
+	ChatID   int64     `bson:"_id"`
+	Platform string    `bson:"platform"`
+	TrackID  string    `bson:"track_id"`
+	Elapsed  int       `bson:"elapsed"`
+	Duration int       `bson:"duration"`
+	SavedAt  time.Time `bson:"saved_at"`
+}
+
+// ensureResumeIndex makes MongoDB drop resume points once they are older than ResumePointTTL.
+// Failing to create it is only logged; GetResumePoint ignores expired points either way.
+func (db *Database) ensureResumeIndex(ctx context.Context) {
+	_, err := db.resumeDB.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "saved_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(ResumePointTTL / time.Second)),
+	})
+	if err != nil {
+		logger.Warn("Failed to create the resume point index: %v", err)
+	}
+}
+
+// SaveResumePoint stores the chat's resume point, replacing the previous one.
+func (db *Database) SaveResumePoint(ctx context.Context, point *ResumePoint) error {
+	_, err := db.resumeDB.ReplaceOne(ctx, bson.M{"_id": point.ChatID}, point, options.Replace().SetUpsert(true))
+	return err
+}
+
+// GetResumePoint returns the chat's resume point for a track, or nil if the chat stopped a different track
+// last or the point has expired.
+func (db *Database) GetResumePoint(ctx context.Context, chatID int64, platform, trackID string) (*ResumePoint, error) {
+	var point ResumePoint
+	err := db.resumeDB.FindOne(ctx, bson.M{"_id": chatID, "platform": platform, "track_id": trackID}).Decode(&point)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if time.Since(point.SavedAt) > ResumePointTTL {
+		return nil, nil
+	}
+	return &point, nil
+}
+
+// DeleteResumePoint removes the chat's resume point.
+func (db *Database) DeleteResumePoint(ctx context.Context, chatID int64) error {
+	_, err := db.resumeDB.DeleteOne(ctx, bson.M{"_id": chatID})
+	return err
+}
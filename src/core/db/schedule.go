@@ -0,0 +1,93 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ScheduledPlay is a /schedule request waiting for its time. At is when playback starts; the request
+// message MsgID is replayed then, as if it had just been sent.
+type ScheduledPlay struct {
+	ID      int64     `bson:"_id"`
+	ChatID  int64     `bson:"chat_id"`
+	MsgID   int32     `bson:"msg_id"`
+	UserID  int64     `bson:"user_id"`
+	Query   string    `bson:"query"`
+	At      time.Time `bson:"at"`
+	Created time.Time `bson:"created"`
+}
+
+// ensureScheduleIndex indexes scheduled plays by time, which the scheduler polls.
+func (db *Database) ensureScheduleIndex(ctx context.Context) {
+	_, err := db.scheduleDB.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "at", Value: 1}}})
+	if err != nil {
+		logger.Warn("Failed to create the scheduled play index: %v", err)
+	}
+}
+
+// AddScheduledPlay stores a new scheduled play and sets its ID and creation time.
+func (db *Database) AddScheduledPlay(ctx context.Context, play *ScheduledPlay) error {
+	now := time.Now()
+	play.ID = now.UnixNano()
+	play.Created = now
+	_, err := db.scheduleDB.InsertOne(ctx, play)
+	return err
+}
+
+// GetScheduledPlays returns the chat's scheduled plays, soonest first.
+func (db *Database) GetScheduledPlays(ctx context.Context, chatID int64) ([]ScheduledPlay, error) {
+	cursor, err := db.scheduleDB.Find(ctx, bson.M{"chat_id": chatID}, options.Find().SetSort(bson.D{{Key: "at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var plays []ScheduledPlay
+	if err := cursor.All(ctx, &plays); err != nil {
+		return nil, err
+	}
+	return plays, nil
+}
+
+// CountScheduledPlays returns how many plays the chat has scheduled.
+func (db *Database) CountScheduledPlays(ctx context.Context, chatID int64) (int64, error) {
+	return db.scheduleDB.CountDocuments(ctx, bson.M{"chat_id": chatID})
+}
+
+// TakeScheduledPlay removes the chat's scheduled play with the given ID and returns it, or nil if it was
+// already cancelled or started. Whoever takes a play is the only one to act on it.
+func (db *Database) TakeScheduledPlay(ctx context.Context, chatID, id int64) (*ScheduledPlay, error) {
+	var play ScheduledPlay
+	err := db.scheduleDB.FindOneAndDelete(ctx, bson.M{"_id": id, "chat_id": chatID}).Decode(&play)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &play, nil
+}
+
+// DueScheduledPlays returns the scheduled plays whose time is up by now, oldest first.
+func (db *Database) DueScheduledPlays(ctx context.Context, now time.Time) ([]ScheduledPlay, error) {
+	cursor, err := db.scheduleDB.Find(ctx, bson.M{"at": bson.M{"$lte": now}}, options.Find().SetSort(bson.D{{Key: "at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var plays []ScheduledPlay
+	if err := cursor.All(ctx, &plays); err != nil {
+		return nil, err
+	}
+	return plays, nil
+}
@@ -0,0 +1,79 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// QueueSnapshot is the persisted state of a chat's queue, used to resume playback after a restart.
+// The first track is the one that was playing, Elapsed seconds into it.
+type QueueSnapshot struct {
+	ChatID  int64               `bson:"_id"`
+	Tracks  []cache.CachedTrack `bson:"tracks"`
+	Elapsed int                 `bson:"elapsed"`
+	SavedAt time.Time           `bson:"saved_at"`
+}
+
+// SaveQueueSnapshot stores or replaces the snapshot of a chat's queue.
+func (db *Database) SaveQueueSnapshot(ctx context.Context, snap *QueueSnapshot) error {
+	_, err := db.snapshotDB.ReplaceOne(ctx, bson.M{"_id": snap.ChatID}, snap, options.Replace().SetUpsert(true))
+	return err
+}
+
+// GetQueueSnapshot retrieves the snapshot of a chat's queue.
+// It returns nil if the chat has no snapshot.
+func (db *Database) GetQueueSnapshot(ctx context.Context, chatID int64) (*QueueSnapshot, error) {
+	var snap QueueSnapshot
+	err := db.snapshotDB.FindOne(ctx, bson.M{"_id": chatID}).Decode(&snap)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// GetQueueSnapshots retrieves every stored queue snapshot.
+func (db *Database) GetQueueSnapshots(ctx context.Context) ([]QueueSnapshot, error) {
+	cursor, err := db.snapshotDB.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var snaps []QueueSnapshot
+	if err := cursor.All(ctx, &snaps); err != nil {
+		return nil, err
+	}
+	return snaps, nil
+}
+
+// DeleteQueueSnapshotsBefore removes the snapshots saved before cutoff and returns how many were removed.
+func (db *Database) DeleteQueueSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := db.snapshotDB.DeleteMany(ctx, bson.M{"saved_at": bson.M{"$lt": cutoff}})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+// DeleteQueueSnapshot removes the snapshot of a chat's queue.
+func (db *Database) DeleteQueueSnapshot(ctx context.Context, chatID int64) error {
+	_, err := db.snapshotDB.DeleteOne(ctx, bson.M{"_id": chatID})
+	return err
+}
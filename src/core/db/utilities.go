@@ -11,7 +11,6 @@ package db
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -39,7 +38,7 @@ func getIntSlice(v interface{}) ([]int64, bool) {
 	case bson.A:
 		return convertInterfaceSlice(val)
 	default:
-		log.Printf("Unexpected type encountered in getIntSlice: %T", v)
+		logger.Warn("Unexpected type encountered in getIntSlice: %T", v)
 		return []int64{}, false
 	}
 }
@@ -60,7 +59,7 @@ func convertInterfaceSlice(arr []interface{}) ([]int64, bool) {
 				out = append(out, int64(n))
 			}
 		default:
-			log.Printf("Unhandled numeric type in convertInterfaceSlice: %T", n)
+			logger.Warn("Unhandled numeric type in convertInterfaceSlice: %T", n)
 			return nil, false
 		}
 	}
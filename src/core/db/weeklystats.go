@@ -0,0 +1,160 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Kinds of daily counters kept per chat for the weekly recap.
+const (
+	dailyChatUser  = "chat_user"
+	dailyChatTrack = "chat_track"
+)
+
+// WeeklyRecap sums up a chat's playback over a week.
+type WeeklyRecap struct {
+	Plays   int64
+	Seconds int64
+	// TopUserID is the member who requested the most tracks, or 0 if nobody did.
+	TopUserID   int64
+	TopRequests int64
+	// TopTrack is the name of the track played to the end most often, or "" if none was.
+	TopTrack      string
+	TopTrackPlays int64
+	// BusiestDay is the day with the most tracks played, or the zero time if nothing was played.
+	BusiestDay   time.Time
+	BusiestPlays int64
+}
+
+// GetWeeklyStats reports whether a chat gets the weekly recap and whether it is pinned.
+func (db *Database) GetWeeklyStats(ctx context.Context, chatID int64) (enabled, pin bool) {
+	chat, _ := db.getChat(ctx, chatID)
+	if chat == nil {
+		return false, false
+	}
+	enabled, _ = chat["weekly_stats"].(bool)
+	pin, _ = chat["weekly_stats_pin"].(bool)
+	return enabled, pin
+}
+
+// SetWeeklyStats sets whether a chat gets the weekly recap and whether it is pinned.
+func (db *Database) SetWeeklyStats(ctx context.Context, chatID int64, enabled, pin bool) error {
+	return db.updateChatFields(ctx, chatID, bson.M{"weekly_stats": enabled, "weekly_stats_pin": pin && enabled})
+}
+
+// WeeklyStatsChats returns the chats that get the weekly recap.
+func (db *Database) WeeklyStatsChats(ctx context.Context) ([]int64, error) {
+	return chatIDs(ctx, db.chatDB, bson.M{"weekly_stats": true})
+}
+
+// ClaimWeeklyStats records that the recap of week is being posted in a chat, and reports whether it was not
+// already. Claiming before posting means a restart in the middle of a run never posts a recap twice.
+func (db *Database) ClaimWeeklyStats(ctx context.Context, chatID int64, week string) (bool, error) {
+	res, err := db.chatDB.UpdateOne(ctx,
+		bson.M{"_id": chatID, "weekly_stats_week": bson.M{"$ne": week}},
+		bson.M{"$set": bson.M{"weekly_stats_week": week}},
+	)
+	if err != nil {
+		return false, err
+	}
+	db.chatCache.Delete(toKey(chatID))
+	return res.ModifiedCount > 0, nil
+}
+
+// AddDailyChatRequests adds to the number of tracks a user requested in a chat on day.
+func (db *Database) AddDailyChatRequests(ctx context.Context, chatID, userID int64, day time.Time, requests int64) error {
+	_, err := db.dailyStatsDB.UpdateOne(ctx,
+		bson.M{"kind": dailyChatUser, "chat": chatID, "id": userID, "day": Day(day)},
+		bson.M{"$inc": bson.M{"requests": requests}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// AddDailyTrackPlays adds to the number of times a track was played to the end in a chat on day.
+func (db *Database) AddDailyTrackPlays(ctx context.Context, chatID int64, trackID, name string, day time.Time, plays int64) error {
+	_, err := db.dailyStatsDB.UpdateOne(ctx,
+		bson.M{"kind": dailyChatTrack, "chat": chatID, "track": trackID, "day": Day(day)},
+		bson.M{"$inc": bson.M{"plays": plays}, "$set": bson.M{"name": name}},
+		options.UpdateOne().SetUpsert(true),
+	)
+	return err
+}
+
+// topInChat returns the key with the highest total of field among the counters of kind in a chat from since
+// up to until, along with its name if the counters have one.
+func (db *Database) topInChat(ctx context.Context, kind, key, field string, chatID int64, since, until time.Time) (bson.M, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"kind": kind, "chat": chatID, "day": bson.M{"$gte": Day(since), "$lt": Day(until)}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$" + key, "total": bson.M{"$sum": "$" + field}, "name": bson.M{"$last": "$name"}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "total", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: 1}},
+	}
+	cursor, err := db.dailyStatsDB.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var top []bson.M
+	if err := cursor.All(ctx, &top); err != nil || len(top) == 0 {
+		return nil, err
+	}
+	return top[0], nil
+}
+
+// GetWeeklyRecap sums up a chat's playback on the days from since up to, but not including, until.
+func (db *Database) GetWeeklyRecap(ctx context.Context, chatID int64, since, until time.Time) (*WeeklyRecap, error) {
+	cursor, err := db.dailyStatsDB.Find(ctx,
+		bson.M{"kind": dailyChat, "id": chatID, "day": bson.M{"$gte": Day(since), "$lt": Day(until)}})
+	if err != nil {
+		return nil, err
+	}
+	var days []struct {
+		Day     time.Time `bson:"day"`
+		Plays   int64     `bson:"plays"`
+		Seconds int64     `bson:"seconds"`
+	}
+	if err := cursor.All(ctx, &days); err != nil {
+		return nil, err
+	}
+
+	recap := &WeeklyRecap{}
+	for _, d := range days {
+		recap.Plays += d.Plays
+		recap.Seconds += d.Seconds
+		if d.Plays > recap.BusiestPlays {
+			recap.BusiestDay, recap.BusiestPlays = d.Day, d.Plays
+		}
+	}
+
+	user, err := db.topInChat(ctx, dailyChatUser, "id", "requests", chatID, since, until)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		recap.TopUserID, _ = user["_id"].(int64)
+		recap.TopRequests, _ = user["total"].(int64)
+	}
+	track, err := db.topInChat(ctx, dailyChatTrack, "track", "plays", chatID, since, until)
+	if err != nil {
+		return nil, err
+	}
+	if track != nil {
+		recap.TopTrack, _ = track["name"].(string)
+		recap.TopTrackPlays, _ = track["total"].(int64)
+	}
+	return recap, nil
+}
@@ -0,0 +1,129 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package diag captures runtime profiles and serves net/http/pprof on the optional debug server.
+package diag
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/pprof"
+	rpprof "runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+)
+
+// Profiles lists the profiles Capture can take.
+var Profiles = []string{"cpu", "heap", "goroutine"}
+
+// ErrBusy is returned when another capture is already running.
+var ErrBusy = errors.New("another profile is being captured")
+
+// captureMu keeps captures from overlapping, whether they come from /profile or from the debug server.
+var captureMu sync.Mutex
+
+// Capture writes a profile of the given kind to w. A CPU profile covers the next d; heap and goroutine profiles
+// are taken after waiting d, so that they can be timed to follow a burst of activity. It returns ErrBusy instead of
+// waiting if another capture is running.
+func Capture(ctx context.Context, kind string, d time.Duration, w io.Writer) error {
+	if !captureMu.TryLock() {
+		return ErrBusy
+	}
+	defer captureMu.Unlock()
+
+	wait := func() error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+			return nil
+		}
+	}
+
+	switch kind {
+	case "cpu":
+		if err := rpprof.StartCPUProfile(w); err != nil {
+			return err
+		}
+		err := wait()
+		rpprof.StopCPUProfile()
+		return err
+	case "heap", "goroutine":
+		if err := wait(); err != nil {
+			return err
+		}
+		return rpprof.Lookup(kind).WriteTo(w, 0)
+	default:
+		return fmt.Errorf("unknown profile %q; use %s", kind, strings.Join(Profiles, ", "))
+	}
+}
+
+// exclusive serves h only if no other capture is running, for the pprof endpoints that sample over time.
+func exclusive(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !captureMu.TryLock() {
+			http.Error(w, ErrBusy.Error(), http.StatusConflict)
+			return
+		}
+		defer captureMu.Unlock()
+		h(w, r)
+	}
+}
+
+// authorized checks the request's token against DEBUG_TOKEN. The token is taken from an
+// "Authorization: Bearer" header or, for browsers, the token query parameter.
+func authorized(r *http.Request) bool {
+	want := config.Get().DebugToken
+	if want == "" {
+		return false
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		got = r.URL.Query().Get("token")
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// Handler returns the debug server's handler: net/http/pprof under /debug/pprof/ and expvar metrics under
+// /debug/vars, all behind DEBUG_TOKEN.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", exclusive(pprof.Profile))
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", exclusive(pprof.Trace))
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// Serve starts the debug server on addr in the background. Errors other than a normal close are passed to onError.
+func Serve(addr string, onError func(error)) *http.Server {
+	srv := &http.Server{Addr: addr, Handler: Handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			onError(err)
+		}
+	}()
+	return srv
+}
@@ -14,7 +14,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -22,13 +21,17 @@ import (
 
 	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/logging"
 )
 
+var logger = logging.For("dl")
+
 // ApiData provides a unified interface for fetching track and playlist information from various music platforms via an API gateway.
 type ApiData struct {
 	Query    string
 	ApiUrl   string
 	APIKey   string
+	Platform string // Platform restricts text searches to a single provider when set.
 	Patterns map[string]*regexp.Regexp
 }
 
@@ -45,8 +48,8 @@ var apiPatterns = map[string]*regexp.Regexp{
 func NewApiData(query string) *ApiData {
 	return &ApiData{
 		Query:    strings.TrimSpace(query),
-		ApiUrl:   strings.TrimRight(config.Conf.ApiUrl, "/"),
-		APIKey:   config.Conf.ApiKey,
+		ApiUrl:   strings.TrimRight(config.Get().ApiUrl, "/"),
+		APIKey:   config.Get().ApiKey,
 		Patterns: apiPatterns,
 	}
 }
@@ -55,7 +58,7 @@ func NewApiData(query string) *ApiData {
 // It returns true if the URL matches a known pattern, and false otherwise.
 func (a *ApiData) IsValid() bool {
 	if a.Query == "" || a.ApiUrl == "" || a.APIKey == "" {
-		log.Printf("The query, API URL, or API key is missing.")
+		logger.Debug("The query, API URL, or API key is missing.")
 		return false
 	}
 
@@ -102,10 +105,14 @@ func (a *ApiData) Search(ctx context.Context) (cache.PlatformTracks, error) {
 		return a.GetInfo(ctx)
 	}
 
-	fullURL := fmt.Sprintf("%s/search?%s", a.ApiUrl, url.Values{
+	params := url.Values{
 		"query": {a.Query},
 		"limit": {"5"},
-	}.Encode())
+	}
+	if a.Platform != "" {
+		params.Set("platform", a.Platform)
+	}
+	fullURL := fmt.Sprintf("%s/search?%s", a.ApiUrl, params.Encode())
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
 	if err != nil {
@@ -113,6 +120,7 @@ func (a *ApiData) Search(ctx context.Context) (cache.PlatformTracks, error) {
 	}
 	req.Header.Set("X-API-Key", a.APIKey)
 
+	defer logger.Time(ctx, "http", "api search", fullURL)()
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return cache.PlatformTracks{}, fmt.Errorf("the search request failed: %w", err)
@@ -0,0 +1,165 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package dl
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+)
+
+const (
+	// bandwidthChunk bounds a single read of a limited body, so that a slow cap is shared evenly between downloads.
+	bandwidthChunk = 32 << 10
+	// bandwidthMaxSleep bounds a single wait for the cap, so that a changed cap applies to in-flight downloads quickly.
+	bandwidthMaxSleep = 250 * time.Millisecond
+	// meterWindow is the number of seconds the throughput is averaged over.
+	meterWindow = 5
+)
+
+// bucket is a token bucket shared by every download the bot reads itself. It holds up to one second of the
+// cap, and the cap is looked up on every wait so that /reloadconfig and the low-bandwidth hours apply at once.
+type bucket struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// meter counts the bytes downloaded per second over the last few seconds.
+type meter struct {
+	mu    sync.Mutex
+	slots [meterWindow + 1]struct{ sec, bytes int64 }
+}
+
+var (
+	limiter    bucket
+	throughput meter
+)
+
+// RateLimit returns the download bandwidth cap in force now, in bytes per second, or 0 if there is none.
+func RateLimit() int64 {
+	return config.Get().DownloadRateAt(time.Now())
+}
+
+// Throughput returns the recent download rate of the bot, in bytes per second.
+func Throughput() int64 {
+	return throughput.rate(time.Now())
+}
+
+// wait blocks until n bytes may be read under the cap, or ctx is done.
+func (b *bucket) wait(ctx context.Context, n int) error {
+	for {
+		now := time.Now()
+		rate := float64(config.Get().DownloadRateAt(now))
+
+		b.mu.Lock()
+		if rate <= 0 {
+			b.tokens, b.last = 0, now
+			b.mu.Unlock()
+			return nil
+		}
+		if !b.last.IsZero() {
+			b.tokens += now.Sub(b.last).Seconds() * rate
+		}
+		b.tokens = min(b.tokens, rate)
+		b.last = now
+		// A read larger than the whole bucket goes through once the bucket is full, or it would wait forever.
+		if b.tokens >= float64(n) || b.tokens >= rate {
+			b.tokens -= float64(n)
+			b.mu.Unlock()
+			return nil
+		}
+		sleep := time.Duration((float64(n) - b.tokens) / rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(min(sleep, bandwidthMaxSleep))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// add records that n bytes were downloaded at now.
+func (m *meter) add(now time.Time, n int) {
+	sec := now.Unix()
+	m.mu.Lock()
+	slot := &m.slots[sec%int64(len(m.slots))]
+	if slot.sec != sec {
+		slot.sec, slot.bytes = sec, 0
+	}
+	slot.bytes += int64(n)
+	m.mu.Unlock()
+}
+
+// rate returns the average bytes per second over the last meterWindow whole seconds before now.
+func (m *meter) rate(now time.Time) int64 {
+	sec := now.Unix()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var total int64
+	for _, slot := range m.slots {
+		if slot.sec < sec && slot.sec >= sec-meterWindow {
+			total += slot.bytes
+		}
+	}
+	return total / meterWindow
+}
+
+// limitedReader reads a download body under the shared bandwidth cap and counts it towards the throughput.
+type limitedReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// limitBandwidth wraps a download body so that it is read under the shared bandwidth cap.
+func limitBandwidth(ctx context.Context, r io.Reader) io.Reader {
+	return &limitedReader{ctx: ctx, r: r}
+}
+
+// Read implements io.Reader.
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > bandwidthChunk {
+		p = p[:bandwidthChunk]
+	}
+	if err := limiter.wait(l.ctx, len(p)); err != nil {
+		return 0, err
+	}
+	n, err := l.r.Read(p)
+	if n < len(p) {
+		// Hand back what the read did not use, so that short reads do not eat into the cap.
+		limiter.mu.Lock()
+		limiter.tokens += float64(len(p) - n)
+		limiter.mu.Unlock()
+	}
+	if n > 0 {
+		throughput.add(time.Now(), n)
+	}
+	return n, err
+}
+
+// ytdlpRateLimit returns the --limit-rate value for a yt-dlp download, or "" if downloads are not capped.
+// A yt-dlp process cannot share the bucket, so each gets an even share of the cap for all concurrent downloads,
+// fixed when it starts.
+func ytdlpRateLimit() string {
+	rate := RateLimit()
+	if rate <= 0 {
+		return ""
+	}
+	if slots := config.Get().MaxDownloads; slots > 1 {
+		rate = max(rate/slots, 1)
+	}
+	return strconv.FormatInt(rate, 10)
+}
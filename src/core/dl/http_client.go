@@ -14,7 +14,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"math/big"
 	"net"
 	"net/http"
@@ -24,8 +23,6 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
-
-	"ashokshau/tgmusic/src/config"
 )
 
 const (
@@ -51,7 +48,7 @@ var client = &http.Client{
 func sendRequest(ctx context.Context, method, fullURL string, body io.Reader, headers map[string]string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
 	if err != nil {
-		log.Printf("Error creating request: %v", err)
+		logger.Ctx(ctx).Error("Error creating request: %v", err)
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
@@ -62,6 +59,8 @@ func sendRequest(ctx context.Context, method, fullURL string, body io.Reader, he
 		req.Header.Set(k, v)
 	}
 
+	defer logger.Time(ctx, "http", method, fullURL)()
+
 	var resp *http.Response
 	var reqErr error
 	backoff := initialBackoff
@@ -78,11 +77,11 @@ func sendRequest(ctx context.Context, method, fullURL string, body io.Reader, he
 				return resp, nil // Success
 			}
 			if err := resp.Body.Close(); err != nil {
-				log.Printf("failed to close response body: %v", err)
+				logger.Ctx(ctx).Warn("failed to close response body: %v", err)
 			}
 			reqErr = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 		} else if isTemporaryError(reqErr) {
-			log.Printf("Temporary error on attempt %d/%d: %v", attempt+1, maxRetries, reqErr)
+			logger.Ctx(ctx).Warn("Temporary error on attempt %d/%d: %v", attempt+1, maxRetries, reqErr)
 			continue // Retry on temporary errors
 		} else {
 			break // Do not retry on permanent errors
@@ -118,17 +117,17 @@ func generateUniqueName(ext string) string {
 // It returns a secure and sanitized filename.
 func determineFilename(urlStr, contentDisp string) string {
 	if filename := extractFilename(contentDisp); filename != "" {
-		return filepath.Join(config.Conf.DownloadsDir, sanitizeFilename(filename))
+		return DownloadPath(sanitizeFilename(filename))
 	}
 
 	if parsedURL, err := url.Parse(urlStr); err == nil {
 		filename := path.Base(parsedURL.Path)
 		if filename != "" && filename != "/" && !strings.Contains(filename, "?") {
-			return filepath.Join(config.Conf.DownloadsDir, sanitizeFilename(filename))
+			return DownloadPath(sanitizeFilename(filename))
 		}
 	}
 
-	return filepath.Join(config.Conf.DownloadsDir, generateUniqueName(".tmp"))
+	return DownloadPath(generateUniqueName(".tmp"))
 }
 
 // writeToFile writes data from an io.Reader to a specified file.
@@ -159,6 +158,7 @@ func DownloadFile(ctx context.Context, urlStr, fileName string, overwrite bool)
 
 	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
 	defer cancel()
+	defer logger.Time(ctx, "http", "download", urlStr)()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
@@ -185,19 +185,27 @@ func DownloadFile(ctx context.Context, urlStr, fileName string, overwrite bool)
 		if _, err := os.Stat(fileName); err == nil {
 			return fileName, nil // File already exists, no need to download again.
 		}
+		if existing, ok := FindDownload(filepath.Base(fileName)); ok {
+			return existing, nil // Downloaded earlier into another folder of the layout.
+		}
 	}
 
 	if err := os.MkdirAll(filepath.Dir(fileName), defaultDownloadDirPerm); err != nil {
 		return "", fmt.Errorf("failed to create the directory: %w", err)
 	}
 
-	tempPath := fileName + ".part"
-	if err := writeToFile(tempPath, resp.Body); err != nil {
-		return "", err
+	tempPath := fileName + partSuffix
+	progress := newProgressive(tempPath, resp.ContentLength)
+	err = writeToFile(tempPath, io.TeeReader(limitBandwidth(ctx, resp.Body), reportProgressive(ctx, progress)))
+	if err == nil {
+		if err = os.Rename(tempPath, fileName); err != nil {
+			err = fmt.Errorf("failed to rename the temporary file: %w", err)
+		}
 	}
-
-	if err := os.Rename(tempPath, fileName); err != nil {
-		return "", fmt.Errorf("failed to rename the temporary file: %w", err)
+	progress.finish(err)
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return "", err
 	}
 
 	return fileName, nil
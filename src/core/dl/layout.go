@@ -0,0 +1,174 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package dl
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/workspace"
+)
+
+// Download directory layouts selected with DOWNLOADS_LAYOUT.
+const (
+	// LayoutFlat keeps every file directly in DownloadsDir.
+	LayoutFlat = "flat"
+	// LayoutDaily puts files in a subfolder per day, such as downloads/2025-01-31/.
+	LayoutDaily = "daily"
+	// LayoutPrefix puts files in a subfolder named after the first two characters of the file name, usually the track ID.
+	LayoutPrefix = "prefix"
+)
+
+// layoutMarker records the layout the downloads directory was last arranged in.
+const layoutMarker = ".layout"
+
+const dayFormat = "2006-01-02"
+
+// shardOf returns the subfolder a file named name belongs to in layout, or "" for the flat layout.
+// Daily shards are named after day.
+func shardOf(layout, name string, day time.Time) string {
+	switch layout {
+	case LayoutDaily:
+		return day.Format(dayFormat)
+	case LayoutPrefix:
+		if len(name) < 2 {
+			return "_"
+		}
+		return name[:2]
+	default:
+		return ""
+	}
+}
+
+// DownloadPath returns where a new download named name is stored in the configured layout,
+// creating its subfolder if needed.
+func DownloadPath(name string) string {
+	dir := filepath.Join(config.Get().DownloadsDir, shardOf(config.Get().DownloadsLayout, name, time.Now()))
+	if err := os.MkdirAll(dir, defaultDownloadDirPerm); err != nil {
+		logger.Warn("Failed to create the download folder %s: %v", dir, err)
+	}
+	return filepath.Join(dir, name)
+}
+
+// ytdlpTemplate returns the yt-dlp output template that places files in the configured layout.
+func ytdlpTemplate() string {
+	base := "%(id)s.%(ext)s"
+	switch config.Get().DownloadsLayout {
+	case LayoutDaily:
+		base = filepath.Join(time.Now().Format(dayFormat), base)
+	case LayoutPrefix:
+		base = filepath.Join("%(id).2s", base)
+	}
+	return filepath.Join(config.Get().DownloadsDir, base)
+}
+
+// FindDownload looks for an existing download whose name matches the glob pattern, wherever the layout put it.
+// It returns the first match and whether one was found.
+func FindDownload(pattern string) (string, bool) {
+	root := config.Get().DownloadsDir
+	var dirs []string
+	switch config.Get().DownloadsLayout {
+	case LayoutDaily:
+		// Look at today's folder first; older days are only reached through the glob.
+		dirs = []string{filepath.Join(root, time.Now().Format(dayFormat)), filepath.Join(root, "*")}
+	case LayoutPrefix:
+		dirs = []string{filepath.Join(root, shardOf(LayoutPrefix, pattern, time.Time{}))}
+	default:
+		dirs = []string{root}
+	}
+
+	for _, dir := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.Mode().IsRegular() && !strings.HasSuffix(match, ".part") {
+				return match, true
+			}
+		}
+	}
+	return "", false
+}
+
+// MigrateLayout moves existing downloads into the configured layout if the directory was arranged differently before.
+// It does nothing when the layout has not changed since the last start.
+func MigrateLayout() error {
+	root := config.Get().DownloadsDir
+	layout := config.Get().DownloadsLayout
+	marker := filepath.Join(root, layoutMarker)
+
+	previous, err := os.ReadFile(marker)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if strings.TrimSpace(string(previous)) == layout {
+		return nil
+	}
+	// A directory without a marker was written by a version that only knew the flat layout.
+	if len(previous) == 0 && layout == LayoutFlat {
+		return os.WriteFile(marker, []byte(layout), 0644)
+	}
+
+	moved := 0
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path == workspace.Root() {
+			// Temporary files of running jobs are not downloads.
+			return filepath.SkipDir
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		name := d.Name()
+		if strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".part") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		dest := filepath.Join(root, shardOf(layout, name, info.ModTime()), name)
+		if dest == path {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), defaultDownloadDirPerm); err != nil {
+			return err
+		}
+		if err := os.Rename(path, dest); err != nil {
+			logger.Warn("Failed to move %s to %s: %v", path, dest, err)
+			return nil
+		}
+		moved++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	removeEmptyDirs(root)
+	logger.Info("Moved %d downloads into the %q layout.", moved, layout)
+	return os.WriteFile(marker, []byte(layout), 0644)
+}
+
+// removeEmptyDirs deletes the subfolders of root that were left empty by a migration.
+func removeEmptyDirs(root string) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			// os.Remove fails on folders that still hold files, which is what we want.
+			_ = os.Remove(filepath.Join(root, entry.Name()))
+		}
+	}
+}
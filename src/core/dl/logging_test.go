@@ -0,0 +1,91 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package dl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"ashokshau/tgmusic/src/core/logging"
+)
+
+// syncBuffer is a bytes.Buffer safe for the logger to write to.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records returns the JSON log records written so far.
+func (b *syncBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+// captureLogs sends all log output to the returned buffer as JSON for the rest of the test.
+func captureLogs(t *testing.T) *syncBuffer {
+	var out syncBuffer
+	logging.Setup("json", &out, slog.LevelDebug, nil)
+	t.Cleanup(func() { logging.Setup("text", os.Stderr, slog.LevelInfo, nil) })
+	return &out
+}
+
+func TestRequestIDReachesDownloadLogs(t *testing.T) {
+	out := captureLogs(t)
+
+	ctx := logging.WithRequestID(context.Background(), "abc123")
+	if _, err := sendRequest(ctx, "BAD METHOD", "http://127.0.0.1/", nil, nil); err == nil {
+		t.Fatal("sendRequest accepted an invalid method")
+	}
+
+	recs := out.records(t)
+	if len(recs) == 0 {
+		t.Fatal("sendRequest logged nothing")
+	}
+	for _, rec := range recs {
+		if rec["module"] != "dl" || rec["req"] != "abc123" {
+			t.Errorf("record %v lacks the dl module or the request ID", rec)
+		}
+	}
+}
+
+func TestLogsWithoutRequestID(t *testing.T) {
+	out := captureLogs(t)
+
+	_, _ = sendRequest(context.Background(), "BAD METHOD", "http://127.0.0.1/", nil, nil)
+	for _, rec := range out.records(t) {
+		if _, ok := rec["req"]; ok {
+			t.Errorf("record %v has a request ID although the context carries none", rec)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package dl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// partSuffix is appended to the name of a file while it is being downloaded.
+const partSuffix = ".part"
+
+// ErrDownloadStalled is returned by WaitFor when no data arrived for longer than the allowed stall time.
+var ErrDownloadStalled = errors.New("the download stalled")
+
+// Progressive is a download whose partially written file may be read while it is still being written.
+type Progressive struct {
+	// Path is the file the data is being written to. It is renamed once the download completes.
+	Path string
+
+	mu        sync.Mutex
+	written   int64
+	total     int64
+	lastWrite time.Time
+	changed   chan struct{}
+	done      chan struct{}
+	err       error
+}
+
+// newProgressive creates the progress record of a download into path; total is -1 if the size is unknown.
+func newProgressive(path string, total int64) *Progressive {
+	return &Progressive{
+		Path:      path,
+		total:     total,
+		lastWrite: time.Now(),
+		changed:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Write records that n more bytes reached the file. It implements io.Writer so it can be teed onto the copy.
+func (p *Progressive) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	p.written += int64(len(b))
+	p.lastWrite = time.Now()
+	close(p.changed)
+	p.changed = make(chan struct{})
+	p.mu.Unlock()
+	return len(b), nil
+}
+
+// finish marks the download as complete, or failed if err is not nil.
+func (p *Progressive) finish(err error) {
+	p.mu.Lock()
+	p.err = err
+	p.mu.Unlock()
+	close(p.done)
+}
+
+// Written returns how many bytes have been written so far.
+func (p *Progressive) Written() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.written
+}
+
+// Total returns the expected size of the file, or -1 if the server did not say.
+func (p *Progressive) Total() int64 {
+	return p.total
+}
+
+// Done is closed once the download has completed or failed.
+func (p *Progressive) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns why the download failed. It is only meaningful once Done is closed.
+func (p *Progressive) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// WaitFor blocks until at least n bytes are written or the download ends.
+// It returns ErrDownloadStalled if no data arrives for longer than stall.
+func (p *Progressive) WaitFor(ctx context.Context, n int64, stall time.Duration) error {
+	for {
+		p.mu.Lock()
+		written, last, changed := p.written, p.lastWrite, p.changed
+		p.mu.Unlock()
+		if written >= n {
+			return nil
+		}
+
+		timer := time.NewTimer(time.Until(last.Add(stall)))
+		select {
+		case <-changed:
+			timer.Stop()
+		case <-p.done:
+			timer.Stop()
+			return p.Err()
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+			return ErrDownloadStalled
+		}
+	}
+}
+
+// ReadyEarly waits until n bytes of the download are written and reports whether playback may start from the
+// partial file. It reports false if the download ended first, or failed or stalled, with the reason in err;
+// the caller then waits for the complete file instead.
+func (p *Progressive) ReadyEarly(ctx context.Context, n int64, stall time.Duration) (bool, error) {
+	if err := p.WaitFor(ctx, n, stall); err != nil {
+		return false, err
+	}
+	select {
+	case <-p.done:
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+// ErrPlaybackGone is returned by Playback.Elapsed once the track being followed is no longer playing.
+var ErrPlaybackGone = errors.New("the track is no longer playing")
+
+// Playback is the stream that plays a progressive download.
+type Playback interface {
+	// Elapsed returns how many seconds of the track have played. ErrPlaybackGone stops Follow; any other
+	// error skips that check.
+	Elapsed() (int, error)
+	Pause() error
+	Resume() error
+}
+
+// Follow keeps pb behind the download, which runs at bps bytes a second of audio. Every tick it pauses pb
+// when the download leads it by less than lowWater seconds, and resumes it once the download is buffer
+// seconds ahead again. It returns when the download ends, resuming pb if it paused it, or when pb is gone.
+func (p *Progressive) Follow(pb Playback, bps int64, buffer, lowWater int, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	paused := false
+	for {
+		select {
+		case <-p.done:
+			if paused {
+				_ = pb.Resume()
+			}
+			return
+		case <-ticker.C:
+		}
+
+		elapsed, err := pb.Elapsed()
+		if errors.Is(err, ErrPlaybackGone) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+
+		ahead := p.Written() - int64(elapsed)*bps
+		switch {
+		case !paused && ahead < bps*int64(lowWater):
+			if pb.Pause() == nil {
+				paused = true
+				logger.Info("[Follow] Playback caught up with the download of %s; buffering.", p.Path)
+			}
+		case paused && ahead >= bps*int64(buffer):
+			if pb.Resume() == nil {
+				paused = false
+			}
+		}
+	}
+}
+
+// ResolvePartial returns the file that the partial download at path was renamed to once it completed. While
+// the download is still being written, or if path is not a partial download, it returns path.
+func ResolvePartial(path string) string {
+	final, ok := strings.CutSuffix(path, partSuffix)
+	if !ok {
+		return path
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	if _, err := os.Stat(final); err == nil {
+		return final
+	}
+	return path
+}
+
+type progressiveKey struct{}
+
+// WithProgressive returns a context under which DownloadFile reports the download it starts on ch,
+// so the caller can use the file before it is complete. ch should be buffered; reports are dropped
+// rather than block the download.
+func WithProgressive(ctx context.Context, ch chan<- *Progressive) context.Context {
+	return context.WithValue(ctx, progressiveKey{}, ch)
+}
+
+// reportProgressive announces p to the caller that asked for progressive downloads, if any.
+// It returns the writer that tracks the download's progress.
+func reportProgressive(ctx context.Context, p *Progressive) io.Writer {
+	ch, ok := ctx.Value(progressiveKey{}).(chan<- *Progressive)
+	if !ok {
+		return io.Discard
+	}
+	select {
+	case ch <- p:
+	default:
+	}
+	return p
+}
@@ -0,0 +1,276 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package dl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+)
+
+func TestResolvePartial(t *testing.T) {
+	dir := t.TempDir()
+	final := filepath.Join(dir, "track.mp3")
+	part := final + partSuffix
+
+	if got := ResolvePartial(final); got != final {
+		t.Fatalf("ResolvePartial of a complete file = %q", got)
+	}
+	if err := os.WriteFile(part, []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := ResolvePartial(part); got != part {
+		t.Fatalf("ResolvePartial while downloading = %q, want the partial file", got)
+	}
+	if err := os.Rename(part, final); err != nil {
+		t.Fatal(err)
+	}
+	if got := ResolvePartial(part); got != final {
+		t.Fatalf("ResolvePartial after completion = %q, want %q", got, final)
+	}
+	if err := os.Remove(final); err != nil {
+		t.Fatal(err)
+	}
+	if got := ResolvePartial(part); got != part {
+		t.Fatalf("ResolvePartial of a failed download = %q, want the partial path", got)
+	}
+}
+
+func TestWaitForBuffer(t *testing.T) {
+	p := newProgressive("x"+partSuffix, 100)
+	go func() {
+		for range 5 {
+			time.Sleep(5 * time.Millisecond)
+			_, _ = p.Write(make([]byte, 10))
+		}
+	}()
+	if err := p.WaitFor(context.Background(), 50, time.Second); err != nil {
+		t.Fatalf("WaitFor = %v", err)
+	}
+	if p.Written() < 50 {
+		t.Fatalf("WaitFor returned after %d bytes", p.Written())
+	}
+}
+
+func TestWaitForStall(t *testing.T) {
+	p := newProgressive("x"+partSuffix, -1)
+	_, _ = p.Write(make([]byte, 10))
+	if err := p.WaitFor(context.Background(), 50, 20*time.Millisecond); !errors.Is(err, ErrDownloadStalled) {
+		t.Fatalf("WaitFor = %v, want ErrDownloadStalled", err)
+	}
+}
+
+func TestWaitForEnd(t *testing.T) {
+	failed := errors.New("connection reset")
+	p := newProgressive("x"+partSuffix, -1)
+	go p.finish(failed)
+	if err := p.WaitFor(context.Background(), 50, time.Second); !errors.Is(err, failed) {
+		t.Fatalf("WaitFor = %v, want the download's error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p = newProgressive("x"+partSuffix, -1)
+	if err := p.WaitFor(ctx, 50, time.Second); !errors.Is(err, context.Canceled) {
+		t.Fatalf("WaitFor = %v, want context.Canceled", err)
+	}
+}
+
+const (
+	// chunkSize is how many bytes the throttled server sends at a time, one second of audio in these tests.
+	chunkSize = 1000
+	// chunkEvery is how long the throttled server waits between chunks.
+	chunkEvery = 5 * time.Millisecond
+)
+
+// throttledServer serves chunks chunks of chunkSize bytes, one every chunkEvery. If hold is not nil, it stops
+// after holdAfter chunks until hold is closed.
+func throttledServer(t *testing.T, chunks, holdAfter int, hold <-chan struct{}) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(chunks*chunkSize))
+		flusher := w.(http.Flusher)
+		chunk := make([]byte, chunkSize)
+		for i := 0; i < chunks; i++ {
+			if hold != nil && i == holdAfter {
+				select {
+				case <-hold:
+				case <-r.Context().Done():
+					return
+				}
+			}
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+			flusher.Flush()
+			time.Sleep(chunkEvery)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// startDownload downloads url into dir in the background and returns the download as soon as it starts, along
+// with the channel its result arrives on.
+func startDownload(t *testing.T, url, dir string) (*Progressive, <-chan error, string) {
+	t.Cleanup(config.Use(&config.BotConfig{}))
+	final := filepath.Join(dir, "track.mp3")
+	started := make(chan *Progressive, 1)
+	result := make(chan error, 1)
+	go func() {
+		path, err := DownloadFile(WithProgressive(context.Background(), started), url, final, true)
+		if err == nil && path != final {
+			err = errors.New("downloaded to " + path)
+		}
+		result <- err
+	}()
+
+	select {
+	case p := <-started:
+		return p, result, final
+	case err := <-result:
+		t.Fatalf("the download ended before it was reported: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the download was never reported")
+	}
+	return nil, nil, ""
+}
+
+// fakePlayback plays speed seconds of audio every time it is checked, unless paused.
+type fakePlayback struct {
+	mu              sync.Mutex
+	speed, elapsed  int
+	paused          bool
+	pauses, resumes int
+}
+
+func (f *fakePlayback) Elapsed() (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.paused {
+		f.elapsed += f.speed
+	}
+	return f.elapsed, nil
+}
+
+func (f *fakePlayback) Pause() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = true
+	f.pauses++
+	return nil
+}
+
+func (f *fakePlayback) Resume() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = false
+	f.resumes++
+	return nil
+}
+
+func TestPlaybackFollowsAThrottledDownload(t *testing.T) {
+	const chunks, buffer, lowWater = 60, 10, 3
+	p, result, final := startDownload(t, throttledServer(t, chunks, 0, nil).URL, t.TempDir())
+
+	// Playback starts once buffer seconds are on disk, well before the download is complete.
+	early, err := p.ReadyEarly(context.Background(), buffer*chunkSize, time.Second)
+	if !early || err != nil {
+		t.Fatalf("ReadyEarly = %v, %v; want playback to start early", early, err)
+	}
+	if got := p.Written(); got < buffer*chunkSize || got >= chunks*chunkSize {
+		t.Fatalf("playback started after %d bytes, want at least %d and less than all %d", got, buffer*chunkSize, chunks*chunkSize)
+	}
+	if _, err := os.Stat(p.Path); err != nil {
+		t.Fatalf("the partial file is missing while it downloads: %v", err)
+	}
+	if _, err := os.Stat(final); err == nil {
+		t.Fatal("the final file exists before the download is complete")
+	}
+
+	// Playback runs three times faster than the download, so it keeps catching up and pausing.
+	pb := &fakePlayback{speed: 3}
+	p.Follow(pb, chunkSize, buffer, lowWater, chunkEvery)
+	if pb.pauses == 0 {
+		t.Fatal("playback never paused although it caught up with the download")
+	}
+	if pb.paused || pb.resumes != pb.pauses {
+		t.Fatalf("playback paused %d times and resumed %d times; it must end resumed", pb.pauses, pb.resumes)
+	}
+
+	if err := <-result; err != nil {
+		t.Fatalf("DownloadFile = %v", err)
+	}
+	if _, err := os.Stat(p.Path); !os.IsNotExist(err) {
+		t.Fatalf("the partial file was kept after the download: %v", err)
+	}
+	info, err := os.Stat(final)
+	if err != nil || info.Size() != chunks*chunkSize {
+		t.Fatalf("the final file is %v, %v; want %d bytes", info, err, chunks*chunkSize)
+	}
+	if got := ResolvePartial(p.Path); got != final {
+		t.Fatalf("ResolvePartial of the played path = %q, want %q", got, final)
+	}
+}
+
+func TestStalledDownloadWaitsForTheFullFile(t *testing.T) {
+	hold := make(chan struct{})
+	p, result, final := startDownload(t, throttledServer(t, 20, 3, hold).URL, t.TempDir())
+
+	early, err := p.ReadyEarly(context.Background(), 10*chunkSize, 50*time.Millisecond)
+	if early || !errors.Is(err, ErrDownloadStalled) {
+		t.Fatalf("ReadyEarly = %v, %v; want the stall to make playback wait", early, err)
+	}
+
+	// The caller now waits for the complete file, which arrives once the server goes on.
+	close(hold)
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("DownloadFile = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the download never completed")
+	}
+	if info, err := os.Stat(final); err != nil || info.Size() != 20*chunkSize {
+		t.Fatalf("the final file is %v, %v; want %d bytes", info, err, 20*chunkSize)
+	}
+	if early, err := p.ReadyEarly(context.Background(), 10*chunkSize, time.Second); early || err != nil {
+		t.Fatalf("ReadyEarly after completion = %v, %v; want the complete file", early, err)
+	}
+}
+
+func TestFollowStopsWhenPlaybackIsGone(t *testing.T) {
+	p := newProgressive("x"+partSuffix, -1)
+	done := make(chan struct{})
+	go func() {
+		p.Follow(gonePlayback{}, chunkSize, 10, 3, time.Millisecond)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Follow kept running after the track stopped playing")
+	}
+}
+
+// gonePlayback is a track that is no longer playing.
+type gonePlayback struct{}
+
+func (gonePlayback) Elapsed() (int, error) { return 0, ErrPlaybackGone }
+func (gonePlayback) Pause() error          { return nil }
+func (gonePlayback) Resume() error         { return nil }
@@ -0,0 +1,55 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package dl
+
+import (
+	"context"
+	"errors"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+)
+
+// SearchPlatforms lists the providers a chat can pick for plain-text searches.
+var SearchPlatforms = []string{cache.YouTube, cache.JioSaavn, cache.SoundCloud}
+
+// Resolve searches for a plain-text query on the given platform.
+// An empty platform keeps the configured default service. For any other provider, YouTube is
+// used as a fallback when the primary search fails or returns nothing.
+func Resolve(ctx context.Context, query, platform string) (cache.PlatformTracks, error) {
+	if platform == "" {
+		return NewDownloaderWrapper(query).Search(ctx)
+	}
+
+	if platform != cache.YouTube {
+		api := NewApiData(query)
+		api.Platform = platform
+		if api.ApiUrl != "" && api.APIKey != "" {
+			tracks, err := api.Search(ctx)
+			if err == nil && len(tracks.Results) > 0 {
+				for i := range tracks.Results {
+					if tracks.Results[i].Platform == "" {
+						tracks.Results[i].Platform = platform
+					}
+				}
+				return tracks, nil
+			}
+			logger.Ctx(ctx).Info("[Resolve] %s search for %q returned nothing, falling back to YouTube: %v", platform, query, config.RedactError(err))
+		}
+	}
+
+	tracks, err := NewYouTubeData(query).Search(ctx)
+	if err != nil {
+		return cache.PlatformTracks{}, config.RedactError(err)
+	}
+	if len(tracks.Results) == 0 {
+		return cache.PlatformTracks{}, errors.New("no results were found")
+	}
+	return tracks, nil
+}
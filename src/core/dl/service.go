@@ -48,7 +48,7 @@ func NewDownloaderWrapper(query string) *DownloaderWrapper {
 	} else if api.IsValid() {
 		chosen = api
 	} else {
-		switch config.Conf.DefaultService {
+		switch config.Get().DefaultService {
 		case "spotify":
 			chosen = api
 		default:
@@ -69,21 +69,25 @@ func (d *DownloaderWrapper) IsValid() bool {
 
 // GetInfo retrieves metadata by delegating the call to the wrapped service.
 func (d *DownloaderWrapper) GetInfo(ctx context.Context) (cache.PlatformTracks, error) {
-	return d.Service.GetInfo(ctx)
+	info, err := d.Service.GetInfo(ctx)
+	return info, config.RedactError(err)
 }
 
 // Search performs a search by delegating the call to the wrapped service.
 func (d *DownloaderWrapper) Search(ctx context.Context) (cache.PlatformTracks, error) {
-	return d.Service.Search(ctx)
+	tracks, err := d.Service.Search(ctx)
+	return tracks, config.RedactError(err)
 }
 
 // GetTrack retrieves detailed track information by delegating the call to the wrapped service.
 func (d *DownloaderWrapper) GetTrack(ctx context.Context) (cache.TrackInfo, error) {
-	return d.Service.GetTrack(ctx)
+	track, err := d.Service.GetTrack(ctx)
+	return track, config.RedactError(err)
 }
 
 // DownloadTrack downloads a track by delegating the call to the wrapped service.
 // It returns the file path of the downloaded track or an error if the download fails.
 func (d *DownloaderWrapper) DownloadTrack(ctx context.Context, info cache.TrackInfo, video bool) (string, error) {
-	return d.Service.downloadTrack(ctx, info, video)
+	filePath, err := d.Service.downloadTrack(ctx, info, video)
+	return filePath, config.RedactError(err)
 }
@@ -9,13 +9,13 @@
 package dl
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"os/exec"
@@ -41,12 +41,11 @@ var (
 // It returns the file path of the processed track or an error if any step fails.
 func (d *Download) processSpotify() (string, error) {
 	track := d.Track
-	downloadsDir := config.Conf.DownloadsDir
+	downloadsDir := config.Get().DownloadsDir
 	sanitizedTrackID := filepath.Base(track.TC)
 
-	outputFile := filepath.Join(downloadsDir, fmt.Sprintf("%s.ogg", sanitizedTrackID))
-	if _, err := os.Stat(outputFile); err == nil {
-		log.Printf("✅ The file already exists: %s", outputFile)
+	if outputFile, ok := FindDownload(fmt.Sprintf("%s.ogg", sanitizedTrackID)); ok {
+		logger.Ctx(d.ctx).Debug("✅ The file already exists: %s", outputFile)
 		return outputFile, nil
 	}
 
@@ -56,7 +55,7 @@ func (d *Download) processSpotify() (string, error) {
 
 	startTime := time.Now()
 	defer func() {
-		log.Printf("The process was completed in %s.", time.Since(startTime))
+		logger.Ctx(d.ctx).Debug("The process was completed in %s.", time.Since(startTime))
 	}()
 
 	encryptedFile := filepath.Join(downloadsDir, fmt.Sprintf("%s.encrypted", sanitizedTrackID))
@@ -68,12 +67,12 @@ func (d *Download) processSpotify() (string, error) {
 	}()
 
 	if err := d.downloadAndDecrypt(encryptedFile, decryptedFile); err != nil {
-		log.Printf("Failed to download and decrypt the file: %v", err)
+		logger.Ctx(d.ctx).Error("Failed to download and decrypt the file: %v", err)
 		return "", err
 	}
 
 	if err := rebuildOGG(decryptedFile); err != nil {
-		log.Printf("Failed to rebuild the OGG headers: %v", err)
+		logger.Ctx(d.ctx).Warn("Failed to rebuild the OGG headers: %v", err)
 	}
 
 	return fixOGG(decryptedFile, track)
@@ -82,6 +81,7 @@ func (d *Download) processSpotify() (string, error) {
 // downloadAndDecrypt handles the download and decryption of a file.
 // It takes the paths for the encrypted and decrypted files and returns an error if any step fails.
 func (d *Download) downloadAndDecrypt(encryptedPath, decryptedPath string) error {
+	defer logger.Time(d.ctx, "http", "download", d.Track.CdnURL)()
 	resp, err := http.Get(d.Track.CdnURL)
 	if err != nil {
 		return fmt.Errorf("failed to download the file: %w", err)
@@ -107,7 +107,7 @@ func (d *Download) downloadAndDecrypt(encryptedPath, decryptedPath string) error
 	if err != nil {
 		return fmt.Errorf("failed to decrypt the audio file: %w", err)
 	}
-	log.Printf("Decryption was completed in %s.", decryptTime)
+	logger.Ctx(d.ctx).Debug("Decryption was completed in %s.", decryptTime)
 
 	return os.WriteFile(decryptedPath, decryptedData, defaultFilePerm)
 }
@@ -191,10 +191,11 @@ func rebuildOGG(filename string) error {
 // It takes the input file path and track information, and returns the final output file path or an error.
 func fixOGG(inputFile string, track cache.TrackInfo) (string, error) {
 	sanitizedTrackID := filepath.Base(track.TC)
-	outputFile := filepath.Join(config.Conf.DownloadsDir, fmt.Sprintf("%s.ogg", sanitizedTrackID))
+	outputFile := DownloadPath(fmt.Sprintf("%s.ogg", sanitizedTrackID))
+	defer logger.Time(context.Background(), "exec", "ffmpeg", inputFile)()
 	cmd := exec.Command("ffmpeg", "-i", inputFile, "-c", "copy", outputFile)
 	if output, err := cmd.CombinedOutput(); err != nil {
-		return "", fmt.Errorf("ffmpeg failed with error: %w\nOutput: %s", err, string(output))
+		return "", fmt.Errorf("ffmpeg failed with error: %w\nOutput: %s", err, config.Redact(string(output)))
 	}
 
 	return outputFile, nil
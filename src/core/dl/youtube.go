@@ -13,7 +13,6 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
-	"log"
 	"math/big"
 	"os"
 	"os/exec"
@@ -43,8 +42,8 @@ var youtubePatterns = map[string]*regexp.Regexp{
 func NewYouTubeData(query string) *YouTubeData {
 	return &YouTubeData{
 		Query:    clearQuery(query),
-		ApiUrl:   strings.TrimRight(config.Conf.ApiUrl, "/"),
-		APIKey:   config.Conf.ApiKey,
+		ApiUrl:   strings.TrimRight(config.Get().ApiUrl, "/"),
+		APIKey:   config.Get().ApiKey,
 		Patterns: youtubePatterns,
 	}
 }
@@ -86,7 +85,7 @@ func (y *YouTubeData) extractVideoID(url string) string {
 // IsValid checks if the query string matches any of the known YouTube URL patterns.
 func (y *YouTubeData) IsValid() bool {
 	if y.Query == "" {
-		log.Println("The query or patterns are empty.")
+		logger.Debug("The query or patterns are empty.")
 		return false
 	}
 	for _, pattern := range y.Patterns {
@@ -112,7 +111,7 @@ func (y *YouTubeData) GetInfo(ctx context.Context) (cache.PlatformTracks, error)
 
 	tracks, err := searchYouTube(y.Query)
 	if err != nil {
-		return cache.PlatformTracks{}, err
+		return cache.PlatformTracks{}, config.RedactError(err)
 	}
 
 	for _, track := range tracks {
@@ -129,7 +128,7 @@ func (y *YouTubeData) GetInfo(ctx context.Context) (cache.PlatformTracks, error)
 func (y *YouTubeData) Search(ctx context.Context) (cache.PlatformTracks, error) {
 	tracks, err := searchYouTube(y.Query)
 	if err != nil {
-		return cache.PlatformTracks{}, err
+		return cache.PlatformTracks{}, config.RedactError(err)
 	}
 	if len(tracks) == 0 {
 		return cache.PlatformTracks{}, errors.New("no video results were found")
@@ -192,7 +191,7 @@ func (y *YouTubeData) downloadTrack(ctx context.Context, info cache.TrackInfo, v
 // BuildYtdlpParams constructs the command-line parameters for yt-dlp to download media.
 // It takes a video ID and a boolean indicating whether to download video or audio, and returns the corresponding parameters.
 func (y *YouTubeData) BuildYtdlpParams(videoID string, video bool) []string {
-	outputTemplate := filepath.Join(config.Conf.DownloadsDir, "%(id)s.%(ext)s")
+	outputTemplate := ytdlpTemplate()
 
 	params := []string{
 		"yt-dlp",
@@ -222,10 +221,14 @@ func (y *YouTubeData) BuildYtdlpParams(videoID string, video bool) []string {
 	}
 	params = append(params, "-f", formatSelector)
 
-	if cookieFile := y.getCookieFile(); cookieFile != "" {
+	if cookieFile := getCookieFile(); cookieFile != "" {
 		params = append(params, "--cookies", cookieFile)
-	} else if config.Conf.Proxy != "" {
-		params = append(params, "--proxy", config.Conf.Proxy)
+	} else if config.Get().Proxy != "" {
+		params = append(params, "--proxy", config.Get().Proxy)
+	}
+
+	if rate := ytdlpRateLimit(); rate != "" {
+		params = append(params, "--limit-rate", rate)
 	}
 
 	videoURL := "https://www.youtube.com/watch?v=" + videoID
@@ -237,14 +240,23 @@ func (y *YouTubeData) BuildYtdlpParams(videoID string, video bool) []string {
 // downloadWithYtDlp downloads media from YouTube using the yt-dlp command-line tool.
 // It returns the file path of the downloaded track or an error if the download fails.
 func (y *YouTubeData) downloadWithYtDlp(ctx context.Context, videoID string, video bool) (string, error) {
+	if filePath, ok := findYtDlpDownload(videoID, video); ok {
+		return filePath, nil
+	}
+
 	ytdlpParams := y.BuildYtdlpParams(videoID, video)
+	defer logger.Time(ctx, "exec", "yt-dlp", strings.Join(ytdlpParams[1:], " "))()
 	cmd := exec.CommandContext(ctx, ytdlpParams[0], ytdlpParams[1:]...)
 
 	output, err := cmd.Output()
 	if err != nil {
+		if ctx.Err() != nil {
+			removeYtDlpPartials(videoID)
+		}
+
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
-			stderr := string(exitErr.Stderr)
+			stderr := config.Redact(string(exitErr.Stderr))
 			return "", fmt.Errorf("yt-dlp failed with exit code %d: %s", exitErr.ExitCode(), stderr)
 		}
 
@@ -267,16 +279,45 @@ func (y *YouTubeData) downloadWithYtDlp(ctx context.Context, videoID string, vid
 	return downloadedPathStr, nil
 }
 
+// findYtDlpDownload looks for a file yt-dlp already downloaded for videoID into another folder of the layout.
+// Video downloads are merged into mp4, so audio requests ignore mp4 files.
+func findYtDlpDownload(videoID string, video bool) (string, bool) {
+	if config.Get().DownloadsLayout != LayoutDaily {
+		// yt-dlp finds its own files when they always land in the same folder.
+		return "", false
+	}
+	if video {
+		return FindDownload(videoID + ".mp4")
+	}
+	for _, ext := range []string{"m4a", "webm", "opus", "mp3"} {
+		if filePath, ok := FindDownload(videoID + "." + ext); ok {
+			return filePath, true
+		}
+	}
+	return "", false
+}
+
+// removeYtDlpPartials deletes the unfinished files yt-dlp left for videoID when it was stopped.
+func removeYtDlpPartials(videoID string) {
+	dir := filepath.Dir(DownloadPath(videoID))
+	for _, pattern := range []string{".*.part", ".*.ytdl"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, videoID+pattern))
+		for _, match := range matches {
+			_ = os.Remove(match)
+		}
+	}
+}
+
 // getCookieFile retrieves the path to a cookie file from the configured list.
 // It returns the path to a randomly selected cookie file.
-func (y *YouTubeData) getCookieFile() string {
-	cookiesPath := config.Conf.CookiesPath
+func getCookieFile() string {
+	cookiesPath := config.Get().CookiesPath
 	if len(cookiesPath) == 0 {
 		return ""
 	}
 	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(cookiesPath))))
 	if err != nil {
-		log.Printf("Could not generate a random number: %v", err)
+		logger.Warn("Could not generate a random number: %v", err)
 		return cookiesPath[0]
 	}
 
@@ -295,7 +336,7 @@ func (y *YouTubeData) downloadWithApi(ctx context.Context, videoID string, _ boo
 
 	down, err := NewDownload(ctx, track)
 	if err != nil {
-		log.Println("Error creating download: " + err.Error())
+		logger.Ctx(ctx).Error("Error creating download: %v", err)
 		return "", err
 	}
 
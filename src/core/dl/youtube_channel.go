@@ -0,0 +1,113 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package dl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+)
+
+// ErrNotChannel is returned by ChannelUploads for a reference that is not a YouTube channel.
+var ErrNotChannel = errors.New("not a YouTube channel")
+
+var (
+	channelIDPattern  = regexp.MustCompile(`^UC[\w-]{22}$`)
+	channelURLPattern = regexp.MustCompile(`^(?:https?://)?(?:www\.|m\.)?youtube\.com/((?:@|channel/|c/|user/)[^/?#]+)`)
+)
+
+// Channel is a YouTube channel with its latest uploads, newest first.
+type Channel struct {
+	ID      string
+	Name    string
+	Uploads []cache.MusicTrack
+}
+
+// ChannelURL returns the uploads page of a channel given as a URL, an @handle or a channel ID.
+func ChannelURL(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	switch {
+	case channelIDPattern.MatchString(ref):
+		return "https://www.youtube.com/channel/" + ref + "/videos", nil
+	case strings.HasPrefix(ref, "@") && len(ref) > 1 && !strings.ContainsAny(ref, "/ "):
+		return "https://www.youtube.com/" + ref + "/videos", nil
+	}
+	if m := channelURLPattern.FindStringSubmatch(ref); m != nil {
+		return "https://www.youtube.com/" + m[1] + "/videos", nil
+	}
+	return "", ErrNotChannel
+}
+
+// ChannelUploads lists the latest n uploads of a channel given as a URL, an @handle or a channel ID. It uses
+// yt-dlp's flat playlist mode, which reads the uploads page without resolving every video.
+func ChannelUploads(ctx context.Context, ref string, n int) (*Channel, error) {
+	pageURL, err := ChannelURL(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"--flat-playlist", "--dump-single-json", "--no-warnings", "--playlist-end", strconv.Itoa(n)}
+	if cookieFile := getCookieFile(); cookieFile != "" {
+		args = append(args, "--cookies", cookieFile)
+	} else if proxy := config.Get().Proxy; proxy != "" {
+		args = append(args, "--proxy", proxy)
+	}
+	defer logger.Time(ctx, "exec", "yt-dlp", pageURL)()
+	output, err := exec.CommandContext(ctx, "yt-dlp", append(args, pageURL)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("yt-dlp failed for %s: %s", pageURL, config.Redact(strings.TrimSpace(string(exitErr.Stderr))))
+		}
+		return nil, err
+	}
+
+	var page struct {
+		ChannelID string `json:"channel_id"`
+		Channel   string `json:"channel"`
+		Uploader  string `json:"uploader"`
+		Entries   []struct {
+			ID       string  `json:"id"`
+			Title    string  `json:"title"`
+			Duration float64 `json:"duration"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(output, &page); err != nil {
+		return nil, fmt.Errorf("failed to read the uploads of %s: %w", pageURL, err)
+	}
+	if page.ChannelID == "" {
+		return nil, ErrNotChannel
+	}
+
+	channel := &Channel{ID: page.ChannelID, Name: page.Channel}
+	if channel.Name == "" {
+		channel.Name = page.Uploader
+	}
+	for _, entry := range page.Entries {
+		if entry.ID == "" {
+			continue
+		}
+		channel.Uploads = append(channel.Uploads, cache.MusicTrack{
+			URL:      "https://www.youtube.com/watch?v=" + entry.ID,
+			Name:     entry.Title,
+			ID:       entry.ID,
+			Duration: int(entry.Duration),
+			Platform: cache.YouTube,
+		})
+	}
+	return channel, nil
+}
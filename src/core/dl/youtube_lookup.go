@@ -0,0 +1,101 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package dl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os/exec"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+)
+
+// lookupBatch is how many videos one yt-dlp run of LookupVideos resolves.
+const lookupBatch = 50
+
+// VideoID returns the ID of the YouTube video a URL points to, or "" if it is not a YouTube video URL.
+func VideoID(url string) string {
+	y := NewYouTubeData(url)
+	if !y.IsValid() {
+		return ""
+	}
+	return y.extractVideoID(y.Query)
+}
+
+// LookupVideos fetches the metadata of YouTube videos by ID with one yt-dlp run per lookupBatch videos,
+// instead of one run per video. Videos that are unavailable are left out of the result; an error is only
+// returned if no video could be looked up at all.
+func LookupVideos(ctx context.Context, ids []string) (map[string]cache.MusicTrack, error) {
+	found := make(map[string]cache.MusicTrack, len(ids))
+	var lastErr error
+	for start := 0; start < len(ids); start += lookupBatch {
+		batch := ids[start:min(start+lookupBatch, len(ids))]
+		if err := lookupBatchVideos(ctx, batch, found); err != nil {
+			if ctx.Err() != nil {
+				return found, ctx.Err()
+			}
+			lastErr = err
+		}
+	}
+	if len(found) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return found, nil
+}
+
+// lookupBatchVideos runs yt-dlp once for the videos in ids and adds those it resolved to found.
+func lookupBatchVideos(ctx context.Context, ids []string, found map[string]cache.MusicTrack) error {
+	args := []string{"--skip-download", "--dump-json", "--no-warnings", "--ignore-errors", "--no-playlist"}
+	if cookieFile := getCookieFile(); cookieFile != "" {
+		args = append(args, "--cookies", cookieFile)
+	} else if proxy := config.Get().Proxy; proxy != "" {
+		args = append(args, "--proxy", proxy)
+	}
+	args = append(args, "--")
+	for _, id := range ids {
+		args = append(args, "https://www.youtube.com/watch?v="+id)
+	}
+
+	defer logger.Time(ctx, "exec", "yt-dlp", "lookup")()
+	// With --ignore-errors yt-dlp exits non-zero when any video failed, so its output is read regardless.
+	output, runErr := exec.CommandContext(ctx, "yt-dlp", args...).Output()
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	before := len(found)
+	for scanner.Scan() {
+		var video struct {
+			ID        string  `json:"id"`
+			Title     string  `json:"title"`
+			Duration  float64 `json:"duration"`
+			Thumbnail string  `json:"thumbnail"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &video) != nil || video.ID == "" {
+			continue
+		}
+		found[video.ID] = cache.MusicTrack{
+			URL:      "https://www.youtube.com/watch?v=" + video.ID,
+			Name:     video.Title,
+			ID:       video.ID,
+			Duration: int(video.Duration),
+			Cover:    video.Thumbnail,
+			Platform: cache.YouTube,
+		}
+	}
+	if len(found) == before {
+		if runErr != nil {
+			return runErr
+		}
+		return errors.New("yt-dlp resolved none of the videos")
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package elapsed follows how far into a track playback is.
+package elapsed
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time. It lets a Tracker be driven by a scripted clock.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// System is the Clock backed by time.Now.
+var System Clock = systemClock{}
+
+// Tracker follows how far into a track playback is, accounting for pauses, seeks and speed changes.
+// The zero value is not usable; create trackers with New.
+type Tracker struct {
+	mu      sync.Mutex
+	clock   Clock
+	base    time.Duration
+	anchor  time.Time
+	speed   float64
+	running bool
+}
+
+// New returns a paused tracker at the start of a track, playing at normal speed once started.
+func New(clock Clock) *Tracker {
+	if clock == nil {
+		clock = System
+	}
+	return &Tracker{clock: clock, speed: 1.0}
+}
+
+// elapsed returns the current position. The caller must hold t.mu.
+func (t *Tracker) elapsed(now time.Time) time.Duration {
+	if !t.running {
+		return t.base
+	}
+	return t.base + time.Duration(float64(now.Sub(t.anchor))*t.speed)
+}
+
+// Start begins counting from offset, as when a stream is (re)started at that position.
+func (t *Tracker) Start(offset time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.base = max(offset, 0)
+	t.anchor = t.clock.Now()
+	t.running = true
+}
+
+// Pause freezes the position. Pausing a paused tracker has no effect.
+func (t *Tracker) Pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.running {
+		return
+	}
+	t.base = t.elapsed(t.clock.Now())
+	t.running = false
+}
+
+// Resume continues counting from the frozen position. Resuming a running tracker has no effect.
+func (t *Tracker) Resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.running {
+		return
+	}
+	t.anchor = t.clock.Now()
+	t.running = true
+}
+
+// SeekTo moves the position to pos without changing whether the tracker is running.
+func (t *Tracker) SeekTo(pos time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.base = max(pos, 0)
+	t.anchor = t.clock.Now()
+}
+
+// SetSpeed changes how fast the position advances from now on; time already played is kept as is.
+func (t *Tracker) SetSpeed(speed float64) {
+	if speed <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock.Now()
+	t.base = t.elapsed(now)
+	t.anchor = now
+	t.speed = speed
+}
+
+// Elapsed returns the current position in the track.
+func (t *Tracker) Elapsed() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.elapsed(t.clock.Now())
+}
@@ -0,0 +1,119 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package elapsed
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock the test moves forward by hand.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTracker() (*Tracker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return New(clock), clock
+}
+
+func expect(t *testing.T, tr *Tracker, want time.Duration) {
+	t.Helper()
+	if got := tr.Elapsed(); got != want {
+		t.Fatalf("Elapsed = %v, want %v", got, want)
+	}
+}
+
+func TestNotStarted(t *testing.T) {
+	tr, clock := newTracker()
+	clock.advance(time.Minute)
+	expect(t, tr, 0)
+}
+
+func TestStartFromOffset(t *testing.T) {
+	tr, clock := newTracker()
+	tr.Start(30 * time.Second)
+	clock.advance(10 * time.Second)
+	expect(t, tr, 40*time.Second)
+
+	tr.Start(-5 * time.Second)
+	expect(t, tr, 0)
+}
+
+func TestPauseResume(t *testing.T) {
+	tr, clock := newTracker()
+	tr.Start(0)
+	clock.advance(10 * time.Second)
+	tr.Pause()
+	clock.advance(time.Hour)
+	expect(t, tr, 10*time.Second)
+
+	// Pausing twice must not count the paused time.
+	tr.Pause()
+	expect(t, tr, 10*time.Second)
+
+	tr.Resume()
+	clock.advance(5 * time.Second)
+	expect(t, tr, 15*time.Second)
+
+	// Resuming a running tracker must not reset its anchor.
+	tr.Resume()
+	clock.advance(5 * time.Second)
+	expect(t, tr, 20*time.Second)
+}
+
+func TestSeek(t *testing.T) {
+	tr, clock := newTracker()
+	tr.Start(0)
+	clock.advance(10 * time.Second)
+	tr.SeekTo(90 * time.Second)
+	clock.advance(time.Second)
+	expect(t, tr, 91*time.Second)
+
+	// Seeking while paused moves the position but keeps it frozen.
+	tr.Pause()
+	tr.SeekTo(20 * time.Second)
+	clock.advance(time.Minute)
+	expect(t, tr, 20*time.Second)
+}
+
+func TestSpeed(t *testing.T) {
+	tr, clock := newTracker()
+	tr.Start(0)
+	clock.advance(10 * time.Second)
+	tr.SetSpeed(2)
+	clock.advance(10 * time.Second)
+	expect(t, tr, 30*time.Second)
+
+	tr.SetSpeed(0.5)
+	clock.advance(10 * time.Second)
+	expect(t, tr, 35*time.Second)
+
+	// Invalid speeds are ignored.
+	tr.SetSpeed(0)
+	tr.SetSpeed(-1)
+	clock.advance(10 * time.Second)
+	expect(t, tr, 40*time.Second)
+}
+
+func TestSpeedWhilePaused(t *testing.T) {
+	tr, clock := newTracker()
+	tr.Start(0)
+	clock.advance(10 * time.Second)
+	tr.Pause()
+	tr.SetSpeed(2)
+	clock.advance(time.Minute)
+	expect(t, tr, 10*time.Second)
+
+	tr.Resume()
+	clock.advance(5 * time.Second)
+	expect(t, tr, 20*time.Second)
+}
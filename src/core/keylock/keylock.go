@@ -0,0 +1,60 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package keylock provides one mutex per chat. A chat's mutex only exists while it is held or waited for,
+// so quiet chats cost nothing and no mutex is ever dropped while someone still uses it.
+package keylock
+
+import "sync"
+
+// entry is the mutex of one key and how many callers hold it or wait for it.
+type entry struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// Map holds the mutexes of keys that are in use.
+type Map struct {
+	mu    sync.Mutex
+	locks map[int64]*entry
+}
+
+// New returns an empty Map.
+func New() *Map {
+	return &Map{locks: make(map[int64]*entry)}
+}
+
+// Lock locks the mutex of key, waiting until it is free, and returns the func that unlocks it.
+func (m *Map) Lock(key int64) (unlock func()) {
+	m.mu.Lock()
+	e, ok := m.locks[key]
+	if !ok {
+		e = &entry{}
+		m.locks[key] = e
+	}
+	e.refs++
+	m.mu.Unlock()
+
+	e.mu.Lock()
+	return func() {
+		e.mu.Unlock()
+		m.mu.Lock()
+		e.refs--
+		if e.refs == 0 {
+			delete(m.locks, key)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// Len returns how many keys are locked or waited for.
+func (m *Map) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.locks)
+}
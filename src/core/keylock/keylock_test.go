@@ -0,0 +1,85 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package keylock
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMutualExclusion(t *testing.T) {
+	m := New()
+	var inside [3]atomic.Int32
+	var wg sync.WaitGroup
+	for i := range 300 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := int64(i % 3)
+			unlock := m.Lock(key)
+			if n := inside[key].Add(1); n != 1 {
+				t.Errorf("%d holders of key %d", n, key)
+			}
+			inside[key].Add(-1)
+			unlock()
+		}()
+	}
+	wg.Wait()
+
+	if n := m.Len(); n != 0 {
+		t.Fatalf("Len = %d after every lock was released, want 0", n)
+	}
+}
+
+func TestEntryKeptWhileWaitedFor(t *testing.T) {
+	m := New()
+	unlock := m.Lock(1)
+
+	acquired := make(chan func())
+	go func() { acquired <- m.Lock(1) }()
+
+	// The first holder releasing must not drop the entry the waiter is queued on.
+	for m.entryRefs(1) != 2 {
+		runtime.Gosched()
+	}
+	unlock()
+	second := <-acquired
+	if m.Len() != 1 {
+		t.Fatalf("Len = %d while the second holder has the lock, want 1", m.Len())
+	}
+
+	third := make(chan func())
+	go func() { third <- m.Lock(1) }()
+	for m.entryRefs(1) != 2 {
+		runtime.Gosched()
+	}
+	select {
+	case <-third:
+		t.Fatal("a third caller got the lock while the second held it")
+	case <-time.After(20 * time.Millisecond):
+	}
+	second()
+	(<-third)()
+	if m.Len() != 0 {
+		t.Fatalf("Len = %d, want 0", m.Len())
+	}
+}
+
+// entryRefs returns how many callers hold or wait for key.
+func (m *Map) entryRefs(key int64) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.locks[key]; ok {
+		return e.refs
+	}
+	return 0
+}
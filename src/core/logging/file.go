@@ -0,0 +1,340 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package logging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileOptions controls rotation of the log file.
+type FileOptions struct {
+	MaxSize     int64         // MaxSize is the size in bytes at which the file is rotated; 0 never rotates.
+	MaxBackups  int           // MaxBackups is how many rotated files are kept; 0 keeps all.
+	MaxAge      time.Duration // MaxAge removes rotated files older than this; 0 keeps them regardless of age.
+	Compress    bool          // Compress gzips rotated files.
+	SyncOnError bool          // SyncOnError flushes the file to disk after every error record.
+}
+
+// rotatingFile is a log file that is rotated once it grows past MaxSize.
+type rotatingFile struct {
+	mu   sync.Mutex
+	path string
+	opts FileOptions
+	f    *os.File
+	size int64
+}
+
+// openRotatingFile opens path for appending, creating its directory if needed.
+func openRotatingFile(path string, opts FileOptions) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, opts: opts}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the file at r.path. The caller must hold r.mu or own r exclusively.
+func (r *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0750); err != nil {
+		return fmt.Errorf("failed to create the log directory: %w", err)
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open the log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to open the log file: %w", err)
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.opts.MaxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.opts.MaxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate moves the current file aside under a timestamped name and starts a new one. The caller must hold r.mu.
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	rotated := fmt.Sprintf("%s.%s", r.path, time.Now().Format("20060102-150405.000"))
+	if err := os.Rename(r.path, rotated); err != nil {
+		return err
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+	go r.cleanup(rotated, r.opts)
+	return nil
+}
+
+// cleanup compresses the file that was just rotated and removes backups beyond the retention limits.
+func (r *rotatingFile) cleanup(rotated string, opts FileOptions) {
+	if opts.Compress {
+		if err := compressFile(rotated); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to compress %s: %v\n", rotated, err)
+		}
+	}
+
+	backups, _ := filepath.Glob(r.path + ".*")
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	for i, name := range backups {
+		tooMany := opts.MaxBackups > 0 && i >= opts.MaxBackups
+		tooOld := false
+		if info, err := os.Stat(name); err == nil && opts.MaxAge > 0 {
+			tooOld = time.Since(info.ModTime()) > opts.MaxAge
+		}
+		if tooMany || tooOld {
+			_ = os.Remove(name)
+		}
+	}
+}
+
+// compressFile replaces name with a gzipped copy named name.gz.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(name + ".gz")
+		return err
+	}
+	return os.Remove(name)
+}
+
+// sync flushes the file to disk.
+func (r *rotatingFile) sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Sync()
+}
+
+// close closes the file.
+func (r *rotatingFile) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// tail returns up to n of the last lines of the file.
+func (r *rotatingFile) tail(n int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.Open(r.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Read backwards in chunks until enough lines are found.
+	const chunk = 64 * 1024
+	var buf []byte
+	offset := r.size
+	for offset > 0 && bytes.Count(buf, []byte{'\n'}) <= n {
+		size := min(int64(chunk), offset)
+		offset -= size
+		part := make([]byte, size)
+		if _, err := f.ReadAt(part, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		buf = append(part, buf...)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(buf), "\n"), "\n")
+	if offset > 0 && len(lines) > 0 {
+		// The first line may have been cut by the chunk boundary.
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// ring keeps the most recent log lines in memory.
+type ring struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+	buf   []byte
+}
+
+// ringSize is how many lines the in-memory buffer keeps.
+const ringSize = 1000
+
+func newRing() *ring {
+	return &ring{lines: make([]string, ringSize)}
+}
+
+func (r *ring) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf = append(r.buf, p...)
+	for {
+		i := bytes.IndexByte(r.buf, '\n')
+		if i < 0 {
+			break
+		}
+		r.lines[r.next] = string(r.buf[:i])
+		r.next = (r.next + 1) % len(r.lines)
+		r.full = r.full || r.next == 0
+		r.buf = r.buf[i+1:]
+	}
+	r.buf = append([]byte(nil), r.buf...)
+	return len(p), nil
+}
+
+// tail returns up to n of the most recent lines.
+func (r *ring) tail(n int) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := r.next
+	if r.full {
+		count = len(r.lines)
+	}
+	n = min(n, count)
+	out := make([]string, 0, n)
+	for i := count - n; i < count; i++ {
+		out = append(out, r.lines[(r.next-count+i+len(r.lines))%len(r.lines)])
+	}
+	return out
+}
+
+var (
+	outMu   sync.RWMutex
+	logFile *rotatingFile
+	recent  = newRing()
+)
+
+// SetFile starts writing every log line to path as well, rotating it according to opts.
+// An empty path stops file logging. Calling it again with the same path only updates the options.
+func SetFile(path string, opts FileOptions) error {
+	outMu.Lock()
+	defer outMu.Unlock()
+
+	if logFile != nil && logFile.path == path {
+		logFile.mu.Lock()
+		logFile.opts = opts
+		logFile.mu.Unlock()
+		return nil
+	}
+
+	var next *rotatingFile
+	if path != "" {
+		var err error
+		if next, err = openRotatingFile(path, opts); err != nil {
+			return err
+		}
+	}
+	if logFile != nil {
+		_ = logFile.close()
+	}
+	logFile = next
+	return nil
+}
+
+// teeWriter copies everything written to it to its console, the in-memory buffer and the log file.
+type teeWriter struct {
+	console io.Writer
+}
+
+// Tee returns a writer that sends output to console, the recent-lines buffer behind Tail and the log file
+// set with SetFile, if any.
+func Tee(console io.Writer) io.Writer {
+	return teeWriter{console: console}
+}
+
+func (t teeWriter) Write(p []byte) (int, error) {
+	_, _ = recent.Write(p)
+	outMu.RLock()
+	f := logFile
+	outMu.RUnlock()
+	if f != nil {
+		if _, err := f.Write(p); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the log file: %v\n", err)
+		}
+	}
+	return t.console.Write(p)
+}
+
+// syncOnError flushes the log file to disk if it is configured to do so after errors.
+func syncOnError() {
+	outMu.RLock()
+	f := logFile
+	outMu.RUnlock()
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	enabled := f.opts.SyncOnError
+	f.mu.Unlock()
+	if enabled {
+		_ = f.sync()
+	}
+}
+
+// Tail returns up to n of the most recent log lines, from the log file when file logging is enabled and from
+// memory otherwise.
+func Tail(n int) ([]string, error) {
+	outMu.RLock()
+	f := logFile
+	outMu.RUnlock()
+	if f != nil {
+		return f.tail(n)
+	}
+	return recent.tail(n), nil
+}
+
+// Close flushes and closes the log file, if any. Later output only reaches the console and memory.
+func Close() {
+	outMu.Lock()
+	defer outMu.Unlock()
+	if logFile != nil {
+		_ = logFile.sync()
+		_ = logFile.close()
+		logFile = nil
+	}
+}
@@ -0,0 +1,204 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package logging provides leveled, structured loggers with an independent level per module.
+// Output is key=value text or JSON; every record carries the module it came from.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Modules lists the loggers whose level can be set from the configuration or with /setlog.
+var Modules = []string{"player", "handlers", "dl", "db", "cache", "broadcast"}
+
+// levelOff disables a module's output entirely.
+const levelOff = slog.Level(100)
+
+var (
+	mu           sync.RWMutex
+	handler      slog.Handler = newHandler("text", os.Stderr)
+	defaultLevel              = slog.LevelInfo
+	levels                    = make(map[string]*slog.LevelVar)
+	overridden                = make(map[string]bool)
+)
+
+// newHandler builds the output handler for format, which is "json" or "text".
+func newHandler(format string, w io.Writer) slog.Handler {
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// ParseLevel parses debug, info, warn, error or off.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	case "off", "none":
+		return levelOff, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q; use debug, info, warn, error or off", s)
+	}
+}
+
+// levelName returns the name ParseLevel accepts for level.
+func levelName(level slog.Level) string {
+	if level >= levelOff {
+		return "off"
+	}
+	return strings.ToLower(level.String())
+}
+
+// ParseOverrides parses per-module levels written as "dl=debug,broadcast=warn".
+func ParseOverrides(s string) (map[string]slog.Level, error) {
+	out := make(map[string]slog.Level)
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		module, name, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not in the form module=level", part)
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return nil, err
+		}
+		out[strings.ToLower(module)] = level
+	}
+	return out, nil
+}
+
+// levelVar returns the level of module, creating it at the default level. The caller must hold mu.
+func levelVar(module string) *slog.LevelVar {
+	lv, ok := levels[module]
+	if !ok {
+		lv = new(slog.LevelVar)
+		lv.Set(defaultLevel)
+		levels[module] = lv
+	}
+	return lv
+}
+
+// Setup selects the output format and writer, the default level and the per-module levels.
+// Modules not named in overrides follow the default level; levels changed with SetLevel are replaced.
+func Setup(format string, w io.Writer, level slog.Level, overrides map[string]slog.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	handler = newHandler(format, w)
+	defaultLevel = level
+	overridden = make(map[string]bool)
+	for _, module := range Modules {
+		levelVar(module)
+	}
+	for module, lv := range levels {
+		if l, ok := overrides[module]; ok {
+			lv.Set(l)
+			overridden[module] = true
+		} else {
+			lv.Set(level)
+		}
+	}
+}
+
+// SetLevel changes the level of one module at runtime.
+func SetLevel(module string, level slog.Level) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := levels[module]; !ok {
+		return fmt.Errorf("unknown module %q", module)
+	}
+	levels[module].Set(level)
+	overridden[module] = true
+	return nil
+}
+
+// Levels returns the current level name of every module, sorted by module.
+func Levels() [][2]string {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([][2]string, 0, len(levels))
+	for module, lv := range levels {
+		out = append(out, [2]string{module, levelName(lv.Level())})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i][0] < out[j][0] })
+	return out
+}
+
+// Logger writes the records of one module.
+type Logger struct {
+	module string
+	level  *slog.LevelVar
+	attrs  []any
+}
+
+// For returns the logger of module.
+func For(module string) *Logger {
+	mu.Lock()
+	defer mu.Unlock()
+	return &Logger{module: module, level: levelVar(module)}
+}
+
+// With returns a logger that adds the given key-value pairs to every record.
+func (l *Logger) With(kv ...any) *Logger {
+	return &Logger{module: l.module, level: l.level, attrs: append(append([]any(nil), l.attrs...), kv...)}
+}
+
+// Enabled reports whether records at level are written.
+func (l *Logger) Enabled(level slog.Level) bool {
+	return level >= l.level.Level()
+}
+
+func (l *Logger) log(level slog.Level, format string, args []any) {
+	if !l.Enabled(level) || !l.sampled(level, format) {
+		return
+	}
+	l.write(level, format, args)
+}
+
+// write formats and writes a record that has passed the level and sampling checks.
+func (l *Logger) write(level slog.Level, format string, args []any) {
+	msg := format
+	if len(args) > 0 {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	mu.RLock()
+	h := handler
+	mu.RUnlock()
+	slog.New(h).With("module", l.module).With(l.attrs...).Log(context.Background(), level, msg)
+	if level >= slog.LevelError {
+		syncOnError()
+	}
+}
+
+// Debug logs a formatted message at debug level.
+func (l *Logger) Debug(format string, args ...any) { l.log(slog.LevelDebug, format, args) }
+
+// Info logs a formatted message at info level.
+func (l *Logger) Info(format string, args ...any) { l.log(slog.LevelInfo, format, args) }
+
+// Warn logs a formatted message at warn level.
+func (l *Logger) Warn(format string, args ...any) { l.log(slog.LevelWarn, format, args) }
+
+// Error logs a formatted message at error level.
+func (l *Logger) Error(format string, args ...any) { l.log(slog.LevelError, format, args) }
@@ -0,0 +1,59 @@
+//go:build otel
+
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package logging
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartTracing exports every timed operation as an OpenTelemetry span over OTLP/HTTP.
+// The collector is set with the standard OTEL_EXPORTER_OTLP_* environment variables. The returned func flushes
+// pending spans and stops exporting.
+func StartTracing(service string) (func(), error) {
+	exporter, err := otlptracehttp.New(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", service))),
+	)
+	otel.SetTracerProvider(provider)
+
+	tracer := provider.Tracer("ashokshau/tgmusic")
+	SetSpanHook(func(ctx context.Context, op Op) {
+		// Details are left out because they are not redacted.
+		_, span := tracer.Start(ctx, op.Kind+" "+op.Name,
+			trace.WithTimestamp(op.Start),
+			trace.WithAttributes(
+				attribute.String("op.kind", op.Kind),
+				attribute.String("op.module", op.Module),
+				attribute.String("request.id", op.RequestID),
+			),
+		)
+		span.End(trace.WithTimestamp(op.Start.Add(op.Duration)))
+	})
+
+	return func() {
+		SetSpanHook(nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = provider.Shutdown(ctx)
+	}, nil
+}
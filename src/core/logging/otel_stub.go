@@ -0,0 +1,21 @@
+//go:build !otel
+
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package logging
+
+import "errors"
+
+// ErrNoTracing is returned by StartTracing in builds without the otel tag.
+var ErrNoTracing = errors.New("this build has no OpenTelemetry support; rebuild with -tags otel")
+
+// StartTracing is unavailable without the otel build tag.
+func StartTracing(string) (func(), error) {
+	return nil, ErrNoTracing
+}
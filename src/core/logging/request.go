@@ -0,0 +1,55 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// requestKey is the context key of the request ID.
+type requestKey struct{}
+
+// NewRequestID returns a short random ID that ties together the log lines of one request.
+func NewRequestID() string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string if there is none.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestKey{}).(string)
+	return id
+}
+
+// Request returns a logger that tags every record with the request ID id. An empty id returns l unchanged.
+func (l *Logger) Request(id string) *Logger {
+	if id == "" {
+		return l
+	}
+	return l.With("req", id)
+}
+
+// Ctx returns a logger that tags every record with the request ID carried by ctx, if any.
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	return l.Request(RequestID(ctx))
+}
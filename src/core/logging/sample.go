@@ -0,0 +1,76 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package logging
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sampleWindow is how long a message signature's burst lasts before its suppressed count is logged.
+const sampleWindow = time.Minute
+
+// sampleBucket counts the occurrences of one message signature in the current window.
+type sampleBucket struct {
+	seen       int64
+	suppressed int64
+}
+
+var (
+	sampleBurst atomic.Int64
+
+	samplesMu sync.Mutex
+	samples   = make(map[string]*sampleBucket)
+)
+
+// SetSampling lets the first burst occurrences of each debug, info or warn message through per minute.
+// Further occurrences are dropped and summarised in one line when the minute ends. Zero disables sampling.
+// Messages are told apart by module and format string, so the same message about different chats is sampled together.
+func SetSampling(burst int64) {
+	sampleBurst.Store(burst)
+}
+
+// sampled reports whether a record with this level and format should be written, counting it if it is dropped.
+func (l *Logger) sampled(level slog.Level, format string) bool {
+	burst := sampleBurst.Load()
+	if burst <= 0 || level >= slog.LevelError {
+		return true
+	}
+
+	key := l.module + "\x00" + format
+	samplesMu.Lock()
+	defer samplesMu.Unlock()
+	b, ok := samples[key]
+	if !ok {
+		b = &sampleBucket{}
+		samples[key] = b
+		time.AfterFunc(sampleWindow, func() { l.flushSample(key, level, format) })
+	}
+	b.seen++
+	if b.seen <= burst {
+		return true
+	}
+	b.suppressed++
+	return false
+}
+
+// flushSample ends the window of a message signature and logs how many of its occurrences were dropped.
+func (l *Logger) flushSample(key string, level slog.Level, format string) {
+	samplesMu.Lock()
+	b := samples[key]
+	delete(samples, key)
+	samplesMu.Unlock()
+
+	if b != nil && b.suppressed > 0 {
+		l.With("suppressed", b.suppressed).write(level,
+			"Suppressed %d more occurrences in the last %s of: %s", []any{b.suppressed, sampleWindow, format})
+	}
+}
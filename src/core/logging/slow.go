@@ -0,0 +1,109 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package logging
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// slowestKept is how many of the slowest operations since startup are remembered for /slowlog.
+const slowestKept = 20
+
+// maxDetail caps the length of an operation's detail.
+const maxDetail = 300
+
+// Op is a timed operation.
+type Op struct {
+	Kind      string        // Kind groups operations, such as exec, http or db.
+	Name      string        // Name identifies the operation within its kind, such as yt-dlp or find chats.
+	Detail    string        // Detail holds the arguments; it is not redacted.
+	Module    string        // Module is the logger that timed the operation.
+	RequestID string        // RequestID is the request the operation ran for, if any.
+	Start     time.Time     // Start is when the operation began.
+	Duration  time.Duration // Duration is how long it took.
+}
+
+// SpanHook receives every timed operation after it ends, for example to emit it as a tracing span.
+type SpanHook func(ctx context.Context, op Op)
+
+var (
+	slowThreshold atomic.Int64
+	spanHook      atomic.Pointer[SpanHook]
+
+	slowestMu sync.Mutex
+	slowest   []Op
+)
+
+// SetSlowThreshold sets how long an operation may take before it is logged as slow. Zero disables the warnings.
+func SetSlowThreshold(d time.Duration) {
+	slowThreshold.Store(int64(d))
+}
+
+// SetSpanHook installs hook to receive every timed operation. A nil hook removes it.
+func SetSpanHook(hook SpanHook) {
+	if hook == nil {
+		spanHook.Store(nil)
+		return
+	}
+	spanHook.Store(&hook)
+}
+
+// Slowest returns the slowest operations since startup, slowest first.
+func Slowest() []Op {
+	slowestMu.Lock()
+	defer slowestMu.Unlock()
+	return append([]Op(nil), slowest...)
+}
+
+// remember keeps op if it is among the slowest seen so far.
+func remember(op Op) {
+	slowestMu.Lock()
+	defer slowestMu.Unlock()
+	if len(slowest) == slowestKept && op.Duration <= slowest[len(slowest)-1].Duration {
+		return
+	}
+	i := sort.Search(len(slowest), func(i int) bool { return slowest[i].Duration < op.Duration })
+	slowest = append(slowest, Op{})
+	copy(slowest[i+1:], slowest[i:])
+	slowest[i] = op
+	if len(slowest) > slowestKept {
+		slowest = slowest[:slowestKept]
+	}
+}
+
+// Time starts timing an operation and returns the func that ends it, typically deferred.
+func (l *Logger) Time(ctx context.Context, kind, name, detail string) func() {
+	start := time.Now()
+	return func() { l.Observe(ctx, kind, name, detail, start, time.Since(start)) }
+}
+
+// Observe records an operation that started at start and took d. It is logged as a warning if it took longer
+// than the slow threshold, remembered for Slowest and passed to the span hook.
+func (l *Logger) Observe(ctx context.Context, kind, name, detail string, start time.Time, d time.Duration) {
+	if len(detail) > maxDetail {
+		detail = detail[:maxDetail] + "…"
+	}
+	op := Op{
+		Kind: kind, Name: name, Detail: detail, Module: l.module,
+		RequestID: RequestID(ctx), Start: start, Duration: d,
+	}
+	remember(op)
+	if hook := spanHook.Load(); hook != nil {
+		(*hook)(ctx, op)
+	}
+
+	if threshold := time.Duration(slowThreshold.Load()); threshold > 0 && d >= threshold {
+		l.Ctx(ctx).With("kind", kind, "op", name, "duration", d.Round(time.Millisecond)).
+			Warn("Slow %s %s took %s: %s", kind, name, d.Round(time.Millisecond), detail)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package perm works out who sent an update and whether they meet a command's permission requirement.
+package perm
+
+import (
+	"strconv"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// Requirement is the permission a command or button asks of whoever uses it.
+type Requirement int
+
+const (
+	// None lets anyone use the command.
+	None Requirement = iota
+	// Auth allows chat admins and the chat's authorized users.
+	Auth
+	// Admin allows chat admins, including anonymous ones.
+	Admin
+	// Sudo allows the bot's developers (DEVS), which include the owners.
+	Sudo
+	// Owner allows the bot's owners only.
+	Owner
+)
+
+// Kind tells who a message came from, as far as permissions are concerned.
+type Kind int
+
+const (
+	// User is a user posting as themselves.
+	User Kind = iota
+	// AnonymousAdmin is an admin posting as the group itself with "remain anonymous" on.
+	AnonymousAdmin
+	// Channel is a channel, such as a linked channel or a user sending as one of their channels.
+	Channel
+)
+
+// Invoker is the resolved sender of a command. ID is a user ID for users and a chat ID otherwise.
+type Invoker struct {
+	ID   int64
+	Kind Kind
+}
+
+// Denial lang keys returned by Roles.Denial.
+const (
+	DeniedChannel = "perm_channel_sender"
+	DeniedOwner   = "owner_only"
+	DeniedSudo    = "perm_sudo_only"
+	DeniedAdmin   = "filter_not_admin"
+	DeniedAuth    = "filter_not_authorized"
+)
+
+// PeerChatID returns the chat ID of a group or channel peer, or 0 for any other peer.
+func PeerChatID(peer telegram.Peer) int64 {
+	switch p := peer.(type) {
+	case *telegram.PeerChannel:
+		return -1000000000000 - p.ChannelID
+	case *telegram.PeerChat:
+		return -p.ChatID
+	}
+	return 0
+}
+
+// Resolve works out who sent msg. In groups the sender may be the group itself, when an anonymous admin
+// posts, or a channel; neither has a user ID.
+func Resolve(msg *telegram.MessageObj) Invoker {
+	if msg == nil {
+		return Invoker{Kind: Channel}
+	}
+	chatID := PeerChatID(msg.PeerID)
+	if chatID == 0 {
+		if user, ok := msg.PeerID.(*telegram.PeerUser); ok {
+			return Invoker{ID: user.UserID}
+		}
+	}
+
+	switch from := msg.FromID.(type) {
+	case *telegram.PeerUser:
+		return Invoker{ID: from.UserID}
+	case *telegram.PeerChannel, *telegram.PeerChat:
+		fromID := PeerChatID(from)
+		if fromID == chatID {
+			return Invoker{ID: chatID, Kind: AnonymousAdmin}
+		}
+		return Invoker{ID: fromID, Kind: Channel}
+	}
+	// Posts in a channel carry no sender at all.
+	return Invoker{ID: chatID, Kind: Channel}
+}
+
+// Requester returns the user ID a request from inv is attributed to, or 0 if it was not sent by a user.
+// Group and channel IDs are never returned, since they could be mistaken for user IDs.
+func (inv Invoker) Requester() int64 {
+	if inv.Kind == User {
+		return inv.ID
+	}
+	return 0
+}
+
+// Name returns the name a request from inv is attributed to: the first name of user, who sent it, anonymous
+// for an admin posting as the group, or the title of the channel as returned by title.
+func (inv Invoker) Name(user *telegram.UserObj, anonymous string, title func(chatID int64) string) string {
+	switch inv.Kind {
+	case AnonymousAdmin:
+		return anonymous
+	case Channel:
+		return title(inv.ID)
+	}
+	if user != nil {
+		return user.FirstName
+	}
+	return strconv.FormatInt(inv.ID, 10)
+}
+
+// Roles looks up the roles of a user in the chat a permission is checked in. Each lookup is only made
+// when the requirement needs it.
+type Roles struct {
+	Owner func(userID int64) bool
+	Sudo  func(userID int64) bool
+	Admin func(userID int64) bool
+	Auth  func(userID int64) bool
+}
+
+// Denial checks inv against req. It returns an empty string if inv may go ahead, or the lang key explaining
+// why not. Anonymous admins count as admins but never as owners or developers, since who is behind them is
+// unknown; channels pass nothing but None.
+func (r Roles) Denial(inv Invoker, req Requirement) string {
+	if req == None {
+		return ""
+	}
+	if inv.Kind == Channel {
+		return DeniedChannel
+	}
+
+	switch req {
+	case Owner:
+		if inv.Kind == User && r.Owner(inv.ID) {
+			return ""
+		}
+		return DeniedOwner
+	case Sudo:
+		if inv.Kind == User && r.Sudo(inv.ID) {
+			return ""
+		}
+		return DeniedSudo
+	case Admin:
+		if inv.Kind == AnonymousAdmin || r.Admin(inv.ID) {
+			return ""
+		}
+		return DeniedAdmin
+	case Auth:
+		if inv.Kind == AnonymousAdmin || r.Admin(inv.ID) || r.Auth(inv.ID) {
+			return ""
+		}
+		return DeniedAuth
+	}
+	return ""
+}
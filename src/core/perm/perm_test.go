@@ -0,0 +1,171 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package perm
+
+import (
+	"testing"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	group   = int64(-1000000000123) // group is the chat ID of supergroup 123.
+	channel = int64(-1000000000456) // channel is the chat ID of channel 456.
+	user    = int64(42)
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *telegram.MessageObj
+		want Invoker
+	}{
+		{"private chat", &telegram.MessageObj{PeerID: &telegram.PeerUser{UserID: user}}, Invoker{ID: user}},
+		{"user in a group", &telegram.MessageObj{
+			PeerID: &telegram.PeerChannel{ChannelID: 123},
+			FromID: &telegram.PeerUser{UserID: user},
+		}, Invoker{ID: user}},
+		{"user in a basic group", &telegram.MessageObj{
+			PeerID: &telegram.PeerChat{ChatID: 7},
+			FromID: &telegram.PeerUser{UserID: user},
+		}, Invoker{ID: user}},
+		{"anonymous admin", &telegram.MessageObj{
+			PeerID: &telegram.PeerChannel{ChannelID: 123},
+			FromID: &telegram.PeerChannel{ChannelID: 123},
+		}, Invoker{ID: group, Kind: AnonymousAdmin}},
+		{"anonymous admin in a basic group", &telegram.MessageObj{
+			PeerID: &telegram.PeerChat{ChatID: 7},
+			FromID: &telegram.PeerChat{ChatID: 7},
+		}, Invoker{ID: -7, Kind: AnonymousAdmin}},
+		{"sending as a channel", &telegram.MessageObj{
+			PeerID: &telegram.PeerChannel{ChannelID: 123},
+			FromID: &telegram.PeerChannel{ChannelID: 456},
+		}, Invoker{ID: channel, Kind: Channel}},
+		{"channel post", &telegram.MessageObj{PeerID: &telegram.PeerChannel{ChannelID: 456}}, Invoker{ID: channel, Kind: Channel}},
+		{"no message", nil, Invoker{Kind: Channel}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resolve(tt.msg); got != tt.want {
+				t.Fatalf("Resolve = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// set returns a role lookup that holds for ids, and counts how often it is asked.
+func set(calls *int, ids ...int64) func(int64) bool {
+	return func(id int64) bool {
+		*calls++
+		for _, x := range ids {
+			if x == id {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestDenial(t *testing.T) {
+	const (
+		admin  = int64(1)
+		authed = int64(2)
+		owner  = int64(3)
+		dev    = int64(4)
+	)
+	anon := Invoker{ID: group, Kind: AnonymousAdmin}
+	ch := Invoker{ID: channel, Kind: Channel}
+
+	tests := []struct {
+		name string
+		inv  Invoker
+		req  Requirement
+		want string
+	}{
+		{"anyone", Invoker{ID: user}, None, ""},
+		{"channel with no requirement", ch, None, ""},
+		{"admin", Invoker{ID: admin}, Admin, ""},
+		{"admin is authorized", Invoker{ID: admin}, Auth, ""},
+		{"authorized user", Invoker{ID: authed}, Auth, ""},
+		{"authorized user is not an admin", Invoker{ID: authed}, Admin, DeniedAdmin},
+		{"plain user", Invoker{ID: user}, Auth, DeniedAuth},
+		{"owner", Invoker{ID: owner}, Owner, ""},
+		{"developer", Invoker{ID: dev}, Sudo, ""},
+		{"developer is not an owner", Invoker{ID: dev}, Owner, DeniedOwner},
+
+		// Anonymous admins post as the group, so their ID is the group's and never an admin's.
+		{"anonymous admin", anon, Admin, ""},
+		{"anonymous admin is authorized", anon, Auth, ""},
+		{"anonymous admin is not a developer", anon, Sudo, DeniedSudo},
+		{"anonymous admin is not an owner", anon, Owner, DeniedOwner},
+
+		// A channel's ID could collide with the roles, so it is refused before they are consulted.
+		{"channel as admin", ch, Admin, DeniedChannel},
+		{"channel as authorized", ch, Auth, DeniedChannel},
+		{"channel as developer", ch, Sudo, DeniedChannel},
+		{"channel as owner", ch, Owner, DeniedChannel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			// The group and the channel hold every role, so only the sender kind can deny them.
+			roles := Roles{
+				Owner: set(&calls, owner, group, channel),
+				Sudo:  set(&calls, owner, dev, group, channel),
+				Admin: set(&calls, admin, channel),
+				Auth:  set(&calls, authed, group, channel),
+			}
+			if got := roles.Denial(tt.inv, tt.req); got != tt.want {
+				t.Fatalf("Denial = %q, want %q", got, tt.want)
+			}
+			if tt.inv.Kind != User && calls != 0 {
+				t.Fatalf("looked up %d roles for a %v sender", calls, tt.inv.Kind)
+			}
+		})
+	}
+}
+
+func TestAttribution(t *testing.T) {
+	titles := map[int64]string{group: "The Group", channel: "The Channel"}
+	title := func(chatID int64) string { return titles[chatID] }
+
+	// Updates as gogram delivers them: m.Sender is nil unless a user sent the message.
+	tests := []struct {
+		name      string
+		m         *telegram.NewMessage
+		requester int64
+		sender    string
+	}{
+		{"user", &telegram.NewMessage{
+			Message: &telegram.MessageObj{PeerID: &telegram.PeerChannel{ChannelID: 123}, FromID: &telegram.PeerUser{UserID: user}},
+			Sender:  &telegram.UserObj{ID: user, FirstName: "Asha"},
+		}, user, "Asha"},
+		{"anonymous admin", &telegram.NewMessage{
+			Message: &telegram.MessageObj{PeerID: &telegram.PeerChannel{ChannelID: 123}, FromID: &telegram.PeerChannel{ChannelID: 123}},
+		}, 0, "Anonymous admin"},
+		{"linked channel", &telegram.NewMessage{
+			Message: &telegram.MessageObj{PeerID: &telegram.PeerChannel{ChannelID: 123}, FromID: &telegram.PeerChannel{ChannelID: 456}},
+		}, 0, "The Channel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := Resolve(tt.m.Message)
+			if got := inv.Requester(); got != tt.requester {
+				t.Errorf("Requester = %d, want %d", got, tt.requester)
+			}
+			if got := inv.Name(tt.m.Sender, "Anonymous admin", title); got != tt.sender {
+				t.Errorf("Name = %q, want %q", got, tt.sender)
+			}
+			// A channel's raw ID is what m.SenderID reports; it must never pass for a requester.
+			if tt.m.Sender == nil && inv.Requester() == tt.m.SenderID() {
+				t.Errorf("Requester = %d, the sender's raw peer ID", inv.Requester())
+			}
+		})
+	}
+}
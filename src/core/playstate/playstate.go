@@ -0,0 +1,209 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package playstate is the per-chat playback state machine. Handlers consult it to answer accurately
+// ("already paused", "still connecting") instead of guessing from the queue.
+package playstate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/logging"
+)
+
+// logger logs under the player module, which the state machine is part of.
+var logger = logging.For("player")
+
+// State is the playback state of a single chat.
+type State int
+
+const (
+	Idle State = iota
+	Joining
+	Playing
+	Paused
+	Transitioning
+	Errored
+)
+
+// String returns the state's name for logs.
+func (s State) String() string {
+	switch s {
+	case Idle:
+		return "idle"
+	case Joining:
+		return "joining"
+	case Playing:
+		return "playing"
+	case Paused:
+		return "paused"
+	case Transitioning:
+		return "transitioning"
+	case Errored:
+		return "errored"
+	default:
+		return fmt.Sprintf("state(%d)", int(s))
+	}
+}
+
+var (
+	// ErrInvalidTransition is returned when a state change is not allowed from the current state.
+	ErrInvalidTransition = errors.New("invalid playback state transition")
+	// ErrNotPlaying is returned when an action needs a running stream but the chat is idle or errored.
+	ErrNotPlaying = errors.New("nothing is playing")
+	// ErrAlreadyPaused is returned when pausing a chat that is already paused.
+	ErrAlreadyPaused = errors.New("playback is already paused")
+	// ErrNotPaused is returned when resuming a chat that is not paused.
+	ErrNotPaused = errors.New("playback is not paused")
+	// ErrBusy is returned while the chat is joining or switching tracks.
+	ErrBusy = errors.New("playback is still connecting")
+)
+
+// validTransitions lists the states reachable from each state. Any state may always return to idle.
+var validTransitions = map[State][]State{
+	Idle:          {Joining, Transitioning},
+	Joining:       {Playing, Errored},
+	Playing:       {Paused, Transitioning, Joining, Errored},
+	Paused:        {Playing, Transitioning, Joining, Errored},
+	Transitioning: {Joining, Errored},
+	Errored:       {Joining, Transitioning},
+}
+
+// CanTransition reports whether the state machine allows moving from one state to another.
+func CanTransition(from, to State) bool {
+	return to == Idle || slices.Contains(validTransitions[from], to)
+}
+
+// chatState is the state machine of one chat.
+type chatState struct {
+	state     State
+	lastError error
+	since     time.Time
+}
+
+// Machine holds the state machines of all chats. Chats without an entry are idle.
+type Machine struct {
+	mu     sync.Mutex
+	states map[int64]*chatState
+	// OnTransition, if set, is called under the machine's lock after every accepted state change.
+	OnTransition func(chatID int64, from, to State)
+}
+
+// New returns a machine in which every chat is idle.
+func New() *Machine {
+	return &Machine{states: make(map[int64]*chatState)}
+}
+
+// Get returns the chat's state and the error that put it into Errored, if any.
+func (m *Machine) Get(chatID int64) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.states[chatID]
+	if !ok {
+		return Idle, nil
+	}
+	return st.state, st.lastError
+}
+
+// Set moves the chat to a new state, rejecting transitions the state machine does not allow.
+// cause is recorded as the chat's last error when moving to Errored.
+func (m *Machine) Set(chatID int64, to State, cause error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.set(chatID, to, cause)
+}
+
+// set is Set with m.mu held.
+func (m *Machine) set(chatID int64, to State, cause error) error {
+	from := Idle
+	if st, ok := m.states[chatID]; ok {
+		from = st.state
+	}
+
+	if from == to {
+		return nil
+	}
+	if !CanTransition(from, to) {
+		logger.Warn("[state] Rejected transition in %d: %s -> %s", chatID, from, to)
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+
+	logger.Info("[state] Chat %d: %s -> %s", chatID, from, to)
+	if m.OnTransition != nil {
+		m.OnTransition(chatID, from, to)
+	}
+	if to == Idle {
+		delete(m.states, chatID)
+		return nil
+	}
+
+	st := &chatState{state: to, since: time.Now()}
+	if to == Errored {
+		st.lastError = cause
+	}
+	m.states[chatID] = st
+	return nil
+}
+
+// Settle ends an operation that moved the chat to from, such as switching tracks, if the chat is still in
+// from when it returns: to Errored with cause if the operation failed, and to Idle otherwise. A chat that
+// the operation already moved on is left alone, so no early return can leave it busy forever.
+func (m *Machine) Settle(chatID int64, from State, cause error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.states[chatID]
+	if !ok || st.state != from {
+		return
+	}
+	if cause != nil {
+		_ = m.set(chatID, Errored, cause)
+		return
+	}
+	_ = m.set(chatID, Idle, nil)
+}
+
+// CheckBusy returns ErrBusy while the chat is joining or switching tracks.
+func (m *Machine) CheckBusy(chatID int64) error {
+	state, _ := m.Get(chatID)
+	if state == Joining || state == Transitioning {
+		return ErrBusy
+	}
+	return nil
+}
+
+// CheckPause returns why the chat's state does not allow pausing, or nil if it does.
+func (m *Machine) CheckPause(chatID int64) error {
+	switch state, _ := m.Get(chatID); state {
+	case Paused:
+		return ErrAlreadyPaused
+	case Joining, Transitioning:
+		return ErrBusy
+	case Idle, Errored:
+		return ErrNotPlaying
+	}
+	return nil
+}
+
+// CheckResume returns why the chat's state does not allow resuming, or nil if it does.
+func (m *Machine) CheckResume(chatID int64) error {
+	switch state, _ := m.Get(chatID); state {
+	case Playing:
+		return ErrNotPaused
+	case Joining, Transitioning:
+		return ErrBusy
+	case Idle, Errored:
+		return ErrNotPlaying
+	}
+	return nil
+}
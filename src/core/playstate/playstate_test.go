@@ -0,0 +1,140 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package playstate
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIllegalSequencesAreRejected(t *testing.T) {
+	tests := []struct {
+		name string
+		path []State
+		bad  State
+	}{
+		{"pause while idle", nil, Paused},
+		{"play without joining", nil, Playing},
+		{"pause while joining", []State{Joining}, Paused},
+		{"pause during transition", []State{Transitioning}, Paused},
+		{"play during transition", []State{Transitioning}, Playing},
+		{"resume while errored", []State{Joining, Errored}, Playing},
+		{"pause while errored", []State{Joining, Errored}, Paused},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			for _, s := range tt.path {
+				if err := m.Set(1, s, nil); err != nil {
+					t.Fatalf("Set(%s) = %v", s, err)
+				}
+			}
+			before, _ := m.Get(1)
+			if err := m.Set(1, tt.bad, nil); !errors.Is(err, ErrInvalidTransition) {
+				t.Fatalf("Set(%s) = %v, want ErrInvalidTransition", tt.bad, err)
+			}
+			if after, _ := m.Get(1); after != before {
+				t.Fatalf("rejected transition changed the state from %s to %s", before, after)
+			}
+		})
+	}
+}
+
+func TestActionChecks(t *testing.T) {
+	tests := []struct {
+		path       []State
+		pause      error
+		resume     error
+		busy       error
+		wantsState State
+	}{
+		{nil, ErrNotPlaying, ErrNotPlaying, nil, Idle},
+		{[]State{Joining}, ErrBusy, ErrBusy, ErrBusy, Joining},
+		{[]State{Joining, Playing}, nil, ErrNotPaused, nil, Playing},
+		{[]State{Joining, Playing, Paused}, ErrAlreadyPaused, nil, nil, Paused},
+		{[]State{Joining, Playing, Transitioning}, ErrBusy, ErrBusy, ErrBusy, Transitioning},
+		{[]State{Joining, Errored}, ErrNotPlaying, ErrNotPlaying, nil, Errored},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantsState.String(), func(t *testing.T) {
+			m := New()
+			for _, s := range tt.path {
+				if err := m.Set(1, s, nil); err != nil {
+					t.Fatalf("Set(%s) = %v", s, err)
+				}
+			}
+			if got, _ := m.Get(1); got != tt.wantsState {
+				t.Fatalf("state = %s, want %s", got, tt.wantsState)
+			}
+			if err := m.CheckPause(1); !errors.Is(err, tt.pause) {
+				t.Errorf("CheckPause = %v, want %v", err, tt.pause)
+			}
+			if err := m.CheckResume(1); !errors.Is(err, tt.resume) {
+				t.Errorf("CheckResume = %v, want %v", err, tt.resume)
+			}
+			if err := m.CheckBusy(1); !errors.Is(err, tt.busy) {
+				t.Errorf("CheckBusy = %v, want %v", err, tt.busy)
+			}
+		})
+	}
+}
+
+func TestSettleLeavesNoChatBusy(t *testing.T) {
+	failed := errors.New("send failed")
+
+	// A track switch that returns early without starting a stream.
+	m := New()
+	_ = m.Set(1, Transitioning, nil)
+	m.Settle(1, Transitioning, failed)
+	state, lastErr := m.Get(1)
+	if state != Errored || !errors.Is(lastErr, failed) {
+		t.Fatalf("after a failed switch: %s, %v; want errored with the cause", state, lastErr)
+	}
+	if err := m.CheckPause(1); errors.Is(err, ErrBusy) {
+		t.Fatal("pause still answers busy after a failed switch")
+	}
+
+	// A track switch that ran out of tracks without an error.
+	_ = m.Set(2, Transitioning, nil)
+	m.Settle(2, Transitioning, nil)
+	if state, _ := m.Get(2); state != Idle {
+		t.Fatalf("after an empty switch: %s, want idle", state)
+	}
+
+	// A track switch that started the next track is left alone.
+	_ = m.Set(3, Transitioning, nil)
+	_ = m.Set(3, Joining, nil)
+	_ = m.Set(3, Playing, nil)
+	m.Settle(3, Transitioning, failed)
+	if state, _ := m.Get(3); state != Playing {
+		t.Fatalf("Settle moved a playing chat to %s", state)
+	}
+}
+
+func TestOnTransition(t *testing.T) {
+	m := New()
+	var seen []State
+	m.OnTransition = func(_ int64, _, to State) { seen = append(seen, to) }
+
+	_ = m.Set(1, Joining, nil)
+	_ = m.Set(1, Joining, nil)
+	_ = m.Set(1, Paused, nil)
+	_ = m.Set(1, Playing, nil)
+	_ = m.Set(1, Idle, nil)
+
+	want := []State{Joining, Playing, Idle}
+	if len(seen) != len(want) {
+		t.Fatalf("transitions = %v, want %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("transitions = %v, want %v", seen, want)
+		}
+	}
+}
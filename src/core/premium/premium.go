@@ -0,0 +1,61 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package premium decides what each user is entitled to. The places that enforce a limit ask For instead of
+// reading the configured limit, so that premium users get theirs and everyone else the free ones.
+package premium
+
+import (
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+)
+
+// Limits are what a user may use.
+type Limits struct {
+	Premium      bool
+	Until        time.Time // Until is when the subscription ends; zero for free users.
+	SongDuration int       // SongDuration is the longest song the user may play, in seconds.
+	VideoHeight  int       // VideoHeight is the highest video resolution streamed for the user's tracks.
+	Playlists    int       // Playlists is how many playlists the user may own.
+}
+
+// Free returns the limits of users without a subscription.
+func Free() Limits {
+	cfg := config.Get()
+	return Limits{
+		SongDuration: int(cfg.SongDurationLimit),
+		VideoHeight:  int(cfg.VideoHeight),
+		Playlists:    int(cfg.MaxPlaylists),
+	}
+}
+
+// For returns the limits of userID. A lapsed subscription falls back to the free limits, and no premium limit
+// is ever lower than the free one, so lowering the free limits cannot leave premium users worse off.
+func For(userID int64) Limits {
+	free := Free()
+	if userID == 0 {
+		return free
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	until := db.Instance.PremiumUntil(ctx, userID)
+	if !until.After(time.Now()) {
+		return free
+	}
+
+	p := config.Get().Premium
+	return Limits{
+		Premium:      true,
+		Until:        until,
+		SongDuration: max(free.SongDuration, int(p.SongDuration)),
+		VideoHeight:  max(free.VideoHeight, int(p.VideoHeight)),
+		Playlists:    max(free.Playlists, int(p.Playlists)),
+	}
+}
@@ -0,0 +1,123 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package quiethours parses a chat's quiet hours and tells whether a moment falls within them.
+package quiethours
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// The zone database is embedded so that zones resolve on hosts without one.
+	_ "time/tzdata"
+)
+
+// ErrInvalid is returned for quiet hours that cannot be parsed or that start and end at the same time.
+var ErrInvalid = errors.New("invalid quiet hours")
+
+// Window is a daily window from Start to End, in minutes after midnight in Loc. A window whose end is before
+// its start wraps past midnight.
+type Window struct {
+	Start int
+	End   int
+	Loc   *time.Location
+}
+
+// ParseClock parses "HH:MM" into minutes after midnight.
+func ParseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(s, ":")
+	if !ok || len(mm) != 2 {
+		return 0, ErrInvalid
+	}
+	h, err := strconv.Atoi(hh)
+	if err != nil || h < 0 || h > 23 {
+		return 0, ErrInvalid
+	}
+	m, err := strconv.Atoi(mm)
+	if err != nil || m < 0 || m > 59 {
+		return 0, ErrInvalid
+	}
+	return h*60 + m, nil
+}
+
+// FormatClock formats minutes after midnight as "HH:MM".
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// Location returns the time zone named tz, falling back to UTC for an empty or unknown name.
+func Location(tz string) *time.Location {
+	if tz == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Parse parses "HH:MM-HH:MM [time zone]". The zone defaults to UTC.
+func Parse(args string) (Window, error) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Window{}, ErrInvalid
+	}
+	from, to, ok := strings.Cut(fields[0], "-")
+	if !ok {
+		return Window{}, ErrInvalid
+	}
+	start, err := ParseClock(from)
+	if err != nil {
+		return Window{}, err
+	}
+	end, err := ParseClock(to)
+	if err != nil {
+		return Window{}, err
+	}
+	if start == end {
+		return Window{}, ErrInvalid
+	}
+
+	w := Window{Start: start, End: end, Loc: time.UTC}
+	if len(fields) == 2 {
+		if w.Loc, err = time.LoadLocation(fields[1]); err != nil {
+			return Window{}, err
+		}
+	}
+	return w, nil
+}
+
+// Until reports whether now falls within w, and if so when w ends. A window that starts and ends at the same
+// time is empty.
+func (w Window) Until(now time.Time) (time.Time, bool) {
+	if w.Start == w.End {
+		return time.Time{}, false
+	}
+	loc := w.Loc
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	quiet := minute >= w.Start && minute < w.End
+	if w.End < w.Start {
+		quiet = minute >= w.Start || minute < w.End
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), w.End/60, w.End%60, 0, 0, loc)
+	if !until.After(local) {
+		until = time.Date(local.Year(), local.Month(), local.Day()+1, w.End/60, w.End%60, 0, 0, loc)
+	}
+	return until, true
+}
@@ -0,0 +1,121 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package quiethours
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		args       string
+		start, end int
+		zone       string
+		wantErr    bool
+	}{
+		{"01:00-07:00", 60, 420, "UTC", false},
+		{"23:30-06:15 Asia/Kolkata", 1410, 375, "Asia/Kolkata", false},
+		{"9:05-10:00", 545, 600, "UTC", false},
+		{"00:00-23:59", 0, 1439, "UTC", false},
+		{"01:00-01:00", 0, 0, "", true},
+		{"1:00-01:00", 0, 0, "", true},
+		{"00:00-0:00", 0, 0, "", true},
+		{"24:00-07:00", 0, 0, "", true},
+		{"01:60-07:00", 0, 0, "", true},
+		{"01:0-07:00", 0, 0, "", true},
+		{"01:00", 0, 0, "", true},
+		{"01:00-07:00 Mars/Olympus", 0, 0, "", true},
+		{"01:00-07:00 UTC extra", 0, 0, "", true},
+		{"", 0, 0, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			w, err := Parse(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse = %+v, want an error", w)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse = %v", err)
+			}
+			if w.Start != tt.start || w.End != tt.end || w.Loc.String() != tt.zone {
+				t.Fatalf("Parse = %d-%d %s, want %d-%d %s", w.Start, w.End, w.Loc, tt.start, tt.end, tt.zone)
+			}
+		})
+	}
+	if _, err := Parse("1:00-01:00"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("an empty window: err = %v, want ErrInvalid", err)
+	}
+}
+
+func TestUntil(t *testing.T) {
+	day := func(h, m int) time.Time { return time.Date(2025, 3, 10, h, m, 0, 0, time.UTC) }
+	next := func(h, m int) time.Time { return day(h, m).AddDate(0, 0, 1) }
+
+	tests := []struct {
+		name   string
+		window string
+		now    time.Time
+		quiet  bool
+		until  time.Time
+	}{
+		{"before a plain window", "01:00-07:00", day(0, 59), false, time.Time{}},
+		{"start of a plain window", "01:00-07:00", day(1, 0), true, day(7, 0)},
+		{"inside a plain window", "01:00-07:00", day(6, 59), true, day(7, 0)},
+		{"end of a plain window", "01:00-07:00", day(7, 0), false, time.Time{}},
+		{"before a wrapping window", "23:00-06:00", day(22, 59), false, time.Time{}},
+		{"start of a wrapping window", "23:00-06:00", day(23, 0), true, next(6, 0)},
+		{"wrapping window at midnight", "23:00-06:00", day(0, 0), true, day(6, 0)},
+		{"wrapping window after midnight", "23:00-06:00", day(5, 59), true, day(6, 0)},
+		{"end of a wrapping window", "23:00-06:00", day(6, 0), false, time.Time{}},
+		{"window ending at midnight", "22:00-00:00", day(23, 30), true, next(0, 0)},
+		{"window starting at midnight", "00:00-01:00", day(0, 0), true, day(1, 0)},
+		{"outside in the middle of the day", "23:00-06:00", day(12, 0), false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := Parse(tt.window)
+			if err != nil {
+				t.Fatalf("Parse(%q) = %v", tt.window, err)
+			}
+			until, quiet := w.Until(tt.now)
+			if quiet != tt.quiet || !until.Equal(tt.until) {
+				t.Fatalf("Until(%s) = %s, %t; want %s, %t", tt.now.Format("15:04"), until, quiet, tt.until, tt.quiet)
+			}
+		})
+	}
+}
+
+func TestUntilInZone(t *testing.T) {
+	w, err := Parse("23:00-06:00 Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 18:00 UTC is 23:30 in Kolkata, inside the window, which ends at 06:00 there, 00:30 UTC.
+	until, quiet := w.Until(time.Date(2025, 3, 10, 18, 0, 0, 0, time.UTC))
+	want := time.Date(2025, 3, 11, 0, 30, 0, 0, time.UTC)
+	if !quiet || !until.Equal(want) {
+		t.Fatalf("Until = %s, %t; want %s in the window", until, quiet, want)
+	}
+	// 02:00 UTC is 07:30 in Kolkata, after the window.
+	if _, quiet := w.Until(time.Date(2025, 3, 10, 2, 0, 0, 0, time.UTC)); quiet {
+		t.Fatal("Until reported 07:30 local time as quiet")
+	}
+}
+
+func TestEmptyWindowIsNeverQuiet(t *testing.T) {
+	w := Window{Start: 60, End: 60}
+	if _, quiet := w.Until(time.Date(2025, 3, 10, 1, 0, 0, 0, time.UTC)); quiet {
+		t.Fatal("a window that starts and ends at the same time was quiet")
+	}
+}
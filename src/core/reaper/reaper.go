@@ -0,0 +1,171 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package reaper tears down per-chat state that has been idle for too long, so that chats which went quiet
+// do not keep their queues, locks and cache entries for the lifetime of the process.
+package reaper
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/core/shutdown"
+)
+
+// sweepInterval is how often the default reaper looks for idle state.
+const sweepInterval = 10 * time.Minute
+
+var logger = logging.For("reaper")
+
+// Pool is a kind of per-chat state the reaper looks after. Idle and Drop reap entries that stay idle for the
+// whole threshold; Purge removes entries that expire on their own, such as TTL cache items. Either may be nil.
+type Pool struct {
+	Name  string
+	Len   func() int              // Len returns how many entries are alive.
+	Idle  func() []int64          // Idle returns the chats whose entry is not in use right now.
+	Drop  func(chatID int64) bool // Drop tears the entry down, unless it came back into use; it reports whether it did.
+	Purge func(now time.Time) int // Purge removes the entries that have expired by now and returns how many.
+}
+
+// Size is the number of live entries in a pool.
+type Size struct {
+	Name string
+	Live int
+}
+
+type pool struct {
+	Pool
+	idleSince map[int64]time.Time
+}
+
+// Reaper sweeps a set of pools.
+type Reaper struct {
+	mu     sync.Mutex
+	pools  []*pool
+	reaped atomic.Int64
+}
+
+// New returns a reaper without any pools.
+func New() *Reaper {
+	return &Reaper{}
+}
+
+// Register adds a pool to the reaper.
+func (r *Reaper) Register(p Pool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pools = append(r.pools, &pool{Pool: p, idleSince: make(map[int64]time.Time)})
+}
+
+// Sweep reaps every entry that has been idle for at least idleAfter as of now, and purges expired ones.
+// An entry's idle time starts at the first sweep that finds it idle, and is reset by any sweep that finds it in use.
+// It returns how many entries were removed.
+func (r *Reaper) Sweep(now time.Time, idleAfter time.Duration) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	total := 0
+	for _, p := range r.pools {
+		n := 0
+		if p.Purge != nil {
+			n += p.Purge(now)
+		}
+		if p.Idle != nil && p.Drop != nil {
+			n += p.sweep(now, idleAfter)
+		}
+		if n > 0 {
+			logger.Info("Reaped %d idle %s.", n, p.Name)
+		}
+		total += n
+	}
+	r.reaped.Add(int64(total))
+	return total
+}
+
+// sweep reaps the pool's entries that have been idle for at least idleAfter.
+func (p *pool) sweep(now time.Time, idleAfter time.Duration) int {
+	idle := make(map[int64]struct{})
+	for _, chatID := range p.Idle() {
+		idle[chatID] = struct{}{}
+	}
+	for chatID := range p.idleSince {
+		if _, ok := idle[chatID]; !ok {
+			delete(p.idleSince, chatID)
+		}
+	}
+
+	n := 0
+	for chatID := range idle {
+		since, ok := p.idleSince[chatID]
+		if !ok {
+			p.idleSince[chatID] = now
+			continue
+		}
+		if now.Sub(since) < idleAfter {
+			continue
+		}
+		delete(p.idleSince, chatID)
+		if p.Drop(chatID) {
+			n++
+		}
+	}
+	return n
+}
+
+// Sizes returns the number of live entries in every pool, ordered by name.
+func (r *Reaper) Sizes() []Size {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sizes := make([]Size, 0, len(r.pools))
+	for _, p := range r.pools {
+		if p.Len != nil {
+			sizes = append(sizes, Size{Name: p.Name, Live: p.Len()})
+		}
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i].Name < sizes[j].Name })
+	return sizes
+}
+
+// Reaped returns how many entries the reaper has removed since it was created.
+func (r *Reaper) Reaped() int64 {
+	return r.reaped.Load()
+}
+
+// Default is the reaper the bot's packages register their per-chat state with.
+var Default = New()
+
+var startOnce sync.Once
+
+// Register adds a pool to the default reaper.
+func Register(p Pool) {
+	Default.Register(p)
+}
+
+// Start sweeps the default reaper every sweepInterval, reaping state idle for REAP_IDLE_AFTER hours.
+// The threshold is read on every sweep, so reloading the configuration changes or disables it.
+func Start() {
+	startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(sweepInterval)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				if shutdown.Stopping() {
+					return
+				}
+				if hours := config.Get().ReapIdleAfter; hours > 0 {
+					Default.Sweep(now, time.Duration(hours)*time.Hour)
+				}
+			}
+		}()
+	})
+}
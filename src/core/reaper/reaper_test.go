@@ -0,0 +1,137 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package reaper
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// sessions is a pool of fake per-chat sessions, some of which are in use.
+type sessions struct {
+	mu    sync.Mutex
+	live  map[int64]bool // live maps each session to whether it is in use.
+	stuck map[int64]bool // stuck sessions refuse to be dropped, as if they came back into use.
+}
+
+func newSessions(n int) *sessions {
+	s := &sessions{live: make(map[int64]bool), stuck: make(map[int64]bool)}
+	for i := range n {
+		s.live[int64(i)] = false
+	}
+	return s
+}
+
+func (s *sessions) pool() Pool {
+	return Pool{
+		Name: "sessions",
+		Len: func() int {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return len(s.live)
+		},
+		Idle: func() []int64 {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			var idle []int64
+			for id, busy := range s.live {
+				if !busy {
+					idle = append(idle, id)
+				}
+			}
+			return idle
+		},
+		Drop: func(id int64) bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.live[id] || s.stuck[id] {
+				return false
+			}
+			delete(s.live, id)
+			return true
+		},
+	}
+}
+
+func (s *sessions) use(id int64, busy bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.live[id] = busy
+}
+
+func TestSweepReapsIdleSessions(t *testing.T) {
+	const idleAfter = time.Hour
+	s := newSessions(1000)
+	for id := range int64(100) {
+		s.use(id, true)
+	}
+	r := New()
+	r.Register(s.pool())
+
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if n := r.Sweep(clock, idleAfter); n != 0 {
+		t.Fatalf("first sweep reaped %d sessions; idle time starts when a sweep first sees them", n)
+	}
+
+	clock = clock.Add(30 * time.Minute)
+	if n := r.Sweep(clock, idleAfter); n != 0 {
+		t.Fatalf("reaped %d sessions idle for half the threshold", n)
+	}
+
+	// Session 500 is used and released again; its idle time starts over.
+	s.use(500, true)
+	clock = clock.Add(10 * time.Minute)
+	r.Sweep(clock, idleAfter)
+	s.use(500, false)
+
+	clock = clock.Add(25 * time.Minute)
+	if n := r.Sweep(clock, idleAfter); n != 899 {
+		t.Fatalf("reaped %d sessions, want the 899 idle for an hour", n)
+	}
+	if got := r.Sizes()[0].Live; got != 101 {
+		t.Fatalf("%d sessions live, want the 100 in use and the one used recently", got)
+	}
+
+	clock = clock.Add(2 * time.Hour)
+	r.Sweep(clock, idleAfter)
+	if got := r.Sizes()[0].Live; got != 100 {
+		t.Fatalf("%d sessions live, want only the 100 in use", got)
+	}
+	if r.Reaped() != 900 {
+		t.Fatalf("Reaped = %d, want 900", r.Reaped())
+	}
+}
+
+func TestSweepKeepsSessionsThatComeBack(t *testing.T) {
+	s := newSessions(3)
+	s.stuck[1] = true
+	r := New()
+	r.Register(s.pool())
+
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.Sweep(clock, time.Minute)
+	if n := r.Sweep(clock.Add(time.Hour), time.Minute); n != 2 {
+		t.Fatalf("reaped %d sessions, want 2", n)
+	}
+	if got := r.Sizes()[0].Live; got != 1 {
+		t.Fatalf("%d sessions live, want the one whose Drop refused", got)
+	}
+}
+
+func TestSweepPurges(t *testing.T) {
+	var purgedAt time.Time
+	r := New()
+	r.Register(Pool{Name: "cache", Purge: func(now time.Time) int { purgedAt = now; return 7 }})
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if n := r.Sweep(now, time.Hour); n != 7 || !purgedAt.Equal(now) {
+		t.Fatalf("Sweep = %d, purged at %v; want 7 at %v", n, purgedAt, now)
+	}
+}
@@ -0,0 +1,239 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package selftest probes the external dependencies the bot needs: the yt-dlp and ffmpeg binaries, the
+// downloads API, the downloads directory and, through checks supplied by the caller, the Telegram sessions.
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+)
+
+// Minimum versions of the external binaries. Older yt-dlp releases can no longer download from YouTube.
+const (
+	minYtDlp  = "2025.01.15"
+	minFFmpeg = "4.4"
+)
+
+// checkTimeout bounds each check so that one unreachable dependency cannot stall the rest.
+const checkTimeout = 20 * time.Second
+
+// Status is the outcome of a check.
+type Status int
+
+const (
+	OK   Status = iota // OK means the dependency works.
+	Warn               // Warn means the bot can run, but some features may not work.
+	Fail               // Fail means the bot cannot play music until the problem is fixed.
+)
+
+// String returns the label shown in the results table.
+func (s Status) String() string {
+	switch s {
+	case OK:
+		return "OK"
+	case Warn:
+		return "WARN"
+	default:
+		return "FAIL"
+	}
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+}
+
+// Check probes one dependency.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (Status, string)
+}
+
+// Builtin returns the checks that need nothing from the caller.
+func Builtin() []Check {
+	return []Check{
+		{Name: "yt-dlp", Run: checkYtDlp},
+		{Name: "ffmpeg", Run: func(ctx context.Context) (Status, string) { return checkFFmpeg(ctx, "ffmpeg") }},
+		{Name: "ffprobe", Run: func(ctx context.Context) (Status, string) { return checkFFmpeg(ctx, "ffprobe") }},
+		{Name: "downloads API", Run: checkAPI},
+		{Name: "downloads dir", Run: checkDownloadsDir},
+	}
+}
+
+// Run runs the checks concurrently and returns their results in the order given.
+func Run(ctx context.Context, checks []Check) []Result {
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			status, detail := check.Run(cctx)
+			if status != Fail && cctx.Err() != nil {
+				status, detail = Fail, "timed out after "+checkTimeout.String()
+			}
+			results[i] = Result{Name: check.Name, Status: status, Detail: detail}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// Failed reports whether any result is a hard failure.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.Status == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Table formats the results as a plain-text table.
+func Table(results []Result) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, r := range results {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", r.Status, r.Name, r.Detail)
+	}
+	_ = w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// output runs a binary and returns the first line of what it printed.
+func output(ctx context.Context, name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s was not found in PATH", name)
+	}
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %v", name, err)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// checkYtDlp checks that yt-dlp is installed and at least minYtDlp.
+func checkYtDlp(ctx context.Context) (Status, string) {
+	version, err := output(ctx, "yt-dlp", "--version")
+	if err != nil {
+		return Fail, err.Error()
+	}
+	if compareVersions(version, minYtDlp) < 0 {
+		return Fail, fmt.Sprintf("%s is older than %s; run yt-dlp -U", version, minYtDlp)
+	}
+	return OK, version
+}
+
+// ffmpegVersion matches the release number in the first line of "ffmpeg -version", such as
+// "ffmpeg version 6.1.1-3ubuntu5" or "ffmpeg version n7.0".
+var ffmpegVersion = regexp.MustCompile(`version n?(\d+(?:\.\d+)*)`)
+
+// checkFFmpeg checks that ffmpeg or ffprobe is installed and at least minFFmpeg. Builds from git
+// ("version N-...") carry no release number and are assumed to be recent.
+func checkFFmpeg(ctx context.Context, name string) (Status, string) {
+	line, err := output(ctx, name, "-version")
+	if err != nil {
+		return Fail, err.Error()
+	}
+	if strings.Contains(line, "version N-") {
+		return OK, "git build"
+	}
+	m := ffmpegVersion.FindStringSubmatch(line)
+	if m == nil {
+		return Warn, "could not read the version from: " + line
+	}
+	if compareVersions(m[1], minFFmpeg) < 0 {
+		return Fail, fmt.Sprintf("%s is older than %s", m[1], minFFmpeg)
+	}
+	return OK, m[1]
+}
+
+// compareVersions compares dotted version numbers part by part, returning -1, 0 or 1.
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			y, _ = strconv.Atoi(pb[i])
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// checkAPI checks that the downloads API answers and accepts the key. Without the API the bot falls back to
+// yt-dlp, so problems here are only warnings.
+func checkAPI(ctx context.Context) (Status, string) {
+	cfg := config.Get()
+	if cfg.ApiUrl == "" || cfg.ApiKey == "" {
+		return OK, "not configured; using yt-dlp"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(cfg.ApiUrl, "/"), nil)
+	if err != nil {
+		return Warn, err.Error()
+	}
+	req.Header.Set("X-API-Key", cfg.ApiKey)
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Warn, "unreachable: " + config.Redact(err.Error())
+	}
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return Warn, "the API key was rejected (" + resp.Status + ")"
+	case resp.StatusCode >= 500:
+		return Warn, "server error (" + resp.Status + ")"
+	}
+	return OK, fmt.Sprintf("responded in %s", time.Since(start).Round(time.Millisecond))
+}
+
+// checkDownloadsDir checks that files can be created in DOWNLOADS_DIR.
+func checkDownloadsDir(context.Context) (Status, string) {
+	dir := config.Get().DownloadsDir
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Fail, err.Error()
+	}
+	f, err := os.CreateTemp(dir, ".selftest-*")
+	if err != nil {
+		return Fail, "not writable: " + err.Error()
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	return OK, dir
+}
@@ -0,0 +1,127 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package shutdown coordinates stopping the bot. Subsystems register hooks that run in priority order
+// once a stop is requested, and the process is forced to exit if they overrun the grace period.
+package shutdown
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Priorities of the shutdown stages. Hooks with a lower priority run first; hooks with equal priority run in
+// the order they were registered.
+const (
+	PriorityCancel = 10 // PriorityCancel cancels the root context so that downloads and broadcasts stop.
+	PriorityDrain  = 15 // PriorityDrain waits for cancelled work to checkpoint its progress.
+	PriorityNotify = 20 // PriorityNotify tells chats with active playback that the bot is going away.
+	PriorityFlush  = 30 // PriorityFlush writes buffered counters and queue snapshots to the database.
+	PriorityCalls  = 40 // PriorityCalls leaves voice chats and stops the assistant clients.
+	PriorityClient = 50 // PriorityClient closes the bot's Telegram client.
+	PriorityLogs   = 60 // PriorityLogs flushes and closes the log file.
+)
+
+// Hook is run during shutdown. ctx expires when the grace period ends.
+type Hook func(ctx context.Context) error
+
+type hook struct {
+	name     string
+	priority int
+	fn       Hook
+}
+
+var (
+	root, cancelRoot = context.WithCancel(context.Background())
+	stopping         atomic.Bool
+	requested        = make(chan struct{})
+	requestOnce      sync.Once
+	exitCode         atomic.Int32
+
+	mu    sync.Mutex
+	hooks []hook
+)
+
+func init() {
+	Register("cancel root context", PriorityCancel, func(context.Context) error {
+		cancelRoot()
+		return nil
+	})
+}
+
+// Context returns the root context of the process, which is cancelled when shutdown begins.
+// Long-running work such as downloads and broadcasts should derive their contexts from it.
+func Context() context.Context {
+	return root
+}
+
+// Stopping reports whether shutdown has begun. Handlers use it to stop accepting new commands.
+func Stopping() bool {
+	return stopping.Load()
+}
+
+// Request asks the process to shut down as if it had received SIGTERM, then exit with status 1 so that a
+// supervisor such as Docker or systemd restarts it.
+func Request(reason string) {
+	requestOnce.Do(func() {
+		log.Printf("A restart was requested: %s", reason)
+		exitCode.Store(1)
+		close(requested)
+	})
+}
+
+// Requested is closed once Request has been called.
+func Requested() <-chan struct{} {
+	return requested
+}
+
+// ExitCode is the status the process should exit with after Run: 1 if the shutdown was requested with Request.
+func ExitCode() int {
+	return int(exitCode.Load())
+}
+
+// Register adds a hook to run during shutdown at the given priority.
+func Register(name string, priority int, fn Hook) {
+	mu.Lock()
+	defer mu.Unlock()
+	hooks = append(hooks, hook{name: name, priority: priority, fn: fn})
+}
+
+// Run stops the bot: new work is refused and every hook runs in priority order. If the hooks have not
+// finished within grace, the process exits with status 1. Only the first call does anything.
+func Run(grace time.Duration) {
+	if !stopping.CompareAndSwap(false, true) {
+		return
+	}
+
+	force := time.AfterFunc(grace, func() {
+		log.Printf("Shutdown did not finish within %s; exiting now.", grace)
+		os.Exit(1)
+	})
+	defer force.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	mu.Lock()
+	ordered := append([]hook(nil), hooks...)
+	mu.Unlock()
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].priority < ordered[j].priority })
+
+	for _, h := range ordered {
+		start := time.Now()
+		if err := h.fn(ctx); err != nil {
+			log.Printf("Shutdown step %q failed after %s: %v", h.name, time.Since(start).Round(time.Millisecond), err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package stt turns voice notes into text for voice searches, through a local whisper.cpp binary or an
+// OpenAI-compatible HTTP API, whichever the configuration sets up.
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/audio"
+	"ashokshau/tgmusic/src/core/logging"
+)
+
+var logger = logging.For("stt")
+
+var (
+	// ErrNotConfigured is returned when no speech-to-text backend is set up.
+	ErrNotConfigured = errors.New("speech-to-text is not configured")
+	// ErrFailed is returned when the backend fails; the details are logged.
+	ErrFailed = errors.New("transcription failed")
+)
+
+// maxResponse caps how much of the HTTP API's response is read.
+const maxResponse = 1 << 20
+
+// Transcribe returns what is said in the audio file at path, with surrounding whitespace removed. An empty
+// result means nothing intelligible was said.
+func Transcribe(ctx context.Context, path string) (string, error) {
+	speech := config.Get().Speech
+	switch {
+	case speech.Local():
+		return transcribeLocal(ctx, speech, path)
+	case speech.APIURL != "":
+		return transcribeAPI(ctx, speech, path)
+	}
+	return "", ErrNotConfigured
+}
+
+// transcribeLocal runs whisper.cpp on path, which is first converted to the 16 kHz WAV it expects.
+func transcribeLocal(ctx context.Context, speech config.Speech, path string) (string, error) {
+	wav := strings.TrimSuffix(path, filepath.Ext(path)) + ".stt.wav"
+	defer os.Remove(wav)
+	if err := audio.ToSpeechWAV(ctx, path, wav); err != nil {
+		return "", err
+	}
+
+	args := []string{"-m", speech.WhisperModel, "-f", wav, "-nt", "-np"}
+	if speech.Language != "" {
+		args = append(args, "-l", speech.Language)
+	}
+	defer logger.Time(ctx, "exec", "whisper", path)()
+	cmd := exec.CommandContext(ctx, speech.WhisperBin, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		logger.Warn("whisper failed on %s: %v: %s", path, err, strings.TrimSpace(stderr.String()))
+		return "", ErrFailed
+	}
+	return cleanTranscript(stdout.String()), nil
+}
+
+// transcribeAPI uploads path to the transcription endpoint and returns the text it answers with.
+func transcribeAPI(ctx context.Context, speech config.Speech, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(part, f); err != nil {
+		return "", err
+	}
+	_ = form.WriteField("model", speech.Model)
+	if speech.Language != "" {
+		_ = form.WriteField("language", speech.Language)
+	}
+	if err = form.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, speech.APIURL, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if speech.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+speech.APIKey)
+	}
+
+	defer logger.Time(ctx, "http", "stt", path)()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		logger.Warn("The transcription request failed: %v", config.Redact(err.Error()))
+		return "", ErrFailed
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxResponse))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		logger.Warn("The transcription API answered %s: %s", resp.Status, config.Redact(strings.TrimSpace(string(raw))))
+		return "", ErrFailed
+	}
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		logger.Warn("The transcription API answered with invalid JSON: %v", err)
+		return "", fmt.Errorf("%w: invalid response", ErrFailed)
+	}
+	return cleanTranscript(result.Text), nil
+}
+
+// markerRe matches the markers whisper writes for silence and non-speech, such as "[BLANK_AUDIO]" or
+// "(upbeat music)".
+var markerRe = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)`)
+
+// cleanTranscript drops the non-speech markers from a transcript and joins its lines.
+func cleanTranscript(s string) string {
+	return strings.Join(strings.Fields(markerRe.ReplaceAllString(s, " ")), " ")
+}
@@ -0,0 +1,313 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package thumb renders the now-playing card: the track's cover, title, duration bar and the requester's
+// avatar composited into one image. It is pure Go and ships its own fonts, so it needs nothing installed.
+package thumb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"ashokshau/tgmusic/src/core/cache"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Card size and layout, in pixels.
+const (
+	cardWidth  = 1280
+	cardHeight = 720
+	coverSize  = 440
+	coverX     = 80
+	coverY     = 140
+	textX      = 580
+	textWidth  = cardWidth - textX - 80
+	avatarSize = 64
+)
+
+// ErrMissingGlyphs is returned when the fonts cannot draw most of the track's title, such as a title in a
+// script they do not cover.
+var ErrMissingGlyphs = errors.New("the card fonts cannot draw the title")
+
+// Card is what goes on a now-playing card. Avatar may be nil.
+type Card struct {
+	Title     string
+	Requester string
+	Duration  int // Duration is the track's length in seconds.
+	Cover     image.Image
+	Avatar    image.Image
+}
+
+// faces are the font faces of the card, loaded once.
+type faces struct {
+	label, title, body *opentype.Font
+	err                error
+}
+
+var (
+	fontOnce sync.Once
+	loaded   faces
+)
+
+// loadFonts parses the card fonts. A font file at path, if given, replaces the bundled bold font for the
+// title and falls back to it when it cannot be read.
+func loadFonts(path string) faces {
+	fontOnce.Do(func() {
+		regular, err := opentype.Parse(goregular.TTF)
+		if err != nil {
+			loaded.err = fmt.Errorf("bundled regular font: %w", err)
+			return
+		}
+		bold, err := opentype.Parse(gobold.TTF)
+		if err != nil {
+			loaded.err = fmt.Errorf("bundled bold font: %w", err)
+			return
+		}
+		loaded = faces{label: regular, title: bold, body: regular}
+
+		if path == "" {
+			return
+		}
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var custom *opentype.Font
+			if custom, err = opentype.Parse(data); err == nil {
+				loaded.title, loaded.body = custom, custom
+				return
+			}
+		}
+		logger.Warn("Failed to load the card font %s, using the bundled one: %v", path, err)
+	})
+	return loaded
+}
+
+// face returns f at size points.
+func face(f *opentype.Font, size float64) (font.Face, error) {
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// Render draws c as a JPEG, using the font at fontPath if one is set.
+func Render(c Card, fontPath string) ([]byte, error) {
+	if c.Cover == nil {
+		return nil, errors.New("the track has no cover")
+	}
+	fonts := loadFonts(fontPath)
+	if fonts.err != nil {
+		return nil, fonts.err
+	}
+
+	labelFace, err := face(fonts.label, 28)
+	if err != nil {
+		return nil, err
+	}
+	defer labelFace.Close()
+	titleFace, err := face(fonts.title, 52)
+	if err != nil {
+		return nil, err
+	}
+	defer titleFace.Close()
+	bodyFace, err := face(fonts.body, 30)
+	if err != nil {
+		return nil, err
+	}
+	defer bodyFace.Close()
+
+	title, ok := drawable(titleFace, c.Title)
+	if !ok {
+		return nil, ErrMissingGlyphs
+	}
+	requester, _ := drawable(bodyFace, c.Requester)
+
+	img := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	drawBackground(img, c.Cover)
+	drawCover(img, c.Cover)
+
+	white := image.NewUniform(color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	grey := image.NewUniform(color.RGBA{R: 190, G: 190, B: 190, A: 255})
+
+	drawText(img, labelFace, grey, textX, 195, "NOW PLAYING")
+	for i, line := range wrap(titleFace, title, textWidth, 2) {
+		drawText(img, titleFace, white, textX, 270+i*64, line)
+	}
+
+	nameX := textX
+	if c.Avatar != nil {
+		drawAvatar(img, c.Avatar, image.Pt(textX, 400))
+		nameX += avatarSize + 20
+	}
+	if requester != "" {
+		drawText(img, bodyFace, grey, nameX, 443, "Requested by "+truncateTo(bodyFace, requester, cardWidth-80-nameX-200))
+	}
+
+	drawProgress(img, bodyFace, white, grey, c.Duration)
+	return encode(img)
+}
+
+// Plain returns the cover alone as a JPEG, for when a card cannot be rendered.
+func Plain(cover image.Image) ([]byte, error) {
+	if cover == nil {
+		return nil, errors.New("the track has no cover")
+	}
+	return encode(cover)
+}
+
+// encode encodes img as a JPEG.
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawable drops the characters of s that f cannot draw. It reports false if that leaves nothing, or drops
+// more than half of the letters.
+func drawable(f font.Face, s string) (string, bool) {
+	var b strings.Builder
+	letters, missing := 0, 0
+	for _, r := range strings.TrimSpace(s) {
+		if _, ok := f.GlyphAdvance(r); ok || r == ' ' {
+			b.WriteRune(r)
+		} else if unicode.IsLetter(r) {
+			missing++
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	out := strings.Join(strings.Fields(b.String()), " ")
+	return out, out != "" && missing*2 <= letters
+}
+
+// drawBackground fills img with a blurred, darkened copy of the cover.
+func drawBackground(img *image.RGBA, cover image.Image) {
+	src := cropTo(cover, float64(cardWidth)/float64(cardHeight))
+	small := image.NewRGBA(image.Rect(0, 0, 48, 27))
+	xdraw.ApproxBiLinear.Scale(small, small.Bounds(), cover, src, draw.Src, nil)
+	xdraw.BiLinear.Scale(img, img.Bounds(), small, small.Bounds(), draw.Src, nil)
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{A: 160}), image.Point{}, draw.Over)
+}
+
+// drawCover draws a square crop of the cover on the left.
+func drawCover(img *image.RGBA, cover image.Image) {
+	dst := image.Rect(coverX, coverY, coverX+coverSize, coverY+coverSize)
+	xdraw.CatmullRom.Scale(img, dst, cover, cropTo(cover, 1), draw.Src, nil)
+}
+
+// drawAvatar draws the avatar as a circle with its top-left corner at at.
+func drawAvatar(img *image.RGBA, avatar image.Image, at image.Point) {
+	scaled := image.NewRGBA(image.Rect(0, 0, avatarSize, avatarSize))
+	xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), avatar, cropTo(avatar, 1), draw.Src, nil)
+	dst := image.Rectangle{Min: at, Max: at.Add(image.Pt(avatarSize, avatarSize))}
+	draw.DrawMask(img, dst, scaled, image.Point{}, circle{r: avatarSize / 2}, image.Point{}, draw.Over)
+}
+
+// drawProgress draws the duration bar at its start, with the elapsed and total time below it.
+func drawProgress(img *image.RGBA, f font.Face, fg, bg *image.Uniform, duration int) {
+	const barY, barH = 520, 8
+	draw.Draw(img, image.Rect(textX, barY, textX+textWidth, barY+barH), bg, image.Point{}, draw.Over)
+	draw.DrawMask(img, image.Rect(textX-4, barY-6, textX+16, barY+14), fg, image.Point{}, circle{r: 10}, image.Point{}, draw.Over)
+
+	drawText(img, f, bg, textX, barY+50, "0:00")
+	total := "Live"
+	if duration > 0 {
+		total = cache.SecToMin(duration)
+	}
+	drawText(img, f, bg, textX+textWidth-font.MeasureString(f, total).Ceil(), barY+50, total)
+}
+
+// drawText draws s with its baseline starting at x, y.
+func drawText(img *image.RGBA, f font.Face, src image.Image, x, y int, s string) {
+	d := font.Drawer{Dst: img, Src: src, Face: f, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+// wrap breaks s into at most maxLines lines of at most width pixels, ending the last one with an ellipsis
+// if s does not fit.
+func wrap(f font.Face, s string, width, maxLines int) []string {
+	var lines []string
+	line := ""
+	words := strings.Fields(s)
+	for i, word := range words {
+		next := strings.TrimSpace(line + " " + word)
+		if font.MeasureString(f, next).Ceil() <= width {
+			line = next
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = word
+		if len(lines) == maxLines-1 {
+			line = strings.Join(words[i:], " ")
+			break
+		}
+	}
+	return append(lines, truncateTo(f, line, width))
+}
+
+// truncateTo shortens s with an ellipsis until it fits in width pixels.
+func truncateTo(f font.Face, s string, width int) string {
+	if font.MeasureString(f, s).Ceil() <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if out := strings.TrimSpace(string(runes)) + "…"; font.MeasureString(f, out).Ceil() <= width {
+			return out
+		}
+	}
+	return ""
+}
+
+// cropTo returns the largest centred rectangle of img with the given aspect ratio (width / height).
+func cropTo(img image.Image, aspect float64) image.Rectangle {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if float64(w)/float64(h) > aspect {
+		cw := int(float64(h) * aspect)
+		x := b.Min.X + (w-cw)/2
+		return image.Rect(x, b.Min.Y, x+cw, b.Max.Y)
+	}
+	ch := int(float64(w) / aspect)
+	y := b.Min.Y + (h-ch)/2
+	return image.Rect(b.Min.X, y, b.Max.X, y+ch)
+}
+
+// circle is a mask that is opaque inside a circle of radius r centred in a 2r square.
+type circle struct {
+	r int
+}
+
+func (c circle) ColorModel() color.Model { return color.AlphaModel }
+
+func (c circle) Bounds() image.Rectangle { return image.Rect(0, 0, 2*c.r, 2*c.r) }
+
+func (c circle) At(x, y int) color.Color {
+	dx, dy := float64(x-c.r)+0.5, float64(y-c.r)+0.5
+	if dx*dx+dy*dy <= float64(c.r*c.r) {
+		return color.Alpha{A: 255}
+	}
+	return color.Alpha{}
+}
@@ -0,0 +1,79 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package thumb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/logging"
+
+	_ "golang.org/x/image/webp"
+)
+
+// maxImageSize caps the size of a downloaded cover.
+const maxImageSize = 8 << 20
+
+var logger = logging.For("thumb")
+
+// rendered caches finished cards by track and requester, so replays and loops are not rendered again.
+var rendered = cache.NewCache[[]byte](time.Hour)
+
+// FetchImage downloads and decodes the image at url.
+func FetchImage(ctx context.Context, url string) (image.Image, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching the cover: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize))
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+// Decode decodes a JPEG, PNG, GIF or WebP image.
+func Decode(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// Cached returns the card stored under key, rendering and storing it with render if there is none.
+func Cached(key string, render func() ([]byte, error)) ([]byte, error) {
+	if data, ok := rendered.Get(key); ok {
+		return data, nil
+	}
+	data, err := render()
+	if err != nil {
+		return nil, err
+	}
+	rendered.Set(key, data)
+	return data, nil
+}
@@ -0,0 +1,179 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package tts turns short texts into spoken clips for the announcements between tracks, through a local espeak
+// or piper binary or an OpenAI-compatible HTTP API, whichever the configuration sets up. Clips are cached in the
+// downloads folder by a hash of their text and voice, so a repeated announcement is only generated once.
+package tts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/core/logging"
+)
+
+var logger = logging.For("tts")
+
+var (
+	// ErrNotConfigured is returned when no text-to-speech backend is set up.
+	ErrNotConfigured = errors.New("text-to-speech is not configured")
+	// ErrFailed is returned when the backend fails; the details are logged.
+	ErrFailed = errors.New("speech synthesis failed")
+)
+
+const (
+	// maxText caps how many characters of a text are spoken.
+	maxText = 300
+	// maxResponse caps how much audio is read from the HTTP API.
+	maxResponse = 20 << 20
+)
+
+// Speak returns the path of a clip of text being spoken, generating it unless it is cached.
+func Speak(ctx context.Context, text string) (string, error) {
+	t := config.Get().TTS
+	if !t.Configured() {
+		return "", ErrNotConfigured
+	}
+	text = strings.TrimSpace(text)
+	if r := []rune(text); len(r) > maxText {
+		text = string(r[:maxText])
+	}
+	if text == "" {
+		return "", ErrFailed
+	}
+
+	name := clipName(t, text)
+	if path, ok := dl.FindDownload(name); ok {
+		return path, nil
+	}
+	path := dl.DownloadPath(name)
+	// Every call writes its own partial file, so two announcements of the same text cannot clobber each other.
+	tmp, err := os.CreateTemp(filepath.Dir(path), name+".*.part")
+	if err != nil {
+		return "", err
+	}
+	part := tmp.Name()
+	_ = tmp.Close()
+	defer os.Remove(part)
+
+	if t.Bin != "" {
+		err = speakLocal(ctx, t, text, part)
+	} else {
+		err = speakAPI(ctx, t, text, part)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(part, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// clipName returns the cache file name of text spoken with the backend and voice of t.
+func clipName(t config.TTS, text string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{t.Bin, t.APIURL, t.Model, t.Voice, text}, "\x00")))
+	return "tts-" + hex.EncodeToString(sum[:16]) + ".wav"
+}
+
+// speakLocal runs espeak or piper with text on stdin, writing a WAV file to out.
+func speakLocal(ctx context.Context, t config.TTS, text, out string) error {
+	var args []string
+	switch {
+	case t.Piper():
+		args = []string{"--model", t.Voice, "--output_file", out}
+	case t.Voice != "":
+		args = []string{"-v", t.Voice, "-w", out, "--stdin"}
+	default:
+		args = []string{"-w", out, "--stdin"}
+	}
+
+	defer logger.Time(ctx, "exec", "tts", t.Bin)()
+	cmd := exec.CommandContext(ctx, t.Bin, args...)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		logger.Warn("%s failed: %v: %s", t.Bin, err, strings.TrimSpace(stderr.String()))
+		return ErrFailed
+	}
+	if info, err := os.Stat(out); err != nil || info.Size() == 0 {
+		logger.Warn("%s wrote no audio", t.Bin)
+		return ErrFailed
+	}
+	return nil
+}
+
+// speakAPI asks the speech endpoint for text as WAV and writes the answer to out.
+func speakAPI(ctx context.Context, t config.TTS, text, out string) error {
+	voice := t.Voice
+	if voice == "" {
+		voice = "alloy"
+	}
+	body, err := json.Marshal(map[string]string{"model": t.Model, "input": text, "voice": voice, "response_format": "wav"})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.APIURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.APIKey)
+	}
+
+	defer logger.Time(ctx, "http", "tts", t.APIURL)()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		logger.Warn("The speech request failed: %v", config.Redact(err.Error()))
+		return ErrFailed
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		logger.Warn("The speech API answered %s: %s", resp.Status, config.Redact(strings.TrimSpace(string(raw))))
+		return ErrFailed
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(resp.Body, maxResponse))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		logger.Warn("The speech API answered with no audio")
+		return ErrFailed
+	}
+	return nil
+}
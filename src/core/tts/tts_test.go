@@ -0,0 +1,66 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package tts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"ashokshau/tgmusic/src/config"
+)
+
+func TestConcurrentSpeakShareTheClip(t *testing.T) {
+	const calls = 4
+	clip := []byte("RIFF-clip")
+	var arrived sync.WaitGroup
+	arrived.Add(calls)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every call is in flight before any of them writes its clip.
+		arrived.Done()
+		arrived.Wait()
+		_, _ = w.Write(clip)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	t.Cleanup(config.Use(&config.BotConfig{DownloadsDir: dir, TTS: config.TTS{APIURL: srv.URL}}))
+
+	var wg sync.WaitGroup
+	paths := make([]string, calls)
+	errs := make([]error, calls)
+	for i := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			paths[i], errs[i] = Speak(context.Background(), "Up next")
+		}()
+	}
+	wg.Wait()
+
+	for i := range calls {
+		if errs[i] != nil {
+			t.Fatalf("Speak %d: %v", i, errs[i])
+		}
+		if paths[i] != paths[0] {
+			t.Fatalf("Speak %d = %s, want %s", i, paths[i], paths[0])
+		}
+	}
+	got, err := os.ReadFile(paths[0])
+	if err != nil || string(got) != string(clip) {
+		t.Fatalf("clip = %q, %v", got, err)
+	}
+	if parts, _ := filepath.Glob(filepath.Join(dir, "*.part")); len(parts) > 0 {
+		t.Fatalf("partial files left behind: %v", parts)
+	}
+}
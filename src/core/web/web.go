@@ -0,0 +1,49 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package web serves the bot's public pages, such as the now-playing pages of /nptoken, on their own server.
+// It is kept apart from the debug server so that exposing these pages never exposes pprof.
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// mux holds the public pages.
+var mux = http.NewServeMux()
+
+// Handle serves h for pattern on the public server. The pages must be read-only and safe to expose to anyone,
+// and Handle must be called before Serve.
+func Handle(pattern string, h http.Handler) {
+	mux.Handle(pattern, h)
+}
+
+// Handler returns the public server's handler.
+func Handler() http.Handler {
+	return mux
+}
+
+// Serve starts the public server on addr in the background. Errors other than a normal close are passed to onError.
+func Serve(addr string, onError func(error)) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       time.Minute,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			onError(err)
+		}
+	}()
+	return srv
+}
@@ -0,0 +1,154 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package workpool runs jobs on a fixed number of workers behind a bounded queue. When the queue is full,
+// low-priority jobs are dropped while high-priority ones wait for room, so a burst of updates cannot make
+// the number of running handlers, and the memory they hold, grow without bound.
+package workpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Priority decides what happens to a job when the queue is full.
+type Priority int
+
+const (
+	Low  Priority = iota // Low jobs are dropped when the queue is full.
+	High                 // High jobs push out a queued low job, or wait for room.
+)
+
+// Stats is a point-in-time view of a pool.
+type Stats struct {
+	Workers int
+	Running int
+	Queued  int
+	Dropped int64
+}
+
+// Pool is a bounded worker pool. High-priority jobs are started before low-priority ones.
+type Pool struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	high    []func()
+	low     []func()
+	size    int
+	workers int
+	running int
+	dropped atomic.Int64
+}
+
+// New starts a pool with the given number of workers and room for size queued jobs.
+func New(workers, size int) *Pool {
+	p := &Pool{size: max(size, 1), workers: max(workers, 1)}
+	p.cond = sync.NewCond(&p.mu)
+	for i := 0; i < p.workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// queued returns the number of jobs waiting for a worker. p.mu must be held.
+func (p *Pool) queued() int {
+	return len(p.high) + len(p.low)
+}
+
+// Submit queues fn. A low-priority job is dropped if the queue is full, in which case Submit returns false.
+// A high-priority job replaces the oldest queued low-priority job if the queue is full, and otherwise waits
+// until a worker makes room; it is always queued.
+func (p *Pool) Submit(priority Priority, fn func()) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if priority == Low {
+		if p.queued() >= p.size {
+			p.dropped.Add(1)
+			return false
+		}
+		p.low = append(p.low, fn)
+		p.cond.Broadcast()
+		return true
+	}
+
+	for p.queued() >= p.size {
+		if len(p.low) > 0 {
+			p.low[0] = nil
+			p.low = p.low[1:]
+			p.dropped.Add(1)
+			break
+		}
+		p.cond.Wait()
+	}
+	p.high = append(p.high, fn)
+	p.cond.Broadcast()
+	return true
+}
+
+// next waits for a job, preferring high-priority ones, and marks it running.
+func (p *Pool) next() func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for p.queued() == 0 {
+		p.cond.Wait()
+	}
+
+	var fn func()
+	if len(p.high) > 0 {
+		fn, p.high[0] = p.high[0], nil
+		p.high = p.high[1:]
+	} else {
+		fn, p.low[0] = p.low[0], nil
+		p.low = p.low[1:]
+	}
+	p.running++
+	p.cond.Broadcast()
+	return fn
+}
+
+// work runs jobs until the process exits.
+func (p *Pool) work() {
+	for {
+		fn := p.next()
+		fn()
+
+		p.mu.Lock()
+		p.running--
+		p.cond.Broadcast()
+		p.mu.Unlock()
+	}
+}
+
+// Stats returns the pool's current load and how many jobs it has dropped.
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Stats{Workers: p.workers, Running: p.running, Queued: p.queued(), Dropped: p.dropped.Load()}
+}
+
+// Drain waits until no job is queued or running, or ctx is done.
+func (p *Pool) Drain(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.cond.Broadcast()
+	})
+	defer stop()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.queued() > 0 || p.running > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p.cond.Wait()
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package workpool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBurstDropsOnlyLowJobs(t *testing.T) {
+	const workers, size = 4, 16
+	p := New(workers, size)
+
+	// Hold every worker so that the burst has to queue.
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(workers)
+	for range workers {
+		p.Submit(High, func() { started.Done(); <-release })
+	}
+	started.Wait()
+
+	var running, peak, high, low atomic.Int64
+	job := func(n *atomic.Int64) func() {
+		return func() {
+			cur := running.Add(1)
+			for {
+				old := peak.Load()
+				if cur <= old || peak.CompareAndSwap(old, cur) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			n.Add(1)
+		}
+	}
+
+	const highJobs, lowJobs = 200, 1000
+	var accepted atomic.Int64
+	var wg sync.WaitGroup
+	for i := range highJobs + lowJobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i < highJobs {
+				p.Submit(High, job(&high))
+			} else if p.Submit(Low, job(&low)) {
+				accepted.Add(1)
+			}
+		}()
+	}
+
+	// Let the burst fill the queue before the workers start draining it.
+	time.Sleep(20 * time.Millisecond)
+	if q := p.Stats().Queued; q > size {
+		t.Fatalf("queued = %d, want at most %d", q, size)
+	}
+	close(release)
+	wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := p.Drain(ctx); err != nil {
+		t.Fatalf("Drain = %v", err)
+	}
+
+	if high.Load() != highJobs {
+		t.Fatalf("ran %d high jobs, want all %d", high.Load(), highJobs)
+	}
+	st := p.Stats()
+	if low.Load()+st.Dropped != lowJobs {
+		t.Fatalf("ran %d low jobs and dropped %d, want %d in total", low.Load(), st.Dropped, lowJobs)
+	}
+	if st.Dropped == 0 {
+		t.Fatal("no low job was dropped under a burst larger than the queue")
+	}
+	if low.Load() > accepted.Load() {
+		t.Fatalf("ran %d low jobs but accepted only %d", low.Load(), accepted.Load())
+	}
+	if peak.Load() > workers {
+		t.Fatalf("%d jobs ran at once, want at most %d", peak.Load(), workers)
+	}
+}
+
+func TestHighJobsStartFirst(t *testing.T) {
+	p := New(1, 8)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit(High, func() { close(started); <-release })
+	<-started
+
+	var mu sync.Mutex
+	var order []string
+	record := func(s string) func() {
+		return func() { mu.Lock(); order = append(order, s); mu.Unlock() }
+	}
+	p.Submit(Low, record("low"))
+	p.Submit(High, record("high"))
+	close(release)
+
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain = %v", err)
+	}
+	if len(order) != 2 || order[0] != "high" {
+		t.Fatalf("order = %v, want the high job first", order)
+	}
+}
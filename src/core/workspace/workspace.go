@@ -0,0 +1,158 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+// Package workspace hands out the temporary directories that edits, transcodes and preloads write their
+// intermediate files to. Every job gets its own directory under DOWNLOADS_DIR/tmp, so concurrent jobs never
+// collide on a file name, and directories a crashed job left behind are swept away.
+package workspace
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/logging"
+)
+
+// DirName is the subfolder of DOWNLOADS_DIR the workspaces are created in.
+const DirName = "tmp"
+
+// StaleAfter is the age from which a workspace no running job owns is swept.
+const StaleAfter = time.Hour
+
+// ErrBudget is returned by New while the workspaces take more space than TEMP_SPACE_BUDGET allows.
+var ErrBudget = errors.New("the temporary space budget is exhausted")
+
+var logger = logging.For("workspace")
+
+var (
+	mu   sync.Mutex
+	live = make(map[string]struct{})
+)
+
+// Workspace is the temporary directory of a single job.
+type Workspace struct {
+	// Dir is the directory; it is removed with everything in it by Close.
+	Dir string
+}
+
+// Root returns the directory the workspaces are created in.
+func Root() string {
+	return filepath.Join(config.Get().DownloadsDir, DirName)
+}
+
+// New creates a workspace for a job of the given kind, such as "edit". It fails with ErrBudget while the
+// existing workspaces exceed TEMP_SPACE_BUDGET. The caller must Close it once the job is done.
+func New(kind string) (*Workspace, error) {
+	if budget := config.Get().TempBudget; budget > 0 {
+		if used, _ := Usage(); used >= budget {
+			return nil, ErrBudget
+		}
+	}
+	return create(Root(), kind)
+}
+
+// create creates a workspace for a job of the given kind under root.
+func create(root, kind string) (*Workspace, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(root, kind+"-")
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	live[dir] = struct{}{}
+	mu.Unlock()
+	return &Workspace{Dir: dir}, nil
+}
+
+// Path returns the path of the file name inside the workspace.
+func (w *Workspace) Path(name string) string {
+	return filepath.Join(w.Dir, filepath.Base(name))
+}
+
+// Close removes the workspace and everything in it. It is safe to call more than once.
+func (w *Workspace) Close() error {
+	mu.Lock()
+	delete(live, w.Dir)
+	mu.Unlock()
+	return os.RemoveAll(w.Dir)
+}
+
+// Usage returns the bytes taken by the workspaces and how many of them belong to running jobs.
+func Usage() (bytes int64, jobs int) {
+	_ = filepath.WalkDir(Root(), func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			bytes += info.Size()
+		}
+		return nil
+	})
+	mu.Lock()
+	jobs = len(live)
+	mu.Unlock()
+	return bytes, jobs
+}
+
+// Sweep removes the workspaces no running job owns that were last changed before olderThan ago, such as
+// those left behind by a crash, and returns how many it removed.
+func Sweep(olderThan time.Duration) int {
+	return sweep(Root(), time.Now().Add(-olderThan))
+}
+
+// sweep removes the workspaces under root that no running job owns and that were last changed before cutoff.
+func sweep(root string, cutoff time.Time) int {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return 0
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		mu.Lock()
+		_, running := live[dir]
+		mu.Unlock()
+		if running {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Warn("Failed to remove the stale workspace %s: %v", dir, err)
+			continue
+		}
+		removed++
+	}
+	return removed
+}
+
+// StartSweeper sweeps stale workspaces now and then every StaleAfter.
+func StartSweeper() {
+	sweep := func() {
+		if n := Sweep(StaleAfter); n > 0 {
+			logger.Info("Removed %d stale workspaces.", n)
+		}
+	}
+	sweep()
+	go func() {
+		for range time.Tick(StaleAfter) {
+			sweep()
+		}
+	}()
+}
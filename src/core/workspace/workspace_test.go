@@ -0,0 +1,105 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// backdate sets the modification time of path to age ago.
+func backdate(t *testing.T, path string, age time.Duration) {
+	t.Helper()
+	at := time.Now().Add(-age)
+	if err := os.Chtimes(path, at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestSweepRemovesCrashedJobs(t *testing.T) {
+	root := t.TempDir()
+
+	// A job that crashed before an earlier restart left its directory and a partial file behind; nothing
+	// in this process owns it.
+	crashed := filepath.Join(root, "edit-crashed")
+	if err := os.MkdirAll(crashed, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(crashed, "out.mp3.part"), []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	backdate(t, crashed, 2*StaleAfter)
+
+	// A directory that is not owned either but changed recently may still be in use by a job of another
+	// process sharing DOWNLOADS_DIR.
+	recent := filepath.Join(root, "edit-recent")
+	if err := os.MkdirAll(recent, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A running job's workspace is kept however old it is.
+	running, err := create(root, "transcode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer running.Close()
+	backdate(t, running.Dir, 2*StaleAfter)
+
+	if n := sweep(root, time.Now().Add(-StaleAfter)); n != 1 {
+		t.Fatalf("sweep removed %d workspaces, want 1", n)
+	}
+	if exists(crashed) {
+		t.Error("the crashed job's workspace was kept")
+	}
+	if !exists(recent) {
+		t.Error("a recently changed workspace was removed")
+	}
+	if !exists(running.Dir) {
+		t.Error("a running job's workspace was removed")
+	}
+
+	// Once the job is done its workspace goes, and a second sweep has nothing left to do.
+	if err := running.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if exists(running.Dir) {
+		t.Error("Close kept the workspace")
+	}
+	if n := sweep(root, time.Now().Add(-StaleAfter)); n != 0 {
+		t.Fatalf("second sweep removed %d workspaces, want 0", n)
+	}
+}
+
+func TestWorkspacesDoNotCollide(t *testing.T) {
+	root := t.TempDir()
+	a, err := create(root, "edit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := create(root, "edit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	if a.Path("out.mp3") == b.Path("out.mp3") {
+		t.Fatalf("two jobs share %s", a.Path("out.mp3"))
+	}
+	if got := a.Path("../../escape.mp3"); filepath.Dir(got) != a.Dir {
+		t.Fatalf("Path(%q) = %s, outside the workspace", "../../escape.mp3", got)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// activityTouchEvery is how often the last activity of a chat or user is written, so a busy chat does not
+// cost a database write per command. Broadcast activity windows are counted in days, so this is precise enough.
+const activityTouchEvery = time.Hour
+
+// activityTouched holds the chats and users whose activity was written within the last activityTouchEvery.
+// Entries expire then, and the reaper purges them, so the set only holds recently active chats and users.
+var activityTouched = cache.NewCache[struct{}](activityTouchEvery)
+
+// noteActivity records that the chat of m, and its sender, used the bot.
+func noteActivity(m *tg.NewMessage) {
+	now := time.Now()
+	chatID, userID := m.ChannelID(), m.SenderID()
+	touchChat := chatID < 0 && dueActivity(chatID)
+	touchUser := userID > 0 && dueActivity(userID)
+	if !touchChat && !touchUser {
+		return
+	}
+	go func() {
+		ctx, cancel := db.Ctx()
+		defer cancel()
+		if touchChat {
+			if err := db.Instance.TouchChat(ctx, chatID, now); err != nil {
+				logger.Debug("Failed to record the activity of chat %d: %v", chatID, err)
+			}
+		}
+		if touchUser {
+			if err := db.Instance.TouchUser(ctx, userID, now); err != nil {
+				logger.Debug("Failed to record the activity of user %d: %v", userID, err)
+			}
+		}
+	}()
+}
+
+// dueActivity reports whether the activity of id should be written, and if so notes it as written.
+func dueActivity(id int64) bool {
+	key := strconv.FormatInt(id, 10)
+	if _, ok := activityTouched.Get(key); ok {
+		return false
+	}
+	activityTouched.Set(key, struct{}{})
+	return true
+}
@@ -38,7 +38,7 @@ func reloadAdminCacheHandler(m *telegram.NewMessage) error {
 		timePassed := time.Since(lastUsed)
 		if timePassed < reloadCooldown {
 			remaining := int((reloadCooldown - timePassed).Seconds())
-			_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "reload_cooldown"), cache.SecToMin(remaining)))
+			_, _ = replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "reload_cooldown"), cache.SecToMin(remaining)), true)
 			return nil
 		}
 	}
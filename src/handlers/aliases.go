@@ -0,0 +1,97 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ashokshau/tgmusic/src/config"
+)
+
+// builtinAliases are the shorthands users bring from other music bots and the transliterated Hindi names
+// Hindi-speaking groups asked for. They map a lower-cased alias to one of the names of a registry command.
+var builtinAliases = map[string]string{
+	"p":      "play",
+	"vp":     "vPlay",
+	"v":      "vPlay",
+	"next":   "skip",
+	"n":      "skip",
+	"q":      "queue",
+	"bajao":  "play",
+	"chalao": "play",
+	"agla":   "skip",
+	"ruko":   "pause",
+	"band":   "stop",
+}
+
+// commandAliases maps every lower-cased name a registered command answers to, to the other names of the
+// same command. Help uses it to list a command's aliases.
+var commandAliases = make(map[string][]string)
+
+// resolveAliases merges the built-in aliases with the ones configured in COMMAND_ALIASES and returns, for the
+// index of each registry entry, the aliases that point at it. An alias that is already the name of a command,
+// or that two sources point at different commands, or that points at no command, is an error.
+func resolveAliases(custom string) (map[int][]string, error) {
+	configured, err := config.ParseAliases(custom)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := make(map[string]int)
+	for i, cmd := range commands {
+		for _, name := range cmd.names {
+			owner[strings.ToLower(name)] = i
+		}
+	}
+
+	targets := make(map[string]string, len(builtinAliases)+len(configured))
+	for alias, target := range builtinAliases {
+		targets[alias] = target
+	}
+	for alias, target := range configured {
+		if prev, ok := builtinAliases[alias]; ok && !strings.EqualFold(prev, target) {
+			return nil, fmt.Errorf("alias /%s already stands for /%s", alias, prev)
+		}
+		targets[alias] = target
+	}
+
+	byCommand := make(map[int][]string)
+	aliases := make([]string, 0, len(targets))
+	for alias := range targets {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		target := targets[alias]
+		if i, ok := owner[alias]; ok {
+			return nil, fmt.Errorf("alias /%s collides with the /%s command", alias, commands[i].names[0])
+		}
+		i, ok := owner[strings.ToLower(target)]
+		if !ok {
+			return nil, fmt.Errorf("alias /%s points at /%s, which is not a command", alias, target)
+		}
+		byCommand[i] = append(byCommand[i], alias)
+	}
+	return byCommand, nil
+}
+
+// noteAliases records names as the names of one command for help.
+func noteAliases(names []string) {
+	for _, name := range names {
+		var others []string
+		for _, other := range names {
+			if !strings.EqualFold(other, name) {
+				others = append(others, "/"+other)
+			}
+		}
+		commandAliases[strings.ToLower(name)] = others
+	}
+}
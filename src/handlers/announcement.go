@@ -0,0 +1,99 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// setAnnouncementHandler handles the /setannouncement command.
+// The replied-to message becomes the announcement shown below the /start welcome in private chats.
+func setAnnouncementHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	if !m.IsReply() {
+		_, err := m.Reply(lang.GetString(langCode, "announcement_usage"))
+		return err
+	}
+
+	reply, err := m.GetReplyMessage()
+	if err != nil || strings.TrimSpace(reply.Text()) == "" {
+		_, err = m.Reply(lang.GetString(langCode, "announcement_usage"))
+		return err
+	}
+
+	if _, err = db.Instance.SetAnnouncement(ctx, m.Client.Me().ID, reply.RawText()); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "announcement_error"), err.Error()))
+		return err
+	}
+
+	audit(m, "setannouncement", "", reply.Text())
+	_, err = m.Reply(lang.GetString(langCode, "announcement_set"))
+	return err
+}
+
+// delAnnouncementHandler handles the /delannouncement command.
+func delAnnouncementHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	if db.Instance.GetAnnouncement(ctx, m.Client.Me().ID) == nil {
+		_, err := m.Reply(lang.GetString(langCode, "announcement_none"))
+		return err
+	}
+
+	if err := db.Instance.DeleteAnnouncement(ctx, m.Client.Me().ID); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "announcement_error"), err.Error()))
+		return err
+	}
+
+	audit(m, "delannouncement", "", "")
+	_, err := m.Reply(lang.GetString(langCode, "announcement_deleted"))
+	return err
+}
+
+// announcementCallbackHandler handles the "Got it" button below an announcement.
+// It records the dismissal for the user and restores the plain /start welcome.
+func announcementCallbackHandler(cb *telegram.CallbackQuery) error {
+	userID := cb.SenderID
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, userID)
+
+	announcementID, err := strconv.ParseInt(strings.TrimPrefix(cb.DataString(), "announce_dismiss_"), 10, 64)
+	if err != nil {
+		_, _ = cb.Answer(lang.GetString(langCode, "invalid_request"), &telegram.CallbackOptions{Alert: true})
+		return nil
+	}
+
+	if err = db.Instance.DismissAnnouncement(ctx, userID, announcementID); err != nil {
+		_, _ = cb.Answer(fmt.Sprintf(lang.GetString(langCode, "announcement_error"), err.Error()), &telegram.CallbackOptions{Alert: true})
+		return err
+	}
+
+	_, _ = cb.Answer(lang.GetString(langCode, "announcement_dismissed"))
+
+	bot := cb.Client.Me()
+	response := fmt.Sprintf(lang.GetString(langCode, "start_text"), cb.Sender.FirstName, bot.FirstName)
+	_, err = cb.Edit(response, &telegram.SendOptions{ReplyMarkup: core.AddMeMarkup(bot.Username)})
+	return err
+}
@@ -0,0 +1,185 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// pendingRequest is a /play or /vplay request waiting for an admin's approval.
+type pendingRequest struct {
+	id       int64
+	chatID   int64
+	userID   int64
+	msgID    int32 // msgID is the request itself, replayed once it is approved.
+	noticeID int32 // noticeID is the message with the Approve and Reject buttons.
+	isVideo  bool
+	timer    *time.Timer
+}
+
+var approvals = struct {
+	sync.Mutex
+	next    int64
+	pending map[int64]*pendingRequest
+}{pending: make(map[int64]*pendingRequest)}
+
+func init() {
+	registerCallback("ap", &callbackRoute{
+		Allow:  permitCallback(requireAdmin),
+		Handle: approvalCallback,
+	})
+}
+
+// needsApproval reports whether the request m must wait for an admin: the chat has approval mode on and
+// the sender is neither an admin nor an authorized user. Bare commands go through so that they show the usage.
+func needsApproval(m *tg.NewMessage, chatID int64) bool {
+	if strings.TrimSpace(m.Args()) == "" && !m.IsReply() {
+		return false
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return db.Instance.GetApprovalMode(ctx, chatID) && messageDenial(ctx, m, requireAuth) != ""
+}
+
+// takeApproval removes and returns the pending request with the given ID, or nil if it was already handled
+// or has expired.
+func takeApproval(id int64) *pendingRequest {
+	approvals.Lock()
+	defer approvals.Unlock()
+	req, ok := approvals.pending[id]
+	if !ok {
+		return nil
+	}
+	delete(approvals.pending, id)
+	if req.timer != nil {
+		req.timer.Stop()
+	}
+	return req
+}
+
+// requestApproval holds the request m back and asks the chat's admins to approve or reject it.
+func requestApproval(m *tg.NewMessage, isVideo bool, langCode string) error {
+	chatID := m.ChannelID()
+	userID := m.SenderID()
+	cfg := config.Get()
+
+	approvals.Lock()
+	waiting := 0
+	for _, req := range approvals.pending {
+		if req.chatID == chatID && req.userID == userID {
+			waiting++
+		}
+	}
+	if int64(waiting) >= cfg.ApprovalPending {
+		approvals.Unlock()
+		_, err := replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "approval_too_many"), waiting), true)
+		return err
+	}
+	approvals.next++
+	req := &pendingRequest{id: approvals.next, chatID: chatID, userID: userID, msgID: m.ID, isVideo: isVideo}
+	approvals.pending[req.id] = req
+	approvals.Unlock()
+
+	what := html.EscapeString(truncate(strings.TrimSpace(m.Args()), 100))
+	if what == "" {
+		what = lang.GetString(langCode, "approval_replied_file")
+	}
+	id := strconv.FormatInt(req.id, 10)
+	kb := tg.NewKeyboard().AddRow(
+		tg.Button.Data(lang.GetString(langCode, "approval_approve"), callbackData("ap", "", id, "y")),
+		tg.Button.Data(lang.GetString(langCode, "approval_reject"), callbackData("ap", "", id, "n")),
+	)
+	timeout := time.Duration(cfg.ApprovalTimeout) * time.Second
+	notice, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "approval_pending"),
+		senderName(m, langCode), what, cache.SecToMin(int(cfg.ApprovalTimeout))), &tg.SendOptions{ReplyMarkup: kb.Build()})
+	if err != nil {
+		takeApproval(req.id)
+		return err
+	}
+
+	approvals.Lock()
+	req.noticeID = notice.ID
+	req.timer = time.AfterFunc(timeout, func() { expireApproval(m.Client, req.id, langCode) })
+	approvals.Unlock()
+	return nil
+}
+
+// expireApproval drops a request nobody approved in time and says so on its approval message.
+func expireApproval(client *tg.Client, id int64, langCode string) {
+	req := takeApproval(id)
+	if req == nil {
+		return
+	}
+	if _, err := client.EditMessage(req.chatID, req.noticeID, lang.GetString(langCode, "approval_expired")); err != nil {
+		logger.Debug("[approval] Failed to mark request %d as expired: %v", id, err)
+	}
+}
+
+// approvalCallback handles the Approve and Reject buttons. An approved request is played as if its
+// requester had just sent it; a rejected one is answered so the requester knows.
+func approvalCallback(c *callbackCtx) error {
+	id, _ := strconv.ParseInt(c.Arg(0), 10, 64)
+	req := takeApproval(id)
+	if req == nil {
+		c.Answer(lang.GetString(c.LangCode, "approval_gone"), true)
+		_, _ = c.Edit(lang.GetString(c.LangCode, "approval_expired"))
+		return nil
+	}
+
+	admin := ""
+	if c.Sender != nil {
+		admin = html.EscapeString(c.Sender.FirstName)
+	}
+	auditCB(c.CallbackQuery, "approval", strconv.FormatInt(req.userID, 10), c.Arg(1))
+
+	if c.Arg(1) != "y" {
+		_, err := c.Edit(fmt.Sprintf(lang.GetString(c.LangCode, "approval_rejected"), admin))
+		_, _ = c.Client.SendMessage(req.chatID, lang.GetString(c.LangCode, "approval_rejected_notice"), &tg.SendOptions{ReplyID: req.msgID})
+		return err
+	}
+
+	orig, err := c.Client.GetMessageByID(req.chatID, req.msgID)
+	if err != nil || orig == nil {
+		_, err = c.Edit(lang.GetString(c.LangCode, "approval_request_deleted"))
+		return err
+	}
+	// The press is answered before the request plays, which runs on this dispatcher worker like a /play.
+	c.Answer("", false)
+	_, err = c.Edit(fmt.Sprintf(lang.GetString(c.LangCode, "approval_approved"), admin))
+	if err := handlePlay(orig, req.isVideo, true); err != nil && err != tg.EndGroup {
+		logger.Warn("[approval] Failed to play approved request %d in %d: %v", req.id, req.chatID, err)
+	}
+	return err
+}
+
+// approvalHandler handles the /approval command.
+// "/approval on" makes members' requests wait for an admin to approve them, and "/approval off" stops it.
+func approvalHandler(m *tg.NewMessage) error {
+	return toggleSetting{
+		action: "approvalmode",
+		key:    "approval",
+		get:    db.Instance.GetApprovalMode,
+		set:    db.Instance.SetApprovalMode,
+		usageArgs: func() []any {
+			return []any{cache.SecToMin(int(config.Get().ApprovalTimeout)), config.Get().ApprovalPending}
+		},
+	}.handle(m)
+}
@@ -0,0 +1,223 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core/audio"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/workspace"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// maxEditSize caps the size of a file /trim and /convert accept.
+	maxEditSize = 50 << 20
+	// maxEditDuration caps the length, in seconds, of a file /trim and /convert accept.
+	maxEditDuration = 20 * 60
+	// editCooldown is how long a user must wait between two edits.
+	editCooldown = 30 * time.Second
+	// editTimeout caps how long downloading, editing and sending one file may take.
+	editTimeout = 3 * time.Minute
+)
+
+var editRateLimit = cache.NewCache[time.Time](editCooldown)
+
+// editSlots limits how many edits run at the same time, since each one runs ffmpeg.
+var editSlots = make(chan struct{}, 2)
+
+// audioEdit is one /trim or /convert request, run by runAudioEdit.
+type audioEdit struct {
+	// suffix is added to the name of the output file, such as "_trim".
+	suffix string
+	// format is the output format; the zero value keeps the input's format if it is one of audio.Formats.
+	format audio.Format
+	// check, if set, gets the input's length in seconds (0 if unknown) and returns the text of a refusal.
+	check func(duration int) string
+	run   func(ctx context.Context, in, out string) error
+}
+
+// trimHandler handles the /trim command.
+// "/trim 0:30-1:45", in reply to an audio file, sends back that part of the file.
+func trimHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
+
+	start, end, err := audio.ParseRange(m.Args())
+	if err != nil {
+		_, err := m.Reply(lang.GetString(langCode, "trim_usage"))
+		return err
+	}
+	return runAudioEdit(m, langCode, audioEdit{
+		suffix: "_trim",
+		check: func(duration int) string {
+			if duration > 0 && start >= duration {
+				return fmt.Sprintf(lang.GetString(langCode, "trim_out_of_range"), cache.SecToMin(duration))
+			}
+			return ""
+		},
+		run: func(ctx context.Context, in, out string) error {
+			return audio.Trim(ctx, in, out, start, end, audio.Formats[strings.TrimPrefix(filepath.Ext(out), ".")])
+		},
+	})
+}
+
+// convertHandler handles the /convert command.
+// "/convert mp3|ogg|wav", in reply to an audio file, sends the file back in that format.
+func convertHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
+
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(m.Args()), "."))
+	format, ok := audio.Formats[name]
+	if !ok {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "convert_usage"), strings.Join(audio.FormatNames(), "|")))
+		return err
+	}
+	return runAudioEdit(m, langCode, audioEdit{
+		format: format,
+		run: func(ctx context.Context, in, out string) error {
+			return audio.Convert(ctx, in, out, format)
+		},
+	})
+}
+
+// editSource returns the message with the file m replies to, or the lang key explaining why it cannot be edited.
+func editSource(m *tg.NewMessage) (*tg.NewMessage, string) {
+	if !m.IsReply() {
+		return nil, "edit_reply_audio"
+	}
+	src, err := m.GetReplyMessage()
+	if err != nil || src == nil || !src.IsMedia() || src.File == nil {
+		return nil, "edit_reply_audio"
+	}
+	if src.Audio() == nil && src.Voice() == nil && src.Video() == nil && src.Document() == nil {
+		return nil, "edit_reply_audio"
+	}
+	return src, ""
+}
+
+// runAudioEdit downloads the file m replies to into a scratch directory, runs edit on it and replies with the
+// result. The scratch directory is removed afterwards, whatever happens.
+func runAudioEdit(m *tg.NewMessage, langCode string, edit audioEdit) error {
+	src, reason := editSource(m)
+	if reason != "" {
+		_, err := m.Reply(lang.GetString(langCode, reason))
+		return err
+	}
+	if src.File.Size > maxEditSize {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "edit_too_large"), maxEditSize>>20))
+		return err
+	}
+	duration := cache.GetFileDur(src)
+	if duration > maxEditDuration {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "edit_too_long"), cache.SecToMin(maxEditDuration)))
+		return err
+	}
+	if edit.check != nil {
+		if text := edit.check(duration); text != "" {
+			_, err := m.Reply(text)
+			return err
+		}
+	}
+
+	rateKey := strconv.FormatInt(m.SenderID(), 10)
+	if lastUsed, ok := editRateLimit.Get(rateKey); ok {
+		if passed := time.Since(lastUsed); passed < editCooldown {
+			_, err := replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "edit_cooldown"), int((editCooldown-passed).Seconds())+1), true)
+			return err
+		}
+	}
+	select {
+	case editSlots <- struct{}{}:
+		defer func() { <-editSlots }()
+	default:
+		_, err := replyTransient(m, lang.GetString(langCode, "edit_busy"), true)
+		return err
+	}
+	editRateLimit.Set(rateKey, time.Now())
+
+	status, err := m.Reply(lang.GetString(langCode, "edit_working"))
+	if err != nil {
+		return err
+	}
+
+	ws, err := workspace.New("edit")
+	if errors.Is(err, workspace.ErrBudget) {
+		_, err = status.Edit(lang.GetString(langCode, "temp_space_full"))
+		return err
+	} else if err != nil {
+		_, err = status.Edit(userError(m, langCode, lang.GetString(langCode, "edit_failed"), err))
+		return err
+	}
+	defer ws.Close()
+
+	ctx, cancel := context.WithTimeout(requestCtx(m), editTimeout)
+	defer cancel()
+
+	inName := filepath.Base(src.File.Name)
+	if inName == "." || inName == "/" || inName == "" {
+		inName = "audio" + src.File.Ext
+	}
+	in, err := src.Download(&tg.DownloadOptions{FileName: ws.Path("in" + filepath.Ext(inName)), Ctx: ctx})
+	if err != nil {
+		_, err = status.Edit(userError(m, langCode, lang.GetString(langCode, "edit_download_failed"), err))
+		return err
+	}
+
+	format := edit.format
+	if format.Ext == "" {
+		// A trim keeps the input's format when it can write it.
+		var ok bool
+		if format, ok = audio.Formats[strings.ToLower(strings.TrimPrefix(filepath.Ext(inName), "."))]; !ok {
+			format = audio.Formats["mp3"]
+		}
+	}
+	outName := strings.TrimSuffix(inName, filepath.Ext(inName)) + edit.suffix + "." + format.Ext
+	out := ws.Path("out." + format.Ext)
+
+	if err := edit.run(ctx, in, out); err != nil {
+		var text string
+		switch {
+		case errors.Is(err, audio.ErrUnsupported):
+			text = lang.GetString(langCode, "edit_unsupported")
+		case errors.Is(err, audio.ErrBadRange):
+			text = lang.GetString(langCode, "trim_usage")
+		case errors.Is(err, context.DeadlineExceeded):
+			text = lang.GetString(langCode, "edit_timeout")
+		default:
+			text = userError(m, langCode, lang.GetString(langCode, "edit_failed"), err)
+		}
+		_, err = status.Edit(text)
+		return err
+	}
+	if info, err := os.Stat(out); err != nil || info.Size() == 0 {
+		_, err = status.Edit(lang.GetString(langCode, "edit_empty"))
+		return err
+	}
+
+	if _, err := replyFile(m, langCode, status, out, &tg.MediaOptions{FileName: outName, MimeType: format.Mime}); err != nil {
+		return err
+	}
+	_, err = status.Delete()
+	return err
+}
@@ -0,0 +1,141 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+func init() {
+	registerCallback("af", &callbackRoute{
+		Allow: func(cb *tg.CallbackQuery) string {
+			if !adminModeCB(cb) {
+				return "filter_not_authorized"
+			}
+			return ""
+		},
+		Token:  func(cb *tg.CallbackQuery) string { return filterToken(cb.ChannelID()) },
+		Handle: audioFilterCallback,
+	})
+}
+
+// filterToken identifies the active filter so that menus rendered for another filter become stale.
+func filterToken(chatID int64) string {
+	if name := vc.Calls.Filter(chatID); name != "" {
+		return name
+	}
+	return "none"
+}
+
+// audioFilterHandler handles the /filters command.
+// With an argument it applies that preset directly; otherwise it shows the preset menu.
+func audioFilterHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	if !cache.ChatCache.IsActive(chatID) || cache.ChatCache.GetPlayingTrack(chatID) == nil {
+		_, err := m.Reply(lang.GetString(langCode, "no_track_playing"))
+		return err
+	}
+
+	if name := strings.ToLower(strings.TrimSpace(m.Args())); name != "" {
+		if err := vc.Calls.ApplyFilter(chatID, name); err != nil {
+			_, _ = m.Reply(audioFilterError(langCode, err))
+			return nil
+		}
+	}
+
+	text, markup := buildFilterMenu(langCode, chatID)
+	_, err := m.Reply(text, &tg.SendOptions{ReplyMarkup: markup})
+	return err
+}
+
+// audioFilterCallback applies the preset picked from the menu and re-renders it.
+func audioFilterCallback(c *callbackCtx) error {
+	chatID := c.ChannelID()
+	if !cache.ChatCache.IsActive(chatID) || cache.ChatCache.GetPlayingTrack(chatID) == nil {
+		c.Answer(lang.GetString(c.LangCode, "no_track_playing"), true)
+		return nil
+	}
+
+	if err := vc.Calls.ApplyFilter(chatID, c.Arg(0)); err != nil {
+		c.Answer(audioFilterError(c.LangCode, err), true)
+		return nil
+	}
+
+	c.Answer(fmt.Sprintf(lang.GetString(c.LangCode, "filters_applied"), filterLabel(c.LangCode, c.Arg(0))), false)
+	text, markup := buildFilterMenu(c.LangCode, chatID)
+	_, err := c.Edit(text, &tg.SendOptions{ReplyMarkup: markup})
+	return err
+}
+
+// buildFilterMenu renders the preset menu with the active preset highlighted.
+func buildFilterMenu(langCode string, chatID int64) (string, tg.ReplyMarkup) {
+	active := filterToken(chatID)
+	token := active
+
+	names := []string{"none"}
+	for _, preset := range vc.FilterPresets {
+		names = append(names, preset.Name)
+	}
+
+	kb := tg.NewKeyboard()
+	var row []tg.KeyboardButton
+	for _, name := range names {
+		label := filterLabel(langCode, name)
+		if name == active {
+			label = "✅ " + label
+		}
+		row = append(row, tg.Button.Data(label, callbackData("af", token, name)))
+		if len(row) == 2 {
+			kb.AddRow(row...)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		kb.AddRow(row...)
+	}
+	kb.AddRow(core.CloseBtn)
+
+	text := fmt.Sprintf(lang.GetString(langCode, "filters_menu"), filterLabel(langCode, active))
+	return text, kb.Build()
+}
+
+// filterLabel returns the display name of a preset, falling back to its key when no translation exists.
+func filterLabel(langCode, name string) string {
+	key := "filter_name_" + name
+	if label := lang.GetString(langCode, key); label != key {
+		return label
+	}
+	return name
+}
+
+// audioFilterError maps ApplyFilter errors to a reply.
+func audioFilterError(langCode string, err error) string {
+	if text, ok := playbackStateText(langCode, err); ok {
+		return text
+	}
+	switch {
+	case errors.Is(err, vc.ErrUnknownFilter):
+		return lang.GetString(langCode, "filters_unknown")
+	default:
+		return fmt.Sprintf(lang.GetString(langCode, "filters_error"), err.Error())
+	}
+}
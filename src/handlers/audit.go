@@ -0,0 +1,156 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// auditPageSize is the number of audit entries shown per page.
+const auditPageSize = 10
+
+func init() {
+	registerCallback("au", &callbackRoute{
+		Allow:  permitCallback(requireOwner),
+		Handle: auditPageCallback,
+	})
+}
+
+// writeAudit stores entry in the background so that the handler is never held up. Failures are only logged.
+func writeAudit(entry db.AuditEntry) {
+	entry.Time = time.Now().Unix()
+	go func() {
+		ctx, cancel := db.Ctx()
+		defer cancel()
+		if err := db.Instance.AddAudit(ctx, entry); err != nil {
+			logger.Warn("[audit] Failed to record %s by %d: %v", entry.Action, entry.ActorID, err)
+		}
+	}()
+}
+
+// audit records an administrative action taken by the sender of m.
+func audit(m *tg.NewMessage, action, target, params string) {
+	name := ""
+	if m.Sender != nil {
+		name = m.Sender.FirstName
+	}
+	writeAudit(db.AuditEntry{
+		ActorID: m.SenderID(), Actor: name, Action: action,
+		ChatID: m.ChannelID(), Target: target, Params: params,
+	})
+}
+
+// auditCB records an administrative action taken by pressing a button.
+func auditCB(cb *tg.CallbackQuery, action, target, params string) {
+	name := ""
+	if cb.Sender != nil {
+		name = cb.Sender.FirstName
+	}
+	writeAudit(db.AuditEntry{
+		ActorID: cb.SenderID, Actor: name, Action: action,
+		ChatID: cb.ChannelID(), Target: target, Params: params,
+	})
+}
+
+// auditHandler handles the /audit command.
+// "/audit [user_id] [action]" pages through the most recent administrative actions, optionally filtered.
+func auditHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	var filter db.AuditFilter
+	for _, arg := range strings.Fields(m.Args()) {
+		if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+			filter.ActorID = id
+		} else {
+			filter.Action = strings.ToLower(arg)
+		}
+	}
+
+	text, markup := buildAuditPage(langCode, filter, 0)
+	_, err := m.Reply(text, &tg.SendOptions{ReplyMarkup: markup})
+	return err
+}
+
+// auditPageCallback switches the audit message to another page.
+func auditPageCallback(c *callbackCtx) error {
+	page, _ := strconv.Atoi(c.Arg(0))
+	actorID, _ := strconv.ParseInt(c.Arg(1), 10, 64)
+	text, markup := buildAuditPage(c.LangCode, db.AuditFilter{ActorID: actorID, Action: c.Arg(2)}, page)
+	_, err := c.Edit(text, &tg.SendOptions{ReplyMarkup: markup})
+	return err
+}
+
+// buildAuditPage renders one page of audit entries along with its navigation keyboard.
+func buildAuditPage(langCode string, filter db.AuditFilter, page int) (string, tg.ReplyMarkup) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	total, err := db.Instance.CountAudit(ctx, filter)
+	if err != nil {
+		return fmt.Sprintf(lang.GetString(langCode, "audit_error"), html.EscapeString(err.Error())), nil
+	}
+	if total == 0 {
+		return lang.GetString(langCode, "audit_empty"), nil
+	}
+
+	pages := int((total + auditPageSize - 1) / auditPageSize)
+	page = max(0, min(page, pages-1))
+	entries, err := db.Instance.GetAudit(ctx, filter, int64(page*auditPageSize), auditPageSize)
+	if err != nil {
+		return fmt.Sprintf(lang.GetString(langCode, "audit_error"), html.EscapeString(err.Error())), nil
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(lang.GetString(langCode, "audit_header"), total))
+	for _, e := range entries {
+		b.WriteString(fmt.Sprintf(lang.GetString(langCode, "audit_entry"),
+			time.Unix(e.Time, 0).UTC().Format("2006-01-02 15:04"),
+			html.EscapeString(e.Action),
+			html.EscapeString(e.Actor), e.ActorID,
+			e.ChatID,
+		))
+		if e.Target != "" {
+			b.WriteString(" → <code>" + html.EscapeString(e.Target) + "</code>")
+		}
+		if e.Params != "" {
+			b.WriteString(" <i>" + html.EscapeString(truncate(e.Params, 80)) + "</i>")
+		}
+		b.WriteString("\n")
+	}
+
+	actor := ""
+	if filter.ActorID != 0 {
+		actor = strconv.FormatInt(filter.ActorID, 10)
+	}
+	kb := tg.NewKeyboard()
+	if pages > 1 {
+		var nav []tg.KeyboardButton
+		if page > 0 {
+			nav = append(nav, tg.Button.Data("« Prev", callbackData("au", "", strconv.Itoa(page-1), actor, filter.Action)))
+		}
+		nav = append(nav, tg.Button.Data(fmt.Sprintf("%d/%d", page+1, pages), callbackData("au", "", strconv.Itoa(page), actor, filter.Action)))
+		if page < pages-1 {
+			nav = append(nav, tg.Button.Data("Next »", callbackData("au", "", strconv.Itoa(page+1), actor, filter.Action)))
+		}
+		kb.AddRow(nav...)
+	}
+	return b.String(), kb.AddRow(core.CloseBtn).Build()
+}
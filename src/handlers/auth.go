@@ -11,6 +11,7 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"strconv"
 
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/lang"
@@ -109,6 +110,7 @@ func addAuthHandler(m *telegram.NewMessage) error {
 		return nil
 	}
 
+	audit(m, "addauth", strconv.FormatInt(userID, 10), "")
 	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "user_authed"), userID))
 	return err
 }
@@ -143,6 +145,7 @@ func removeAuthHandler(m *telegram.NewMessage) error {
 		return nil
 	}
 
+	audit(m, "removeauth", strconv.FormatInt(userID, 10), "")
 	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "user_unauthed"), userID))
 	return err
 }
@@ -0,0 +1,54 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"ashokshau/tgmusic/src/core/cleaner"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// scheduleDelete deletes the given messages from a chat once the chat's clean mode delay has passed.
+// Failures are ignored, as the messages may already have been removed by a user or another bot.
+func scheduleDelete(client *telegram.Client, chatID int64, msgIDs ...int32) {
+	cleaner.ScheduleChat(client, chatID, msgIDs...)
+}
+
+// replyTransient sends a reply that is deleted after the chat's clean mode delay.
+// If withCommand is true, the triggering command message is deleted along with it.
+// It is meant for short-lived notices only; persistent messages such as now-playing cards should use m.Reply.
+func replyTransient(m *telegram.NewMessage, text string, withCommand bool, opts ...*telegram.SendOptions) (*telegram.NewMessage, error) {
+	reply, err := m.Reply(text, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int32{reply.ID}
+	if withCommand && !m.IsPrivate() {
+		ids = append(ids, m.ID)
+	}
+	scheduleDelete(m.Client, m.ChannelID(), ids...)
+	return reply, nil
+}
+
+// editTransient edits a message and schedules it for deletion after the chat's clean mode delay.
+// If cmd is not nil, the command message that triggered it is deleted as well.
+func editTransient(msg *telegram.NewMessage, cmd *telegram.NewMessage, text string, opts ...*telegram.SendOptions) (*telegram.NewMessage, error) {
+	edited, err := msg.Edit(text, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int32{msg.ID}
+	if cmd != nil && !cmd.IsPrivate() {
+		ids = append(ids, cmd.ID)
+	}
+	scheduleDelete(msg.Client, msg.ChannelID(), ids...)
+	return edited, nil
+}
@@ -0,0 +1,201 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"ashokshau/tgmusic/src/core/blockwords"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// chatBlockWordsMax is how many keywords a chat may block.
+	chatBlockWordsMax = 50
+	// globalBlockWordsMax is how many keywords the owners may block in every chat.
+	globalBlockWordsMax = 200
+	// blockWordMaxLen is the longest keyword that can be blocked, in characters.
+	blockWordMaxLen = 64
+)
+
+// blockList is a list of blocked keywords that the block commands edit: a chat's own or the global one.
+type blockList struct {
+	get    func(ctx context.Context) []string
+	set    func(ctx context.Context, words []string) error
+	max    int
+	action string // action is the audit action recorded for changes to the list.
+}
+
+// chatBlockList returns the blocked keywords of the chat m was sent in.
+func chatBlockList(m *tg.NewMessage) blockList {
+	chatID := m.ChannelID()
+	return blockList{
+		get: func(ctx context.Context) []string {
+			return db.Instance.GetBlockedWords(ctx, chatID)
+		},
+		set: func(ctx context.Context, words []string) error {
+			return db.Instance.SetBlockedWords(ctx, chatID, words)
+		},
+		max:    chatBlockWordsMax,
+		action: "blockword",
+	}
+}
+
+// globalBlockList returns the keywords blocked in every chat.
+func globalBlockList(m *tg.NewMessage) blockList {
+	botID := m.Client.Me().ID
+	return blockList{
+		get: func(ctx context.Context) []string {
+			return db.Instance.GetGlobalBlockedWords(ctx, botID)
+		},
+		set: func(ctx context.Context, words []string) error {
+			return db.Instance.SetGlobalBlockedWords(ctx, botID, words)
+		},
+		max:    globalBlockWordsMax,
+		action: "gblockword",
+	}
+}
+
+// titleBlocked reports whether title matches a keyword blocked in chatID or globally.
+func titleBlocked(client *tg.Client, chatID int64, title string) bool {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return blockwords.Match(title, db.Instance.GetBlockedWords(ctx, chatID)) ||
+		blockwords.Match(title, db.Instance.GetGlobalBlockedWords(ctx, client.Me().ID))
+}
+
+// rejectBlocked tells the user that a track was refused because its title matches a blocked keyword, and
+// reports whether it did. The keyword itself is not named.
+func rejectBlocked(m, updater *tg.NewMessage, chatID int64, title, langCode string) bool {
+	if !titleBlocked(m.Client, chatID, title) {
+		return false
+	}
+	_, _ = editTransient(updater, m, lang.GetString(langCode, "blockword_rejected"))
+	return true
+}
+
+// blockWordHandler handles the /blockword command, which blocks tracks whose title contains a keyword.
+func blockWordHandler(m *tg.NewMessage) error {
+	return addBlockWord(m, chatBlockList(m))
+}
+
+// unblockWordHandler handles the /unblockword command.
+func unblockWordHandler(m *tg.NewMessage) error {
+	return removeBlockWord(m, chatBlockList(m))
+}
+
+// blockWordsHandler handles the /blockwords command, which lists the chat's blocked keywords.
+func blockWordsHandler(m *tg.NewMessage) error {
+	return listBlockWords(m, chatBlockList(m))
+}
+
+// globalBlockWordHandler handles the /gblockword command, which blocks a keyword in every chat.
+func globalBlockWordHandler(m *tg.NewMessage) error {
+	return addBlockWord(m, globalBlockList(m))
+}
+
+// globalUnblockWordHandler handles the /gunblockword command.
+func globalUnblockWordHandler(m *tg.NewMessage) error {
+	return removeBlockWord(m, globalBlockList(m))
+}
+
+// globalBlockWordsHandler handles the /gblockwords command, which lists the keywords blocked in every chat.
+func globalBlockWordsHandler(m *tg.NewMessage) error {
+	return listBlockWords(m, globalBlockList(m))
+}
+
+// addBlockWord adds the keyword given to m to list.
+func addBlockWord(m *tg.NewMessage, list blockList) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
+
+	word := blockwords.Normalize(m.Args())
+	switch {
+	case word == "":
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "blockword_usage"), blockWordMaxLen))
+		return err
+	case utf8.RuneCountInString(word) > blockWordMaxLen:
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "blockword_too_long"), blockWordMaxLen))
+		return err
+	}
+
+	words := list.get(ctx)
+	if slices.Contains(words, word) {
+		_, err := replyTransient(m, lang.GetString(langCode, "blockword_exists"), true)
+		return err
+	}
+	if len(words) >= list.max {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "blockword_full"), list.max))
+		return err
+	}
+	if err := list.set(ctx, append(slices.Clone(words), word)); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "blockword_error"), html.EscapeString(err.Error())))
+		return err
+	}
+
+	audit(m, list.action, "", "add")
+	_, err := replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "blockword_added"), len(words)+1, list.max), true)
+	return err
+}
+
+// removeBlockWord removes the keyword given to m from list.
+func removeBlockWord(m *tg.NewMessage, list blockList) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
+
+	word := blockwords.Normalize(m.Args())
+	if word == "" {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "blockword_usage"), blockWordMaxLen))
+		return err
+	}
+	words := list.get(ctx)
+	i := slices.Index(words, word)
+	if i < 0 {
+		_, err := replyTransient(m, lang.GetString(langCode, "blockword_missing"), true)
+		return err
+	}
+	if err := list.set(ctx, slices.Delete(slices.Clone(words), i, i+1)); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "blockword_error"), html.EscapeString(err.Error())))
+		return err
+	}
+
+	audit(m, list.action, "", "remove")
+	_, err := replyTransient(m, lang.GetString(langCode, "blockword_removed"), true)
+	return err
+}
+
+// listBlockWords lists the keywords in list, hidden behind spoilers.
+func listBlockWords(m *tg.NewMessage, list blockList) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
+
+	words := list.get(ctx)
+	if len(words) == 0 {
+		_, err := m.Reply(lang.GetString(langCode, "blockwords_empty"))
+		return err
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "blockwords_header"), len(words), list.max))
+	for i, word := range words {
+		sb.WriteString(fmt.Sprintf("%d. <tg-spoiler>%s</tg-spoiler>\n", i+1, html.EscapeString(word)))
+	}
+	_, err := m.Reply(sb.String())
+	return err
+}
@@ -9,189 +9,616 @@
 package handlers
 
 import (
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/broadcast"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/counters"
 	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/core/shutdown"
+	"ashokshau/tgmusic/src/lang"
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
-	"sync"
 	"sync/atomic"
 	"time"
 
 	tg "github.com/amarnathcjd/gogram/telegram"
 )
 
-var (
-	broadcastCancelFlag atomic.Bool
-	broadcastInProgress atomic.Bool
+const (
+	// maxBroadcastListSize is the largest target list, in bytes, that -list accepts.
+	maxBroadcastListSize = 1 << 20
+	// broadcastSaveEvery is how often the counts of a running broadcast are saved for a resume.
+	broadcastSaveEvery = 5 * time.Second
+	// broadcastSendEstimate is roughly how long one send takes, for the estimate of -dryrun.
+	broadcastSendEstimate = 200 * time.Millisecond
 )
 
+var broadcastLogger = logging.For("broadcast")
+
+// broadcasts tracks the running broadcast.
+var broadcasts broadcast.Manager
+
+func init() {
+	registerCallback("bc", &callbackRoute{
+		Allow:  permitCallback(requireSudo),
+		Token:  func(*tg.CallbackQuery) string { return broadcasts.Token() },
+		Handle: cancelBroadcastCallback,
+	})
+
+	shutdown.Register("finish broadcast", shutdown.PriorityDrain, func(ctx context.Context) error {
+		if !broadcasts.Stop() {
+			return nil
+		}
+		return broadcasts.Wait(ctx)
+	})
+}
+
+// cancelBroadcastCallback handles the cancel button on the broadcast progress message.
+func cancelBroadcastCallback(c *callbackCtx) error {
+	broadcasts.Stop()
+	c.Answer(lang.GetString(c.LangCode, "broadcast_cancelled"), false)
+	return nil
+}
+
+// cancelBroadcastHandler handles the /cancelbroadcast command. It stops the running broadcast, or discards
+// the unfinished one left by a restart if none is running.
 func cancelBroadcastHandler(m *tg.NewMessage) error {
-	broadcastCancelFlag.Store(true)
-	_, _ = m.Reply("🚫 Broadcast cancelled.")
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
+
+	if !broadcasts.Stop() {
+		if job, err := db.Instance.GetBroadcastJob(ctx); err == nil && job != nil {
+			if err := db.Instance.DeleteBroadcastJob(ctx, job.ID); err != nil {
+				_, _ = m.Reply(lang.Format(langCode, "broadcast_resume_error", err.Error()))
+				return tg.EndGroup
+			}
+			audit(m, "discardbroadcast", fmt.Sprintf("message %d", job.SourceMsg), job.Args)
+			_, _ = m.Reply(lang.GetString(langCode, "broadcast_resume_discarded"))
+			return tg.EndGroup
+		}
+	}
+
+	audit(m, "cancelbroadcast", "", "")
+	_, _ = m.Reply(lang.GetString(langCode, "broadcast_cancelled"))
 	return tg.EndGroup
 }
 
-func broadcastHandler(m *tg.NewMessage) error {
-	if broadcastInProgress.Load() {
-		_, _ = m.Reply("❗ A broadcast is already in progress. Please wait for it to complete or cancel it with /cancelbroadcast")
-		return tg.EndGroup
-	}
+// broadcastOptions are the flags of a /broadcast command.
+type broadcastOptions = broadcast.Options
 
-	broadcastInProgress.Store(true)
-	defer broadcastInProgress.Store(false)
+// parseBroadcastArgs parses the arguments of a /broadcast command. It returns the lang key of the error to
+// show for an invalid flag.
+func parseBroadcastArgs(raw string) (broadcastOptions, string) {
+	return broadcast.ParseArgs(raw, int(config.Get().BroadcastWorkers))
+}
 
+// broadcastHandler handles the /broadcast command, which sends the replied message, or the text after the
+// flags if it replies to none, to every chat and user, now or at the time given with -schedule.
+func broadcastHandler(m *tg.NewMessage) error {
 	ctx, cancel := db.Ctx()
 	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
 
-	reply, err := m.GetReplyMessage()
-	if err != nil {
-		_, _ = m.Reply("❗ Reply to a message to broadcast.\nExample:\n`/broadcast -copy -limit 100 -delay 2s optional preview text`")
+	if strings.TrimSpace(m.Args()) == "" {
+		key := "broadcast_no_flags"
+		if !m.IsReply() {
+			key = "broadcast_no_reply"
+		}
+		_, _ = m.Reply(lang.GetString(langCode, key))
 		return tg.EndGroup
 	}
 
-	args := strings.Fields(m.Args())
-	if len(args) == 0 {
-		_, _ = m.Reply("Provide flags.\nExample: `/broadcast -copy -limit 50 -delay 1s`")
+	opts, errKey := parseBroadcastArgs(m.Args())
+	if errKey != "" {
+		_, _ = m.Reply(lang.GetString(langCode, errKey))
 		return tg.EndGroup
 	}
 
-	copyMode := false
-	noChats := false
-	noUsers := false
-	limit := 0
-	delay := time.Duration(0)
-
-	for _, a := range args {
-		switch {
-		case a == "-copy":
-			copyMode = true
-		case a == "-nochat" || a == "-nochats":
-			noChats = true
-		case a == "-nouser" || a == "-nousers":
-			noUsers = true
-
-		case strings.HasPrefix(a, "-limit"):
-			val := strings.TrimPrefix(a, "-limit")
-			val = strings.TrimSpace(val)
-			n, err := strconv.Atoi(val)
-			if err != nil || n <= 0 {
-				_, _ = m.Reply("❗ Invalid limit value. Example: `-limit 100`")
-				return tg.EndGroup
-			}
-			limit = n
-
-		case strings.HasPrefix(a, "-delay"):
-			val := strings.TrimPrefix(a, "-delay")
-			val = strings.TrimSpace(val)
-			d, err := time.ParseDuration(val)
-			if err != nil {
-				_, _ = m.Reply("❗ Invalid delay. Example: `-delay 2s`")
-				return tg.EndGroup
-			}
-			delay = d
+	var reply *tg.NewMessage
+	if m.IsReply() {
+		var err error
+		if reply, err = m.GetReplyMessage(); err != nil {
+			_, _ = m.Reply(lang.GetString(langCode, "broadcast_no_reply"))
+			return tg.EndGroup
 		}
+	} else if opts.Text == "" {
+		_, _ = m.Reply(lang.GetString(langCode, "broadcast_no_reply"))
+		return tg.EndGroup
 	}
 
-	broadcastCancelFlag.Store(false)
-	chats, _ := db.Instance.GetAllChats(ctx)
-	users, _ := db.Instance.GetAllUsers(ctx)
+	if opts.DryRun {
+		dryRunBroadcast(m, reply, opts, langCode)
+		return tg.EndGroup
+	}
+	if opts.Schedule != "" {
+		return scheduleBroadcast(m, opts.Schedule, langCode)
+	}
+	runBroadcast(m, reply, opts, langCode)
+	return tg.EndGroup
+}
 
-	var targets []int64
-	if !noChats {
-		targets = append(targets, chats...)
+// dryRunBroadcast replies with what a /broadcast with opts would do: how many groups and users it would
+// reach and roughly how long it would take. Nothing is sent, and the running broadcast is not disturbed.
+func dryRunBroadcast(m, reply *tg.NewMessage, opts broadcastOptions, langCode string) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	sel, errKey := broadcastTargets(ctx, reply, opts)
+	if errKey != "" {
+		_, _ = m.Reply(lang.GetString(langCode, errKey))
+		return
 	}
-	if !noUsers {
-		targets = append(targets, users...)
+	content, targets := sel.content, sel.targets
+
+	groups := 0
+	for _, id := range targets {
+		if id < 0 {
+			groups++
+		}
+	}
+	total := len(targets)
+	if opts.Limit > 0 && opts.Limit < total {
+		total = opts.Limit
 	}
+	// The broadcast goes as fast as the slower of its workers and its rate limit allow.
+	rounds := (total + opts.Workers - 1) / opts.Workers
+	estimate := max(time.Duration(rounds)*(broadcastSendEstimate+opts.Delay),
+		time.Duration(float64(total)/broadcast.MaxRate*float64(time.Second)))
+
+	text := lang.Format(langCode, "broadcast_dryrun", groups, len(targets)-groups, total, broadcastMode(content, opts, langCode),
+		opts.Delay, opts.Workers, cache.SecToMin(int(estimate.Seconds())))
+	text += sel.notes(langCode)
+	if content != nil && broadcastProtected(content) {
+		text += lang.GetString(langCode, "broadcast_dryrun_protected")
+	}
+	audit(m, "dryrunbroadcast", broadcastSubject(content), m.Args())
+	_, _ = m.Reply(text)
+}
 
-	if len(targets) == 0 {
-		_, _ = m.Reply("❗ No targets found.")
-		return tg.EndGroup
+// broadcastProtected reports whether msg cannot be forwarded or copied: it is a service message, or it
+// comes from a chat with protected content.
+func broadcastProtected(msg *tg.NewMessage) bool {
+	switch {
+	case msg.Message == nil || msg.Action != nil:
+		return true
+	case msg.Message.Noforwards:
+		return true
+	case msg.Channel != nil && msg.Channel.Noforwards:
+		return true
+	case msg.Chat != nil && msg.Chat.Noforwards:
+		return true
 	}
+	return false
+}
 
-	if limit > 0 && limit < len(targets) {
-		targets = targets[:limit]
+// broadcastMode returns how a broadcast of content, or of the text of opts if content is nil, is sent.
+func broadcastMode(content *tg.NewMessage, opts broadcastOptions, langCode string) string {
+	switch {
+	case content == nil:
+		return lang.GetString(langCode, "broadcast_mode_text")
+	case opts.Copy:
+		return lang.GetString(langCode, "broadcast_mode_copy")
 	}
+	return lang.GetString(langCode, "broadcast_mode_forward")
+}
 
-	sentMsg, _ := m.Reply(fmt.Sprintf(
-		"🚀 <b>Broadcast Started</b>\nTargets: %d\nMode: %s\nDelay: %v\n\nSend <code>/cancelbroadcast</code> to stop.",
-		len(targets),
-		map[bool]string{true: "Copy", false: "Forward"}[copyMode],
-		delay,
-	))
+// broadcastSubject describes what a broadcast sends, for the audit log.
+func broadcastSubject(content *tg.NewMessage) string {
+	if content == nil {
+		return "text"
+	}
+	return fmt.Sprintf("message %d", content.ID)
+}
 
-	var success int32
-	var failed int32
+// runBroadcast sends reply to the targets selected by opts, reporting the progress as a reply to m, the
+// /broadcast command. With -list, reply is the file of target IDs and the message it replies to is sent.
+// If there is no message to send, the text of opts is. Only one broadcast runs at a time.
+func runBroadcast(m, reply *tg.NewMessage, opts broadcastOptions, langCode string) {
+	runCtx, ok := broadcasts.Begin(shutdown.Context())
+	if !ok {
+		_, _ = m.Reply(lang.GetString(langCode, "broadcast_in_progress"))
+		return
+	}
+	defer broadcasts.End()
+	startBroadcast(runCtx, m, reply, opts, langCode)
+}
 
-	workers := 20
-	jobs := make(chan int64, workers)
-	wg := sync.WaitGroup{}
+// startBroadcast selects the targets of a broadcast and sends it. The caller must have claimed the manager,
+// and runCtx is the context that begin returned.
+func startBroadcast(runCtx context.Context, m, reply *tg.NewMessage, opts broadcastOptions, langCode string) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	sel, errKey := broadcastTargets(ctx, reply, opts)
+	if errKey != "" {
+		_, _ = m.Reply(lang.GetString(langCode, errKey))
+		return
+	}
+	audit(m, "broadcast", broadcastSubject(sel.content), m.Args())
 
-	worker := func() {
-		for id := range jobs {
-			if broadcastCancelFlag.Load() {
-				atomic.AddInt32(&failed, 1)
-				continue
-			}
+	if len(sel.targets) == 0 {
+		_, _ = m.Reply(lang.GetString(langCode, "broadcast_no_targets"))
+		return
+	}
 
-			for {
-				_, errSend := reply.ForwardTo(id, &tg.ForwardOptions{
-					Noforwards: copyMode,
-				})
+	if opts.Limit > 0 && opts.Limit < len(sel.targets) {
+		sel.targets = sel.targets[:opts.Limit]
+	}
 
-				if errSend == nil {
-					atomic.AddInt32(&success, 1)
-					break
-				}
+	job := &db.BroadcastJob{
+		ChatID:   m.ChannelID(),
+		UserID:   m.SenderID(),
+		Args:     m.Args(),
+		LangCode: langCode,
+		Targets:  sel.targets,
+	}
+	if sel.content != nil {
+		job.SourceChat, job.SourceMsg = sel.content.ChannelID(), sel.content.ID
+	}
+	if err := db.Instance.SaveBroadcastJob(ctx, job); err != nil {
+		broadcastLogger.Warn("Failed to save the broadcast job, it cannot be resumed: %v", err)
+		job.ID = 0
+	}
+	sendBroadcast(runCtx, m, sel, job, opts, langCode)
+}
 
-				if wait := tg.GetFloodWait(errSend); wait > 0 {
-					logger.Warn("FloodWait %ds for chatID=%d", wait, id)
-					time.Sleep(time.Duration(wait) * time.Second)
-					continue
-				}
+// sendBroadcast sends the content of sel, or the text of opts with its buttons if it has none, to the
+// targets of sel, the part of job that is left, reporting the progress as a reply to m. Every target is
+// recorded in the saved job before it is sent to, so a resumed job skips it even if the bot stopped right
+// after; the job is removed once the summary is posted. A broadcast cut off by a shutdown keeps its job for
+// /resumebroadcast. The workers stop once ctx, the context of the broadcast, is cancelled.
+func sendBroadcast(ctx context.Context, m *tg.NewMessage, sel *broadcastSelection, job *db.BroadcastJob, opts broadcastOptions, langCode string) {
+	content, targets := sel.content, sel.targets
+	saved := job.ID != 0
+	begun := time.Now()
+	limiter := broadcast.NewLimiter()
+	mode := broadcastMode(content, opts, langCode)
+	started := lang.Plural(langCode, "broadcast_started", len(targets), len(targets), mode, opts.Delay, limiter.Rate(), opts.Workers)
+	if resumed := len(job.Targets) - len(targets) - sel.excluded; resumed > 0 {
+		started += lang.Format(langCode, "broadcast_resume_skipped", resumed)
+	}
+	started += sel.notes(langCode)
+	sentMsg, _ := m.Reply(started, &tg.SendOptions{
+		ReplyMarkup: tg.NewKeyboard().AddRow(tg.Button.Data(lang.GetString(langCode, "broadcast_cancel_button"), callbackData("bc", broadcasts.Token(), "cancel"))).Build(),
+	})
+
+	var progress broadcast.Progress
+	var pinned int32
+	var pinSkipped int32
+	var pruned int32
+
+	// totals returns the counts of the job, including those of the runs before a resume.
+	// Targets skipped for a shutdown are left for the resumed broadcast; those skipped for a cancel failed.
+	totals := func() db.BroadcastCounts {
+		failed := progress.Failed()
+		if shutdown.Context().Err() == nil {
+			failed += progress.Skipped()
+		}
+		return db.BroadcastCounts{
+			Success:    job.Success + progress.Sent(),
+			Failed:     job.Failed + failed,
+			Pinned:     job.Pinned + atomic.LoadInt32(&pinned),
+			PinSkipped: job.PinSkipped + atomic.LoadInt32(&pinSkipped),
+			Pruned:     job.Pruned + atomic.LoadInt32(&pruned),
+		}
+	}
+	// saveCounts stores the totals of the job.
+	saveCounts := func() {
+		if !saved {
+			return
+		}
+		dbCtx, dbCancel := db.Ctx()
+		defer dbCancel()
+		if err := db.Instance.SaveBroadcastCounts(dbCtx, job.ID, totals()); err != nil {
+			broadcastLogger.Warn("Failed to save the broadcast counts: %v", err)
+		}
+	}
+	// markDone records id in the saved job before it is sent to.
+	markDone := func(id int64) {
+		if !saved {
+			return
+		}
+		dbCtx, dbCancel := db.Ctx()
+		defer dbCancel()
+		if err := db.Instance.MarkBroadcastDone(dbCtx, job.ID, id); err != nil {
+			broadcastLogger.Warn("Failed to record chatID=%d in the broadcast job: %v", id, err)
+		}
+	}
 
-				atomic.AddInt32(&failed, 1)
-				logger.Warn("[Broadcast] chatID: %d error: %v", id, errSend)
-				break
+	stopSaving := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(broadcastSaveEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopSaving:
+				return
+			case <-ticker.C:
+				saveCounts()
 			}
-
-			if delay > 0 {
-				time.Sleep(delay)
+		}
+	}()
+
+	plan := broadcast.Plan{
+		Workers: opts.Workers,
+		Delay:   opts.Delay,
+		Limiter: limiter,
+		Start:   markDone,
+		Send: func(_ context.Context, id int64) error {
+			var sent *tg.NewMessage
+			var err error
+			if content != nil {
+				sent, err = content.ForwardTo(id, &tg.ForwardOptions{Noforwards: opts.Copy})
+			} else {
+				sent, err = m.Client.SendMessage(id, opts.Text, &tg.SendOptions{ReplyMarkup: opts.Buttons})
+			}
+			if err == nil && opts.Pin && id < 0 {
+				if pinBroadcast(m.Client, id, sent, opts.PinLoud) {
+					atomic.AddInt32(&pinned, 1)
+				} else {
+					atomic.AddInt32(&pinSkipped, 1)
+				}
 			}
+			return err
+		},
+		Flood: func(id int64, d time.Duration) {
+			broadcastLogger.Warn("FloodWait %s for chatID=%d, slowing down to %.0f msg/s", d, id, limiter.Rate())
+		},
+		Failed: func(id int64, err error) {
+			broadcastLogger.Warn("[Broadcast] chatID: %d error: %v", id, err)
+			if !opts.NoPrune && broadcast.IsDeadTarget(err) && pruneBroadcastTarget(id) {
+				atomic.AddInt32(&pruned, 1)
+			}
+		},
+	}
+	broadcast.Run(ctx, targets, plan, &progress)
+	close(stopSaving)
+	total := totals()
+	counters.Add(counters.Broadcasts, "runs", 1)
+	counters.Add(counters.Broadcasts, "sent", int64(progress.Sent()))
+	counters.Add(counters.Broadcasts, "failed", int64(total.Failed-job.Failed))
+
+	if saved && shutdown.Context().Err() != nil {
+		saveCounts()
+		_, _ = sentMsg.Edit(lang.Format(langCode, "broadcast_interrupted", total.Success, len(job.Targets)))
+		return
+	}
+
+	resultKey := "broadcast_complete"
+	if ctx.Err() != nil {
+		resultKey = "broadcast_complete_cancelled"
+	}
+	result := lang.Format(langCode, resultKey, len(job.Targets), total.Success, total.Failed, mode, opts.Delay)
+	if opts.Pin {
+		result += lang.Format(langCode, "broadcast_pin_summary", total.Pinned, total.PinSkipped)
+	}
+	if total.Pruned > 0 {
+		result += lang.Format(langCode, "broadcast_prune_summary", total.Pruned)
+	}
+	elapsed := time.Since(begun)
+	result += lang.Format(langCode, "broadcast_rate_summary", float64(progress.Sent())/max(elapsed.Seconds(), 1),
+		limiter.FloodWaited().Round(time.Second), opts.Workers)
+
+	_, _ = sentMsg.Edit(result)
+	if saved {
+		dbCtx, dbCancel := db.Ctx()
+		defer dbCancel()
+		if err := db.Instance.DeleteBroadcastJob(dbCtx, job.ID); err != nil {
+			broadcastLogger.Warn("Failed to remove the finished broadcast job: %v", err)
 		}
-		wg.Done()
 	}
+}
+
+// pruneBroadcastTarget marks the chat or user id that can no longer be sent to as unreachable, so later
+// broadcasts skip it until it uses the bot again, and reports whether it was marked. Its settings are kept.
+func pruneBroadcastTarget(id int64) bool {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	marked, err := db.Instance.MarkUnreachable(ctx, id, time.Now())
+	if err != nil {
+		broadcastLogger.Warn("Failed to prune chatID=%d: %v", id, err)
+		return false
+	}
+	if marked {
+		broadcastLogger.Info("Pruned chatID=%d, which can no longer be sent to", id)
+	}
+	return marked
+}
+
+// pinBroadcast pins the broadcast message sent to the group chatID, silently unless loud is set. It reports
+// whether the message was pinned; failures such as missing admin rights only skip the pin.
+func pinBroadcast(c *tg.Client, chatID int64, sent *tg.NewMessage, loud bool) bool {
+	if sent == nil {
+		return false
+	}
+	if _, err := c.PinMessage(chatID, sent.ID, &tg.PinOptions{Silent: !loud}); err != nil {
+		broadcastLogger.Debug("[Broadcast] Could not pin in chatID: %d: %v", chatID, err)
+		return false
+	}
+	return true
+}
+
+// broadcastSelection is what a broadcast sends and to whom.
+type broadcastSelection struct {
+	content   *tg.NewMessage // content is the message to send, or nil for a text broadcast.
+	targets   []int64        // targets are the chats and users to send to.
+	invalid   int            // invalid is how many lines of a -list file were not IDs.
+	inactive  int            // inactive is how many chats and users -active left out.
+	untracked int            // untracked is how many of those -active left out because their activity is unknown.
+	excluded  int            // excluded is how many targets the broadcast exclusion list left out.
+}
 
-	wg.Add(workers)
-	for i := 0; i < workers; i++ {
-		go worker()
+// notes returns the lines the start message and the -dryrun report add about the targets left out. The
+// number excluded is always shown, so it is clear the exclusion list was applied.
+func (s *broadcastSelection) notes(langCode string) string {
+	notes := lang.Format(langCode, "broadcast_excluded_count", s.excluded)
+	if s.inactive > 0 {
+		notes += lang.Format(langCode, "broadcast_active_excluded", s.inactive)
+	}
+	if s.untracked > 0 {
+		notes += lang.Format(langCode, "broadcast_active_untracked", s.untracked)
+	}
+	if s.invalid > 0 {
+		notes += lang.Format(langCode, "broadcast_list_invalid", s.invalid)
 	}
+	return notes
+}
+
+// broadcastTargets selects the message to broadcast and the chats and users to send it to. It reads the
+// targets from the database, or with -list from the replied file, whose own reply is then the message to
+// send. The message is nil for a text broadcast. It returns the lang key of the error to show if the
+// targets cannot be read.
+func broadcastTargets(ctx context.Context, reply *tg.NewMessage, opts broadcastOptions) (*broadcastSelection, string) {
+	sel := &broadcastSelection{content: reply}
+	var targets []int64
+	if opts.List {
+		if reply == nil {
+			return nil, "broadcast_list_usage"
+		}
+		var errKey string
+		if targets, sel.invalid, errKey = readBroadcastList(reply); errKey != "" {
+			return nil, errKey
+		}
+		sel.content = nil
+		if reply.IsReply() {
+			sel.content, _ = reply.GetReplyMessage()
+		}
+		if sel.content == nil && opts.Text == "" {
+			return nil, "broadcast_list_no_message"
+		}
+	} else if opts.Active == 0 {
+		chats, _ := db.Instance.GetReachableChats(ctx)
+		users, _ := db.Instance.GetReachableUsers(ctx)
+		targets = append(chats, users...)
+	}
+	if sel.content != nil && opts.Buttons != nil {
+		return nil, "broadcast_buttons_text_only"
+	}
+
+	if opts.Active > 0 {
+		var errKey string
+		if targets, sel.inactive, sel.untracked, errKey = activeBroadcastTargets(ctx, targets, opts); errKey != "" {
+			return nil, errKey
+		}
+	}
+
+	// Without the exclusion list, the broadcast could reach a chat that asked not to get any.
+	exclusions, err := db.Instance.GetBroadcastExclusions(ctx)
+	if err != nil {
+		broadcastLogger.Warn("Failed to read the broadcast exclusion list: %v", err)
+		return nil, "broadcast_exclusions_unreadable"
+	}
+	excluded := broadcastExclusionSet(exclusions)
 
 	for _, id := range targets {
-		jobs <- id
+		if (id < 0 && opts.NoChats) || (id > 0 && opts.NoUsers) {
+			continue
+		}
+		if excluded[id] {
+			sel.excluded++
+			continue
+		}
+		sel.targets = append(sel.targets, id)
 	}
-	close(jobs)
+	return sel, ""
+}
 
-	wg.Wait()
+// activeBroadcastTargets returns the chats and users that used the bot within the -active window, how many
+// were left out, and how many of those were left out because no activity was ever recorded for them, as for
+// the chats that have not used the bot since recording started. With -list, list holds the targets and only
+// those active are kept; otherwise the active ones are read from the database, which also counts those it
+// leaves out.
+func activeBroadcastTargets(ctx context.Context, list []int64, opts broadcastOptions) ([]int64, int, int, string) {
+	since := time.Now().Add(-opts.Active)
+	var active, untracked []int64
+	if !opts.NoChats {
+		chats, err := db.Instance.GetActiveChats(ctx, since)
+		if err != nil {
+			broadcastLogger.Warn("Failed to read the active chats: %v", err)
+			return nil, 0, 0, "broadcast_active_error"
+		}
+		unknown, err := db.Instance.GetUntrackedChats(ctx)
+		if err != nil {
+			broadcastLogger.Warn("Failed to read the chats without activity: %v", err)
+			return nil, 0, 0, "broadcast_active_error"
+		}
+		active, untracked = append(active, chats...), append(untracked, unknown...)
+	}
+	if !opts.NoUsers {
+		users, err := db.Instance.GetActiveUsers(ctx, since)
+		if err != nil {
+			broadcastLogger.Warn("Failed to read the active users: %v", err)
+			return nil, 0, 0, "broadcast_active_error"
+		}
+		unknown, err := db.Instance.GetUntrackedUsers(ctx)
+		if err != nil {
+			broadcastLogger.Warn("Failed to read the users without activity: %v", err)
+			return nil, 0, 0, "broadcast_active_error"
+		}
+		active, untracked = append(active, users...), append(untracked, unknown...)
+	}
 
-	total := len(targets)
-	result := fmt.Sprintf(
-		"📢 <b>Broadcast Complete</b>\n\n"+
-			"👥 Total: %d\n"+
-			"✅ Success: %d\n"+
-			"❌ Failed: %d\n"+
-			"⚙ Mode: %s\n"+
-			"⏱ Delay: %v\n"+
-			"🛑 Cancelled: %v\n",
-		total,
-		success,
-		failed,
-		map[bool]string{true: "Copy", false: "Forward"}[copyMode],
-		delay,
-		broadcastCancelFlag.Load(),
-	)
+	if opts.List {
+		kept, inactive, leftUntracked := broadcast.KeepActive(list, active, untracked)
+		return kept, inactive, leftUntracked, ""
+	}
 
-	_, _ = sentMsg.Edit(result)
-	broadcastInProgress.Store(false)
-	return tg.EndGroup
+	var total int64
+	if !opts.NoChats {
+		n, _ := db.Instance.CountChats(ctx)
+		total += n
+	}
+	if !opts.NoUsers {
+		n, _ := db.Instance.CountUsers(ctx)
+		total += n
+	}
+	return active, max(int(total)-len(active), len(untracked)), len(untracked), ""
+}
+
+// readBroadcastList reads the target IDs from the .txt or .csv file msg, one per line; the first column of
+// a CSV line is used. Blank lines and lines starting with # are ignored, duplicates are dropped and the
+// lines that are not IDs are counted.
+func readBroadcastList(msg *tg.NewMessage) ([]int64, int, string) {
+	doc := msg.Document()
+	if doc == nil || msg.File == nil {
+		return nil, 0, "broadcast_list_usage"
+	}
+	if ext := strings.ToLower(filepath.Ext(msg.File.Name)); ext != ".txt" && ext != ".csv" {
+		return nil, 0, "broadcast_list_usage"
+	}
+	if doc.Size > maxBroadcastListSize {
+		return nil, 0, "broadcast_list_too_large"
+	}
+	var buf bytes.Buffer
+	if _, err := msg.Download(&tg.DownloadOptions{Buffer: &buf}); err != nil {
+		broadcastLogger.Warn("Failed to download the target list: %v", err)
+		return nil, 0, "broadcast_list_unreadable"
+	}
+
+	var ids []int64
+	invalid := 0
+	seen := make(map[int64]bool)
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		field, _, _ := strings.Cut(line, ",")
+		id, err := strconv.ParseInt(strings.Trim(strings.TrimSpace(field), `"`), 10, 64)
+		if err != nil || id == 0 {
+			invalid++
+			continue
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, invalid, ""
 }
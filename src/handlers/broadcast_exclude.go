@@ -0,0 +1,128 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// maxExclusionsShown is how many entries /broadcastexcluded lists.
+const maxExclusionsShown = 50
+
+// exclusionTarget returns the chat or user an exclusion command names: its argument, or the chat it was
+// sent in if it has none and is not private. It reports false if there is neither.
+func exclusionTarget(m *tg.NewMessage) (int64, bool) {
+	args := strings.TrimSpace(m.Args())
+	if args == "" {
+		return m.ChannelID(), !m.IsPrivate()
+	}
+	id, err := strconv.ParseInt(args, 10, 64)
+	return id, err == nil && id != 0
+}
+
+// broadcastExcludeHandler handles the /broadcastexclude command, which stops broadcasts from ever being
+// sent to a chat or user.
+func broadcastExcludeHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
+
+	id, ok := exclusionTarget(m)
+	if !ok {
+		_, _ = m.Reply(lang.GetString(langCode, "broadcast_exclude_usage"))
+		return tg.EndGroup
+	}
+	added, err := db.Instance.ExcludeFromBroadcasts(ctx, id, m.SenderID())
+	if err != nil {
+		_, _ = m.Reply(lang.Format(langCode, "broadcast_exclude_error", html.EscapeString(err.Error())))
+		return tg.EndGroup
+	}
+	title := html.EscapeString(getChatTitle(m.Client, id))
+	if !added {
+		_, _ = m.Reply(lang.Format(langCode, "broadcast_exclude_exists", title, id))
+		return tg.EndGroup
+	}
+	audit(m, "broadcastexclude", strconv.FormatInt(id, 10), "")
+	_, _ = m.Reply(lang.Format(langCode, "broadcast_exclude_done", title, id))
+	return tg.EndGroup
+}
+
+// broadcastIncludeHandler handles the /broadcastinclude command, which takes a chat or user off the
+// broadcast exclusion list.
+func broadcastIncludeHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
+
+	id, ok := exclusionTarget(m)
+	if !ok {
+		_, _ = m.Reply(lang.GetString(langCode, "broadcast_include_usage"))
+		return tg.EndGroup
+	}
+	removed, err := db.Instance.IncludeInBroadcasts(ctx, id)
+	if err != nil {
+		_, _ = m.Reply(lang.Format(langCode, "broadcast_exclude_error", html.EscapeString(err.Error())))
+		return tg.EndGroup
+	}
+	title := html.EscapeString(getChatTitle(m.Client, id))
+	if !removed {
+		_, _ = m.Reply(lang.Format(langCode, "broadcast_include_missing", title, id))
+		return tg.EndGroup
+	}
+	audit(m, "broadcastinclude", strconv.FormatInt(id, 10), "")
+	_, _ = m.Reply(lang.Format(langCode, "broadcast_include_done", title, id))
+	return tg.EndGroup
+}
+
+// broadcastExcludedHandler handles the /broadcastexcluded command, which lists the broadcast exclusion list.
+func broadcastExcludedHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
+
+	exclusions, err := db.Instance.GetBroadcastExclusions(ctx)
+	if err != nil {
+		_, _ = m.Reply(lang.Format(langCode, "broadcast_exclude_error", html.EscapeString(err.Error())))
+		return tg.EndGroup
+	}
+	if len(exclusions) == 0 {
+		_, _ = m.Reply(lang.GetString(langCode, "broadcast_excluded_empty"))
+		return tg.EndGroup
+	}
+
+	var sb strings.Builder
+	sb.WriteString(lang.Format(langCode, "broadcast_excluded_header", len(exclusions)))
+	for i, e := range exclusions {
+		if i == maxExclusionsShown {
+			sb.WriteString(lang.Format(langCode, "broadcast_excluded_more", len(exclusions)-i))
+			break
+		}
+		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "broadcast_excluded_entry"),
+			html.EscapeString(getChatTitle(m.Client, e.ID)), e.ID, e.Created.Format("2 Jan 2006")))
+	}
+	_, _ = m.Reply(sb.String())
+	return tg.EndGroup
+}
+
+// broadcastExclusionSet returns the IDs on the broadcast exclusion list.
+func broadcastExclusionSet(exclusions []db.BroadcastExclusion) map[int64]bool {
+	set := make(map[int64]bool, len(exclusions))
+	for _, e := range exclusions {
+		set[e.ID] = true
+	}
+	return set
+}
@@ -0,0 +1,120 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/shutdown"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// broadcastResumeNoticeDelay is how long after startup an unfinished broadcast is reported, so the client
+// is connected by then.
+const broadcastResumeNoticeDelay = 10 * time.Second
+
+// notifyUnfinishedBroadcast tells whoever started a broadcast that a restart cut it off, offering
+// /resumebroadcast to finish it.
+func notifyUnfinishedBroadcast(c *tg.Client) {
+	go func() {
+		time.Sleep(broadcastResumeNoticeDelay)
+		ctx, cancel := db.Ctx()
+		defer cancel()
+		job, err := db.Instance.GetBroadcastJob(ctx)
+		if err != nil {
+			broadcastLogger.Warn("Failed to look for an unfinished broadcast: %v", err)
+			return
+		}
+		if job == nil || broadcasts.Running() {
+			return
+		}
+		broadcastLogger.Info("Found an unfinished broadcast: %d of %d targets done", len(job.Done), len(job.Targets))
+		text := lang.Format(job.LangCode, "broadcast_resume_notice", len(job.Done), len(job.Targets), job.Started.Local().Format(broadcastTimeFormat))
+		if _, err := c.SendMessage(job.ChatID, text); err != nil {
+			broadcastLogger.Debug("Failed to report the unfinished broadcast to %d: %v", job.ChatID, err)
+		}
+	}()
+}
+
+// resumeBroadcastHandler handles the /resumebroadcast command, which continues the broadcast a restart cut
+// off, sending to the targets it had not reached yet with the flags it was started with.
+func resumeBroadcastHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
+
+	runCtx, ok := broadcasts.Begin(shutdown.Context())
+	if !ok {
+		_, _ = m.Reply(lang.GetString(langCode, "broadcast_in_progress"))
+		return tg.EndGroup
+	}
+	defer broadcasts.End()
+
+	job, err := db.Instance.GetBroadcastJob(ctx)
+	if err != nil {
+		_, _ = m.Reply(lang.Format(langCode, "broadcast_resume_error", err.Error()))
+		return tg.EndGroup
+	}
+	if job == nil {
+		_, _ = m.Reply(lang.GetString(langCode, "broadcast_resume_none"))
+		return tg.EndGroup
+	}
+
+	// A text broadcast has no message to read again; its text is part of the flags.
+	var content *tg.NewMessage
+	if job.SourceMsg != 0 {
+		content, err = m.Client.GetMessageByID(job.SourceChat, job.SourceMsg)
+		if err != nil || content == nil {
+			_ = db.Instance.DeleteBroadcastJob(ctx, job.ID)
+			_, _ = m.Reply(lang.GetString(langCode, "broadcast_resume_gone"))
+			return tg.EndGroup
+		}
+	}
+	opts, _ := parseBroadcastArgs(job.Args)
+	opts.Schedule = ""
+
+	// Chats excluded since the broadcast started are left out too.
+	exclusions, err := db.Instance.GetBroadcastExclusions(ctx)
+	if err != nil {
+		_, _ = m.Reply(lang.GetString(langCode, "broadcast_exclusions_unreadable"))
+		return tg.EndGroup
+	}
+	sel := &broadcastSelection{content: content}
+	excluded := broadcastExclusionSet(exclusions)
+	for _, id := range remainingBroadcastTargets(job) {
+		if excluded[id] {
+			sel.excluded++
+			continue
+		}
+		sel.targets = append(sel.targets, id)
+	}
+
+	audit(m, "resumebroadcast", broadcastSubject(content), fmt.Sprintf("%d of %d left", len(sel.targets), len(job.Targets)))
+	sendBroadcast(runCtx, m, sel, job, opts, job.LangCode)
+	return tg.EndGroup
+}
+
+// remainingBroadcastTargets returns the targets of job that it has not sent to yet, in their original order.
+func remainingBroadcastTargets(job *db.BroadcastJob) []int64 {
+	done := make(map[int64]bool, len(job.Done))
+	for _, id := range job.Done {
+		done[id] = true
+	}
+	remaining := make([]int64, 0, len(job.Targets)-len(job.Done))
+	for _, id := range job.Targets {
+		if !done[id] {
+			remaining = append(remaining, id)
+		}
+	}
+	return remaining
+}
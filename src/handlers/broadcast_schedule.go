@@ -0,0 +1,243 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/shutdown"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// maxScheduledBroadcasts is how many broadcasts may be scheduled at once.
+	maxScheduledBroadcasts = 20
+	// broadcastScheduleGrace is how late a scheduled broadcast may still start, for broadcasts that came due
+	// while the bot was down.
+	broadcastScheduleGrace = time.Hour
+	// broadcastTimeFormat is how the time of a scheduled broadcast is shown.
+	broadcastTimeFormat = "Mon 02 Jan 15:04 MST"
+)
+
+var broadcastSchedulerOnce sync.Once
+
+func init() {
+	registerCallback("sb", &callbackRoute{
+		Allow:  permitCallback(requireSudo),
+		Handle: scheduledBroadcastCancelCallback,
+	})
+}
+
+// scheduleBroadcast stores the /broadcast command m to be run at spec, a clock time in the bot's local time
+// or a delay.
+func scheduleBroadcast(m *tg.NewMessage, spec, langCode string) error {
+	at, err := parseScheduleTime(spec, time.Local, time.Now())
+	switch {
+	case errors.Is(err, errScheduleTooSoon):
+		_, _ = m.Reply(lang.GetString(langCode, "broadcast_schedule_too_soon"))
+		return tg.EndGroup
+	case errors.Is(err, errScheduleTooFar):
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "broadcast_schedule_too_far"), int(scheduleMaxAhead.Hours()/24)))
+		return tg.EndGroup
+	case err != nil:
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "schedule_bad_time"), html.EscapeString(spec)))
+		return tg.EndGroup
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	pending, err := db.Instance.GetScheduledBroadcasts(ctx)
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "broadcast_schedule_error"), html.EscapeString(err.Error())))
+		return tg.EndGroup
+	}
+	if len(pending) >= maxScheduledBroadcasts {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "broadcast_schedule_full"), maxScheduledBroadcasts))
+		return tg.EndGroup
+	}
+
+	b := &db.ScheduledBroadcast{ChatID: m.ChannelID(), MsgID: m.ID, UserID: m.SenderID(), Args: m.Args(), At: at}
+	if err := db.Instance.AddScheduledBroadcast(ctx, b); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "broadcast_schedule_error"), html.EscapeString(err.Error())))
+		return tg.EndGroup
+	}
+
+	audit(m, "schedulebroadcast", "", fmt.Sprintf("%s %s", at.UTC().Format(time.RFC3339), m.Args()))
+	// The command is kept, since it and the message it replies to are read again when the broadcast is due.
+	_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "broadcast_scheduled"),
+		at.Format(broadcastTimeFormat), cache.SecToMin(int(time.Until(at).Seconds()))))
+	return tg.EndGroup
+}
+
+// scheduledBroadcastsHandler handles the /scheduledbroadcasts command, which lists the pending broadcasts
+// with a button to cancel each.
+func scheduledBroadcastsHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID())
+
+	text, markup, err := buildScheduledBroadcastList(langCode)
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "broadcast_schedule_error"), html.EscapeString(err.Error())))
+		return tg.EndGroup
+	}
+	_, _ = m.Reply(text, &tg.SendOptions{ReplyMarkup: markup})
+	return tg.EndGroup
+}
+
+// buildScheduledBroadcastList renders the pending broadcasts along with their cancel buttons.
+func buildScheduledBroadcastList(langCode string) (string, tg.ReplyMarkup, error) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	pending, err := db.Instance.GetScheduledBroadcasts(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	kb := tg.NewKeyboard()
+	if len(pending) == 0 {
+		return lang.GetString(langCode, "scheduled_broadcasts_empty"), kb.AddRow(core.CloseBtn).Build(), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "scheduled_broadcasts_header"), len(pending), maxScheduledBroadcasts))
+	for i, b := range pending {
+		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "scheduled_broadcasts_entry"),
+			i+1, b.At.Local().Format(broadcastTimeFormat), html.EscapeString(truncate(b.Args, 80)), b.UserID))
+		kb.AddRow(tg.Button.Data(fmt.Sprintf(lang.GetString(langCode, "scheduled_cancel_button"), i+1),
+			callbackData("sb", "", strconv.FormatInt(b.ID, 10))))
+	}
+	return sb.String(), kb.AddRow(core.CloseBtn).Build(), nil
+}
+
+// scheduledBroadcastCancelCallback cancels a broadcast from the /scheduledbroadcasts list and refreshes the list.
+func scheduledBroadcastCancelCallback(c *callbackCtx) error {
+	id, _ := strconv.ParseInt(c.Arg(0), 10, 64)
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	b, err := db.Instance.TakeScheduledBroadcast(ctx, id)
+	switch {
+	case err != nil:
+		c.Answer(fmt.Sprintf(lang.GetString(c.LangCode, "broadcast_schedule_error"), err.Error()), true)
+		return nil
+	case b == nil:
+		c.Answer(lang.GetString(c.LangCode, "scheduled_broadcast_gone"), true)
+	default:
+		auditCB(c.CallbackQuery, "unschedulebroadcast", "", b.Args)
+		c.Answer(lang.GetString(c.LangCode, "scheduled_broadcast_cancelled"), false)
+	}
+
+	text, markup, err := buildScheduledBroadcastList(c.LangCode)
+	if err != nil {
+		return err
+	}
+	_, err = c.Edit(text, &tg.SendOptions{ReplyMarkup: markup})
+	return err
+}
+
+// startBroadcastScheduler starts the loop that runs scheduled broadcasts once they are due.
+func startBroadcastScheduler(c *tg.Client) {
+	broadcastSchedulerOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(schedulePoll)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				runDueBroadcast(c, now)
+			}
+		}()
+	})
+}
+
+// runDueBroadcast starts the oldest scheduled broadcast that is due, unless a broadcast is already running;
+// the others wait for later ticks. The manager is claimed before the broadcast is taken, so a /broadcast
+// sent meanwhile cannot make a taken broadcast fail to start. The broadcast is removed before it starts, so
+// it runs once even if starting it fails.
+func runDueBroadcast(c *tg.Client, now time.Time) {
+	runCtx, ok := broadcasts.Begin(shutdown.Context())
+	if !ok {
+		return
+	}
+	started := false
+	defer func() {
+		if !started {
+			broadcasts.End()
+		}
+	}()
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	due, err := db.Instance.DueScheduledBroadcasts(ctx, now)
+	if err != nil {
+		broadcastLogger.Warn("Failed to load due broadcasts: %v", err)
+		return
+	}
+	for _, d := range due {
+		b, err := db.Instance.TakeScheduledBroadcast(ctx, d.ID)
+		if err != nil || b == nil {
+			continue
+		}
+		langCode := db.Instance.LangFor(ctx, b.ChatID, b.UserID)
+		if late := now.Sub(b.At); late > broadcastScheduleGrace {
+			broadcastLogger.Info("Skipping the broadcast scheduled for %s, %s late", b.At.Format(time.RFC3339), late.Round(time.Minute))
+			notifyBroadcastSchedule(c, b, lang.GetString(langCode, "broadcast_schedule_missed"))
+			continue
+		}
+		started = true
+		go startScheduledBroadcast(runCtx, c, b, langCode)
+		return
+	}
+}
+
+// startScheduledBroadcast runs a scheduled broadcast as if its /broadcast command had just been sent. It owns
+// the manager that runDueBroadcast claimed, and releases it when done.
+func startScheduledBroadcast(runCtx context.Context, c *tg.Client, b *db.ScheduledBroadcast, langCode string) {
+	defer broadcasts.End()
+
+	orig, err := c.GetMessageByID(b.ChatID, b.MsgID)
+	if err != nil || orig == nil {
+		notifyBroadcastSchedule(c, b, lang.GetString(langCode, "broadcast_schedule_deleted"))
+		return
+	}
+	opts, errKey := parseBroadcastArgs(b.Args)
+	if errKey != "" {
+		notifyBroadcastSchedule(c, b, lang.GetString(langCode, errKey))
+		return
+	}
+	opts.Schedule = ""
+
+	// A text broadcast replies to no message; the replied message of any other must still exist.
+	var reply *tg.NewMessage
+	if orig.IsReply() || opts.Text == "" {
+		if reply, err = orig.GetReplyMessage(); err != nil || reply == nil {
+			notifyBroadcastSchedule(c, b, lang.GetString(langCode, "broadcast_schedule_deleted"))
+			return
+		}
+	}
+	startBroadcast(runCtx, orig, reply, opts, langCode)
+}
+
+// notifyBroadcastSchedule tells whoever scheduled b that it did not start.
+func notifyBroadcastSchedule(c *tg.Client, b *db.ScheduledBroadcast, text string) {
+	if _, err := c.SendMessage(b.ChatID, text); err != nil {
+		broadcastLogger.Debug("Failed to notify %d about a scheduled broadcast: %v", b.ChatID, err)
+	}
+}
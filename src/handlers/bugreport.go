@@ -0,0 +1,254 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// reportCooldown is how long a user must wait between two reports.
+const reportCooldown = 5 * time.Minute
+
+// maxUserReport caps the length of a report's text.
+const maxUserReport = 1000
+
+// maxOpenReports is the number of open reports /reports lists.
+const maxOpenReports = 20
+
+var reportRateLimit = cache.NewCache[time.Time](reportCooldown)
+
+func init() {
+	// Reports are stored, so their buttons keep working after a restart.
+	registerCallback("rt", &callbackRoute{
+		Allow:   reportStaffDenial,
+		Handle:  reportCallback,
+		Durable: true,
+	})
+}
+
+// reportKey formats a report ID the way it is shown to users and staff.
+func reportKey(id int64) string {
+	return strconv.FormatInt(id, 36)
+}
+
+// reportHandler handles the /report command.
+// "/report <text>", or a reply to the message that went wrong, sends an issue to the support chat along with
+// the chat, the sender, the current track and the ID of the chat's last error.
+func reportHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, chatID, m.SenderID())
+
+	text := strings.TrimSpace(m.Args())
+	var replied string
+	if m.IsReply() {
+		if reply, err := m.GetReplyMessage(); err == nil && reply != nil {
+			replied = strings.TrimSpace(reply.Text())
+		}
+	}
+	if text == "" && replied == "" {
+		_, err := m.Reply(lang.GetString(langCode, "report_usage"))
+		return err
+	}
+
+	supportChat := config.ReportChatID()
+	if supportChat == 0 {
+		_, err := m.Reply(lang.GetString(langCode, "report_unavailable"))
+		return err
+	}
+
+	inv := resolveInvoker(m.Message)
+	rateKey := strconv.FormatInt(inv.ID, 10)
+	if lastUsed, ok := reportRateLimit.Get(rateKey); ok {
+		if passed := time.Since(lastUsed); passed < reportCooldown {
+			remaining := int((reportCooldown - passed).Seconds())
+			_, err := replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "report_cooldown"), cache.SecToMin(remaining)), true)
+			return err
+		}
+	}
+
+	staffLang := db.Instance.GetLang(ctx, supportChat)
+	report := &db.Report{
+		ChatID:   chatID,
+		UserID:   inv.ID,
+		UserName: invokerName(m, inv, staffLang),
+		Text:     truncate(text, maxUserReport),
+		ErrorID:  lastErrorID(chatID),
+	}
+	if !m.IsPrivate() {
+		report.ChatTitle = getChatTitle(m.Client, chatID)
+	}
+	if replied != "" {
+		report.Text = strings.TrimSpace(report.Text + "\n\n↪ " + truncate(replied, maxUserReport/2))
+	}
+	if state, cause := vc.Calls.State(chatID); cause != nil {
+		report.Playback = fmt.Sprintf("%s: %s", state, truncate(config.Redact(cause.Error()), 200))
+	}
+	if track := cache.ChatCache.GetPlayingTrack(chatID); track != nil {
+		report.Track = track.Name
+		if track.URL != "" {
+			report.Track += " — " + track.URL
+		}
+	}
+
+	if err := db.Instance.AddReport(ctx, report); err != nil {
+		_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "report_failed"), err))
+		return nil
+	}
+	reportRateLimit.Set(rateKey, time.Now())
+
+	kb := tg.NewKeyboard().AddRow(
+		tg.Button.Data(lang.GetString(staffLang, "report_btn_resolve"), callbackData("rt", "", db.ReportResolved, reportKey(report.ID))),
+		tg.Button.Data(lang.GetString(staffLang, "report_btn_ignore"), callbackData("rt", "", db.ReportIgnored, reportKey(report.ID))),
+	).Build()
+	if _, err := m.Client.SendMessage(supportChat, reportText(report, staffLang), &tg.SendOptions{ReplyMarkup: kb}); err != nil {
+		logger.Warn("[report] Failed to forward report %s: %v", reportKey(report.ID), err)
+		_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "report_failed"), err))
+		return nil
+	}
+
+	_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "report_sent"), reportKey(report.ID)))
+	return err
+}
+
+// invokerName returns how a report names whoever sent m, in the support chat's language.
+func invokerName(m *tg.NewMessage, inv invoker, langCode string) string {
+	switch inv.Kind {
+	case senderAnonymousAdmin:
+		return lang.GetString(langCode, "sender_anonymous_admin")
+	case senderChannel:
+		return lang.Format(langCode, "report_channel_sender", inv.ID)
+	}
+	if m.Sender != nil {
+		return strings.TrimSpace(m.Sender.FirstName + " " + m.Sender.LastName)
+	}
+	return strconv.FormatInt(inv.ID, 10)
+}
+
+// reportText renders a report for the support chat in langCode.
+func reportText(r *db.Report, langCode string) string {
+	var b strings.Builder
+	b.WriteString(lang.Format(langCode, "report_card_title", reportKey(r.ID)))
+	b.WriteString(lang.Format(langCode, "report_card_user", html.EscapeString(r.UserName), r.UserID))
+	if r.ChatTitle != "" {
+		b.WriteString(lang.Format(langCode, "report_card_chat", html.EscapeString(r.ChatTitle), r.ChatID))
+	} else {
+		b.WriteString(lang.GetString(langCode, "report_card_private"))
+	}
+	if r.Track != "" {
+		b.WriteString(lang.Format(langCode, "report_card_track", html.EscapeString(r.Track)))
+	}
+	if r.Playback != "" {
+		b.WriteString(lang.Format(langCode, "report_card_playback", html.EscapeString(r.Playback)))
+	}
+	if r.ErrorID != "" {
+		b.WriteString(lang.Format(langCode, "report_card_error", html.EscapeString(r.ErrorID)))
+	}
+	b.WriteString("\n" + html.EscapeString(r.Text))
+	return b.String()
+}
+
+// reportStaffDenial lets only staff close reports: a developer or an admin of the support chat.
+func reportStaffDenial(cb *tg.CallbackQuery) string {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if denial(ctx, cb.Client, 0, callbackInvoker(cb), requireSudo) == "" ||
+		isChatAdmin(ctx, cb.Client, config.ReportChatID(), cb.SenderID) {
+		return ""
+	}
+	return "report_staff_only"
+}
+
+// reportCallback handles the Resolved and Ignore buttons of a forwarded report. It closes the report, marks
+// the support message and lets the reporter know.
+func reportCallback(c *callbackCtx) error {
+	status := c.Arg(0)
+	if status != db.ReportResolved && status != db.ReportIgnored {
+		return nil
+	}
+	id, err := strconv.ParseInt(c.Arg(1), 36, 64)
+	if err != nil {
+		return nil
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	report, err := db.Instance.CloseReport(ctx, id, status, c.SenderID)
+	if err != nil {
+		c.Answer(lang.GetString(c.LangCode, "report_close_failed"), true)
+		return err
+	}
+	if report == nil {
+		c.Answer(lang.GetString(c.LangCode, "report_already_closed"), true)
+		return nil
+	}
+
+	name := strconv.FormatInt(c.SenderID, 10)
+	if c.Sender != nil {
+		name = c.Sender.FirstName
+	}
+	verdict := lang.GetString(c.LangCode, "report_verdict_"+status)
+	c.Answer(verdict, false)
+	_, _ = c.Edit(reportText(report, c.LangCode) + lang.Format(c.LangCode, "report_closed_by", verdict, html.EscapeString(name)))
+
+	langCode := db.Instance.LangFor(ctx, report.ChatID, report.UserID)
+	notice := fmt.Sprintf(lang.GetString(langCode, "report_"+status+"_notice"), reportKey(report.ID))
+	if _, err := c.Client.SendMessage(report.UserID, notice); err != nil && report.ChatID != report.UserID {
+		_, _ = c.Client.SendMessage(report.ChatID, notice)
+	}
+	return nil
+}
+
+// reportsHandler handles the /reports command, which lists the open reports.
+func reportsHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	total, err := db.Instance.CountOpenReports(ctx)
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "reports_error"), html.EscapeString(err.Error())))
+		return err
+	}
+	if total == 0 {
+		_, err := m.Reply(lang.GetString(langCode, "reports_empty"))
+		return err
+	}
+	reports, err := db.Instance.OpenReports(ctx, maxOpenReports)
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "reports_error"), html.EscapeString(err.Error())))
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(lang.GetString(langCode, "reports_header"), total))
+	for _, r := range reports {
+		b.WriteString(fmt.Sprintf(lang.GetString(langCode, "reports_entry"),
+			reportKey(r.ID),
+			time.Unix(r.Created, 0).UTC().Format("2006-01-02 15:04"),
+			html.EscapeString(r.UserName), r.ChatID,
+			html.EscapeString(truncate(strings.ReplaceAll(r.Text, "\n", " "), 80)),
+		))
+	}
+	_, err = m.Reply(b.String())
+	return err
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"strings"
 
+	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core"
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
@@ -61,6 +62,10 @@ func playCallbackHandler(cb *telegram.CallbackQuery) error {
 	switch {
 	case strings.Contains(data, "play_skip"):
 		if err := vc.Calls.PlayNext(chatID); err != nil {
+			if text, ok := playbackStateText(langCode, err); ok {
+				_, _ = cb.Answer(text, &telegram.CallbackOptions{Alert: true})
+				return nil
+			}
 			_, _ = cb.Answer(lang.GetString(langCode, "skip_fail"), &telegram.CallbackOptions{Alert: true})
 			_, _ = cb.Edit(lang.GetString(langCode, "skip_fail"), &telegram.SendOptions{ReplyMarkup: core.ControlButtons("")})
 			return nil
@@ -82,6 +87,10 @@ func playCallbackHandler(cb *telegram.CallbackQuery) error {
 
 	case strings.Contains(data, "play_pause"):
 		if _, err := vc.Calls.Pause(chatID); err != nil {
+			if text, ok := playbackStateText(langCode, err); ok {
+				_, _ = cb.Answer(text, &telegram.CallbackOptions{Alert: true})
+				return nil
+			}
 			_, _ = cb.Answer(lang.GetString(langCode, "pause_fail"), &telegram.CallbackOptions{Alert: true})
 			_, _ = cb.Edit(lang.GetString(langCode, "pause_fail"), &telegram.SendOptions{ReplyMarkup: core.ControlButtons("")})
 			return nil
@@ -93,6 +102,10 @@ func playCallbackHandler(cb *telegram.CallbackQuery) error {
 
 	case strings.Contains(data, "play_resume"):
 		if _, err := vc.Calls.Resume(chatID); err != nil {
+			if text, ok := playbackStateText(langCode, err); ok {
+				_, _ = cb.Answer(text, &telegram.CallbackOptions{Alert: true})
+				return nil
+			}
 			_, _ = cb.Answer(lang.GetString(langCode, "resume_fail"), &telegram.CallbackOptions{Alert: true})
 			_, _ = cb.Edit(lang.GetString(langCode, "resume_fail"), &telegram.SendOptions{ReplyMarkup: core.ControlButtons("pause")})
 			return nil
@@ -124,6 +137,10 @@ func playCallbackHandler(cb *telegram.CallbackQuery) error {
 		_, _ = cb.Edit(text, &telegram.SendOptions{ReplyMarkup: core.ControlButtons("unmute")})
 		return nil
 	case strings.Contains(data, "play_add_to_list"):
+		if !config.Get().Features.Playlists {
+			_, _ = cb.Answer(lang.GetString(langCode, "feature_disabled"), &telegram.CallbackOptions{Alert: true})
+			return nil
+		}
 		userID := cb.GetSenderID()
 		playlists, err := db.Instance.GetUserPlaylists(ctx, userID)
 		if err != nil {
@@ -0,0 +1,109 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"strconv"
+
+	"ashokshau/tgmusic/src/core/cbroute"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// callbackRouter routes "cb:" callback data; see package cbroute for its format. Its boot ID identifies the
+// current process so that buttons rendered before a restart are detected as stale.
+var callbackRouter = cbroute.New[*callbackRoute](strconv.FormatInt(startTime.Unix()%(36*36*36*36), 36))
+
+// callbackRoute describes how callbacks for one route are authorised, checked for staleness and handled.
+type callbackRoute struct {
+	// Allow returns an empty string if the presser may use the button, or a lang key explaining why not.
+	Allow func(cb *telegram.CallbackQuery) string
+	// Token returns the current state token for the button's chat. A mismatch with the rendered token marks the button as stale.
+	// A nil Token disables the staleness check beyond the boot ID.
+	Token func(cb *telegram.CallbackQuery) string
+	// Handle processes a valid callback. Unanswered callbacks are answered automatically once it returns.
+	Handle func(ctx *callbackCtx) error
+	// Durable keeps the route's buttons valid across restarts, for buttons that act on stored state.
+	Durable bool
+}
+
+// callbackCtx carries a routed callback and its decoded arguments.
+type callbackCtx struct {
+	*telegram.CallbackQuery
+	Args     []string
+	LangCode string
+	answered bool
+}
+
+// Answer answers the callback query, at most once.
+func (c *callbackCtx) Answer(text string, alert bool) {
+	if c.answered {
+		return
+	}
+	c.answered = true
+	_, _ = c.CallbackQuery.Answer(text, &telegram.CallbackOptions{Alert: alert})
+}
+
+// Arg returns the i-th argument, or an empty string if it is missing.
+func (c *callbackCtx) Arg(i int) string {
+	return cbroute.Data{Args: c.Args}.Arg(i)
+}
+
+// registerCallback adds a route to the callback router.
+func registerCallback(name string, route *callbackRoute) {
+	if route.Durable {
+		callbackRouter.RegisterDurable(name, route)
+		return
+	}
+	callbackRouter.Register(name, route)
+}
+
+// callbackData builds routed callback data for a button.
+func callbackData(route, token string, args ...string) string {
+	return callbackRouter.Data(route, token, args...)
+}
+
+// callbackArgs returns the arguments of routed callback data, for Allow funcs that depend on them.
+func callbackArgs(cb *telegram.CallbackQuery) []string {
+	d, _ := cbroute.Parse(cb.DataString())
+	return d.Args
+}
+
+// routeCallback dispatches routed callbacks, rejecting unauthorised presses and stale buttons with a toast.
+func routeCallback(cb *telegram.CallbackQuery) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, cb.ChannelID())
+
+	route, d, reason := callbackRouter.Resolve(cb.DataString())
+	if reason == "" {
+		var allow, token func() string
+		if route.Allow != nil {
+			allow = func() string { return route.Allow(cb) }
+		}
+		if route.Token != nil {
+			token = func() string { return route.Token(cb) }
+		}
+		reason = callbackRouter.Check(d, allow, token)
+	}
+	if reason != "" {
+		_, _ = cb.Answer(lang.GetString(langCode, reason), &telegram.CallbackOptions{Alert: true})
+		return telegram.EndGroup
+	}
+
+	c := &callbackCtx{CallbackQuery: cb, Args: d.Args, LangCode: langCode}
+	err := route.Handle(c)
+	c.Answer("", false)
+	if err != nil {
+		logger.Warn("[routeCallback] %s: %v", d.Route, err)
+	}
+	return telegram.EndGroup
+}
@@ -10,8 +10,12 @@ package handlers
 
 import (
 	"fmt"
+	"html"
+	"strconv"
 	"strings"
+	"time"
 
+	"ashokshau/tgmusic/src/core"
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/lang"
@@ -20,6 +24,9 @@ import (
 	"github.com/amarnathcjd/gogram/telegram"
 )
 
+// activeVcPageSize is the number of chats shown per page of the /activevc listing.
+const activeVcPageSize = 15
+
 // activeVcHandler handles the /activevc command.
 // It takes a telegram.NewMessage object as input.
 // It returns an error if any.
@@ -28,50 +35,112 @@ func activeVcHandler(m *telegram.NewMessage) error {
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
-	activeChats := cache.ChatCache.GetActiveChats()
-	if len(activeChats) == 0 {
-		_, err := m.Reply(lang.GetString(langCode, "no_active_chats"))
-		return err
-	}
 
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "active_chats_header"), len(activeChats)))
-
-	for _, chatID := range activeChats {
-		queueLength := cache.ChatCache.GetQueueLength(chatID)
-		currentSong := cache.ChatCache.GetPlayingTrack(chatID)
-
-		var songInfo string
-		if currentSong != nil {
-			songInfo = fmt.Sprintf(
-				lang.GetString(langCode, "now_playing_devs"),
-				currentSong.URL,
-				currentSong.Name,
-				currentSong.Duration,
-			)
+	text, markup := buildActiveVcPage(m.Client, langCode, 0)
+	_, err := m.Reply(text, &telegram.SendOptions{LinkPreview: false, ReplyMarkup: markup})
+	return err
+}
+
+// activeVcCallbackHandler handles the pagination and stop buttons of the /activevc listing.
+// It takes a telegram.CallbackQuery object as input.
+// It returns an error if any.
+func activeVcCallbackHandler(cb *telegram.CallbackQuery) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, cb.ChannelID())
+	data := cb.DataString()
+
+	page := 0
+	switch {
+	case strings.HasPrefix(data, "activevc_page_"):
+		page, _ = strconv.Atoi(strings.TrimPrefix(data, "activevc_page_"))
+		_, _ = cb.Answer("")
+
+	case strings.HasPrefix(data, "activevc_stop_"):
+		parts := strings.SplitN(strings.TrimPrefix(data, "activevc_stop_"), "_", 2)
+		if len(parts) != 2 {
+			_, _ = cb.Answer(lang.GetString(langCode, "invalid_request"), &telegram.CallbackOptions{Alert: true})
+			return nil
+		}
+
+		page, _ = strconv.Atoi(parts[0])
+		target, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			_, _ = cb.Answer(lang.GetString(langCode, "invalid_request"), &telegram.CallbackOptions{Alert: true})
+			return nil
+		}
+
+		if !cache.ChatCache.IsActive(target) {
+			_, _ = cb.Answer(lang.GetString(langCode, "active_vc_not_active"), &telegram.CallbackOptions{Alert: true})
+		} else if err = vc.Calls.Stop(target); err != nil {
+			_, _ = cb.Answer(fmt.Sprintf(lang.GetString(langCode, "active_vc_stop_failed"), err.Error()), &telegram.CallbackOptions{Alert: true})
+			return nil
 		} else {
-			songInfo = lang.GetString(langCode, "no_song_playing")
+			_, _ = cb.Answer(fmt.Sprintf(lang.GetString(langCode, "active_vc_stopped"), target), &telegram.CallbackOptions{Alert: true})
 		}
 
-		sb.WriteString(fmt.Sprintf(
-			lang.GetString(langCode, "chat_info"),
-			chatID,
-			queueLength,
-			songInfo,
-		))
+	default:
+		_, _ = cb.Answer("")
+		return nil
 	}
 
-	text := sb.String()
-	if len(text) > 4096 {
-		text = fmt.Sprintf(lang.GetString(langCode, "active_chats_header_short"), len(activeChats))
+	text, markup := buildActiveVcPage(cb.Client, langCode, page)
+	_, err := cb.Edit(text, &telegram.SendOptions{LinkPreview: false, ReplyMarkup: markup})
+	return err
+}
+
+// buildActiveVcPage renders a single page of the active voice chat listing along with its keyboard.
+// Out-of-range pages are clamped so stale buttons still land on a valid page.
+func buildActiveVcPage(client *telegram.Client, langCode string, page int) (string, *telegram.ReplyInlineMarkup) {
+	sessions := cache.ChatCache.Sessions()
+	if len(sessions) == 0 {
+		return lang.GetString(langCode, "no_active_chats"), core.SupportKeyboard()
 	}
 
-	_, err := m.Reply(text, &telegram.SendOptions{LinkPreview: false})
-	if err != nil {
-		return err
+	total := len(sessions)
+	pages := (total + activeVcPageSize - 1) / activeVcPageSize
+	page = max(0, min(page, pages-1))
+	offset := page * activeVcPageSize
+	sessions = sessions[offset:min(offset+activeVcPageSize, total)]
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "active_vc_header"), total, page+1, pages))
+
+	chatIDs := make([]int64, 0, len(sessions))
+	for i, session := range sessions {
+		chatIDs = append(chatIDs, session.ChatID)
+
+		songInfo := lang.GetString(langCode, "no_song_playing")
+		duration := "0:00"
+		if session.Current != nil {
+			songInfo = fmt.Sprintf("<a href='%s'>%s</a>", session.Current.URL, truncate(session.Current.Name, 45))
+			duration = cache.SecToMin(session.Current.Duration)
+		}
+
+		elapsed := "0:00"
+		if played, err := vc.Calls.Elapsed(session.ChatID); err == nil && played > 0 {
+			elapsed = cache.SecToMin(played)
+		}
+
+		listeners := "?"
+		if count, err := vc.Calls.ListenerCount(session.ChatID); err == nil {
+			listeners = strconv.Itoa(count)
+		}
+
+		sb.WriteString(fmt.Sprintf(
+			lang.GetString(langCode, "active_vc_entry"),
+			offset+i+1,
+			html.EscapeString(getChatTitle(client, session.ChatID)),
+			session.ChatID,
+			songInfo,
+			elapsed,
+			duration,
+			session.QueueLength,
+			listeners,
+		))
 	}
 
-	return nil
+	return sb.String(), core.ActiveVcKeyboard(chatIDs, offset, page, pages)
 }
 
 // Handles the /clearass command to remove all assistant assignments
@@ -87,10 +156,138 @@ func clearAssistantsHandler(m *telegram.NewMessage) error {
 		return err
 	}
 
+	audit(m, "clearassistants", "", fmt.Sprintf("%d cleared", done))
 	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "clear_assistants_success"), done))
 	return err
 }
 
+// assistantInfoHandler handles the /assistantinfo command.
+// It lists every assistant account with its active call count and health.
+func assistantInfoHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	assistants := vc.Calls.Assistants()
+	if len(assistants) == 0 {
+		_, err := m.Reply(lang.GetString(langCode, "assistant_info_none"))
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "assistant_info_header"), len(assistants)))
+	for i, a := range assistants {
+		username := a.Name
+		if a.Username != "" {
+			username = "@" + a.Username
+		}
+
+		health := lang.GetString(langCode, "assistant_info_healthy")
+		if !a.Healthy {
+			health = fmt.Sprintf(lang.GetString(langCode, "assistant_info_unhealthy"), html.EscapeString(a.Reason))
+			if !a.Until.IsZero() {
+				health += fmt.Sprintf(lang.GetString(langCode, "assistant_info_until"), a.Until.Format("15:04:05"))
+			}
+		}
+
+		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "assistant_info_entry"),
+			i+1, html.EscapeString(username), a.UserID, a.ActiveCalls, health))
+	}
+
+	_, err := m.Reply(sb.String())
+	return err
+}
+
+// assistantHealthHandler handles the /assistanthealth command.
+// It shows the restrictions Telegram currently places on each assistant and when they end.
+func assistantHealthHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	assistants := vc.Calls.Assistants()
+	if len(assistants) == 0 {
+		_, err := m.Reply(lang.GetString(langCode, "assistant_info_none"))
+		return err
+	}
+
+	var sb strings.Builder
+	restricted := 0
+	sb.WriteString(lang.GetString(langCode, "assistant_health_header"))
+	for _, a := range assistants {
+		name := html.EscapeString(a.Name)
+		if a.Username != "" {
+			name += " (@" + html.EscapeString(a.Username) + ")"
+		}
+		switch {
+		case a.Healthy:
+			sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "assistant_health_ok"), name))
+		case a.Until.IsZero():
+			restricted++
+			sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "assistant_health_dead"), name, html.EscapeString(a.Reason)))
+		default:
+			restricted++
+			sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "assistant_health_cooling"), name, html.EscapeString(a.Reason),
+				a.Since.UTC().Format("2006-01-02 15:04"), a.Until.UTC().Format("2006-01-02 15:04"),
+				time.Until(a.Until).Round(time.Minute)))
+		}
+	}
+	if restricted == 0 {
+		sb.WriteString(lang.GetString(langCode, "assistant_health_all_ok"))
+	}
+	_, err := m.Reply(sb.String())
+	return err
+}
+
+// waitListHandler handles the /waitlist command.
+// It lists the chats waiting for a free playback slot, in the order they will be started.
+func waitListHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	waiting := vc.Calls.WaitingList()
+	if len(waiting) == 0 {
+		_, err := m.Reply(lang.GetString(langCode, "waitlist_empty"))
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "waitlist_header"), len(waiting), len(cache.ChatCache.GetActiveChats())))
+	for i, waitingID := range waiting {
+		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "waitlist_entry"),
+			i+1, html.EscapeString(getChatTitle(m.Client, waitingID)), waitingID, cache.ChatCache.GetQueueLength(waitingID)))
+	}
+
+	_, err := m.Reply(sb.String())
+	return err
+}
+
+// bumpHandler handles the /bump command, which moves a waiting chat to the front of the waiting room.
+func bumpHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	target, err := strconv.ParseInt(strings.TrimSpace(m.Args()), 10, 64)
+	if err != nil {
+		_, err = m.Reply(lang.GetString(langCode, "bump_usage"))
+		return err
+	}
+
+	if err = vc.Calls.BumpWaiting(target); err != nil {
+		_, err = m.Reply(lang.GetString(langCode, "bump_not_waiting"))
+		return err
+	}
+
+	audit(m, "bump", strconv.FormatInt(target, 10), "")
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "bump_success"), target))
+	return err
+}
+
 // Handles the /leaveall command to leave all chats
 func leaveAllHandler(m *telegram.NewMessage) error {
 	chatID := m.ChannelID()
@@ -104,6 +301,7 @@ func leaveAllHandler(m *telegram.NewMessage) error {
 	}
 
 	leftCount, err := vc.Calls.LeaveAll()
+	audit(m, "leaveall", "", fmt.Sprintf("%d left", leftCount))
 	if err != nil {
 		_, _ = reply.Edit(fmt.Sprintf(lang.GetString(langCode, "leave_all_error"), err.Error()))
 		return err
@@ -0,0 +1,133 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/counters"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// digestTopErrors is how many of the most frequent errors the digest lists.
+const digestTopErrors = 5
+
+var digestOnce sync.Once
+
+// startDigest starts posting the daily digest to the logger group at DIGEST_TIME.
+// The time is checked every minute, so reloading the configuration moves or disables the digest.
+func startDigest(c *tg.Client) {
+	digestOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+
+			lastDay := ""
+			for now := range ticker.C {
+				at := config.Get().DigestTime
+				if at == "" || now.Format("15:04") != at || now.Format(time.DateOnly) == lastDay {
+					continue
+				}
+				lastDay = now.Format(time.DateOnly)
+				sendDigest(c, now)
+			}
+		}()
+	})
+}
+
+// sendDigest posts the counters collected since the previous digest. The counters are reset even when there
+// is no logger group, so that every digest covers one day.
+func sendDigest(c *tg.Client, now time.Time) {
+	snap := counters.Take()
+	hits, misses := cache.TakeLookups()
+
+	chatID := config.Get().LoggerId
+	if chatID == 0 {
+		return
+	}
+	if _, err := c.SendMessage(chatID, buildDigest(snap, hits, misses, now)); err != nil {
+		logger.Warn("[digest] Failed to send the daily digest: %v", err)
+	}
+}
+
+// buildDigest renders the daily digest.
+func buildDigest(snap counters.Snapshot, hits, misses int64, now time.Time) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("<b>📊 Daily digest</b> — %s\n\n", now.Format(time.DateOnly)))
+	b.WriteString(fmt.Sprintf("‣ <b>New users:</b> %d\n‣ <b>New chats:</b> %d\n",
+		snap.Total(counters.NewUsers), snap.Total(counters.NewChats)))
+	b.WriteString(fmt.Sprintf("‣ <b>Tracks played:</b> %d%s\n",
+		snap.Total(counters.Plays), digestBreakdown(snap.Top(counters.Plays, 0))))
+	b.WriteString(fmt.Sprintf("‣ <b>Download failures:</b> %d%s\n",
+		snap.Total(counters.DownloadFailures), digestBreakdown(snap.Top(counters.DownloadFailures, 0))))
+
+	bc := snap[counters.Broadcasts]
+	b.WriteString(fmt.Sprintf("‣ <b>Broadcasts:</b> %d (%d sent, %d failed)\n", bc["runs"], bc["sent"], bc["failed"]))
+
+	if total := hits + misses; total > 0 {
+		b.WriteString(fmt.Sprintf("‣ <b>Cache hit rate:</b> %.1f%% of %d lookups\n", float64(hits)*100/float64(total), total))
+	} else {
+		b.WriteString("‣ <b>Cache hit rate:</b> no lookups\n")
+	}
+
+	size, files := dirUsage(config.Get().DownloadsDir)
+	b.WriteString(fmt.Sprintf("‣ <b>Downloads:</b> %s in %d files\n", humanBytes(uint64(size)), files))
+
+	b.WriteString(fmt.Sprintf("\n<b>Top errors</b> (%d in total)\n", snap.Total(counters.Errors)))
+	top := snap.Top(counters.Errors, digestTopErrors)
+	if len(top) == 0 {
+		b.WriteString("None 🎉\n")
+	}
+	for i, e := range top {
+		b.WriteString(fmt.Sprintf("%d. <code>%s</code> × %d\n", i+1, html.EscapeString(truncate(config.Redact(e.Key), 120)), e.N))
+	}
+	return b.String()
+}
+
+// digestBreakdown renders counts by key, such as " (youtube 12, spotify 3)".
+func digestBreakdown(counts []counters.Count) string {
+	if len(counts) == 0 {
+		return ""
+	}
+	parts := make([]string, len(counts))
+	for i, c := range counts {
+		key := c.Key
+		if key == "" {
+			key = "unknown"
+		}
+		parts[i] = fmt.Sprintf("%s %d", html.EscapeString(key), c.N)
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// dirUsage returns the total size and number of the regular files under dir.
+func dirUsage(dir string) (int64, int) {
+	var size int64
+	var files int
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+			files++
+		}
+		return nil
+	})
+	return size, files
+}
@@ -0,0 +1,48 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"expvar"
+	"sync"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/shutdown"
+	"ashokshau/tgmusic/src/core/workpool"
+)
+
+var (
+	dispatcher     *workpool.Pool
+	dispatcherOnce sync.Once
+)
+
+// startDispatcher starts the worker pool that runs every handler. Updates are submitted at high priority, so
+// that a burst waits for room rather than dropping one that the player's state depends on.
+func startDispatcher() {
+	dispatcherOnce.Do(func() {
+		cfg := config.Get()
+		dispatcher = workpool.New(int(cfg.DispatchWorkers), int(cfg.DispatchQueue))
+		expvar.Publish("dispatcher", expvar.Func(func() any { return dispatcher.Stats() }))
+		shutdown.Register("drain the dispatcher", shutdown.PriorityDrain, func(ctx context.Context) error {
+			return dispatcher.Drain(ctx)
+		})
+	})
+}
+
+// dispatch runs fn on the dispatcher. Before LoadModules has started the dispatcher, fn runs at once.
+func dispatch(priority workpool.Priority, fn func()) {
+	if dispatcher == nil {
+		fn()
+		return
+	}
+	if !dispatcher.Submit(priority, fn) {
+		logger.Debug("[dispatch] The queue is full; dropped a low-priority update.")
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// forceFlag lets admins enqueue a track even though it is a duplicate.
+const forceFlag = "-force"
+
+// stripForceFlag removes the -force flag from command arguments and reports whether it was given.
+func stripForceFlag(args string) (string, bool) {
+	return stripFlag(args, forceFlag)
+}
+
+// stripFlag removes flag from command arguments and reports whether it was given.
+func stripFlag(args, flag string) (string, bool) {
+	fields := strings.Fields(args)
+	kept := fields[:0]
+	found := false
+	for _, f := range fields {
+		if strings.EqualFold(f, flag) {
+			found = true
+			continue
+		}
+		kept = append(kept, f)
+	}
+	if !found {
+		return args, false
+	}
+	return strings.Join(kept, " "), true
+}
+
+// canForce reports whether the message asks to skip the duplicate check and its sender is an admin.
+func canForce(m *telegram.NewMessage) bool {
+	if _, force := stripForceFlag(m.Args()); !force {
+		return false
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return messageDenial(ctx, m, requireAdmin) == ""
+}
+
+// noDuplicates reports whether a chat rejects tracks that are queued or were played recently.
+func noDuplicates(chatID int64) bool {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return db.Instance.GetNoDuplicates(ctx, chatID)
+}
+
+// playedRecently reports whether the chat finished the track within DUPLICATE_WINDOW.
+func playedRecently(chatID int64, platform, trackID string) bool {
+	if config.Get().DuplicateWindow <= 0 {
+		return false
+	}
+	window := time.Duration(config.Get().DuplicateWindow) * time.Minute
+	return cache.History.PlayedWithin(chatID, platform, trackID, window)
+}
+
+// rejectDuplicate tells the user when a track is already queued, or was played recently in a chat
+// that rejects duplicates, and reports whether it did. Admins can override it with -force.
+func rejectDuplicate(m, updater *telegram.NewMessage, chatID int64, platform, trackID, langCode string) bool {
+	if canForce(m) {
+		return false
+	}
+
+	var text string
+	switch pos := cache.ChatCache.TrackPosition(chatID, platform, trackID); {
+	case pos == 0:
+		text = lang.GetString(langCode, "play_track_already_in_queue")
+	case pos > 0:
+		text = fmt.Sprintf(lang.GetString(langCode, "duplicate_in_queue"), pos)
+	case noDuplicates(chatID) && playedRecently(chatID, platform, trackID):
+		text = fmt.Sprintf(lang.GetString(langCode, "duplicate_recent"), config.Get().DuplicateWindow)
+	default:
+		return false
+	}
+
+	_, _ = editTransient(updater, m, text)
+	return true
+}
+
+// isDuplicate reports whether a track is queued or was played recently in the chat.
+func isDuplicate(chatID int64, track cache.MusicTrack) bool {
+	return cache.ChatCache.TrackPosition(chatID, track.Platform, track.ID) >= 0 ||
+		playedRecently(chatID, track.Platform, track.ID)
+}
@@ -0,0 +1,146 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// failedSample is how many recent failures are grouped by error class.
+	failedSample = 200
+	// failedShown is how many of the most recent failures are listed one by one.
+	failedShown = 10
+)
+
+func init() {
+	registerCallback("fd", &callbackRoute{
+		Allow:  permitCallback(requireOwner),
+		Handle: failedRetryCallback,
+	})
+}
+
+// failedHandler handles the /failed command.
+// It groups recent download failures by error class and lists the latest, with a retry button for each
+// one that a retry might fix.
+func failedHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	failures, err := db.Instance.RecentFailedDownloads(ctx, failedSample)
+	if err != nil {
+		_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "failed_error"), err))
+		return err
+	}
+	if len(failures) == 0 {
+		_, err := m.Reply(lang.GetString(langCode, "failed_empty"))
+		return err
+	}
+
+	type classCount struct {
+		class string
+		count int
+	}
+	var classes []classCount
+	for _, f := range failures {
+		i := slices.IndexFunc(classes, func(c classCount) bool { return c.class == f.Class })
+		if i < 0 {
+			classes = append(classes, classCount{class: f.Class})
+			i = len(classes) - 1
+		}
+		classes[i].count++
+	}
+	slices.SortStableFunc(classes, func(a, b classCount) int { return cmp.Compare(b.count, a.count) })
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(lang.GetString(langCode, "failed_header"), len(failures)))
+	for _, c := range classes {
+		b.WriteString(fmt.Sprintf("\n• %s — <b>%d</b>", html.EscapeString(c.class), c.count))
+	}
+	b.WriteString("\n\n" + lang.GetString(langCode, "failed_recent"))
+
+	kb := telegram.NewKeyboard()
+	var row []telegram.KeyboardButton
+	for i, f := range failures[:min(len(failures), failedShown)] {
+		b.WriteString(fmt.Sprintf("\n%d. <b>%s</b> [%s] · %s · <code>%d</code> · %s",
+			i+1,
+			html.EscapeString(truncate(f.Name, 60)),
+			html.EscapeString(f.Platform),
+			html.EscapeString(f.Class),
+			f.ChatID,
+			time.Unix(f.Time, 0).UTC().Format("2006-01-02 15:04"),
+		))
+		if f.Terminal {
+			b.WriteString(" " + lang.GetString(langCode, "failed_terminal"))
+			continue
+		}
+		row = append(row, telegram.Button.Data(fmt.Sprintf("🔁 %d", i+1), callbackData("fd", "", strconv.FormatInt(f.ID, 10))))
+		if len(row) == 5 {
+			kb.AddRow(row...)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		kb.AddRow(row...)
+	}
+
+	opts := &telegram.SendOptions{}
+	if len(kb.Build().Rows) > 0 {
+		opts.ReplyMarkup = kb.Build()
+	}
+	_, err = m.Reply(b.String(), opts)
+	return err
+}
+
+// failedRetryCallback handles the retry buttons of /failed. The download runs in the background and its
+// outcome is posted as a reply to the list.
+func failedRetryCallback(c *callbackCtx) error {
+	id, _ := strconv.ParseInt(c.Arg(0), 10, 64)
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	f, err := db.Instance.GetFailedDownload(ctx, id)
+	if err != nil {
+		c.Answer(lang.GetString(c.LangCode, "failed_error"), true)
+		return err
+	}
+	if f == nil {
+		c.Answer(lang.GetString(c.LangCode, "failed_gone"), true)
+		return nil
+	}
+	if f.Terminal {
+		c.Answer(lang.GetString(c.LangCode, "failed_not_retryable"), true)
+		return nil
+	}
+
+	auditCB(c.CallbackQuery, "retrydownload", f.Platform+":"+f.TrackID, "")
+	c.Answer(fmt.Sprintf(lang.GetString(c.LangCode, "failed_retrying"), f.Name), false)
+	// The download runs on this dispatcher worker, so retries are bounded like every other handler.
+	text := fmt.Sprintf(lang.GetString(c.LangCode, "failed_retry_ok"), html.EscapeString(f.Name))
+	if _, err := vc.Calls.RetryFailedDownload(f); err != nil {
+		text = fmt.Sprintf(lang.GetString(c.LangCode, "failed_retry_failed"), html.EscapeString(f.Name), html.EscapeString(config.Redact(err.Error())))
+	}
+	if _, err := c.Client.SendMessage(c.ChannelID(), text, &telegram.SendOptions{ReplyID: c.MessageID}); err != nil {
+		logger.Warn("[failed] Failed to report the retry of %s:%s: %v", f.Platform, f.TrackID, err)
+	}
+	return nil
+}
@@ -11,7 +11,7 @@ package handlers
 import (
 	"strings"
 
-	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core"
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/lang"
@@ -19,196 +19,129 @@ import (
 	"github.com/amarnathcjd/gogram/telegram"
 )
 
-// isDev checks if the user is a developer.
-// It takes a telegram.NewMessage object as input.
-// It returns true if the user is a developer, otherwise false.
-func isDev(m *telegram.NewMessage) bool {
-	for _, dev := range config.Conf.DEVS {
-		if dev == m.SenderID() {
-			return true
-		}
-	}
-
-	return false
-}
-
-// adminMode checks if the bot is an admin in the chat.
-// It takes a telegram.NewMessage object as input.
-// It checks if the bot is an admin in the chat.
-// Handle Admin Mode
-// It returns true if the bot is an admin, otherwise false.
-func adminMode(m *telegram.NewMessage) bool {
-	if m.IsPrivate() {
-		return false
-	}
-	chatID := m.ChannelID()
-	ctx, cancel := db.Ctx()
-	defer cancel()
-	langCode := db.Instance.GetLang(ctx, chatID)
-
-	botStatus, err := cache.GetUserAdmin(m.Client, chatID, m.Client.Me().ID, false)
+// botDenial checks that the bot can manage voice chats in chatID. It returns an empty string if it can, or
+// the lang key explaining what is missing.
+func botDenial(client *telegram.Client, chatID int64) string {
+	botStatus, err := cache.GetUserAdmin(client, chatID, client.Me().ID, false)
 	if err != nil {
 		if strings.Contains(err.Error(), "is not an admin in chat") {
-			_, _ = m.Reply(lang.GetString(langCode, "filter_bot_not_admin"))
-			return false
+			return "filter_bot_not_admin"
 		}
-
 		logger.Warn("GetUserAdmin error: %v", err)
-		_, _ = m.Reply(lang.GetString(langCode, "filter_bot_admin_status_failed"))
-		return false
+		return "filter_bot_admin_status_failed"
 	}
 
 	if botStatus.Status != telegram.Admin && botStatus.Status != telegram.Creator {
-		_, _ = m.Reply(lang.GetString(langCode, "filter_bot_not_admin_reload"))
-		return false
+		return "filter_bot_not_admin_reload"
 	}
-
 	if botStatus.Rights != nil && !botStatus.Rights.InviteUsers {
-		_, _ = m.Reply(lang.GetString(langCode, "filter_bot_no_invite_permission"))
-		return false
+		return "filter_bot_no_invite_permission"
 	}
-	userID := m.SenderID()
+	return ""
+}
 
-	getAdminMode := db.Instance.GetAdminMode(ctx, chatID)
-	if getAdminMode == cache.Everyone {
-		return true
+// modeRequirement returns what a chat's admin or play mode asks of the users of its commands. An unknown
+// mode is treated as the strictest one.
+func modeRequirement(mode string) requirement {
+	switch mode {
+	case cache.Everyone:
+		return requireNone
+	case cache.Auth:
+		return requireAuth
+	default:
+		return requireAdmin
 	}
+}
 
-	if getAdminMode == cache.Admins {
-		if db.Instance.IsAdmin(ctx, chatID, userID) {
-			return true
-		}
-		_, _ = m.Reply(lang.GetString(langCode, "filter_not_admin"))
+// adminMode lets a playback-control command through if the bot can manage the voice chat and the sender
+// meets the chat's admin mode. Otherwise it replies with the reason.
+func adminMode(m *telegram.NewMessage) bool {
+	if m.IsPrivate() {
 		return false
 	}
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
 
-	if getAdminMode == cache.Auth {
-		if db.Instance.IsAuthUser(ctx, chatID, userID) {
-			return true
-		}
-		_, _ = m.Reply(lang.GetString(langCode, "filter_not_authorized"))
-		return false
+	reason := botDenial(m.Client, chatID)
+	if reason == "" {
+		reason = messageDenial(ctx, m, modeRequirement(db.Instance.GetAdminMode(ctx, chatID)))
 	}
-
-	_, _ = m.Reply(lang.GetString(langCode, "filter_not_authorized"))
+	if reason == "" {
+		return true
+	}
+	_, _ = replyTransient(m, lang.GetString(db.Instance.GetLang(ctx, chatID), reason), true)
 	return false
 }
 
+// adminModeCB is adminMode for button presses; it answers the callback with the reason.
 func adminModeCB(cb *telegram.CallbackQuery) bool {
 	chatID := cb.ChannelID()
 	ctx, cancel := db.Ctx()
 	defer cancel()
-	langCode := db.Instance.GetLang(ctx, chatID)
-
-	botStatus, err := cache.GetUserAdmin(cb.Client, chatID, cb.Client.Me().ID, false)
-	opts := &telegram.CallbackOptions{Alert: true}
-
-	if err != nil {
-		if strings.Contains(err.Error(), "is not an admin in chat") {
-			_, _ = cb.Answer(lang.GetString(langCode, "filter_bot_not_admin"), opts)
-			return false
-		}
-
-		logger.Warn("GetUserAdmin error: %v", err)
-		_, _ = cb.Answer(lang.GetString(langCode, "filter_bot_admin_status_failed"), opts)
-		return false
-	}
-
-	if botStatus.Status != telegram.Admin && botStatus.Status != telegram.Creator {
-		_, _ = cb.Answer(lang.GetString(langCode, "filter_bot_not_admin_reload"), opts)
-		return false
-	}
 
-	if botStatus.Rights != nil && !botStatus.Rights.InviteUsers {
-		_, _ = cb.Answer(lang.GetString(langCode, "filter_bot_no_invite_permission"), opts)
-		return false
+	reason := botDenial(cb.Client, chatID)
+	if reason == "" {
+		reason = denial(ctx, cb.Client, chatID, callbackInvoker(cb), modeRequirement(db.Instance.GetAdminMode(ctx, chatID)))
 	}
-	userID := cb.SenderID
-
-	getAdminMode := db.Instance.GetAdminMode(ctx, chatID)
-	if getAdminMode == cache.Everyone {
+	if reason == "" {
 		return true
 	}
-
-	if getAdminMode == cache.Admins {
-		if db.Instance.IsAdmin(ctx, chatID, userID) {
-			return true
-		}
-		_, _ = cb.Answer(lang.GetString(langCode, "filter_not_admin"), opts)
-		return false
-	}
-
-	if getAdminMode == cache.Auth {
-		if db.Instance.IsAuthUser(ctx, chatID, userID) {
-			return true
-		}
-		_, _ = cb.Answer(lang.GetString(langCode, "filter_not_authorized"), opts)
-		return false
-	}
-
-	_, _ = cb.Answer(lang.GetString(langCode, "filter_not_authorized"), opts)
+	_, _ = cb.Answer(lang.GetString(db.Instance.GetLang(ctx, chatID), reason), &telegram.CallbackOptions{Alert: true})
 	return false
 }
 
+// playMode lets a play command through if the bot can manage the voice chat and the sender meets the
+// chat's play mode. Otherwise it replies with the reason. Chats mirroring another cannot play their own tracks.
 func playMode(m *telegram.NewMessage) bool {
 	if m.IsPrivate() {
 		return false
 	}
-
 	chatID := m.ChannelID()
 	ctx, cancel := db.Ctx()
 	defer cancel()
-	langCode := db.Instance.GetLang(ctx, chatID)
-	botStatus, err := cache.GetUserAdmin(m.Client, chatID, m.Client.Me().ID, false)
-	if err != nil {
-		if strings.Contains(err.Error(), "is not an admin in chat") {
-			_, _ = m.Reply(lang.GetString(langCode, "filter_bot_not_admin"))
-			return false
-		}
 
-		logger.Warn("GetUserAdmin error: %v", err)
-		_, _ = m.Reply(lang.GetString(langCode, "filter_bot_admin_status_failed"))
+	langCode := db.Instance.GetLang(ctx, chatID)
+	if rejectMirrored(m, chatID, langCode) {
 		return false
 	}
-
-	if botStatus.Status != telegram.Admin && botStatus.Status != telegram.Creator {
-		_, _ = m.Reply(lang.GetString(langCode, "filter_bot_not_admin_reload"))
+	reason := botDenial(m.Client, chatID)
+	if reason != "" && reason != "filter_bot_admin_status_failed" {
+		sendPermissionChecklist(m, langCode)
 		return false
 	}
-
-	if botStatus.Rights != nil && !botStatus.Rights.InviteUsers {
-		_, _ = m.Reply(lang.GetString(langCode, "filter_bot_no_invite_permission"))
-		return false
+	if reason == "" {
+		reason = messageDenial(ctx, m, modeRequirement(db.Instance.GetPlayMode(ctx, chatID)))
 	}
+	if reason == "" {
+		return true
+	}
+	_, _ = replyTransient(m, lang.GetString(langCode, reason), true)
+	return false
+}
 
-	getPlayMode := db.Instance.GetPlayMode(ctx, chatID)
-	if getPlayMode != cache.Everyone {
-		admins, err := cache.GetAdmins(m.Client, chatID, false)
-		if err != nil {
-			logger.Warn("getAdmins error: %v", err)
-			return false
-		}
-
-		var isAdmin bool
-		for _, admin := range admins {
-			if admin.User.ID == m.Sender.ID {
-				isAdmin = true
-				break
+// scopeGuard returns a filter enforcing the registry scope of a command.
+// Group-only commands used in PM get a short explanation with an "add me" button;
+// PM-only commands used in groups are silently ignored.
+func scopeGuard(scope commandScope) func(*telegram.NewMessage) bool {
+	return func(m *telegram.NewMessage) bool {
+		switch scope {
+		case scopeGroup:
+			if !m.IsPrivate() {
+				return true
 			}
-		}
 
-		if !isAdmin {
-			if getPlayMode == cache.Auth {
-				if !db.Instance.IsAuthUser(ctx, chatID, m.Sender.ID) {
-					_, _ = m.Reply(lang.GetString(langCode, "filter_not_authorized_command"))
-					return false
-				}
-			} else {
-				_, _ = m.Reply(lang.GetString(langCode, "filter_not_authorized_command"))
-				return false
-			}
+			ctx, cancel := db.Ctx()
+			defer cancel()
+			langCode := db.Instance.GetLang(ctx, m.ChannelID())
+			_, _ = m.Reply(lang.GetString(langCode, "group_only_command"), &telegram.SendOptions{
+				ReplyMarkup: core.AddMeMarkup(m.Client.Me().Username),
+			})
+			return false
+		case scopePrivate:
+			return m.IsPrivate()
+		default:
+			return true
 		}
 	}
-
-	return true
 }
@@ -0,0 +1,364 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// followFetch is how many of a channel's latest uploads are checked on every poll.
+	followFetch = 10
+	// followMaxNotices is how many new uploads of one channel a chat is told about per poll; older ones are
+	// only marked as seen, so a channel that uploads a batch does not flood the chat.
+	followMaxNotices = 3
+	// followTimeout bounds a single look at a channel's uploads.
+	followTimeout = time.Minute
+)
+
+var releaseRadarOnce sync.Once
+
+func init() {
+	registerCallback("rr", &callbackRoute{
+		Allow:  allowReleaseRadar,
+		Handle: releaseRadarCallback,
+	})
+}
+
+// allowReleaseRadar lets anyone queue a new upload, but only admins may play it at once.
+func allowReleaseRadar(cb *tg.CallbackQuery) string {
+	if args := callbackArgs(cb); len(args) > 0 && args[0] == "q" {
+		return ""
+	}
+	return permitCallback(requireAdmin)(cb)
+}
+
+// followHandler handles the /follow command, which subscribes the chat to the uploads of a YouTube channel
+// given as a URL or an @handle.
+func followHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	cfg := config.Get()
+
+	ref := strings.TrimSpace(m.Args())
+	if ref == "" {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "follow_usage"), cfg.MaxChatFollows))
+		return err
+	}
+	if _, err := dl.ChannelURL(ref); err != nil {
+		_, err = m.Reply(lang.GetString(langCode, "follow_invalid"))
+		return err
+	}
+
+	count, err := db.Instance.CountFollows(ctx, chatID)
+	if err != nil {
+		_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "follow_error"), err))
+		return err
+	}
+	if count >= cfg.MaxChatFollows {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "follow_chat_full"), cfg.MaxChatFollows))
+		return err
+	}
+	if cfg.MaxFollows > 0 {
+		if total, err := db.Instance.CountFollows(ctx, 0); err == nil && total >= cfg.MaxFollows {
+			_, err = m.Reply(lang.GetString(langCode, "follow_global_full"))
+			return err
+		}
+	}
+
+	status, err := m.Reply(lang.GetString(langCode, "follow_resolving"))
+	if err != nil {
+		return err
+	}
+	lookup, cancelLookup := context.WithTimeout(requestCtx(m), followTimeout)
+	defer cancelLookup()
+	channel, err := dl.ChannelUploads(lookup, ref, followFetch)
+	if errors.Is(err, dl.ErrNotChannel) {
+		_, err = status.Edit(lang.GetString(langCode, "follow_invalid"))
+		return err
+	} else if err != nil {
+		_, err = status.Edit(userError(m, langCode, lang.GetString(langCode, "follow_lookup_failed"), err))
+		return err
+	}
+
+	// What the channel has uploaded so far is not new; only what comes after is announced.
+	seen := make([]string, 0, len(channel.Uploads))
+	for _, upload := range channel.Uploads {
+		seen = append(seen, upload.ID)
+	}
+	follow := &db.Follow{ChatID: chatID, ChannelID: channel.ID, Name: channel.Name, UserID: m.SenderID(), Seen: seen}
+	added, err := db.Instance.AddFollow(ctx, follow)
+	if err != nil {
+		_, err = status.Edit(userError(m, langCode, lang.GetString(langCode, "follow_error"), err))
+		return err
+	}
+	if !added {
+		_, err = status.Edit(fmt.Sprintf(lang.GetString(langCode, "follow_exists"), html.EscapeString(channel.Name)))
+		return err
+	}
+
+	audit(m, "follow", channel.ID, channel.Name)
+	_, err = status.Edit(fmt.Sprintf(lang.GetString(langCode, "follow_added"), html.EscapeString(channel.Name)))
+	return err
+}
+
+// unfollowHandler handles the /unfollow command. It takes the number of a channel in /following, its
+// channel ID, URL or @handle.
+func unfollowHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	ref := strings.TrimSpace(m.Args())
+	if ref == "" {
+		_, err := m.Reply(lang.GetString(langCode, "unfollow_usage"))
+		return err
+	}
+	follows, err := db.Instance.GetFollows(ctx, chatID)
+	if err != nil {
+		_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "follow_error"), err))
+		return err
+	}
+
+	follow := findFollow(follows, ref)
+	if follow == nil {
+		if _, err := dl.ChannelURL(ref); err == nil {
+			// A URL or handle only names the channel; its ID is what the subscription is stored under.
+			lookup, cancelLookup := context.WithTimeout(requestCtx(m), followTimeout)
+			defer cancelLookup()
+			if channel, err := dl.ChannelUploads(lookup, ref, 1); err == nil {
+				follow = findFollow(follows, channel.ID)
+			}
+		}
+	}
+	if follow == nil {
+		_, err = m.Reply(lang.GetString(langCode, "unfollow_not_found"))
+		return err
+	}
+
+	if _, err := db.Instance.RemoveFollow(ctx, chatID, follow.ChannelID); err != nil {
+		_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "follow_error"), err))
+		return err
+	}
+	audit(m, "unfollow", follow.ChannelID, follow.Name)
+	_, err = replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "unfollow_done"), html.EscapeString(follow.Name)), true)
+	return err
+}
+
+// findFollow returns the subscription ref names by its number in /following or its channel ID, or nil.
+func findFollow(follows []db.Follow, ref string) *db.Follow {
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && n <= len(follows) {
+		return &follows[n-1]
+	}
+	for i := range follows {
+		if follows[i].ChannelID == ref {
+			return &follows[i]
+		}
+	}
+	return nil
+}
+
+// followingHandler handles the /following command, which lists the channels the chat follows.
+func followingHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	follows, err := db.Instance.GetFollows(ctx, chatID)
+	if err != nil {
+		_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "follow_error"), err))
+		return err
+	}
+	if len(follows) == 0 {
+		_, err = m.Reply(lang.GetString(langCode, "following_empty"))
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "following_header"), len(follows), config.Get().MaxChatFollows))
+	for i, follow := range follows {
+		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "following_entry"),
+			i+1, follow.ChannelID, html.EscapeString(follow.Name), follow.ChannelID))
+	}
+	_, err = m.Reply(sb.String(), &tg.SendOptions{LinkPreview: false})
+	return err
+}
+
+// startReleaseRadar starts checking followed channels for new uploads every FOLLOW_POLL_INTERVAL.
+func startReleaseRadar(c *tg.Client) {
+	releaseRadarOnce.Do(func() {
+		go func() {
+			for {
+				// The interval is read on every round, so /reloadconfig applies from the next one.
+				time.Sleep(time.Duration(config.Get().FollowPoll) * time.Second)
+				pollFollowedChannels(c)
+			}
+		}()
+	})
+}
+
+// pollFollowedChannels looks at the latest uploads of every followed channel once, and tells each chat
+// following it about the ones it has not seen.
+func pollFollowedChannels(c *tg.Client) {
+	ctx, cancel := db.Ctx()
+	channels, err := db.Instance.FollowedChannels(ctx)
+	cancel()
+	if err != nil {
+		logger.Warn("[follow] Failed to load the followed channels: %v", err)
+		return
+	}
+
+	for _, channelID := range channels {
+		lookup, cancelLookup := context.WithTimeout(context.Background(), followTimeout)
+		channel, err := dl.ChannelUploads(lookup, channelID, followFetch)
+		cancelLookup()
+		if err != nil {
+			logger.Warn("[follow] Failed to check the uploads of %s: %v", channelID, err)
+			continue
+		}
+
+		ctx, cancel := db.Ctx()
+		follows, err := db.Instance.ChannelFollows(ctx, channelID)
+		cancel()
+		if err != nil {
+			logger.Warn("[follow] Failed to load the followers of %s: %v", channelID, err)
+			continue
+		}
+		for _, follow := range follows {
+			announceUploads(c, follow, channel)
+		}
+	}
+}
+
+// announceUploads tells the chat of follow about the uploads of channel it has not seen, oldest first.
+func announceUploads(c *tg.Client, follow db.Follow, channel *dl.Channel) {
+	var fresh []cache.MusicTrack
+	for _, upload := range channel.Uploads {
+		if !slices.Contains(follow.Seen, upload.ID) {
+			fresh = append(fresh, upload)
+		}
+	}
+	if len(fresh) == 0 {
+		return
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	ids := make([]string, 0, len(fresh))
+	for _, upload := range fresh {
+		ids = append(ids, upload.ID)
+	}
+	// Marking them first means a failed post is not retried on every poll.
+	if err := db.Instance.MarkFollowSeen(ctx, follow.ChatID, follow.ChannelID, ids); err != nil {
+		logger.Warn("[follow] Failed to mark the uploads of %s seen in %d: %v", follow.ChannelID, follow.ChatID, err)
+		return
+	}
+
+	langCode := db.Instance.GetLang(ctx, follow.ChatID)
+	name := channel.Name
+	if name == "" {
+		name = follow.Name
+	}
+	notices := fresh[:min(len(fresh), followMaxNotices)]
+	for i := len(notices) - 1; i >= 0; i-- {
+		upload := notices[i]
+		text := fmt.Sprintf(lang.GetString(langCode, "release_radar_new"),
+			html.EscapeString(name), upload.URL, html.EscapeString(upload.Name), cache.SecToMin(upload.Duration))
+		kb := tg.NewKeyboard().AddRow(
+			tg.Button.Data(lang.GetString(langCode, "release_radar_play"), callbackData("rr", "", "p", upload.ID)),
+			tg.Button.Data(lang.GetString(langCode, "release_radar_queue"), callbackData("rr", "", "q", upload.ID)),
+		).AddRow(core.CloseBtn)
+		if _, err := c.SendMessage(follow.ChatID, text, &tg.SendOptions{ReplyMarkup: kb.Build()}); err != nil {
+			logger.Info("[follow] Failed to announce %s in %d: %v", upload.ID, follow.ChatID, err)
+			return
+		}
+	}
+}
+
+// releaseRadarCallback queues a new upload from a release-radar notice, or plays it at once.
+func releaseRadarCallback(c *callbackCtx) error {
+	chatID := c.ChannelID()
+	playNow := c.Arg(0) == "p"
+	videoID := c.Arg(1)
+	if videoID == "" {
+		c.Answer(lang.GetString(c.LangCode, "callback_stale"), true)
+		return nil
+	}
+	if cache.ChatCache.IsFull(chatID) {
+		c.Answer(lang.GetString(c.LangCode, "play_queue_full"), true)
+		return nil
+	}
+
+	notice, err := c.GetMessage()
+	if err != nil {
+		return err
+	}
+	c.Answer(lang.GetString(c.LangCode, "release_radar_fetching"), false)
+	updater, err := notice.Reply(lang.GetString(c.LangCode, "play_searching"))
+	if err != nil {
+		return err
+	}
+	// The notice belongs to the bot, so the status message stands in for the request it would otherwise reply to.
+	if rejectQuietHours(updater, chatID, c.LangCode) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), followTimeout)
+	defer cancel()
+	tracks, err := dl.NewYouTubeData("https://www.youtube.com/watch?v=" + videoID).GetInfo(ctx)
+	if err != nil || len(tracks.Results) == 0 {
+		if err == nil {
+			err = errors.New("no track found")
+		}
+		_, err = editTransient(updater, nil, userError(updater, c.LangCode, lang.GetString(c.LangCode, "release_radar_unavailable"), err))
+		return err
+	}
+	track := tracks.Results[0]
+	auditCB(c.CallbackQuery, "release_radar", videoID, fmt.Sprintf("now=%t", playNow))
+
+	if !playNow || !cache.ChatCache.IsActive(chatID) {
+		return handleSingleTrack(updater, updater, track, "", chatID, false, c.LangCode)
+	}
+
+	// Something is playing: queue the upload, move it to the front and skip to it.
+	if err := handleSingleTrack(updater, updater, track, "", chatID, false, c.LangCode); err != nil {
+		return err
+	}
+	queue := cache.ChatCache.GetQueue(chatID)
+	last := len(queue) - 1
+	if last < 1 || queue[last].TrackID != track.ID {
+		return nil
+	}
+	if last > 1 && !cache.ChatCache.Move(chatID, last, 1) {
+		return nil
+	}
+	return vc.Calls.PlayNext(chatID)
+}
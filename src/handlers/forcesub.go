@@ -0,0 +1,233 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// memberships caches whether a user has joined a force-subscribe channel, keyed by "channel:user".
+var memberships = cache.NewCache[bool](10 * time.Minute)
+
+// channelLinks caches the join link of each force-subscribe channel.
+var channelLinks = cache.NewCache[string](time.Hour)
+
+func init() {
+	registerCallback("fs", &callbackRoute{
+		Allow:  forceSubPresser,
+		Handle: forceSubCallback,
+	})
+}
+
+// forceSubChannels returns the channels the members of chatID must join: the deployment's and the chat's own.
+func forceSubChannels(chatID int64) []string {
+	var channels []string
+	if ch := config.Get().ForceSub; ch != "" {
+		channels = append(channels, ch)
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if ch := db.Instance.GetForceSub(ctx, chatID); ch != "" && ch != config.Get().ForceSub {
+		channels = append(channels, ch)
+	}
+	return channels
+}
+
+// isSubscribed reports whether userID has joined channel. When the bot cannot tell, such as when it is not an
+// admin of the channel, the user is let through rather than locked out.
+func isSubscribed(client *tg.Client, channel string, userID int64) bool {
+	key := channel + ":" + strconv.FormatInt(userID, 10)
+	if joined, ok := memberships.Get(key); ok {
+		return joined
+	}
+	ref, ok := config.ChannelRef(channel)
+	if !ok {
+		return true
+	}
+
+	joined := true
+	member, err := client.GetChatMember(ref, userID)
+	switch {
+	case err != nil && strings.Contains(err.Error(), "USER_NOT_PARTICIPANT"):
+		joined = false
+	case err != nil:
+		logger.Warn("[forceSub] Failed to check whether %d joined %s, letting them through: %v", userID, channel, err)
+	default:
+		joined = member.Status != tg.Left && member.Status != tg.Kicked
+	}
+	memberships.Set(key, joined)
+	return joined
+}
+
+// channelLink returns a link to join channel, or "" if the bot cannot get one.
+func channelLink(client *tg.Client, channel string) string {
+	ref, ok := config.ChannelRef(channel)
+	if !ok {
+		return ""
+	}
+	if username, ok := ref.(string); ok {
+		return "https://t.me/" + username
+	}
+	if link, ok := channelLinks.Get(channel); ok {
+		return link
+	}
+	invite, err := client.GetChatInviteLink(ref)
+	if err != nil {
+		logger.Warn("[forceSub] Failed to get the invite link of %s: %v", channel, err)
+		return ""
+	}
+	exported, ok := invite.(*tg.ChatInviteExported)
+	if !ok {
+		return ""
+	}
+	channelLinks.Set(channel, exported.Link)
+	return exported.Link
+}
+
+// forceSubExempt reports whether inv skips the force-subscribe check in chatID: anonymous admins and channels,
+// whose membership cannot be checked, developers, chat admins and whoever requested the track that is playing.
+func forceSubExempt(client *tg.Client, chatID int64, inv invoker) bool {
+	if inv.Kind != senderUser {
+		return true
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if denial(ctx, client, chatID, inv, requireSudo) == "" || isChatAdmin(ctx, client, chatID, inv.ID) {
+		return true
+	}
+	track := cache.ChatCache.GetPlayingTrack(chatID)
+	return track != nil && track.UserID == inv.ID
+}
+
+// missingChannel returns the first channel of chatID that userID has not joined, or "" if there is none.
+func missingChannel(client *tg.Client, chatID, userID int64) string {
+	for _, channel := range forceSubChannels(chatID) {
+		if !isSubscribed(client, channel, userID) {
+			return channel
+		}
+	}
+	return ""
+}
+
+// forceSubscribe is the filter of the commands gated behind the force-subscribe channels. A sender who has not
+// joined one gets a join button and a button to check again.
+func forceSubscribe(m *tg.NewMessage) bool {
+	chatID := m.ChannelID()
+	inv := resolveInvoker(m.Message)
+	if forceSubExempt(m.Client, chatID, inv) {
+		return true
+	}
+	channel := missingChannel(m.Client, chatID, inv.ID)
+	if channel == "" {
+		return true
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, chatID, inv.ID)
+	kb := tg.NewKeyboard()
+	if link := channelLink(m.Client, channel); link != "" {
+		kb.AddRow(tg.Button.URL(lang.GetString(langCode, "fsub_join_button"), link))
+	}
+	kb.AddRow(tg.Button.Data(lang.GetString(langCode, "fsub_retry_button"), callbackData("fs", "", strconv.FormatInt(inv.ID, 10))))
+	_, _ = replyTransient(m, lang.GetString(langCode, "fsub_required"), false, &tg.SendOptions{ReplyMarkup: kb.Build()})
+	return false
+}
+
+// forceSubPresser lets only the user the force-subscribe message was sent to press its button.
+func forceSubPresser(cb *tg.CallbackQuery) string {
+	args := callbackArgs(cb)
+	if len(args) == 0 || args[0] != strconv.FormatInt(cb.SenderID, 10) {
+		return "fsub_not_yours"
+	}
+	return ""
+}
+
+// forceSubCallback handles the "Try again" button of the force-subscribe message. It checks the presser's
+// membership again, skipping the cache, and removes the message once they have joined.
+func forceSubCallback(c *callbackCtx) error {
+	chatID := c.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, chatID, c.SenderID)
+
+	for _, channel := range forceSubChannels(chatID) {
+		memberships.Delete(channel + ":" + strconv.FormatInt(c.SenderID, 10))
+	}
+	if missingChannel(c.Client, chatID, c.SenderID) != "" {
+		c.Answer(lang.GetString(langCode, "fsub_not_yet"), true)
+		return nil
+	}
+
+	c.Answer(lang.GetString(langCode, "fsub_thanks"), false)
+	_, err := c.Edit(lang.GetString(langCode, "fsub_thanks"))
+	return err
+}
+
+// forceSubHandler handles the /forcesub command.
+// "/forcesub @channel" makes the chat's members join the channel before using playback commands, and
+// "/forcesub off" stops it. The deployment's own channel, if any, applies regardless.
+func forceSubHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	arg := strings.TrimSpace(m.Args())
+	switch strings.ToLower(arg) {
+	case "":
+		current := db.Instance.GetForceSub(ctx, chatID)
+		if current == "" {
+			current = lang.GetString(langCode, "forcesub_none")
+		}
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "forcesub_usage"), html.EscapeString(current)))
+		return err
+	case "off", "disable":
+		if err := db.Instance.SetForceSub(ctx, chatID, ""); err != nil {
+			_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "forcesub_error"), html.EscapeString(err.Error())))
+			return err
+		}
+		audit(m, "forcesub", "", "off")
+		_, err := replyTransient(m, lang.GetString(langCode, "forcesub_cleared"), true)
+		return err
+	}
+
+	ref, ok := config.ChannelRef(arg)
+	if !ok {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "forcesub_invalid"), html.EscapeString(arg)))
+		return err
+	}
+	if _, err := m.Client.ResolvePeer(ref); err != nil {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "forcesub_invalid"), html.EscapeString(arg)))
+		return err
+	}
+
+	channel := fmt.Sprint(ref)
+	if _, isName := ref.(string); isName {
+		channel = "@" + channel
+	}
+	if err := db.Instance.SetForceSub(ctx, chatID, channel); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "forcesub_error"), html.EscapeString(err.Error())))
+		return err
+	}
+	audit(m, "forcesub", "", channel)
+	_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "forcesub_set"), html.EscapeString(channel)))
+	return err
+}
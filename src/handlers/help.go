@@ -10,6 +10,7 @@ package handlers
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"ashokshau/tgmusic/src/core"
@@ -19,6 +20,28 @@ import (
 	"github.com/amarnathcjd/gogram/telegram"
 )
 
+var helpCommandRegex = regexp.MustCompile(`<code>/(\w+)`)
+
+// visibleHelp drops the help lines of commands that are not registered, such as those of disabled features,
+// and lists the aliases of the others.
+func visibleHelp(langCode, content string) string {
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if match := helpCommandRegex.FindStringSubmatch(line); match != nil {
+			name := strings.ToLower(match[1])
+			if !registered[name] {
+				continue
+			}
+			if aliases := commandAliases[name]; len(aliases) > 0 {
+				line += fmt.Sprintf(lang.GetString(langCode, "help_aliases"), strings.Join(aliases, ", "))
+			}
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
 func getHelpCategories(langCode string) map[string]struct {
 	Title   string
 	Content string
@@ -31,27 +54,27 @@ func getHelpCategories(langCode string) map[string]struct {
 	}{
 		"help_user": {
 			Title:   lang.GetString(langCode, "help_user_title"),
-			Content: lang.GetString(langCode, "help_user_content"),
+			Content: visibleHelp(langCode, lang.GetString(langCode, "help_user_content")),
 			Markup:  core.BackHelpMenuKeyboard(),
 		},
 		"help_admin": {
 			Title:   lang.GetString(langCode, "help_admin_title"),
-			Content: lang.GetString(langCode, "help_admin_content"),
+			Content: visibleHelp(langCode, lang.GetString(langCode, "help_admin_content")),
 			Markup:  core.BackHelpMenuKeyboard(),
 		},
 		"help_devs": {
 			Title:   lang.GetString(langCode, "help_devs_title"),
-			Content: lang.GetString(langCode, "help_devs_content"),
+			Content: visibleHelp(langCode, lang.GetString(langCode, "help_devs_content")),
 			Markup:  core.BackHelpMenuKeyboard(),
 		},
 		"help_owner": {
 			Title:   lang.GetString(langCode, "help_owner_title"),
-			Content: lang.GetString(langCode, "help_owner_content"),
+			Content: visibleHelp(langCode, lang.GetString(langCode, "help_owner_content")),
 			Markup:  core.BackHelpMenuKeyboard(),
 		},
 		"help_playlist": {
 			Title:   lang.GetString(langCode, "help_playlist_title"),
-			Content: lang.GetString(langCode, "help_playlist_content"),
+			Content: visibleHelp(langCode, lang.GetString(langCode, "help_playlist_content")),
 			Markup:  core.BackHelpMenuKeyboard(),
 		},
 	}
@@ -9,6 +9,12 @@
 package handlers
 
 import (
+	"errors"
+	"strconv"
+
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
 	"github.com/amarnathcjd/gogram/telegram"
 )
 
@@ -60,6 +66,20 @@ func isValidMedia(reply *telegram.NewMessage) bool {
 	return true
 }
 
+// voiceNote reports whether msg is a voice note rather than an audio file, and if so how long it is in seconds.
+func voiceNote(msg *telegram.NewMessage) (int32, bool) {
+	doc := msg.Document()
+	if doc == nil {
+		return 0, false
+	}
+	for _, attr := range doc.Attributes {
+		if a, ok := attr.(*telegram.DocumentAttributeAudio); ok && a.Voice {
+			return a.Duration, true
+		}
+	}
+	return 0, false
+}
+
 // coalesce returns the first non-empty string.
 // It takes two strings as input.
 // It returns the first non-empty string.
@@ -79,3 +99,37 @@ func truncate(s string, max int) string {
 	}
 	return s[:max]
 }
+
+// getChatTitle resolves a chat's title from the client's peer cache.
+// It falls back to the chat ID when the title cannot be resolved.
+func getChatTitle(client *telegram.Client, chatID int64) string {
+	switch {
+	case chatID < -1000000000000:
+		if channel, err := client.GetChannel(-chatID - 1000000000000); err == nil && channel.Title != "" {
+			return channel.Title
+		}
+	case chatID < 0:
+		if chat, err := client.GetChat(-chatID); err == nil && chat.Title != "" {
+			return chat.Title
+		}
+	}
+
+	return strconv.FormatInt(chatID, 10)
+}
+
+// playbackStateText returns a user-facing explanation for errors caused by the chat's playback state.
+// It returns false for any other error so the caller can fall back to its own message.
+func playbackStateText(langCode string, err error) (string, bool) {
+	switch {
+	case errors.Is(err, vc.ErrAlreadyPaused):
+		return lang.GetString(langCode, "playback_already_paused"), true
+	case errors.Is(err, vc.ErrNotPaused):
+		return lang.GetString(langCode, "playback_not_paused"), true
+	case errors.Is(err, vc.ErrPlaybackBusy):
+		return lang.GetString(langCode, "playback_busy"), true
+	case errors.Is(err, vc.ErrNotPlaying):
+		return lang.GetString(langCode, "no_track_playing"), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,340 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/core/premium"
+	"ashokshau/tgmusic/src/core/shutdown"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// maxDataImportSize is the largest file, in bytes, that /importdata accepts.
+	maxDataImportSize = 256 * 1024
+	// maxDataImportEntries is how many entries of a file /importdata reads.
+	maxDataImportEntries = 200
+	// dataImportTimeout bounds resolving the entries of one import.
+	dataImportTimeout = 10 * time.Minute
+	// dataImportProgressEvery is how often the progress message of an import is edited.
+	dataImportProgressEvery = 3 * time.Second
+	// maxReportedFailures is how many unresolvable entries the final report lists.
+	maxReportedFailures = 5
+)
+
+// Formats of the files /importdata reads, as named in its messages.
+const (
+	importFormatJSON   = "json"
+	importFormatTitled = "titled"
+	importFormatURLs   = "urls"
+)
+
+// dataImports holds the users with an import running, so each user runs one at a time.
+var dataImports sync.Map
+
+// importEntry is one track of an imported file. Title is only a hint and may be empty.
+type importEntry struct {
+	Title string
+	URL   string
+}
+
+// label returns how the entry is shown when it cannot be resolved.
+func (e importEntry) label() string {
+	if e.Title != "" {
+		return e.Title
+	}
+	return e.URL
+}
+
+// importDataHandler handles the /importdata command. It must reply to a playlist exported by another music
+// bot: a list of links, a list of "title | link" lines or a JSON array of {title, url} objects. The entries are
+// resolved in the background and saved to a new playlist of the sender, named by the command's argument.
+func importDataHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	userID := m.SenderID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	if !m.IsReply() {
+		_, err := m.Reply(lang.GetString(langCode, "import_data_usage"))
+		return err
+	}
+	reply, err := m.GetReplyMessage()
+	if err != nil || reply.Document() == nil {
+		_, err = m.Reply(lang.GetString(langCode, "import_data_usage"))
+		return err
+	}
+	if reply.Document().Size > maxDataImportSize {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "import_data_too_large"), maxDataImportSize/1024))
+		return err
+	}
+
+	playlists, err := db.Instance.GetUserPlaylists(ctx, userID)
+	if err != nil {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "import_data_failed"), err.Error()))
+		return err
+	}
+	if limits := premium.For(userID); len(playlists) >= limits.Playlists {
+		_, _ = m.Reply(playlistLimitReached(langCode, limits))
+		return telegram.EndGroup
+	}
+
+	if _, running := dataImports.LoadOrStore(userID, struct{}{}); running {
+		_, err = m.Reply(lang.GetString(langCode, "import_data_running"))
+		return err
+	}
+	started := false
+	defer func() {
+		if !started {
+			dataImports.Delete(userID)
+		}
+	}()
+
+	var buf bytes.Buffer
+	if _, err = reply.Download(&telegram.DownloadOptions{Buffer: &buf}); err != nil {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "import_data_failed"), err.Error()))
+		return err
+	}
+	entries, format, invalid := parseImportData(buf.Bytes())
+	if len(entries) == 0 {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "import_data_nothing"), invalid))
+		return err
+	}
+
+	name := strings.TrimSpace(m.Args())
+	if name == "" {
+		name = "Imported " + time.Now().Format("2 Jan 2006")
+	}
+	if len([]rune(name)) > 40 {
+		name = string([]rune(name)[:40])
+	}
+
+	status, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "import_data_started"),
+		lang.GetString(langCode, "import_format_"+format), len(entries)))
+	if err != nil {
+		return err
+	}
+	started = true
+	go func() {
+		defer dataImports.Delete(userID)
+		runDataImport(status, userID, name, entries, invalid, langCode)
+	}()
+	return telegram.EndGroup
+}
+
+// runDataImport resolves the entries of an import, saves what it found to a new playlist and edits status
+// with the progress and the final report. invalid is the number of lines already found unreadable.
+func runDataImport(status *telegram.NewMessage, userID int64, name string, entries []importEntry, invalid int, langCode string) {
+	ctx, cancel := context.WithTimeout(shutdown.Context(), dataImportTimeout)
+	defer cancel()
+
+	lastEdit := time.Now()
+	progress := func(done int) {
+		if time.Since(lastEdit) < dataImportProgressEvery {
+			return
+		}
+		lastEdit = time.Now()
+		_, _ = status.Edit(fmt.Sprintf(lang.GetString(langCode, "import_data_progress"), done, len(entries)))
+	}
+	songs, failed := resolveImport(ctx, entries, progress)
+	if ctx.Err() != nil && len(songs) == 0 {
+		_, _ = status.Edit(lang.GetString(langCode, "import_data_timeout"))
+		return
+	}
+	if len(songs) == 0 {
+		_, _ = status.Edit(fmt.Sprintf(lang.GetString(langCode, "import_data_nothing"), invalid+len(failed)))
+		return
+	}
+
+	dbCtx, dbCancel := db.Ctx()
+	defer dbCancel()
+	id, err := db.Instance.CreatePlaylist(dbCtx, name, userID)
+	if err == nil {
+		err = db.Instance.AddSongsToPlaylist(dbCtx, id, songs)
+	}
+	if err != nil {
+		logger.Warn("[importdata] Failed to save the import of %d: %v", userID, err)
+		_, _ = status.Edit(fmt.Sprintf(lang.GetString(langCode, "import_data_failed"), err.Error()))
+		return
+	}
+
+	text := fmt.Sprintf(lang.GetString(langCode, "import_data_done"), html.EscapeString(name), id, len(songs), invalid+len(failed))
+	if len(failed) > 0 {
+		text += lang.GetString(langCode, "import_data_unresolved")
+		for i, entry := range failed {
+			if i == maxReportedFailures {
+				text += fmt.Sprintf(lang.GetString(langCode, "import_data_unresolved_more"), len(failed)-i)
+				break
+			}
+			text += "\n• " + html.EscapeString(truncate(entry.label(), 80))
+		}
+	}
+	_, _ = status.Edit(text)
+}
+
+// parseImportData reads the entries of an exported playlist, detecting its format. It returns the entries with
+// a link, the format's name and how many entries had none.
+func parseImportData(data []byte) ([]importEntry, string, int) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if bytes.HasPrefix(data, []byte("[")) {
+		entries, invalid := parseImportJSON(data)
+		return entries, importFormatJSON, invalid
+	}
+
+	var entries []importEntry
+	format := importFormatURLs
+	invalid := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() && len(entries) < maxDataImportEntries {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		entry := importEntry{URL: line}
+		if i := strings.LastIndex(line, "|"); i >= 0 {
+			format = importFormatTitled
+			entry = importEntry{Title: strings.TrimSpace(line[:i]), URL: strings.TrimSpace(line[i+1:])}
+		}
+		if entry.URL = importURL(entry.URL); entry.URL == "" {
+			invalid++
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, format, invalid
+}
+
+// parseImportJSON reads a JSON array of objects with a title and a link, under the key names other bots use.
+func parseImportJSON(data []byte) ([]importEntry, int) {
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, 0
+	}
+	field := func(item map[string]any, keys ...string) string {
+		for _, key := range keys {
+			if s, ok := item[key].(string); ok && strings.TrimSpace(s) != "" {
+				return strings.TrimSpace(s)
+			}
+		}
+		return ""
+	}
+
+	var entries []importEntry
+	invalid := 0
+	for _, item := range items {
+		if len(entries) >= maxDataImportEntries {
+			break
+		}
+		entry := importEntry{
+			Title: field(item, "title", "name"),
+			URL:   importURL(field(item, "url", "link", "webpage_url")),
+		}
+		if entry.URL == "" {
+			invalid++
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, invalid
+}
+
+// importURL returns s as an http(s) link, adding the scheme to bare YouTube links, or "" if it is not one.
+func importURL(s string) string {
+	s = strings.Trim(s, " \t<>\"'")
+	if strings.HasPrefix(s, "youtu.be/") || strings.HasPrefix(s, "youtube.com/") || strings.HasPrefix(s, "www.youtube.com/") {
+		s = "https://" + s
+	}
+	if !strings.HasPrefix(s, "https://") && !strings.HasPrefix(s, "http://") {
+		return ""
+	}
+	return s
+}
+
+// resolveImport turns the entries of an import into playlist songs. YouTube links are looked up together with
+// dl.LookupVideos so a long list costs a few yt-dlp runs; other links go through the provider registry one by
+// one. It returns the songs, without duplicates and in the order of the file, and the entries that could not
+// be resolved. progress is called with the number of entries handled so far.
+func resolveImport(ctx context.Context, entries []importEntry, progress func(done int)) ([]db.Song, []importEntry) {
+	var ids []string
+	for _, entry := range entries {
+		if id := dl.VideoID(entry.URL); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	videos := map[string]cache.MusicTrack{}
+	if len(ids) > 0 {
+		found, err := dl.LookupVideos(ctx, ids)
+		if err != nil {
+			logger.Warn("[importdata] Failed to look up %d YouTube videos: %v", len(ids), err)
+		}
+		if found != nil {
+			videos = found
+		}
+	}
+	progress(len(ids))
+
+	var songs []db.Song
+	var failed []importEntry
+	seen := make(map[string]bool)
+	add := func(track cache.MusicTrack, title string) {
+		key := track.Platform + ":" + track.ID
+		if track.ID == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		if track.Name == "" {
+			track.Name = title
+		}
+		songs = append(songs, db.Song{URL: track.URL, Name: track.Name, TrackID: track.ID, Duration: track.Duration, Platform: track.Platform})
+	}
+
+	done := len(ids)
+	for _, entry := range entries {
+		if id := dl.VideoID(entry.URL); id != "" {
+			if video, ok := videos[id]; ok {
+				add(video, entry.Title)
+			} else {
+				failed = append(failed, entry)
+			}
+			continue
+		}
+
+		done++
+		progress(done)
+		wrapper := dl.NewDownloaderWrapper(entry.URL)
+		if ctx.Err() != nil || !wrapper.IsValid() {
+			failed = append(failed, entry)
+			continue
+		}
+		tracks, err := wrapper.GetInfo(ctx)
+		if err != nil || len(tracks.Results) == 0 {
+			failed = append(failed, entry)
+			continue
+		}
+		for _, track := range tracks.Results {
+			add(track, entry.Title)
+		}
+	}
+	return songs, failed
+}
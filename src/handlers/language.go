@@ -13,7 +13,6 @@ import (
 	"strings"
 
 	"ashokshau/tgmusic/src/core"
-	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/lang"
 
@@ -60,23 +59,13 @@ func setLangCallbackHandler(c *telegram.CallbackQuery) error {
 	if c.IsPrivate() {
 		_ = db.Instance.SetUserLang(ctx, chatID, langCode)
 	} else {
-		admins, err := cache.GetAdmins(c.Client, chatID, false)
-		if err != nil {
-			return err
-		}
-		var isAdmin bool
-		for _, admin := range admins {
-			if admin.User.ID == c.Sender.ID {
-				isAdmin = true
-				break
-			}
-		}
-		if !isAdmin {
+		if denial(ctx, c.Client, chatID, callbackInvoker(c), requireAdmin) != "" {
 			_, err := c.Answer(lang.GetString(langCode, "lang_no_permission"), &telegram.CallbackOptions{Alert: true})
 			return err
 		}
 
 		_ = db.Instance.SetChatLang(ctx, chatID, langCode)
+		auditCB(c, "setlang", "", langCode)
 	}
 
 	_, _ = c.Answer(fmt.Sprintf(lang.GetString(langCode, "lang_updated"), langCode), &telegram.CallbackOptions{Alert: true})
@@ -9,81 +9,247 @@
 package handlers
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/core/reaper"
+
 	tg "github.com/amarnathcjd/gogram/telegram"
 )
 
 var startTime = time.Now()
-var logger tg.Logger
+var logger = logging.For("handlers")
+
+// commandScope tells the dispatcher in which kind of chat a command may run.
+type commandScope int
+
+const (
+	// scopeAny allows the command in both groups and private chats.
+	scopeAny commandScope = iota
+	// scopeGroup restricts the command to groups; private invocations get an explanation.
+	scopeGroup
+	// scopePrivate restricts the command to the bot's PM; group invocations are ignored.
+	scopePrivate
+)
+
+// command is a single entry of the command registry.
+type command struct {
+	names   []string
+	handler func(*tg.NewMessage) error
+	scope   commandScope
+	filter  func(*tg.NewMessage) bool
+	// perm is what the sender must be to use the command; it is checked after the scope and the filter.
+	perm requirement
+	// subscribe makes the sender join the force-subscribe channels first; it is checked after perm.
+	subscribe bool
+	// feature reports whether the deployment enables the feature the command belongs to; nil means always.
+	feature func(f config.Features) bool
+	// long marks commands that may run for minutes, such as a broadcast, so the watchdog does not take them
+	// for wedged handlers.
+	long bool
+}
+
+// Feature selectors used by the registry.
+var (
+	videoFeature     = func(f config.Features) bool { return f.Video }
+	broadcastFeature = func(f config.Features) bool { return f.Broadcasts }
+	playlistFeature  = func(f config.Features) bool { return f.Playlists }
+	radioFeature     = func(f config.Features) bool { return f.Radio }
+	queueIOFeature   = func(f config.Features) bool { return f.QueueIO }
+)
+
+// registered holds the lower-cased names of the commands registered at startup.
+var registered = make(map[string]bool)
+
+// commands is the command registry. New handlers declare their names, scope and filter here.
+var commands = []command{
+	{names: []string{"ping"}, handler: pingHandler},
+	{names: []string{"start", "help"}, handler: startHandler},
+	{names: []string{"lang"}, handler: langHandler},
+	{names: []string{"reload"}, handler: reloadAdminCacheHandler, scope: scopeGroup},
+	{names: []string{"checkperms"}, handler: checkPermsHandler, scope: scopeGroup},
+	{names: []string{"privacy"}, handler: privacyHandler},
+	{names: []string{"mydata"}, handler: myDataHandler, scope: scopePrivate},
+	{names: []string{"deleteme"}, handler: deleteMeHandler, scope: scopePrivate},
+	{names: []string{"report"}, handler: reportHandler},
+	{names: []string{"premium"}, handler: premiumHandler},
+	{names: []string{"trim"}, handler: trimHandler},
+	{names: []string{"convert"}, handler: convertHandler},
+
+	{names: []string{"play"}, handler: playHandler, scope: scopeGroup, filter: playMode, subscribe: true},
+	{names: []string{"playall"}, handler: playAllHandler, scope: scopeGroup, filter: playMode, subscribe: true, feature: playlistFeature},
+	{names: []string{"vPlay"}, handler: vPlayHandler, scope: scopeGroup, filter: playMode, subscribe: true, feature: videoFeature},
+	{names: []string{"importqueue"}, handler: importQueueHandler, scope: scopeGroup, filter: playMode, subscribe: true, feature: queueIOFeature, long: true},
+
+	{names: []string{"loop"}, handler: loopHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"remove"}, handler: removeHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"skip"}, handler: skipHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"replay"}, handler: replayHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"previous", "prev"}, handler: previousHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"stop", "end"}, handler: stopHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"mute"}, handler: muteHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"unmute"}, handler: unmuteHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"pause"}, handler: pauseHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"resume"}, handler: resumeHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"queue"}, handler: queueHandler, scope: scopeGroup, filter: adminMode},
+	{names: []string{"exportqueue"}, handler: exportQueueHandler, scope: scopeGroup, filter: adminMode, feature: queueIOFeature},
+	{names: []string{"seek"}, handler: seekHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"speed"}, handler: speedHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"filters", "filter"}, handler: audioFilterHandler, scope: scopeGroup, filter: controlMode, subscribe: true},
+	{names: []string{"authList"}, handler: authListHandler, scope: scopeGroup, filter: adminMode},
+	{names: []string{"addAuth", "auth"}, handler: addAuthHandler, scope: scopeGroup, filter: adminMode},
+	{names: []string{"removeAuth", "unAuth", "rmAuth"}, handler: removeAuthHandler, scope: scopeGroup, filter: adminMode},
+
+	{names: []string{"activevc", "active_vc", "av"}, handler: activeVcHandler, scope: scopePrivate, perm: requireOwner},
+	{names: []string{"stats"}, handler: sysStatsHandler, perm: requireSudo},
+	{names: []string{"chatstats"}, handler: chatStatsHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"myquota"}, handler: myQuotaHandler, scope: scopeGroup},
+	{names: []string{"clear_assistants", "clearAss"}, handler: clearAssistantsHandler, perm: requireSudo},
+	{names: []string{"assistantinfo", "assistants"}, handler: assistantInfoHandler, perm: requireSudo},
+	{names: []string{"assistanthealth"}, handler: assistantHealthHandler, perm: requireSudo},
+	{names: []string{"waitlist"}, handler: waitListHandler, perm: requireSudo},
+	{names: []string{"bump"}, handler: bumpHandler, perm: requireSudo},
+	{names: []string{"link"}, handler: linkHandler, perm: requireSudo},
+	{names: []string{"unlink"}, handler: unlinkHandler, perm: requireSudo},
+	{names: []string{"links"}, handler: linksHandler, perm: requireSudo},
+	{names: []string{"leaveAll"}, handler: leaveAllHandler, scope: scopePrivate, perm: requireSudo},
+	{names: []string{"broadcast", "gCast"}, handler: broadcastHandler, perm: requireSudo, feature: broadcastFeature, long: true},
+	{names: []string{"cancelBroadcast"}, handler: cancelBroadcastHandler, perm: requireSudo, feature: broadcastFeature},
+	{names: []string{"scheduledbroadcasts"}, handler: scheduledBroadcastsHandler, perm: requireSudo, feature: broadcastFeature},
+	{names: []string{"resumebroadcast"}, handler: resumeBroadcastHandler, perm: requireSudo, feature: broadcastFeature, long: true},
+	{names: []string{"broadcastexclude"}, handler: broadcastExcludeHandler, perm: requireSudo, feature: broadcastFeature},
+	{names: []string{"broadcastinclude"}, handler: broadcastIncludeHandler, perm: requireSudo, feature: broadcastFeature},
+	{names: []string{"broadcastexcluded"}, handler: broadcastExcludedHandler, perm: requireSudo, feature: broadcastFeature},
+	{names: []string{"setannouncement"}, handler: setAnnouncementHandler, scope: scopePrivate, perm: requireOwner},
+	{names: []string{"delannouncement"}, handler: delAnnouncementHandler, scope: scopePrivate, perm: requireOwner},
+	{names: []string{"reloadconfig"}, handler: reloadConfigHandler, perm: requireOwner},
+	{names: []string{"setlog"}, handler: setLogHandler, perm: requireSudo},
+	{names: []string{"logs"}, handler: logsHandler, perm: requireSudo},
+	{names: []string{"slowlog"}, handler: slowLogHandler, perm: requireOwner},
+	{names: []string{"audit"}, handler: auditHandler, perm: requireOwner},
+	{names: []string{"top"}, handler: topHandler, perm: requireOwner},
+	{names: []string{"gblockword"}, handler: globalBlockWordHandler, perm: requireOwner},
+	{names: []string{"gunblockword"}, handler: globalUnblockWordHandler, perm: requireOwner},
+	{names: []string{"gblockwords"}, handler: globalBlockWordsHandler, perm: requireOwner},
+	{names: []string{"grantpremium"}, handler: grantPremiumHandler, perm: requireOwner},
+	{names: []string{"revokepremium"}, handler: revokePremiumHandler, perm: requireOwner},
+	{names: []string{"profile"}, handler: profileHandler, perm: requireOwner, long: true},
+	{names: []string{"selftest"}, handler: selfTestHandler, perm: requireOwner},
+	{names: []string{"reports"}, handler: reportsHandler, perm: requireSudo},
+	{names: []string{"failed"}, handler: failedHandler, perm: requireOwner},
+
+	{names: []string{"settings"}, handler: settingsHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"cleanmode"}, handler: cleanModeHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"autopause"}, handler: autoPauseHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"vctitle"}, handler: vcTitleHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"noduplicates", "nodupes"}, handler: noDuplicatesHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"queuenotice"}, handler: queueNoticeHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"keepqueue"}, handler: keepQueueHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"announce"}, handler: announceHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"forcesub"}, handler: forceSubHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"approval"}, handler: approvalHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"weeklystats"}, handler: weeklyStatsHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"quiethours"}, handler: quietHoursHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"requestlimit"}, handler: requestLimitHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"blockword"}, handler: blockWordHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"unblockword"}, handler: unblockWordHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"blockwords"}, handler: blockWordsHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"schedule"}, handler: scheduleHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"scheduled"}, handler: scheduledHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"follow"}, handler: followHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"unfollow"}, handler: unfollowHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"following"}, handler: followingHandler, scope: scopeGroup, filter: adminMode},
+	{names: []string{"nptoken"}, handler: npTokenHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"timezone"}, handler: timezoneHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"setnptemplate"}, handler: setNowPlayingTemplateHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"delnptemplate"}, handler: delNowPlayingTemplateHandler, scope: scopeGroup, filter: adminMode, perm: requireAdmin},
+	{names: []string{"radio247", "247"}, handler: radio247Handler, scope: scopeGroup, filter: adminMode, perm: requireAdmin, feature: radioFeature},
+
+	{names: []string{"cplist", "createplaylist"}, handler: createPlaylistHandler, feature: playlistFeature},
+	{names: []string{"dlplist", "deleteplaylist"}, handler: deletePlaylistHandler, feature: playlistFeature},
+	{names: []string{"addtoplist", "addtoplaylist"}, handler: addToPlaylistHandler, feature: playlistFeature},
+	{names: []string{"rmplist", "removefromplaylist"}, handler: removeFromPlaylistHandler, feature: playlistFeature},
+	{names: []string{"plistinfo", "playlistinfo"}, handler: playlistInfoHandler, feature: playlistFeature},
+	{names: []string{"myplist", "myplaylists"}, handler: myPlaylistsHandler, feature: playlistFeature},
+	{names: []string{"importdata"}, handler: importDataHandler, feature: playlistFeature, long: true},
+}
+
+// registerCommands wires every registry entry, under its names and aliases, to the client.
+// The scope guard runs before the entry's own filter and permission so those checks never fire in the wrong chat type.
+// An alias is the same entry under another name, so it goes through the same checks and is tracked under the
+// entry's first name. Commands of features disabled in the configuration are skipped, aliases included.
+// aliases holds the aliases of each entry by its index, as returned by resolveAliases.
+func registerCommands(c *tg.Client, aliases map[int][]string) {
+	features := config.Get().Features
+	for i, cmd := range commands {
+		if cmd.feature != nil && !cmd.feature(features) {
+			continue
+		}
+		names := append(slices.Clone(cmd.names), aliases[i]...)
+		noteAliases(names)
+
+		filters := []tg.Filter{tg.FilterFunc(scopeGuard(cmd.scope))}
+		if cmd.filter != nil {
+			filters = append(filters, tg.FilterFunc(cmd.filter))
+		}
+		if cmd.perm != requireNone {
+			filters = append(filters, tg.FilterFunc(permit(cmd.perm)))
+		}
+		if cmd.subscribe {
+			filters = append(filters, tg.FilterFunc(forceSubscribe))
+		}
+
+		if cmd.long {
+			allowLongRun("/" + cmd.names[0])
+		}
+		for _, name := range names {
+			c.On("command:"+name, guard("/"+cmd.names[0], cmd.handler), filters...)
+			registered[strings.ToLower(name)] = true
+		}
+	}
+}
 
 // LoadModules loads all the handlers.
-// It takes a telegram client as input.
-func LoadModules(c *tg.Client) {
+// It takes a telegram client as input and fails if the command aliases collide.
+func LoadModules(c *tg.Client) error {
+	aliases, err := resolveAliases(config.Get().Aliases)
+	if err != nil {
+		return fmt.Errorf("COMMAND_ALIASES: %w", err)
+	}
+
 	_, _ = c.UpdatesGetState()
-	logger = c.Log
-
-	c.On("command:ping", pingHandler)
-	c.On("command:start", startHandler)
-	c.On("command:help", startHandler)
-	c.On("command:lang", langHandler)
-	c.On("command:reload", reloadAdminCacheHandler)
-	c.On("command:privacy", privacyHandler)
-
-	c.On("command:play", playHandler, tg.FilterFunc(playMode))
-	c.On("command:vPlay", vPlayHandler, tg.FilterFunc(playMode))
-
-	c.On("command:loop", loopHandler, tg.FilterFunc(adminMode))
-	c.On("command:remove", removeHandler, tg.FilterFunc(adminMode))
-	c.On("command:skip", skipHandler, tg.FilterFunc(adminMode))
-	c.On("command:stop", stopHandler, tg.FilterFunc(adminMode))
-	c.On("command:end", stopHandler, tg.FilterFunc(adminMode))
-	c.On("command:mute", muteHandler, tg.FilterFunc(adminMode))
-	c.On("command:unmute", unmuteHandler, tg.FilterFunc(adminMode))
-	c.On("command:pause", pauseHandler, tg.FilterFunc(adminMode))
-	c.On("command:resume", resumeHandler, tg.FilterFunc(adminMode))
-	c.On("command:queue", queueHandler, tg.FilterFunc(adminMode))
-	c.On("command:seek", seekHandler, tg.FilterFunc(adminMode))
-	c.On("command:speed", speedHandler, tg.FilterFunc(adminMode))
-	c.On("command:authList", authListHandler, tg.FilterFunc(adminMode))
-	c.On("command:addAuth", addAuthHandler, tg.FilterFunc(adminMode))
-	c.On("command:auth", addAuthHandler, tg.FilterFunc(adminMode))
-	c.On("command:removeAuth", removeAuthHandler, tg.FilterFunc(adminMode))
-	c.On("command:unAuth", removeAuthHandler, tg.FilterFunc(adminMode))
-	c.On("command:rmAuth", removeAuthHandler, tg.FilterFunc(adminMode))
-
-	c.On("command:active_vc", activeVcHandler, tg.FilterFunc(isDev))
-	c.On("command:av", activeVcHandler, tg.FilterFunc(isDev))
-	c.On("command:stats", sysStatsHandler, tg.FilterFunc(isDev))
-	c.On("command:clear_assistants", clearAssistantsHandler, tg.FilterFunc(isDev))
-	c.On("command:clearAss", clearAssistantsHandler, tg.FilterFunc(isDev))
-	c.On("command:leaveAll", leaveAllHandler, tg.FilterFunc(isDev))
-	c.On("command:broadcast", broadcastHandler, tg.FilterFunc(isDev))
-	c.On("command:gCast", broadcastHandler, tg.FilterFunc(isDev))
-	c.On("command:cancelBroadcast", cancelBroadcastHandler, tg.FilterFunc(isDev))
-
-	c.On("command:settings", settingsHandler, tg.FilterFunc(adminMode))
-
-	c.On("command:cplist", createPlaylistHandler)
-	c.On("command:createplaylist", createPlaylistHandler)
-	c.On("command:dlplist", deletePlaylistHandler)
-	c.On("command:deleteplaylist", deletePlaylistHandler)
-	c.On("command:addtoplist", addToPlaylistHandler)
-	c.On("command:addtoplaylist", addToPlaylistHandler)
-	c.On("command:rmplist", removeFromPlaylistHandler)
-	c.On("command:removefromplaylist", removeFromPlaylistHandler)
-	c.On("command:plistinfo", playlistInfoHandler)
-	c.On("command:playlistinfo", playlistInfoHandler)
-	c.On("command:myplist", myPlaylistsHandler)
-	c.On("command:myplaylists", myPlaylistsHandler)
-
-	c.On("callback:play_\\w+", playCallbackHandler, tg.FilterFuncCallback(adminModeCB))
-	c.On("callback:vcplay_\\w+", vcPlayHandler)
-	c.On("callback:help_\\w+", helpCallbackHandler)
-	c.On("callback:settings_\\w+", settingsCallbackHandler)
-	c.On("callback:setlang_\\w+", setLangCallbackHandler)
-
-	c.AddParticipantHandler(handleParticipant)
-	c.AddActionHandler(handleVoiceChatMessage)
+	reporter.client = c
+	startDigest(c)
+	startScheduler(c)
+	startWeeklyStats(c)
+	startReleaseRadar(c)
+	startBroadcastScheduler(c)
+	notifyUnfinishedBroadcast(c)
+	startWatchdog(c)
+	reaper.Start()
+	startDispatcher()
+
+	registerCommands(c, aliases)
+
+	c.On("callback:^cb:", guardCallback("callback:cb", routeCallback))
+	c.On("callback:play_\\w+", guardCallback("callback:play", playCallbackHandler), tg.FilterFuncCallback(adminModeCB))
+	c.On("callback:vcplay_\\w+", guardCallback("callback:vcplay", vcPlayHandler))
+	c.On("callback:help_\\w+", guardCallback("callback:help", helpCallbackHandler))
+	c.On("callback:settings_\\w+", guardCallback("callback:settings", settingsCallbackHandler), tg.FilterFuncCallback(permitCB(requireAdmin)))
+	c.On("callback:setlang_\\w+", guardCallback("callback:setlang", setLangCallbackHandler))
+	c.On("callback:announce_\\w+", guardCallback("callback:announce", announcementCallbackHandler))
+	c.On("callback:activevc_\\w+", guardCallback("callback:activevc", activeVcCallbackHandler), tg.FilterFuncCallback(permitCB(requireOwner)))
+
+	c.AddRawHandler(&tg.UpdateBotPrecheckoutQuery{}, premiumPrecheckout)
+	c.AddParticipantHandler(guardParticipant("participant", handleParticipant))
+	// Participant updates, payments and voice chat service messages all run at command priority, so that a
+	// burst of updates cannot drop one and leave the player's state behind.
+	c.AddActionHandler(guard("payment", premiumPaymentHandler))
+	c.AddActionHandler(guardService("action", handleVoiceChatMessage))
 	logger.Debug("Handlers loaded successfully.")
+	return nil
 }
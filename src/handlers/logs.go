@@ -0,0 +1,67 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	defaultLogLines = 200
+	maxLogLines     = 1000
+)
+
+// logsHandler handles the /logs command.
+// It sends the most recent log lines as a file, read from the log file when one is configured and from memory otherwise.
+func logsHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	n := defaultLogLines
+	if arg := strings.TrimSpace(m.Args()); arg != "" {
+		if v, err := strconv.Atoi(arg); err == nil && v > 0 {
+			n = min(v, maxLogLines)
+		}
+	}
+
+	lines, err := logging.Tail(n)
+	if err != nil {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "logs_error"), html.EscapeString(err.Error())))
+		return err
+	}
+	if len(lines) == 0 {
+		_, err = m.Reply(lang.GetString(langCode, "logs_empty"))
+		return err
+	}
+
+	source := lang.GetString(langCode, "logs_source_memory")
+	if path := config.Get().LogFile; path != "" {
+		source = fmt.Sprintf(lang.GetString(langCode, "logs_source_file"), html.EscapeString(path))
+	}
+
+	_, err = replyFile(m, langCode, nil, []byte(strings.Join(lines, "\n")+"\n"), &telegram.MediaOptions{
+		FileName:      fmt.Sprintf("logs_%d.txt", time.Now().Unix()),
+		MimeType:      "text/plain",
+		ForceDocument: true,
+		Caption:       fmt.Sprintf(lang.GetString(langCode, "logs_caption"), len(lines), source),
+	})
+	return err
+}
@@ -55,6 +55,6 @@ func loopHandler(m *telegram.NewMessage) error {
 		action = fmt.Sprintf(lang.GetString(langCode, "loop_set"), argsInt)
 	}
 
-	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "loop_status_changed"), action, m.Sender.FirstName))
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "loop_status_changed"), action, senderName(m, langCode)))
 	return err
 }
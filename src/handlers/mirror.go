@@ -0,0 +1,150 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// rejectMirrored tells the sender of m that chatID follows another chat's playback, and reports whether it
+// did. Playback in a mirror is controlled from its source.
+func rejectMirrored(m *telegram.NewMessage, chatID int64, langCode string) bool {
+	source := vc.Calls.MirrorSource(chatID)
+	if source == 0 {
+		return false
+	}
+	_, _ = replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "mirror_target_locked"),
+		html.EscapeString(getChatTitle(m.Client, source))), true)
+	return true
+}
+
+// controlMode is adminMode for the commands that steer playback, which a mirror takes from its source.
+func controlMode(m *telegram.NewMessage) bool {
+	if m.IsPrivate() {
+		return false
+	}
+	ctx, cancel := db.Ctx()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+	cancel()
+	if rejectMirrored(m, m.ChannelID(), langCode) {
+		return false
+	}
+	return adminMode(m)
+}
+
+// linkHandler handles the /link command, which makes one chat play whatever another chat plays:
+// "/link <source chat ID> <target chat ID>".
+func linkHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	fields := strings.Fields(m.Args())
+	if len(fields) != 2 {
+		_, err := m.Reply(lang.GetString(langCode, "link_usage"))
+		return err
+	}
+	source, err1 := strconv.ParseInt(fields[0], 10, 64)
+	target, err2 := strconv.ParseInt(fields[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		_, err := m.Reply(lang.GetString(langCode, "link_usage"))
+		return err
+	}
+	if reason := botDenial(m.Client, target); reason != "" {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "link_target_unusable"), target, lang.GetString(langCode, reason)))
+		return err
+	}
+
+	if err := vc.Calls.Link(source, target, m.SenderID()); err != nil {
+		key := "link_error"
+		switch {
+		case errors.Is(err, vc.ErrMirrorSelf):
+			key = "link_self"
+		case errors.Is(err, vc.ErrMirrorChain):
+			key = "link_chain"
+		case errors.Is(err, vc.ErrMirrorTargetBusy):
+			key = "link_target_busy"
+		}
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, key), html.EscapeString(err.Error())))
+		return nil
+	}
+
+	audit(m, "link", strconv.FormatInt(target, 10), strconv.FormatInt(source, 10))
+	_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "link_done"),
+		html.EscapeString(getChatTitle(m.Client, target)), target, html.EscapeString(getChatTitle(m.Client, source)), source))
+	return err
+}
+
+// unlinkHandler handles the /unlink command, which stops a chat from mirroring another. It takes the
+// target chat's ID, or none when used in the target chat.
+func unlinkHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	target := m.ChannelID()
+	if args := strings.TrimSpace(m.Args()); args != "" {
+		id, err := strconv.ParseInt(args, 10, 64)
+		if err != nil {
+			_, err = m.Reply(lang.GetString(langCode, "unlink_usage"))
+			return err
+		}
+		target = id
+	}
+
+	if err := vc.Calls.Unlink(target); err != nil {
+		key := "link_error"
+		if errors.Is(err, vc.ErrNotMirrored) {
+			key = "unlink_not_linked"
+		}
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, key), html.EscapeString(err.Error())))
+		return nil
+	}
+
+	audit(m, "unlink", strconv.FormatInt(target, 10), "")
+	_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "unlink_done"), html.EscapeString(getChatTitle(m.Client, target)), target))
+	return err
+}
+
+// linksHandler handles the /links command, which lists the active mirror links.
+func linksHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	links := vc.Calls.MirrorLinks()
+	if len(links) == 0 {
+		_, err := m.Reply(lang.GetString(langCode, "links_empty"))
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "links_header"), len(links)))
+	for _, l := range links {
+		status := lang.GetString(langCode, "links_ok")
+		if l.Degraded != "" {
+			status = fmt.Sprintf(lang.GetString(langCode, "links_degraded"), html.EscapeString(truncate(l.Degraded, 80)))
+		}
+		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "links_entry"),
+			html.EscapeString(getChatTitle(m.Client, l.Source)), l.Source,
+			html.EscapeString(getChatTitle(m.Client, l.Target)), l.Target, status))
+	}
+	_, err := m.Reply(sb.String())
+	return err
+}
@@ -0,0 +1,190 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// newcomerWindow is the least time between two newcomer notifications; newcomers arriving in between are
+// reported together.
+const newcomerWindow = time.Minute
+
+// maxNewcomerLines caps how many newcomers one notification lists.
+const maxNewcomerLines = 15
+
+// newcomer is a user who started the bot, or a chat that added it, for the first time.
+type newcomer struct {
+	group    bool
+	id       int64
+	name     string // name is the user's full name or the chat's title.
+	username string
+	members  int
+	addedBy  *tg.UserObj
+}
+
+var newcomers struct {
+	sync.Mutex
+	pending  []newcomer
+	timer    *time.Timer
+	lastSent time.Time
+}
+
+// noteNewcomer queues a notification about n for the logger group. The first newcomer after a quiet spell is
+// reported at once; the ones that follow within newcomerWindow are summarised in one message.
+func noteNewcomer(client *tg.Client, n newcomer) {
+	if config.Get().LoggerId == 0 {
+		return
+	}
+	newcomers.Lock()
+	defer newcomers.Unlock()
+	newcomers.pending = append(newcomers.pending, n)
+	if newcomers.timer != nil {
+		return
+	}
+	wait := max(newcomerWindow-time.Since(newcomers.lastSent), 0)
+	newcomers.timer = time.AfterFunc(wait, func() { flushNewcomers(client) })
+}
+
+// flushNewcomers sends the queued newcomers to the logger group.
+func flushNewcomers(client *tg.Client) {
+	newcomers.Lock()
+	pending := newcomers.pending
+	newcomers.pending = nil
+	newcomers.timer = nil
+	newcomers.lastSent = time.Now()
+	newcomers.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+	chatID := config.Get().LoggerId
+	if chatID == 0 {
+		return
+	}
+	if _, err := client.SendMessage(chatID, newcomerText(pending), &tg.SendOptions{LinkPreview: false}); err != nil {
+		logger.Warn("[newcomers] Failed to report %d newcomers: %v", len(pending), err)
+	}
+}
+
+// newcomerText renders a notification about pending, with the running totals.
+func newcomerText(pending []newcomer) string {
+	var users, groups int
+	for _, n := range pending {
+		if n.group {
+			groups++
+		} else {
+			users++
+		}
+	}
+
+	var b strings.Builder
+	switch {
+	case len(pending) > 1:
+		b.WriteString(fmt.Sprintf("<b>🆕 %d new chats and %d new users</b>\n\n", groups, users))
+	case groups == 1:
+		b.WriteString("<b>🆕 Added to a new chat</b>\n\n")
+	default:
+		b.WriteString("<b>🆕 New user</b>\n\n")
+	}
+	for i, n := range pending {
+		if i == maxNewcomerLines {
+			b.WriteString(fmt.Sprintf("… and %d more\n", len(pending)-i))
+			break
+		}
+		b.WriteString(newcomerLine(n) + "\n")
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	chats, chatErr := db.Instance.CountChats(ctx)
+	usersTotal, userErr := db.Instance.CountUsers(ctx)
+	if chatErr == nil && userErr == nil {
+		b.WriteString(fmt.Sprintf("\n<b>Total:</b> %d chats, %d users", chats, usersTotal))
+	}
+	return b.String()
+}
+
+// newcomerLine renders one newcomer.
+func newcomerLine(n newcomer) string {
+	name := html.EscapeString(n.name)
+	if name == "" {
+		name = "—"
+	}
+	handle := ""
+	if n.username != "" {
+		handle = " @" + html.EscapeString(n.username)
+	}
+	if !n.group {
+		return fmt.Sprintf("‣ 👤 <a href=\"tg://user?id=%d\">%s</a>%s (<code>%d</code>)", n.id, name, handle, n.id)
+	}
+
+	line := fmt.Sprintf("‣ 👥 <b>%s</b>%s (<code>%d</code>)", name, handle, n.id)
+	if n.members > 0 {
+		line += fmt.Sprintf(" — %d members", n.members)
+	}
+	if n.addedBy != nil {
+		line += fmt.Sprintf(", added by <a href=\"tg://user?id=%d\">%s</a> (<code>%d</code>)",
+			n.addedBy.ID, html.EscapeString(strings.TrimSpace(n.addedBy.FirstName+" "+n.addedBy.LastName)), n.addedBy.ID)
+	}
+	return line
+}
+
+// userNewcomer describes a user who started the bot.
+func userNewcomer(user *tg.UserObj) newcomer {
+	return newcomer{
+		id:       user.ID,
+		name:     strings.TrimSpace(user.FirstName + " " + user.LastName),
+		username: user.Username,
+	}
+}
+
+// chatNewcomer describes a chat that added the bot. addedBy may be nil if it is unknown.
+func chatNewcomer(client *tg.Client, chatID int64, addedBy *tg.UserObj) newcomer {
+	n := newcomer{group: true, id: chatID, name: getChatTitle(client, chatID), addedBy: addedBy}
+	peer, err := client.ResolvePeer(chatID)
+	if err != nil {
+		return n
+	}
+	switch p := peer.(type) {
+	case *tg.InputPeerChannel:
+		full, err := client.ChannelsGetFullChannel(&tg.InputChannelObj{ChannelID: p.ChannelID, AccessHash: p.AccessHash})
+		if err != nil {
+			return n
+		}
+		if info, ok := full.FullChat.(*tg.ChannelFull); ok {
+			n.members = int(info.ParticipantsCount)
+		}
+		for _, chat := range full.Chats {
+			if channel, ok := chat.(*tg.Channel); ok && channel.ID == p.ChannelID {
+				n.username = channel.Username
+			}
+		}
+	case *tg.InputPeerChat:
+		full, err := client.MessagesGetFullChat(p.ChatID)
+		if err != nil {
+			return n
+		}
+		if info, ok := full.FullChat.(*tg.ChatFullObj); ok {
+			if members, ok := info.Participants.(*tg.ChatParticipantsObj); ok {
+				n.members = len(members.Participants)
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,110 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// nowPlayingSample is the track the preview of a new now-playing template is rendered with.
+var nowPlayingSample = &cache.CachedTrack{
+	Name:     "Never Gonna Give You Up <Official Video>",
+	Duration: 213,
+	User:     "Rick",
+	Platform: "youtube",
+}
+
+// nowPlayingPlaceholderList returns the placeholders a template may use, for the usage text.
+func nowPlayingPlaceholderList() string {
+	names := make([]string, len(vc.NowPlayingPlaceholders))
+	for i, name := range vc.NowPlayingPlaceholders {
+		names[i] = "<code>{" + name + "}</code>"
+	}
+	return strings.Join(names, ", ")
+}
+
+// setNowPlayingTemplateHandler handles the /setnptemplate command, which replaces the chat's now-playing
+// text with a template of its own. The template is previewed before it is saved, so one Telegram cannot
+// parse is never stored.
+func setNowPlayingTemplateHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	tmpl := strings.TrimSpace(m.Args())
+	if tmpl == "" {
+		current := lang.GetString(langCode, "nptemplate_default")
+		if saved := db.Instance.GetNowPlayingTemplate(ctx, chatID); saved != "" {
+			current = "<code>" + html.EscapeString(saved) + "</code>"
+		}
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "nptemplate_usage"), current,
+			nowPlayingPlaceholderList(), vc.NowPlayingTemplateMax))
+		return err
+	}
+
+	if err := vc.ValidateNowPlayingTemplate(tmpl); err != nil {
+		var unknown *vc.UnknownPlaceholder
+		switch {
+		case errors.As(err, &unknown):
+			_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "nptemplate_unknown"),
+				html.EscapeString(unknown.Name), nowPlayingPlaceholderList()))
+		case errors.Is(err, vc.ErrTemplateTooLong):
+			_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "nptemplate_too_long"), vc.NowPlayingTemplateMax))
+		default:
+			_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "nptemplate_error"), html.EscapeString(err.Error())))
+		}
+		return err
+	}
+
+	preview := vc.RenderNowPlayingTemplate(tmpl, nowPlayingSample, 3)
+	if _, err := m.Reply(preview, &tg.SendOptions{ReplyMarkup: core.ControlButtons("play")}); err != nil {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "nptemplate_bad_html"), html.EscapeString(err.Error())))
+		return err
+	}
+	if err := db.Instance.SetNowPlayingTemplate(ctx, chatID, tmpl); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "nptemplate_error"), html.EscapeString(err.Error())))
+		return err
+	}
+
+	audit(m, "nptemplate", "", truncate(tmpl, 100))
+	_, err := replyTransient(m, lang.GetString(langCode, "nptemplate_set"), true)
+	return err
+}
+
+// delNowPlayingTemplateHandler handles the /delnptemplate command, which restores the default now-playing text.
+func delNowPlayingTemplateHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	if db.Instance.GetNowPlayingTemplate(ctx, chatID) == "" {
+		_, err := replyTransient(m, lang.GetString(langCode, "nptemplate_none"), true)
+		return err
+	}
+	if err := db.Instance.SetNowPlayingTemplate(ctx, chatID, ""); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "nptemplate_error"), html.EscapeString(err.Error())))
+		return err
+	}
+	audit(m, "nptemplate", "", "default")
+	_, err := replyTransient(m, lang.GetString(langCode, "nptemplate_cleared"), true)
+	return err
+}
@@ -0,0 +1,264 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/web"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// npPagePath is where the public now-playing pages are served on the public server.
+	npPagePath = "/np/"
+	// npRateLimit is how many page requests one IP address may make per npRateWindow.
+	npRateLimit = 30
+	// npRateWindow is the window npRateLimit applies to.
+	npRateWindow = time.Minute
+	// npMaxUpcoming is how many upcoming tracks a page lists.
+	npMaxUpcoming = 10
+)
+
+// npHits counts the page requests of every IP address in its current window.
+var npHits = struct {
+	sync.Mutex
+	windows *cache.Cache[npWindow]
+}{windows: cache.NewCache[npWindow](npRateWindow)}
+
+// npWindow is an IP address's requests since start.
+type npWindow struct {
+	start time.Time
+	n     int
+}
+
+// npTrack is a track as shown on a public now-playing page. Requester is only set when the chat allows it.
+type npTrack struct {
+	Name      string `json:"name"`
+	URL       string `json:"url,omitempty"`
+	Platform  string `json:"platform"`
+	Duration  int    `json:"duration"`
+	Thumbnail string `json:"thumbnail,omitempty"`
+	Requester string `json:"requested_by,omitempty"`
+}
+
+// npPage is the public now-playing state of a chat.
+type npPage struct {
+	Playing  bool      `json:"playing"`
+	Track    *npTrack  `json:"track,omitempty"`
+	Elapsed  int       `json:"elapsed"`
+	Upcoming []npTrack `json:"upcoming"`
+	Queued   int       `json:"queued"`
+	Updated  int64     `json:"updated"`
+}
+
+var npTemplate = template.Must(template.New("np").Funcs(template.FuncMap{"clock": cache.SecToMin}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta http-equiv="refresh" content="15">
+<meta name="viewport" content="width=device-width, initial-scale=1"><title>Now playing</title>
+<style>body{font-family:sans-serif;max-width:32em;margin:1em auto;padding:0 1em}small{color:#666}</style></head><body>
+{{if .Track}}<h2>{{if .Playing}}▶{{else}}⏸{{end}} {{.Track.Name}}</h2>
+<p>{{clock .Elapsed}} / {{clock .Track.Duration}}{{if .Track.Requester}} <small>requested by {{.Track.Requester}}</small>{{end}}</p>
+{{if .Upcoming}}<h3>Up next</h3><ol>{{range .Upcoming}}<li>{{.Name}} <small>{{clock .Duration}}{{if .Requester}} · {{.Requester}}{{end}}</small></li>{{end}}</ol>
+{{if gt .Queued (len .Upcoming)}}<p><small>and {{.Queued}} tracks in total</small></p>{{end}}{{end}}
+{{else}}<h2>Nothing is playing</h2>{{end}}
+</body></html>`))
+
+func init() {
+	web.Handle(npPagePath, http.HandlerFunc(serveNowPlaying))
+}
+
+// npTokenHandler handles the /nptoken command. "/nptoken" shows the chat's public now-playing page, creating
+// it if needed, "/nptoken new" replaces its address, "/nptoken revoke" takes it down and
+// "/nptoken requesters on|off" sets whether it names who requested each track.
+func npTokenHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	args := strings.Fields(strings.ToLower(m.Args()))
+	action := ""
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "revoke", "off":
+		if err := db.Instance.RevokeNPToken(ctx, chatID); err != nil {
+			_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "nptoken_error"), err))
+			return err
+		}
+		audit(m, "nptoken", "", "revoke")
+		_, err := replyTransient(m, lang.GetString(langCode, "nptoken_revoked"), true)
+		return err
+
+	case "requesters":
+		if len(args) < 2 || (args[1] != "on" && args[1] != "off") {
+			_, err := m.Reply(lang.GetString(langCode, "nptoken_usage"))
+			return err
+		}
+		show := args[1] == "on"
+		if err := db.Instance.SetNPRequesters(ctx, chatID, show); err != nil {
+			_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "nptoken_error"), err))
+			return err
+		}
+		audit(m, "nptoken", "", "requesters "+args[1])
+		key := "nptoken_requesters_hidden"
+		if show {
+			key = "nptoken_requesters_shown"
+		}
+		_, err := replyTransient(m, lang.GetString(langCode, key), true)
+		return err
+
+	case "", "new":
+		token := db.Instance.GetNPToken(ctx, chatID)
+		if token == "" || action == "new" {
+			token = newNPToken()
+			if err := db.Instance.SetNPToken(ctx, chatID, token); err != nil {
+				_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "nptoken_error"), err))
+				return err
+			}
+			audit(m, "nptoken", "", "new")
+		}
+		text := fmt.Sprintf(lang.GetString(langCode, "nptoken_show"), npPagePath+token, npPagePath+token)
+		if config.Get().PublicAddr == "" {
+			text += lang.GetString(langCode, "nptoken_no_server")
+		}
+		_, err := m.Reply(text)
+		return err
+
+	default:
+		_, err := m.Reply(lang.GetString(langCode, "nptoken_usage"))
+		return err
+	}
+}
+
+// newNPToken returns a new random public now-playing token.
+func newNPToken() string {
+	b := make([]byte, 18)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// npAllow reports whether the IP address of r may make another page request in its current window.
+func npAllow(r *http.Request) bool {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	npHits.Lock()
+	defer npHits.Unlock()
+	now := time.Now()
+	w, ok := npHits.windows.Get(ip)
+	if !ok || now.Sub(w.start) >= npRateWindow {
+		w = npWindow{start: now}
+	}
+	w.n++
+	npHits.windows.Set(ip, w)
+	return w.n <= npRateLimit
+}
+
+// serveNowPlaying serves the public now-playing page of the chat whose token is in the path, as JSON or, for
+// browsers and ?format=html, as a small HTML page. Unknown and revoked tokens get a 404.
+func serveNowPlaying(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !npAllow(r) {
+		w.Header().Set("Retry-After", fmt.Sprint(int(npRateWindow.Seconds())))
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return
+	}
+
+	token := strings.TrimPrefix(r.URL.Path, npPagePath)
+	if token == "" || strings.Contains(token, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	chatID, err := db.Instance.ChatByNPToken(ctx, token)
+	if err != nil {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if chatID == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	page := buildNPPage(chatID, db.Instance.GetNPRequesters(ctx, chatID))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.URL.Query().Get("format") == "html" || strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = npTemplate.Execute(w, page)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+// buildNPPage collects what a chat is playing and what comes next. Requesters are named only if showRequesters
+// is set, and the paths of downloaded files are never exposed.
+func buildNPPage(chatID int64, showRequesters bool) *npPage {
+	page := &npPage{Upcoming: []npTrack{}, Updated: time.Now().Unix()}
+	if !cache.ChatCache.IsActive(chatID) {
+		return page
+	}
+	queue := cache.ChatCache.GetQueue(chatID)
+	if len(queue) == 0 {
+		return page
+	}
+
+	toPage := func(t *cache.CachedTrack) npTrack {
+		track := npTrack{Name: t.Name, Platform: t.Platform, Duration: t.Duration}
+		if strings.HasPrefix(t.URL, "http") {
+			track.URL = t.URL
+		}
+		if strings.HasPrefix(t.Thumbnail, "http") {
+			track.Thumbnail = t.Thumbnail
+		}
+		if showRequesters {
+			track.Requester = t.User
+		}
+		return track
+	}
+
+	current := toPage(queue[0])
+	page.Track = &current
+	if elapsed, err := vc.Calls.Elapsed(chatID); err == nil {
+		page.Elapsed = elapsed
+		state, _ := vc.Calls.State(chatID)
+		page.Playing = state == vc.StatePlaying
+	}
+	page.Queued = len(queue) - 1
+	for _, t := range queue[1:min(len(queue), npMaxUpcoming+1)] {
+		page.Upcoming = append(page.Upcoming, toPage(t))
+	}
+	return page
+}
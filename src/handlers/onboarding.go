@@ -0,0 +1,132 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// botRight is an admin right the bot asks for in a group.
+type botRight struct {
+	key      string // key is the lang key of the right's name.
+	required bool   // required rights are needed to play; the others only enable extras.
+	has      func(r *tg.ChatAdminRights) bool
+}
+
+// botRights are the rights the permission checklist shows, in order.
+var botRights = []botRight{
+	{key: "perm_invite_users", required: true, has: func(r *tg.ChatAdminRights) bool { return r.InviteUsers }},
+	{key: "perm_manage_call", required: true, has: func(r *tg.ChatAdminRights) bool { return r.ManageCall }},
+	{key: "perm_delete_messages", has: func(r *tg.ChatAdminRights) bool { return r.DeleteMessages }},
+}
+
+// onboarded remembers the chats the onboarding message was just posted in, so a bot that is removed and added
+// back right away does not greet the chat twice.
+var onboarded = cache.NewCache[bool](10 * time.Minute)
+
+// permissionChecklist renders the bot's rights in chatID with ✅ or ❌ each. It reports whether every
+// required right is granted. refresh reloads the admin list instead of using the cached one.
+func permissionChecklist(client *tg.Client, chatID int64, langCode string, refresh bool) (string, bool) {
+	var rights *tg.ChatAdminRights
+	isAdmin, isCreator := false, false
+	if me, err := cache.GetUserAdmin(client, chatID, client.Me().ID, refresh); err == nil && me != nil {
+		isCreator = me.Status == tg.Creator
+		isAdmin = isCreator || me.Status == tg.Admin
+		rights = me.Rights
+	}
+
+	mark := func(ok bool) string {
+		if ok {
+			return "✅"
+		}
+		return "❌"
+	}
+
+	var b strings.Builder
+	b.WriteString(lang.GetString(langCode, "perm_checklist_header"))
+	b.WriteString(fmt.Sprintf("%s %s\n", mark(isAdmin), lang.GetString(langCode, "perm_admin")))
+	ready := isAdmin
+	for _, right := range botRights {
+		granted := isCreator || (isAdmin && rights != nil && right.has(rights))
+		name := lang.GetString(langCode, right.key)
+		if !right.required {
+			name += lang.GetString(langCode, "perm_optional")
+		} else if !granted {
+			ready = false
+		}
+		b.WriteString(fmt.Sprintf("%s %s\n", mark(granted), name))
+	}
+	if ready {
+		b.WriteString(lang.GetString(langCode, "perm_checklist_ready"))
+	} else {
+		b.WriteString(lang.GetString(langCode, "perm_checklist_fix"))
+	}
+	return b.String(), ready
+}
+
+// sendOnboarding posts the onboarding message in chatID: the permission checklist, a settings button and
+// examples to get started.
+func sendOnboarding(client *tg.Client, chatID int64) {
+	key := strconv.FormatInt(chatID, 10)
+	if _, done := onboarded.Get(key); done {
+		return
+	}
+	onboarded.Set(key, true)
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	checklist, _ := permissionChecklist(client, chatID, langCode, true)
+	text := fmt.Sprintf(lang.GetString(langCode, "onboarding_text"), checklist)
+	kb := tg.NewKeyboard().AddRow(
+		tg.Button.Data(lang.GetString(langCode, "onboarding_settings_button"), "settings_open"),
+	).Build()
+	if _, err := client.SendMessage(chatID, text, &tg.SendOptions{ReplyMarkup: kb}); err != nil {
+		logger.Warn("[onboarding] Failed to greet chat %d: %v", chatID, err)
+	}
+}
+
+// botAdded reports whether the service message m says the bot was added to the chat.
+func botAdded(m *tg.NewMessage) bool {
+	me := m.Client.Me().ID
+	switch action := m.Action.(type) {
+	case *tg.MessageActionChatAddUser:
+		return slices.Contains(action.Users, me)
+	case *tg.MessageActionChatCreate:
+		return slices.Contains(action.Users, me)
+	}
+	return false
+}
+
+// sendPermissionChecklist replies to m with the bot's permission checklist. It is used when a command fails
+// because the bot lacks a right.
+func sendPermissionChecklist(m *tg.NewMessage, langCode string) {
+	checklist, _ := permissionChecklist(m.Client, m.ChannelID(), langCode, true)
+	_, _ = replyTransient(m, checklist, true)
+}
+
+// checkPermsHandler handles the /checkperms command, which shows the bot's permission checklist.
+func checkPermsHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+	checklist, _ := permissionChecklist(m.Client, m.ChannelID(), langCode, true)
+	_, err := m.Reply(checklist)
+	return err
+}
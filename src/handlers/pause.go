@@ -10,6 +10,7 @@ package handlers
 
 import (
 	"fmt"
+	"strings"
 
 	"ashokshau/tgmusic/src/core"
 	"ashokshau/tgmusic/src/core/cache"
@@ -32,11 +33,15 @@ func pauseHandler(m *telegram.NewMessage) error {
 	}
 
 	if _, err := vc.Calls.Pause(chatID); err != nil {
+		if text, ok := playbackStateText(langCode, err); ok {
+			_, _ = m.Reply(text)
+			return nil
+		}
 		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "pause_error"), err.Error()))
 		return nil
 	}
 
-	_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "pause_success"), m.Sender.FirstName), &telegram.SendOptions{ReplyMarkup: core.ControlButtons("pause")})
+	_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "pause_success"), senderName(m, langCode)), &telegram.SendOptions{ReplyMarkup: core.ControlButtons("pause")})
 	return err
 }
 
@@ -51,16 +56,40 @@ func resumeHandler(m *telegram.NewMessage) error {
 		return nil
 	}
 
+	if n := vc.Calls.EndedQueueLength(chatID); n > 0 && !cache.ChatCache.IsActive(chatID) {
+		return resumeEndedQueue(m, chatID, n, langCode)
+	}
+
 	if !cache.ChatCache.IsActive(chatID) {
 		_, _ = m.Reply(lang.GetString(langCode, "no_track_playing"))
 		return nil
 	}
 
 	if _, err := vc.Calls.Resume(chatID); err != nil {
+		if text, ok := playbackStateText(langCode, err); ok {
+			_, _ = m.Reply(text)
+			return nil
+		}
 		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "resume_error"), err.Error()))
 		return nil
 	}
 
-	_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "resume_success"), m.Sender.FirstName), &telegram.SendOptions{ReplyMarkup: core.ControlButtons("resume")})
+	_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "resume_success"), senderName(m, langCode)), &telegram.SendOptions{ReplyMarkup: core.ControlButtons("resume")})
+	return err
+}
+
+// resumeEndedQueue continues the n tracks saved when the chat's voice chat was ended.
+func resumeEndedQueue(m *telegram.NewMessage, chatID int64, n int, langCode string) error {
+	waitPos, err := vc.Calls.ResumeEnded(chatID)
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "resume_failed"), err.Error()))
+		return nil
+	}
+	if waitPos > 0 {
+		_, err = m.Reply(strings.TrimSpace(fmt.Sprintf(lang.GetString(langCode, "waiting_room_notice"), waitPos)))
+		return err
+	}
+
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "vc_ended_resumed"), n, senderName(m, langCode)), &telegram.SendOptions{ReplyMarkup: core.ControlButtons("resume")})
 	return err
 }
@@ -0,0 +1,137 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"slices"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/perm"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// requirement is the permission a command or button asks of whoever uses it.
+type requirement = perm.Requirement
+
+const (
+	requireNone  = perm.None
+	requireAuth  = perm.Auth
+	requireAdmin = perm.Admin
+	requireSudo  = perm.Sudo
+	requireOwner = perm.Owner
+)
+
+// invoker is the resolved sender of a command.
+type invoker = perm.Invoker
+
+const (
+	senderUser           = perm.User
+	senderAnonymousAdmin = perm.AnonymousAdmin
+	senderChannel        = perm.Channel
+)
+
+// resolveInvoker works out who sent msg; m.Sender is nil for anonymous admins and channels.
+func resolveInvoker(msg *telegram.MessageObj) invoker {
+	return perm.Resolve(msg)
+}
+
+// senderName returns the name a request from m is attributed to: the user's first name, "Anonymous admin"
+// for an admin posting as the group, or the channel's title for a channel.
+func senderName(m *telegram.NewMessage, langCode string) string {
+	return resolveInvoker(m.Message).Name(m.Sender, lang.GetString(langCode, "sender_anonymous_admin"),
+		func(chatID int64) string { return getChatTitle(m.Client, chatID) })
+}
+
+// requesterID returns the user ID a request from m is attributed to, or 0 if it was not sent by a user.
+func requesterID(m *telegram.NewMessage) int64 {
+	return resolveInvoker(m.Message).Requester()
+}
+
+// callbackInvoker returns the invoker of a button press. Buttons are always pressed by a user, even an
+// anonymous admin.
+func callbackInvoker(cb *telegram.CallbackQuery) invoker {
+	return invoker{ID: cb.SenderID}
+}
+
+// isChatAdmin reports whether userID administers chatID, loading the admin list if it is not cached.
+func isChatAdmin(ctx context.Context, client *telegram.Client, chatID, userID int64) bool {
+	if client == nil {
+		return db.Instance.IsAdmin(ctx, chatID, userID)
+	}
+	admins, err := cache.GetAdmins(client, chatID, false)
+	if err != nil {
+		return db.Instance.IsAdmin(ctx, chatID, userID)
+	}
+	return slices.ContainsFunc(admins, func(p *telegram.Participant) bool {
+		return p.User != nil && p.User.ID == userID
+	})
+}
+
+// denial checks inv against req in chatID. It returns an empty string if inv may go ahead, or the lang key
+// explaining why not.
+func denial(ctx context.Context, client *telegram.Client, chatID int64, inv invoker, req requirement) string {
+	return perm.Roles{
+		Owner: config.IsOwner,
+		Sudo:  func(userID int64) bool { return slices.Contains(config.Get().DEVS, userID) },
+		Admin: func(userID int64) bool { return isChatAdmin(ctx, client, chatID, userID) },
+		Auth:  func(userID int64) bool { return slices.Contains(db.Instance.GetAuthUsers(ctx, chatID), userID) },
+	}.Denial(inv, req)
+}
+
+// messageDenial checks the sender of m against req.
+func messageDenial(ctx context.Context, m *telegram.NewMessage, req requirement) string {
+	return denial(ctx, m.Client, m.ChannelID(), resolveInvoker(m.Message), req)
+}
+
+// permit returns a filter that lets a command through only if its sender meets req, and otherwise replies
+// with the reason.
+func permit(req requirement) func(*telegram.NewMessage) bool {
+	return func(m *telegram.NewMessage) bool {
+		if req == requireNone {
+			return true
+		}
+		ctx, cancel := db.Ctx()
+		defer cancel()
+		reason := messageDenial(ctx, m, req)
+		if reason == "" {
+			return true
+		}
+		_, _ = replyTransient(m, lang.GetString(db.Instance.GetLang(ctx, m.ChannelID()), reason), true)
+		return false
+	}
+}
+
+// permitCallback returns a callback route's Allow function for req.
+func permitCallback(req requirement) func(*telegram.CallbackQuery) string {
+	return func(cb *telegram.CallbackQuery) string {
+		ctx, cancel := db.Ctx()
+		defer cancel()
+		return denial(ctx, cb.Client, cb.ChannelID(), callbackInvoker(cb), req)
+	}
+}
+
+// permitCB returns a callback filter for req that answers presses that do not meet it.
+func permitCB(req requirement) func(*telegram.CallbackQuery) bool {
+	allow := permitCallback(req)
+	return func(cb *telegram.CallbackQuery) bool {
+		reason := allow(cb)
+		if reason == "" {
+			return true
+		}
+		ctx, cancel := db.Ctx()
+		defer cancel()
+		_, _ = cb.Answer(lang.GetString(db.Instance.GetLang(ctx, cb.ChannelID()), reason), &telegram.CallbackOptions{Alert: true})
+		return false
+	}
+}
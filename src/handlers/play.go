@@ -11,17 +11,20 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"path/filepath"
+	"math/rand/v2"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/blockwords"
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/core/premium"
 	"ashokshau/tgmusic/src/lang"
 	"ashokshau/tgmusic/src/vc"
 
@@ -30,31 +33,69 @@ import (
 
 var telegramURLRegex = regexp.MustCompile(`^https://t\.me/([a-zA-Z0-9_]{4,})/(\d+)$`)
 
+// shuffleFlag makes a playlist play in random order.
+const shuffleFlag = "-shuffle"
+
 // playHandler handles the /play command.
 func playHandler(m *telegram.NewMessage) error {
-	return handlePlay(m, false)
+	return handlePlay(m, false, false)
+}
+
+// playAllHandler handles the /playall command.
+// "/playall tgpl_xxx [-shuffle]" queues a stored playlist, optionally in random order.
+func playAllHandler(m *telegram.NewMessage) error {
+	if args, _ := stripForceFlag(m.Args()); !strings.HasPrefix(strings.TrimSpace(args), "tgpl_") {
+		ctx, cancel := db.Ctx()
+		defer cancel()
+		_, err := m.Reply(lang.GetString(db.Instance.LangFor(ctx, m.ChannelID(), m.SenderID()), "playall_usage"))
+		return err
+	}
+	return handlePlay(m, false, false)
 }
 
 // vPlayHandler handles the /vplay command.
 func vPlayHandler(m *telegram.NewMessage) error {
-	return handlePlay(m, true)
+	return handlePlay(m, true, false)
 }
 
 // handlePlay is the main handler for /play and /vplay commands.
-func handlePlay(m *telegram.NewMessage, isVideo bool) error {
+// approved is set when an admin approved the request in a chat with approval mode on.
+func handlePlay(m *telegram.NewMessage, isVideo, approved bool) error {
+	return playRequest(m, m.Args(), isVideo, approved)
+}
+
+// playRequest plays what args asks for on behalf of m, which is replied to with the progress. It is
+// handlePlay for requests whose arguments are not the message's own, such as scheduled ones.
+func playRequest(m *telegram.NewMessage, args string, isVideo, approved bool) error {
 	chatID := m.ChannelID()
 	ctx, cancel := db.Ctx()
 	defer cancel()
-	langCode := db.Instance.GetLang(ctx, chatID)
+	langCode := db.Instance.LangFor(ctx, chatID, m.SenderID())
 
-	if queue := cache.ChatCache.GetQueue(chatID); len(queue) > 10 {
-		_, _ = m.Reply(lang.GetString(langCode, "play_queue_full"))
+	if isVideo && !config.Get().Features.Video {
+		_, _ = replyTransient(m, lang.GetString(langCode, "feature_disabled"), true)
+		return telegram.EndGroup
+	}
+	if cache.ChatCache.IsFull(chatID) {
+		_, _ = replyTransient(m, lang.GetString(langCode, "play_queue_full"), true)
 		return telegram.EndGroup
 	}
+	if rejectQuietHours(m, chatID, langCode) {
+		return telegram.EndGroup
+	}
+	if !reserveQuota(m, chatID, langCode) {
+		return telegram.EndGroup
+	}
+	defer settleQuota(m)
+	if !approved && needsApproval(m, chatID) {
+		return requestApproval(m, isVideo, langCode)
+	}
+	// Starting something new drops the queue saved from an ended voice chat.
+	vc.Calls.DiscardEndedQueue(chatID)
 
 	isReply := m.IsReply()
 	url := getUrl(m, isReply)
-	args := m.Args()
+	args, _ = stripForceFlag(args)
 	rMsg := m
 	var err error
 
@@ -72,7 +113,12 @@ func handlePlay(m *telegram.NewMessage, isVideo bool) error {
 
 	input := coalesce(url, args)
 	if strings.HasPrefix(input, "tgpl_") {
-		playlist, err := db.Instance.GetPlaylist(ctx, input)
+		if !config.Get().Features.Playlists {
+			_, err := replyTransient(m, lang.GetString(langCode, "feature_disabled"), true)
+			return err
+		}
+		id, shuffle := stripFlag(input, shuffleFlag)
+		playlist, err := db.Instance.GetPlaylist(ctx, id)
 		if err != nil {
 			_, err := m.Reply(lang.GetString(langCode, "playlist_not_found"))
 			return err
@@ -88,13 +134,18 @@ func handlePlay(m *telegram.NewMessage, isVideo bool) error {
 				Platform: song.Platform,
 			})
 		}
+		if shuffle {
+			rand.Shuffle(len(tracks), func(i, j int) { tracks[i], tracks[j] = tracks[j], tracks[i] })
+		}
 
 		updater, err := m.Reply(lang.GetString(langCode, "play_searching"))
 		if err != nil {
 			logger.Warn("failed to send message: %v", err)
 			return telegram.EndGroup
 		}
-		return handleMultipleTracks(m, updater, tracks, chatID, isVideo, langCode)
+		vc.Calls.RecordRequest(chatID, requesterID(m))
+		_, err = handleMultipleTracks(m, updater, tracks, chatID, isVideo, langCode)
+		return err
 	}
 
 	if username, msgID, ok := parseTelegramURL(input); ok {
@@ -120,11 +171,16 @@ func handlePlay(m *telegram.NewMessage, isVideo bool) error {
 		return telegram.EndGroup
 	}
 
+	if url == "" && args == "" && voiceSearchable(rMsg) {
+		return handleVoiceSearch(m, rMsg, chatID, isVideo, langCode)
+	}
+
 	updater, err := m.Reply(lang.GetString(langCode, "play_searching"))
 	if err != nil {
 		logger.Warn("failed to send message: %v", err)
 		return telegram.EndGroup
 	}
+	vc.Calls.RecordRequest(chatID, requesterID(m))
 
 	if isReply && isValidMedia(rMsg) {
 		return handleMedia(m, updater, rMsg, chatID, isVideo, langCode)
@@ -133,34 +189,34 @@ func handlePlay(m *telegram.NewMessage, isVideo bool) error {
 	wrapper := dl.NewDownloaderWrapper(input)
 	if url != "" {
 		if !wrapper.IsValid() {
-			_, _ = updater.Edit(lang.GetString(langCode, "play_invalid_url"), &telegram.SendOptions{ReplyMarkup: core.SupportKeyboard()})
+			_, _ = editTransient(updater, m, lang.GetString(langCode, "play_invalid_url"), &telegram.SendOptions{ReplyMarkup: core.SupportKeyboard()})
 			return telegram.EndGroup
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		ctx, cancel := context.WithTimeout(requestCtx(m), 30*time.Second)
 		defer cancel()
 		trackInfo, err := wrapper.GetInfo(ctx)
 		if err != nil {
-			_, _ = updater.Edit(fmt.Sprintf(lang.GetString(langCode, "play_fetch_error"), err.Error()))
+			_, _ = editTransient(updater, m, userError(m, langCode, fmt.Sprintf(lang.GetString(langCode, "play_fetch_error"), err.Error()), err))
 			return telegram.EndGroup
 		}
 
 		if trackInfo.Results == nil {
-			_, _ = updater.Edit(lang.GetString(langCode, "play_no_tracks_found"))
+			_, _ = editTransient(updater, m, lang.GetString(langCode, "play_no_tracks_found"))
 			return telegram.EndGroup
 		}
 		return handleUrl(m, updater, trackInfo, chatID, isVideo, langCode)
 	}
 
-	ctx2, cancel2 := context.WithTimeout(context.Background(), 15*time.Second)
+	ctx2, cancel2 := context.WithTimeout(requestCtx(m), 15*time.Second)
 	defer cancel2()
-	return handleTextSearch(m, updater, wrapper, chatID, isVideo, ctx2, langCode)
+	return handleTextSearch(m, updater, input, db.Instance.GetSearchPlatform(ctx, chatID), chatID, isVideo, ctx2, langCode)
 }
 
 // handleMedia handles playing media from a message.
 func handleMedia(m *telegram.NewMessage, updater *telegram.NewMessage, dlMsg *telegram.NewMessage, chatId int64, isVideo bool, langCode string) error {
-	if dlMsg.File.Size > config.Conf.MaxFileSize {
-		_, err := updater.Edit(fmt.Sprintf(lang.GetString(langCode, "play_file_too_large"), config.Conf.MaxFileSize/(1024*1024)))
+	if dlMsg.File.Size > config.Get().MaxFileSize {
+		_, err := editTransient(updater, m, fmt.Sprintf(lang.GetString(langCode, "play_file_too_large"), config.Get().MaxFileSize/(1024*1024)))
 		if err != nil {
 			logger.Warn("[play.go - handleMedia] Edit message failed: %v", err)
 		}
@@ -169,34 +225,34 @@ func handleMedia(m *telegram.NewMessage, updater *telegram.NewMessage, dlMsg *te
 
 	fileName := dlMsg.File.Name
 	fileId := dlMsg.File.FileID
-	if _track := cache.ChatCache.GetTrackIfExists(chatId, fileId); _track != nil {
-		_, err := updater.Edit(lang.GetString(langCode, "play_track_already_in_queue"))
-		return err
+	if rejectDuplicate(m, updater, chatId, cache.Telegram, fileId, langCode) {
+		return nil
+	}
+	if rejectBlocked(m, updater, chatId, fileName, langCode) {
+		return nil
 	}
 
 	dur := cache.GetFileDur(dlMsg)
 	if cache.ChatCache.IsActive(chatId) {
 		saveCache := cache.CachedTrack{
-			URL: dlMsg.Link(), Name: fileName, User: m.Sender.FirstName, TrackID: fileId,
-			Duration: dur, IsVideo: isVideo, Platform: cache.Telegram,
+			URL: dlMsg.Link(), Name: fileName, User: senderName(m, langCode), UserID: requesterID(m), TrackID: fileId,
+			Duration: dur, IsVideo: isVideo, Platform: cache.Telegram, RequestID: requestID(m),
 		}
-		queue := cache.ChatCache.GetQueue(chatId)
-		cache.ChatCache.AddSong(chatId, &saveCache)
-
-		queueInfo := fmt.Sprintf(
-			lang.GetString(langCode, "play_added_to_queue"),
-			len(queue), saveCache.URL, saveCache.Name, cache.SecToMin(saveCache.Duration), saveCache.User,
-		)
+		position, err := cache.ChatCache.Enqueue(chatId, &saveCache)
+		if err != nil {
+			_, err = editTransient(updater, m, lang.GetString(langCode, "play_queue_full"))
+			return err
+		}
+		keepQuota(m)
 
-		_, err := updater.Edit(queueInfo, &telegram.SendOptions{ReplyMarkup: core.ControlButtons("play")})
-		return err
+		return announceEnqueued(m, updater, chatId, &saveCache, position, langCode)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	ctx, cancel := context.WithTimeout(requestCtx(m), 2*time.Minute)
 	defer cancel()
-	filePath, err := dlMsg.Download(&telegram.DownloadOptions{FileName: filepath.Join(config.Conf.DownloadsDir, fileName), Ctx: ctx})
+	filePath, err := dlMsg.Download(&telegram.DownloadOptions{FileName: dl.DownloadPath(fileName), Ctx: ctx})
 	if err != nil {
-		_, err = updater.Edit(fmt.Sprintf(lang.GetString(langCode, "play_download_failed"), err.Error()))
+		_, err = editTransient(updater, m, userError(m, langCode, fmt.Sprintf(lang.GetString(langCode, "play_download_failed"), err.Error()), err))
 		return err
 	}
 
@@ -213,22 +269,22 @@ func handleMedia(m *telegram.NewMessage, updater *telegram.NewMessage, dlMsg *te
 }
 
 // handleTextSearch handles a text search for a song.
-func handleTextSearch(m *telegram.NewMessage, updater *telegram.NewMessage, wrapper *dl.DownloaderWrapper, chatId int64, isVideo bool, ctx context.Context, langCode string) error {
-	searchResult, err := wrapper.Search(ctx)
+// The query is resolved against the chat's preferred search platform.
+func handleTextSearch(m *telegram.NewMessage, updater *telegram.NewMessage, query, platform string, chatId int64, isVideo bool, ctx context.Context, langCode string) error {
+	searchResult, err := dl.Resolve(ctx, query, platform)
 	if err != nil {
-		_, err = updater.Edit(fmt.Sprintf(lang.GetString(langCode, "play_search_failed"), err.Error()))
+		_, err = editTransient(updater, m, userError(m, langCode, fmt.Sprintf(lang.GetString(langCode, "play_search_failed"), err.Error()), err))
 		return err
 	}
 
 	if searchResult.Results == nil || len(searchResult.Results) == 0 {
-		_, err = updater.Edit(lang.GetString(langCode, "play_no_results"))
+		_, err = editTransient(updater, m, lang.GetString(langCode, "play_no_results"))
 		return err
 	}
 
 	song := searchResult.Results[0]
-	if _track := cache.ChatCache.GetTrackIfExists(chatId, song.ID); _track != nil {
-		_, err := updater.Edit(lang.GetString(langCode, "play_track_already_in_queue"))
-		return err
+	if rejectDuplicate(m, updater, chatId, song.Platform, song.ID, langCode) {
+		return nil
 	}
 
 	return handleSingleTrack(m, updater, song, "", chatId, isVideo, langCode)
@@ -238,39 +294,58 @@ func handleTextSearch(m *telegram.NewMessage, updater *telegram.NewMessage, wrap
 func handleUrl(m *telegram.NewMessage, updater *telegram.NewMessage, trackInfo cache.PlatformTracks, chatId int64, isVideo bool, langCode string) error {
 	if len(trackInfo.Results) == 1 {
 		track := trackInfo.Results[0]
-		if _track := cache.ChatCache.GetTrackIfExists(chatId, track.ID); _track != nil {
-			_, err := updater.Edit(lang.GetString(langCode, "play_track_already_in_queue"))
-			return err
+		if rejectDuplicate(m, updater, chatId, track.Platform, track.ID, langCode) {
+			return nil
 		}
 		return handleSingleTrack(m, updater, track, "", chatId, isVideo, langCode)
 	}
-	return handleMultipleTracks(m, updater, trackInfo.Results, chatId, isVideo, langCode)
+	_, err := handleMultipleTracks(m, updater, trackInfo.Results, chatId, isVideo, langCode)
+	return err
 }
 
 // handleSingleTrack handles a single track.
 func handleSingleTrack(m *telegram.NewMessage, updater *telegram.NewMessage, song cache.MusicTrack, filePath string, chatId int64, isVideo bool, langCode string) error {
-	if song.Duration > int(config.Conf.SongDurationLimit) {
-		_, err := updater.Edit(fmt.Sprintf(lang.GetString(langCode, "play_song_too_long"), config.Conf.SongDurationLimit/60))
+	if limits := premium.For(requesterID(m)); song.Duration > limits.SongDuration {
+		_, err := editTransient(updater, m, songTooLong(langCode, limits))
 		return err
 	}
+	if rejectBlocked(m, updater, chatId, song.Name, langCode) {
+		return nil
+	}
 	saveCache := cache.CachedTrack{
-		URL: song.URL, Name: song.Name, User: m.Sender.FirstName, FilePath: filePath,
+		URL: song.URL, Name: song.Name, User: senderName(m, langCode), UserID: requesterID(m), FilePath: filePath,
 		Thumbnail: song.Cover, TrackID: song.ID, Duration: song.Duration,
-		IsVideo: isVideo, Platform: song.Platform,
+		IsVideo: isVideo, Platform: song.Platform, RequestID: requestID(m),
 	}
 
 	if cache.ChatCache.IsActive(chatId) {
-		queue := cache.ChatCache.GetQueue(chatId)
-		cache.ChatCache.AddSong(chatId, &saveCache)
+		position, err := cache.ChatCache.Enqueue(chatId, &saveCache)
+		if err != nil {
+			_, err = editTransient(updater, m, lang.GetString(langCode, "play_queue_full"))
+			return err
+		}
+		keepQuota(m)
 
-		queueInfo := fmt.Sprintf(
-			lang.GetString(langCode, "play_added_to_queue"),
-			len(queue), saveCache.URL, saveCache.Name, cache.SecToMin(saveCache.Duration), saveCache.User,
-		)
+		return announceEnqueued(m, updater, chatId, &saveCache, position, langCode)
+	}
 
-		_, err := updater.Edit(queueInfo, &telegram.SendOptions{ReplyMarkup: core.ControlButtons("play")})
+	waitPos, err := vc.Calls.Admit(chatId, func() error {
+		if _, err := cache.ChatCache.Enqueue(chatId, &saveCache); err != nil {
+			return err
+		}
+		cache.ChatCache.SetActive(chatId, false)
+		return nil
+	})
+	if err != nil {
+		_, err = editTransient(updater, m, lang.GetString(langCode, "play_queue_full"))
 		return err
 	}
+	keepQuota(m)
+	if waitPos > 0 {
+		_, err := updater.Edit(fmt.Sprintf(lang.GetString(langCode, "waiting_room_queued"), saveCache.Name, waitPos))
+		return err
+	}
+	defer vc.Calls.ReleaseSlot(chatId)
 
 	if saveCache.FilePath == "" {
 		_, err := updater.Edit(fmt.Sprintf(lang.GetString(langCode, "downloading"), song.Name))
@@ -278,11 +353,9 @@ func handleSingleTrack(m *telegram.NewMessage, updater *telegram.NewMessage, son
 			logger.Warn("[play.go - handleSingleTrack] Edit message failed: %v", err)
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-		defer cancel()
-		dlResult, trackInfo, err := vc.DownloadSong(ctx, &saveCache, m.Client)
+		dlResult, trackInfo, err := vc.Calls.DownloadForPlayback(chatId, &saveCache)
 		if err != nil {
-			_, err = updater.Edit(fmt.Sprintf(lang.GetString(langCode, "play_song_download_failed"), err.Error()))
+			_, err = editTransient(updater, m, userError(m, langCode, fmt.Sprintf(lang.GetString(langCode, "play_song_download_failed"), err.Error()), err))
 			return err
 		}
 
@@ -296,50 +369,100 @@ func handleSingleTrack(m *telegram.NewMessage, updater *telegram.NewMessage, son
 	}
 
 	cache.ChatCache.SetActive(chatId, true)
-	cache.ChatCache.AddSong(chatId, &saveCache)
-
-	if err := vc.Calls.PlayMedia(chatId, saveCache.FilePath, saveCache.IsVideo, ""); err != nil {
-		_, err = updater.Edit(err.Error())
+	position, err := cache.ChatCache.Enqueue(chatId, &saveCache)
+	if err != nil {
+		_, err = editTransient(updater, m, lang.GetString(langCode, "play_queue_full"))
 		return err
 	}
+	if position > 0 {
+		// Another request started playback while this one was downloading.
+		return announceEnqueued(m, updater, chatId, &saveCache, position, langCode)
+	}
 
-	nowPlaying := fmt.Sprintf(
-		lang.GetString(langCode, "play_now_playing"),
-		saveCache.URL, saveCache.Name, cache.SecToMin(song.Duration), saveCache.User,
-	)
+	if offset := vc.Calls.ResumeOffset(chatId, &saveCache); offset > 0 {
+		return offerResume(updater, chatId, &saveCache, offset, langCode)
+	}
+	vc.Calls.ForgetResumePoint(chatId)
+	if err := vc.Calls.StartTrack(chatId, &saveCache); err != nil {
+		_, editErr := editTransient(updater, m, userError(m, langCode, err.Error(), err))
+		if strings.Contains(err.Error(), "CHAT_ADMIN_REQUIRED") {
+			sendPermissionChecklist(m, langCode)
+		}
+		return editErr
+	}
+	return announceNowPlaying(updater, chatId, &saveCache, langCode)
+}
+
+// announceNowPlaying turns updater into the now-playing message of song, which has just started in chatId.
+func announceNowPlaying(updater *telegram.NewMessage, chatId int64, song *cache.CachedTrack, langCode string) error {
+	nowPlaying := vc.Calls.NowPlayingText(chatId, song, "play_now_playing", langCode)
 
 	_, err := updater.Edit(nowPlaying, &telegram.SendOptions{ReplyMarkup: core.ControlButtons("play")})
+	vc.Calls.SetNowPlayingMessage(chatId, updater.ID)
+	if err == nil {
+		vc.Calls.ShowCard(chatId, updater.ID, song, nowPlaying)
+	}
 	return err
 }
 
 // handleMultipleTracks handles multiple tracks.
-func handleMultipleTracks(m *telegram.NewMessage, updater *telegram.NewMessage, tracks []cache.MusicTrack, chatId int64, isVideo bool, langCode string) error {
+func handleMultipleTracks(m *telegram.NewMessage, updater *telegram.NewMessage, tracks []cache.MusicTrack, chatId int64, isVideo bool, langCode string) (int, error) {
 	isActive := cache.ChatCache.IsActive(chatId)
-	queue := cache.ChatCache.GetQueue(chatId)
 
+	skipDuplicates := noDuplicates(chatId) && !canForce(m)
+	requester, requesterUID := senderName(m, langCode), requesterID(m)
+	durationLimit := premium.For(requesterUID).SongDuration
+	ctx, cancel := db.Ctx()
+	blocked := slices.Concat(db.Instance.GetBlockedWords(ctx, chatId), db.Instance.GetGlobalBlockedWords(ctx, m.Client.Me().ID))
+	cancel()
 	queueHeader := lang.GetString(langCode, "play_added_to_queue_header")
 	var queueItems []string
 	var skippedTracks []string
 
-	for i, track := range tracks {
-		if track.Duration > int(config.Conf.SongDurationLimit) {
+	// Every track is checked first and the ones that pass are queued in one step, so a batch is never
+	// interleaved with another request's tracks.
+	var batch []*cache.CachedTrack
+	seen := make(map[string]bool)
+	for _, track := range tracks {
+		key := track.Platform + ":" + track.ID
+		if track.Duration > durationLimit || blockwords.Match(track.Name, blocked) || (skipDuplicates && (seen[key] || isDuplicate(chatId, track))) {
 			skippedTracks = append(skippedTracks, track.Name)
 			continue
 		}
-		position := len(queue) + i
-		saveCache := cache.CachedTrack{
+		seen[key] = true
+		batch = append(batch, &cache.CachedTrack{
 			Name: track.Name, TrackID: track.ID, Duration: track.Duration,
-			Thumbnail: track.Cover, User: m.Sender.FirstName, Platform: track.Platform,
-			IsVideo: isVideo, URL: track.URL,
-		}
-		if !isActive && i == 0 {
-			saveCache.Loop = 1
+			Thumbnail: track.Cover, User: requester, UserID: requesterUID, Platform: track.Platform,
+			IsVideo: isVideo, URL: track.URL, RequestID: requestID(m),
+		})
+	}
+	var first, added, waitPos int
+	if !isActive {
+		// A waiting chat's tracks are queued while it joins the waiting room, so it cannot be started
+		// before they are there.
+		waitPos, _ = vc.Calls.Admit(chatId, func() error {
+			first, added = cache.ChatCache.EnqueueMany(chatId, batch)
+			cache.ChatCache.SetActive(chatId, false)
+			return nil
+		})
+	}
+	if waitPos == 0 {
+		if !isActive {
+			defer vc.Calls.ReleaseSlot(chatId)
+			if len(batch) > 0 {
+				batch[0].Loop = 1
+			}
 		}
-		cache.ChatCache.AddSong(chatId, &saveCache)
-
+		first, added = cache.ChatCache.EnqueueMany(chatId, batch)
+	}
+	if added > 0 {
+		keepQuota(m)
+	}
+	dropped := len(batch) - added
+	for i, track := range batch[:added] {
 		queueItems = append(queueItems,
 			fmt.Sprintf(lang.GetString(langCode, "play_queue_item"),
-				position, track.Name, cache.SecToMin(track.Duration)),
+				first+i, track.Name, cache.SecToMin(track.Duration)),
 		)
 	}
 
@@ -350,21 +473,29 @@ func handleMultipleTracks(m *telegram.NewMessage, updater *telegram.NewMessage,
 
 	queueSummary := fmt.Sprintf(
 		lang.GetString(langCode, "play_queue_summary"),
-		len(cache.ChatCache.GetQueue(chatId)), cache.SecToMin(totalDuration), m.Sender.FirstName,
+		len(cache.ChatCache.GetQueue(chatId)), cache.SecToMin(totalDuration), requester,
 	)
 
 	fullMessage := queueHeader + strings.Join(queueItems, "\n") + queueSummary
 	if len(skippedTracks) > 0 {
-		fullMessage += fmt.Sprintf(lang.GetString(langCode, "play_skipped_tracks"), len(skippedTracks))
+		fullMessage += lang.Plural(langCode, "play_skipped_tracks", len(skippedTracks), len(skippedTracks))
+	}
+	if dropped > 0 {
+		fullMessage += fmt.Sprintf(lang.GetString(langCode, "play_dropped_tracks"), added, dropped)
 	}
 	if len(fullMessage) > 4096 {
 		fullMessage = queueSummary
 	}
 
-	if !isActive {
+	if !isActive && waitPos > 0 {
+		fullMessage += fmt.Sprintf(lang.GetString(langCode, "waiting_room_notice"), waitPos)
+	} else if !isActive {
 		_ = vc.Calls.PlayNext(chatId)
 	}
 
+	if isActive {
+		return added, announceBatch(m, updater, chatId, len(queueItems), fullMessage, langCode)
+	}
 	_, err := updater.Edit(fullMessage, &telegram.SendOptions{ReplyMarkup: core.ControlButtons("play")})
-	return err
+	return added, err
 }
@@ -15,6 +15,7 @@ import (
 
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/core/premium"
 	"ashokshau/tgmusic/src/lang"
 
 	"github.com/amarnathcjd/gogram/telegram"
@@ -39,8 +40,8 @@ func createPlaylistHandler(m *telegram.NewMessage) error {
 		return err
 	}
 
-	if len(userPlaylists) >= 10 {
-		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "playlist_create_limit"), 10))
+	if limits := premium.For(userID); len(userPlaylists) >= limits.Playlists {
+		_, _ = m.Reply(playlistLimitReached(langCode, limits))
 		return telegram.EndGroup
 	}
 
@@ -0,0 +1,70 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// topTracksLimit is how many of the most-played tracks the stats list.
+const topTracksLimit = 5
+
+// writePlayStats appends the playback counters of a chat, or the bot-wide ones for db.GlobalStatsID, to sb.
+func writePlayStats(ctx context.Context, sb *strings.Builder, langCode string, chatID int64) error {
+	stats, err := db.Instance.GetPlayStats(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	streamed := time.Duration(stats.Seconds) * time.Second
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_play_time"), streamed.String()))
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_play_completed"), stats.Completed))
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_play_skipped"), stats.Skipped))
+
+	tracks, err := db.Instance.TopTracks(ctx, chatID, topTracksLimit)
+	if err != nil || len(tracks) == 0 {
+		return err
+	}
+	sb.WriteString(lang.GetString(langCode, "stats_play_top"))
+	for i, track := range tracks {
+		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_play_top_item"), i+1, html.EscapeString(track.Name), track.Plays))
+	}
+	return nil
+}
+
+// chatStatsHandler handles the /chatstats command, showing the playback counters of the current chat to its admins.
+func chatStatsHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	vc.Calls.FlushChatStats(chatID)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "chatstats_header"), html.EscapeString(getChatTitle(m.Client, chatID))))
+	sb.WriteString(strings.Repeat("-", 40) + "\n")
+	if err := writePlayStats(ctx, &sb, langCode, chatID); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "stats_error"), err))
+		return nil
+	}
+
+	_, err := m.Reply(sb.String())
+	return err
+}
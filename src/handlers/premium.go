@@ -0,0 +1,278 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/premium"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// premiumPayloadPrefix starts the payload of premium invoices, followed by the buyer's ID and the days bought.
+const premiumPayloadPrefix = "premium:"
+
+var (
+	errBadPremiumPayload = errors.New("invalid premium payload")
+	errNoPremiumTarget   = errors.New("no user given")
+)
+
+// premiumPayload builds the invoice payload for userID buying days of premium.
+func premiumPayload(userID, days int64) []byte {
+	return []byte(fmt.Sprintf("%s%d:%d", premiumPayloadPrefix, userID, days))
+}
+
+// parsePremiumPayload returns the buyer and the days of an invoice payload built by premiumPayload.
+func parsePremiumPayload(payload []byte) (int64, int64, error) {
+	rest, ok := strings.CutPrefix(string(payload), premiumPayloadPrefix)
+	if !ok {
+		return 0, 0, errBadPremiumPayload
+	}
+	user, days, ok := strings.Cut(rest, ":")
+	if !ok {
+		return 0, 0, errBadPremiumPayload
+	}
+	userID, err := strconv.ParseInt(user, 10, 64)
+	if err != nil {
+		return 0, 0, errBadPremiumPayload
+	}
+	n, err := strconv.ParseInt(days, 10, 64)
+	if err != nil || n < 1 {
+		return 0, 0, errBadPremiumPayload
+	}
+	return userID, n, nil
+}
+
+// premiumUpsell returns a hint that premium lifts a limit the user just hit, or an empty string if the user
+// already has premium or it is not for sale.
+func premiumUpsell(langCode string, limits premium.Limits) string {
+	if limits.Premium || !config.Get().Premium.Selling() {
+		return ""
+	}
+	return lang.GetString(langCode, "premium_upsell")
+}
+
+// songTooLong tells the requester that a track exceeds their duration limit.
+func songTooLong(langCode string, limits premium.Limits) string {
+	return fmt.Sprintf(lang.GetString(langCode, "play_song_too_long"), limits.SongDuration/60) + premiumUpsell(langCode, limits)
+}
+
+// playlistLimitReached tells the user that they own as many playlists as they may.
+func playlistLimitReached(langCode string, limits premium.Limits) string {
+	return fmt.Sprintf(lang.GetString(langCode, "playlist_create_limit"), limits.Playlists) + premiumUpsell(langCode, limits)
+}
+
+// premiumHandler handles the /premium command. It shows the user's tier and what premium adds, and sends an
+// invoice when premium is for sale.
+func premiumHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	userID := m.SenderID()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), userID)
+
+	cfg := config.Get()
+	free, mine := premium.Free(), premium.For(userID)
+	paid := premium.Limits{
+		SongDuration: max(free.SongDuration, int(cfg.Premium.SongDuration)),
+		VideoHeight:  max(free.VideoHeight, int(cfg.Premium.VideoHeight)),
+		Playlists:    max(free.Playlists, int(cfg.Premium.Playlists)),
+	}
+
+	status := lang.GetString(langCode, "premium_status_free")
+	if mine.Premium {
+		status = fmt.Sprintf(lang.GetString(langCode, "premium_status_active"), mine.Until.UTC().Format("2006-01-02 15:04"))
+	}
+	text := fmt.Sprintf(lang.GetString(langCode, "premium_benefits"), status,
+		free.SongDuration/60, paid.SongDuration/60,
+		free.VideoHeight, paid.VideoHeight,
+		free.Playlists, paid.Playlists)
+
+	if !cfg.Premium.Selling() {
+		_, err := m.Reply(text + lang.GetString(langCode, "premium_not_for_sale"))
+		return err
+	}
+	if _, err := m.Reply(text); err != nil {
+		return err
+	}
+
+	title := lang.GetString(langCode, "premium_invoice_title")
+	invoice := &tg.InputMediaInvoice{
+		Title:       title,
+		Description: fmt.Sprintf(lang.GetString(langCode, "premium_invoice_description"), cfg.Premium.Days),
+		Invoice: &tg.Invoice{
+			Currency: cfg.Premium.Currency,
+			Prices:   []*tg.LabeledPrice{{Label: title, Amount: cfg.Premium.Price}},
+		},
+		Payload:      premiumPayload(userID, cfg.Premium.Days),
+		Provider:     cfg.Premium.ProviderToken,
+		ProviderData: &tg.DataJson{Data: "{}"},
+	}
+	_, err := m.Client.SendMedia(m.ChannelID(), invoice)
+	if err != nil {
+		_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "premium_invoice_failed"), err))
+	}
+	return err
+}
+
+// premiumPrecheckout approves the payment of a premium invoice if it still matches the current price.
+// Telegram charges the user only after this answer, so a stale invoice is refused rather than honoured.
+func premiumPrecheckout(u tg.Update, c *tg.Client) error {
+	q, ok := u.(*tg.UpdateBotPrecheckoutQuery)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, q.UserID, q.UserID)
+
+	p := config.Get().Premium
+	userID, _, err := parsePremiumPayload(q.Payload)
+	refusal := ""
+	switch {
+	case err != nil || userID != q.UserID:
+		refusal = lang.GetString(langCode, "premium_checkout_invalid")
+	case !p.Selling() || q.Currency != p.Currency || q.TotalAmount != p.Price:
+		refusal = lang.GetString(langCode, "premium_checkout_stale")
+	}
+	if _, err := c.MessagesSetBotPrecheckoutResults(refusal == "", q.QueryID, refusal); err != nil {
+		logger.Warn("[premium] Failed to answer the checkout of %d: %v", q.UserID, err)
+	}
+	return nil
+}
+
+// premiumPaymentHandler records a successful premium payment and tells the buyer until when they have it.
+func premiumPaymentHandler(m *tg.NewMessage) error {
+	action, ok := m.Action.(*tg.MessageActionPaymentSentMe)
+	if !ok {
+		return nil
+	}
+	userID, days, err := parsePremiumPayload(action.Payload)
+	if err != nil {
+		logger.Warn("[premium] Received a payment with an unknown payload %q from %d", action.Payload, m.SenderID())
+		return tg.EndGroup
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), userID)
+
+	payment := &db.PremiumPayment{Amount: action.TotalAmount, Currency: action.Currency, At: time.Now()}
+	if action.Charge != nil {
+		payment.ChargeID = action.Charge.ID
+	}
+	until, err := db.Instance.ExtendPremium(ctx, userID, time.Duration(days)*24*time.Hour, db.PremiumPaid, payment)
+	if err != nil {
+		// The user has been charged, so the owners must hear about it even if the reply fails.
+		logger.Error("[premium] Failed to record the payment %s of %d: %v", payment.ChargeID, userID, err)
+		_, _ = m.Client.SendMessage(config.Get().LoggerId, fmt.Sprintf(
+			"<b>Premium payment not recorded</b>\nUser: <code>%d</code>\nCharge: <code>%s</code>\nError: %s",
+			userID, html.EscapeString(payment.ChargeID), html.EscapeString(err.Error())))
+		_, _ = m.Reply(lang.GetString(langCode, "premium_payment_failed"))
+		return tg.EndGroup
+	}
+
+	writeAudit(db.AuditEntry{ActorID: userID, Action: "premium", ChatID: m.ChannelID(), Target: strconv.FormatInt(userID, 10),
+		Params: fmt.Sprintf("paid %d %s for %d days, charge %s", payment.Amount, payment.Currency, days, payment.ChargeID)})
+	_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "premium_payment_done"), until.UTC().Format("2006-01-02 15:04")))
+	return tg.EndGroup
+}
+
+// premiumTarget reads the user an owner command acts on, from a reply or the first argument, and returns the
+// remaining arguments.
+func premiumTarget(m *tg.NewMessage) (int64, []string, error) {
+	args := strings.Fields(m.Args())
+	if m.IsReply() {
+		reply, err := m.GetReplyMessage()
+		if err != nil {
+			return 0, nil, err
+		}
+		return reply.SenderID(), args, nil
+	}
+	if len(args) == 0 {
+		return 0, nil, errNoPremiumTarget
+	}
+	if id, err := strconv.ParseInt(args[0], 10, 64); err == nil {
+		return id, args[1:], nil
+	}
+	peer, err := m.Client.ResolveUsername(args[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	user, ok := peer.(*tg.UserObj)
+	if !ok {
+		return 0, nil, errNoPremiumTarget
+	}
+	return user.ID, args[1:], nil
+}
+
+// grantPremiumHandler handles the /grantpremium command, which gives a user premium for free:
+// "/grantpremium <user> [days]". Days default to the length of a paid period.
+func grantPremiumHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	userID, rest, err := premiumTarget(m)
+	if err != nil || userID == 0 {
+		_, err = m.Reply(lang.GetString(langCode, "grantpremium_usage"))
+		return err
+	}
+	days := config.Get().Premium.Days
+	if len(rest) > 0 {
+		days, err = strconv.ParseInt(rest[0], 10, 64)
+		if err != nil || days < 1 || days > 3650 {
+			_, err = m.Reply(lang.GetString(langCode, "grantpremium_usage"))
+			return err
+		}
+	}
+
+	until, err := db.Instance.ExtendPremium(ctx, userID, time.Duration(days)*24*time.Hour, db.PremiumGranted, nil)
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "premium_error"), html.EscapeString(err.Error())))
+		return err
+	}
+	audit(m, "grantpremium", strconv.FormatInt(userID, 10), strconv.FormatInt(days, 10))
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "grantpremium_done"), userID, days, until.UTC().Format("2006-01-02 15:04")))
+	return err
+}
+
+// revokePremiumHandler handles the /revokepremium command, which ends a user's premium immediately.
+func revokePremiumHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	userID, _, err := premiumTarget(m)
+	if err != nil || userID == 0 {
+		_, err = m.Reply(lang.GetString(langCode, "revokepremium_usage"))
+		return err
+	}
+
+	revoked, err := db.Instance.RevokePremium(ctx, userID)
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "premium_error"), html.EscapeString(err.Error())))
+		return err
+	}
+	if !revoked {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "revokepremium_none"), userID))
+		return err
+	}
+	audit(m, "revokepremium", strconv.FormatInt(userID, 10), "")
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "revokepremium_done"), userID))
+	return err
+}
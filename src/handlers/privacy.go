@@ -0,0 +1,101 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"time"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+func init() {
+	registerCallback("dm", &callbackRoute{Handle: deleteMeCallback})
+}
+
+// myDataHandler handles the /mydata command, which sends the user everything stored about them as JSON.
+func myDataHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	userID := m.SenderID()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), userID)
+
+	data, err := db.Instance.ExportUserData(ctx, userID)
+	if err != nil {
+		_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "mydata_failed"), err))
+		return err
+	}
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	audit(m, "mydata", strconv.FormatInt(userID, 10), "")
+	_, err = replyFile(m, langCode, nil, raw, &tg.MediaOptions{
+		FileName:      fmt.Sprintf("mydata_%d_%d.json", userID, time.Now().Unix()),
+		MimeType:      "application/json",
+		ForceDocument: true,
+		Caption:       lang.GetString(langCode, "mydata_caption"),
+	})
+	return err
+}
+
+// deleteMeHandler handles the /deleteme command. It asks the user to confirm before anything is deleted.
+func deleteMeHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	userID := m.SenderID()
+	langCode := db.Instance.LangFor(ctx, m.ChannelID(), userID)
+
+	id := strconv.FormatInt(userID, 10)
+	kb := tg.NewKeyboard().AddRow(
+		tg.Button.Data(lang.GetString(langCode, "deleteme_confirm_button"), callbackData("dm", "", id, "yes")),
+		tg.Button.Data(lang.GetString(langCode, "deleteme_cancel_button"), callbackData("dm", "", id, "no")),
+	)
+	_, err := m.Reply(lang.GetString(langCode, "deleteme_confirm"), &tg.SendOptions{ReplyMarkup: kb.Build()})
+	return err
+}
+
+// deleteMeCallback handles the buttons of the /deleteme confirmation. Only the user who asked may press them.
+func deleteMeCallback(c *callbackCtx) error {
+	userID, err := strconv.ParseInt(c.Arg(0), 10, 64)
+	if err != nil || userID != c.SenderID {
+		c.Answer(lang.GetString(c.LangCode, "deleteme_not_yours"), true)
+		return nil
+	}
+	if c.Arg(1) != "yes" {
+		_, err := c.Edit(lang.GetString(c.LangCode, "deleteme_cancelled"))
+		return err
+	}
+
+	vc.Calls.ForgetUser(userID)
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	deleted, err := db.Instance.DeleteUserData(ctx, userID)
+	if err != nil {
+		logger.Warn("[deleteme] Failed to delete the data of %d: %v", userID, err)
+		_, err = c.Edit(fmt.Sprintf(lang.GetString(c.LangCode, "deleteme_failed"), html.EscapeString(err.Error())))
+		return err
+	}
+
+	summary := fmt.Sprintf("profile=%t playlists=%d auth_chats=%d queues=%d stats=%d reports=%d",
+		deleted.Profile, deleted.Playlists, deleted.AuthChats, deleted.Queues, deleted.Stats, deleted.Reports)
+	// The entry leaves out the user's name, which was just deleted.
+	writeAudit(db.AuditEntry{ActorID: userID, Action: "deleteme", ChatID: c.ChannelID(), Params: summary})
+	_, err = c.Edit(fmt.Sprintf(lang.GetString(c.LangCode, "deleteme_done"),
+		deleted.Playlists, deleted.AuthChats, deleted.Reports))
+	return err
+}
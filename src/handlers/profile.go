@@ -0,0 +1,88 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/diag"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	defaultProfileSeconds = 30
+	maxProfileSeconds     = 300
+)
+
+// profileHandler handles the /profile command.
+// "/profile cpu|heap|goroutine [seconds]" captures a runtime profile and sends it to the owner in private.
+func profileHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	args := strings.Fields(strings.ToLower(m.Args()))
+	if len(args) == 0 || !slices.Contains(diag.Profiles, args[0]) {
+		_, err := m.Reply(lang.GetString(langCode, "profile_usage"))
+		return err
+	}
+	kind := args[0]
+
+	seconds := defaultProfileSeconds
+	if kind != "cpu" {
+		seconds = 0
+	}
+	if len(args) > 1 {
+		v, err := strconv.Atoi(args[1])
+		if err != nil || v < 0 || v > maxProfileSeconds {
+			_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "profile_invalid_seconds"), maxProfileSeconds))
+			return err
+		}
+		seconds = v
+	}
+
+	status, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "profile_started"), kind, seconds))
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = diag.Capture(requestCtx(m), kind, time.Duration(seconds)*time.Second, &buf); err != nil {
+		text := fmt.Sprintf(lang.GetString(langCode, "profile_error"), html.EscapeString(err.Error()))
+		if errors.Is(err, diag.ErrBusy) {
+			text = lang.GetString(langCode, "profile_busy")
+		}
+		_, err = status.Edit(text)
+		return err
+	}
+
+	_, err = m.Client.SendMedia(m.SenderID(), buf.Bytes(), &telegram.MediaOptions{
+		FileName:      fmt.Sprintf("%s_%d.pprof", kind, time.Now().Unix()),
+		MimeType:      "application/octet-stream",
+		ForceDocument: true,
+		Caption:       fmt.Sprintf(lang.GetString(langCode, "profile_caption"), kind, seconds),
+	})
+	if err != nil {
+		_, err = status.Edit(fmt.Sprintf(lang.GetString(langCode, "profile_error"), html.EscapeString(err.Error())))
+		return err
+	}
+
+	_, err = status.Edit(lang.GetString(langCode, "profile_sent"))
+	return err
+}
@@ -10,10 +10,11 @@ package handlers
 
 import (
 	"fmt"
-	"math"
+	"hash/fnv"
 	"strconv"
 	"strings"
 
+	"ashokshau/tgmusic/src/core"
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/lang"
@@ -22,13 +23,23 @@ import (
 	tg "github.com/amarnathcjd/gogram/telegram"
 )
 
+// queuePageSize is the number of upcoming tracks shown per page of the queue.
+const queuePageSize = 10
+
+func init() {
+	registerCallback("q", &callbackRoute{
+		Allow:  queueAllow,
+		Token:  func(cb *tg.CallbackQuery) string { return queueToken(cb.ChannelID()) },
+		Handle: queuePageCallback,
+	})
+}
+
 // queueHandler displays the current playback queue with detailed information.
 func queueHandler(m *tg.NewMessage) error {
 	chatID := m.ChannelID()
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
-	chat := m.Channel
 	queue := cache.ChatCache.GetQueue(chatID)
 	if len(queue) == 0 {
 		_, _ = m.Reply(lang.GetString(langCode, "queue_empty"))
@@ -40,11 +51,56 @@ func queueHandler(m *tg.NewMessage) error {
 		return nil
 	}
 
+	text, markup := buildQueuePage(m.Channel.Title, langCode, chatID, queue, 0)
+	_, err := m.Reply(text, &tg.SendOptions{ReplyMarkup: markup})
+	return err
+}
+
+// queueAllow lets through the presses of users who meet the chat's admin mode, like the /queue command.
+func queueAllow(cb *tg.CallbackQuery) string {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	chatID := cb.ChannelID()
+	return denial(ctx, cb.Client, chatID, callbackInvoker(cb), modeRequirement(db.Instance.GetAdminMode(ctx, chatID)))
+}
+
+// queuePageCallback switches the queue message to another page.
+func queuePageCallback(c *callbackCtx) error {
+	chatID := c.ChannelID()
+	queue := cache.ChatCache.GetQueue(chatID)
+	if len(queue) == 0 || !cache.ChatCache.IsActive(chatID) {
+		c.Answer(lang.GetString(c.LangCode, "queue_no_session"), true)
+		return nil
+	}
+
+	page, _ := strconv.Atoi(c.Arg(0))
+	text, markup := buildQueuePage(getChatTitle(c.Client, chatID), c.LangCode, chatID, queue, page)
+	_, err := c.Edit(text, &tg.SendOptions{ReplyMarkup: markup})
+	return err
+}
+
+// queueToken fingerprints a chat's queue so that pagination buttons become stale once the queue changes.
+func queueToken(chatID int64) string {
+	h := fnv.New32a()
+	for _, track := range cache.ChatCache.GetQueue(chatID) {
+		_, _ = h.Write([]byte(track.TrackID))
+		_, _ = h.Write([]byte{0})
+	}
+	return strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+// buildQueuePage renders one page of the queue along with its navigation keyboard.
+// The keyboard is nil when the queue fits on a single page.
+func buildQueuePage(title, langCode string, chatID int64, queue []*cache.CachedTrack, page int) (string, tg.ReplyMarkup) {
 	current := queue[0]
-	playedTime, _ := vc.Calls.PlayedTime(chatID)
+	upcoming := queue[1:]
+	playedTime, _ := vc.Calls.Elapsed(chatID)
+
+	pages := max(1, (len(upcoming)+queuePageSize-1)/queuePageSize)
+	page = max(0, min(page, pages-1))
 
 	var b strings.Builder
-	b.WriteString(fmt.Sprintf(lang.GetString(langCode, "queue_header"), chat.Title))
+	b.WriteString(fmt.Sprintf(lang.GetString(langCode, "queue_header"), title))
 
 	b.WriteString(lang.GetString(langCode, "queue_now_playing"))
 	b.WriteString(fmt.Sprintf(lang.GetString(langCode, "queue_track_title"), truncate(current.Name, 45)))
@@ -57,30 +113,36 @@ func queueHandler(m *tg.NewMessage) error {
 		b.WriteString(lang.GetString(langCode, "queue_loop_off"))
 	}
 	b.WriteString(lang.GetString(langCode, "queue_progress"))
-	if playedTime > 0 && playedTime < math.MaxInt {
-		b.WriteString(cache.SecToMin(int(playedTime)))
-	} else {
-		b.WriteString("0:00")
+	progress := "0:00"
+	if current.Duration > 0 {
+		playedTime = min(playedTime, current.Duration)
 	}
+	if playedTime > 0 {
+		progress = cache.SecToMin(playedTime)
+	}
+	b.WriteString(progress)
 	b.WriteString(" min\n")
 
-	if len(queue) > 1 {
-		b.WriteString(fmt.Sprintf(lang.GetString(langCode, "queue_next_up"), len(queue)-1))
+	if len(upcoming) > 0 {
+		b.WriteString(fmt.Sprintf(lang.GetString(langCode, "queue_next_up"), len(upcoming)))
 
-		for i, song := range queue[1:] {
-			if i >= 14 {
-				break
-			}
-			b.WriteString(strconv.Itoa(i + 1))
+		etas := cache.ChatCache.ETAs(chatID, playedTime)
+		start := page * queuePageSize
+		end := min(start+queuePageSize, len(upcoming))
+		for i, song := range upcoming[start:end] {
+			b.WriteString(strconv.Itoa(start + i + 1))
 			b.WriteString(". <code>")
 			b.WriteString(truncate(song.Name, 45))
 			b.WriteString("</code> | ")
 			b.WriteString(cache.SecToMin(song.Duration))
-			b.WriteString(" min\n")
+			b.WriteString(" min")
+			if pos := start + i + 1; pos < len(etas) && etas[pos] >= 0 {
+				b.WriteString(fmt.Sprintf(lang.GetString(langCode, "queue_item_eta"), cache.SecToMin(etas[pos])))
+			}
+			b.WriteString("\n")
 		}
-
-		if len(queue) > 15 {
-			b.WriteString(fmt.Sprintf(lang.GetString(langCode, "queue_more_tracks"), len(queue)-15))
+		if rest := len(upcoming) - end; rest > 0 {
+			b.WriteString(fmt.Sprintf(lang.GetString(langCode, "queue_more_tracks"), rest))
 		}
 	}
 
@@ -88,15 +150,21 @@ func queueHandler(m *tg.NewMessage) error {
 
 	text := b.String()
 	if len(text) > 4096 {
-		var sb strings.Builder
-		progress := "0:00"
-		if playedTime > 0 && playedTime < math.MaxInt {
-			progress = cache.SecToMin(int(playedTime))
-		}
-		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "queue_short_summary"), chat.Title, truncate(current.Name, 45), progress, cache.SecToMin(current.Duration), len(queue)))
-		text = sb.String()
+		text = fmt.Sprintf(lang.GetString(langCode, "queue_short_summary"), title, truncate(current.Name, 45), progress, cache.SecToMin(current.Duration), len(queue))
 	}
 
-	_, err := m.Reply(text)
-	return err
+	if pages == 1 {
+		return text, nil
+	}
+
+	token := queueToken(chatID)
+	var nav []tg.KeyboardButton
+	if page > 0 {
+		nav = append(nav, tg.Button.Data("« Prev", callbackData("q", token, strconv.Itoa(page-1))))
+	}
+	nav = append(nav, tg.Button.Data(fmt.Sprintf("%d/%d", page+1, pages), callbackData("q", token, strconv.Itoa(page))))
+	if page < pages-1 {
+		nav = append(nav, tg.Button.Data("Next »", callbackData("q", token, strconv.Itoa(page+1))))
+	}
+	return text, tg.NewKeyboard().AddRow(nav...).AddRow(core.CloseBtn).Build()
 }
@@ -0,0 +1,200 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/premium"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// queueExportVersion is the current version of the exported queue format.
+	queueExportVersion = 1
+	// maxQueueImportSize is the largest queue file, in bytes, that /importqueue accepts.
+	maxQueueImportSize = 64 * 1024
+	// maxQueueImportTracks is the maximum number of tracks enqueued from a single import.
+	maxQueueImportTracks = 50
+)
+
+// queueExport is the on-disk format produced by /exportqueue and consumed by /importqueue.
+type queueExport struct {
+	Version    int                `json:"version"`
+	ChatID     int64              `json:"chat_id"`
+	ExportedAt int64              `json:"exported_at"`
+	Tracks     []queueExportTrack `json:"tracks"`
+}
+
+// queueExportTrack is a single queue entry in an exported queue.
+type queueExportTrack struct {
+	Platform string `json:"platform"`
+	TrackID  string `json:"track_id"`
+	Title    string `json:"title"`
+	Duration int    `json:"duration"`
+	URL      string `json:"url,omitempty"`
+}
+
+// exportQueueHandler handles the /exportqueue command.
+// It sends the chat's current queue as a JSON document.
+func exportQueueHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	queue := cache.ChatCache.GetQueue(chatID)
+	if len(queue) == 0 {
+		_, err := replyTransient(m, lang.GetString(langCode, "queue_empty"), true)
+		return err
+	}
+
+	export := queueExport{
+		Version:    queueExportVersion,
+		ChatID:     chatID,
+		ExportedAt: time.Now().Unix(),
+		Tracks:     make([]queueExportTrack, 0, len(queue)),
+	}
+	for _, track := range queue {
+		export.Tracks = append(export.Tracks, queueExportTrack{
+			Platform: track.Platform,
+			TrackID:  track.TrackID,
+			Title:    track.Name,
+			Duration: track.Duration,
+			URL:      track.URL,
+		})
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = replyFile(m, langCode, nil, data, &telegram.MediaOptions{
+		FileName:      fmt.Sprintf("queue_%d.json", time.Now().Unix()),
+		MimeType:      "application/json",
+		ForceDocument: true,
+		Caption:       fmt.Sprintf(lang.GetString(langCode, "queue_export_caption"), len(export.Tracks)),
+	})
+	return err
+}
+
+// importQueueHandler handles the /importqueue command.
+// It must reply to a file produced by /exportqueue, and enqueues every valid entry.
+func importQueueHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	if !m.IsReply() {
+		_, err := replyTransient(m, lang.GetString(langCode, "queue_import_usage"), true)
+		return err
+	}
+
+	reply, err := m.GetReplyMessage()
+	if err != nil || reply.Document() == nil {
+		_, err = replyTransient(m, lang.GetString(langCode, "queue_import_usage"), true)
+		return err
+	}
+
+	if reply.Document().Size > maxQueueImportSize {
+		_, err = replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "queue_import_too_large"), maxQueueImportSize/1024), true)
+		return err
+	}
+
+	var buf bytes.Buffer
+	if _, err = reply.Download(&telegram.DownloadOptions{Buffer: &buf}); err != nil {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "queue_import_failed"), err.Error()))
+		return err
+	}
+
+	var export queueExport
+	if err = json.Unmarshal(buf.Bytes(), &export); err != nil {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "queue_import_failed"), err.Error()))
+		return err
+	}
+
+	if export.Version != queueExportVersion {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "queue_import_bad_version"), export.Version))
+		return err
+	}
+
+	tracks, skipped := validateQueueImport(export.Tracks, premium.For(requesterID(m)).SongDuration)
+	if len(tracks) == 0 {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "queue_import_nothing"), skipped))
+		return err
+	}
+
+	updater, err := m.Reply(lang.GetString(langCode, "play_searching"))
+	if err != nil {
+		return err
+	}
+
+	// Tracks left out for a full queue, a duplicate or a blocked word count as skipped too.
+	added, err := handleMultipleTracks(m, updater, tracks, chatID, false, langCode)
+	if err != nil {
+		logger.Warn("[importQueue] Failed to enqueue tracks for chat %d: %v", chatID, err)
+	}
+	skipped += len(tracks) - added
+
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "queue_import_done"), added, skipped))
+	return err
+}
+
+// validateQueueImport filters the entries of an imported queue, dropping tracks longer than durationLimit seconds.
+// Telegram files are dropped as well: their file references expire, so they cannot be fetched again from an
+// export. It returns the tracks that can be enqueued and the number of entries that were skipped.
+func validateQueueImport(entries []queueExportTrack, durationLimit int) ([]cache.MusicTrack, int) {
+	validPlatforms := map[string]bool{
+		cache.YouTube:  true,
+		cache.Spotify:  true,
+		cache.JioSaavn: true,
+		cache.Apple:    true,
+	}
+
+	var tracks []cache.MusicTrack
+	skipped := 0
+	for _, entry := range entries {
+		if len(tracks) >= maxQueueImportTracks {
+			skipped++
+			continue
+		}
+
+		entry.Title = strings.TrimSpace(entry.Title)
+		switch {
+		case !validPlatforms[entry.Platform],
+			entry.TrackID == "",
+			entry.Title == "",
+			entry.Duration < 0,
+			entry.Duration > durationLimit,
+			!strings.HasPrefix(entry.URL, "https://"):
+			skipped++
+			continue
+		}
+
+		tracks = append(tracks, cache.MusicTrack{
+			URL:      entry.URL,
+			Name:     entry.Title,
+			ID:       entry.TrackID,
+			Duration: entry.Duration,
+			Platform: entry.Platform,
+		})
+	}
+
+	return tracks, skipped
+}
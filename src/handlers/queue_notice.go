@@ -0,0 +1,127 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"time"
+
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/cleaner"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// Queue notice modes set with /queuenotice.
+const (
+	queueNoticeOff      = "off"
+	queueNoticeMinimal  = "minimal"
+	queueNoticeDetailed = "detailed"
+)
+
+// minimalNoticeDelay is how long minimal queue notices live in chats without clean mode.
+const minimalNoticeDelay = 10 * time.Second
+
+// queueNoticeMode returns the chat's queue notice mode.
+func queueNoticeMode(chatID int64) string {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return db.Instance.GetQueueNotice(ctx, chatID)
+}
+
+// queueETA returns how long until the track at position starts playing, formatted for display.
+func queueETA(langCode string, chatID int64, position int) string {
+	elapsed, _ := vc.Calls.Elapsed(chatID)
+	etas := cache.ChatCache.ETAs(chatID, elapsed)
+	if position <= 0 || position >= len(etas) || etas[position] < 0 {
+		return lang.GetString(langCode, "queue_eta_unknown")
+	}
+	return cache.SecToMin(etas[position])
+}
+
+// dropNotice deletes the searching message and the command that asked for it.
+func dropNotice(m, updater *telegram.NewMessage) {
+	_, _ = updater.Delete()
+	if !m.IsPrivate() {
+		_, _ = m.Delete()
+	}
+}
+
+// editMinimalNotice replaces the searching message with a one-line notice that deletes itself.
+func editMinimalNotice(m, updater *telegram.NewMessage, text string) error {
+	if _, err := updater.Edit(text); err != nil {
+		return err
+	}
+
+	delay := cleaner.ChatDelay(m.ChannelID())
+	if delay <= 0 {
+		delay = minimalNoticeDelay
+	}
+	ids := []int32{updater.ID}
+	if !m.IsPrivate() {
+		ids = append(ids, m.ID)
+	}
+	cleaner.Default.Schedule(m.Client, m.ChannelID(), delay, ids...)
+	return nil
+}
+
+// announceEnqueued tells the chat that track was added at position, as its queue notice mode asks.
+// Detailed notices show the track's cover when it has one, along with its position and the time until it plays.
+func announceEnqueued(m, updater *telegram.NewMessage, chatID int64, track *cache.CachedTrack, position int, langCode string) error {
+	switch queueNoticeMode(chatID) {
+	case queueNoticeOff:
+		dropNotice(m, updater)
+		return nil
+	case queueNoticeMinimal:
+		return editMinimalNotice(m, updater, fmt.Sprintf(lang.GetString(langCode, "queue_notice_minimal"), position, html.EscapeString(track.Name)))
+	}
+
+	text := fmt.Sprintf(
+		lang.GetString(langCode, "play_added_to_queue"),
+		position, track.URL, track.Name, cache.SecToMin(track.Duration), track.User,
+	) + fmt.Sprintf(lang.GetString(langCode, "queue_notice_eta"), queueETA(langCode, chatID, position))
+	markup := core.ControlButtons("play")
+
+	if track.Thumbnail != "" {
+		card, err := m.ReplyMedia(track.Thumbnail, &telegram.MediaOptions{Caption: text, ReplyMarkup: markup})
+		if err == nil {
+			_, _ = updater.Delete()
+			ids := []int32{card.ID}
+			if !m.IsPrivate() {
+				ids = append(ids, m.ID)
+			}
+			scheduleDelete(m.Client, chatID, ids...)
+			return nil
+		}
+		logger.Warn("[announceEnqueued] Failed to send the cover of %q: %v", track.Name, err)
+	}
+
+	_, err := editTransient(updater, m, text, &telegram.SendOptions{ReplyMarkup: markup})
+	return err
+}
+
+// announceBatch tells the chat that several tracks were added, as its queue notice mode asks.
+// Detailed mode keeps the full list in text.
+func announceBatch(m, updater *telegram.NewMessage, chatID int64, added int, text, langCode string) error {
+	switch queueNoticeMode(chatID) {
+	case queueNoticeOff:
+		dropNotice(m, updater)
+		return nil
+	case queueNoticeMinimal:
+		return editMinimalNotice(m, updater, lang.Plural(langCode, "queue_notice_minimal_batch", added, added))
+	}
+
+	_, err := updater.Edit(text, &telegram.SendOptions{ReplyMarkup: core.ControlButtons("play")})
+	return err
+}
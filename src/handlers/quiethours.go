@@ -0,0 +1,103 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/quiethours"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// quietHoursEnd reports whether chatID is within its quiet hours, and if so when they end.
+func quietHoursEnd(chatID int64) (time.Time, bool) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	q := db.Instance.GetQuietHours(ctx, chatID)
+	if q == nil {
+		return time.Time{}, false
+	}
+	start, err := quiethours.ParseClock(q.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := quiethours.ParseClock(q.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return quiethours.Window{Start: start, End: end, Loc: quiethours.Location(q.TZ)}.Until(time.Now())
+}
+
+// rejectQuietHours tells the sender of m that playback cannot start until the chat's quiet hours end, and
+// reports whether it did. Admins can play anyway with the -force flag.
+func rejectQuietHours(m *tg.NewMessage, chatID int64, langCode string) bool {
+	until, quiet := quietHoursEnd(chatID)
+	if !quiet || canForce(m) {
+		return false
+	}
+	_, _ = replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "quiet_hours_active"),
+		until.Format("15:04"), until.Location().String()), true)
+	return true
+}
+
+// parseQuietHours parses the arguments of /quiethours: "HH:MM-HH:MM [time zone]".
+func parseQuietHours(args string) (*db.QuietHours, error) {
+	w, err := quiethours.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	return &db.QuietHours{Start: quiethours.FormatClock(w.Start), End: quiethours.FormatClock(w.End), TZ: w.Loc.String()}, nil
+}
+
+// quietHoursHandler handles the /quiethours command.
+// "/quiethours 01:00-07:00 Europe/Berlin" stops new playback in that window, and "/quiethours off" lifts it.
+func quietHoursHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	args := strings.TrimSpace(m.Args())
+	switch strings.ToLower(args) {
+	case "":
+		current := lang.GetString(langCode, "quiethours_none")
+		if q := db.Instance.GetQuietHours(ctx, chatID); q != nil {
+			current = fmt.Sprintf("%s–%s %s", q.Start, q.End, quiethours.Location(q.TZ).String())
+		}
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "quiethours_usage"), html.EscapeString(current)))
+		return err
+	case "off", "disable":
+		if err := db.Instance.SetQuietHours(ctx, chatID, nil); err != nil {
+			_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "quiethours_error"), html.EscapeString(err.Error())))
+			return err
+		}
+		audit(m, "quiethours", "", "off")
+		_, err := replyTransient(m, lang.GetString(langCode, "quiethours_cleared"), true)
+		return err
+	}
+
+	q, err := parseQuietHours(args)
+	if err != nil {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "quiethours_invalid"), html.EscapeString(args)))
+		return err
+	}
+	if err := db.Instance.SetQuietHours(ctx, chatID, q); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "quiethours_error"), html.EscapeString(err.Error())))
+		return err
+	}
+	audit(m, "quiethours", "", fmt.Sprintf("%s-%s %s", q.Start, q.End, q.TZ))
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "quiethours_set"), q.Start, q.End, html.EscapeString(q.TZ)))
+	return err
+}
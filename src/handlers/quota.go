@@ -0,0 +1,173 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// maxRequestLimit is the highest daily request limit a chat can set.
+const maxRequestLimit = 1000
+
+// quotaDay returns the chat-local date that requests in chatID made at now count towards, and when that
+// day ends.
+func quotaDay(chatID int64, now time.Time) (string, time.Time) {
+	local := now.In(chatLocation(chatID))
+	midnight := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, local.Location())
+	return local.Format(time.DateOnly), midnight
+}
+
+// quotaExempt reports whether the sender of m is exempt from the chat's request limit: admins, authorized
+// users, and senders that are not users, such as anonymous admins.
+func quotaExempt(ctx context.Context, m *tg.NewMessage) bool {
+	return requesterID(m) == 0 || messageDenial(ctx, m, requireAuth) == ""
+}
+
+// quotaHold is a request reserved against its sender's daily limit while /play works out what to queue.
+type quotaHold struct {
+	chatID int64
+	userID int64
+	day    string
+	kept   atomic.Bool
+}
+
+// quotaHolds maps the message of each /play in progress to the request it reserved.
+var quotaHolds sync.Map
+
+// reserveQuota reserves one of the requests the sender of m may make today in chatID. If none is left, it
+// tells them so and returns false. The reservation is counted at once, so concurrent requests cannot exceed
+// the limit, and settleQuota refunds it unless keepQuota marks it used.
+func reserveQuota(m *tg.NewMessage, chatID int64, langCode string) bool {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	limit := db.Instance.GetRequestLimit(ctx, chatID)
+	if limit <= 0 || quotaExempt(ctx, m) {
+		return true
+	}
+	userID := requesterID(m)
+	day, resets := quotaDay(chatID, time.Now())
+	used, err := db.Instance.AddRequest(ctx, chatID, userID, day)
+	if err != nil {
+		logger.Warn("[quota] Failed to count a request of %d in %d: %v", userID, chatID, err)
+		return true
+	}
+	if used > limit {
+		if err := db.Instance.RefundRequest(ctx, chatID, userID, day); err != nil {
+			logger.Warn("[quota] Failed to refund a refused request of %d in %d: %v", userID, chatID, err)
+		}
+		_, _ = replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "quota_exhausted"),
+			used-1, limit, cache.SecToMin(int(time.Until(resets).Seconds()))), true)
+		return false
+	}
+	quotaHolds.Store(m, &quotaHold{chatID: chatID, userID: userID, day: day})
+	return true
+}
+
+// keepQuota marks the request reserved for m as used, once it has queued something.
+func keepQuota(m *tg.NewMessage) {
+	if h, ok := quotaHolds.Load(m); ok {
+		h.(*quotaHold).kept.Store(true)
+	}
+}
+
+// settleQuota refunds the request reserved for m unless keepQuota marked it used.
+func settleQuota(m *tg.NewMessage) {
+	v, ok := quotaHolds.LoadAndDelete(m)
+	if !ok {
+		return
+	}
+	h := v.(*quotaHold)
+	if h.kept.Load() {
+		return
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if err := db.Instance.RefundRequest(ctx, h.chatID, h.userID, h.day); err != nil {
+		logger.Warn("[quota] Failed to refund a request of %d in %d: %v", h.userID, h.chatID, err)
+	}
+}
+
+// requestLimitHandler handles the /requestlimit command.
+// "/requestlimit 10" lets each member request 10 tracks per day, and "/requestlimit 0" removes the limit.
+func requestLimitHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	arg := strings.ToLower(strings.TrimSpace(m.Args()))
+	if arg == "" {
+		current := lang.GetString(langCode, "quota_unlimited")
+		if limit := db.Instance.GetRequestLimit(ctx, chatID); limit > 0 {
+			current = strconv.Itoa(limit)
+		}
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "requestlimit_usage"), current, maxRequestLimit))
+		return err
+	}
+	if arg == "off" {
+		arg = "0"
+	}
+	limit, err := strconv.Atoi(arg)
+	if err != nil || limit < 0 || limit > maxRequestLimit {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "requestlimit_invalid"), maxRequestLimit))
+		return err
+	}
+	if err := db.Instance.SetRequestLimit(ctx, chatID, limit); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "requestlimit_error"), err.Error()))
+		return err
+	}
+
+	audit(m, "requestlimit", "", strconv.Itoa(limit))
+	if limit == 0 {
+		_, err = replyTransient(m, lang.GetString(langCode, "requestlimit_removed"), true)
+		return err
+	}
+	_, err = replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "requestlimit_set"), limit), true)
+	return err
+}
+
+// myQuotaHandler handles the /myquota command, which shows the caller how many requests they have left today.
+func myQuotaHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.LangFor(ctx, chatID, m.SenderID())
+
+	limit := db.Instance.GetRequestLimit(ctx, chatID)
+	switch {
+	case limit <= 0:
+		_, err := replyTransient(m, lang.GetString(langCode, "myquota_unlimited"), true)
+		return err
+	case quotaExempt(ctx, m):
+		_, err := replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "myquota_exempt"), limit), true)
+		return err
+	}
+
+	day, resets := quotaDay(chatID, time.Now())
+	used, err := db.Instance.RequestsOn(ctx, chatID, requesterID(m), day)
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "requestlimit_error"), err.Error()))
+		return err
+	}
+	_, err = replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "myquota_status"),
+		used, limit, max(0, limit-used), cache.SecToMin(int(time.Until(resets).Seconds()))), true)
+	return err
+}
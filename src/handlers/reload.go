@@ -0,0 +1,53 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// reloadConfigHandler handles the /reloadconfig command.
+// It re-reads the configuration without restarting, so active streams keep playing.
+func reloadConfigHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	res, err := config.Reload()
+	if res == nil {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "reload_config_failed"), html.EscapeString(err.Error())))
+		return err
+	}
+
+	audit(m, "reloadconfig", "", strings.Join(res.Changed, ", "))
+
+	var b strings.Builder
+	if len(res.Changed) == 0 {
+		b.WriteString(lang.GetString(langCode, "reload_config_unchanged"))
+	} else {
+		b.WriteString(fmt.Sprintf(lang.GetString(langCode, "reload_config_done"), strings.Join(res.Changed, ", ")))
+	}
+	if len(res.Ignored) > 0 {
+		b.WriteString("\n\n" + fmt.Sprintf(lang.GetString(langCode, "reload_config_ignored"), strings.Join(res.Ignored, ", ")))
+	}
+	if err != nil {
+		b.WriteString("\n\n" + fmt.Sprintf(lang.GetString(langCode, "reload_config_failed"), html.EscapeString(err.Error())))
+	}
+
+	_, err = m.Reply(b.String())
+	return err
+}
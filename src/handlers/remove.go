@@ -48,12 +48,11 @@ func removeHandler(m *telegram.NewMessage) error {
 		return nil
 	}
 
-	if trackNum <= 0 || trackNum > len(queue) {
-		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "remove_out_of_range"), len(queue)))
+	if trackNum <= 0 || !cache.ChatCache.RemoveTrack(chatID, trackNum) {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "remove_out_of_range"), len(queue)-1))
 		return nil
 	}
 
-	cache.ChatCache.RemoveTrack(chatID, trackNum)
-	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "remove_success"), trackNum, m.Sender.FirstName))
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "remove_success"), trackNum, senderName(m, langCode)))
 	return err
 }
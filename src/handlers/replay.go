@@ -0,0 +1,78 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// replayHandler handles the /replay command, which restarts the current track from the beginning.
+func replayHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	if !cache.ChatCache.IsActive(chatID) {
+		_, err := m.Reply(lang.GetString(langCode, "no_track_playing"))
+		return err
+	}
+
+	if err := vc.Calls.Replay(chatID); err != nil {
+		text, ok := playbackStateText(langCode, err)
+		if !ok {
+			text = fmt.Sprintf(lang.GetString(langCode, "replay_error"), err.Error())
+		}
+		_, _ = m.Reply(text)
+		return nil
+	}
+
+	_, err := replyTransient(m, lang.GetString(langCode, "replay_success"), false)
+	return err
+}
+
+// previousHandler handles the /previous command, which switches back to the last finished track.
+func previousHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	if !cache.ChatCache.IsActive(chatID) {
+		_, err := m.Reply(lang.GetString(langCode, "no_track_playing"))
+		return err
+	}
+
+	prev, err := vc.Calls.Previous(chatID)
+	if err != nil {
+		text, ok := playbackStateText(langCode, err)
+		if !ok {
+			switch {
+			case errors.Is(err, vc.ErrNoPrevious):
+				text = lang.GetString(langCode, "previous_none")
+			case errors.Is(err, cache.ErrQueueFull):
+				text = lang.GetString(langCode, "previous_queue_full")
+			default:
+				text = fmt.Sprintf(lang.GetString(langCode, "previous_error"), err.Error())
+			}
+		}
+		_, _ = m.Reply(text)
+		return nil
+	}
+
+	_, err = replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "previous_success"), html.EscapeString(prev.Name)), false)
+	return err
+}
@@ -0,0 +1,227 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/counters"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/core/shutdown"
+	"ashokshau/tgmusic/src/core/workpool"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// reportWindow is how long repeats of an error are collected before they are reported again.
+const reportWindow = 10 * time.Minute
+
+// maxReportText caps the error text included in a report.
+const maxReportText = 1500
+
+// digitsRegex matches numbers, which are left out of error signatures so that the same failure in different
+// chats or with different IDs is reported once.
+var digitsRegex = regexp.MustCompile(`\d+`)
+
+// errorReport is an error signature that has been reported in the current window.
+type errorReport struct {
+	handler string
+	text    string
+	chatID  int64 // chatID is the chat of the latest occurrence.
+	count   int   // count is how many times the error happened since it was last reported.
+}
+
+// errorReporter sends deduplicated handler errors to the report chat.
+type errorReporter struct {
+	mu      sync.Mutex
+	client  *tg.Client
+	pending map[string]*errorReport
+}
+
+var reporter = &errorReporter{pending: make(map[string]*errorReport)}
+
+// add records an error. The first occurrence of a signature is reported at once; repeats within the window are
+// counted and reported together when the window ends.
+func (r *errorReporter) add(handler string, chatID int64, text string) {
+	sig := handler + "|" + digitsRegex.ReplaceAllString(text, "#")
+	counters.Add(counters.Errors, sig, 1)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rep, ok := r.pending[sig]; ok {
+		rep.count++
+		rep.chatID = chatID
+		rep.text = text
+		return
+	}
+
+	rep := &errorReport{handler: handler, text: text, chatID: chatID, count: 1}
+	r.pending[sig] = rep
+	go r.send(*rep)
+	rep.count = 0
+	time.AfterFunc(reportWindow, func() { r.flush(sig) })
+}
+
+// flush reports the repeats of sig collected during the window that just ended. If there were any, a new window
+// starts so that the signature is still reported at most once per window.
+func (r *errorReporter) flush(sig string) {
+	r.mu.Lock()
+	rep, ok := r.pending[sig]
+	if !ok || rep.count == 0 {
+		delete(r.pending, sig)
+		r.mu.Unlock()
+		return
+	}
+	snapshot := *rep
+	rep.count = 0
+	r.mu.Unlock()
+
+	r.send(snapshot)
+	time.AfterFunc(reportWindow, func() { r.flush(sig) })
+}
+
+// send posts rep to the report chat.
+func (r *errorReporter) send(rep errorReport) {
+	chatID := config.ReportChatID()
+	if r.client == nil || chatID == 0 {
+		return
+	}
+
+	text := config.Redact(rep.text)
+	if len(text) > maxReportText {
+		text = text[:maxReportText] + "…"
+	}
+	msg := fmt.Sprintf(
+		"<b>⚠️ Handler error</b>\n\n‣ <b>Handler:</b> <code>%s</code>\n‣ <b>Chat:</b> <code>%d</code>\n‣ <b>Count:</b> %d in the last %s\n\n<pre>%s</pre>",
+		html.EscapeString(rep.handler),
+		rep.chatID,
+		rep.count,
+		reportWindow,
+		html.EscapeString(text),
+	)
+	if _, err := r.client.SendMessage(chatID, msg); err != nil {
+		logger.Warn("[report] Failed to send the error report: %v", err)
+	}
+}
+
+// reportError logs and reports err unless it is a normal way for a handler to stop.
+func reportError(handler, reqID string, chatID int64, err error) {
+	if err == nil || errors.Is(err, tg.EndGroup) || errors.Is(err, context.Canceled) {
+		return
+	}
+	logger.Request(reqID).Error("[%s] chat=%d: %v", handler, chatID, err)
+	noteError(chatID, reqID)
+	reporter.add(handler, chatID, err.Error())
+}
+
+// recoverPanic stops a handler panic from reaching the dispatcher. It logs the stack and reports the panic.
+// It must be deferred directly by the handler wrapper.
+func recoverPanic(handler, reqID string, chatID int64, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	logger.Request(reqID).Error("[%s] chat=%d: panic: %v\n%s", handler, chatID, r, debug.Stack())
+	reporter.add(handler, chatID, fmt.Sprintf("panic: %v", r))
+	*err = nil
+}
+
+// guard wraps a command handler so that it runs on the dispatcher, its errors are reported and its panics recovered.
+// Each call gets a request ID that the handler can pass downstream with requestCtx, and counts as activity of
+// the chat and its sender. Once shutdown has begun, new messages are ignored.
+func guard(name string, h func(*tg.NewMessage) error) func(*tg.NewMessage) error {
+	return guardMessage(name, workpool.High, func(m *tg.NewMessage) error {
+		noteActivity(m)
+		return h(m)
+	})
+}
+
+// guardService is guard for service message handlers. They report voice chat starts and ends, which the player's
+// state depends on, so they run at command priority and are never dropped, but do not count as activity.
+func guardService(name string, h func(*tg.NewMessage) error) func(*tg.NewMessage) error {
+	return guardMessage(name, workpool.High, h)
+}
+
+// guardMessage is guard with the given dispatcher priority.
+func guardMessage(name string, priority workpool.Priority, h func(*tg.NewMessage) error) func(*tg.NewMessage) error {
+	run := func(m *tg.NewMessage) (err error) {
+		defer trackHandler(name)()
+		id, done := beginRequest(m)
+		defer done()
+		defer recoverPanic(name, id, m.ChannelID(), &err)
+		err = h(m)
+		reportError(name, id, m.ChannelID(), err)
+		return err
+	}
+	return func(m *tg.NewMessage) error {
+		if shutdown.Stopping() {
+			return nil
+		}
+		dispatch(priority, func() { _ = run(m) })
+		return nil
+	}
+}
+
+// guardCallback is guard for callback query handlers.
+func guardCallback(name string, h func(*tg.CallbackQuery) error) func(*tg.CallbackQuery) error {
+	run := func(cb *tg.CallbackQuery) (err error) {
+		defer trackHandler(name)()
+		id := logging.NewRequestID()
+		defer recoverPanic(name, id, cb.ChannelID(), &err)
+		err = h(cb)
+		reportError(name, id, cb.ChannelID(), err)
+		return err
+	}
+	return func(cb *tg.CallbackQuery) error {
+		if shutdown.Stopping() {
+			return nil
+		}
+		dispatch(workpool.High, func() { _ = run(cb) })
+		return nil
+	}
+}
+
+// guardTask wraps a job the bot starts on its own, such as a scheduled play, so that it runs on the dispatcher
+// like a handler: watched by the watchdog, its errors reported and its panics recovered.
+func guardTask(name string, chatID int64, fn func() error) func() {
+	run := func() (err error) {
+		defer trackHandler(name)()
+		id := logging.NewRequestID()
+		defer recoverPanic(name, id, chatID, &err)
+		err = fn()
+		reportError(name, id, chatID, err)
+		return err
+	}
+	return func() { _ = run() }
+}
+
+// guardParticipant is guard for participant update handlers. They track the bot's own membership and admin
+// rights, so like service messages they are never dropped.
+func guardParticipant(name string, h func(*tg.ParticipantUpdate) error) func(*tg.ParticipantUpdate) error {
+	run := func(pu *tg.ParticipantUpdate) (err error) {
+		defer trackHandler(name)()
+		id := logging.NewRequestID()
+		defer recoverPanic(name, id, pu.ChannelID(), &err)
+		err = h(pu)
+		reportError(name, id, pu.ChannelID(), err)
+		return err
+	}
+	return func(pu *tg.ParticipantUpdate) error {
+		dispatch(workpool.High, func() { _ = run(pu) })
+		return nil
+	}
+}
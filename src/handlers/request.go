@@ -0,0 +1,74 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/core/shutdown"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// requestIDs maps the message a handler is running for to the ID of that request.
+var requestIDs sync.Map
+
+// lastErrorIDs holds the request ID of the latest failure in each chat, so that /report can point at its logs.
+var lastErrorIDs = cache.NewCache[string](time.Hour)
+
+// noteError records id as the latest failed request in chatID.
+func noteError(chatID int64, id string) {
+	if id != "" {
+		lastErrorIDs.Set(strconv.FormatInt(chatID, 10), id)
+	}
+}
+
+// lastErrorID returns the request ID of the latest failure in chatID within the past hour, if any.
+func lastErrorID(chatID int64) string {
+	id, _ := lastErrorIDs.Get(strconv.FormatInt(chatID, 10))
+	return id
+}
+
+// beginRequest assigns a new request ID to m for the duration of its handler. The returned func releases it.
+func beginRequest(m *tg.NewMessage) (string, func()) {
+	id := logging.NewRequestID()
+	requestIDs.Store(m, id)
+	return id, func() { requestIDs.Delete(m) }
+}
+
+// requestID returns the ID of the request m is being handled for, or an empty string outside a handler.
+func requestID(m *tg.NewMessage) string {
+	id, _ := requestIDs.Load(m)
+	s, _ := id.(string)
+	return s
+}
+
+// requestCtx returns a background context carrying the request ID of m, so that downstream logs can be traced back to it.
+func requestCtx(m *tg.NewMessage) context.Context {
+	return logging.WithRequestID(shutdown.Context(), requestID(m))
+}
+
+// userError logs err under the request ID of m and returns text, the message shown to the user, with that ID
+// appended so that support can find the logs of the failed request.
+func userError(m *tg.NewMessage, langCode, text string, err error) string {
+	id := requestID(m)
+	logger.Request(id).Warn("chat=%d: %v", m.ChannelID(), err)
+	noteError(m.ChannelID(), id)
+	if id == "" {
+		return text
+	}
+	return text + fmt.Sprintf(lang.GetString(langCode, "error_id"), id)
+}
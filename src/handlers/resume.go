@@ -0,0 +1,125 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+func init() {
+	registerCallback("rs", &callbackRoute{
+		Allow:  permitCallback(requireAdmin),
+		Token:  resumeToken,
+		Handle: resumeCallback,
+	})
+}
+
+// resumeToken identifies the snapshot a resume button was rendered for.
+func resumeToken(cb *telegram.CallbackQuery) string {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	snap, err := db.Instance.GetQueueSnapshot(ctx, cb.ChannelID())
+	if err != nil || snap == nil {
+		return "0"
+	}
+	return strconv.FormatInt(snap.SavedAt.Unix(), 36)
+}
+
+// ResumeInterrupted handles the queue snapshots left behind by the previous run.
+// Stale snapshots are discarded with a notice; the others are resumed automatically
+// when AUTO_RESUME is set, or offered to the chat with a "Resume playback" button.
+func ResumeInterrupted(client *telegram.Client) {
+	ctx, cancel := db.Ctx()
+	snaps, err := db.Instance.GetQueueSnapshots(ctx)
+	cancel()
+	if err != nil {
+		logger.Warn("[ResumeInterrupted] Failed to load queue snapshots: %v", err)
+		return
+	}
+
+	staleAfter := time.Duration(config.Get().ResumeStaleAfter) * time.Second
+	for i := range snaps {
+		snap := &snaps[i]
+		if len(snap.Tracks) == 0 {
+			continue
+		}
+
+		ctx, cancel := db.Ctx()
+		langCode := db.Instance.GetLang(ctx, snap.ChatID)
+		cancel()
+		title := html.EscapeString(snap.Tracks[0].Name)
+
+		if time.Since(snap.SavedAt) > staleAfter {
+			ctx, cancel := db.Ctx()
+			_ = db.Instance.DeleteQueueSnapshot(ctx, snap.ChatID)
+			cancel()
+			_, _ = client.SendMessage(snap.ChatID, fmt.Sprintf(lang.GetString(langCode, "resume_stale"), title))
+			continue
+		}
+
+		if config.Get().AutoResume {
+			if err := vc.Calls.RestoreSnapshot(snap); err != nil {
+				logger.Warn("[ResumeInterrupted] Failed to resume playback in %d: %v", snap.ChatID, err)
+				_, _ = client.SendMessage(snap.ChatID, fmt.Sprintf(lang.GetString(langCode, "resume_failed"), err.Error()))
+				continue
+			}
+			_, _ = client.SendMessage(snap.ChatID, fmt.Sprintf(lang.GetString(langCode, "resume_auto"), title))
+			continue
+		}
+
+		token := strconv.FormatInt(snap.SavedAt.Unix(), 36)
+		markup := telegram.NewKeyboard().AddRow(
+			telegram.Button.Data(lang.GetString(langCode, "resume_button"), callbackData("rs", token, "go")),
+			telegram.Button.Data(lang.GetString(langCode, "resume_discard_button"), callbackData("rs", token, "discard")),
+		).Build()
+		_, _ = client.SendMessage(snap.ChatID,
+			fmt.Sprintf(lang.GetString(langCode, "resume_offer"), title, len(snap.Tracks)),
+			&telegram.SendOptions{ReplyMarkup: markup},
+		)
+	}
+}
+
+// resumeCallback handles the buttons of the resume offer.
+func resumeCallback(c *callbackCtx) error {
+	chatID := c.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	snap, err := db.Instance.GetQueueSnapshot(ctx, chatID)
+	if err != nil || snap == nil {
+		c.Answer(lang.GetString(c.LangCode, "callback_stale"), true)
+		return err
+	}
+
+	if c.Arg(0) == "discard" {
+		_ = db.Instance.DeleteQueueSnapshot(ctx, chatID)
+		_, err = c.Edit(lang.GetString(c.LangCode, "resume_discarded"))
+		return err
+	}
+
+	c.Answer(lang.GetString(c.LangCode, "resume_starting"), false)
+	if err := vc.Calls.RestoreSnapshot(snap); err != nil {
+		_, _ = c.Edit(fmt.Sprintf(lang.GetString(c.LangCode, "resume_failed"), err.Error()))
+		return err
+	}
+
+	_, err = c.Edit(fmt.Sprintf(lang.GetString(c.LangCode, "resume_done"), html.EscapeString(snap.Tracks[0].Name), c.Sender.FirstName))
+	return err
+}
@@ -0,0 +1,332 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/quiethours"
+	"ashokshau/tgmusic/src/core/workpool"
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// maxScheduledPlays is how many plays a chat may have scheduled at once.
+	maxScheduledPlays = 5
+	// scheduleMaxAhead is how far ahead a play can be scheduled.
+	scheduleMaxAhead = 7 * 24 * time.Hour
+	// scheduleMinAhead is how soon a play can be scheduled.
+	scheduleMinAhead = time.Minute
+	// scheduleGrace is how late a play may still start, for plays that came due while the bot was down.
+	scheduleGrace = 10 * time.Minute
+	// schedulePoll is how often the scheduler looks for plays that are due.
+	schedulePoll = 20 * time.Second
+	// scheduleTimeFormat is how the time of a scheduled play is shown.
+	scheduleTimeFormat = "Mon 02 Jan 15:04"
+)
+
+var (
+	errBadScheduleTime = errors.New("invalid schedule time")
+	errScheduleTooFar  = errors.New("schedule time too far ahead")
+	errScheduleTooSoon = errors.New("schedule time too soon")
+)
+
+var schedulerOnce sync.Once
+
+func init() {
+	registerCallback("sc", &callbackRoute{
+		Allow:  permitCallback(requireAdmin),
+		Handle: scheduleCancelCallback,
+	})
+}
+
+// chatLocation returns the time zone set with /timezone for chatID, falling back to UTC.
+func chatLocation(chatID int64) *time.Location {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	tz := db.Instance.GetTimezone(ctx, chatID)
+	if tz == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// parseScheduleTime parses when a play should start: a clock time "HH:MM" in loc, meaning its next
+// occurrence, or a delay such as "45m" or "2h30m".
+func parseScheduleTime(spec string, loc *time.Location, now time.Time) (time.Time, error) {
+	var at time.Time
+	if strings.Contains(spec, ":") {
+		minutes, err := quiethours.ParseClock(spec)
+		if err != nil {
+			return time.Time{}, errBadScheduleTime
+		}
+		local := now.In(loc)
+		at = time.Date(local.Year(), local.Month(), local.Day(), minutes/60, minutes%60, 0, 0, loc)
+		if !at.After(local) {
+			at = time.Date(local.Year(), local.Month(), local.Day()+1, minutes/60, minutes%60, 0, 0, loc)
+		}
+	} else {
+		d, err := time.ParseDuration(spec)
+		if err != nil {
+			return time.Time{}, errBadScheduleTime
+		}
+		at = now.Add(d)
+	}
+
+	switch {
+	case at.Sub(now) < scheduleMinAhead:
+		return time.Time{}, errScheduleTooSoon
+	case at.Sub(now) > scheduleMaxAhead:
+		return time.Time{}, errScheduleTooFar
+	}
+	return at, nil
+}
+
+// scheduleHandler handles the /schedule command.
+// "/schedule 21:30 query" or "/schedule 45m query" plays the query, URL or playlist then, as if /play had
+// been sent at that time. Replying to an audio file schedules that file.
+func scheduleHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	spec, query, _ := strings.Cut(strings.TrimSpace(m.Args()), " ")
+	query = strings.TrimSpace(query)
+	if spec == "" || (query == "" && !m.IsReply()) {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "schedule_usage"), maxScheduledPlays))
+		return err
+	}
+
+	loc := chatLocation(chatID)
+	at, err := parseScheduleTime(spec, loc, time.Now())
+	switch {
+	case errors.Is(err, errScheduleTooSoon):
+		_, err = m.Reply(lang.GetString(langCode, "schedule_too_soon"))
+		return err
+	case errors.Is(err, errScheduleTooFar):
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "schedule_too_far"), int(scheduleMaxAhead.Hours()/24)))
+		return err
+	case err != nil:
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "schedule_bad_time"), html.EscapeString(spec)))
+		return err
+	}
+
+	count, err := db.Instance.CountScheduledPlays(ctx, chatID)
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "schedule_error"), html.EscapeString(err.Error())))
+		return err
+	}
+	if count >= maxScheduledPlays {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "schedule_full"), maxScheduledPlays))
+		return err
+	}
+
+	play := &db.ScheduledPlay{ChatID: chatID, MsgID: m.ID, UserID: m.SenderID(), Query: query, At: at}
+	if err := db.Instance.AddScheduledPlay(ctx, play); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "schedule_error"), html.EscapeString(err.Error())))
+		return err
+	}
+
+	audit(m, "schedule", "", fmt.Sprintf("%s %s", at.UTC().Format(time.RFC3339), truncate(query, 80)))
+	// The command is kept rather than cleaned up, since it is replayed when the play is due.
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "schedule_added"),
+		scheduledQuery(langCode, query), at.In(loc).Format(scheduleTimeFormat), html.EscapeString(loc.String()),
+		cache.SecToMin(int(time.Until(at).Seconds()))))
+	return err
+}
+
+// scheduledQuery returns what a scheduled play will play, escaped for display.
+func scheduledQuery(langCode, query string) string {
+	if query == "" {
+		return lang.GetString(langCode, "schedule_replied_file")
+	}
+	return html.EscapeString(truncate(query, 60))
+}
+
+// scheduledHandler handles the /scheduled command, which lists the chat's scheduled plays with a button to
+// cancel each.
+func scheduledHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	text, markup, err := buildScheduledList(chatID, langCode)
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "schedule_error"), html.EscapeString(err.Error())))
+		return err
+	}
+	_, err = m.Reply(text, &tg.SendOptions{ReplyMarkup: markup})
+	return err
+}
+
+// buildScheduledList renders the chat's scheduled plays along with their cancel buttons.
+func buildScheduledList(chatID int64, langCode string) (string, tg.ReplyMarkup, error) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	plays, err := db.Instance.GetScheduledPlays(ctx, chatID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	kb := tg.NewKeyboard()
+	if len(plays) == 0 {
+		return lang.GetString(langCode, "scheduled_empty"), kb.AddRow(core.CloseBtn).Build(), nil
+	}
+
+	loc := chatLocation(chatID)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "scheduled_header"), len(plays), maxScheduledPlays, html.EscapeString(loc.String())))
+	for i, play := range plays {
+		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "scheduled_entry"),
+			i+1, play.At.In(loc).Format(scheduleTimeFormat), scheduledQuery(langCode, play.Query)))
+		kb.AddRow(tg.Button.Data(fmt.Sprintf(lang.GetString(langCode, "scheduled_cancel_button"), i+1),
+			callbackData("sc", "", strconv.FormatInt(play.ID, 10))))
+	}
+	return sb.String(), kb.AddRow(core.CloseBtn).Build(), nil
+}
+
+// scheduleCancelCallback cancels a scheduled play from the /scheduled list and refreshes the list.
+func scheduleCancelCallback(c *callbackCtx) error {
+	id, _ := strconv.ParseInt(c.Arg(0), 10, 64)
+	chatID := c.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	play, err := db.Instance.TakeScheduledPlay(ctx, chatID, id)
+	switch {
+	case err != nil:
+		c.Answer(fmt.Sprintf(lang.GetString(c.LangCode, "schedule_error"), err.Error()), true)
+		return nil
+	case play == nil:
+		c.Answer(lang.GetString(c.LangCode, "scheduled_gone"), true)
+	default:
+		auditCB(c.CallbackQuery, "unschedule", "", truncate(play.Query, 80))
+		c.Answer(lang.GetString(c.LangCode, "scheduled_cancelled"), false)
+	}
+
+	text, markup, err := buildScheduledList(chatID, c.LangCode)
+	if err != nil {
+		return err
+	}
+	_, err = c.Edit(text, &tg.SendOptions{ReplyMarkup: markup})
+	return err
+}
+
+// timezoneHandler handles the /timezone command, which sets the time zone /schedule reads clock times in.
+func timezoneHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	arg := strings.TrimSpace(m.Args())
+	if arg == "" {
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "timezone_usage"), html.EscapeString(chatLocation(chatID).String())))
+		return err
+	}
+	loc, err := time.LoadLocation(arg)
+	if err != nil || arg == "Local" {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "timezone_invalid"), html.EscapeString(arg)))
+		return err
+	}
+	if err := db.Instance.SetTimezone(ctx, chatID, loc.String()); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "timezone_error"), html.EscapeString(err.Error())))
+		return err
+	}
+
+	audit(m, "timezone", "", loc.String())
+	_, err = replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "timezone_set"),
+		html.EscapeString(loc.String()), time.Now().In(loc).Format("15:04")), true)
+	return err
+}
+
+// startScheduler starts the loop that starts scheduled plays once they are due.
+func startScheduler(c *tg.Client) {
+	schedulerOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(schedulePoll)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				runDueScheduledPlays(c, now)
+			}
+		}()
+	})
+}
+
+// runDueScheduledPlays starts every scheduled play that is due. Each play is removed before it starts, so
+// it runs once even if starting it fails.
+func runDueScheduledPlays(c *tg.Client, now time.Time) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	plays, err := db.Instance.DueScheduledPlays(ctx, now)
+	if err != nil {
+		logger.Warn("[schedule] Failed to load due plays: %v", err)
+		return
+	}
+	for _, due := range plays {
+		play, err := db.Instance.TakeScheduledPlay(ctx, due.ChatID, due.ID)
+		if err != nil || play == nil {
+			continue
+		}
+		dispatch(workpool.High, guardTask("schedule", play.ChatID, func() error {
+			startScheduledPlay(c, play, now)
+			return nil
+		}))
+	}
+}
+
+// startScheduledPlay replays the request of a scheduled play as a /play sent now. Failures to join or start
+// the voice chat are reported by the playback itself; the chat is told here when the play cannot even be
+// attempted.
+func startScheduledPlay(c *tg.Client, play *db.ScheduledPlay, now time.Time) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, play.ChatID)
+	what := scheduledQuery(langCode, play.Query)
+
+	if late := now.Sub(play.At); late > scheduleGrace {
+		logger.Info("[schedule] Skipping play %d in %d, %s late", play.ID, play.ChatID, late.Round(time.Minute))
+		notifyScheduleFailure(c, play, fmt.Sprintf(lang.GetString(langCode, "schedule_missed"), what))
+		return
+	}
+
+	orig, err := c.GetMessageByID(play.ChatID, play.MsgID)
+	if err != nil || orig == nil {
+		notifyScheduleFailure(c, play, fmt.Sprintf(lang.GetString(langCode, "schedule_request_deleted"), what))
+		return
+	}
+	if err := playRequest(orig, play.Query, false, true); err != nil && err != tg.EndGroup {
+		logger.Warn("[schedule] Failed to start play %d in %d: %v", play.ID, play.ChatID, err)
+		notifyScheduleFailure(c, play, fmt.Sprintf(lang.GetString(langCode, "schedule_failed"), what, html.EscapeString(err.Error())))
+	}
+}
+
+// notifyScheduleFailure tells the chat of play that it did not start.
+func notifyScheduleFailure(c *tg.Client, play *db.ScheduledPlay, text string) {
+	if _, err := c.SendMessage(play.ChatID, text); err != nil {
+		logger.Debug("[schedule] Failed to notify %d: %v", play.ChatID, err)
+	}
+}
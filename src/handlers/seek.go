@@ -54,13 +54,13 @@ func seekHandler(m *telegram.NewMessage) error {
 		return nil
 	}
 
-	currDur, err := vc.Calls.PlayedTime(chatID)
+	currDur, err := vc.Calls.Elapsed(chatID)
 	if err != nil {
 		_, _ = m.Reply(lang.GetString(langCode, "seek_fetch_duration_error"))
 		return nil
 	}
 
-	toSeek := int(currDur) + seekTime
+	toSeek := currDur + seekTime
 	if toSeek >= playingSong.Duration {
 		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "seek_beyond_duration"), cache.SecToMin(playingSong.Duration)))
 		return nil
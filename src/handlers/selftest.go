@@ -0,0 +1,114 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/selftest"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// selfTestChecks returns the built-in checks plus one for the bot's session and one for each assistant.
+func selfTestChecks(c *tg.Client) []selftest.Check {
+	checks := selftest.Builtin()
+	checks = append(checks, selftest.Check{Name: "bot session", Run: func(context.Context) (selftest.Status, string) {
+		me, err := c.GetMe()
+		if err != nil {
+			return selftest.Fail, err.Error()
+		}
+		return selftest.OK, "@" + me.Username
+	}})
+
+	assistants := vc.Calls.Assistants()
+	if len(assistants) == 0 {
+		checks = append(checks, selftest.Check{Name: "assistants", Run: func(context.Context) (selftest.Status, string) {
+			return selftest.Fail, "no assistant is running"
+		}})
+	}
+	for _, a := range assistants {
+		checks = append(checks, selftest.Check{Name: "assistant " + a.Name, Run: func(context.Context) (selftest.Status, string) {
+			if err := vc.Calls.PingAssistant(a.Name); err != nil {
+				return selftest.Fail, err.Error()
+			}
+			return selftest.OK, "@" + a.Username
+		}})
+	}
+	return checks
+}
+
+// selfTestSummary returns the lang key describing the overall outcome.
+func selfTestSummary(results []selftest.Result) string {
+	worst := selftest.OK
+	for _, r := range results {
+		worst = max(worst, r.Status)
+	}
+	switch worst {
+	case selftest.OK:
+		return "selftest_passed"
+	case selftest.Warn:
+		return "selftest_warnings"
+	default:
+		return "selftest_failed"
+	}
+}
+
+// StartupSelfTest probes the bot's dependencies, logs the results and sends them to the logger group, or to the
+// owners when there is none. With SELFTEST_STRICT it returns an error if any check failed hard.
+func StartupSelfTest(c *tg.Client) error {
+	results := selftest.Run(context.Background(), selfTestChecks(c))
+	table := selftest.Table(results)
+	if selftest.Failed(results) {
+		logger.Error("[selftest] Some dependencies are not working:\n%s", table)
+	} else {
+		logger.Info("[selftest] Dependency check:\n%s", table)
+	}
+
+	text := fmt.Sprintf(lang.GetString("en", "selftest_report"),
+		lang.GetString("en", selfTestSummary(results)), html.EscapeString(table))
+	recipients := config.Get().OwnerIds
+	if id := config.Get().LoggerId; id != 0 {
+		recipients = []int64{id}
+	}
+	for _, id := range recipients {
+		if _, err := c.SendMessage(id, text); err != nil {
+			logger.Warn("[selftest] Failed to send the report to %d: %v", id, err)
+		}
+	}
+
+	if selftest.Failed(results) && config.Get().SelfTestStrict {
+		return errors.New("the startup self-test failed and SELFTEST_STRICT is set")
+	}
+	return nil
+}
+
+// selfTestHandler handles the /selftest command, which runs the startup self-test again.
+func selfTestHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	status, err := m.Reply(lang.GetString(langCode, "selftest_running"))
+	if err != nil {
+		return err
+	}
+
+	results := selftest.Run(requestCtx(m), selfTestChecks(m.Client))
+	_, err = status.Edit(fmt.Sprintf(lang.GetString(langCode, "selftest_report"),
+		lang.GetString(langCode, selfTestSummary(results)), html.EscapeString(selftest.Table(results))))
+	return err
+}
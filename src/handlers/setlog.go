@@ -0,0 +1,53 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// setLogHandler handles the /setlog command.
+// Without arguments it lists the level of every module; "/setlog <module> <level>" changes one until the next reload.
+func setLogHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	args := strings.Fields(m.Args())
+	if len(args) != 2 {
+		var b strings.Builder
+		for _, lv := range logging.Levels() {
+			b.WriteString(fmt.Sprintf("• <code>%s</code>: %s\n", lv[0], lv[1]))
+		}
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "setlog_usage"), b.String()))
+		return err
+	}
+
+	module := strings.ToLower(args[0])
+	level, err := logging.ParseLevel(args[1])
+	if err == nil {
+		err = logging.SetLevel(module, level)
+	}
+	if err != nil {
+		_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "setlog_error"), html.EscapeString(err.Error())))
+		return err
+	}
+
+	audit(m, "setlog", module, strings.ToLower(args[1]))
+	_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "setlog_done"), module, strings.ToLower(args[1])))
+	return err
+}
@@ -9,13 +9,20 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
+	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core"
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/dl"
 	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
 
 	"github.com/amarnathcjd/gogram/telegram"
 )
@@ -29,34 +36,22 @@ func settingsHandler(m *telegram.NewMessage) error {
 	defer cancel()
 
 	chatID := m.ChannelID()
-	admins, err := cache.GetAdmins(m.Client, chatID, false)
-	if err != nil {
-		return err
-	}
-
-	// Check if user is admin
-	var isAdmin bool
-	for _, admin := range admins {
-		if admin.User.ID == m.Sender.ID {
-			isAdmin = true
-			break
-		}
-	}
-	if !isAdmin {
-		return nil
-	}
 	langCode := db.Instance.GetLang(ctx, chatID)
-	// Get current settings
-	getPlayMode := db.Instance.GetPlayMode(ctx, chatID)
-	getAdminMode := db.Instance.GetAdminMode(ctx, chatID)
+	text, markup := settingsMenu(ctx, chatID, m.Chat.Title, langCode)
+	_, err := m.Reply(text, &telegram.SendOptions{ReplyMarkup: markup})
+	return err
+}
 
-	text := fmt.Sprintf(lang.GetString(langCode, "settings_header"),
-		m.Chat.Title, getPlayMode, getAdminMode)
+// settingsMenu renders the settings menu of chatID, titled title, with its current values.
+func settingsMenu(ctx context.Context, chatID int64, title, langCode string) (string, telegram.ReplyMarkup) {
+	playMode := db.Instance.GetPlayMode(ctx, chatID)
+	adminMode := db.Instance.GetAdminMode(ctx, chatID)
+	cleanMode := db.Instance.GetCleanMode(ctx, chatID)
+	searchPlatform := db.Instance.GetSearchPlatform(ctx, chatID)
 
-	_, err = m.Reply(text, &telegram.SendOptions{
-		ReplyMarkup: core.SettingsKeyboard(getPlayMode, getAdminMode),
-	})
-	return err
+	text := fmt.Sprintf(lang.GetString(langCode, "settings_header"),
+		title, playMode, adminMode) + cleanModeLine(langCode, cleanMode) + searchPlatformLine(langCode, searchPlatform)
+	return text, core.SettingsKeyboard(playMode, adminMode, cleanMode, searchPlatform)
 }
 
 func settingsCallbackHandler(c *telegram.CallbackQuery) error {
@@ -65,22 +60,11 @@ func settingsCallbackHandler(c *telegram.CallbackQuery) error {
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
 
-	// Check admin permissions
-	admins, err := cache.GetAdmins(c.Client, chatID, false)
-	if err != nil {
-		return err
-	}
-
-	var hasPerms bool
-	for _, admin := range admins {
-		if admin.User.ID == c.Sender.ID {
-			hasPerms = (admin.Rights != nil && admin.Rights.ManageCall) || admin.Status == telegram.Creator
-			break
-		}
-	}
-
-	if !hasPerms {
-		_, err := c.Answer(lang.GetString(langCode, "settings_no_permission"), &telegram.CallbackOptions{Alert: true})
+	// The settings button of the onboarding message opens the menu in a new message.
+	if c.DataString() == "settings_open" {
+		_, _ = c.Answer("")
+		text, markup := settingsMenu(ctx, chatID, getChatTitle(c.Client, chatID), langCode)
+		_, err := c.Client.SendMessage(chatID, text, &telegram.SendOptions{ReplyMarkup: markup})
 		return err
 	}
 
@@ -101,7 +85,7 @@ func settingsCallbackHandler(c *telegram.CallbackQuery) error {
 		cache.Everyone: true,
 	}
 
-	if !validValues[settingValue] {
+	if settingType != "clean" && settingType != "search" && !validValues[settingValue] {
 		_, _ = c.Answer(lang.GetString(langCode, "settings_update_invalid"), &telegram.CallbackOptions{Alert: true})
 		return nil
 	}
@@ -111,26 +95,37 @@ func settingsCallbackHandler(c *telegram.CallbackQuery) error {
 		_ = db.Instance.SetPlayMode(ctx, chatID, settingValue)
 	case "admin":
 		_ = db.Instance.SetAdminMode(ctx, chatID, settingValue)
+	case "clean":
+		seconds, err := strconv.Atoi(settingValue)
+		if err != nil || !slices.Contains(core.CleanModeOptions, seconds) {
+			_, _ = c.Answer(lang.GetString(langCode, "settings_update_invalid"), &telegram.CallbackOptions{Alert: true})
+			return nil
+		}
+		_ = db.Instance.SetCleanMode(ctx, chatID, seconds)
+	case "search":
+		platform := settingValue
+		if platform == core.SearchDefault {
+			platform = ""
+		} else if !slices.Contains(dl.SearchPlatforms, platform) {
+			_, _ = c.Answer(lang.GetString(langCode, "settings_update_invalid"), &telegram.CallbackOptions{Alert: true})
+			return nil
+		}
+		_ = db.Instance.SetSearchPlatform(ctx, chatID, platform)
 	default:
 		_, _ = c.Answer(lang.GetString(langCode, "settings_update_prompt"), &telegram.CallbackOptions{Alert: true})
 		return nil
 	}
 
-	// Get updated settings
-	getPlayMode := db.Instance.GetPlayMode(ctx, chatID)
-	getAdminMode := db.Instance.GetAdminMode(ctx, chatID)
+	auditCB(c, "settings", settingType, settingValue)
+
 	chat, err := c.GetChannel()
 	if err != nil {
 		logger.Warn("Failed to get chat: %v", err)
 		return nil
 	}
 
-	text := fmt.Sprintf(lang.GetString(langCode, "settings_header"),
-		chat.Title, getPlayMode, getAdminMode)
-
-	_, err = c.Edit(text, &telegram.SendOptions{
-		ReplyMarkup: core.SettingsKeyboard(getPlayMode, getAdminMode),
-	})
+	text, markup := settingsMenu(ctx, chatID, chat.Title, langCode)
+	_, err = c.Edit(text, &telegram.SendOptions{ReplyMarkup: markup})
 	if err != nil {
 		logger.Warn("Failed to edit message: %v", err)
 		return err
@@ -140,3 +135,223 @@ func settingsCallbackHandler(c *telegram.CallbackQuery) error {
 	_, _ = c.Edit(lang.GetString(langCode, "settings_updated"))
 	return nil
 }
+
+// cleanModeLine renders the clean mode line shown below the settings header.
+func cleanModeLine(langCode string, seconds int) string {
+	if seconds <= 0 {
+		return fmt.Sprintf(lang.GetString(langCode, "settings_clean_mode"), lang.GetString(langCode, "clean_mode_off"))
+	}
+	return fmt.Sprintf(lang.GetString(langCode, "settings_clean_mode"), fmt.Sprintf("%ds", seconds))
+}
+
+// searchPlatformLine renders the search platform line shown below the settings header.
+func searchPlatformLine(langCode, platform string) string {
+	name := lang.GetString(langCode, "settings_search_default")
+	if platform != "" {
+		name = cache.PlatformName(platform)
+	}
+	return fmt.Sprintf(lang.GetString(langCode, "settings_search_platform"), name)
+}
+
+// cleanModeHandler handles the /cleanmode command.
+// It takes "on" (the default delay), "off" or a delay in seconds and updates the chat's clean mode.
+func cleanModeHandler(m *telegram.NewMessage) error {
+	if m.IsPrivate() {
+		return nil
+	}
+
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	args := strings.ToLower(strings.TrimSpace(m.Args()))
+	if args == "" {
+		_, err := m.Reply(lang.GetString(langCode, "clean_mode_usage") + cleanModeLine(langCode, db.Instance.GetCleanMode(ctx, chatID)))
+		return err
+	}
+
+	seconds := 0
+	switch args {
+	case "off", "0":
+	case "on":
+		seconds = int(config.Get().AutoDeleteDelay)
+		if seconds <= 0 {
+			seconds = config.DefaultCleanDelay
+		}
+	default:
+		var err error
+		seconds, err = strconv.Atoi(strings.TrimSuffix(args, "s"))
+		if err != nil || seconds < 5 || seconds > 3600 {
+			_, err = replyTransient(m, lang.GetString(langCode, "clean_mode_invalid"), true)
+			return err
+		}
+	}
+
+	if err := db.Instance.SetCleanMode(ctx, chatID, seconds); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "clean_mode_error"), err.Error()))
+		return err
+	}
+
+	audit(m, "cleanmode", "", strconv.Itoa(seconds))
+	_, err := replyTransient(m, lang.GetString(langCode, "settings_updated")+cleanModeLine(langCode, seconds), true)
+	return err
+}
+
+// autoPauseHandler handles the /autopause command.
+// It takes "on" or "off" and sets whether playback pauses while nobody is in the voice chat.
+func autoPauseHandler(m *telegram.NewMessage) error {
+	return toggleSetting{
+		action: "autopause",
+		key:    "auto_pause",
+		get:    db.Instance.GetAutoPause,
+		set:    db.Instance.SetAutoPause,
+	}.handle(m)
+}
+
+// radio247Handler handles the /radio247 command.
+// "on" keeps the assistant in the voice chat indefinitely, optionally looping a playlist when the queue empties;
+// "off" restores the normal inactivity and auto-pause behaviour.
+func radio247Handler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	enabled, playlistID := db.Instance.GetRadio247(ctx, chatID)
+	state := lang.GetString(langCode, "radio247_off")
+	if enabled {
+		state = lang.GetString(langCode, "radio247_on")
+		if playlistID != "" {
+			state = fmt.Sprintf(lang.GetString(langCode, "radio247_on_playlist"), playlistID)
+		}
+	}
+
+	args := append(strings.Fields(m.Args()), "")
+	switch strings.ToLower(args[0]) {
+	case "off", "disable":
+		if err := db.Instance.SetRadio247(ctx, chatID, false, ""); err != nil {
+			_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "radio247_error"), err.Error()))
+			return err
+		}
+		vc.Calls.RadioChanged(chatID)
+		audit(m, "radio247", "", "off")
+		_, err := m.Reply(lang.GetString(langCode, "radio247_disabled"))
+		return err
+
+	case "on", "enable":
+		playlistID = ""
+		if args[1] != "" {
+			playlist, err := db.Instance.GetPlaylist(ctx, args[1])
+			if err != nil || len(playlist.Songs) == 0 {
+				_, err = m.Reply(lang.GetString(langCode, "playlist_not_found"))
+				return err
+			}
+			if playlist.UserID != m.SenderID() {
+				_, err = m.Reply(lang.GetString(langCode, "playlist_not_owner"))
+				return err
+			}
+			playlistID = playlist.ID
+		}
+
+		limit := config.Get().MaxRadioChats
+		if err := db.Instance.EnableRadio247(ctx, chatID, playlistID, limit); err != nil {
+			if errors.Is(err, db.ErrRadioLimit) {
+				_, err = m.Reply(fmt.Sprintf(lang.GetString(langCode, "radio247_limit_reached"), limit))
+				return err
+			}
+			_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "radio247_error"), err.Error()))
+			return err
+		}
+		vc.Calls.RadioChanged(chatID)
+		audit(m, "radio247", playlistID, "on")
+
+		key := "radio247_enabled"
+		if playlistID != "" {
+			key = "radio247_enabled_playlist"
+		}
+		_, err := m.Reply(lang.GetString(langCode, key))
+		return err
+
+	default:
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "radio247_usage"), state))
+		return err
+	}
+}
+
+// vcTitleHandler handles the /vctitle command.
+// It takes "on" or "off" and sets whether the voice chat title shows the current track.
+func vcTitleHandler(m *telegram.NewMessage) error {
+	return toggleSetting{
+		action: "vctitle",
+		key:    "vc_title",
+		get:    db.Instance.GetVCTitle,
+		set:    db.Instance.SetVCTitle,
+	}.handle(m)
+}
+
+// noDuplicatesHandler handles the /noduplicates command.
+// It takes "on" or "off" and sets whether tracks that are queued or were played recently are rejected.
+func noDuplicatesHandler(m *telegram.NewMessage) error {
+	return toggleSetting{
+		action:    "noduplicates",
+		key:       "no_duplicates",
+		get:       db.Instance.GetNoDuplicates,
+		set:       db.Instance.SetNoDuplicates,
+		usageArgs: func() []any { return []any{config.Get().DuplicateWindow} },
+	}.handle(m)
+}
+
+// queueNoticeHandler handles the /queuenotice command.
+// It takes "off", "minimal" or "detailed" and sets how the chat is told about tracks added to its queue.
+func queueNoticeHandler(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	mode := strings.ToLower(strings.TrimSpace(m.Args()))
+	switch mode {
+	case queueNoticeOff, queueNoticeMinimal, queueNoticeDetailed:
+	default:
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "queue_notice_usage"), db.Instance.GetQueueNotice(ctx, chatID)))
+		return err
+	}
+
+	if err := db.Instance.SetQueueNotice(ctx, chatID, mode); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "queue_notice_error"), err.Error()))
+		return err
+	}
+
+	audit(m, "queuenotice", "", mode)
+	_, err := replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "queue_notice_set"), mode), true)
+	return err
+}
+
+// keepQueueHandler handles the /keepqueue command.
+// It takes "on" or "off" and sets whether the queue is kept when an admin ends the voice chat during playback.
+func keepQueueHandler(m *telegram.NewMessage) error {
+	return toggleSetting{
+		action: "keepqueue",
+		key:    "keep_queue",
+		get:    db.Instance.GetKeepQueue,
+		set:    db.Instance.SetKeepQueue,
+	}.handle(m)
+}
+
+// announceHandler handles the /announce command.
+// It takes "on" or "off" and sets whether every track is announced by a short spoken clip before it plays.
+func announceHandler(m *telegram.NewMessage) error {
+	return toggleSetting{
+		action: "announce",
+		key:    "announce",
+		get:    db.Instance.GetAnnounce,
+		set:    db.Instance.SetAnnounce,
+		refuse: func(langCode string, enabled bool) string {
+			if enabled && !config.Get().TTS.Configured() {
+				return lang.GetString(langCode, "announce_unavailable")
+			}
+			return ""
+		},
+	}.handle(m)
+}
@@ -28,6 +28,10 @@ func skipHandler(m *telegram.NewMessage) error {
 		return nil
 	}
 
-	_ = vc.Calls.PlayNext(chatID)
+	if err := vc.Calls.PlayNext(chatID); err != nil {
+		if text, ok := playbackStateText(langCode, err); ok {
+			_, _ = m.Reply(text)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,62 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// maxSlowlogDetail caps the detail shown for each operation.
+const maxSlowlogDetail = 120
+
+// slowLogHandler handles the /slowlog command.
+// It lists the slowest commands, downloads and queries since startup.
+func slowLogHandler(m *telegram.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	ops := logging.Slowest()
+	if len(ops) == 0 {
+		_, err := m.Reply(lang.GetString(langCode, "slowlog_empty"))
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString(lang.GetString(langCode, "slowlog_header"))
+	for i, op := range ops {
+		detail := config.Redact(op.Detail)
+		if len(detail) > maxSlowlogDetail {
+			detail = detail[:maxSlowlogDetail] + "…"
+		}
+		b.WriteString(fmt.Sprintf("\n%d. <b>%s</b> %s %s — <code>%s</code>",
+			i+1,
+			op.Duration.Round(time.Millisecond),
+			html.EscapeString(op.Kind),
+			html.EscapeString(op.Name),
+			html.EscapeString(detail),
+		))
+		if op.RequestID != "" {
+			b.WriteString(fmt.Sprintf(" [%s]", op.RequestID))
+		}
+	}
+
+	_, err := m.Reply(b.String())
+	return err
+}
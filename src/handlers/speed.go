@@ -11,6 +11,7 @@ package handlers
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
@@ -36,27 +37,67 @@ func speedHandler(m *tg.NewMessage) error {
 		return err
 	}
 
-	args := m.Args()
+	args := strings.ToLower(strings.TrimSpace(m.Args()))
 	if args == "" {
-		_, _ = m.Reply(lang.GetString(langCode, "speed_usage"))
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "speed_current"), vc.Calls.Speed(chatID)) + "\n\n" + lang.GetString(langCode, "speed_usage"))
 		return nil
 	}
 
+	if keep, ok := strings.CutPrefix(args, "keep"); ok {
+		return speedKeep(m, chatID, langCode, strings.TrimSpace(keep))
+	}
+
 	speed, err := strconv.ParseFloat(args, 64)
 	if err != nil {
 		_, _ = m.Reply(lang.GetString(langCode, "speed_invalid_value"))
 		return nil
 	}
 
-	if speed < 0.5 || speed > 4.0 {
-		_, _ = m.Reply(lang.GetString(langCode, "speed_out_of_range"))
+	applied, err := vc.Calls.ChangeSpeed(chatID, speed)
+	if text, ok := playbackStateText(langCode, err); ok {
+		_, _ = m.Reply(text)
+		return nil
+	}
+	if err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "speed_error"), err.Error()))
+		return nil
+	}
+
+	text := fmt.Sprintf(lang.GetString(langCode, "speed_success"), applied)
+	if applied != speed {
+		text = fmt.Sprintf(lang.GetString(langCode, "speed_clamped"), speed, applied)
+	}
+	_, _ = m.Reply(text)
+	return nil
+}
+
+// speedKeep handles /speed keep [on|off], which controls whether the speed carries over to the next track.
+func speedKeep(m *tg.NewMessage, chatID int64, langCode, arg string) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	var keep bool
+	switch arg {
+	case "":
+		keep = !db.Instance.GetKeepSpeed(ctx, chatID)
+	case "on", "enable", "yes":
+		keep = true
+	case "off", "disable", "no":
+		keep = false
+	default:
+		_, _ = m.Reply(lang.GetString(langCode, "speed_usage"))
 		return nil
 	}
 
-	if err = vc.Calls.ChangeSpeed(chatID, speed); err != nil {
+	if err := db.Instance.SetKeepSpeed(ctx, chatID, keep); err != nil {
 		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "speed_error"), err.Error()))
 		return nil
 	}
-	_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "speed_success"), speed))
+
+	key := "speed_keep_off"
+	if keep {
+		key = "speed_keep_on"
+	}
+	_, _ = m.Reply(lang.GetString(langCode, key))
 	return nil
 }
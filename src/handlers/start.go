@@ -10,6 +10,7 @@ package handlers
 
 import (
 	"fmt"
+	"html"
 	"time"
 
 	"ashokshau/tgmusic/src/core"
@@ -48,13 +49,17 @@ func startHandler(m *telegram.NewMessage) error {
 		go func(chatID int64) {
 			ctx, cancel := db.Ctx()
 			defer cancel()
-			_ = db.Instance.AddUser(ctx, chatID)
+			if added, _ := db.Instance.AddUser(ctx, chatID); added && m.Sender != nil {
+				noteNewcomer(m.Client, userNewcomer(m.Sender))
+			}
 		}(chatID)
 	} else {
 		go func(chatID int64) {
 			ctx, cancel := db.Ctx()
 			defer cancel()
-			_ = db.Instance.AddChat(ctx, chatID)
+			if added, _ := db.Instance.AddChat(ctx, chatID); added {
+				noteNewcomer(m.Client, chatNewcomer(m.Client, chatID, nil))
+			}
 		}(chatID)
 	}
 
@@ -62,9 +67,17 @@ func startHandler(m *telegram.NewMessage) error {
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
 
-	response := fmt.Sprintf(lang.GetString(langCode, "start_text"), m.Sender.FirstName, bot.FirstName)
+	response := fmt.Sprintf(lang.GetString(langCode, "start_text"), senderName(m, langCode), bot.FirstName)
+	markup := core.AddMeMarkup(bot.Username)
+	if m.IsPrivate() {
+		if announcement := db.Instance.GetAnnouncement(ctx, bot.ID); announcement != nil && !db.Instance.IsAnnouncementDismissed(ctx, chatID, announcement.ID) {
+			response += fmt.Sprintf(lang.GetString(langCode, "announcement_block"), html.EscapeString(announcement.Text))
+			markup = core.AnnouncementMarkup(bot.Username, announcement.ID)
+		}
+	}
+
 	_, err := m.Reply(response, &telegram.SendOptions{
-		ReplyMarkup: core.AddMeMarkup(m.Client.Me().Username),
+		ReplyMarkup: markup,
 	})
 
 	return err
@@ -16,8 +16,13 @@ import (
 	"strings"
 	"time"
 
+	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/core/reaper"
+	"ashokshau/tgmusic/src/core/workspace"
 	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
 
 	"github.com/amarnathcjd/gogram/telegram"
 	"github.com/shirou/gopsutil/cpu"
@@ -65,6 +70,30 @@ func humanBytes(bytes uint64) string {
 	return fmt.Sprintf("%.2f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// downloadRateLine renders the current download throughput against the bandwidth cap in force.
+func downloadRateLine(langCode string) string {
+	throughput := humanBytes(uint64(dl.Throughput())) + "/s"
+	limit := dl.RateLimit()
+	if limit <= 0 {
+		return fmt.Sprintf(lang.GetString(langCode, "stats_download_rate_unlimited"), throughput)
+	}
+	key := "stats_download_rate"
+	if config.Get().LowBandwidthRate > 0 && config.Get().InLowBandwidthHours(time.Now()) {
+		key = "stats_download_rate_low"
+	}
+	return fmt.Sprintf(lang.GetString(langCode, key), throughput, humanBytes(uint64(limit))+"/s")
+}
+
+// tempUsageLine renders the space taken by the temporary files of running jobs against TEMP_SPACE_BUDGET.
+func tempUsageLine(langCode string) string {
+	used, jobs := workspace.Usage()
+	budget := config.Get().TempBudget
+	if budget <= 0 {
+		return fmt.Sprintf(lang.GetString(langCode, "stats_temp_unlimited"), humanBytes(uint64(used)), jobs)
+	}
+	return fmt.Sprintf(lang.GetString(langCode, "stats_temp"), humanBytes(uint64(used)), humanBytes(uint64(budget)), jobs)
+}
+
 // Reads memory limit if running inside Docker.
 func readContainerMemLimit() uint64 {
 	if data, err := os.ReadFile("/sys/fs/cgroup/memory/memory.limit_in_bytes"); err == nil {
@@ -199,6 +228,24 @@ func sysStatsHandler(msg *telegram.NewMessage) error {
 	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_gc_last"), info.LastGC))
 	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_gc_pause"), info.GCTotalPause))
 
+	sb.WriteString(lang.GetString(langCode, "stats_sessions_header"))
+	for _, size := range reaper.Default.Sizes() {
+		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_sessions_item"), size.Name, size.Live))
+	}
+	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_sessions_reaped"), reaper.Default.Reaped()))
+	if dispatcher != nil {
+		ds := dispatcher.Stats()
+		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_dispatcher"), ds.Running, ds.Workers, ds.Queued, ds.Dropped))
+	}
+	sb.WriteString(downloadRateLine(langCode))
+	sb.WriteString(tempUsageLine(langCode))
+
+	sb.WriteString(lang.GetString(langCode, "stats_play_header"))
+	vc.Calls.FlushPlayStats()
+	if err := writePlayStats(ctx, &sb, langCode, db.GlobalStatsID); err != nil {
+		sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_error"), err) + "\n")
+	}
+
 	sb.WriteString("\n" + lang.GetString(langCode, "stats_server_header"))
 	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_server_cpu"), info.SystemCPUUsage))
 	sb.WriteString(fmt.Sprintf(lang.GetString(langCode, "stats_server_ram"), info.SystemMemUsed, info.SystemMemTotal))
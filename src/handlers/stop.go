@@ -25,7 +25,7 @@ func stopHandler(m *telegram.NewMessage) error {
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
-	if !cache.ChatCache.IsActive(chatID) {
+	if !cache.ChatCache.IsActive(chatID) && !vc.Calls.IsIdle(chatID) && vc.Calls.WaitingPosition(chatID) == 0 {
 		_, _ = m.Reply(lang.GetString(langCode, "no_track_playing"))
 		return nil
 	}
@@ -35,6 +35,6 @@ func stopHandler(m *telegram.NewMessage) error {
 		return err
 	}
 
-	_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "stop_success"), m.Sender.FirstName))
+	_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, "stop_success"), senderName(m, langCode)))
 	return nil
 }
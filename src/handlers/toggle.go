@@ -0,0 +1,91 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// parseToggle reads an on/off argument. It reports false if arg is neither.
+func parseToggle(arg string) (enabled, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(arg)) {
+	case "on", "enable":
+		return true, true
+	case "off", "disable":
+		return false, true
+	}
+	return false, false
+}
+
+// toggleState returns the label shown for the current value of an on/off setting.
+func toggleState(langCode string, enabled bool) string {
+	if enabled {
+		return lang.GetString(langCode, "toggle_on")
+	}
+	return lang.GetString(langCode, "toggle_off")
+}
+
+// toggleSetting is an on/off chat setting changed by a command. Its replies use the lang keys
+// <key>_usage, <key>_error, <key>_enabled and <key>_disabled.
+type toggleSetting struct {
+	action string
+	key    string
+	get    func(ctx context.Context, chatID int64) bool
+	set    func(ctx context.Context, chatID int64, enabled bool) error
+	// usageArgs, if set, returns the arguments of the usage text that follow the current state.
+	usageArgs func() []any
+	// refuse, if set, returns a reply refusing the change, or an empty string to allow it.
+	refuse func(langCode string, enabled bool) string
+}
+
+// handle runs the setting's command: with "on" or "off" it changes the setting, and otherwise it shows the
+// current state and usage.
+func (s toggleSetting) handle(m *telegram.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	enabled, ok := parseToggle(m.Args())
+	if !ok {
+		args := []any{toggleState(langCode, s.get(ctx, chatID))}
+		if s.usageArgs != nil {
+			args = append(args, s.usageArgs()...)
+		}
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, s.key+"_usage"), args...))
+		return err
+	}
+
+	if s.refuse != nil {
+		if text := s.refuse(langCode, enabled); text != "" {
+			_, err := m.Reply(text)
+			return err
+		}
+	}
+	if err := s.set(ctx, chatID, enabled); err != nil {
+		_, _ = m.Reply(fmt.Sprintf(lang.GetString(langCode, s.key+"_error"), err.Error()))
+		return err
+	}
+
+	audit(m, s.action, "", strconv.FormatBool(enabled))
+	key := s.key + "_disabled"
+	if enabled {
+		key = s.key + "_enabled"
+	}
+	_, err := replyTransient(m, lang.GetString(langCode, key), true)
+	return err
+}
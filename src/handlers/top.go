@@ -0,0 +1,202 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// topLimit is how many chats or users each /top page lists.
+	topLimit = 10
+	// topPeriod is how far back /top looks.
+	topPeriod = 7 * 24 * time.Hour
+	// topButtonsPerRow is how many entry buttons share a keyboard row.
+	topButtonsPerRow = 5
+)
+
+// topPage is one of the rankings /top pages through.
+type topPage struct {
+	header string // header is the lang key of the page's title.
+	unit   string // unit is the lang key of an entry's total, formatted with the total.
+	chats  bool   // chats pages rank chats and get a stats button per entry; the others rank users.
+	load   func() ([]db.TopEntry, error)
+}
+
+// topPages are the pages of /top, in order.
+var topPages = []topPage{
+	{header: "top_chats_plays", unit: "top_unit_plays", chats: true, load: func() ([]db.TopEntry, error) {
+		return topChats(db.TopByPlays)
+	}},
+	{header: "top_chats_minutes", unit: "top_unit_minutes", chats: true, load: func() ([]db.TopEntry, error) {
+		entries, err := topChats(db.TopBySeconds)
+		for i := range entries {
+			entries[i].Total /= 60
+		}
+		return entries, err
+	}},
+	{header: "top_users_requests", unit: "top_unit_requests", load: func() ([]db.TopEntry, error) {
+		ctx, cancel := db.Ctx()
+		defer cancel()
+		return db.Instance.TopUsers(ctx, time.Now().Add(-topPeriod), topLimit)
+	}},
+}
+
+func init() {
+	registerCallback("tp", &callbackRoute{
+		Allow:  permitCallback(requireOwner),
+		Handle: topPageCallback,
+	})
+	registerCallback("tps", &callbackRoute{
+		Allow:  permitCallback(requireOwner),
+		Handle: topChatStatsCallback,
+	})
+}
+
+// topChats returns the chats with the highest total of field over topPeriod.
+func topChats(field string) ([]db.TopEntry, error) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return db.Instance.TopChats(ctx, field, time.Now().Add(-topPeriod), topLimit)
+}
+
+// topHandler handles the /top command, which ranks the busiest chats and users of the last week.
+func topHandler(m *tg.NewMessage) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, m.ChannelID())
+
+	vc.Calls.FlushPlayStats()
+	text, markup := buildTopPage(m.Client, langCode, 0)
+	_, err := m.Reply(text, &tg.SendOptions{ReplyMarkup: markup, LinkPreview: false})
+	return err
+}
+
+// topPageCallback switches the /top message to another page.
+func topPageCallback(c *callbackCtx) error {
+	page, _ := strconv.Atoi(c.Arg(0))
+	text, markup := buildTopPage(c.Client, c.LangCode, page)
+	_, err := c.Edit(text, &tg.SendOptions{ReplyMarkup: markup, LinkPreview: false})
+	return err
+}
+
+// topChatStatsCallback shows the /chatstats of a chat listed by /top, with a button back to the page.
+func topChatStatsCallback(c *callbackCtx) error {
+	chatID, err := strconv.ParseInt(c.Arg(0), 10, 64)
+	if err != nil {
+		c.Answer(lang.GetString(c.LangCode, "invalid_request"), true)
+		return nil
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(lang.GetString(c.LangCode, "chatstats_header"), html.EscapeString(getChatTitle(c.Client, chatID))))
+	sb.WriteString(strings.Repeat("-", 40) + "\n")
+	if err := writePlayStats(ctx, &sb, c.LangCode, chatID); err != nil {
+		c.Answer(fmt.Sprintf(lang.GetString(c.LangCode, "stats_error"), err), true)
+		return nil
+	}
+
+	kb := tg.NewKeyboard().AddRow(
+		tg.Button.Data(lang.GetString(c.LangCode, "top_back"), callbackData("tp", "", c.Arg(1))),
+		core.CloseBtn,
+	)
+	_, err = c.Edit(sb.String(), &tg.SendOptions{ReplyMarkup: kb.Build()})
+	return err
+}
+
+// buildTopPage renders one page of /top along with its keyboard.
+func buildTopPage(client *tg.Client, langCode string, page int) (string, tg.ReplyMarkup) {
+	page = max(0, min(page, len(topPages)-1))
+	p := topPages[page]
+
+	entries, err := p.load()
+	if err != nil {
+		return fmt.Sprintf(lang.GetString(langCode, "top_error"), html.EscapeString(err.Error())), tg.NewKeyboard().AddRow(core.CloseBtn).Build()
+	}
+
+	var b strings.Builder
+	b.WriteString(lang.GetString(langCode, p.header))
+	if len(entries) == 0 {
+		b.WriteString(lang.GetString(langCode, "top_empty"))
+	}
+	rank := 0
+	for i, e := range entries {
+		// Entries with the same total share a rank.
+		if i == 0 || e.Total != entries[i-1].Total {
+			rank = i + 1
+		}
+		name := topUserName(client, langCode, e.ID)
+		if p.chats {
+			name = topChatName(client, langCode, e.ID)
+		}
+		b.WriteString(fmt.Sprintf(lang.GetString(langCode, "top_entry"), rank, name, e.ID,
+			fmt.Sprintf(lang.GetString(langCode, p.unit), e.Total)))
+	}
+
+	kb := tg.NewKeyboard()
+	if p.chats {
+		var row []tg.KeyboardButton
+		for i, e := range entries {
+			row = append(row, tg.Button.Data(fmt.Sprintf("📊 %d", i+1),
+				callbackData("tps", "", strconv.FormatInt(e.ID, 10), strconv.Itoa(page))))
+			if len(row) == topButtonsPerRow {
+				kb.AddRow(row...)
+				row = nil
+			}
+		}
+		if len(row) > 0 {
+			kb.AddRow(row...)
+		}
+	}
+
+	var nav []tg.KeyboardButton
+	if page > 0 {
+		nav = append(nav, tg.Button.Data("« Prev", callbackData("tp", "", strconv.Itoa(page-1))))
+	}
+	nav = append(nav, tg.Button.Data(fmt.Sprintf("%d/%d", page+1, len(topPages)), callbackData("tp", "", strconv.Itoa(page))))
+	if page < len(topPages)-1 {
+		nav = append(nav, tg.Button.Data("Next »", callbackData("tp", "", strconv.Itoa(page+1))))
+	}
+	return b.String(), kb.AddRow(nav...).AddRow(core.CloseBtn).Build()
+}
+
+// topChatName returns the escaped title of chatID, or a placeholder if the bot can no longer see the chat.
+func topChatName(client *tg.Client, langCode string, chatID int64) string {
+	title := getChatTitle(client, chatID)
+	if title == strconv.FormatInt(chatID, 10) {
+		return lang.GetString(langCode, "top_chat_gone")
+	}
+	return "<b>" + html.EscapeString(title) + "</b>"
+}
+
+// topUserName returns a mention of userID, or a placeholder if the user cannot be looked up.
+func topUserName(client *tg.Client, langCode string, userID int64) string {
+	user, err := client.GetUser(userID)
+	if err != nil || user == nil {
+		return lang.GetString(langCode, "top_user_unknown")
+	}
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if name == "" {
+		name = strconv.FormatInt(userID, 10)
+	}
+	return fmt.Sprintf("<a href=\"tg://user?id=%d\">%s</a>", userID, html.EscapeString(name))
+}
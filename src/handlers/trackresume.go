@@ -0,0 +1,133 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+// resumeChoiceTimeout is how long the resume prompt waits before the track starts over by itself.
+const resumeChoiceTimeout = 30 * time.Second
+
+// resumeChoice is a track waiting for the chat to pick between resuming it and starting it over.
+type resumeChoice struct {
+	song     *cache.CachedTrack
+	offset   int
+	updater  *telegram.NewMessage
+	langCode string
+	timer    *time.Timer
+}
+
+var resumeChoices = struct {
+	sync.Mutex
+	pending map[int64]*resumeChoice
+}{pending: make(map[int64]*resumeChoice)}
+
+func init() {
+	registerCallback("rp", &callbackRoute{
+		Allow:  allowResumeChoice,
+		Handle: resumeChoiceCallback,
+	})
+}
+
+// allowResumeChoice lets the requester of the track pick, along with the chat's admins and authorized users.
+func allowResumeChoice(cb *telegram.CallbackQuery) string {
+	resumeChoices.Lock()
+	choice := resumeChoices.pending[cb.ChannelID()]
+	resumeChoices.Unlock()
+	if choice != nil && choice.song.UserID == cb.SenderID {
+		return ""
+	}
+	return permitCallback(requireAuth)(cb)
+}
+
+// takeResumeChoice removes and returns the chat's pending choice, or nil if it was already made. A choice
+// whose track is no longer playing, because the chat was stopped meanwhile, is dropped and nil returned.
+func takeResumeChoice(chatID int64) *resumeChoice {
+	resumeChoices.Lock()
+	defer resumeChoices.Unlock()
+	choice, ok := resumeChoices.pending[chatID]
+	if !ok {
+		return nil
+	}
+	delete(resumeChoices.pending, chatID)
+	choice.timer.Stop()
+	if cache.ChatCache.GetPlayingTrack(chatID) != choice.song {
+		return nil
+	}
+	return choice
+}
+
+// offerResume asks the chat whether song, which it stopped offset seconds in, should resume from there or
+// start over. The track already holds the chat's playback slot, so later requests queue behind it.
+func offerResume(updater *telegram.NewMessage, chatID int64, song *cache.CachedTrack, offset int, langCode string) error {
+	kb := telegram.NewKeyboard().AddRow(
+		telegram.Button.Data(fmt.Sprintf(lang.GetString(langCode, "track_resume_from"), cache.SecToMin(offset)), callbackData("rp", "", "resume")),
+		telegram.Button.Data(lang.GetString(langCode, "track_resume_over"), callbackData("rp", "", "over")),
+	)
+	if _, err := updater.Edit(fmt.Sprintf(lang.GetString(langCode, "track_resume_prompt"),
+		html.EscapeString(song.Name), cache.SecToMin(offset), cache.SecToMin(song.Duration)), &telegram.SendOptions{ReplyMarkup: kb.Build()}); err != nil {
+		// Without the prompt nobody can choose, so the track just starts over.
+		logger.Warn("[offerResume] Failed to show the resume prompt in %d: %v", chatID, err)
+		return startSingleTrack(updater, chatID, song, 0, langCode)
+	}
+
+	choice := &resumeChoice{song: song, offset: offset, updater: updater, langCode: langCode}
+	resumeChoices.Lock()
+	if prev, ok := resumeChoices.pending[chatID]; ok {
+		// The earlier prompt's timer would otherwise take this choice when it fires.
+		prev.timer.Stop()
+	}
+	choice.timer = time.AfterFunc(resumeChoiceTimeout, func() {
+		if choice := takeResumeChoice(chatID); choice != nil {
+			if err := startSingleTrack(choice.updater, chatID, choice.song, 0, choice.langCode); err != nil {
+				logger.Warn("[offerResume] Failed to start %d after the prompt timed out: %v", chatID, err)
+			}
+		}
+	})
+	resumeChoices.pending[chatID] = choice
+	resumeChoices.Unlock()
+	return nil
+}
+
+// resumeChoiceCallback handles the Resume and Start over buttons of the resume prompt.
+func resumeChoiceCallback(c *callbackCtx) error {
+	chatID := c.ChannelID()
+	choice := takeResumeChoice(chatID)
+	if choice == nil {
+		c.Answer(lang.GetString(c.LangCode, "callback_stale"), true)
+		return nil
+	}
+	offset := 0
+	if c.Arg(0) == "resume" {
+		offset = choice.offset
+	}
+	return startSingleTrack(choice.updater, chatID, choice.song, offset, choice.langCode)
+}
+
+// startSingleTrack streams song, already queued as the chat's current track, from offset seconds in and
+// turns updater into its now-playing message. The chat's resume point is used up either way.
+func startSingleTrack(updater *telegram.NewMessage, chatID int64, song *cache.CachedTrack, offset int, langCode string) error {
+	vc.Calls.ForgetResumePoint(chatID)
+	if err := vc.Calls.StartTrackAt(chatID, song, offset); err != nil {
+		_ = vc.Calls.Stop(chatID)
+		_, _ = updater.Edit(fmt.Sprintf(lang.GetString(langCode, "track_resume_failed"), html.EscapeString(err.Error())))
+		return err
+	}
+	return announceNowPlaying(updater, chatID, song, langCode)
+}
@@ -0,0 +1,185 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/lang"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// uploadAttempts is how many times a file is uploaded before giving up.
+	uploadAttempts = 4
+	// uploadBackoff is the wait before the first retry; it doubles on every retry after.
+	uploadBackoff = 2 * time.Second
+	// uploadMaxFloodWait is the longest flood wait an upload sits out instead of failing.
+	uploadMaxFloodWait = 2 * time.Minute
+	// uploadProgressSize is the size from which the upload progress is shown in the status message.
+	uploadProgressSize = 50 << 20
+	// uploadProgressInterval is how often, in seconds, the upload progress is updated.
+	uploadProgressInterval = 5
+	// uploadMaxSize is the largest file a bot may send.
+	uploadMaxSize = 2000 << 20
+)
+
+// uploadPartSizes are the part sizes tried in turn when Telegram rejects the parts of an upload.
+var uploadPartSizes = []int32{512 << 10, 256 << 10, 128 << 10}
+
+// replyFile sends file, a path or the raw bytes, as a reply to m. Transient failures are retried with backoff,
+// flood waits are sat out, and rejected parts are uploaded again in smaller parts. Files from uploadProgressSize
+// up report their progress in status, which is sent if it is nil. If the file cannot be sent, the user is told
+// its size and the probable cause, in status if there is one, and the error is returned.
+func replyFile(m *tg.NewMessage, langCode string, status *tg.NewMessage, file any, opts *tg.MediaOptions) (*tg.NewMessage, error) {
+	size := fileSize(file)
+	if size > uploadMaxSize {
+		return nil, reportUploadFailure(m, langCode, status, size, lang.GetString(langCode, "upload_cause_too_big"), nil)
+	}
+
+	var pm *tg.ProgressManager
+	ownStatus := false
+	if size >= uploadProgressSize {
+		if status == nil {
+			status, _ = m.Reply(lang.GetString(langCode, "upload_started"))
+			ownStatus = status != nil
+		}
+		if status != nil {
+			pm = tg.NewProgressManager(uploadProgressInterval).WithEdit(func(total, current int64) {
+				if total > 0 {
+					_, _ = status.Edit(fmt.Sprintf(lang.GetString(langCode, "upload_progress"),
+						current*100/total, humanBytes(uint64(current)), humanBytes(uint64(total))))
+				}
+			})
+		}
+	}
+
+	part, backoff := 0, uploadBackoff
+	var err error
+	for attempt := 1; attempt <= uploadAttempts; attempt++ {
+		var sent *tg.NewMessage
+		if sent, err = uploadOnce(m, file, opts, uploadPartSizes[part], pm); err == nil {
+			if ownStatus {
+				_, _ = status.Delete()
+			}
+			return sent, nil
+		}
+
+		wait := time.Duration(tg.GetFloodWait(err)) * time.Second
+		switch {
+		case wait > uploadMaxFloodWait:
+			cause := fmt.Sprintf(lang.GetString(langCode, "upload_cause_flood"), wait)
+			return nil, reportUploadFailure(m, langCode, status, size, cause, err)
+		case wait > 0:
+			// Sit out the flood wait and try again with the same parts.
+		case uploadPartsRejected(err) && part < len(uploadPartSizes)-1:
+			part++
+			wait = 0
+		case uploadRetryable(err):
+			wait, backoff = backoff, backoff*2
+		default:
+			return nil, reportUploadFailure(m, langCode, status, size, uploadFailureCause(langCode, err), err)
+		}
+
+		if attempt < uploadAttempts {
+			logger.Warn("[upload] Attempt %d of %d in %d failed, retrying in %s: %v", attempt, uploadAttempts, m.ChannelID(), wait, err)
+			if status != nil {
+				_, _ = status.Edit(fmt.Sprintf(lang.GetString(langCode, "upload_retrying"), attempt+1, uploadAttempts))
+			}
+			time.Sleep(wait)
+		}
+	}
+	return nil, reportUploadFailure(m, langCode, status, size, uploadFailureCause(langCode, err), err)
+}
+
+// uploadOnce uploads file in parts of partSize and sends it as a reply to m.
+func uploadOnce(m *tg.NewMessage, file any, opts *tg.MediaOptions, partSize int32, pm *tg.ProgressManager) (*tg.NewMessage, error) {
+	media, err := m.Client.UploadFile(file, &tg.UploadOptions{ChunkSize: partSize, FileName: opts.FileName, ProgressManager: pm})
+	if err != nil {
+		return nil, err
+	}
+	sendOpts := *opts
+	sendOpts.ReplyID = m.ID
+	return m.Client.SendMedia(m.ChannelID(), media, &sendOpts)
+}
+
+// fileSize returns the size of a file given as a path or as raw bytes, or 0 if it cannot be told.
+func fileSize(file any) int64 {
+	switch f := file.(type) {
+	case []byte:
+		return int64(len(f))
+	case string:
+		if info, err := os.Stat(f); err == nil {
+			return info.Size()
+		}
+	}
+	return 0
+}
+
+// uploadPartsRejected reports whether Telegram rejected the parts of an upload, which smaller parts may fix.
+func uploadPartsRejected(err error) bool {
+	return tg.MatchError(err, "FILE_PARTS_INVALID") || tg.MatchError(err, "FILE_PART_")
+}
+
+// uploadRetryable reports whether an upload failed for a reason that may pass by itself.
+func uploadRetryable(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, transient := range []string{"timeout", "timed out", "rpc_call_fail", "internal", "connection reset", "broken pipe"} {
+		if strings.Contains(msg, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// uploadFailureCause explains in the user's language why an upload probably failed.
+func uploadFailureCause(langCode string, err error) string {
+	switch {
+	case tg.MatchError(err, "TOO_BIG") || tg.MatchError(err, "TOO_LARGE"):
+		return lang.GetString(langCode, "upload_cause_too_big")
+	case tg.MatchError(err, "FORBIDDEN") || tg.MatchError(err, "CHAT_WRITE") || tg.MatchError(err, "CHAT_ADMIN_REQUIRED"):
+		return lang.GetString(langCode, "upload_cause_forbidden")
+	case uploadPartsRejected(err):
+		return lang.GetString(langCode, "upload_cause_parts")
+	case uploadRetryable(err):
+		return lang.GetString(langCode, "upload_cause_network")
+	}
+	return lang.GetString(langCode, "upload_cause_unknown")
+}
+
+// reportUploadFailure tells the user that a file of size could not be sent and why, and returns the error.
+func reportUploadFailure(m *tg.NewMessage, langCode string, status *tg.NewMessage, size int64, cause string, err error) error {
+	text := fmt.Sprintf(lang.GetString(langCode, "upload_failed"), humanBytes(uint64(size)), cause)
+	if err != nil {
+		text = userError(m, langCode, text, err)
+	} else {
+		err = errors.New("the file is too big to send")
+	}
+	if status != nil {
+		_, _ = status.Edit(text)
+	} else {
+		_, _ = m.Reply(text)
+	}
+	return err
+}
@@ -0,0 +1,227 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/core/stt"
+	"ashokshau/tgmusic/src/core/workspace"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	"github.com/amarnathcjd/gogram/telegram"
+)
+
+const (
+	// voiceSearchMaxDuration is the longest voice note, in seconds, that is transcribed.
+	voiceSearchMaxDuration = 60
+	// voiceSearchResults is how many search results the picker offers.
+	voiceSearchResults = 5
+	// voicePickTimeout is how long the picker waits for a choice.
+	voicePickTimeout = 2 * time.Minute
+)
+
+// voicePick is a voice search waiting for its requester to pick one of the results.
+type voicePick struct {
+	id       int64
+	chatID   int64
+	request  *telegram.NewMessage // request is the /play message, which the picked track is played for.
+	updater  *telegram.NewMessage // updater is the picker message.
+	results  []cache.MusicTrack
+	isVideo  bool
+	langCode string
+	timer    *time.Timer
+}
+
+var voicePicks = struct {
+	sync.Mutex
+	next    int64
+	pending map[int64]*voicePick
+}{pending: make(map[int64]*voicePick)}
+
+func init() {
+	registerCallback("vs", &callbackRoute{
+		Allow:  allowVoicePick,
+		Handle: voicePickCallback,
+	})
+}
+
+// voiceSearchable reports whether msg is a voice note that /play should transcribe and search for.
+func voiceSearchable(msg *telegram.NewMessage) bool {
+	cfg := config.Get()
+	if !cfg.Features.VoiceSearch || !cfg.Speech.Configured() {
+		return false
+	}
+	_, ok := voiceNote(msg)
+	return ok
+}
+
+// allowVoicePick lets whoever asked for the voice search pick a result, along with the chat's admins.
+func allowVoicePick(cb *telegram.CallbackQuery) string {
+	if args := callbackArgs(cb); len(args) > 0 {
+		id, _ := strconv.ParseInt(args[0], 10, 64)
+		voicePicks.Lock()
+		pick := voicePicks.pending[id]
+		voicePicks.Unlock()
+		if pick != nil && requesterID(pick.request) == cb.SenderID {
+			return ""
+		}
+	}
+	return permitCallback(requireAdmin)(cb)
+}
+
+// takeVoicePick removes and returns the pending pick with the given ID, or nil if it was already made or
+// has expired.
+func takeVoicePick(id int64) *voicePick {
+	voicePicks.Lock()
+	defer voicePicks.Unlock()
+	pick, ok := voicePicks.pending[id]
+	if !ok {
+		return nil
+	}
+	delete(voicePicks.pending, id)
+	if pick.timer != nil {
+		pick.timer.Stop()
+	}
+	return pick
+}
+
+// transcribeVoiceNote downloads the voice note msg and returns what is said in it.
+func transcribeVoiceNote(ctx context.Context, msg *telegram.NewMessage) (string, error) {
+	ws, err := workspace.New("voice")
+	if err != nil {
+		return "", err
+	}
+	defer ws.Close()
+
+	path, err := msg.Download(&telegram.DownloadOptions{FileName: ws.Path("voice.ogg"), Ctx: ctx})
+	if err != nil {
+		return "", err
+	}
+	return stt.Transcribe(ctx, path)
+}
+
+// handleVoiceSearch transcribes the voice note voice, searches for what was said and lets the requester of
+// m pick one of the results, so that a misheard query never starts the wrong track.
+func handleVoiceSearch(m, voice *telegram.NewMessage, chatID int64, isVideo bool, langCode string) error {
+	if dur, _ := voiceNote(voice); dur > voiceSearchMaxDuration {
+		_, err := replyTransient(m, fmt.Sprintf(lang.GetString(langCode, "voice_too_long"), voiceSearchMaxDuration), true)
+		return err
+	}
+
+	updater, err := m.Reply(lang.GetString(langCode, "voice_transcribing"))
+	if err != nil {
+		logger.Warn("failed to send message: %v", err)
+		return telegram.EndGroup
+	}
+	vc.Calls.RecordRequest(chatID, requesterID(m))
+
+	ctx, cancel := context.WithTimeout(requestCtx(m), time.Minute)
+	defer cancel()
+	text, err := transcribeVoiceNote(ctx, voice)
+	if err != nil {
+		key := "voice_failed"
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			key = "voice_timeout"
+		case errors.Is(err, workspace.ErrBudget):
+			key = "temp_space_full"
+		}
+		logger.Warn("[voicesearch] Failed to transcribe a voice note in %d: %v", chatID, err)
+		_, err = editTransient(updater, m, lang.GetString(langCode, key))
+		return err
+	}
+	if text == "" {
+		_, err = editTransient(updater, m, lang.GetString(langCode, "voice_not_understood"))
+		return err
+	}
+
+	heard := html.EscapeString(truncate(text, 200))
+	dbCtx, dbCancel := db.Ctx()
+	platform := db.Instance.GetSearchPlatform(dbCtx, chatID)
+	dbCancel()
+	searchCtx, searchCancel := context.WithTimeout(requestCtx(m), 15*time.Second)
+	defer searchCancel()
+	found, err := dl.Resolve(searchCtx, text, platform)
+	if err != nil || len(found.Results) == 0 {
+		_, err = editTransient(updater, m, fmt.Sprintf(lang.GetString(langCode, "voice_no_results"), heard))
+		return err
+	}
+	results := found.Results
+	if len(results) > voiceSearchResults {
+		results = results[:voiceSearchResults]
+	}
+
+	voicePicks.Lock()
+	voicePicks.next++
+	pick := &voicePick{
+		id: voicePicks.next, chatID: chatID, request: m, updater: updater,
+		results: results, isVideo: isVideo, langCode: langCode,
+	}
+	voicePicks.pending[pick.id] = pick
+	voicePicks.Unlock()
+
+	id := strconv.FormatInt(pick.id, 10)
+	kb := telegram.NewKeyboard()
+	for i, track := range results {
+		label := fmt.Sprintf("%d. %s (%s)", i+1, truncate(track.Name, 40), cache.SecToMin(track.Duration))
+		kb.AddRow(telegram.Button.Data(label, callbackData("vs", "", id, strconv.Itoa(i))))
+	}
+	kb.AddRow(telegram.Button.Data(lang.GetString(langCode, "voice_cancel"), callbackData("vs", "", id, "x")))
+	if _, err := updater.Edit(fmt.Sprintf(lang.GetString(langCode, "voice_pick"), heard), &telegram.SendOptions{ReplyMarkup: kb.Build()}); err != nil {
+		takeVoicePick(pick.id)
+		return err
+	}
+
+	voicePicks.Lock()
+	pick.timer = time.AfterFunc(voicePickTimeout, func() {
+		if pick := takeVoicePick(pick.id); pick != nil {
+			_, _ = editTransient(pick.updater, pick.request, lang.GetString(pick.langCode, "voice_pick_expired"))
+		}
+	})
+	voicePicks.Unlock()
+	return nil
+}
+
+// voicePickCallback handles the result and Cancel buttons of the voice search picker.
+func voicePickCallback(c *callbackCtx) error {
+	id, _ := strconv.ParseInt(c.Arg(0), 10, 64)
+	pick := takeVoicePick(id)
+	if pick == nil {
+		c.Answer(lang.GetString(c.LangCode, "callback_stale"), true)
+		return nil
+	}
+	if c.Arg(1) == "x" {
+		_, err := editTransient(pick.updater, pick.request, lang.GetString(pick.langCode, "voice_cancelled"))
+		return err
+	}
+
+	i, err := strconv.Atoi(c.Arg(1))
+	if err != nil || i < 0 || i >= len(pick.results) {
+		c.Answer(lang.GetString(c.LangCode, "callback_stale"), true)
+		return nil
+	}
+	track := pick.results[i]
+	c.Answer("", false)
+	if rejectDuplicate(pick.request, pick.updater, pick.chatID, track.Platform, track.ID, pick.langCode) {
+		return nil
+	}
+	return handleSingleTrack(pick.request, pick.updater, track, "", pick.chatID, pick.isVideo, pick.langCode)
+}
@@ -0,0 +1,176 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/shutdown"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// watchdogPingTimeout is how long the watchdog's API call may take before the check fails.
+const watchdogPingTimeout = 30 * time.Second
+
+// runningHandler is a handler call that has not returned yet.
+type runningHandler struct {
+	name  string
+	start time.Time
+}
+
+var (
+	lastUpdate    atomic.Int64 // lastUpdate is when the dispatcher last handed the bot an update, in Unix nanoseconds.
+	handlerSeq    atomic.Int64
+	runningCalls  sync.Map // runningCalls maps a call sequence number to its runningHandler.
+	longHandlers  sync.Map // longHandlers holds the names of handlers that may run for minutes, such as /broadcast.
+	watchdogStart sync.Once
+)
+
+// allowLongRun exempts the handler name from the watchdog's check for wedged handlers.
+func allowLongRun(name string) {
+	longHandlers.Store(name, true)
+}
+
+// trackHandler records that the dispatcher started a handler and returns the func that marks it finished.
+// Handlers exempted with allowLongRun only count as activity.
+func trackHandler(name string) func() {
+	now := time.Now()
+	lastUpdate.Store(now.UnixNano())
+	if _, ok := longHandlers.Load(name); ok {
+		return func() {}
+	}
+	id := handlerSeq.Add(1)
+	runningCalls.Store(id, runningHandler{name: name, start: now})
+	return func() { runningCalls.Delete(id) }
+}
+
+// oldestHandler returns the handler that has been running the longest, if any.
+func oldestHandler() (runningHandler, bool) {
+	var oldest runningHandler
+	found := false
+	runningCalls.Range(func(_, v any) bool {
+		h := v.(runningHandler)
+		if !found || h.start.Before(oldest.start) {
+			oldest, found = h, true
+		}
+		return true
+	})
+	return oldest, found
+}
+
+// startWatchdog starts checking that the bot can still reach Telegram and that its handlers are not wedged.
+func startWatchdog(c *tg.Client) {
+	watchdogStart.Do(func() {
+		lastUpdate.Store(time.Now().UnixNano())
+		go runWatchdog(c)
+	})
+}
+
+// runWatchdog checks the bot every WATCHDOG_INTERVAL seconds. After WATCHDOG_FAILURES failed checks in a row it
+// logs a goroutine dump, alerts the logger group and, with WATCHDOG_RESTART, restarts the bot.
+func runWatchdog(c *tg.Client) {
+	failures := int64(0)
+	for {
+		interval := time.Duration(config.Get().WatchdogInterval) * time.Second
+		if interval <= 0 {
+			failures = 0
+			time.Sleep(time.Minute)
+			continue
+		}
+		time.Sleep(interval)
+		if shutdown.Stopping() {
+			return
+		}
+
+		problems := watchdogCheck(c)
+		if len(problems) == 0 {
+			failures = 0
+			continue
+		}
+		failures++
+		logger.Warn("[watchdog] Check %d/%d failed: %s", failures, config.Get().WatchdogFailures, strings.Join(problems, "; "))
+		if failures < config.Get().WatchdogFailures {
+			continue
+		}
+
+		failures = 0
+		watchdogAlert(c, problems)
+		if config.Get().WatchdogRestart {
+			shutdown.Request("watchdog: " + strings.Join(problems, "; "))
+			return
+		}
+	}
+}
+
+// watchdogCheck returns what is wrong with the bot, or nothing if it is healthy.
+func watchdogCheck(c *tg.Client) []string {
+	var problems []string
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.UpdatesGetState()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("the API call failed: %v", err))
+		}
+	case <-time.After(watchdogPingTimeout):
+		problems = append(problems, fmt.Sprintf("the API call did not return within %s", watchdogPingTimeout))
+	}
+
+	if h, ok := oldestHandler(); ok {
+		if running := time.Since(h.start); running > time.Duration(config.Get().WatchdogStuck)*time.Second {
+			problems = append(problems, fmt.Sprintf("%s has been running for %s", h.name, running.Round(time.Second)))
+		}
+	}
+
+	if idle := time.Duration(config.Get().WatchdogIdleAfter) * time.Second; idle > 0 {
+		if since := time.Since(time.Unix(0, lastUpdate.Load())); since > idle {
+			problems = append(problems, fmt.Sprintf("no update has been handled for %s", since.Round(time.Second)))
+		}
+	}
+	return problems
+}
+
+// watchdogAlert logs a goroutine dump and tells the logger group what the watchdog found.
+func watchdogAlert(c *tg.Client, problems []string) {
+	var dump bytes.Buffer
+	_ = pprof.Lookup("goroutine").WriteTo(&dump, 2)
+	logger.Error("[watchdog] The bot looks wedged: %s\n%s", strings.Join(problems, "; "), dump.String())
+
+	chatID := config.Get().LoggerId
+	if chatID == 0 {
+		return
+	}
+	action := "Set WATCHDOG_RESTART=true to restart automatically."
+	if config.Get().WatchdogRestart {
+		action = "Restarting now."
+	}
+	text := fmt.Sprintf(
+		"<b>🐕 Watchdog alert</b>\n\n‣ %s\n‣ <b>Goroutines:</b> %d\n\nA goroutine dump has been written to the log. %s",
+		html.EscapeString(strings.Join(problems, "\n‣ ")),
+		runtime.NumGoroutine(),
+		action,
+	)
+	if _, err := c.SendMessage(chatID, text); err != nil {
+		logger.Warn("[watchdog] Failed to alert the logger group: %v", err)
+	}
+}
@@ -25,6 +25,10 @@ func handleVoiceChatMessage(m *telegram.NewMessage) error {
 	if m.Action == nil {
 		return nil
 	}
+	if botAdded(m) {
+		go sendOnboarding(m.Client, m.ChannelID())
+		return telegram.EndGroup
+	}
 
 	chatID := m.ChannelID()
 	ctx, cancel := db.Ctx()
@@ -92,7 +96,13 @@ func handleParticipant(pu *telegram.ParticipantUpdate) error {
 	go func(chatID int64) {
 		ctx, cancel := db.Ctx()
 		defer cancel()
-		_ = db.Instance.AddChat(ctx, chatID)
+		if added, _ := db.Instance.AddChat(ctx, chatID); added {
+			var addedBy *telegram.UserObj
+			if userID == client.Me().ID {
+				addedBy = pu.Actor
+			}
+			noteNewcomer(client, chatNewcomer(client, chatID, addedBy))
+		}
 	}(chatID)
 
 	if chat.Username != "" {
@@ -162,7 +172,7 @@ func handleLeaveOrKick(client *telegram.Client, chatID, userID, ubId int64) erro
 	logger.Debug("User %d left or was kicked from %d", userID, chatID)
 	if userID == ubId {
 		logger.Info("UB left chat %d. Stopping call...", chatID)
-		cache.ChatCache.ClearChat(chatID)
+		_ = vc.Calls.Stop(chatID)
 	}
 
 	if userID == client.Me().ID {
@@ -184,7 +194,7 @@ func handleBan(client *telegram.Client, chatID, userID, ubId int64) error {
 	langCode := db.Instance.GetLang(ctx, chatID)
 	if userID == ubId {
 		logger.Info("The bot (assistant) was banned in chat %d. Stopping any active calls and clearing cache...", chatID)
-		cache.ChatCache.ClearChat(chatID)
+		_ = vc.Calls.Stop(chatID)
 
 		_, err := client.SendMessage(chatID, fmt.Sprintf(lang.GetString(langCode, "watcher_assistant_banned"),
 			ubId,
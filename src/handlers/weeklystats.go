@@ -0,0 +1,159 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package handlers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// weeklyStatsPoll is how often the weekly recap job checks whether recaps are due.
+const weeklyStatsPoll = time.Hour
+
+var weeklyStatsOnce sync.Once
+
+// weeklyStatsHandler handles the /weeklystats command.
+// "/weeklystats on [pin]" posts a recap of the past week every Monday, pinning it if asked, and
+// "/weeklystats off" stops it.
+func weeklyStatsHandler(m *tg.NewMessage) error {
+	chatID := m.ChannelID()
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	args := append(strings.Fields(strings.ToLower(m.Args())), "", "")
+	enabled, ok := parseToggle(args[0])
+	pin := enabled && args[1] == "pin"
+	if !ok {
+		enabled, pin := db.Instance.GetWeeklyStats(ctx, chatID)
+		state := toggleState(langCode, enabled)
+		if pin {
+			state += lang.GetString(langCode, "weeklystats_pinned")
+		}
+		_, err := m.Reply(fmt.Sprintf(lang.GetString(langCode, "weeklystats_usage"), state))
+		return err
+	}
+
+	if err := db.Instance.SetWeeklyStats(ctx, chatID, enabled, pin); err != nil {
+		_, _ = m.Reply(userError(m, langCode, lang.GetString(langCode, "weeklystats_error"), err))
+		return err
+	}
+	audit(m, "weeklystats", "", fmt.Sprintf("enabled=%t pin=%t", enabled, pin))
+	key := "weeklystats_disabled"
+	switch {
+	case pin:
+		key = "weeklystats_enabled_pin"
+	case enabled:
+		key = "weeklystats_enabled"
+	}
+	_, err := replyTransient(m, lang.GetString(langCode, key), true)
+	return err
+}
+
+// startWeeklyStats starts posting the weekly recap to the chats that asked for it. Recaps are posted on
+// Mondays, UTC, and cover the seven days before.
+func startWeeklyStats(c *tg.Client) {
+	weeklyStatsOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(weeklyStatsPoll)
+			defer ticker.Stop()
+			for now := range ticker.C {
+				if now.UTC().Weekday() == time.Monday {
+					postWeeklyStats(c, now)
+				}
+			}
+		}()
+	})
+}
+
+// postWeeklyStats posts the recap of the week before now to every chat that gets it and has not had it yet.
+func postWeeklyStats(c *tg.Client, now time.Time) {
+	vc.Calls.FlushPlayStats()
+	until := db.Day(now)
+	since := until.AddDate(0, 0, -7)
+	year, week := since.ISOWeek()
+	label := fmt.Sprintf("%d-W%02d", year, week)
+
+	ctx, cancel := db.Ctx()
+	chats, err := db.Instance.WeeklyStatsChats(ctx)
+	cancel()
+	if err != nil {
+		logger.Warn("[weeklystats] Failed to load the chats: %v", err)
+		return
+	}
+	for _, chatID := range chats {
+		postWeeklyRecap(c, chatID, label, since, until)
+	}
+}
+
+// postWeeklyRecap posts the recap of the week from since to until in a chat, unless it was posted already.
+// A chat that played nothing that week gets no recap.
+func postWeeklyRecap(c *tg.Client, chatID int64, week string, since, until time.Time) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	claimed, err := db.Instance.ClaimWeeklyStats(ctx, chatID, week)
+	if err != nil || !claimed {
+		if err != nil {
+			logger.Warn("[weeklystats] Failed to claim the %s recap of %d: %v", week, chatID, err)
+		}
+		return
+	}
+	recap, err := db.Instance.GetWeeklyRecap(ctx, chatID, since, until)
+	if err != nil {
+		logger.Warn("[weeklystats] Failed to build the %s recap of %d: %v", week, chatID, err)
+		return
+	}
+	if recap.Plays == 0 {
+		return
+	}
+
+	langCode := db.Instance.GetLang(ctx, chatID)
+	msg, err := c.SendMessage(chatID, buildWeeklyRecap(c, recap, langCode, since, until))
+	if err != nil {
+		logger.Warn("[weeklystats] Failed to post the %s recap in %d: %v", week, chatID, err)
+		return
+	}
+	if _, pin := db.Instance.GetWeeklyStats(ctx, chatID); pin {
+		// Losing the right to pin is not worth failing over; the recap is posted either way.
+		if _, err := c.PinMessage(chatID, msg.ID, &tg.PinOptions{Silent: true}); err != nil {
+			logger.Info("[weeklystats] Failed to pin the %s recap in %d: %v", week, chatID, err)
+		}
+	}
+}
+
+// buildWeeklyRecap renders a chat's recap of the week from since to until.
+func buildWeeklyRecap(c *tg.Client, recap *db.WeeklyRecap, langCode string, since, until time.Time) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(lang.GetString(langCode, "weeklystats_header"),
+		since.Format("Jan 2"), until.AddDate(0, 0, -1).Format("Jan 2")))
+	b.WriteString(fmt.Sprintf(lang.GetString(langCode, "weeklystats_plays"), recap.Plays))
+	b.WriteString(fmt.Sprintf(lang.GetString(langCode, "weeklystats_time"), (time.Duration(recap.Seconds) * time.Second).String()))
+	if recap.TopUserID != 0 {
+		b.WriteString(fmt.Sprintf(lang.GetString(langCode, "weeklystats_top_user"),
+			topUserName(c, langCode, recap.TopUserID), recap.TopRequests))
+	}
+	if recap.TopTrack != "" {
+		b.WriteString(fmt.Sprintf(lang.GetString(langCode, "weeklystats_top_track"),
+			html.EscapeString(recap.TopTrack), recap.TopTrackPlays))
+	}
+	if !recap.BusiestDay.IsZero() {
+		b.WriteString(fmt.Sprintf(lang.GetString(langCode, "weeklystats_busiest_day"),
+			recap.BusiestDay.Format("Monday"), recap.BusiestPlays))
+	}
+	return b.String()
+}
@@ -10,10 +10,14 @@ package pkg
 
 import (
 	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/core/workspace"
 	"ashokshau/tgmusic/src/handlers"
 	"ashokshau/tgmusic/src/vc"
 	"context"
+	"fmt"
 
 	tg "github.com/amarnathcjd/gogram/telegram"
 )
@@ -23,17 +27,34 @@ func Init(client *tg.Client) error {
 		return err
 	}
 
+	if err := dl.MigrateLayout(); err != nil {
+		return fmt.Errorf("failed to arrange the downloads directory: %w", err)
+	}
+	workspace.StartSweeper()
+
+	cache.ChatCache.SetMaxLength(int(config.Get().MaxQueueLength))
+	config.OnReload(func(c *config.BotConfig) {
+		cache.ChatCache.SetMaxLength(int(c.MaxQueueLength))
+	})
+
 	// Then start the voice call clients
-	for _, session := range config.Conf.SessionStrings {
-		_, err := vc.Calls.StartClient(config.Conf.ApiId, config.Conf.ApiHash, session)
+	for _, session := range config.Get().SessionStrings {
+		_, err := vc.Calls.StartClient(config.Get().ApiId, config.Get().ApiHash, session)
 		if err != nil {
 			return err
 		}
 	}
 
+	if err := handlers.StartupSelfTest(client); err != nil {
+		return err
+	}
+
 	// Register handlers and load modules
 	vc.Calls.RegisterHandlers(client)
-	handlers.LoadModules(client)
+	if err := handlers.LoadModules(client); err != nil {
+		return err
+	}
+	go handlers.ResumeInterrupted(client)
 
 	return nil
 }
@@ -10,75 +10,236 @@ package lang
 
 import (
 	"encoding/json"
+	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 )
 
+// Fallback is the language used for keys a locale does not translate. Every key must exist in it.
+const Fallback = "en"
+
 var translations = make(map[string]map[string]string)
 
-func LoadTranslations() error {
-	execPath, err := os.Executable()
-	if err != nil {
-		return err
+// warned remembers the missing keys that have been logged, so each is logged once.
+var warned sync.Map
+
+// LoadTranslations loads the locale files built into the binary from embedded, which holds one <code>.json
+// file per language. A locales directory next to the executable or in the working directory, if there is one,
+// overrides the built-in files, so translations can be edited without rebuilding.
+func LoadTranslations(embedded fs.FS) error {
+	if err := loadDir(embedded); err != nil {
+		return fmt.Errorf("failed to load the built-in translations: %w", err)
 	}
-	execDir := filepath.Dir(execPath)
 
-	localePath := filepath.Join(execDir, "locales")
-	if _, err := os.Stat(localePath); os.IsNotExist(err) {
-		cwd, err := os.Getwd()
-		if err != nil {
+	if dir := localeDir(); dir != "" {
+		if err := loadDir(os.DirFS(dir)); err != nil {
+			log.Printf("Failed to load translations from %s: %v", dir, err)
 			return err
 		}
-		localePath = filepath.Join(cwd, "locales")
 	}
 
-	err = filepath.Walk(localePath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+	if _, ok := translations[Fallback]; !ok {
+		return fmt.Errorf("the %s locale is missing", Fallback)
+	}
+	for _, code := range GetAvailableLangs() {
+		if missing := MissingKeys(code); len(missing) > 0 {
+			log.Printf("Language %s lacks %d of %d keys; %s is used for them.", code, len(missing), len(translations[Fallback]), Fallback)
 		}
-		if !info.IsDir() && strings.HasSuffix(info.Name(), ".json") {
-			langCode := strings.TrimSuffix(info.Name(), ".json")
-			file, err := os.ReadFile(path)
-			if err != nil {
-				return err
-			}
-			var langMap map[string]string
-			if err := json.Unmarshal(file, &langMap); err != nil {
-				return err
-			}
-			translations[langCode] = langMap
-			log.Printf("Loaded language: %s", langCode)
+	}
+
+	log.Printf("Loaded %d languages", len(translations))
+	return nil
+}
+
+// localeDir returns the locales directory on disk, or an empty string if there is none.
+func localeDir() string {
+	var dirs []string
+	if execPath, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Join(filepath.Dir(execPath), "locales"))
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, filepath.Join(cwd, "locales"))
+	}
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
 		}
-		return nil
-	})
+	}
+	return ""
+}
 
+// loadDir loads every <code>.json file at the root of fsys, replacing the keys it defines.
+func loadDir(fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*.json")
 	if err != nil {
-		log.Printf("Failed to load translations: %v", err)
 		return err
 	}
+	for _, name := range files {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		var langMap map[string]string
+		if err := json.Unmarshal(data, &langMap); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
 
-	log.Printf("Loaded %d languages", len(translations))
+		langCode := strings.TrimSuffix(path.Base(name), ".json")
+		if translations[langCode] == nil {
+			translations[langCode] = make(map[string]string, len(langMap))
+		}
+		for k, v := range langMap {
+			translations[langCode][k] = v
+		}
+	}
 	return nil
 }
 
+// lookup returns the translation of key in langCode, if it has one.
+func lookup(langCode, key string) (string, bool) {
+	val, ok := translations[langCode][key]
+	return val, ok
+}
+
+// warnMissing logs once that langCode has no translation for key.
+func warnMissing(langCode, key string) {
+	if _, seen := warned.LoadOrStore(langCode+"\x00"+key, struct{}{}); seen {
+		return
+	}
+	if langCode == Fallback {
+		log.Printf("Unknown translation key %q.", key)
+	} else {
+		log.Printf("Missing %s translation for %q; using %s.", langCode, key, Fallback)
+	}
+}
+
+// GetString returns the translation of key in langCode, falling back to English. A key missing from both is
+// returned as is. Missing keys are logged once.
 func GetString(langCode, key string) string {
-	if lang, ok := translations[langCode]; ok {
-		if val, ok := lang[key]; ok {
-			return val
-		}
+	if val, ok := lookup(langCode, key); ok {
+		return val
 	}
-	// Fallback to English
-	if lang, ok := translations["en"]; ok {
-		if val, ok := lang[key]; ok {
-			return val
+	if langCode != Fallback {
+		if _, known := translations[langCode]; known {
+			warnMissing(langCode, key)
 		}
 	}
+	if val, ok := lookup(Fallback, key); ok {
+		return val
+	}
+	warnMissing(Fallback, key)
 	return key
 }
 
+// Format returns the translation of key in langCode with args filled into its verbs.
+func Format(langCode, key string, args ...any) string {
+	return fmt.Sprintf(GetString(langCode, key), args...)
+}
+
+// Plural returns the translation of key in langCode for the count n, with args filled into its verbs.
+// A locale may define a form for each plural category of its language as key_zero, key_one, key_two,
+// key_few and key_many; key itself is the "other" form and is used when the category has no form of its own.
+func Plural(langCode, key string, n int, args ...any) string {
+	form := key + "_" + pluralCategory(langCode, n)
+	for _, code := range []string{langCode, Fallback} {
+		if val, ok := lookup(code, form); ok {
+			return fmt.Sprintf(val, args...)
+		}
+		if val, ok := lookup(code, key); ok {
+			return fmt.Sprintf(val, args...)
+		}
+	}
+	return Format(langCode, key, args...)
+}
+
+// pluralCategory returns the CLDR plural category of the integer n in langCode.
+func pluralCategory(langCode string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch base, _, _ := strings.Cut(langCode, "-"); base {
+	case "ja", "ko", "zh", "id":
+		return "other"
+	case "hi", "bn", "gu", "mr", "fa", "fr", "pt":
+		if n <= 1 {
+			return "one"
+		}
+	case "ru":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "ar":
+		switch {
+		case n == 0:
+			return "zero"
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case n%100 >= 3 && n%100 <= 10:
+			return "few"
+		case n%100 >= 11:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
+
+// pluralSuffixes are the suffixes of the plural forms that Plural looks up.
+var pluralSuffixes = []string{"_zero", "_one", "_two", "_few", "_many"}
+
+// MissingKeys returns the keys of the English locale that langCode does not translate, in order.
+// A plural form is not missing if the locale translates the key it belongs to.
+func MissingKeys(langCode string) []string {
+	var missing []string
+	for key := range translations[Fallback] {
+		if _, ok := translations[langCode][key]; ok {
+			continue
+		}
+		if base, ok := pluralBase(key); ok {
+			if _, ok := translations[langCode][base]; ok {
+				continue
+			}
+		}
+		missing = append(missing, key)
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// pluralBase returns the key a plural form belongs to, if key is a plural form of a key in the English locale.
+func pluralBase(key string) (string, bool) {
+	for _, suffix := range pluralSuffixes {
+		if base, ok := strings.CutSuffix(key, suffix); ok {
+			if _, known := translations[Fallback][base]; known {
+				return base, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Has reports whether a locale for langCode is loaded.
+func Has(langCode string) bool {
+	_, ok := translations[langCode]
+	return ok
+}
+
 func GetAvailableLangs() []string {
 	langs := make([]string, 0, len(translations))
 	for k := range translations {
@@ -0,0 +1,147 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package lang
+
+import (
+	"io"
+	"log"
+	"maps"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// load loads the repository's locale files.
+func load(t *testing.T) {
+	t.Helper()
+	if len(translations) > 0 {
+		return
+	}
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	if err := loadDir(os.DirFS("../../locales")); err != nil {
+		t.Fatalf("loading the locales: %v", err)
+	}
+	if !Has(Fallback) {
+		t.Fatalf("the %s locale is missing", Fallback)
+	}
+}
+
+// verbRe matches fmt verbs, with their argument index, flags, width and precision.
+var verbRe = regexp.MustCompile(`%(?:\[([0-9]+)\])?[-+# 0]*[0-9]*(?:\.[0-9]+)?([a-zA-Z%])`)
+
+// verbs maps each argument s formats to its verb, so that translations may reorder arguments with %[n]v.
+func verbs(s string) map[int]string {
+	out := make(map[int]string)
+	next := 1
+	for _, m := range verbRe.FindAllStringSubmatch(s, -1) {
+		if m[2] == "%" {
+			continue
+		}
+		if m[1] != "" {
+			next, _ = strconv.Atoi(m[1])
+		}
+		out[next] = m[2]
+		next++
+	}
+	return out
+}
+
+func TestEveryKeyResolves(t *testing.T) {
+	load(t)
+	for _, code := range GetAvailableLangs() {
+		for key, en := range translations[Fallback] {
+			got := GetString(code, key)
+			if got == "" || (got == key && en != key) {
+				t.Errorf("%s: %q does not resolve", code, key)
+			}
+			if _, ok := lookup(code, key); !ok && got != en {
+				if base, plural := pluralBase(key); !plural || got != GetString(code, base) {
+					t.Errorf("%s: missing %q does not fall back to %s", code, key, Fallback)
+				}
+			}
+		}
+	}
+}
+
+func TestTranslationsKeepVerbs(t *testing.T) {
+	load(t)
+	for _, code := range GetAvailableLangs() {
+		if code == Fallback {
+			continue
+		}
+		for key, val := range translations[code] {
+			en, ok := translations[Fallback][key]
+			if !ok {
+				if _, plural := pluralBase(key); !plural {
+					t.Errorf("%s: %q is not an %s key", code, key, Fallback)
+				}
+				continue
+			}
+			if want, got := verbs(en), verbs(val); !maps.Equal(want, got) {
+				t.Errorf("%s: %q has verbs %v, %s has %v", code, key, got, Fallback, want)
+			}
+		}
+	}
+}
+
+func TestEveryLocaleNamesItself(t *testing.T) {
+	load(t)
+	for _, code := range GetAvailableLangs() {
+		if GetLangDisplayName(code) == "Unknown" {
+			t.Errorf("%s has no lang_name", code)
+		}
+	}
+}
+
+func TestUnknownKeyFallsBackToItself(t *testing.T) {
+	load(t)
+	if got := GetString("hi", "no_such_key"); got != "no_such_key" {
+		t.Fatalf("GetString = %q, want the key", got)
+	}
+	if got := GetString("xx", "lang_name"); got != translations[Fallback]["lang_name"] {
+		t.Fatalf("unknown locale: GetString = %q, want the %s text", got, Fallback)
+	}
+}
+
+func TestPlural(t *testing.T) {
+	saved := translations
+	t.Cleanup(func() { translations = saved })
+	translations = map[string]map[string]string{
+		"en": {"tracks": "%d tracks", "tracks_one": "%d track"},
+		"ru": {"tracks": "%d трека", "tracks_many": "%d треков"},
+		"ja": {"tracks": "%d曲"},
+	}
+
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 1, "1 track"},
+		{"en", 0, "0 tracks"},
+		{"en", 5, "5 tracks"},
+		{"ru", 5, "5 треков"},
+		{"ru", 11, "11 треков"},
+		{"ru", 21, "21 трека"},
+		{"ru", 3, "3 трека"},
+		{"ja", 1, "1曲"},
+		{"xx", 1, "1 track"},
+	}
+	for _, tt := range tests {
+		if got := Plural(tt.lang, "tracks", tt.n, tt.n); got != tt.want {
+			t.Errorf("Plural(%s, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+	if !strings.Contains(Plural("en", "missing", 1), "missing") {
+		t.Error("Plural of an unknown key does not fall back to the key")
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/shutdown"
+	"ashokshau/tgmusic/src/core/tts"
+	"ashokshau/tgmusic/src/lang"
+)
+
+const (
+	// announceTimeout bounds generating an announcement; a slow backend skips it rather than delay the track.
+	announceTimeout = 15 * time.Second
+	// announceMaxLength bounds how long the player waits for an announcement to finish.
+	announceMaxLength = 30 * time.Second
+)
+
+// announceState holds the chats that are generating or playing an announcement.
+type announceState struct {
+	mu     sync.Mutex
+	active map[int64]*announcement
+}
+
+// announcement is an announcement in progress. Cancelling it ends the wait for it, whether the clip is still
+// being generated or already playing.
+type announcement struct {
+	cancel  context.CancelFunc
+	playing bool // playing is set once the clip was handed to the call, so that its stream end can be told apart.
+}
+
+// announce plays a spoken "now playing" announcement of song in chatID and returns once it has finished. It
+// runs between tracks while the chat is transitioning, so the announcement is neither counted as play time nor
+// recorded in the history. Announcements the chat did not switch on, and any failure, are skipped silently.
+// The caller must hold the chat's playback lock; work that cannot wait for the announcement, such as /stop or
+// moving the chat to another assistant, cuts it short with interruptAnnouncement.
+func (c *TelegramCalls) announce(chatID int64, song *cache.CachedTrack, langCode string) {
+	if !config.Get().TTS.Configured() {
+		return
+	}
+	if state, _ := c.State(chatID); state != StateTransitioning {
+		return
+	}
+	dbCtx, dbCancel := db.Ctx()
+	enabled := db.Instance.GetAnnounce(dbCtx, chatID)
+	dbCancel()
+	if !enabled {
+		return
+	}
+
+	text := fmt.Sprintf(lang.GetString(langCode, "announce_text"), song.Name)
+	if song.User != "" {
+		text = fmt.Sprintf(lang.GetString(langCode, "announce_text_requester"), song.Name, song.User)
+	}
+	ctx, cancel := context.WithCancel(shutdown.Context())
+	a := &announcement{cancel: cancel}
+	c.announcing.mu.Lock()
+	c.announcing.active[chatID] = a
+	c.announcing.mu.Unlock()
+	defer func() {
+		c.announcing.mu.Lock()
+		if c.announcing.active[chatID] == a {
+			delete(c.announcing.active, chatID)
+		}
+		c.announcing.mu.Unlock()
+		cancel()
+	}()
+
+	speakCtx, speakCancel := context.WithTimeout(ctx, announceTimeout)
+	clip, err := tts.Speak(speakCtx, text)
+	speakCancel()
+	if err != nil {
+		logger.Debug("[announce] Skipping the announcement in %d: %v", chatID, err)
+		return
+	}
+	_, call, err := c.assistantFor(chatID)
+	if err != nil || ctx.Err() != nil {
+		return
+	}
+
+	c.announcing.mu.Lock()
+	a.playing = true
+	c.announcing.mu.Unlock()
+	if err := call.Play(chatID, getMediaDescription(clip, false, "", 0)); err != nil {
+		logger.Debug("[announce] Failed to play the announcement in %d: %v", chatID, err)
+		return
+	}
+	wait := announceMaxLength
+	if d := time.Duration(cache.GetFileDuration(clip)+3) * time.Second; d < wait {
+		wait = d
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(wait):
+		logger.Debug("[announce] The announcement in %d did not report its end; moving on.", chatID)
+	}
+}
+
+// interruptAnnouncement cuts short the announcement in chatID, if any, so that the track it holds up starts
+// and the playback lock is released without waiting for the clip to be generated or to finish.
+func (c *TelegramCalls) interruptAnnouncement(chatID int64) {
+	c.announcing.mu.Lock()
+	defer c.announcing.mu.Unlock()
+	if a, ok := c.announcing.active[chatID]; ok {
+		a.cancel()
+		delete(c.announcing.active, chatID)
+	}
+}
+
+// announcementEnded reports whether a stream that ended in chatID was an announcement, and if so wakes the
+// track waiting for it.
+func (c *TelegramCalls) announcementEnded(chatID int64) bool {
+	c.announcing.mu.Lock()
+	defer c.announcing.mu.Unlock()
+	a, ok := c.announcing.active[chatID]
+	if !ok || !a.playing {
+		return false
+	}
+	a.cancel()
+	delete(c.announcing.active, chatID)
+	return true
+}
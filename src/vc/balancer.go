@@ -0,0 +1,274 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/vc/ubot"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// assistantHealth records why an assistant is currently excluded from assignment.
+type assistantHealth struct {
+	reason string
+	since  time.Time
+	until  time.Time // zero means the assistant stays unhealthy until restart
+}
+
+const (
+	// peerFloodCooldown is how long an assistant rests after PEER_FLOOD, which comes without a wait time.
+	peerFloodCooldown = 12 * time.Hour
+	// longCooldown is how long a restriction must last to be persisted and reported to the logger group.
+	longCooldown = 10 * time.Minute
+)
+
+// AssistantStatus describes one assistant account for /assistantinfo.
+type AssistantStatus struct {
+	Name        string
+	Username    string
+	UserID      int64
+	ActiveCalls int
+	Healthy     bool
+	Reason      string
+	Since       time.Time
+	Until       time.Time
+}
+
+// fatalSessionErrors are errors after which an assistant session cannot be used again without a restart.
+var fatalSessionErrors = []string{"AUTH_KEY_UNREGISTERED", "AUTH_KEY_DUPLICATED", "SESSION_REVOKED", "USER_DEACTIVATED"}
+
+// getClientName selects an assistant client for a given chat. It keeps the chat's stored assignment while that
+// assistant is healthy; otherwise it assigns the least-loaded healthy assistant and saves the assignment for future use.
+func (c *TelegramCalls) getClientName(chatID int64) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.availableClients) == 0 {
+		return "", fmt.Errorf("no clients are available")
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	assistant, err := db.Instance.GetAssistant(ctx, chatID)
+	if err != nil {
+		logger.Info("[TelegramCalls] DB.GetAssistant error: %v", err)
+	}
+
+	if assistant != "" {
+		for _, name := range c.availableClients {
+			if name == assistant && c.isHealthy(name) {
+				return name, nil
+			}
+		}
+	}
+
+	newClient := c.leastLoaded()
+	if newClient == assistant {
+		return newClient, nil
+	}
+
+	if err = db.Instance.SetAssistant(ctx, chatID, newClient); err != nil {
+		logger.Info("[TelegramCalls] DB.SetAssistant error: %v", err)
+	}
+
+	logger.Info("[TelegramCalls] An assistant has been set for chat %d -> %s", chatID, newClient)
+	return newClient, nil
+}
+
+// leastLoaded returns the healthy assistant with the fewest active calls.
+// If every assistant is unhealthy, it falls back to the least-loaded one overall.
+// The caller must hold c.mu.
+func (c *TelegramCalls) leastLoaded() string {
+	best, bestLoad := "", -1
+	fallback, fallbackLoad := c.availableClients[0], -1
+	for _, name := range c.availableClients {
+		load := len(c.uBContext[name].Calls())
+		if fallbackLoad < 0 || load < fallbackLoad {
+			fallback, fallbackLoad = name, load
+		}
+		if c.isHealthy(name) && (bestLoad < 0 || load < bestLoad) {
+			best, bestLoad = name, load
+		}
+	}
+
+	if best == "" {
+		return fallback
+	}
+	return best
+}
+
+// isHealthy reports whether an assistant may be assigned to chats.
+func (c *TelegramCalls) isHealthy(name string) bool {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+
+	h, ok := c.health[name]
+	if !ok {
+		return true
+	}
+	if !h.until.IsZero() && time.Now().After(h.until) {
+		delete(c.health, name)
+		return true
+	}
+	return false
+}
+
+// reportAssistantError marks an assistant unhealthy if err shows it is flood-limited or logged out.
+// It returns true when the assistant was marked, meaning the caller should retry with another assistant.
+// The chats an assistant is streaming in when it becomes unhealthy are moved to healthy assistants.
+func (c *TelegramCalls) reportAssistantError(name string, err error) bool {
+	if name == "" || err == nil {
+		return false
+	}
+
+	now := time.Now()
+	health := assistantHealth{since: now}
+	if wait := tg.GetFloodWait(err); wait > 0 {
+		health.reason = fmt.Sprintf("flood wait %ds", wait)
+		health.until = now.Add(time.Duration(wait) * time.Second)
+	} else if strings.Contains(err.Error(), "PEER_FLOOD") {
+		health.reason = "PEER_FLOOD"
+		health.until = now.Add(peerFloodCooldown)
+	} else {
+		for _, fatal := range fatalSessionErrors {
+			if strings.Contains(err.Error(), fatal) {
+				health.reason = fatal
+				break
+			}
+		}
+	}
+
+	if health.reason == "" {
+		return false
+	}
+
+	c.healthMu.Lock()
+	prev, had := c.health[name]
+	c.health[name] = health
+	c.healthMu.Unlock()
+
+	logger.Warn("[TelegramCalls] Assistant %s marked unhealthy: %s", name, health.reason)
+	if health.until.Sub(now) >= longCooldown && !(had && prev.until.After(now)) {
+		go c.startCooldown(name, health)
+	}
+	if !c.hasHealthyAssistant() {
+		return false
+	}
+	if !had || (!prev.until.IsZero() && !prev.until.After(now)) {
+		go c.failOver(name)
+	}
+	return true
+}
+
+// failOver moves every chat an assistant that just became unhealthy is streaming in to a healthy assistant,
+// restarting the current track where it was.
+func (c *TelegramCalls) failOver(name string) {
+	c.mu.RLock()
+	call, ok := c.uBContext[name]
+	c.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	for chatID := range call.Calls() {
+		c.moveChat(chatID, name, call)
+	}
+}
+
+// moveChat leaves chatID with the unhealthy assistant and resumes its current track with the chat's new one.
+// A chat the assistant already left, for example because PlayMedia failed over on its own, is left alone.
+func (c *TelegramCalls) moveChat(chatID int64, from string, call *ubot.Context) {
+	c.interruptAnnouncement(chatID)
+	unlock := c.lockPlayback(chatID)
+	defer unlock()
+
+	if _, ok := call.Calls()[chatID]; !ok {
+		return
+	}
+	song := cache.ChatCache.GetPlayingTrack(chatID)
+	pos := c.position(chatID)
+	if song != nil {
+		pos.offset = c.restartOffset(chatID, song)
+	}
+	if err := call.Stop(chatID); err != nil {
+		logger.Debug("[failOver] %s could not leave %d: %v", from, chatID, err)
+	}
+	if song == nil {
+		return
+	}
+
+	if err := c.restream(chatID, song, pos); err != nil {
+		logger.Warn("[failOver] Failed to move %d off %s: %v", chatID, from, err)
+		return
+	}
+	logger.Info("[failOver] Moved %d off %s", chatID, from)
+}
+
+// hasHealthyAssistant reports whether at least one assistant can take new chats.
+func (c *TelegramCalls) hasHealthyAssistant() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, name := range c.availableClients {
+		if c.isHealthy(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Assistants returns the status of every configured assistant, in start order.
+func (c *TelegramCalls) Assistants() []AssistantStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	statuses := make([]AssistantStatus, 0, len(c.availableClients))
+	for _, name := range c.availableClients {
+		status := AssistantStatus{
+			Name:        name,
+			ActiveCalls: len(c.uBContext[name].Calls()),
+			Healthy:     c.isHealthy(name),
+		}
+
+		if me := c.clients[name].Me(); me != nil {
+			status.Username = me.Username
+			status.UserID = me.ID
+		}
+
+		if !status.Healthy {
+			c.healthMu.Lock()
+			status.Reason = c.health[name].reason
+			status.Since = c.health[name].since
+			status.Until = c.health[name].until
+			c.healthMu.Unlock()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// PingAssistant checks that an assistant's session is still logged in. A fatal session error marks the
+// assistant unhealthy, as it would during playback.
+func (c *TelegramCalls) PingAssistant(name string) error {
+	c.mu.RLock()
+	client, ok := c.clients[name]
+	c.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no client was found for %s", name)
+	}
+
+	_, err := client.GetMe()
+	c.reportAssistantError(name, err)
+	return err
+}
@@ -0,0 +1,131 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/callend"
+	"ashokshau/tgmusic/src/core/cleaner"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+)
+
+// ErrNoEndedQueue is returned by ResumeEnded when the chat has no queue saved from an ended voice chat.
+var ErrNoEndedQueue = errors.New("no saved queue")
+
+// endSession tears down the chat's playback session without touching the call itself.
+// The streams of the chats mirroring it are ended too.
+func (c *TelegramCalls) endSession(chatID int64) {
+	cache.ChatCache.ClearChat(chatID)
+	c.retireNowPlaying(chatID)
+	c.cancelIdleTimer(chatID)
+	c.clearAutoPause(chatID)
+	c.resetRestarts(chatID)
+	c.restoreTitle(chatID)
+	c.clearPosition(chatID)
+	c.discardPreload(chatID)
+	c.stopMirrors(chatID)
+	_ = c.setState(chatID, StateIdle, nil)
+}
+
+// handleCallEnded cleans up after the voice chat of a chat was ended while the bot was in it, streaming or
+// idle. Depending on the chat's keep_queue setting the queue is kept, so /resume can continue it in a new voice
+// chat. A chat mirroring another only has its link marked degraded.
+func (c *TelegramCalls) handleCallEnded(chatID int64) {
+	switch callend.Decide(callend.Session{
+		Mirroring: c.MirrorSource(chatID) != 0,
+		Active:    cache.ChatCache.IsActive(chatID),
+		Idle:      c.IsIdle(chatID),
+	}) {
+	case callend.DegradeMirror:
+		c.degradeMirror(chatID, "the voice chat was ended")
+		return
+	case callend.Ignore:
+		return
+	}
+
+	c.interruptAnnouncement(chatID)
+	unlock := c.lockPlayback(chatID)
+	queue := cache.ChatCache.GetQueue(chatID)
+	elapsed, _ := c.Elapsed(chatID)
+	c.endSession(chatID)
+	unlock()
+	go c.admitWaiting()
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	logger.Info("[handleCallEnded] The voice chat in %d was ended with %d tracks queued.", chatID, len(queue))
+
+	text := lang.GetString(langCode, "vc_ended")
+	if len(queue) > 0 && db.Instance.GetKeepQueue(ctx, chatID) {
+		snap := &db.QueueSnapshot{ChatID: chatID, Elapsed: elapsed, SavedAt: time.Now()}
+		for _, t := range queue {
+			snap.Tracks = append(snap.Tracks, *t)
+		}
+		c.endedMu.Lock()
+		c.endedQueues[chatID] = snap
+		c.endedMu.Unlock()
+		text = fmt.Sprintf(lang.GetString(langCode, "vc_ended_saved"), len(queue))
+	}
+
+	if msg, err := c.bot.SendMessage(chatID, text); err == nil {
+		cleaner.ScheduleChat(c.bot, chatID, msg.ID)
+	}
+}
+
+// EndedQueueLength returns how many tracks are saved for the chat from a voice chat that was ended, or 0.
+func (c *TelegramCalls) EndedQueueLength(chatID int64) int {
+	c.endedMu.Lock()
+	defer c.endedMu.Unlock()
+	if snap, ok := c.endedQueues[chatID]; ok {
+		return len(snap.Tracks)
+	}
+	return 0
+}
+
+// DiscardEndedQueue forgets the queue saved for the chat from a voice chat that was ended.
+func (c *TelegramCalls) DiscardEndedQueue(chatID int64) {
+	c.endedMu.Lock()
+	defer c.endedMu.Unlock()
+	delete(c.endedQueues, chatID)
+}
+
+// ResumeEnded continues the queue saved from an ended voice chat where it stopped.
+// It returns the number of waiting-room places ahead of the chat when no playback slot is free, or 0 once playback started.
+func (c *TelegramCalls) ResumeEnded(chatID int64) (int, error) {
+	c.endedMu.Lock()
+	snap, ok := c.endedQueues[chatID]
+	delete(c.endedQueues, chatID)
+	c.endedMu.Unlock()
+	if !ok {
+		return 0, ErrNoEndedQueue
+	}
+
+	waitPos, err := c.Admit(chatID, func() error {
+		cache.ChatCache.ClearChat(chatID)
+		for i := range snap.Tracks {
+			track := snap.Tracks[i]
+			if _, err := cache.ChatCache.Enqueue(chatID, &track); err != nil {
+				break
+			}
+		}
+		cache.ChatCache.SetActive(chatID, false)
+		return nil
+	})
+	if waitPos > 0 || err != nil {
+		return waitPos, err
+	}
+	defer c.ReleaseSlot(chatID)
+	return 0, c.RestoreSnapshot(snap)
+}
@@ -22,13 +22,9 @@ import "C"
 
 import (
 	"context"
-	"crypto/rand"
 	"errors"
 	"fmt"
-	"log"
-	"math/big"
 	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -36,8 +32,10 @@ import (
 	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core"
 	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/cleaner"
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/core/shutdown"
 	"ashokshau/tgmusic/src/lang"
 	"ashokshau/tgmusic/src/vc/ntgcalls"
 	"ashokshau/tgmusic/src/vc/sessions"
@@ -46,53 +44,17 @@ import (
 	tg "github.com/amarnathcjd/gogram/telegram"
 )
 
-// getClientName selects an assistant client for a given chat. It prioritizes existing assignments from the database.
-// If no assignment exists, it randomly selects an available client and saves the assignment for future use.
-//
-// TODO: Implement a more sophisticated client selection strategy, such as consistent hashing or load-based balancing,
-// to ensure a more even distribution of chats among assistants.
-func (c *TelegramCalls) getClientName(chatID int64) (string, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	if len(c.availableClients) == 0 {
-		return "", fmt.Errorf("no clients are available")
-	}
-	ctx, cancel := db.Ctx()
-	defer cancel()
-
-	assistant, err := db.Instance.GetAssistant(ctx, chatID)
-	if err != nil {
-		c.bot.Log.Info("[TelegramCalls] DB.GetAssistant error: %v", err)
-	}
-
-	if assistant != "" {
-		for _, name := range c.availableClients {
-			if name == assistant {
-				return name, nil
-			}
-		}
-	}
-
-	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(c.availableClients))))
-	if err != nil {
-		log.Printf("[TelegramCalls] Could not generate a random number: %v", err)
-		return c.availableClients[0], nil
-	}
-	newClient := c.availableClients[n.Int64()]
-
-	if err = db.Instance.SetAssistant(ctx, chatID, newClient); err != nil {
-		c.bot.Log.Info("[TelegramCalls] DB.SetAssistant error: %v", err)
-	}
-
-	c.bot.Log.Info("[TelegramCalls] An assistant has been set for chat %d -> %s", chatID, newClient)
-	return newClient, nil
-}
-
 // GetGroupAssistant retrieves the ubot.Context for a given chat, which is used to interact with the voice call.
 func (c *TelegramCalls) GetGroupAssistant(chatID int64) (*ubot.Context, error) {
+	_, call, err := c.assistantFor(chatID)
+	return call, err
+}
+
+// assistantFor returns the name and ubot.Context of the assistant assigned to a chat.
+func (c *TelegramCalls) assistantFor(chatID int64) (string, *ubot.Context, error) {
 	clientName, err := c.getClientName(chatID)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	c.mu.RLock()
@@ -100,9 +62,9 @@ func (c *TelegramCalls) GetGroupAssistant(chatID int64) (*ubot.Context, error) {
 
 	call, ok := c.uBContext[clientName]
 	if !ok {
-		return nil, fmt.Errorf("no ntgcalls instance was found for %s", clientName)
+		return "", nil, fmt.Errorf("no ntgcalls instance was found for %s", clientName)
 	}
-	return call, nil
+	return clientName, call, nil
 }
 
 // StartClient initializes a new userbot client and adds it to the pool of available assistants.
@@ -123,7 +85,7 @@ func (c *TelegramCalls) StartClient(apiID int32, apiHash, stringSession string)
 		SessionName:   clientName,
 	}
 
-	switch config.Conf.SessionType {
+	switch config.Get().SessionType {
 	case "telethon":
 		sess, err = sessions.DecodeTelethonSessionString(stringSession)
 		if err != nil {
@@ -139,7 +101,7 @@ func (c *TelegramCalls) StartClient(apiID int32, apiHash, stringSession string)
 	case "gogram":
 		clientConfig.StringSession = stringSession
 	default:
-		return nil, fmt.Errorf("unsupported session type: %s", config.Conf.SessionType)
+		return nil, fmt.Errorf("unsupported session type: %s", config.Get().SessionType)
 	}
 
 	mtProto, err := tg.NewClient(clientConfig)
@@ -179,72 +141,109 @@ func (c *TelegramCalls) StopAllClients() {
 	}
 
 	for name, client := range c.clients {
-		c.bot.Log.Info("[TelegramCalls] Stopping the client: %s", name)
+		logger.Info("[TelegramCalls] Stopping the client: %s", name)
 		_ = client.Stop()
 	}
 }
 
 // PlayMedia starts playing a media file in a voice chat. It handles joining the assistant to the chat if necessary
 // and sends a log message if logging is enabled.
+// If the chat's assistant turns out to be flood-limited or logged out, the chat is moved to another assistant and
+// playback is retried once.
 func (c *TelegramCalls) PlayMedia(chatID int64, filePath string, video bool, ffmpegParameters string) error {
-	call, err := c.GetGroupAssistant(chatID)
+	_ = c.setState(chatID, StateJoining, nil)
+	clientName, err := c.playMedia(chatID, filePath, video, ffmpegParameters)
+	if err != nil && c.reportAssistantError(clientName, err) {
+		logger.Warn("[PlayMedia] Assistant %s failed in %d (%v); failing over.", clientName, chatID, err)
+		_, err = c.playMedia(chatID, filePath, video, ffmpegParameters)
+	}
+
 	if err != nil {
+		_ = c.setState(chatID, StateErrored, err)
+		c.clearPosition(chatID)
+		cache.ChatCache.ClearChat(chatID)
 		return err
 	}
+	c.clearAutoPause(chatID)
+	_ = c.setState(chatID, StatePlaying, nil)
+	c.mirrorPlay(chatID, filePath, video, ffmpegParameters)
+	return nil
+}
+
+// playMedia joins the chat's assistant and starts the stream.
+// It returns the name of the assistant used so failures can be attributed to it.
+func (c *TelegramCalls) playMedia(chatID int64, filePath string, video bool, ffmpegParameters string) (string, error) {
+	clientName, call, err := c.assistantFor(chatID)
+	if err != nil {
+		return "", err
+	}
 	ctx, cancel := db.Ctx()
 	defer cancel()
 
 	if chatID < 0 {
 		if err := c.joinAssistant(chatID, call.App.Me().ID); err != nil {
-			cache.ChatCache.ClearChat(chatID)
-			return err
+			return clientName, err
 		}
 	} else {
 		_, _ = call.App.ResolvePeer(chatID)
 	}
 
-	c.bot.Log.Info("Playing media in chat %d: %s", chatID, filePath)
-	mediaDesc := getMediaDescription(filePath, video, ffmpegParameters)
-	if err := call.Play(chatID, mediaDesc); err != nil {
+	c.cancelIdleTimer(chatID)
+	filePath = dl.ResolvePartial(filePath)
+	logger.Info("Playing media in chat %d: %s", chatID, filePath)
+	if flags := growingFlags(filePath); flags != "" {
+		ffmpegParameters = strings.TrimSpace(flags + " " + ffmpegParameters)
+	}
+	mediaDesc, preloaded := c.mediaDescription(chatID, filePath, video, ffmpegParameters)
+	err = call.Play(chatID, mediaDesc)
+	if err != nil && chatID < 0 && isNoActiveCall(err) {
+		if err = c.startVoiceChat(chatID, call); err != nil {
+			return clientName, err
+		}
+		err = call.Play(chatID, mediaDesc)
+	}
+	if err != nil {
 		logger.Error("Failed to play the media: %v", err)
-		cache.ChatCache.ClearChat(chatID)
-		return fmt.Errorf("playback failed: %w", err)
+		return clientName, fmt.Errorf("playback failed: %w", err)
 	}
+	c.logGap(chatID, preloaded)
 
 	if db.Instance.GetLoggerStatus(ctx, c.bot.Me().ID) {
 		go sendLogger(c.bot, chatID, cache.ChatCache.GetPlayingTrack(chatID))
 	}
 
-	return nil
+	return clientName, nil
 }
 
 // downloadAndPrepareSong handles the download and preparation of a song for playback.
 // It returns an error if the download or preparation fails.
-func (c *TelegramCalls) downloadAndPrepareSong(song *cache.CachedTrack, reply *tg.NewMessage) error {
-	if song.FilePath != "" {
+func (c *TelegramCalls) downloadAndPrepareSong(chatID int64, song *cache.CachedTrack, reply *tg.NewMessage) error {
+	if cache.ChatCache.TrackFile(chatID, song) != "" {
 		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-	defer cancel()
-
-	chatID := config.Conf.LoggerId
 	dbCtx, dbCancel := db.Ctx()
 	defer dbCancel()
 	langCode := db.Instance.GetLang(dbCtx, chatID)
 
-	dlPath, trackInfo, err := DownloadSong(ctx, song, c.bot)
+	dlPath, trackInfo, err := c.DownloadForPlayback(chatID, song)
 	if err != nil {
 		_, _ = reply.Edit(fmt.Sprintf(lang.GetString(langCode, "download_failed_skip"), err))
 		return err
 	}
 
-	song.FilePath = dlPath
+	if dlPath == "" {
+		_, _ = reply.Edit(lang.GetString(langCode, "download_failed_empty"))
+		return errors.New("download failed due to an empty file path")
+	}
+
+	// A prefetch that finished meanwhile may have set the file first; either copy will do.
+	cache.ChatCache.SetTrackFile(chatID, song, dlPath)
 	if trackInfo != nil && trackInfo.Duration > 0 {
 		song.Duration = trackInfo.Duration
 	}
 
-	if song.FilePath == "" {
+	if cache.ChatCache.TrackFile(chatID, song) == "" {
 		_, _ = reply.Edit(lang.GetString(langCode, "download_failed_empty"))
 		return errors.New("download failed due to an empty file path")
 	}
@@ -253,32 +252,100 @@ func (c *TelegramCalls) downloadAndPrepareSong(song *cache.CachedTrack, reply *t
 }
 
 // PlayNext plays the next song in the queue, handles looping, and notifies the chat when the queue is finished.
+// It runs under the chat's playback lock so concurrent callers advance the queue one at a time,
+// and returns ErrPlaybackBusy if a transition is already in flight.
 func (c *TelegramCalls) PlayNext(chatID int64) error {
-	loop := cache.ChatCache.GetLoopCount(chatID)
-	if loop > 0 {
-		cache.ChatCache.SetLoopCount(chatID, loop-1)
-		if currentsSong := cache.ChatCache.GetPlayingTrack(chatID); currentsSong != nil {
-			return c.playSong(chatID, currentsSong)
-		}
+	if err := c.checkBusy(chatID); err != nil {
+		return err
+	}
+
+	unlock := c.lockPlayback(chatID)
+	defer unlock()
+
+	return c.advance(chatID)
+}
+
+// playNextAfter advances the queue only if ended is still the current track.
+// It is used for stream-end events, which may race with a /skip that already moved the queue on.
+// A stream that ended well before the track's duration is restarted from where it stopped instead.
+func (c *TelegramCalls) playNextAfter(chatID int64, ended *cache.CachedTrack) error {
+	unlock := c.lockPlayback(chatID)
+	defer unlock()
+
+	if ended == nil || cache.ChatCache.GetPlayingTrack(chatID) != ended {
+		logger.Debug("[playNextAfter] The queue in %d already moved on; ignoring stream end.", chatID)
+		return nil
 	}
+	c.markStreamEnded(chatID)
 
-	if nextSong := cache.ChatCache.GetUpcomingTrack(chatID); nextSong != nil {
-		cache.ChatCache.RemoveCurrentSong(chatID)
-		return c.playSong(chatID, nextSong)
+	if c.recoverStream(chatID, ended) {
+		return nil
 	}
+	c.resetRestarts(chatID)
+	c.markCompleted(chatID)
+	return c.advance(chatID)
+}
+
+// advance picks the next playable track and starts it, skipping tracks that cannot be prepared.
+// The track that was playing is recorded in the chat's history once it leaves the queue.
+// The caller must hold the chat's playback lock.
+func (c *TelegramCalls) advance(chatID int64) error {
+	return c.advanceQueue(chatID, true)
+}
+
+// advanceQueue is advance with control over whether the outgoing track is recorded in the history.
+// The chat is StateTransitioning while it runs and never left in it, whichever way it returns.
+func (c *TelegramCalls) advanceQueue(chatID int64, record bool) (err error) {
+	c.retireNowPlaying(chatID)
+	_ = c.setState(chatID, StateTransitioning, nil)
+	defer func() { c.states.Settle(chatID, StateTransitioning, err) }()
+
+	ended := cache.ChatCache.GetPlayingTrack(chatID)
+	refilled := false
+	for {
+		nextSong := cache.ChatCache.Next(chatID)
+		if ended != nil {
+			if record && nextSong != ended {
+				cache.History.Push(chatID, ended)
+			}
+			ended = nil
+		}
+		if nextSong == nil && !refilled {
+			refilled = true
+			if c.refillRadio(chatID) {
+				nextSong = cache.ChatCache.GetPlayingTrack(chatID)
+			}
+		}
+		if nextSong == nil {
+			return c.handleNoSong(chatID)
+		}
+
+		err = c.playSong(chatID, nextSong)
+		if !errors.Is(err, errTrackUnavailable) {
+			return err
+		}
 
-	cache.ChatCache.RemoveCurrentSong(chatID)
-	return c.handleNoSong(chatID)
+		logger.Request(nextSong.RequestID).Info("[advance] Skipping %q in %d: %v", nextSong.Name, chatID, err)
+		nextSong.Loop = 0
+	}
 }
 
-// handleNoSong manages the situation where there are no more songs in the queue by stopping the playback
+// handleNoSong manages the situation where there are no more songs in the queue by starting the inactivity timer
 // and sending a notification to the chat.
 func (c *TelegramCalls) handleNoSong(chatID int64) error {
-	_ = c.Stop(chatID)
+	_ = c.setState(chatID, StateIdle, nil)
+	c.clearPosition(chatID)
+	c.restoreTitle(chatID)
+	c.discardPreload(chatID)
+	c.stopMirrors(chatID)
+	c.startIdleTimer(chatID)
+	go c.admitWaiting()
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
-	_, _ = c.bot.SendMessage(chatID, lang.GetString(langCode, "queue_finished"))
+	if msg, err := c.bot.SendMessage(chatID, lang.GetString(langCode, "queue_finished")); err == nil {
+		cleaner.ScheduleChat(c.bot, chatID, msg.ID)
+	}
 	return nil
 }
 
@@ -290,49 +357,72 @@ func (c *TelegramCalls) playSong(chatID int64, song *cache.CachedTrack) error {
 	langCode := db.Instance.GetLang(ctx, chatID)
 	reply, err := c.bot.SendMessage(chatID, fmt.Sprintf(lang.GetString(langCode, "downloading"), song.Name))
 	if err != nil {
-		c.bot.Log.Info("[playSong] Failed to send message: %v", err)
+		logger.Info("[playSong] Failed to send message: %v", err)
 		return err
 	}
 
-	if err := c.downloadAndPrepareSong(song, reply); err != nil {
-		return c.PlayNext(chatID)
+	if err := c.downloadAndPrepareSong(chatID, song, reply); err != nil {
+		cleaner.ScheduleChat(c.bot, chatID, reply.ID)
+		return fmt.Errorf("%w: %v", errTrackUnavailable, err)
+	}
+
+	filePath := cache.ChatCache.TrackFile(chatID, song)
+	if _, err := os.Stat(filePath); err != nil {
+		_, _ = reply.Edit(fmt.Sprintf(lang.GetString(langCode, "track_file_missing_skip"), song.Name))
+		cleaner.ScheduleChat(c.bot, chatID, reply.ID)
+		return fmt.Errorf("%w: %v", errTrackUnavailable, err)
 	}
 
-	if err := c.PlayMedia(chatID, song.FilePath, song.IsVideo, ""); err != nil {
+	c.announce(chatID, song, langCode)
+	pos := streamPosition{speed: c.nextTrackSpeed(chatID), filter: c.position(chatID).filter}
+	if err := c.PlayMedia(chatID, filePath, song.IsVideo, streamParams(pos, 0)); err != nil {
 		_, err := reply.Edit(err.Error())
+		cleaner.ScheduleChat(c.bot, chatID, reply.ID)
 		return err
 	}
+	c.setPosition(chatID, pos)
+	c.showTrackTitle(chatID, song)
 
 	if song.Duration == 0 {
-		song.Duration = cache.GetFileDuration(song.FilePath)
+		song.Duration = cache.GetFileDuration(filePath)
 	}
-	text := fmt.Sprintf(
-		lang.GetString(langCode, "now_playing_details"),
-		song.URL,
-		song.Name,
-		cache.SecToMin(song.Duration),
-		song.User,
-	)
+	text := c.NowPlayingText(chatID, song, "now_playing_details", langCode)
 
 	_, err = reply.Edit(text, &tg.SendOptions{ReplyMarkup: core.ControlButtons("play")})
 	if err != nil {
-		c.bot.Log.Warn("[playSong] Failed to edit message: %v", err)
+		logger.Warn("[playSong] Failed to edit message: %v", err)
 		return nil
 	}
 
+	c.SetNowPlayingMessage(chatID, reply.ID)
+	c.ShowCard(chatID, reply.ID, song, text)
+	c.prefetchNext(chatID)
 	return nil
 }
 
 // Stop halts media playback in a voice chat and clears the chat's cache.
+// How far the current track got is saved so that playing it again can resume it.
+// The freed playback slot is handed to the next chat in the waiting room.
 func (c *TelegramCalls) Stop(chatId int64) error {
+	if c.WaitingPosition(chatId) > 0 {
+		c.leaveWaitingRoom(chatId)
+		cache.ChatCache.ClearChat(chatId)
+		return nil
+	}
+	defer func() { go c.admitWaiting() }()
+	c.interruptAnnouncement(chatId)
+
 	call, err := c.GetGroupAssistant(chatId)
 	if err != nil {
 		return err
 	}
-	cache.ChatCache.ClearChat(chatId)
+	c.saveResumePoint(chatId)
+	c.endSession(chatId)
+	c.DiscardEndedQueue(chatId)
+	cache.History.Clear(chatId)
 	err = call.Stop(chatId)
 	if err != nil {
-		c.bot.Log.Info("[Stop] Failed to stop the call: %v", err)
+		logger.Info("[Stop] Failed to stop the call: %v", err)
 		// For now, we will ignore the error.
 		return nil
 	}
@@ -341,23 +431,59 @@ func (c *TelegramCalls) Stop(chatId int64) error {
 
 // Pause temporarily stops media playback in a voice chat.
 // It returns true if the operation was successful, and an error otherwise.
+// ErrAlreadyPaused, ErrPlaybackBusy and ErrNotPlaying report why the chat's state does not allow pausing.
 func (c *TelegramCalls) Pause(chatId int64) (bool, error) {
+	if err := c.states.CheckPause(chatId); err != nil {
+		return false, err
+	}
+
 	call, err := c.GetGroupAssistant(chatId)
 	if err != nil {
 		return false, err
 	}
 
-	return call.Pause(chatId)
+	ok, err := call.Pause(chatId)
+	if err != nil && isNoActiveCall(err) {
+		go c.handleCallEnded(chatId)
+		return false, ErrNotPlaying
+	}
+	if err == nil {
+		_ = c.setState(chatId, StatePaused, nil)
+		if tracker := c.tracker(chatId); tracker != nil {
+			tracker.Pause()
+		}
+		c.mirrorPause(chatId, true)
+	}
+	return ok, err
 }
 
 // Resume continues a paused media playback in a voice chat.
 // It returns true if the operation was successful, and an error otherwise.
+// ErrNotPaused, ErrPlaybackBusy and ErrNotPlaying report why the chat's state does not allow resuming.
 func (c *TelegramCalls) Resume(chatId int64) (bool, error) {
+	if err := c.states.CheckResume(chatId); err != nil {
+		return false, err
+	}
+
 	call, err := c.GetGroupAssistant(chatId)
 	if err != nil {
 		return false, err
 	}
-	return call.Resume(chatId)
+
+	ok, err := call.Resume(chatId)
+	if err != nil && isNoActiveCall(err) {
+		go c.handleCallEnded(chatId)
+		return false, ErrNotPlaying
+	}
+	if err == nil {
+		c.clearAutoPause(chatId)
+		_ = c.setState(chatId, StatePlaying, nil)
+		if tracker := c.tracker(chatId); tracker != nil {
+			tracker.Resume()
+		}
+		c.mirrorPause(chatId, false)
+	}
+	return ok, err
 }
 
 // Mute silences the media playback in a voice chat.
@@ -392,9 +518,28 @@ func (c *TelegramCalls) PlayedTime(chatId int64) (uint64, error) {
 	return call.Time(chatId, 0)
 }
 
+// ListenerCount returns the number of participants in a chat's voice call, excluding the assistant itself.
+func (c *TelegramCalls) ListenerCount(chatId int64) (int, error) {
+	call, err := c.GetGroupAssistant(chatId)
+	if err != nil {
+		return 0, err
+	}
+
+	participants, err := call.GetParticipants(chatId)
+	if err != nil {
+		return 0, err
+	}
+
+	count := len(participants)
+	if count > 0 {
+		count--
+	}
+	return count, nil
+}
+
 var urlRegex = regexp.MustCompile(`^https?://`)
 
-// SeekStream jumps to a specific time in the current media stream.
+// SeekStream jumps to a specific time in the current media stream, keeping the chat's current speed and filter.
 func (c *TelegramCalls) SeekStream(chatID int64, filePath string, toSeek, duration int, isVideo bool) error {
 	ctx, cancel := db.Ctx()
 	defer cancel()
@@ -407,48 +552,55 @@ func (c *TelegramCalls) SeekStream(chatID int64, filePath string, toSeek, durati
 	_, err := os.Stat(filePath)
 	isFile := err == nil
 
+	pos := c.position(chatID)
 	var ffmpegParams string
 	if isURL || !isFile {
-		ffmpegParams = fmt.Sprintf("-ss %d -i %s -to %d", toSeek, filePath, duration)
+		ffmpegParams = strings.TrimSpace(fmt.Sprintf("-ss %d -i %s -to %d ", toSeek, filePath, duration) + streamParams(pos, 0))
 	} else {
-		ffmpegParams = fmt.Sprintf("-ss %d -to %d", toSeek, duration)
+		pos.offset = toSeek
+		ffmpegParams = streamParams(pos, duration)
 	}
 
-	return c.PlayMedia(chatID, filePath, isVideo, ffmpegParams)
+	if err = c.PlayMedia(chatID, filePath, isVideo, ffmpegParams); err != nil {
+		return err
+	}
+	pos.offset = toSeek
+	c.setPosition(chatID, pos)
+	return nil
 }
 
-// ChangeSpeed modifies the playback speed of the current stream.
-func (c *TelegramCalls) ChangeSpeed(chatID int64, speed float64) error {
+// ChangeSpeed re-streams the current track from the current position at the given speed.
+// The speed is clamped to the range MinSpeed..MaxSpeed; the applied value is returned.
+// A filter preset that changes the tempo itself, such as nightcore, is dropped since the explicit speed takes precedence.
+func (c *TelegramCalls) ChangeSpeed(chatID int64, speed float64) (float64, error) {
+	if err := c.checkBusy(chatID); err != nil {
+		return 0, err
+	}
+
+	unlock := c.lockPlayback(chatID)
+	defer unlock()
+
 	ctx, cancel := db.Ctx()
 	defer cancel()
 	langCode := db.Instance.GetLang(ctx, chatID)
-	if speed < 0.5 || speed > 4.0 {
-		return errors.New(lang.GetString(langCode, "invalid_speed"))
-	}
+	speed = ClampSpeed(speed)
 
 	playingSong := cache.ChatCache.GetPlayingTrack(chatID)
 	if playingSong == nil {
-		return errors.New(lang.GetString(langCode, "no_song_playing"))
+		return 0, errors.New(lang.GetString(langCode, "no_song_playing"))
 	}
 
-	videoPTS := 1 / speed
-
-	audioFilters := make([]string, 0)
-	remaining := speed
-	for remaining > 2.0 {
-		audioFilters = append(audioFilters, "atempo=2.0")
-		remaining /= 2.0
-	}
-	for remaining < 0.5 {
-		audioFilters = append(audioFilters, "atempo=0.5")
-		remaining /= 0.5
+	pos := c.position(chatID)
+	pos.offset = c.restartOffset(chatID, playingSong)
+	pos.speed = speed
+	if preset, ok := lookupFilter(pos.filter); ok && preset.Tempo != 0 {
+		pos.filter = ""
 	}
-	audioFilters = append(audioFilters, fmt.Sprintf("atempo=%f", remaining))
-	audioFilter := strings.Join(audioFilters, ",")
-
-	ffmpegFilters := fmt.Sprintf("-filter:v setpts=%f*PTS -filter:a %s", videoPTS, audioFilter)
 
-	return c.PlayMedia(chatID, playingSong.FilePath, playingSong.IsVideo, ffmpegFilters)
+	if err := c.restream(chatID, playingSong, pos); err != nil {
+		return 0, err
+	}
+	return speed, nil
 }
 
 // RegisterHandlers sets up the event handlers for the voice call client.
@@ -457,23 +609,38 @@ func (c *TelegramCalls) RegisterHandlers(client *tg.Client) {
 	defer c.mu.Unlock()
 
 	c.bot = client
-	logger = client.Log
+	go c.watchAlone()
+	go c.persistSnapshots()
+	go c.persistPlayStats()
+	go c.loadMirrors()
+	go c.loadCooldowns()
+	c.registerShutdown()
+	c.registerReaper()
 
 	for _, call := range c.uBContext {
 
 		//_, _ = call.App.UpdatesGetState()
 		call.OnStreamEnd(func(chatID int64, streamType ntgcalls.StreamType, device ntgcalls.StreamDevice) {
-			client.Log.Info("[TelegramCalls] The stream has ended in chat %d (type=%v, device=%v)", chatID, streamType, device)
+			logger.Info("[TelegramCalls] The stream has ended in chat %d (type=%v, device=%v)", chatID, streamType, device)
 			if streamType == ntgcalls.VideoStream {
-				client.Log.Info("Ignoring video stream end for chat %d", chatID)
+				logger.Info("Ignoring video stream end for chat %d", chatID)
+				return
+			}
+			if c.announcementEnded(chatID) {
+				return
+			}
+			if c.MirrorSource(chatID) != 0 {
+				// A mirror moves on when its source does.
 				return
 			}
 
-			if err := c.PlayNext(chatID); err != nil {
-				client.Log.Error("[OnStreamEnd] Failed to play the song: %v", err)
+			if err := c.playNextAfter(chatID, cache.ChatCache.GetPlayingTrack(chatID)); err != nil {
+				logger.Error("[OnStreamEnd] Failed to play the song: %v", err)
 			}
 		})
 
+		call.OnGroupCallEnded(c.handleCallEnded)
+
 		call.OnIncomingCall(func(ub *ubot.Context, chatID int64) {
 			ctx, cancel := db.Ctx()
 			defer cancel()
@@ -481,21 +648,21 @@ func (c *TelegramCalls) RegisterHandlers(client *tg.Client) {
 			_, _ = ub.App.SendMessage(chatID, lang.GetString(langCode, "incoming_call"))
 			msg, err := dl.GetMessage(c.bot, "https://t.me/FallenSongs/1295")
 			if err != nil {
-				c.bot.Log.Info("[OnIncomingCall] Failed to get the message: %v", err)
+				logger.Info("[OnIncomingCall] Failed to get the message: %v", err)
 				return
 			}
 
-			dCtx, dCancel := context.WithTimeout(context.Background(), 1*time.Minute)
+			dCtx, dCancel := context.WithTimeout(shutdown.Context(), 1*time.Minute)
 			defer dCancel()
-			filePath, err := msg.Download(&tg.DownloadOptions{FileName: filepath.Join(config.Conf.DownloadsDir, msg.File.Name), Ctx: dCtx})
+			filePath, err := msg.Download(&tg.DownloadOptions{FileName: dl.DownloadPath(msg.File.Name), Ctx: dCtx})
 			if err != nil {
-				c.bot.Log.Info("[OnIncomingCall] Failed to download the message: %v", err)
+				logger.Info("[OnIncomingCall] Failed to download the message: %v", err)
 				return
 			}
 
 			err = c.PlayMedia(chatID, filePath, false, "")
 			if err != nil {
-				c.bot.Log.Info("[OnIncomingCall] Failed to play the media: %v", err)
+				logger.Info("[OnIncomingCall] Failed to play the media: %v", err)
 				return
 			}
 
@@ -503,13 +670,13 @@ func (c *TelegramCalls) RegisterHandlers(client *tg.Client) {
 		})
 
 		call.OnFrame(func(chatId int64, mode ntgcalls.StreamMode, device ntgcalls.StreamDevice, frames []ntgcalls.Frame) {
-			c.bot.Log.Debug("Received frames for chatId: %d, mode: %v, device: %v", chatId, mode, device)
+			logger.Debug("Received frames for chatId: %d, mode: %v, device: %v", chatId, mode, device)
 		})
 
 		_, _ = call.App.SendMessage(client.Me().Username, "/start")
-		_, err := call.App.SendMessage(config.Conf.LoggerId, "UB has started.")
+		_, err := call.App.SendMessage(config.Get().LoggerId, "UB has started.")
 		if err != nil {
-			c.bot.Log.Info("[TelegramCalls - SendMessage] Failed to send message: %v", err)
+			logger.Info("[TelegramCalls - SendMessage] Failed to send message: %v", err)
 		}
 	}
 }
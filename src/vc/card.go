@@ -0,0 +1,126 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/thumb"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// cardTimeout caps how long fetching the images and rendering a card may take.
+const cardTimeout = 30 * time.Second
+
+// ShowCard replaces the now-playing message msgID with a card for song, keeping caption as its text. It
+// returns at once and does the work in the background, so playback never waits for it. Telegram cannot turn a
+// text message into a photo, so the card is sent as a new message and the text one deleted. Nothing changes if
+// cards are off, if the track has no cover, or if another track started meanwhile.
+func (c *TelegramCalls) ShowCard(chatID int64, msgID int32, song *cache.CachedTrack, caption string) {
+	cfg := config.Get()
+	if !cfg.Cards || song == nil || song.Thumbnail == "" {
+		return
+	}
+	track := *song
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cardTimeout)
+		defer cancel()
+
+		photo, err := c.renderCard(ctx, &track, cfg.CardFont, cfg.CardFallback)
+		if err != nil {
+			logger.Debug("[ShowCard] No card for %s in %d: %v", track.TrackID, chatID, err)
+			return
+		}
+		if !c.isNowPlaying(chatID, msgID) {
+			return
+		}
+
+		sent, err := c.bot.SendMedia(chatID, photo, &tg.MediaOptions{
+			FileName:    "now_playing.jpg",
+			Caption:     caption,
+			ReplyMarkup: core.ControlButtons("play"),
+		})
+		if err != nil {
+			logger.Warn("[ShowCard] Failed to send the card in %d: %v", chatID, err)
+			return
+		}
+
+		stale := msgID
+		if !c.swapNowPlaying(chatID, msgID, sent.ID) {
+			// The track changed while the card was uploading.
+			stale = sent.ID
+		}
+		_, _ = c.bot.DeleteMessages(chatID, []int32{stale})
+	}()
+}
+
+// renderCard returns the card for song, or its plain cover if the card cannot be rendered and fallback is set.
+// The cover is only downloaded when the card is not cached.
+func (c *TelegramCalls) renderCard(ctx context.Context, song *cache.CachedTrack, font string, fallback bool) ([]byte, error) {
+	id := song.TrackID
+	if id == "" {
+		id = song.URL
+	}
+	key := fmt.Sprintf("%s:%s:%d", song.Platform, id, song.UserID)
+
+	var cover image.Image
+	card, err := thumb.Cached(key, func() ([]byte, error) {
+		var err error
+		if cover, err = thumb.FetchImage(ctx, song.Thumbnail); err != nil {
+			return nil, err
+		}
+		return thumb.Render(thumb.Card{
+			Title:     song.Name,
+			Requester: song.User,
+			Duration:  song.Duration,
+			Cover:     cover,
+			Avatar:    c.avatar(song.UserID),
+		}, font)
+	})
+	if err == nil {
+		return card, nil
+	}
+	if !fallback || cover == nil {
+		return nil, err
+	}
+	if !errors.Is(err, thumb.ErrMissingGlyphs) {
+		logger.Warn("[renderCard] Failed to render the card for %s, sending the cover: %v", id, err)
+	}
+	return thumb.Plain(cover)
+}
+
+// avatar returns the current profile photo of userID, or nil if it has none or it cannot be downloaded.
+func (c *TelegramCalls) avatar(userID int64) image.Image {
+	if userID <= 0 {
+		return nil
+	}
+	photos, err := c.bot.GetProfilePhotos(userID, &tg.PhotosOptions{Limit: 1})
+	if err != nil || len(photos) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	if _, err := c.bot.DownloadMedia(photos[0].Photo, &tg.DownloadOptions{Buffer: &buf}); err != nil {
+		logger.Debug("[avatar] Failed to download the photo of %d: %v", userID, err)
+		return nil
+	}
+	img, err := thumb.Decode(buf.Bytes())
+	if err != nil {
+		return nil
+	}
+	return img
+}
@@ -0,0 +1,163 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"fmt"
+	"html"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/db"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// assistantUser returns the account of an assistant, or nil if it is unknown.
+func (c *TelegramCalls) assistantUser(name string) *tg.UserObj {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	client, ok := c.clients[name]
+	if !ok {
+		return nil
+	}
+	return client.Me()
+}
+
+// startCooldown persists a long restriction of an assistant, so that a restart does not hand it chats again,
+// reports it to the logger group and schedules its end.
+func (c *TelegramCalls) startCooldown(name string, health assistantHealth) {
+	me := c.assistantUser(name)
+	if me != nil && c.bot != nil {
+		ctx, cancel := db.Ctx()
+		cd := db.AssistantCooldown{Reason: health.reason, Since: health.since, Until: health.until}
+		if err := db.Instance.SetAssistantCooldown(ctx, c.bot.Me().ID, me.ID, cd); err != nil {
+			logger.Warn("[cooldown] Failed to save the cooldown of %s: %v", name, err)
+		}
+		cancel()
+	}
+	c.scheduleCooldownEnd(name, health.until)
+	c.alertCooldown(name, me, health)
+}
+
+// scheduleCooldownEnd lifts the restriction of an assistant at until.
+func (c *TelegramCalls) scheduleCooldownEnd(name string, until time.Time) {
+	time.AfterFunc(time.Until(until), func() { c.endCooldown(name, until) })
+}
+
+// endCooldown lifts the restriction of an assistant that ends at until, unless a later one replaced it, and
+// starts the chats that waited for an assistant meanwhile.
+func (c *TelegramCalls) endCooldown(name string, until time.Time) {
+	c.healthMu.Lock()
+	h, ok := c.health[name]
+	current := ok && h.until.Equal(until)
+	if current {
+		delete(c.health, name)
+	}
+	c.healthMu.Unlock()
+	if !current {
+		return
+	}
+
+	if me := c.assistantUser(name); me != nil && c.bot != nil {
+		ctx, cancel := db.Ctx()
+		if err := db.Instance.ClearAssistantCooldown(ctx, c.bot.Me().ID, me.ID); err != nil {
+			logger.Warn("[cooldown] Failed to clear the cooldown of %s: %v", name, err)
+		}
+		cancel()
+	}
+	logger.Info("[cooldown] Assistant %s has recovered.", name)
+	c.admitWaiting()
+}
+
+// loadCooldowns restores the restrictions that were in force when the bot last stopped.
+func (c *TelegramCalls) loadCooldowns() {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	botID := c.bot.Me().ID
+	cooldowns, err := db.Instance.GetAssistantCooldowns(ctx, botID)
+	if err != nil {
+		logger.Warn("[cooldown] Failed to load the assistant cooldowns: %v", err)
+		return
+	}
+
+	c.mu.RLock()
+	names := append([]string(nil), c.availableClients...)
+	c.mu.RUnlock()
+	now := time.Now()
+	for _, name := range names {
+		me := c.assistantUser(name)
+		if me == nil {
+			continue
+		}
+		cd, ok := cooldowns[me.ID]
+		if !ok {
+			continue
+		}
+		if !cd.Until.After(now) {
+			_ = db.Instance.ClearAssistantCooldown(ctx, botID, me.ID)
+			continue
+		}
+		c.healthMu.Lock()
+		c.health[name] = assistantHealth{reason: cd.Reason, since: cd.Since, until: cd.Until}
+		c.healthMu.Unlock()
+		c.scheduleCooldownEnd(name, cd.Until)
+		logger.Info("[cooldown] Assistant %s is still cooling down until %s (%s).", name, cd.Until.Format(time.RFC3339), cd.Reason)
+	}
+}
+
+// coolingDown reports whether every assistant is out of use and at least one of them will recover by itself,
+// in which case new sessions wait for it rather than fail.
+func (c *TelegramCalls) coolingDown() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cooling := false
+	for _, name := range c.availableClients {
+		if c.isHealthy(name) {
+			return false
+		}
+		c.healthMu.Lock()
+		if !c.health[name].until.IsZero() {
+			cooling = true
+		}
+		c.healthMu.Unlock()
+	}
+	return cooling
+}
+
+// alertCooldown tells the logger group that an assistant is restricted and when it recovers.
+func (c *TelegramCalls) alertCooldown(name string, me *tg.UserObj, health assistantHealth) {
+	chatID := config.Get().LoggerId
+	if chatID == 0 || c.bot == nil {
+		return
+	}
+
+	account := name
+	if me != nil {
+		account = fmt.Sprintf("%s (<code>%d</code>)", html.EscapeString(name), me.ID)
+		if me.Username != "" {
+			account = fmt.Sprintf("%s, @%s (<code>%d</code>)", html.EscapeString(name), me.Username, me.ID)
+		}
+	}
+	next := "New chats go to the other assistants."
+	if !c.hasHealthyAssistant() {
+		next = "No other assistant is available, so new chats wait in the waiting room until it recovers."
+	}
+	text := fmt.Sprintf("⚠️ <b>Assistant restricted</b>\n\n‣ <b>Assistant:</b> %s\n‣ <b>Reason:</b> %s\n‣ <b>Recovers:</b> %s UTC (in %s)\n\n%s",
+		account,
+		html.EscapeString(health.reason),
+		health.until.UTC().Format("2006-01-02 15:04"),
+		time.Until(health.until).Round(time.Minute),
+		next,
+	)
+	if _, err := c.bot.SendMessage(chatID, text); err != nil {
+		logger.Warn("[cooldown] Failed to report the cooldown of %s: %v", name, err)
+	}
+}
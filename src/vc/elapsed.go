@@ -0,0 +1,25 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import "ashokshau/tgmusic/src/core/elapsed"
+
+// Clock tells the current time. It lets ElapsedTracker be driven by a scripted clock.
+type Clock = elapsed.Clock
+
+// SystemClock is the Clock backed by time.Now.
+var SystemClock = elapsed.System
+
+// ElapsedTracker follows how far into a track playback is, accounting for pauses, seeks and speed changes.
+type ElapsedTracker = elapsed.Tracker
+
+// NewElapsedTracker returns a paused tracker at the start of a track, playing at normal speed once started.
+func NewElapsedTracker(clock Clock) *ElapsedTracker {
+	return elapsed.New(clock)
+}
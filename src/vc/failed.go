@@ -0,0 +1,33 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"context"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/shutdown"
+)
+
+// retryTimeout bounds a retried download, including the wait for a download slot.
+const retryTimeout = 3 * time.Minute
+
+// RetryFailedDownload downloads the track of a failed download again, through the same slots as playback
+// downloads, and returns the file it was saved to. Another failure is recorded like the first.
+func (c *TelegramCalls) RetryFailedDownload(f *db.FailedDownload) (string, error) {
+	ctx, cancel := context.WithTimeout(shutdown.Context(), retryTimeout)
+	defer cancel()
+	song := &cache.CachedTrack{
+		TrackID: f.TrackID, Platform: f.Platform, Name: f.Name, URL: f.URL, IsVideo: f.IsVideo,
+	}
+	filePath, _, err := DownloadSong(ctx, f.ChatID, song, c.bot)
+	return filePath, err
+}
@@ -0,0 +1,86 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"errors"
+
+	"ashokshau/tgmusic/src/core/cache"
+)
+
+// FilterPreset is a named ffmpeg audio filter applied when a stream starts.
+type FilterPreset struct {
+	Name string
+	// Audio is the ffmpeg audio filter chain.
+	Audio string
+	// Tempo is the playback rate the filter implies, or 0 if it leaves the tempo alone.
+	// Presets with a tempo override /speed, and /speed drops them again.
+	Tempo float64
+}
+
+// FilterPresets lists the available filters in menu order. Add new presets here.
+var FilterPresets = []FilterPreset{
+	{Name: "bassboost", Audio: "bass=g=10:f=110:w=0.6"},
+	{Name: "nightcore", Audio: "aresample=48000,asetrate=48000*1.25,aresample=48000", Tempo: 1.25},
+	{Name: "vaporwave", Audio: "aresample=48000,asetrate=48000*0.8,aresample=48000", Tempo: 0.8},
+	{Name: "8d", Audio: "apulsator=hz=0.125"},
+	{Name: "karaoke", Audio: "pan=stereo|c0=c0-c1|c1=c1-c0"},
+}
+
+// ErrUnknownFilter is returned when applying a filter that is not in FilterPresets.
+var ErrUnknownFilter = errors.New("unknown filter")
+
+// lookupFilter returns the preset with the given name.
+func lookupFilter(name string) (FilterPreset, bool) {
+	for _, preset := range FilterPresets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return FilterPreset{}, false
+}
+
+// Filter returns the name of the chat's active filter preset, or an empty string if none is active.
+func (c *TelegramCalls) Filter(chatID int64) string {
+	return c.position(chatID).filter
+}
+
+// ApplyFilter restarts the current track from its current position with the named filter preset.
+// The name "none" clears the active filter. The filter stays active for the following tracks until cleared
+// or until playback stops.
+func (c *TelegramCalls) ApplyFilter(chatID int64, name string) error {
+	if err := c.checkBusy(chatID); err != nil {
+		return err
+	}
+
+	unlock := c.lockPlayback(chatID)
+	defer unlock()
+
+	pos := c.position(chatID)
+	if name == "none" {
+		name = ""
+	}
+
+	preset, ok := lookupFilter(name)
+	if name != "" && !ok {
+		return ErrUnknownFilter
+	}
+
+	playingSong := cache.ChatCache.GetPlayingTrack(chatID)
+	if playingSong == nil {
+		return ErrNotPlaying
+	}
+
+	pos.offset = c.restartOffset(chatID, playingSong)
+	pos.filter = name
+	if preset.Tempo != 0 {
+		pos.speed = 1.0
+	}
+	return c.restream(chatID, playingSong, pos)
+}
@@ -0,0 +1,186 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/core/premium"
+	"ashokshau/tgmusic/src/core/workspace"
+	"ashokshau/tgmusic/src/vc/ntgcalls"
+)
+
+// ntgcalls cannot queue a second input behind the running one, so gapless playback pre-spawns the next
+// track's ffmpeg process. It opens and probes its input, then blocks on opening a named pipe until the
+// player starts reading it, which removes the ffmpeg startup from the gap between tracks.
+
+// preload is an ffmpeg process waiting to stream a chat's next track into a named pipe.
+type preload struct {
+	filePath string
+	params   string
+	fifo     string
+	cmd      *exec.Cmd
+	done     chan struct{}
+}
+
+// gaplessState holds the preloaded next track and the time the last stream ended for every chat.
+type gaplessState struct {
+	mu       sync.Mutex
+	preloads map[int64]*preload
+	endedAt  map[int64]time.Time
+}
+
+// startPreload spawns ffmpeg for filePath with the given parameters, writing to a new named pipe.
+func startPreload(filePath, params string) (*preload, error) {
+	ws, err := workspace.New("preload")
+	if err != nil {
+		return nil, err
+	}
+	fifo := ws.Path("next.pcm")
+	if err := exec.Command("mkfifo", fifo).Run(); err != nil {
+		_ = ws.Close()
+		return nil, fmt.Errorf("failed to create the pipe: %w", err)
+	}
+
+	input := strings.TrimSuffix(getMediaDescription(filePath, false, params, 0).Microphone.Input, "pipe:1")
+	cmd := exec.Command("sh", "-c", fmt.Sprintf("exec %s-y \"%s\"", input, fifo))
+	if err := cmd.Start(); err != nil {
+		_ = ws.Close()
+		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	p := &preload{filePath: filePath, params: params, fifo: fifo, cmd: cmd, done: make(chan struct{})}
+	go func() {
+		_ = cmd.Wait()
+		_ = ws.Close()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// exited reports whether the preloaded ffmpeg process has already ended.
+func (p *preload) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// discard stops the preloaded ffmpeg process; its pipe is removed once it exits.
+func (p *preload) discard() {
+	if p.cmd.Process != nil {
+		_ = p.cmd.Process.Kill()
+	}
+}
+
+// preloadNext pre-spawns the player input of the chat's upcoming track when GAPLESS_PRELOAD is on.
+// Only audio tracks whose file is already on disk are preloaded; a preload for another track is discarded.
+func (c *TelegramCalls) preloadNext(chatID int64) {
+	if !config.Get().GaplessPreload {
+		return
+	}
+
+	next := cache.ChatCache.GetUpcomingTrack(chatID)
+	var params, filePath string
+	if next != nil {
+		filePath = dl.ResolvePartial(cache.ChatCache.TrackFile(chatID, next))
+		params = streamParams(streamPosition{speed: c.nextTrackSpeed(chatID), filter: c.position(chatID).filter}, 0)
+	}
+
+	c.gapless.mu.Lock()
+	defer c.gapless.mu.Unlock()
+
+	if p, ok := c.gapless.preloads[chatID]; ok {
+		if next != nil && p.filePath == filePath && p.params == params && !p.exited() {
+			return
+		}
+		p.discard()
+		delete(c.gapless.preloads, chatID)
+	}
+
+	if next == nil || filePath == "" || next.IsVideo || growingFlags(filePath) != "" {
+		return
+	}
+
+	p, err := startPreload(filePath, params)
+	if err != nil {
+		logger.Debug("[preloadNext] Failed to preload %q in %d: %v", next.Name, chatID, err)
+		return
+	}
+	c.gapless.preloads[chatID] = p
+	logger.Debug("[preloadNext] Preloaded %q in %d", next.Name, chatID)
+}
+
+// discardPreload stops the chat's preloaded track, if any.
+func (c *TelegramCalls) discardPreload(chatID int64) {
+	c.gapless.mu.Lock()
+	defer c.gapless.mu.Unlock()
+	if p, ok := c.gapless.preloads[chatID]; ok {
+		p.discard()
+		delete(c.gapless.preloads, chatID)
+	}
+	delete(c.gapless.endedAt, chatID)
+}
+
+// mediaDescription returns the player input for filePath, reading from the chat's preloaded ffmpeg
+// process when it was started for the same file and parameters. It reports whether the preload was used.
+func (c *TelegramCalls) mediaDescription(chatID int64, filePath string, video bool, ffmpegParameters string) (ntgcalls.MediaDescription, bool) {
+	c.gapless.mu.Lock()
+	p, ok := c.gapless.preloads[chatID]
+	delete(c.gapless.preloads, chatID)
+	c.gapless.mu.Unlock()
+
+	if ok {
+		if !video && p.filePath == filePath && p.params == ffmpegParameters && !p.exited() {
+			desc := getMediaDescription(filePath, false, ffmpegParameters, 0)
+			desc.Microphone.Input = fmt.Sprintf("cat \"%s\"", p.fifo)
+			return desc, true
+		}
+		p.discard()
+	}
+	return getMediaDescription(filePath, video, ffmpegParameters, c.videoHeight(chatID)), false
+}
+
+// videoHeight returns the highest resolution the chat's current track may stream at, which depends on
+// whether its requester has premium.
+func (c *TelegramCalls) videoHeight(chatID int64) int {
+	var userID int64
+	if song := cache.ChatCache.GetPlayingTrack(chatID); song != nil {
+		userID = song.UserID
+	}
+	return premium.For(userID).VideoHeight
+}
+
+// markStreamEnded records when the chat's stream ended so that the gap to the next track can be measured.
+func (c *TelegramCalls) markStreamEnded(chatID int64) {
+	c.gapless.mu.Lock()
+	defer c.gapless.mu.Unlock()
+	c.gapless.endedAt[chatID] = time.Now()
+}
+
+// logGap logs how long the chat went silent between the end of the last stream and the start of this one.
+func (c *TelegramCalls) logGap(chatID int64, preloaded bool) {
+	c.gapless.mu.Lock()
+	endedAt, ok := c.gapless.endedAt[chatID]
+	delete(c.gapless.endedAt, chatID)
+	c.gapless.mu.Unlock()
+
+	if ok {
+		logger.Info("[gap] Chat %d: %s between tracks (preloaded=%v)", chatID, time.Since(endedAt).Round(time.Millisecond), preloaded)
+	}
+}
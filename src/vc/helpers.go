@@ -10,15 +10,17 @@ package vc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
-	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"ashokshau/tgmusic/src/config"
 	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/counters"
+	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/core/dl"
 	"ashokshau/tgmusic/src/vc/ntgcalls"
 
@@ -26,15 +28,16 @@ import (
 )
 
 func getVideoDimensions(filePath string) (int, int) {
+	defer logger.Time(context.Background(), "exec", "ffprobe", filePath)()
 	cmd := exec.Command("ffprobe", "-v", "error", "-select_streams", "v:0", "-show_entries", "stream=width,height", "-of", "csv=s=x:p=0", filePath)
 	out, err := cmd.Output()
 	if err != nil {
-		logger.Warnf("[getVideoDimensions] Failed to get video dimensions (%s): %v", filePath, err)
+		logger.Warn("[getVideoDimensions] Failed to get video dimensions (%s): %v", filePath, err)
 		return 0, 0
 	}
 	dimensions := strings.Split(strings.TrimSpace(string(out)), "x")
 	if len(dimensions) != 2 {
-		logger.Warnf("[getVideoDimensions] Invalid video dimensions(%s): %s", filePath, string(out))
+		logger.Warn("[getVideoDimensions] Invalid video dimensions(%s): %s", filePath, string(out))
 		return 0, 0
 	}
 
@@ -46,7 +49,8 @@ func getVideoDimensions(filePath string) (int, int) {
 var isURLRegex = regexp.MustCompile(`^https?://`)
 
 // getMediaDescription creates a media description for ntgcalls based on the provided file path, video status, and ffmpeg parameters.
-func getMediaDescription(filePath string, isVideo bool, ffmpegParameters string) ntgcalls.MediaDescription {
+// Video is scaled down to fit a 16:9 frame maxHeight lines high.
+func getMediaDescription(filePath string, isVideo bool, ffmpegParameters string, maxHeight int) ntgcalls.MediaDescription {
 	audioDescription := &ntgcalls.AudioDescription{
 		MediaSource:  ntgcalls.MediaSourceShell,
 		SampleRate:   96000,
@@ -63,12 +67,11 @@ func getMediaDescription(filePath string, isVideo bool, ffmpegParameters string)
 	}
 
 	var seekFlags, filterFlags string
-	if ffmpegParameters != "" {
-		if strings.Contains(ffmpegParameters, "filter:") {
-			filterFlags = ffmpegParameters
-		} else {
-			seekFlags = ffmpegParameters
-		}
+	if idx := strings.Index(ffmpegParameters, "-filter:"); idx >= 0 {
+		seekFlags = strings.TrimSpace(ffmpegParameters[:idx])
+		filterFlags = ffmpegParameters[idx:]
+	} else {
+		seekFlags = ffmpegParameters
 	}
 
 	if seekFlags != "" {
@@ -94,8 +97,8 @@ func getMediaDescription(filePath string, isVideo bool, ffmpegParameters string)
 
 	originalWidth, originalHeight := getVideoDimensions(filePath)
 
-	width := 1280
-	height := 720
+	height := maxHeight
+	width := height * 16 / 9
 
 	if originalWidth > 0 && originalHeight > 0 {
 		ratio := float64(originalWidth) / float64(originalHeight)
@@ -157,15 +160,70 @@ func getMediaDescription(filePath string, isVideo bool, ffmpegParameters string)
 var telegramMessageRegex = regexp.MustCompile(`t\.me/(\w+)/(\d+)`)
 
 // DownloadSong downloads a song using the provided cached track information.
+// Downloads share a global pool of slots sized by MAX_CONCURRENT_DOWNLOADS, and failures are recorded for
+// /failed against chatID.
 // It returns the file path, track information, and an error if the download fails.
-func DownloadSong(ctx context.Context, song *cache.CachedTrack, bot *telegram.Client) (string, *cache.TrackInfo, error) {
+func DownloadSong(ctx context.Context, chatID int64, song *cache.CachedTrack, bot *telegram.Client) (string, *cache.TrackInfo, error) {
+	filePath, info, err := downloadSong(ctx, song, bot)
+	if err != nil && ctx.Err() == nil {
+		class := downloadErrorClass(err)
+		counters.Add(counters.DownloadFailures, class, 1)
+		go recordFailedDownload(chatID, song, class, err)
+	}
+	return filePath, info, err
+}
+
+// Download error classes that a retry cannot fix.
+var terminalDownloadClasses = map[string]bool{"removed": true, "unavailable": true, "invalid link": true}
+
+// recordFailedDownload stores a failed download for /failed.
+func recordFailedDownload(chatID int64, song *cache.CachedTrack, class string, err error) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	f := &db.FailedDownload{
+		TrackID: song.TrackID, Platform: song.Platform, Name: song.Name, URL: song.URL, IsVideo: song.IsVideo,
+		Class: class, Error: config.Redact(err.Error()), Terminal: terminalDownloadClasses[class], ChatID: chatID,
+	}
+	if err := db.Instance.AddFailedDownload(ctx, f); err != nil {
+		logger.Warn("[DownloadSong] Failed to record the failed download of %q: %v", song.Name, err)
+	}
+}
+
+// downloadErrorClass sorts a download error into a broad class for the daily digest.
+func downloadErrorClass(err error) string {
+	text := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, dl.ErrDownloadStalled), strings.Contains(text, "timeout"):
+		return "timeout"
+	case strings.Contains(text, "429"), strings.Contains(text, "rate limit"), strings.Contains(text, "too many requests"):
+		return "rate limited"
+	case strings.Contains(text, "private"), strings.Contains(text, "removed"), strings.Contains(text, "copyright"), strings.Contains(text, "terminated"):
+		return "removed"
+	case strings.Contains(text, "403"), strings.Contains(text, "sign in"), strings.Contains(text, "confirm you"):
+		return "blocked"
+	case strings.Contains(text, "404"), strings.Contains(text, "not found"), strings.Contains(text, "unavailable"), strings.Contains(text, "no results"):
+		return "unavailable"
+	case strings.Contains(text, "invalid"):
+		return "invalid link"
+	default:
+		return "other"
+	}
+}
+
+func downloadSong(ctx context.Context, song *cache.CachedTrack, bot *telegram.Client) (string, *cache.TrackInfo, error) {
+	release, err := acquireDownloadSlot(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	defer release()
+
 	if song.Platform == cache.Telegram {
 		file, err := telegram.ResolveBotFileID(song.TrackID)
 		if err != nil {
 			return "", nil, err
 		}
 
-		filePath, err := bot.DownloadMedia(file, &telegram.DownloadOptions{FileName: filepath.Join(config.Conf.DownloadsDir, song.Name), Ctx: ctx})
+		filePath, err := bot.DownloadMedia(file, &telegram.DownloadOptions{FileName: dl.DownloadPath(song.Name), Ctx: ctx})
 		return filePath, nil, err
 	}
 
@@ -175,7 +233,7 @@ func DownloadSong(ctx context.Context, song *cache.CachedTrack, bot *telegram.Cl
 	if wrapper.IsValid() {
 		trackInfo, err := wrapper.GetTrack(ctx)
 		if err != nil {
-			logger.Info("[DownloadSong] Failed to get track information: %v", err)
+			logger.Ctx(ctx).Info("[DownloadSong] Failed to get track information: %v", err)
 			return "", nil, err
 		}
 
@@ -187,7 +245,7 @@ func DownloadSong(ctx context.Context, song *cache.CachedTrack, bot *telegram.Cl
 			}
 
 			fileName := msg.File.Name
-			download, err := msg.Download(&telegram.DownloadOptions{FileName: filepath.Join(config.Conf.DownloadsDir, fileName), Ctx: ctx})
+			download, err := msg.Download(&telegram.DownloadOptions{FileName: dl.DownloadPath(fileName), Ctx: ctx})
 			if err != nil {
 				return "", &trackInfo, fmt.Errorf("failed to download %s: %w", trackInfo.Name, err)
 			}
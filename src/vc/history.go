@@ -0,0 +1,73 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"errors"
+	"os"
+
+	"ashokshau/tgmusic/src/core/cache"
+)
+
+// ErrNoPrevious is returned by Previous when no track has finished in the chat yet.
+var ErrNoPrevious = errors.New("no previous track")
+
+// Replay restarts the current track from the beginning, keeping the chat's speed and filter.
+func (c *TelegramCalls) Replay(chatID int64) error {
+	if err := c.checkBusy(chatID); err != nil {
+		return err
+	}
+
+	unlock := c.lockPlayback(chatID)
+	defer unlock()
+
+	song := cache.ChatCache.GetPlayingTrack(chatID)
+	if song == nil {
+		return ErrNotPlaying
+	}
+
+	pos := c.position(chatID)
+	pos.offset = 0
+	return c.restream(chatID, song, pos)
+}
+
+// Previous puts the most recently finished track back at the front of the queue and switches to it.
+// The interrupted track is dropped like a skip and is not added to the history, so repeated calls walk further back.
+func (c *TelegramCalls) Previous(chatID int64) (*cache.CachedTrack, error) {
+	if err := c.checkBusy(chatID); err != nil {
+		return nil, err
+	}
+
+	unlock := c.lockPlayback(chatID)
+	defer unlock()
+
+	if cache.ChatCache.GetPlayingTrack(chatID) == nil {
+		return nil, ErrNotPlaying
+	}
+
+	prev := cache.History.Pop(chatID)
+	if prev == nil {
+		return nil, ErrNoPrevious
+	}
+
+	// The file may have been cleaned up since the track finished; clearing the path makes playSong download it again.
+	if prev.FilePath != "" {
+		if _, err := os.Stat(prev.FilePath); err != nil {
+			prev.FilePath = ""
+		}
+	}
+
+	if err := cache.ChatCache.InsertNext(chatID, prev); err != nil {
+		cache.History.Push(chatID, prev)
+		return nil, err
+	}
+
+	cache.ChatCache.SetLoopCount(chatID, 0)
+	return prev, c.advanceQueue(chatID, false)
+}
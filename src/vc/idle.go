@@ -0,0 +1,229 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/cleaner"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+)
+
+// aloneCheckInterval is how often the participant count of active calls is polled.
+const aloneCheckInterval = 30 * time.Second
+
+// IsIdle reports whether the assistant is still in the chat's voice chat waiting for new tracks.
+func (c *TelegramCalls) IsIdle(chatID int64) bool {
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+	_, ok := c.idleTimers[chatID]
+	return ok
+}
+
+// startIdleTimer keeps the assistant in the voice chat after the queue ends and
+// leaves once the configured inactivity timeout passes without a new track.
+// Chats in 24/7 radio mode are held indefinitely instead; their entry in idleTimers is nil.
+func (c *TelegramCalls) startIdleTimer(chatID int64) {
+	if radio, _ := c.isRadio(chatID); radio {
+		cache.ChatCache.SetActive(chatID, false)
+		c.idleMu.Lock()
+		defer c.idleMu.Unlock()
+		if t := c.idleTimers[chatID]; t != nil {
+			t.Stop()
+		}
+		c.idleTimers[chatID] = nil
+		return
+	}
+
+	timeout := time.Duration(config.Get().IdleLeaveTimeout) * time.Second
+	if timeout <= 0 {
+		_ = c.Stop(chatID)
+		return
+	}
+
+	cache.ChatCache.SetActive(chatID, false)
+
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+	if t := c.idleTimers[chatID]; t != nil {
+		t.Stop()
+	}
+	c.idleTimers[chatID] = time.AfterFunc(timeout, func() {
+		c.idleMu.Lock()
+		delete(c.idleTimers, chatID)
+		c.idleMu.Unlock()
+
+		if cache.ChatCache.IsActive(chatID) {
+			return
+		}
+		c.leaveWithNotice(chatID, "left_inactive")
+	})
+}
+
+// cancelIdleTimer stops a pending inactivity timer, typically because a new track is starting.
+func (c *TelegramCalls) cancelIdleTimer(chatID int64) {
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+	if t, ok := c.idleTimers[chatID]; ok {
+		if t != nil {
+			t.Stop()
+		}
+		delete(c.idleTimers, chatID)
+	}
+}
+
+// leaveWithNotice leaves the chat's voice chat and posts a short notice explaining why.
+func (c *TelegramCalls) leaveWithNotice(chatID int64, key string) {
+	logger.Info("[TelegramCalls] Leaving the voice chat in %d (%s).", chatID, key)
+	_ = c.Stop(chatID)
+	c.notify(chatID, key)
+}
+
+// IsAutoPaused reports whether the chat's stream was paused because nobody was listening.
+func (c *TelegramCalls) IsAutoPaused(chatID int64) bool {
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+	_, ok := c.autoPaused[chatID]
+	return ok
+}
+
+// clearAutoPause forgets that the chat's stream was paused automatically.
+func (c *TelegramCalls) clearAutoPause(chatID int64) {
+	c.idleMu.Lock()
+	defer c.idleMu.Unlock()
+	delete(c.autoPaused, chatID)
+}
+
+// autoPause pauses a stream that is playing to an empty voice chat and tells the chat why.
+func (c *TelegramCalls) autoPause(chatID int64) {
+	if state, _ := c.State(chatID); state != StatePlaying {
+		return
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if !db.Instance.GetAutoPause(ctx, chatID) {
+		return
+	}
+
+	if _, err := c.Pause(chatID); err != nil {
+		logger.Warn("[autoPause] Failed to pause %d: %v", chatID, err)
+		return
+	}
+
+	c.idleMu.Lock()
+	c.autoPaused[chatID] = time.Now()
+	c.idleMu.Unlock()
+
+	c.notify(chatID, "auto_paused")
+}
+
+// autoResume resumes a stream that was paused automatically once someone joins again.
+func (c *TelegramCalls) autoResume(chatID int64) {
+	if _, err := c.Resume(chatID); err != nil {
+		logger.Warn("[autoResume] Failed to resume %d: %v", chatID, err)
+		c.clearAutoPause(chatID)
+		return
+	}
+	c.notify(chatID, "auto_resumed")
+}
+
+// notify posts a short, auto-deleted notice to the chat.
+func (c *TelegramCalls) notify(chatID int64, key string) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	if msg, err := c.bot.SendMessage(chatID, lang.GetString(langCode, key)); err == nil {
+		cleaner.ScheduleChat(c.bot, chatID, msg.ID)
+	}
+}
+
+// watchAlone periodically checks the participants of every joined voice chat.
+// Streams playing to nobody are paused after AutoPauseAfter and resumed when someone joins; an auto-paused
+// stream that nobody returns to within IdleLeaveTimeout is left. Independently, the assistant leaves
+// chats where it has been alone for longer than AloneLeaveTimeout. Chats in 24/7 radio mode are left alone.
+func (c *TelegramCalls) watchAlone() {
+	leaveAfter := time.Duration(config.Get().AloneLeaveTimeout) * time.Second
+	pauseAfter := time.Duration(config.Get().AutoPauseAfter) * time.Second
+	if leaveAfter <= 0 && pauseAfter <= 0 {
+		return
+	}
+	idleTimeout := time.Duration(config.Get().IdleLeaveTimeout) * time.Second
+
+	aloneSince := make(map[int64]time.Time)
+	ticker := time.NewTicker(aloneCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		chats := make(map[int64]struct{})
+		for _, chatID := range cache.ChatCache.GetActiveChats() {
+			chats[chatID] = struct{}{}
+		}
+		c.idleMu.Lock()
+		for chatID := range c.idleTimers {
+			chats[chatID] = struct{}{}
+		}
+		c.idleMu.Unlock()
+
+		for chatID := range aloneSince {
+			if _, ok := chats[chatID]; !ok {
+				delete(aloneSince, chatID)
+			}
+		}
+
+		for chatID := range chats {
+			if radio, _ := c.isRadio(chatID); radio {
+				delete(aloneSince, chatID)
+				continue
+			}
+
+			listeners, err := c.ListenerCount(chatID)
+			if err != nil {
+				delete(aloneSince, chatID)
+				continue
+			}
+
+			if listeners > 0 {
+				delete(aloneSince, chatID)
+				if c.IsAutoPaused(chatID) {
+					c.autoResume(chatID)
+				}
+				continue
+			}
+
+			since, ok := aloneSince[chatID]
+			if !ok {
+				aloneSince[chatID] = time.Now()
+				continue
+			}
+
+			if leaveAfter > 0 && time.Since(since) >= leaveAfter {
+				delete(aloneSince, chatID)
+				c.cancelIdleTimer(chatID)
+				c.leaveWithNotice(chatID, "left_alone")
+				continue
+			}
+
+			c.idleMu.Lock()
+			pausedAt, paused := c.autoPaused[chatID]
+			c.idleMu.Unlock()
+
+			switch {
+			case paused && time.Since(pausedAt) >= idleTimeout:
+				delete(aloneSince, chatID)
+				c.leaveWithNotice(chatID, "left_inactive")
+			case !paused && pauseAfter > 0 && time.Since(since) >= pauseAfter:
+				c.autoPause(chatID)
+			}
+		}
+	}
+}
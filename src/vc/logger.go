@@ -20,7 +20,7 @@ import (
 // sendLogger sends a formatted log message to the designated logger chat.
 // It includes details about the song being played, such as its title, duration, and the user who requested it.
 func sendLogger(client *tg.Client, chatID int64, song *cache.CachedTrack) {
-	if chatID == 0 || song == nil || chatID == config.Conf.LoggerId {
+	if chatID == 0 || song == nil || chatID == config.Get().LoggerId {
 		return
 	}
 
@@ -35,7 +35,7 @@ func sendLogger(client *tg.Client, chatID int64, song *cache.CachedTrack) {
 		song.IsVideo,
 	)
 
-	_, err := client.SendMessage(config.Conf.LoggerId, text, &tg.SendOptions{LinkPreview: false})
+	_, err := client.SendMessage(config.Get().LoggerId, text, &tg.SendOptions{LinkPreview: false})
 	if err != nil {
 		logger.Warn("[sendLogger] Failed to send the message: %v", err)
 	}
@@ -0,0 +1,232 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+)
+
+var (
+	// ErrMirrorSelf is returned when linking a chat to itself.
+	ErrMirrorSelf = errors.New("a chat cannot mirror itself")
+	// ErrMirrorChain is returned when a link would make a source follow another chat, or a target lead one.
+	ErrMirrorChain = errors.New("links cannot be chained")
+	// ErrMirrorTargetBusy is returned when the target chat is playing its own queue.
+	ErrMirrorTargetBusy = errors.New("the target chat is playing its own queue")
+	// ErrNotMirrored is returned when unlinking a chat that is not a target.
+	ErrNotMirrored = errors.New("the chat is not mirroring another chat")
+)
+
+// mirrorState holds the mirror links, keyed by target chat.
+type mirrorState struct {
+	mu    sync.Mutex
+	links map[int64]*db.MirrorLink
+}
+
+// loadMirrors reads the mirror links saved by previous runs.
+func (c *TelegramCalls) loadMirrors() {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	links, err := db.Instance.GetMirrorLinks(ctx)
+	if err != nil {
+		logger.Warn("[loadMirrors] Failed to load mirror links: %v", err)
+		return
+	}
+
+	c.mirrors.mu.Lock()
+	defer c.mirrors.mu.Unlock()
+	for i := range links {
+		c.mirrors.links[links[i].Target] = &links[i]
+	}
+}
+
+// Link makes target play whatever source plays from now on, starting with source's current track.
+func (c *TelegramCalls) Link(source, target, by int64) error {
+	if source == target {
+		return ErrMirrorSelf
+	}
+	if cache.ChatCache.IsActive(target) {
+		return ErrMirrorTargetBusy
+	}
+
+	c.mirrors.mu.Lock()
+	_, sourceFollows := c.mirrors.links[source]
+	targetLeads := slices.ContainsFunc(c.linksLocked(), func(l db.MirrorLink) bool { return l.Source == target })
+	if sourceFollows || targetLeads {
+		c.mirrors.mu.Unlock()
+		return ErrMirrorChain
+	}
+	c.mirrors.mu.Unlock()
+
+	link := &db.MirrorLink{Target: target, Source: source, CreatedBy: by, CreatedAt: time.Now()}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if err := db.Instance.SaveMirrorLink(ctx, link); err != nil {
+		return err
+	}
+
+	c.mirrors.mu.Lock()
+	c.mirrors.links[target] = link
+	c.mirrors.mu.Unlock()
+
+	if song := cache.ChatCache.GetPlayingTrack(source); song != nil {
+		if filePath := cache.ChatCache.TrackFile(source, song); filePath != "" {
+			pos := c.position(source)
+			pos.offset = c.restartOffset(source, song)
+			go c.playMirror(target, filePath, song.IsVideo, streamParams(pos, song.Duration))
+		}
+	}
+	return nil
+}
+
+// Unlink stops target from mirroring its source and ends its stream.
+func (c *TelegramCalls) Unlink(target int64) error {
+	c.mirrors.mu.Lock()
+	_, ok := c.mirrors.links[target]
+	delete(c.mirrors.links, target)
+	c.mirrors.mu.Unlock()
+	if !ok {
+		return ErrNotMirrored
+	}
+
+	c.stopMirror(target)
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return db.Instance.DeleteMirrorLink(ctx, target)
+}
+
+// MirrorLinks returns a copy of every mirror link, ordered by source and then target.
+func (c *TelegramCalls) MirrorLinks() []db.MirrorLink {
+	c.mirrors.mu.Lock()
+	defer c.mirrors.mu.Unlock()
+	links := c.linksLocked()
+	slices.SortFunc(links, func(a, b db.MirrorLink) int {
+		if a.Source != b.Source {
+			return cmp.Compare(a.Source, b.Source)
+		}
+		return cmp.Compare(a.Target, b.Target)
+	})
+	return links
+}
+
+// linksLocked copies the links; c.mirrors.mu must be held.
+func (c *TelegramCalls) linksLocked() []db.MirrorLink {
+	links := make([]db.MirrorLink, 0, len(c.mirrors.links))
+	for _, l := range c.mirrors.links {
+		links = append(links, *l)
+	}
+	return links
+}
+
+// MirrorSource returns the chat that chatID mirrors, or 0 if it mirrors none.
+func (c *TelegramCalls) MirrorSource(chatID int64) int64 {
+	c.mirrors.mu.Lock()
+	defer c.mirrors.mu.Unlock()
+	if link, ok := c.mirrors.links[chatID]; ok {
+		return link.Source
+	}
+	return 0
+}
+
+// mirrorTargets returns the chats mirroring source.
+func (c *TelegramCalls) mirrorTargets(source int64) []int64 {
+	c.mirrors.mu.Lock()
+	defer c.mirrors.mu.Unlock()
+	var targets []int64
+	for target, link := range c.mirrors.links {
+		if link.Source == source {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// mirrorPlay starts the stream source just started in each of its targets. Targets play in the background
+// so that a slow or broken target never holds the source back.
+func (c *TelegramCalls) mirrorPlay(source int64, filePath string, video bool, ffmpegParameters string) {
+	for _, target := range c.mirrorTargets(source) {
+		go c.playMirror(target, filePath, video, ffmpegParameters)
+	}
+}
+
+// playMirror streams into a target chat, marking its link degraded if that fails and healthy once it works.
+func (c *TelegramCalls) playMirror(target int64, filePath string, video bool, ffmpegParameters string) {
+	if _, err := c.playMedia(target, filePath, video, ffmpegParameters); err != nil {
+		c.degradeMirror(target, err.Error())
+		return
+	}
+	c.degradeMirror(target, "")
+}
+
+// mirrorPause pauses or resumes the targets of source along with it.
+func (c *TelegramCalls) mirrorPause(source int64, pause bool) {
+	for _, target := range c.mirrorTargets(source) {
+		call, err := c.GetGroupAssistant(target)
+		if err != nil {
+			continue
+		}
+		if pause {
+			_, err = call.Pause(target)
+		} else {
+			_, err = call.Resume(target)
+		}
+		if err != nil {
+			logger.Debug("[mirrorPause] Failed to pause=%t the mirror %d of %d: %v", pause, target, source, err)
+		}
+	}
+}
+
+// stopMirrors ends the streams of source's targets when source stops playing. The links stay in place.
+func (c *TelegramCalls) stopMirrors(source int64) {
+	for _, target := range c.mirrorTargets(source) {
+		go c.stopMirror(target)
+	}
+}
+
+// stopMirror ends the stream of a target chat.
+func (c *TelegramCalls) stopMirror(target int64) {
+	call, err := c.GetGroupAssistant(target)
+	if err != nil {
+		return
+	}
+	if err := call.Stop(target); err != nil {
+		logger.Debug("[stopMirror] Failed to stop the mirror in %d: %v", target, err)
+	}
+}
+
+// degradeMirror records why a target stopped following its source, or clears the reason if reason is
+// empty. Only changes are saved and logged.
+func (c *TelegramCalls) degradeMirror(target int64, reason string) {
+	c.mirrors.mu.Lock()
+	link, ok := c.mirrors.links[target]
+	if !ok || link.Degraded == reason {
+		c.mirrors.mu.Unlock()
+		return
+	}
+	link.Degraded = reason
+	c.mirrors.mu.Unlock()
+
+	if reason != "" {
+		logger.Warn("[mirror] The mirror %d of %d is degraded: %s", target, link.Source, reason)
+	} else {
+		logger.Info("[mirror] The mirror %d of %d is following again.", target, link.Source)
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if err := db.Instance.SetMirrorDegraded(ctx, target, reason); err != nil {
+		logger.Warn("[mirror] Failed to save the state of the mirror %d: %v", target, err)
+	}
+}
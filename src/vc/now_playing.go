@@ -0,0 +1,51 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"ashokshau/tgmusic/src/core/cleaner"
+)
+
+// SetNowPlayingMessage records the message ID of the now-playing card for a chat,
+// so it can be cleaned up once the track finishes.
+func (c *TelegramCalls) SetNowPlayingMessage(chatID int64, msgID int32) {
+	c.nowPlayingMu.Lock()
+	defer c.nowPlayingMu.Unlock()
+	c.nowPlaying[chatID] = msgID
+}
+
+// isNowPlaying reports whether msgID is still the chat's now-playing message.
+func (c *TelegramCalls) isNowPlaying(chatID int64, msgID int32) bool {
+	c.nowPlayingMu.Lock()
+	defer c.nowPlayingMu.Unlock()
+	return c.nowPlaying[chatID] == msgID
+}
+
+// swapNowPlaying makes next the chat's now-playing message if it still is prev, and reports whether it did.
+func (c *TelegramCalls) swapNowPlaying(chatID int64, prev, next int32) bool {
+	c.nowPlayingMu.Lock()
+	defer c.nowPlayingMu.Unlock()
+	if c.nowPlaying[chatID] != prev {
+		return false
+	}
+	c.nowPlaying[chatID] = next
+	return true
+}
+
+// retireNowPlaying schedules the chat's current now-playing card for deletion according to its clean mode.
+func (c *TelegramCalls) retireNowPlaying(chatID int64) {
+	c.nowPlayingMu.Lock()
+	msgID, ok := c.nowPlaying[chatID]
+	delete(c.nowPlaying, chatID)
+	c.nowPlayingMu.Unlock()
+
+	if ok {
+		cleaner.ScheduleChat(c.bot, chatID, msgID)
+	}
+}
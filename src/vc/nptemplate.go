@@ -0,0 +1,101 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+)
+
+// NowPlayingTemplateMax is the longest now-playing template a chat may set, in characters.
+const NowPlayingTemplateMax = 1024
+
+// NowPlayingPlaceholders are the placeholders a now-playing template may use.
+var NowPlayingPlaceholders = []string{"title", "duration", "requester", "platform", "queue_len"}
+
+var (
+	// ErrTemplateTooLong is returned for a now-playing template longer than NowPlayingTemplateMax.
+	ErrTemplateTooLong = errors.New("template too long")
+	// ErrTemplateEmpty is returned for an empty now-playing template.
+	ErrTemplateEmpty = errors.New("template empty")
+)
+
+// placeholderRe matches a {placeholder} in a now-playing template.
+var placeholderRe = regexp.MustCompile(`\{([A-Za-z0-9_]*)\}`)
+
+// UnknownPlaceholder is returned by ValidateNowPlayingTemplate for a placeholder it does not know.
+type UnknownPlaceholder struct {
+	Name string
+}
+
+func (e *UnknownPlaceholder) Error() string {
+	return fmt.Sprintf("unknown placeholder {%s}", e.Name)
+}
+
+// ValidateNowPlayingTemplate checks that tmpl is neither empty nor too long and only uses known placeholders.
+func ValidateNowPlayingTemplate(tmpl string) error {
+	if tmpl == "" {
+		return ErrTemplateEmpty
+	}
+	if utf8.RuneCountInString(tmpl) > NowPlayingTemplateMax {
+		return ErrTemplateTooLong
+	}
+	for _, match := range placeholderRe.FindAllStringSubmatch(tmpl, -1) {
+		known := false
+		for _, name := range NowPlayingPlaceholders {
+			if match[1] == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return &UnknownPlaceholder{Name: match[1]}
+		}
+	}
+	return nil
+}
+
+// RenderNowPlayingTemplate fills the placeholders of tmpl for song, with queueLen tracks waiting after it.
+// The template itself is HTML written by the chat's admins, but the values are escaped, since titles and
+// requester names come from outside the bot.
+func RenderNowPlayingTemplate(tmpl string, song *cache.CachedTrack, queueLen int) string {
+	values := map[string]string{
+		"title":     html.EscapeString(song.Name),
+		"duration":  cache.SecToMin(song.Duration),
+		"requester": html.EscapeString(song.User),
+		"platform":  html.EscapeString(cache.PlatformName(song.Platform)),
+		"queue_len": strconv.Itoa(queueLen),
+	}
+	return placeholderRe.ReplaceAllStringFunc(tmpl, func(match string) string {
+		if v, ok := values[match[1:len(match)-1]]; ok {
+			return v
+		}
+		return match
+	})
+}
+
+// NowPlayingText returns the now-playing announcement for song in chatID: the chat's template if it set
+// one, otherwise the text of the lang key defaultKey followed by the platform.
+func (c *TelegramCalls) NowPlayingText(chatID int64, song *cache.CachedTrack, defaultKey, langCode string) string {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if tmpl := db.Instance.GetNowPlayingTemplate(ctx, chatID); tmpl != "" {
+		return RenderNowPlayingTemplate(tmpl, song, max(0, cache.ChatCache.GetQueueLength(chatID)-1))
+	}
+	return fmt.Sprintf(lang.GetString(langCode, defaultKey), song.URL, song.Name, cache.SecToMin(song.Duration), song.User) +
+		fmt.Sprintf(lang.GetString(langCode, "now_playing_platform"), cache.PlatformName(song.Platform))
+}
@@ -0,0 +1,316 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"expvar"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/counters"
+	"ashokshau/tgmusic/src/core/db"
+)
+
+// playStatsFlushInterval is how often the playback counters collected in memory are written to the database.
+const playStatsFlushInterval = time.Minute
+
+// trackPlays counts the plays of one track that have not been flushed yet.
+type trackPlays struct {
+	name  string
+	plays int64
+}
+
+// playDelta holds the playback counters of one chat that have not been flushed yet.
+type playDelta struct {
+	streamed  time.Duration
+	completed int64
+	skipped   int64
+	tracks    map[string]*trackPlays
+}
+
+// playStats collects playback counters from the state machine's transitions.
+type playStats struct {
+	mu           sync.Mutex
+	pending      map[int64]*playDelta
+	playingSince map[int64]time.Time
+	completing   map[int64]bool
+	requests     map[requestKey]int64 // requests counts the playback requests of each user in each chat.
+	flushed      playTotals           // flushed sums every counter flushed since the bot started.
+}
+
+// playTotals are bot-wide playback counters, published as the "playstats" expvar.
+type playTotals struct {
+	StreamedSeconds int64 `json:"streamed_seconds"`
+	Completed       int64 `json:"completed"`
+	Skipped         int64 `json:"skipped"`
+	Requests        int64 `json:"requests"`
+}
+
+// requestKey identifies the requests of one user in one chat.
+type requestKey struct {
+	chatID int64
+	userID int64
+}
+
+// delta returns the unflushed counters of a chat. The caller must hold s.mu.
+func (s *playStats) delta(chatID int64) *playDelta {
+	d, ok := s.pending[chatID]
+	if !ok {
+		d = &playDelta{tracks: make(map[string]*trackPlays)}
+		s.pending[chatID] = d
+	}
+	return d
+}
+
+// markCompleted records that the chat's current track played to its end,
+// so that the following transition counts it as completed rather than skipped.
+func (c *TelegramCalls) markCompleted(chatID int64) {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	c.stats.completing[chatID] = true
+}
+
+// recordTransition updates the playback counters for a state change.
+// Time spent playing is counted when the chat leaves StatePlaying, and the track that was on
+// counts as completed or skipped when the chat moves on to the next one.
+func (c *TelegramCalls) recordTransition(chatID int64, from, to PlaybackState) {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+
+	now := time.Now()
+	if from == StatePlaying {
+		if since, ok := c.stats.playingSince[chatID]; ok {
+			c.stats.delta(chatID).streamed += now.Sub(since)
+			delete(c.stats.playingSince, chatID)
+		}
+	}
+	if to == StatePlaying {
+		c.stats.playingSince[chatID] = now
+	}
+
+	if to != StateTransitioning && to != StateIdle {
+		return
+	}
+	completed := c.stats.completing[chatID]
+	delete(c.stats.completing, chatID)
+	if to != StateTransitioning || (from != StatePlaying && from != StatePaused) {
+		return
+	}
+
+	track := cache.ChatCache.GetPlayingTrack(chatID)
+	if track != nil {
+		counters.Add(counters.Plays, track.Platform, 1)
+	}
+
+	d := c.stats.delta(chatID)
+	if !completed {
+		d.skipped++
+		return
+	}
+	d.completed++
+	if track != nil && track.TrackID != "" {
+		tp, ok := d.tracks[track.TrackID]
+		if !ok {
+			tp = &trackPlays{name: track.Name}
+			d.tracks[track.TrackID] = tp
+		}
+		tp.plays++
+	}
+}
+
+// RecordRequest counts a playback request by userID in chatID. Requests without a user, passed as 0, are not
+// counted.
+func (c *TelegramCalls) RecordRequest(chatID, userID int64) {
+	if userID == 0 {
+		return
+	}
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	c.stats.requests[requestKey{chatID: chatID, userID: userID}]++
+}
+
+// ForgetUser removes userID from the data held in memory: their unflushed request counters and the requester
+// fields of queued and finished tracks. It must run before their stored data is deleted, so that a flush
+// cannot write their counters back.
+func (c *TelegramCalls) ForgetUser(userID int64) {
+	c.stats.mu.Lock()
+	for key := range c.stats.requests {
+		if key.userID == userID {
+			delete(c.stats.requests, key)
+		}
+	}
+	c.stats.mu.Unlock()
+
+	cache.ChatCache.ForgetUser(userID)
+	cache.History.ForgetUser(userID)
+}
+
+// takePlayStats returns the unflushed counters of every chat and user and starts collecting anew.
+// Chats that are still playing have their time so far counted as well.
+func (c *TelegramCalls) takePlayStats() (map[int64]*playDelta, map[requestKey]int64) {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+
+	now := time.Now()
+	for chatID, since := range c.stats.playingSince {
+		c.stats.delta(chatID).streamed += now.Sub(since)
+		c.stats.playingSince[chatID] = now
+	}
+
+	pending, requests := c.stats.pending, c.stats.requests
+	c.stats.pending = make(map[int64]*playDelta)
+	c.stats.requests = make(map[requestKey]int64)
+	for _, d := range pending {
+		c.stats.addFlushed(d)
+	}
+	for _, n := range requests {
+		c.stats.flushed.Requests += n
+	}
+	return pending, requests
+}
+
+// takeChatPlayStats is takePlayStats for a single chat.
+func (c *TelegramCalls) takeChatPlayStats(chatID int64) (*playDelta, map[requestKey]int64) {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+
+	now := time.Now()
+	if since, ok := c.stats.playingSince[chatID]; ok {
+		c.stats.delta(chatID).streamed += now.Sub(since)
+		c.stats.playingSince[chatID] = now
+	}
+
+	d := c.stats.pending[chatID]
+	delete(c.stats.pending, chatID)
+	if d != nil {
+		c.stats.addFlushed(d)
+	}
+	requests := make(map[requestKey]int64)
+	for key, n := range c.stats.requests {
+		if key.chatID == chatID {
+			requests[key] = n
+			c.stats.flushed.Requests += n
+			delete(c.stats.requests, key)
+		}
+	}
+	return d, requests
+}
+
+// addFlushed adds a chat's counters to the bot-wide totals. The caller must hold s.mu.
+func (s *playStats) addFlushed(d *playDelta) {
+	s.flushed.StreamedSeconds += int64(d.streamed / time.Second)
+	s.flushed.Completed += d.completed
+	s.flushed.Skipped += d.skipped
+}
+
+// playStatsVar returns the playback counters flushed since the bot started, for expvar.
+func (c *TelegramCalls) playStatsVar() any {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	return c.stats.flushed
+}
+
+// FlushPlayStats writes the playback counters collected since the last flush to the database,
+// both per chat and bot-wide. It is also called on shutdown and before the counters are shown.
+func (c *TelegramCalls) FlushPlayStats() {
+	pending, requests := c.takePlayStats()
+	c.saveRequests(requests)
+	if len(pending) == 0 {
+		return
+	}
+
+	total := &playDelta{tracks: make(map[string]*trackPlays)}
+	for chatID, d := range pending {
+		c.savePlayDelta(chatID, d)
+		total.streamed += d.streamed
+		total.completed += d.completed
+		total.skipped += d.skipped
+		for trackID, tp := range d.tracks {
+			if t, ok := total.tracks[trackID]; ok {
+				t.plays += tp.plays
+			} else {
+				total.tracks[trackID] = &trackPlays{name: tp.name, plays: tp.plays}
+			}
+		}
+	}
+	c.savePlayDelta(db.GlobalStatsID, total)
+}
+
+// FlushChatStats writes the playback counters collected for one chat since the last flush to the database,
+// both for the chat and bot-wide, leaving other chats' counters to the periodic flush.
+func (c *TelegramCalls) FlushChatStats(chatID int64) {
+	d, requests := c.takeChatPlayStats(chatID)
+	c.saveRequests(requests)
+	if d == nil {
+		return
+	}
+	c.savePlayDelta(chatID, d)
+	c.savePlayDelta(db.GlobalStatsID, d)
+}
+
+// savePlayDelta adds a chat's unflushed counters to its stored ones.
+func (c *TelegramCalls) savePlayDelta(chatID int64, d *playDelta) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	seconds := int64(d.streamed / time.Second)
+	if seconds != 0 || d.completed != 0 || d.skipped != 0 {
+		if err := db.Instance.AddPlayStats(ctx, chatID, seconds, d.completed, d.skipped); err != nil {
+			logger.Warn("[FlushPlayStats] Failed to save the playback stats of %d: %v", chatID, err)
+		}
+		if chatID != db.GlobalStatsID {
+			if err := db.Instance.AddDailyChatStats(ctx, chatID, time.Now(), d.completed+d.skipped, seconds); err != nil {
+				logger.Warn("[FlushPlayStats] Failed to save the daily stats of %d: %v", chatID, err)
+			}
+		}
+	}
+	for trackID, tp := range d.tracks {
+		if err := db.Instance.AddTrackPlays(ctx, chatID, trackID, tp.name, tp.plays); err != nil {
+			logger.Warn("[FlushPlayStats] Failed to save the plays of %s in %d: %v", trackID, chatID, err)
+		}
+		if chatID != db.GlobalStatsID {
+			if err := db.Instance.AddDailyTrackPlays(ctx, chatID, trackID, tp.name, time.Now(), tp.plays); err != nil {
+				logger.Warn("[FlushPlayStats] Failed to save the daily plays of %s in %d: %v", trackID, chatID, err)
+			}
+		}
+	}
+}
+
+// saveRequests adds the unflushed request counts of each user to today's, both per chat and overall.
+func (c *TelegramCalls) saveRequests(requests map[requestKey]int64) {
+	if len(requests) == 0 {
+		return
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	perUser := make(map[int64]int64)
+	for key, n := range requests {
+		perUser[key.userID] += n
+		if err := db.Instance.AddDailyChatRequests(ctx, key.chatID, key.userID, time.Now(), n); err != nil {
+			logger.Warn("[FlushPlayStats] Failed to save the requests of %d in %d: %v", key.userID, key.chatID, err)
+		}
+	}
+	for userID, n := range perUser {
+		if err := db.Instance.AddDailyRequests(ctx, userID, time.Now(), n); err != nil {
+			logger.Warn("[FlushPlayStats] Failed to save the requests of %d: %v", userID, err)
+		}
+	}
+}
+
+// persistPlayStats periodically flushes the playback counters.
+func (c *TelegramCalls) persistPlayStats() {
+	expvar.Publish("playstats", expvar.Func(c.playStatsVar))
+	ticker := time.NewTicker(playStatsFlushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		c.FlushPlayStats()
+	}
+}
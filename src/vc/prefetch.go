@@ -0,0 +1,161 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/core/shutdown"
+)
+
+// prefetchTimeout bounds a single background download of an upcoming track.
+const prefetchTimeout = 3 * time.Minute
+
+var (
+	downloadSlots   chan struct{}
+	downloadSlotsMu sync.Mutex
+)
+
+// currentDownloadSlots returns the download slot pool, replacing it when MAX_CONCURRENT_DOWNLOADS was reloaded.
+// Downloads holding a slot of the old pool release it there, so the new limit applies as they finish.
+func currentDownloadSlots() chan struct{} {
+	n := int(config.Get().MaxDownloads)
+	if n <= 0 {
+		n = 1
+	}
+
+	downloadSlotsMu.Lock()
+	defer downloadSlotsMu.Unlock()
+	if downloadSlots == nil || cap(downloadSlots) != n {
+		downloadSlots = make(chan struct{}, n)
+	}
+	return downloadSlots
+}
+
+// acquireDownloadSlot blocks until one of the global download slots is free or ctx is done.
+// The returned function releases the slot.
+func acquireDownloadSlot(ctx context.Context) (func(), error) {
+	slots := currentDownloadSlots()
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// prefetchJob is a background download of a chat's upcoming track.
+type prefetchJob struct {
+	track  *cache.CachedTrack
+	cancel context.CancelFunc
+}
+
+// prefetchState tracks in-flight prefetches and the files they hold per chat.
+type prefetchState struct {
+	mu     sync.Mutex
+	jobs   map[int64]*prefetchJob
+	holds  map[int64]map[*cache.CachedTrack]string
+	listen sync.Once
+}
+
+// prefetchNext starts downloading the chat's upcoming track in the background.
+// Tracks that already have a file are skipped, and a running prefetch for a different track is cancelled.
+func (c *TelegramCalls) prefetchNext(chatID int64) {
+	c.prefetch.listen.Do(func() { cache.ChatCache.OnChange(c.onQueueChange) })
+
+	next := cache.ChatCache.GetUpcomingTrack(chatID)
+
+	c.prefetch.mu.Lock()
+	defer c.prefetch.mu.Unlock()
+
+	if job, ok := c.prefetch.jobs[chatID]; ok {
+		if job.track == next {
+			return
+		}
+		job.cancel()
+		delete(c.prefetch.jobs, chatID)
+	}
+
+	if next == nil || cache.ChatCache.TrackFile(chatID, next) != "" {
+		go c.preloadNext(chatID)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(logging.WithRequestID(shutdown.Context(), next.RequestID), prefetchTimeout)
+	job := &prefetchJob{track: next, cancel: cancel}
+	c.prefetch.jobs[chatID] = job
+	go c.runPrefetch(ctx, chatID, job)
+}
+
+// runPrefetch downloads the job's track and attaches the file to the queue entry if it is still queued.
+func (c *TelegramCalls) runPrefetch(ctx context.Context, chatID int64, job *prefetchJob) {
+	defer job.cancel()
+	defer func() {
+		c.prefetch.mu.Lock()
+		if c.prefetch.jobs[chatID] == job {
+			delete(c.prefetch.jobs, chatID)
+		}
+		c.prefetch.mu.Unlock()
+	}()
+
+	filePath, _, err := DownloadSong(ctx, chatID, job.track, c.bot)
+	if err != nil || filePath == "" {
+		logger.Ctx(ctx).Debug("[prefetch] Failed to prefetch %q in %d: %v", job.track.Name, chatID, err)
+		return
+	}
+
+	if !cache.ChatCache.SetTrackFile(chatID, job.track, filePath) {
+		return
+	}
+
+	cache.FileRefs.Acquire(filePath)
+	c.prefetch.mu.Lock()
+	if c.prefetch.holds[chatID] == nil {
+		c.prefetch.holds[chatID] = make(map[*cache.CachedTrack]string)
+	}
+	c.prefetch.holds[chatID][job.track] = filePath
+	c.prefetch.mu.Unlock()
+	logger.Ctx(ctx).Debug("[prefetch] Prefetched %q in %d", job.track.Name, chatID)
+	c.preloadNext(chatID)
+}
+
+// onQueueChange releases files held for entries that left the queue and re-evaluates the prefetch target.
+func (c *TelegramCalls) onQueueChange(change cache.QueueChange) {
+	go func() {
+		queued := make(map[*cache.CachedTrack]struct{})
+		for _, t := range cache.ChatCache.GetQueue(change.ChatID) {
+			queued[t] = struct{}{}
+		}
+
+		c.prefetch.mu.Lock()
+		for track, path := range c.prefetch.holds[change.ChatID] {
+			if _, ok := queued[track]; !ok {
+				cache.FileRefs.Release(path)
+				delete(c.prefetch.holds[change.ChatID], track)
+			}
+		}
+		if len(c.prefetch.holds[change.ChatID]) == 0 {
+			delete(c.prefetch.holds, change.ChatID)
+		}
+		c.prefetch.mu.Unlock()
+
+		if change.Event == cache.QueueAdvanced {
+			// The new current track is being started; playSong prefetches once it is playing.
+			return
+		}
+		if cache.ChatCache.IsActive(change.ChatID) {
+			c.prefetchNext(change.ChatID)
+		}
+	}()
+}
@@ -0,0 +1,167 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/dl"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/core/shutdown"
+)
+
+const (
+	// progressiveMinDuration is how long a track must be, in seconds, before playback starts from a partial download.
+	progressiveMinDuration = 10 * 60
+	// progressiveBuffer is how many seconds of audio must be downloaded before playback starts or resumes.
+	progressiveBuffer = 15
+	// progressiveLowWater is how many seconds the download may lead playback by before playback is paused.
+	progressiveLowWater = 5
+	// progressiveStall is how long a download may go without data before playback waits for it to complete.
+	progressiveStall = 10 * time.Second
+	// fallbackBytesPerSec estimates the bitrate (320 kbps) when the download's size is unknown.
+	fallbackBytesPerSec = 40_000
+	// downloadTimeout bounds a download, or for a long track the wait until playback can start from it.
+	downloadTimeout = 3 * time.Minute
+	// followFlags make ffmpeg keep reading a file that is still being written, giving up after 5 s without new data.
+	followFlags = "-follow 1 -rw_timeout 5000000"
+)
+
+// growingFiles holds the downloads that are still being written while they play, keyed by file path.
+var growingFiles sync.Map
+
+// growingFlags returns the ffmpeg input flags needed to play filePath, which may still be downloading.
+// filePath must have been passed through dl.ResolvePartial.
+func growingFlags(filePath string) string {
+	if _, ok := growingFiles.Load(filePath); ok {
+		return followFlags
+	}
+	return ""
+}
+
+// bytesPerSecond estimates the bitrate of a download from its size and the track's duration.
+func bytesPerSecond(p *dl.Progressive, duration int) int64 {
+	if total := p.Total(); total > 0 && duration > 0 {
+		return max(total/int64(duration), 1)
+	}
+	return fallbackBytesPerSec
+}
+
+// downloadResult is the outcome of DownloadSong.
+type downloadResult struct {
+	path string
+	info *cache.TrackInfo
+	err  error
+}
+
+// DownloadForPlayback downloads song like DownloadSong, but for long tracks fetched over HTTP it returns
+// as soon as progressiveBuffer seconds of audio are on disk. The download then continues in the background,
+// song.FilePath is switched to the complete file when it finishes, and playback in chatID is paused whenever
+// it catches up with the download. If the download stalls before the buffer fills, it waits for completion.
+// The returned path of a partial download is renamed when it completes; dl.ResolvePartial finds the new one.
+func (c *TelegramCalls) DownloadForPlayback(chatID int64, song *cache.CachedTrack) (string, *cache.TrackInfo, error) {
+	base := logging.WithRequestID(shutdown.Context(), song.RequestID)
+	if song.Duration < progressiveMinDuration || song.Platform == cache.Telegram {
+		ctx, cancel := context.WithTimeout(base, downloadTimeout)
+		defer cancel()
+		return DownloadSong(ctx, chatID, song, c.bot)
+	}
+
+	// Once playback starts from the partial file, the rest of the download is given as long as the track
+	// plays rather than downloadTimeout, which a long track on a slow link may well exceed.
+	ctx, cancel := context.WithCancel(base)
+	deadline := time.AfterFunc(downloadTimeout, cancel)
+
+	started := make(chan *dl.Progressive, 1)
+	results := make(chan downloadResult, 1)
+	go func() {
+		defer cancel()
+		path, info, err := DownloadSong(dl.WithProgressive(ctx, started), chatID, song, c.bot)
+		results <- downloadResult{path: path, info: info, err: err}
+	}()
+
+	var progress *dl.Progressive
+	select {
+	case r := <-results:
+		return r.path, r.info, r.err
+	case progress = <-started:
+	}
+
+	bps := bytesPerSecond(progress, song.Duration)
+	if early, err := progress.ReadyEarly(ctx, bps*progressiveBuffer, progressiveStall); !early {
+		if err != nil {
+			logger.Request(song.RequestID).Info("[DownloadForPlayback] Waiting for the full download of %q: %v", song.Name, err)
+		}
+		r := <-results
+		return r.path, r.info, r.err
+	}
+
+	deadline.Reset(max(downloadTimeout, time.Duration(song.Duration)*time.Second))
+	growingFiles.Store(progress.Path, progress)
+	go c.finishGrowing(chatID, song, progress, results)
+	go progress.Follow(growingPlayback{c: c, chatID: chatID, song: song}, bps, progressiveBuffer, progressiveLowWater, time.Second)
+	logger.Request(song.RequestID).Info("[DownloadForPlayback] Starting %q in %d from a partial download.", song.Name, chatID)
+	return progress.Path, nil, nil
+}
+
+// finishGrowing points song at the complete file once its background download ends. A song that is not
+// queued yet keeps the partial path, which dl.ResolvePartial maps to the complete file.
+func (c *TelegramCalls) finishGrowing(chatID int64, song *cache.CachedTrack, progress *dl.Progressive, results <-chan downloadResult) {
+	r := <-results
+	growingFiles.Delete(progress.Path)
+	if r.err != nil {
+		logger.Request(song.RequestID).Warn("[finishGrowing] The background download of %q failed: %v", song.Name, r.err)
+		return
+	}
+	cache.ChatCache.UpdateTrack(chatID, song, func(t *cache.CachedTrack) {
+		if t.FilePath == progress.Path {
+			t.FilePath = r.path
+		}
+		if r.info != nil && r.info.Lyrics != "" {
+			t.Lyrics = r.info.Lyrics
+		}
+	})
+}
+
+// growingPlayback is the stream of song in chatID, which plays from a download still being written.
+type growingPlayback struct {
+	c      *TelegramCalls
+	chatID int64
+	song   *cache.CachedTrack
+}
+
+// errNotStarted is returned by growingPlayback.Elapsed while the chat is still joining to play the track.
+var errNotStarted = errors.New("the track has not started yet")
+
+// Elapsed implements dl.Playback.
+func (g growingPlayback) Elapsed() (int, error) {
+	if cache.ChatCache.GetPlayingTrack(g.chatID) != g.song {
+		if state, _ := g.c.State(g.chatID); state != StateJoining {
+			return 0, dl.ErrPlaybackGone
+		}
+		return 0, errNotStarted
+	}
+	return g.c.Elapsed(g.chatID)
+}
+
+// Pause implements dl.Playback.
+func (g growingPlayback) Pause() error {
+	_, err := g.c.Pause(g.chatID)
+	return err
+}
+
+// Resume implements dl.Playback.
+func (g growingPlayback) Resume() error {
+	_, err := g.c.Resume(g.chatID)
+	return err
+}
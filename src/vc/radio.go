@@ -0,0 +1,83 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"slices"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/blockwords"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+)
+
+// radioUser is shown as the requester of tracks queued by 24/7 radio mode.
+const radioUser = "24/7 Radio"
+
+// isRadio reports whether the chat runs in 24/7 radio mode and which playlist it loops.
+// Radio mode is off everywhere when the deployment disables it.
+func (c *TelegramCalls) isRadio(chatID int64) (bool, string) {
+	if !config.Get().Features.Radio {
+		return false, ""
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	return db.Instance.GetRadio247(ctx, chatID)
+}
+
+// refillRadio queues the chat's radio playlist again once the queue has run out. Tracks whose title matches a
+// keyword blocked in the chat or globally are left out, as they would be refused if requested.
+// It returns false if the chat is not in radio mode, has no playlist, or no track of it may be played.
+func (c *TelegramCalls) refillRadio(chatID int64) bool {
+	radio, playlistID := c.isRadio(chatID)
+	if !radio || playlistID == "" {
+		return false
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	playlist, err := db.Instance.GetPlaylist(ctx, playlistID)
+	if err != nil {
+		logger.Warn("[refillRadio] Failed to load playlist %s for %d: %v", playlistID, chatID, err)
+		return false
+	}
+
+	blocked := slices.Concat(db.Instance.GetBlockedWords(ctx, chatID), db.Instance.GetGlobalBlockedWords(ctx, c.bot.Me().ID))
+	added := 0
+	for _, song := range playlist.Songs {
+		if blockwords.Match(song.Name, blocked) {
+			continue
+		}
+		track := &cache.CachedTrack{
+			URL:      song.URL,
+			Name:     song.Name,
+			TrackID:  song.TrackID,
+			Duration: song.Duration,
+			Platform: song.Platform,
+			User:     radioUser,
+		}
+		if cache.ChatCache.AddSong(chatID, track) == nil {
+			break
+		}
+		added++
+	}
+
+	if added > 0 {
+		cache.ChatCache.SetActive(chatID, true)
+	}
+	return added > 0
+}
+
+// RadioChanged applies a change of the chat's 24/7 radio setting to an assistant that is waiting in its voice chat:
+// an idle chat that enabled radio mode is held indefinitely, and a held chat that disabled it times out normally.
+func (c *TelegramCalls) RadioChanged(chatID int64) {
+	if c.IsIdle(chatID) {
+		c.startIdleTimer(chatID)
+	}
+}
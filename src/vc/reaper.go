@@ -0,0 +1,74 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/reaper"
+)
+
+// registerReaper lets the reaper tear down the player's per-chat state once a chat has gone quiet.
+// Everything else a chat holds is released when its session ends.
+func (c *TelegramCalls) registerReaper() {
+	// A playback lock only exists while it is held or waited for, so the pool is listed but never reaped.
+	reaper.Register(reaper.Pool{
+		Name: "playback locks",
+		Len:  c.playbackLocks.Len,
+	})
+	reaper.Register(reaper.Pool{
+		Name: "stream positions",
+		Len: func() int {
+			c.positions.mu.Lock()
+			defer c.positions.mu.Unlock()
+			return len(c.positions.positions)
+		},
+		Idle: func() []int64 {
+			c.positions.mu.Lock()
+			defer c.positions.mu.Unlock()
+			var idle []int64
+			for chatID := range c.positions.positions {
+				if !cache.ChatCache.IsActive(chatID) {
+					idle = append(idle, chatID)
+				}
+			}
+			return idle
+		},
+		Drop: func(chatID int64) bool {
+			if cache.ChatCache.IsActive(chatID) {
+				return false
+			}
+			c.clearPosition(chatID)
+			return true
+		},
+	})
+	reaper.Register(reaper.Pool{
+		Name: "ended queues",
+		Len: func() int {
+			c.endedMu.Lock()
+			defer c.endedMu.Unlock()
+			return len(c.endedQueues)
+		},
+		Idle: func() []int64 {
+			c.endedMu.Lock()
+			defer c.endedMu.Unlock()
+			idle := make([]int64, 0, len(c.endedQueues))
+			for chatID := range c.endedQueues {
+				idle = append(idle, chatID)
+			}
+			return idle
+		},
+		Drop: func(chatID int64) bool {
+			if c.EndedQueueLength(chatID) == 0 {
+				return false
+			}
+			c.DiscardEndedQueue(chatID)
+			return true
+		},
+	})
+}
@@ -0,0 +1,103 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"fmt"
+	"html"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/cleaner"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/lang"
+)
+
+const (
+	// maxStreamRestarts is how many times a track whose stream died is restarted before it is skipped.
+	maxStreamRestarts = 2
+	// earlyEndMargin is how many seconds before the expected end a stream may stop without being treated as a failure.
+	earlyEndMargin = 10
+)
+
+// streamRestarts counts the restart attempts of the track currently playing in each chat.
+type streamRestarts struct {
+	track    *cache.CachedTrack
+	attempts int
+}
+
+// endedEarly reports whether the stream of song stopped well before the track's expected duration,
+// which points to ffmpeg crashing or the source connection being reset. It also returns the elapsed offset.
+func (c *TelegramCalls) endedEarly(chatID int64, song *cache.CachedTrack) (int, bool) {
+	if song.Duration <= 0 {
+		return 0, false
+	}
+	elapsed, err := c.Elapsed(chatID)
+	if err != nil {
+		return 0, false
+	}
+	return elapsed, elapsed < song.Duration-earlyEndMargin
+}
+
+// nextRestartAttempt records another restart attempt for song and returns its number.
+// Counting starts over whenever a different track is playing.
+func (c *TelegramCalls) nextRestartAttempt(chatID int64, song *cache.CachedTrack) int {
+	c.restartMu.Lock()
+	defer c.restartMu.Unlock()
+	r, ok := c.restarts[chatID]
+	if !ok || r.track != song {
+		r = &streamRestarts{track: song}
+		c.restarts[chatID] = r
+	}
+	r.attempts++
+	return r.attempts
+}
+
+// resetRestarts forgets the chat's restart attempts.
+func (c *TelegramCalls) resetRestarts(chatID int64) {
+	c.restartMu.Lock()
+	defer c.restartMu.Unlock()
+	delete(c.restarts, chatID)
+}
+
+// recoverStream restarts a track whose stream died mid-way from the last known offset.
+// It returns true if the track was restarted, and false once the attempts are used up or the restart failed,
+// in which case the chat is told that the track is being skipped and its remaining loops are dropped.
+// The caller must hold the chat's playback lock.
+func (c *TelegramCalls) recoverStream(chatID int64, song *cache.CachedTrack) bool {
+	elapsed, early := c.endedEarly(chatID, song)
+	if !early {
+		return false
+	}
+
+	attempt := c.nextRestartAttempt(chatID, song)
+	if attempt <= maxStreamRestarts {
+		logger.Request(song.RequestID).Warn("[recoverStream] Stream in %d ended at %ds of %ds (track %s); restart attempt %d/%d.",
+			chatID, elapsed, song.Duration, song.TrackID, attempt, maxStreamRestarts)
+
+		pos := c.position(chatID)
+		pos.offset = elapsed
+		err := c.restream(chatID, song, pos)
+		if err == nil {
+			return true
+		}
+		logger.Request(song.RequestID).Error("[recoverStream] Restart attempt %d for track %s in %d failed: %v", attempt, song.TrackID, chatID, err)
+	} else {
+		logger.Request(song.RequestID).Error("[recoverStream] Giving up on track %s in %d after %d restarts.", song.TrackID, chatID, maxStreamRestarts)
+	}
+
+	song.Loop = 0
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+	text := fmt.Sprintf(lang.GetString(langCode, "stream_failed_skip"), html.EscapeString(song.Name))
+	if msg, err := c.bot.SendMessage(chatID, text); err == nil {
+		cleaner.ScheduleChat(c.bot, chatID, msg.ID)
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+)
+
+const (
+	// minResumeOffset is how far into a track playback must have got for resuming it to be offered.
+	minResumeOffset = 30
+	// maxResumeShare is the share of a track past which it counts as finished rather than interrupted.
+	maxResumeShare = 0.95
+)
+
+// saveResumePoint remembers how far the chat got into its current track, so that playing the track again
+// can pick up from there.
+func (c *TelegramCalls) saveResumePoint(chatID int64) {
+	song := cache.ChatCache.GetPlayingTrack(chatID)
+	if song == nil || song.TrackID == "" {
+		return
+	}
+	elapsed, err := c.Elapsed(chatID)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	err = db.Instance.SaveResumePoint(ctx, &db.ResumePoint{
+		ChatID:   chatID,
+		Platform: song.Platform,
+		TrackID:  song.TrackID,
+		Elapsed:  elapsed,
+		Duration: song.Duration,
+		SavedAt:  time.Now(),
+	})
+	if err != nil {
+		logger.Warn("[saveResumePoint] Failed to save the resume point of %d: %v", chatID, err)
+	}
+}
+
+// ResumeOffset returns the offset in seconds song can be resumed from in the chat, or 0 if the chat has not
+// stopped it recently. Offsets too close to either end of the track are not worth offering and return 0 too.
+func (c *TelegramCalls) ResumeOffset(chatID int64, song *cache.CachedTrack) int {
+	if song.TrackID == "" {
+		return 0
+	}
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	point, err := db.Instance.GetResumePoint(ctx, chatID, song.Platform, song.TrackID)
+	if err != nil || point == nil {
+		return 0
+	}
+	duration := song.Duration
+	if duration <= 0 {
+		duration = point.Duration
+	}
+	if point.Elapsed < minResumeOffset || (duration > 0 && float64(point.Elapsed) > maxResumeShare*float64(duration)) {
+		return 0
+	}
+	return point.Elapsed
+}
+
+// ForgetResumePoint drops the chat's resume point once it has been used or declined.
+func (c *TelegramCalls) ForgetResumePoint(chatID int64) {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if err := db.Instance.DeleteResumePoint(ctx, chatID); err != nil {
+		logger.Debug("[ForgetResumePoint] Failed to delete the resume point of %d: %v", chatID, err)
+	}
+}
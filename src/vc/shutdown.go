@@ -0,0 +1,43 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"context"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/shutdown"
+)
+
+// registerShutdown registers the player's shutdown hooks: chats that are playing are told about the restart,
+// queues and playback counters are saved, and the assistant clients are stopped.
+func (c *TelegramCalls) registerShutdown() {
+	shutdown.Register("notify active chats", shutdown.PriorityNotify, func(ctx context.Context) error {
+		for _, chatID := range cache.ChatCache.GetActiveChats() {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			c.notify(chatID, "shutdown_notice")
+		}
+		return nil
+	})
+	shutdown.Register("save queue snapshots", shutdown.PriorityFlush, func(context.Context) error {
+		saved := c.SaveSnapshots()
+		logger.Info("[shutdown] Saved the queues of %d chats.", len(saved))
+		return nil
+	})
+	shutdown.Register("flush play stats", shutdown.PriorityFlush, func(context.Context) error {
+		c.FlushPlayStats()
+		return nil
+	})
+	shutdown.Register("stop assistants", shutdown.PriorityCalls, func(context.Context) error {
+		c.StopAllClients()
+		return nil
+	})
+}
@@ -0,0 +1,130 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"os"
+	"time"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+)
+
+// snapshotInterval is how often the queues of active chats are persisted.
+const snapshotInterval = 15 * time.Second
+
+// persistSnapshots periodically saves the queue and elapsed time of every active chat
+// and removes the snapshots of chats whose playback has ended. Snapshots this process never saved, such as
+// those left from before a restart that were skipped or never resumed, are removed once they are stale.
+func (c *TelegramCalls) persistSnapshots() {
+	saved := make(map[int64]struct{})
+	ticker := time.NewTicker(snapshotInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		active := make(map[int64]struct{})
+		for _, chatID := range c.SaveSnapshots() {
+			active[chatID] = struct{}{}
+			saved[chatID] = struct{}{}
+		}
+
+		for chatID := range saved {
+			if _, ok := active[chatID]; ok {
+				continue
+			}
+			ctx, cancel := db.Ctx()
+			if err := db.Instance.DeleteQueueSnapshot(ctx, chatID); err == nil {
+				delete(saved, chatID)
+			}
+			cancel()
+		}
+
+		c.deleteStaleSnapshots()
+	}
+}
+
+// deleteStaleSnapshots removes the snapshots older than RESUME_STALE_AFTER. Active chats are saved on every
+// tick, so only snapshots nobody saves any more age out.
+func (c *TelegramCalls) deleteStaleSnapshots() {
+	cutoff := time.Now().Add(-time.Duration(config.Get().ResumeStaleAfter) * time.Second)
+	ctx, cancel := db.Ctx()
+	defer cancel()
+
+	n, err := db.Instance.DeleteQueueSnapshotsBefore(ctx, cutoff)
+	if err != nil {
+		logger.Warn("[persistSnapshots] Failed to delete stale snapshots: %v", err)
+		return
+	}
+	if n > 0 {
+		logger.Info("[persistSnapshots] Deleted %d stale snapshots", n)
+	}
+}
+
+// SaveSnapshots persists the queue of every active chat and returns the chats that were saved.
+// It is also called on shutdown so that playback can be resumed after the restart.
+func (c *TelegramCalls) SaveSnapshots() []int64 {
+	var saved []int64
+	for _, chatID := range cache.ChatCache.GetActiveChats() {
+		queue := cache.ChatCache.GetQueue(chatID)
+		if len(queue) == 0 {
+			continue
+		}
+
+		snap := &db.QueueSnapshot{ChatID: chatID, SavedAt: time.Now()}
+		for _, t := range queue {
+			snap.Tracks = append(snap.Tracks, *t)
+		}
+		if played, err := c.Elapsed(chatID); err == nil {
+			snap.Elapsed = played
+		}
+
+		ctx, cancel := db.Ctx()
+		err := db.Instance.SaveQueueSnapshot(ctx, snap)
+		cancel()
+		if err != nil {
+			logger.Warn("[SaveSnapshots] Failed to save the snapshot of %d: %v", chatID, err)
+			continue
+		}
+		saved = append(saved, chatID)
+	}
+	return saved
+}
+
+// RestoreSnapshot rebuilds a chat's queue from a snapshot and restarts the current track,
+// seeking to the saved offset when its file is still on disk.
+func (c *TelegramCalls) RestoreSnapshot(snap *db.QueueSnapshot) error {
+	chatID := snap.ChatID
+	cache.ChatCache.ClearChat(chatID)
+	for i := range snap.Tracks {
+		track := snap.Tracks[i]
+		if _, err := cache.ChatCache.Enqueue(chatID, &track); err != nil {
+			break
+		}
+	}
+
+	current := cache.ChatCache.GetPlayingTrack(chatID)
+	if current == nil {
+		return ErrNotPlaying
+	}
+
+	unlock := c.lockPlayback(chatID)
+	defer unlock()
+
+	if current.FilePath != "" {
+		if _, err := os.Stat(current.FilePath); err != nil {
+			current.FilePath = ""
+		}
+	}
+
+	if current.FilePath != "" && snap.Elapsed > 0 && snap.Elapsed < current.Duration {
+		return c.SeekStream(chatID, current.FilePath, snap.Elapsed, current.Duration, current.IsVideo)
+	}
+	return c.playSong(chatID, current)
+}
@@ -0,0 +1,206 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+)
+
+const (
+	// MinSpeed and MaxSpeed bound the playback speed accepted by ChangeSpeed.
+	MinSpeed = 0.5
+	MaxSpeed = 2.0
+)
+
+// streamPosition records where in the track the running stream was started, at what speed it plays
+// and which filter preset it uses.
+type streamPosition struct {
+	offset int
+	speed  float64
+	filter string
+}
+
+// rate returns how many seconds of the track play per second of streaming.
+func (p streamPosition) rate() float64 {
+	rate := p.speed
+	if preset, ok := lookupFilter(p.filter); ok && preset.Tempo != 0 {
+		rate *= preset.Tempo
+	}
+	return rate
+}
+
+// streamPositions holds the stream position and elapsed-time tracker of every chat.
+type streamPositions struct {
+	mu        sync.Mutex
+	clock     Clock
+	positions map[int64]streamPosition
+	trackers  map[int64]*ElapsedTracker
+}
+
+// ClampSpeed limits speed to the supported range.
+func ClampSpeed(speed float64) float64 {
+	return min(max(speed, MinSpeed), MaxSpeed)
+}
+
+// atempoChain builds the atempo filter for speed.
+// A single atempo stage only accepts factors between 0.5 and 2.0, so other factors are split into several stages.
+func atempoChain(speed float64) string {
+	stages := make([]string, 0, 2)
+	remaining := speed
+	for remaining > 2.0 {
+		stages = append(stages, "atempo=2.0")
+		remaining /= 2.0
+	}
+	for remaining < 0.5 {
+		stages = append(stages, "atempo=0.5")
+		remaining /= 0.5
+	}
+	stages = append(stages, fmt.Sprintf("atempo=%g", remaining))
+	return strings.Join(stages, ",")
+}
+
+// streamParams builds the ffmpeg parameters that start a track at pos.offset seconds with pos's speed and filter preset.
+func streamParams(pos streamPosition, duration int) string {
+	var params []string
+	if pos.offset > 0 {
+		params = append(params, fmt.Sprintf("-ss %d", pos.offset))
+		if duration > pos.offset {
+			params = append(params, fmt.Sprintf("-to %d", duration))
+		}
+	}
+
+	var audio []string
+	if preset, ok := lookupFilter(pos.filter); ok {
+		audio = append(audio, preset.Audio)
+	}
+	if pos.speed != 1.0 {
+		audio = append(audio, atempoChain(pos.speed))
+	}
+	// The media command runs through a shell, so filter values are quoted.
+	if rate := pos.rate(); rate != 1.0 {
+		params = append(params, fmt.Sprintf("-filter:v \"setpts=%g*PTS\"", 1/rate))
+	}
+	if len(audio) > 0 {
+		params = append(params, fmt.Sprintf("-filter:a \"%s\"", strings.Join(audio, ",")))
+	}
+	return strings.Join(params, " ")
+}
+
+// setPosition records how the chat's stream was (re)started and restarts its elapsed-time tracker accordingly.
+func (c *TelegramCalls) setPosition(chatID int64, pos streamPosition) {
+	c.positions.mu.Lock()
+	defer c.positions.mu.Unlock()
+	c.positions.positions[chatID] = pos
+
+	tracker, ok := c.positions.trackers[chatID]
+	if !ok {
+		tracker = NewElapsedTracker(c.positions.clock)
+		c.positions.trackers[chatID] = tracker
+	}
+	tracker.Start(time.Duration(pos.offset) * time.Second)
+	tracker.SetSpeed(pos.rate())
+}
+
+// clearPosition forgets the chat's stream position and tracker.
+func (c *TelegramCalls) clearPosition(chatID int64) {
+	c.positions.mu.Lock()
+	defer c.positions.mu.Unlock()
+	delete(c.positions.positions, chatID)
+	delete(c.positions.trackers, chatID)
+}
+
+// tracker returns the chat's elapsed-time tracker, or nil if no track is streaming.
+func (c *TelegramCalls) tracker(chatID int64) *ElapsedTracker {
+	c.positions.mu.Lock()
+	defer c.positions.mu.Unlock()
+	return c.positions.trackers[chatID]
+}
+
+// position returns the chat's stream position, defaulting to the start of the track at normal speed.
+func (c *TelegramCalls) position(chatID int64) streamPosition {
+	c.positions.mu.Lock()
+	defer c.positions.mu.Unlock()
+	if pos, ok := c.positions.positions[chatID]; ok {
+		return pos
+	}
+	return streamPosition{speed: 1.0}
+}
+
+// Speed returns the playback speed of the chat's current stream.
+func (c *TelegramCalls) Speed(chatID int64) float64 {
+	return c.position(chatID).speed
+}
+
+// Elapsed returns how many seconds into the current track playback is.
+// It accounts for pauses, seeks, speed changes and tempo-changing filters, and returns ErrNotPlaying
+// if no track is streaming in the chat.
+func (c *TelegramCalls) Elapsed(chatID int64) (int, error) {
+	tracker := c.tracker(chatID)
+	if tracker == nil {
+		return 0, ErrNotPlaying
+	}
+	return int(tracker.Elapsed() / time.Second), nil
+}
+
+// nextTrackSpeed returns the speed a newly started track should play at.
+// Speed changes last until the end of the track unless the chat has asked to keep them.
+func (c *TelegramCalls) nextTrackSpeed(chatID int64) float64 {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if !db.Instance.GetKeepSpeed(ctx, chatID) {
+		return 1.0
+	}
+	return c.Speed(chatID)
+}
+
+// restartOffset returns the position the current track should be restarted from when its stream settings change.
+func (c *TelegramCalls) restartOffset(chatID int64, song *cache.CachedTrack) int {
+	elapsed, err := c.Elapsed(chatID)
+	if err != nil || elapsed < 0 || (song.Duration > 0 && elapsed >= song.Duration) {
+		return 0
+	}
+	return elapsed
+}
+
+// restream restarts the current track with new stream settings and records them.
+// The caller must hold the chat's playback lock.
+func (c *TelegramCalls) restream(chatID int64, song *cache.CachedTrack, pos streamPosition) error {
+	if err := c.PlayMedia(chatID, cache.ChatCache.TrackFile(chatID, song), song.IsVideo, streamParams(pos, song.Duration)); err != nil {
+		return err
+	}
+	c.setPosition(chatID, pos)
+	return nil
+}
+
+// StartTrack streams song from its beginning at normal speed without a filter.
+func (c *TelegramCalls) StartTrack(chatID int64, song *cache.CachedTrack) error {
+	return c.StartTrackAt(chatID, song, 0)
+}
+
+// StartTrackAt streams song from offset seconds in at normal speed without a filter.
+func (c *TelegramCalls) StartTrackAt(chatID int64, song *cache.CachedTrack, offset int) error {
+	if err := c.checkBusy(chatID); err != nil {
+		return err
+	}
+
+	unlock := c.lockPlayback(chatID)
+	defer unlock()
+
+	if err := c.restream(chatID, song, streamPosition{speed: 1.0, offset: offset}); err != nil {
+		return err
+	}
+	c.showTrackTitle(chatID, song)
+	return nil
+}
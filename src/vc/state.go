@@ -0,0 +1,52 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import "ashokshau/tgmusic/src/core/playstate"
+
+// PlaybackState is the playback state of a single chat.
+type PlaybackState = playstate.State
+
+const (
+	StateIdle          = playstate.Idle
+	StateJoining       = playstate.Joining
+	StatePlaying       = playstate.Playing
+	StatePaused        = playstate.Paused
+	StateTransitioning = playstate.Transitioning
+	StateErrored       = playstate.Errored
+)
+
+var (
+	// ErrInvalidTransition is returned when a state change is not allowed from the current state.
+	ErrInvalidTransition = playstate.ErrInvalidTransition
+	// ErrNotPlaying is returned when an action needs a running stream but the chat is idle or errored.
+	ErrNotPlaying = playstate.ErrNotPlaying
+	// ErrAlreadyPaused is returned when pausing a chat that is already paused.
+	ErrAlreadyPaused = playstate.ErrAlreadyPaused
+	// ErrNotPaused is returned when resuming a chat that is not paused.
+	ErrNotPaused = playstate.ErrNotPaused
+	// ErrPlaybackBusy is returned while the chat is joining or switching tracks.
+	ErrPlaybackBusy = playstate.ErrBusy
+)
+
+// State returns the chat's playback state and the error that put it into StateErrored, if any.
+func (c *TelegramCalls) State(chatID int64) (PlaybackState, error) {
+	return c.states.Get(chatID)
+}
+
+// setState moves the chat to a new state, rejecting transitions the state machine does not allow.
+// cause is recorded as the chat's LastError when moving to StateErrored.
+func (c *TelegramCalls) setState(chatID int64, to PlaybackState, cause error) error {
+	return c.states.Set(chatID, to, cause)
+}
+
+// checkBusy returns ErrPlaybackBusy while the chat is joining or switching tracks.
+func (c *TelegramCalls) checkBusy(chatID int64) error {
+	return c.states.CheckBusy(chatID)
+}
@@ -0,0 +1,145 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"time"
+
+	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+)
+
+const (
+	// titleMinInterval is the minimum time between two title edits in the same voice chat.
+	titleMinInterval = 30 * time.Second
+	// maxTitleLength is the longest voice chat title Telegram accepts, in characters.
+	maxTitleLength = 64
+)
+
+// titleState tracks the voice chat title of one chat while the bot is changing it.
+type titleState struct {
+	original string
+	saved    bool
+	want     string
+	restore  bool
+	last     time.Time
+	pending  *time.Timer
+}
+
+// truncateTitle shortens title to the length Telegram accepts.
+func truncateTitle(title string) string {
+	runes := []rune(title)
+	if len(runes) <= maxTitleLength {
+		return title
+	}
+	return string(runes[:maxTitleLength-1]) + "…"
+}
+
+// showTrackTitle sets the voice chat title to the track's name if the chat has enabled it.
+func (c *TelegramCalls) showTrackTitle(chatID int64, song *cache.CachedTrack) {
+	if chatID > 0 || song == nil {
+		return
+	}
+
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	if !db.Instance.GetVCTitle(ctx, chatID) {
+		return
+	}
+	c.queueTitle(chatID, truncateTitle("🎵 "+song.Name), false)
+}
+
+// restoreTitle puts back the voice chat title the chat had before the bot changed it.
+func (c *TelegramCalls) restoreTitle(chatID int64) {
+	c.queueTitle(chatID, "", true)
+}
+
+// queueTitle schedules a title edit, coalescing edits that arrive faster than titleMinInterval
+// so that only the latest one is applied.
+func (c *TelegramCalls) queueTitle(chatID int64, title string, restore bool) {
+	c.titleMu.Lock()
+	defer c.titleMu.Unlock()
+
+	st := c.titles[chatID]
+	if st == nil {
+		if restore {
+			return
+		}
+		st = &titleState{}
+		c.titles[chatID] = st
+	}
+
+	if restore && !st.saved {
+		if st.pending != nil {
+			st.pending.Stop()
+		}
+		delete(c.titles, chatID)
+		return
+	}
+
+	st.want, st.restore = title, restore
+	if st.pending != nil {
+		return
+	}
+	wait := max(time.Until(st.last.Add(titleMinInterval)), 0)
+	st.pending = time.AfterFunc(wait, func() { c.flushTitle(chatID) })
+}
+
+// flushTitle applies the latest scheduled title edit.
+// Chats where the assistant may not manage voice chats are skipped silently.
+func (c *TelegramCalls) flushTitle(chatID int64) {
+	c.titleMu.Lock()
+	st := c.titles[chatID]
+	if st == nil {
+		c.titleMu.Unlock()
+		return
+	}
+	st.pending = nil
+	saved, restore := st.saved, st.restore
+	title := st.want
+	if restore {
+		title = st.original
+	}
+	c.titleMu.Unlock()
+
+	call, err := c.GetGroupAssistant(chatID)
+	if err != nil {
+		return
+	}
+
+	status, err := cache.GetUserAdmin(c.bot, chatID, call.App.Me().ID, false)
+	if err != nil || status.Rights == nil || !status.Rights.ManageCall {
+		c.titleMu.Lock()
+		delete(c.titles, chatID)
+		c.titleMu.Unlock()
+		return
+	}
+
+	if !saved {
+		original, err := call.GroupCallTitle(chatID)
+		if err != nil {
+			logger.Debug("[flushTitle] Failed to read the voice chat title in %d: %v", chatID, err)
+			return
+		}
+		c.titleMu.Lock()
+		st.original, st.saved = original, true
+		c.titleMu.Unlock()
+	}
+
+	if err = call.SetGroupCallTitle(chatID, title); err != nil {
+		logger.Debug("[flushTitle] Failed to set the voice chat title in %d: %v", chatID, err)
+	}
+
+	c.titleMu.Lock()
+	defer c.titleMu.Unlock()
+	st.last = time.Now()
+	if restore && st.restore && st.pending == nil && c.titles[chatID] == st {
+		delete(c.titles, chatID)
+	}
+}
@@ -9,16 +9,21 @@
 package vc
 
 import (
+	"errors"
 	"sync"
 	"time"
 
 	"ashokshau/tgmusic/src/core/cache"
+	"ashokshau/tgmusic/src/core/db"
+	"ashokshau/tgmusic/src/core/keylock"
+	"ashokshau/tgmusic/src/core/logging"
+	"ashokshau/tgmusic/src/core/playstate"
 	"ashokshau/tgmusic/src/vc/ubot"
 
 	tg "github.com/amarnathcjd/gogram/telegram"
 )
 
-var logger tg.Logger
+var logger = logging.For("player")
 
 // TelegramCalls manages the state and operations for voice calls, including userbots and the main bot client.
 type TelegramCalls struct {
@@ -30,6 +35,28 @@ type TelegramCalls struct {
 	bot              *tg.Client
 	statusCache      *cache.Cache[string]
 	inviteCache      *cache.Cache[string]
+	nowPlayingMu     sync.Mutex
+	nowPlaying       map[int64]int32
+	idleMu           sync.Mutex
+	idleTimers       map[int64]*time.Timer
+	autoPaused       map[int64]time.Time
+	playbackLocks    *keylock.Map
+	prefetch         prefetchState
+	healthMu         sync.Mutex
+	health           map[string]assistantHealth
+	states           *playstate.Machine
+	positions        streamPositions
+	waiting          waitingRoom
+	restartMu        sync.Mutex
+	restarts         map[int64]*streamRestarts
+	titleMu          sync.Mutex
+	titles           map[int64]*titleState
+	stats            playStats
+	endedMu          sync.Mutex
+	endedQueues      map[int64]*db.QueueSnapshot
+	gapless          gaplessState
+	mirrors          mirrorState
+	announcing       announceState
 }
 
 var (
@@ -46,10 +73,50 @@ func getCalls() *TelegramCalls {
 			clientCounter: 1,
 			statusCache:   cache.NewCache[string](2 * time.Hour),
 			inviteCache:   cache.NewCache[string](2 * time.Hour),
+			nowPlaying:    make(map[int64]int32),
+			idleTimers:    make(map[int64]*time.Timer),
+			autoPaused:    make(map[int64]time.Time),
+			restarts:      make(map[int64]*streamRestarts),
+			titles:        make(map[int64]*titleState),
+			endedQueues:   make(map[int64]*db.QueueSnapshot),
+			playbackLocks: keylock.New(),
+			health:        make(map[string]assistantHealth),
+			states:        playstate.New(),
+			mirrors:       mirrorState{links: make(map[int64]*db.MirrorLink)},
+			announcing:    announceState{active: make(map[int64]*announcement)},
+			gapless: gaplessState{
+				preloads: make(map[int64]*preload),
+				endedAt:  make(map[int64]time.Time),
+			},
+			stats: playStats{
+				pending:      make(map[int64]*playDelta),
+				playingSince: make(map[int64]time.Time),
+				completing:   make(map[int64]bool),
+				requests:     make(map[requestKey]int64),
+			},
+			positions: streamPositions{
+				clock:     SystemClock,
+				positions: make(map[int64]streamPosition),
+				trackers:  make(map[int64]*ElapsedTracker),
+			},
+			prefetch: prefetchState{
+				jobs:  make(map[int64]*prefetchJob),
+				holds: make(map[int64]map[*cache.CachedTrack]string),
+			},
 		}
+		instance.states.OnTransition = instance.recordTransition
 	})
 	return instance
 }
 
+// errTrackUnavailable marks a track that could not be prepared and should be skipped.
+var errTrackUnavailable = errors.New("track unavailable")
+
+// lockPlayback locks the mutex that serializes queue advancement for a chat and returns the func that
+// unlocks it.
+func (c *TelegramCalls) lockPlayback(chatID int64) (unlock func()) {
+	return c.playbackLocks.Lock(chatID)
+}
+
 // Calls is the singleton instance of TelegramCalls, initialized lazily.
 var Calls = getCalls()
@@ -31,6 +31,7 @@ type Context struct {
 	incomingCallCallbacks []func(client *Context, chatId int64)
 	streamEndCallbacks    []ntgcalls.StreamEndCallback
 	frameCallbacks        []ntgcalls.FrameCallback
+	callEndedCallbacks    []func(chatId int64)
 }
 
 func NewInstance(app *tg.Client) (*Context, error) {
@@ -71,6 +72,10 @@ func (ctx *Context) OnFrame(callback ntgcalls.FrameCallback) {
 	ctx.frameCallbacks = append(ctx.frameCallbacks, callback)
 }
 
+func (ctx *Context) OnGroupCallEnded(callback func(chatId int64)) {
+	ctx.callEndedCallbacks = append(ctx.callEndedCallbacks, callback)
+}
+
 func (ctx *Context) Close() {
 	ctx.binding.Free()
 }
@@ -1,11 +1,15 @@
 package ubot
 
 import (
+	"errors"
 	"fmt"
 
 	tg "github.com/amarnathcjd/gogram/telegram"
 )
 
+// ErrNoActiveCall is returned when the chat has no voice chat running.
+var ErrNoActiveCall = errors.New("no active group call")
+
 func (ctx *Context) getInputGroupCall(chatId int64) (tg.InputGroupCall, error) {
 	ctx.groupCallsMutex.RLock()
 	call, ok := ctx.inputGroupCalls[chatId]
@@ -13,7 +17,7 @@ func (ctx *Context) getInputGroupCall(chatId int64) (tg.InputGroupCall, error) {
 
 	if ok {
 		if call == nil {
-			return nil, fmt.Errorf("group call for chatId %d is closed", chatId)
+			return nil, fmt.Errorf("group call for chatId %d is closed: %w", chatId, ErrNoActiveCall)
 		}
 		return call, nil
 	}
@@ -46,7 +50,7 @@ func (ctx *Context) getInputGroupCall(chatId int64) (tg.InputGroupCall, error) {
 	ctx.groupCallsMutex.Unlock()
 
 	if newCall == nil {
-		return nil, fmt.Errorf("group call for chatId %d is closed", chatId)
+		return nil, fmt.Errorf("group call for chatId %d is closed: %w", chatId, ErrNoActiveCall)
 	}
 	return newCall, nil
 }
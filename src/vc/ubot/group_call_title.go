@@ -0,0 +1,39 @@
+package ubot
+
+import (
+	"fmt"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// GroupCallTitle returns the title of the chat's voice chat.
+// An empty title means the voice chat shows the group's name.
+func (ctx *Context) GroupCallTitle(chatId int64) (string, error) {
+	call, err := ctx.getInputGroupCall(chatId)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := ctx.App.PhoneGetGroupCall(call, 1)
+	if err != nil {
+		return "", err
+	}
+
+	groupCall, ok := res.Call.(*tg.GroupCallObj)
+	if !ok {
+		return "", fmt.Errorf("group call for chatId %d is closed: %w", chatId, ErrNoActiveCall)
+	}
+	return groupCall.Title, nil
+}
+
+// SetGroupCallTitle changes the title of the chat's voice chat.
+// The assistant must be an admin allowed to manage voice chats.
+func (ctx *Context) SetGroupCallTitle(chatId int64, title string) error {
+	call, err := ctx.getInputGroupCall(chatId)
+	if err != nil {
+		return err
+	}
+
+	_, err = ctx.App.PhoneEditGroupCallTitle(call, title)
+	return err
+}
@@ -239,6 +239,9 @@ func (ctx *Context) handleUpdates() {
 				delete(ctx.inputGroupCalls, chatID)
 				ctx.groupCallsMutex.Unlock()
 				_ = ctx.binding.Stop(chatID)
+				for _, callback := range ctx.callEndedCallbacks {
+					go callback(chatID)
+				}
 				return nil
 			}
 		}
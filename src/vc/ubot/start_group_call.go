@@ -0,0 +1,30 @@
+package ubot
+
+import (
+	"math/rand/v2"
+
+	tg "github.com/amarnathcjd/gogram/telegram"
+)
+
+// StartGroupCall starts a voice chat in the given group.
+// The assistant must be an admin allowed to manage voice chats.
+func (ctx *Context) StartGroupCall(chatId int64) error {
+	peer, err := ctx.App.ResolvePeer(chatId)
+	if err != nil {
+		return err
+	}
+
+	_, err = ctx.App.PhoneCreateGroupCall(&tg.PhoneCreateGroupCallParams{
+		Peer:     peer,
+		RandomID: rand.Int32(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Drop the cached "closed" marker so the next join resolves the new call.
+	ctx.groupCallsMutex.Lock()
+	delete(ctx.inputGroupCalls, chatId)
+	ctx.groupCallsMutex.Unlock()
+	return nil
+}
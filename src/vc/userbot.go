@@ -16,6 +16,7 @@ import (
 	"ashokshau/tgmusic/src/core/cache"
 	"ashokshau/tgmusic/src/core/db"
 	"ashokshau/tgmusic/src/lang"
+	"ashokshau/tgmusic/src/vc/ubot"
 
 	tg "github.com/amarnathcjd/gogram/telegram"
 )
@@ -46,7 +47,10 @@ func (c *TelegramCalls) joinAssistant(chatID, ubID int64) error {
 		logger.Info("[TelegramCalls - joinAssistant] The assistant appears to be %s. Attempting to unban and rejoin...", status)
 		botStatus, err := cache.GetUserAdmin(c.bot, chatID, c.bot.Me().ID, false)
 		if err != nil {
-			if strings.Contains(err.Error(), "is not an admin in chat") {
+			if strings.Contains(err.Error(), "is not an administrator in chat") {
+				if isBanned {
+					return fmt.Errorf(lang.GetString(langCode, "assistant_banned_here"), ubID)
+				}
 				return fmt.Errorf(lang.GetString(langCode, "unban_fail_no_admin"), ubID)
 			}
 			logger.Warn("An error occurred while checking the bot's admin status: %v", err)
@@ -54,10 +58,16 @@ func (c *TelegramCalls) joinAssistant(chatID, ubID int64) error {
 		}
 
 		if botStatus.Status != tg.Admin {
+			if isBanned {
+				return fmt.Errorf(lang.GetString(langCode, "assistant_banned_here"), ubID)
+			}
 			return fmt.Errorf(lang.GetString(langCode, "unban_fail_bot_not_admin"), ubID)
 		}
 
 		if botStatus.Rights != nil && !botStatus.Rights.BanUsers {
+			if isBanned {
+				return fmt.Errorf(lang.GetString(langCode, "assistant_banned_here"), ubID)
+			}
 			return fmt.Errorf(lang.GetString(langCode, "unban_fail_no_perm"), ubID)
 		}
 
@@ -190,3 +200,30 @@ func (c *TelegramCalls) joinUb(chatID int64) error {
 	c.UpdateMembership(chatID, ub.Me().ID, tg.Member)
 	return nil
 }
+
+// isNoActiveCall reports whether a playback error means the group has no voice chat running.
+func isNoActiveCall(err error) bool {
+	return errors.Is(err, ubot.ErrNoActiveCall) ||
+		strings.Contains(err.Error(), "GROUPCALL_INVALID") ||
+		strings.Contains(err.Error(), "GROUPCALL_FORBIDDEN")
+}
+
+// startVoiceChat starts a voice chat through the assistant when it is allowed to manage voice chats.
+// Otherwise it returns an error asking an admin to start one.
+func (c *TelegramCalls) startVoiceChat(chatID int64, call *ubot.Context) error {
+	ctx, cancel := db.Ctx()
+	defer cancel()
+	langCode := db.Instance.GetLang(ctx, chatID)
+
+	ubStatus, err := cache.GetUserAdmin(c.bot, chatID, call.App.Me().ID, false)
+	if err != nil || ubStatus.Rights == nil || !ubStatus.Rights.ManageCall {
+		return errors.New(lang.GetString(langCode, "no_active_voice_chat"))
+	}
+
+	logger.Info("[TelegramCalls - startVoiceChat] No voice chat in %d; starting one via the assistant.", chatID)
+	if err = call.StartGroupCall(chatID); err != nil {
+		logger.Warn("Failed to start a voice chat in %d: %v", chatID, err)
+		return fmt.Errorf(lang.GetString(langCode, "start_voice_chat_fail"), err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+ * TgMusicBot - Telegram Music Bot
+ *  Copyright (c) 2025 Ashok Shau
+ *
+ *  Licensed under GNU GPL v3
+ *  See https://github.com/AshokShau/TgMusicBot
+ */
+
+package vc
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"ashokshau/tgmusic/src/config"
+	"ashokshau/tgmusic/src/core/cache"
+)
+
+// waitingRoom holds the chats waiting for a free playback slot, in admission order.
+type waitingRoom struct {
+	mu    sync.Mutex
+	chats []int64
+	// admitted holds the chats given a playback slot that have not started playing yet. They count against
+	// the cap, so that chats admitted at the same time cannot all take the last slot.
+	admitted map[int64]struct{}
+}
+
+// full reports whether the global cap on active playback sessions has been reached. w.mu must be held.
+func (w *waitingRoom) full() bool {
+	limit := int(config.Get().MaxActiveCalls)
+	if limit <= 0 {
+		return false
+	}
+	active := cache.ChatCache.GetActiveChats()
+	used := len(active)
+	for chatID := range w.admitted {
+		if !slices.Contains(active, chatID) {
+			used++
+		}
+	}
+	return used >= limit
+}
+
+// admit gives the chat a playback slot until releaseSlot is called. w.mu must be held.
+func (w *waitingRoom) admit(chatID int64) {
+	if w.admitted == nil {
+		w.admitted = make(map[int64]struct{})
+	}
+	w.admitted[chatID] = struct{}{}
+}
+
+// Admit decides whether a chat that is not playing yet may start playback now.
+// It returns 0 if the chat may start, and the caller must then call ReleaseSlot once playback started or
+// failed. Otherwise it runs enqueue, which must queue the chat's tracks and leave it inactive, and returns
+// the chat's 1-based position in the waiting room; enqueue runs under the waiting room's lock, so the chat
+// cannot be admitted before its tracks are queued. Chats also wait while every assistant is cooling down
+// from a Telegram restriction. A chat that is already waiting keeps its position.
+func (c *TelegramCalls) Admit(chatID int64, enqueue func() error) (int, error) {
+	c.waiting.mu.Lock()
+	defer c.waiting.mu.Unlock()
+	if i := slices.Index(c.waiting.chats, chatID); i >= 0 {
+		return i + 1, enqueue()
+	}
+	if len(c.waiting.chats) == 0 && !c.waiting.full() && !c.coolingDown() {
+		c.waiting.admit(chatID)
+		return 0, nil
+	}
+
+	if err := enqueue(); err != nil {
+		return 0, err
+	}
+	c.waiting.chats = append(c.waiting.chats, chatID)
+	logger.Info("[Admit] No playback slot or assistant is free; %d is waiting at position %d.", chatID, len(c.waiting.chats))
+	return len(c.waiting.chats), nil
+}
+
+// ReleaseSlot ends the admission of a chat that Admit let start. A chat that started playing keeps its
+// slot as long as it is active.
+func (c *TelegramCalls) ReleaseSlot(chatID int64) {
+	c.waiting.mu.Lock()
+	delete(c.waiting.admitted, chatID)
+	c.waiting.mu.Unlock()
+}
+
+// WaitingPosition returns the chat's 1-based position in the waiting room, or 0 if it is not waiting.
+func (c *TelegramCalls) WaitingPosition(chatID int64) int {
+	c.waiting.mu.Lock()
+	defer c.waiting.mu.Unlock()
+	return slices.Index(c.waiting.chats, chatID) + 1
+}
+
+// WaitingList returns the waiting chats in admission order.
+func (c *TelegramCalls) WaitingList() []int64 {
+	c.waiting.mu.Lock()
+	defer c.waiting.mu.Unlock()
+	return slices.Clone(c.waiting.chats)
+}
+
+// ErrNotWaiting is returned by BumpWaiting for a chat that is not in the waiting room.
+var ErrNotWaiting = errors.New("chat is not waiting")
+
+// BumpWaiting moves a waiting chat to the front of the waiting room.
+func (c *TelegramCalls) BumpWaiting(chatID int64) error {
+	c.waiting.mu.Lock()
+	defer c.waiting.mu.Unlock()
+	i := slices.Index(c.waiting.chats, chatID)
+	if i < 0 {
+		return ErrNotWaiting
+	}
+	c.waiting.chats = slices.Delete(c.waiting.chats, i, i+1)
+	c.waiting.chats = slices.Insert(c.waiting.chats, 0, chatID)
+	return nil
+}
+
+// leaveWaitingRoom removes the chat from the waiting room, if it is there.
+func (c *TelegramCalls) leaveWaitingRoom(chatID int64) {
+	c.waiting.mu.Lock()
+	defer c.waiting.mu.Unlock()
+	if i := slices.Index(c.waiting.chats, chatID); i >= 0 {
+		c.waiting.chats = slices.Delete(c.waiting.chats, i, i+1)
+	}
+}
+
+// admitWaiting starts waiting chats, in order, while playback slots and assistants are free.
+// It is called whenever a session ends or an assistant recovers.
+func (c *TelegramCalls) admitWaiting() {
+	for {
+		c.waiting.mu.Lock()
+		if len(c.waiting.chats) == 0 || c.waiting.full() || c.coolingDown() {
+			c.waiting.mu.Unlock()
+			return
+		}
+		chatID := c.waiting.chats[0]
+		c.waiting.chats = c.waiting.chats[1:]
+		c.waiting.admit(chatID)
+		c.waiting.mu.Unlock()
+
+		err := c.startWaiting(chatID)
+		c.ReleaseSlot(chatID)
+		if err != nil {
+			logger.Warn("[admitWaiting] Failed to start playback in %d: %v", chatID, err)
+		}
+	}
+}
+
+// startWaiting starts the queue of a chat that was admitted from the waiting room.
+func (c *TelegramCalls) startWaiting(chatID int64) error {
+	unlock := c.lockPlayback(chatID)
+	defer unlock()
+
+	song := cache.ChatCache.GetPlayingTrack(chatID)
+	if song == nil {
+		return nil
+	}
+
+	cache.ChatCache.SetActive(chatID, true)
+	c.notify(chatID, "waiting_room_started")
+	_ = c.setState(chatID, StateTransitioning, nil)
+	err := c.playSong(chatID, song)
+	if errors.Is(err, errTrackUnavailable) {
+		return c.advanceQueue(chatID, false)
+	}
+	c.states.Settle(chatID, StateTransitioning, err)
+	return err
+}